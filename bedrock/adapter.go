@@ -0,0 +1,258 @@
+// Package bedrock is an adapter for Amazon Bedrock.
+//
+// Chat requests use the Converse API, which serves Anthropic Claude, Amazon
+// Titan and Nova, and the other Bedrock text models with one message format.
+// Requests are signed with AWS Signature Version 4, or authenticated with a
+// Bedrock API key. Embeddings use the model's InvokeModel API and support
+// Amazon Titan and Cohere embedding models.
+package bedrock
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+const (
+	providerName           = "bedrock"
+	signingService         = "bedrock"
+	defaultMaxAgenticLoops = 8
+	defaultHTTPTimeout     = 5 * time.Minute
+	defaultOutputToolName  = "structured_output"
+	defaultThinkingBudget  = 4096
+	minThinkingBudget      = 1024
+	envRegion              = "AWS_REGION"
+	envDefaultRegion       = "AWS_DEFAULT_REGION"
+	envAccessKeyID         = "AWS_ACCESS_KEY_ID"
+	envSecretAccessKey     = "AWS_SECRET_ACCESS_KEY"
+	envSessionToken        = "AWS_SESSION_TOKEN"
+	envBearerToken         = "AWS_BEARER_TOKEN_BEDROCK"
+)
+
+// Credentials are AWS credentials used to sign requests. SessionToken is only
+// set for temporary credentials, such as those of an assumed role.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+type Adapter struct {
+	// Model is a Bedrock model ID, such as
+	// "anthropic.claude-3-5-sonnet-20240620-v1:0", an inference profile ID,
+	// such as "us.anthropic.claude-3-7-sonnet-20250219-v1:0", or an ARN.
+	Model  string
+	Region string
+
+	// BaseURL replaces the regional endpoint
+	// https://bedrock-runtime.<region>.amazonaws.com, such as for a VPC
+	// endpoint. Requests are still signed for Region.
+	BaseURL string
+
+	Credentials Credentials
+
+	// APIKey is a Bedrock API key. When set, it is sent as a bearer token and
+	// requests are not signed.
+	APIKey string
+
+	HTTPClient *http.Client
+}
+
+var _ core.TextAdapter = (*Adapter)(nil)
+var _ core.EmbeddingAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
+
+type Option func(*Adapter)
+
+// New creates a Bedrock adapter.
+//
+// Preferred usage is to use core and add this adapter there.
+//
+// If no region or credentials are provided via options, New reads AWS_REGION
+// (then AWS_DEFAULT_REGION), AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+// AWS_SESSION_TOKEN, and the Bedrock API key AWS_BEARER_TOKEN_BEDROCK.
+func New(model string, opts ...Option) *Adapter {
+	adapter := &Adapter{
+		Model:       strings.TrimSpace(model),
+		Region:      resolveRegion(),
+		Credentials: resolveCredentials(),
+		APIKey:      strings.TrimSpace(os.Getenv(envBearerToken)),
+		HTTPClient:  &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(adapter)
+	}
+
+	return adapter
+}
+
+// WithRegion sets the AWS region of the Bedrock endpoint, such as "us-east-1".
+func WithRegion(region string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(region) == "" {
+			return
+		}
+		adapter.Region = strings.TrimSpace(region)
+	}
+}
+
+// WithCredentials sets the AWS credentials that sign requests. The session
+// token may be empty for long-term credentials.
+func WithCredentials(accessKeyID, secretAccessKey, sessionToken string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(accessKeyID) == "" || strings.TrimSpace(secretAccessKey) == "" {
+			return
+		}
+		adapter.Credentials = Credentials{
+			AccessKeyID:     strings.TrimSpace(accessKeyID),
+			SecretAccessKey: strings.TrimSpace(secretAccessKey),
+			SessionToken:    strings.TrimSpace(sessionToken),
+		}
+	}
+}
+
+// WithAPIKey sets a Bedrock API key, which replaces SigV4 signing.
+func WithAPIKey(apiKey string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(apiKey) == "" {
+			return
+		}
+		adapter.APIKey = strings.TrimSpace(apiKey)
+	}
+}
+
+// WithBaseURL sets the API base URL used by the adapter.
+func WithBaseURL(baseURL string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(baseURL) == "" {
+			return
+		}
+		adapter.BaseURL = strings.TrimSpace(baseURL)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the adapter.
+func WithHTTPClient(client *http.Client) Option {
+	return func(adapter *Adapter) {
+		if client == nil {
+			return
+		}
+		adapter.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the timeout on the adapter HTTP client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(adapter *Adapter) {
+		if timeout <= 0 {
+			return
+		}
+		if adapter.HTTPClient == nil {
+			adapter.HTTPClient = &http.Client{}
+		}
+		adapter.HTTPClient.Timeout = timeout
+	}
+}
+
+// Capabilities reports the chat features of the Converse API. Whether a model
+// supports tools, images, documents, or reasoning depends on the model.
+// Structured output is requested through a forced tool call.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:              true,
+		Vision:             true,
+		Documents:          true,
+		StructuredOutput:   true,
+		StreamingWithTools: true,
+		Reasoning:          true,
+	}
+}
+
+func (a *Adapter) validate() error {
+	if a == nil {
+		return errors.New("bedrock: adapter is nil")
+	}
+
+	if strings.TrimSpace(a.Model) == "" {
+		return errors.New("bedrock: model is required")
+	}
+
+	if strings.TrimSpace(a.Region) == "" {
+		a.Region = resolveRegion()
+	}
+	if strings.TrimSpace(a.Region) == "" {
+		return errors.New("bedrock: region is required (set AWS_REGION or use bedrock.WithRegion)")
+	}
+
+	if strings.TrimSpace(a.APIKey) == "" {
+		a.APIKey = strings.TrimSpace(os.Getenv(envBearerToken))
+	}
+	if a.APIKey != "" {
+		return nil
+	}
+
+	if a.Credentials.AccessKeyID == "" || a.Credentials.SecretAccessKey == "" {
+		a.Credentials = resolveCredentials()
+	}
+	if a.Credentials.AccessKeyID == "" || a.Credentials.SecretAccessKey == "" {
+		return errors.New("bedrock: AWS credentials are required (set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, AWS_BEARER_TOKEN_BEDROCK, or use bedrock.WithCredentials)")
+	}
+
+	return nil
+}
+
+func (a *Adapter) client() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// transport returns the client that sends API requests. Signing needs the
+// encoded body, so the signature headers are added per request by
+// a.request.
+func (a *Adapter) transport() *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   providerName,
+		Header: func(header http.Header) {
+			header.Set("Accept", "application/json")
+			if a.APIKey != "" {
+				header.Set("Authorization", "Bearer "+a.APIKey)
+			}
+		},
+		DecodeError: decodeAPIError,
+	}
+}
+
+func (a *Adapter) baseURL() string {
+	if strings.TrimSpace(a.BaseURL) == "" {
+		return "https://bedrock-runtime." + strings.TrimSpace(a.Region) + ".amazonaws.com"
+	}
+	return a.BaseURL
+}
+
+func resolveRegion() string {
+	if region := strings.TrimSpace(os.Getenv(envRegion)); region != "" {
+		return region
+	}
+	return strings.TrimSpace(os.Getenv(envDefaultRegion))
+}
+
+func resolveCredentials() Credentials {
+	return Credentials{
+		AccessKeyID:     strings.TrimSpace(os.Getenv(envAccessKeyID)),
+		SecretAccessKey: strings.TrimSpace(os.Getenv(envSecretAccessKey)),
+		SessionToken:    strings.TrimSpace(os.Getenv(envSessionToken)),
+	}
+}
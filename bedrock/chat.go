@@ -0,0 +1,566 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/partialjson"
+)
+
+// Chat sends a non-streaming Converse request to Bedrock.
+//
+// It supports tool calls, structured output through a forced tool call, and
+// the reasoning of models with extended thinking.
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	requestTemplate, serverTools, clientTools, maxLoopCount, err := a.buildRequest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := requestTemplate.Messages
+	conversation := cloneCoreMessages(params)
+	reasoningParts := make([]string, 0, 4)
+
+	for range maxLoopCount {
+		request := requestTemplate
+		request.Messages = messages
+
+		response, err := a.converse(ctx, &request)
+		if err != nil {
+			return nil, err
+		}
+
+		var content []contentBlock
+		if response.Output.Message != nil {
+			content = response.Output.Message.Content
+		}
+		reasoningParts = appendReasoningPart(reasoningParts, extractReasoning(content))
+
+		toolUses := extractToolUses(content)
+		if output, ok := findToolUse(toolUses, request.OutputTool); ok {
+			encoded, err := json.Marshal(output.Input)
+			if err != nil {
+				return nil, fmt.Errorf("bedrock: encode structured output: %w", err)
+			}
+			text := string(encoded)
+			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: text})
+			return &core.ChatResult{
+				Text:            text,
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				FinishReason:    core.FinishReasonStop,
+				RawFinishReason: response.StopReason,
+				Usage:           responseUsage(response),
+				RequestID:       response.RequestID,
+			}, nil
+		}
+		if len(toolUses) == 0 {
+			text := extractText(content)
+			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: text})
+			return &core.ChatResult{
+				Text:            text,
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				FinishReason:    toCoreFinishReason(response.StopReason),
+				RawFinishReason: response.StopReason,
+				Usage:           responseUsage(response),
+				RequestID:       response.RequestID,
+			}, nil
+		}
+
+		messages = append(messages, message{Role: core.RoleAssistant, Content: content})
+
+		coreCalls := toCoreToolCalls(toolUses)
+		if text := extractText(content); strings.TrimSpace(text) != "" {
+			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: text})
+		}
+		conversation = append(conversation, core.ToolCallMessagePart{
+			Role:            core.RoleToolCall,
+			ToolCalls:       coreCalls,
+			ReasoningBlocks: extractReasoningBlocks(content),
+		})
+
+		results, pendingClientCalls, err := runServerTools(coreCalls, serverTools, clientTools)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			conversation = append(conversation, result)
+		}
+
+		if len(pendingClientCalls) > 0 {
+			return &core.ChatResult{
+				Text:            "",
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				ToolCalls:       pendingClientCalls,
+				FinishReason:    core.FinishReasonToolCalls,
+				RawFinishReason: response.StopReason,
+				Usage:           responseUsage(response),
+				RequestID:       response.RequestID,
+			}, nil
+		}
+
+		messages = append(messages, toolResultsMessage(results))
+	}
+
+	return nil, fmt.Errorf("bedrock: reached max tool loop count (%d)", maxLoopCount)
+}
+
+// ChatStream sends a streaming ConverseStream request to Bedrock.
+//
+// Structured output streams as StreamChunkPartialJSON chunks. Tool calls are
+// emitted once their input is complete; server tools run between streamed
+// turns, and the stream ends with FinishReason "tool_calls" when client tools
+// must be run.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	requestTemplate, serverTools, clientTools, maxLoopCount, err := a.buildRequest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	out := make(chan core.StreamChunk, 64)
+
+	go func() {
+		defer close(out)
+
+		messages := requestTemplate.Messages
+		reasoningParts := make([]string, 0, 4)
+
+		for range maxLoopCount {
+			request := requestTemplate
+			request.Messages = messages
+
+			turn, err := a.streamTurn(ctx, &request, out)
+			if err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: nonEmpty(core.RequestIDOf(err), turn.requestID)}
+				return
+			}
+
+			reasoningParts = appendReasoningPart(reasoningParts, extractReasoning(turn.message.Content))
+
+			if turn.output || len(turn.calls) == 0 {
+				finishReason := toCoreFinishReason(turn.stopReason)
+				if turn.output {
+					finishReason = core.FinishReasonStop
+				}
+				out <- core.StreamChunk{
+					Type:            core.StreamChunkDone,
+					FinishReason:    finishReason,
+					RawFinishReason: turn.stopReason,
+					Reasoning:       joinReasoningParts(reasoningParts),
+					Usage:           turn.usage,
+					RequestID:       turn.requestID,
+				}
+				return
+			}
+
+			messages = append(messages, turn.message)
+
+			results, pendingClientCalls, err := runServerTools(turn.calls, serverTools, clientTools)
+			if err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err}
+				return
+			}
+			for _, result := range results {
+				out <- core.StreamChunk{Type: core.StreamChunkToolResult, ToolCallID: result.ToolCallID, Content: result.Content}
+			}
+
+			if len(pendingClientCalls) > 0 {
+				out <- core.StreamChunk{
+					Type:            core.StreamChunkDone,
+					FinishReason:    core.FinishReasonToolCalls,
+					RawFinishReason: turn.stopReason,
+					Reasoning:       joinReasoningParts(reasoningParts),
+					Usage:           turn.usage,
+					RequestID:       turn.requestID,
+				}
+				return
+			}
+
+			messages = append(messages, toolResultsMessage(results))
+		}
+
+		out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("bedrock: reached max tool loop count (%d)", maxLoopCount)}
+	}()
+
+	return core.TimeStream(ctx, out, start), nil
+}
+
+// streamedTurn is one streamed assistant response, accumulated for the next
+// request of the tool loop.
+type streamedTurn struct {
+	message    message
+	calls      []core.ToolCall
+	output     bool
+	stopReason string
+	usage      *core.Usage
+	requestID  string
+}
+
+// streamBlock accumulates one content block of a streamed response.
+type streamBlock struct {
+	text      strings.Builder
+	reasoning strings.Builder
+	signature string
+	redacted  string
+	toolUse   *toolUseBlock
+	input     strings.Builder
+}
+
+// streamTurn streams one ConverseStream request, forwarding reasoning,
+// content, structured output, and tool call chunks to out as they arrive. The
+// returned turn is never nil, so that failed turns still report the request
+// ID.
+func (a *Adapter) streamTurn(ctx context.Context, request *converseRequest, out chan<- core.StreamChunk) (*streamedTurn, error) {
+	apiRequest, err := a.request(a.modelPath("converse-stream"), "stream", request)
+	if err != nil {
+		return &streamedTurn{}, err
+	}
+	apiRequest.Header.Set("Accept", "application/vnd.amazon.eventstream")
+
+	turn := &streamedTurn{message: message{Role: core.RoleAssistant}}
+	httpResp, err := a.transport().Do(ctx, apiRequest)
+	if err != nil {
+		return turn, err
+	}
+	defer httpResp.Body.Close()
+	turn.requestID = requestID(httpResp.Header)
+	blocks := make(map[int]*streamBlock)
+	var order []int
+	block := func(index int) *streamBlock {
+		if b, ok := blocks[index]; ok {
+			return b
+		}
+		b := &streamBlock{}
+		blocks[index] = b
+		order = append(order, index)
+		return b
+	}
+
+	var content, reasoning strings.Builder
+	var partial partialjson.Assembler
+	reader := newEventReader(httpResp.Body)
+
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return turn, fmt.Errorf("bedrock: stream read failed: %w", err)
+		}
+
+		if event.Headers[":message-type"] == "exception" {
+			return turn, streamException(event, turn.requestID)
+		}
+
+		var payload streamEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return turn, fmt.Errorf("bedrock: decode stream event: %w", err)
+		}
+
+		switch event.Headers[":event-type"] {
+		case "contentBlockStart":
+			if payload.Start != nil && payload.Start.ToolUse != nil {
+				use := *payload.Start.ToolUse
+				block(payload.ContentBlockIndex).toolUse = &use
+			}
+
+		case "contentBlockDelta":
+			if payload.Delta == nil {
+				continue
+			}
+			current := block(payload.ContentBlockIndex)
+			delta := payload.Delta
+
+			if delta.Text != "" {
+				current.text.WriteString(delta.Text)
+				content.WriteString(delta.Text)
+				out <- core.StreamChunk{
+					Type:    core.StreamChunkContent,
+					Role:    core.RoleAssistant,
+					Delta:   delta.Text,
+					Content: content.String(),
+				}
+			}
+
+			if delta.ReasoningContent != nil {
+				current.reasoning.WriteString(delta.ReasoningContent.Text)
+				if delta.ReasoningContent.Signature != "" {
+					current.signature = delta.ReasoningContent.Signature
+				}
+				if delta.ReasoningContent.RedactedContent != "" {
+					current.redacted += delta.ReasoningContent.RedactedContent
+				}
+				if delta.ReasoningContent.Text != "" {
+					reasoning.WriteString(delta.ReasoningContent.Text)
+					out <- core.StreamChunk{
+						Type:      core.StreamChunkReasoning,
+						Role:      core.RoleAssistant,
+						Delta:     delta.ReasoningContent.Text,
+						Reasoning: reasoning.String(),
+					}
+				}
+			}
+
+			if delta.ToolUse != nil && current.toolUse != nil {
+				current.input.WriteString(delta.ToolUse.Input)
+				if current.toolUse.Name == request.OutputTool && delta.ToolUse.Input != "" {
+					partial.WriteString(delta.ToolUse.Input)
+					out <- core.StreamChunk{
+						Type:    core.StreamChunkPartialJSON,
+						Role:    core.RoleAssistant,
+						Delta:   delta.ToolUse.Input,
+						Content: partial.Partial(),
+					}
+				}
+			}
+
+		case "contentBlockStop":
+			current, ok := blocks[payload.ContentBlockIndex]
+			if !ok || current.toolUse == nil {
+				continue
+			}
+			input, err := decodeToolInput(current.input.String())
+			if err != nil {
+				return turn, fmt.Errorf("bedrock: invalid input for tool %q: %w", current.toolUse.Name, err)
+			}
+			current.toolUse.Input = input
+			if current.toolUse.Name == request.OutputTool {
+				turn.output = true
+				continue
+			}
+
+			call := core.ToolCall{ID: current.toolUse.ToolUseID, Name: current.toolUse.Name, Arguments: input}
+			turn.calls = append(turn.calls, call)
+			out <- core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &call}
+
+		case "messageStop":
+			turn.stopReason = payload.StopReason
+
+		case "metadata":
+			if payload.Usage != nil {
+				turn.usage = toCoreUsage(payload.Usage, payload.Metrics)
+			}
+		}
+	}
+
+	for _, index := range order {
+		current := blocks[index]
+		switch {
+		case current.toolUse != nil:
+			turn.message.Content = append(turn.message.Content, contentBlock{ToolUse: current.toolUse})
+		case current.redacted != "":
+			turn.message.Content = append(turn.message.Content, contentBlock{ReasoningContent: &reasoningContent{RedactedContent: current.redacted}})
+		case current.reasoning.Len() > 0:
+			turn.message.Content = append(turn.message.Content, contentBlock{ReasoningContent: &reasoningContent{
+				ReasoningText: &reasoningText{Text: current.reasoning.String(), Signature: current.signature},
+			}})
+		case current.text.Len() > 0:
+			turn.message.Content = append(turn.message.Content, contentBlock{Text: current.text.String()})
+		}
+	}
+	if turn.usage != nil {
+		turn.usage.RequestID = turn.requestID
+	}
+	return turn, nil
+}
+
+// buildRequest converts params into a Converse request template holding the
+// initial messages.
+func (a *Adapter) buildRequest(params *core.ChatParams) (converseRequest, map[string]core.ServerTool, map[string]struct{}, int, error) {
+	messages, system, err := toMessagesAndSystem(params)
+	if err != nil {
+		return converseRequest{}, nil, nil, 0, err
+	}
+
+	tools, serverTools, clientTools, err := toTools(params)
+	if err != nil {
+		return converseRequest{}, nil, nil, 0, err
+	}
+
+	budget, err := thinkingBudget(params)
+	if err != nil {
+		return converseRequest{}, nil, nil, 0, err
+	}
+	if budget > 0 && !isAnthropicModel(a.Model) {
+		return converseRequest{}, nil, nil, 0, fmt.Errorf("bedrock: thinking is only supported for Anthropic models, got %q", a.Model)
+	}
+
+	request := converseRequest{
+		Messages:                     messages,
+		System:                       system,
+		InferenceConfig:              toInferenceConfig(params),
+		AdditionalModelRequestFields: additionalFields(params, budget),
+	}
+	if budget > 0 {
+		// The thinking budget counts against maxTokens, which must exceed it.
+		if request.InferenceConfig == nil {
+			request.InferenceConfig = &inferenceConfig{}
+		}
+		if limit := request.InferenceConfig.MaxTokens; limit == nil || *limit <= budget {
+			value := budget + minThinkingBudget
+			request.InferenceConfig.MaxTokens = &value
+		}
+	}
+
+	choice, offerTools, err := requestToolChoice(params)
+	if err != nil {
+		return converseRequest{}, nil, nil, 0, err
+	}
+	if !offerTools {
+		tools = nil
+	}
+
+	if params.Output != nil {
+		if params.Output.Schema == nil {
+			return converseRequest{}, nil, nil, 0, fmt.Errorf("bedrock: output schema is required")
+		}
+		definition := outputTool(params.Output)
+		if err := assertNewToolName(toolNames(tools), definition.ToolSpec.Name); err != nil {
+			return converseRequest{}, nil, nil, 0, err
+		}
+		request.OutputTool = definition.ToolSpec.Name
+		// Extended thinking only permits automatic tool choice.
+		switch {
+		case budget > 0:
+			choice = &toolChoice{Auto: &struct{}{}}
+		case len(tools) > 0:
+			choice = &toolChoice{Any: &struct{}{}}
+		default:
+			choice = &toolChoice{Tool: &toolChoiceName{Name: definition.ToolSpec.Name}}
+		}
+		tools = append(tools, definition)
+	}
+
+	if len(tools) > 0 {
+		if params.CacheTools != nil {
+			tools = append(tools, tool{CachePoint: &cachePoint{Type: "default"}})
+		}
+		request.ToolConfig = &toolConfig{Tools: tools, ToolChoice: choice}
+	}
+
+	return request, serverTools, clientTools, maxLoops(params, len(serverTools) > 0), nil
+}
+
+func toolNames(tools []tool) map[string]struct{} {
+	names := make(map[string]struct{}, len(tools))
+	for _, t := range tools {
+		if t.ToolSpec != nil {
+			names[t.ToolSpec.Name] = struct{}{}
+		}
+	}
+	return names
+}
+
+func (a *Adapter) converse(ctx context.Context, request *converseRequest) (*converseResponse, error) {
+	apiRequest, err := a.request(a.modelPath("converse"), "converse", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var response converseResponse
+	httpResp, err := a.transport().Send(ctx, apiRequest, &response)
+	if err != nil {
+		return nil, err
+	}
+	response.Duration = httpResp.Duration
+	response.RequestID = requestID(httpResp.Header)
+
+	return &response, nil
+}
+
+// request encodes body and returns a POST request for path, signed with the
+// adapter's credentials unless it uses an API key.
+func (a *Adapter) request(path, name string, body any) (httpclient.Request, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return httpclient.Request{}, fmt.Errorf("bedrock: marshal %s request: %w", name, err)
+	}
+
+	header := http.Header{}
+	if a.APIKey == "" {
+		target, err := url.Parse(strings.TrimRight(a.baseURL(), "/") + path)
+		if err != nil {
+			return httpclient.Request{}, fmt.Errorf("bedrock: build %s request: %w", name, err)
+		}
+		header.Set("Content-Type", "application/json")
+		signRequest(header, http.MethodPost, target, payload, a.Credentials, a.Region, signingService, time.Now())
+	}
+
+	return httpclient.Request{Method: http.MethodPost, Path: path, Name: name, Body: payload, Header: header}, nil
+}
+
+// modelPath returns the path of a model operation, such as "converse". Model
+// IDs and ARNs are escaped as one path segment.
+func (a *Adapter) modelPath(operation string) string {
+	return "/model/" + uriEncode(a.Model) + "/" + operation
+}
+
+// runServerTools runs the server tools among calls and returns their results
+// and the calls left to the caller.
+func runServerTools(calls []core.ToolCall, serverTools map[string]core.ServerTool, clientTools map[string]struct{}) ([]core.ToolResultMessagePart, []core.ToolCall, error) {
+	results := make([]core.ToolResultMessagePart, 0, len(calls))
+	var pendingClientCalls []core.ToolCall
+
+	for _, call := range calls {
+		if serverTool, ok := serverTools[call.Name]; ok {
+			result, callErr := serverTool.Handler(call.Arguments)
+			if callErr != nil {
+				result = "tool_error: " + callErr.Error()
+			}
+			results = append(results, core.ToolResultMessagePart{
+				Role:       core.RoleToolResult,
+				ToolCallID: call.ID,
+				Name:       call.Name,
+				Content:    result,
+			})
+			continue
+		}
+
+		if _, ok := clientTools[call.Name]; ok {
+			pendingClientCalls = append(pendingClientCalls, call)
+			continue
+		}
+
+		return nil, nil, fmt.Errorf("bedrock: tool %q was requested but not registered", call.Name)
+	}
+
+	return results, pendingClientCalls, nil
+}
+
+func toolResultsMessage(results []core.ToolResultMessagePart) message {
+	blocks := make([]contentBlock, 0, len(results))
+	for _, result := range results {
+		blocks = append(blocks, toolResultBlockFor(result.ToolCallID, result.Content))
+	}
+	return message{Role: core.RoleUser, Content: blocks}
+}
+
+func cloneCoreMessages(params *core.ChatParams) []core.MessageUnion {
+	if params == nil || len(params.Messages) == 0 {
+		return nil
+	}
+
+	out := make([]core.MessageUnion, 0, len(params.Messages)+8)
+	out = append(out, params.Messages...)
+	return out
+}
@@ -0,0 +1,246 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+const testModel = "anthropic.claude-3-5-sonnet-20240620-v1:0"
+
+func newTestAdapter(baseURL string, opts ...Option) *Adapter {
+	opts = append([]Option{
+		WithRegion("us-east-1"),
+		WithCredentials("AKIDEXAMPLE", "secret", ""),
+		WithBaseURL(baseURL),
+	}, opts...)
+	return New(testModel, opts...)
+}
+
+func TestChatSignsAndMapsRequest(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/model/anthropic.claude-3-5-sonnet-20240620-v1%3A0/converse" {
+			t.Errorf("unexpected path %q", r.URL.EscapedPath())
+		}
+		if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/us-east-1/bedrock/aws4_request") {
+			t.Errorf("unexpected Authorization %q", auth)
+		}
+		if r.Header.Get("X-Amz-Date") == "" {
+			t.Error("missing X-Amz-Date")
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-amzn-RequestId", "req-1")
+		_, _ = w.Write([]byte(`{"output":{"message":{"role":"assistant","content":[{"text":"Hello!"}]}},"stopReason":"end_turn","usage":{"inputTokens":10,"outputTokens":3,"totalTokens":13,"cacheReadInputTokens":4},"metrics":{"latencyMs":120}}`))
+	}))
+	defer server.Close()
+
+	maxTokens := int64(256)
+	temperature := 0.2
+	result, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		SystemPrompts:     []string{"Be brief."},
+		CacheSystemPrompt: &core.CacheControl{},
+		Messages:          []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		MaxTokens:         &maxTokens,
+		Temperature:       &temperature,
+		StopSequences:     []string{"END"},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if result.Text != "Hello!" || result.FinishReason != core.FinishReasonStop || result.RequestID != "req-1" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 13 || result.Usage.Details["cache_read_input_tokens"] != 4 || result.Usage.Details["latency_ms"] != 120 {
+		t.Fatalf("unexpected usage %+v", result.Usage)
+	}
+
+	system := request["system"].([]any)
+	if len(system) != 2 || system[0].(map[string]any)["text"] != "Be brief." || system[1].(map[string]any)["cachePoint"] == nil {
+		t.Fatalf("unexpected system %#v", system)
+	}
+	messages := request["messages"].([]any)
+	first := messages[0].(map[string]any)
+	if first["role"] != "user" || first["content"].([]any)[0].(map[string]any)["text"] != "Hi" {
+		t.Fatalf("unexpected messages %#v", messages)
+	}
+	config := request["inferenceConfig"].(map[string]any)
+	if config["maxTokens"] != float64(256) || config["temperature"] != 0.2 || config["stopSequences"].([]any)[0] != "END" {
+		t.Fatalf("unexpected inferenceConfig %#v", config)
+	}
+	if request["toolConfig"] != nil {
+		t.Fatalf("unexpected toolConfig %#v", request["toolConfig"])
+	}
+}
+
+func TestChatRunsServerToolsAndSendsReasoningBack(t *testing.T) {
+	t.Parallel()
+
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		requests = append(requests, request)
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"output":{"message":{"role":"assistant","content":[{"reasoningContent":{"reasoningText":{"text":"check weather","signature":"sig"}}},{"toolUse":{"toolUseId":"t1","name":"weather","input":{"city":"Berlin"}}}]}},"stopReason":"tool_use"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"output":{"message":{"role":"assistant","content":[{"text":"Sunny."}]}},"stopReason":"end_turn"}`))
+	}))
+	defer server.Close()
+
+	var arguments any
+	result, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather?"}},
+		Tools: []core.ToolUnion{core.ServerTool{
+			Name:        "weather",
+			Description: "Current weather",
+			Handler: func(args any) (string, error) {
+				arguments = args
+				return "sunny", nil
+			},
+		}},
+		Thinking: "enabled",
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if result.Text != "Sunny." || result.Reasoning != "check weather" || len(requests) != 2 {
+		t.Fatalf("unexpected result %+v after %d requests", result, len(requests))
+	}
+	if arguments.(map[string]any)["city"] != "Berlin" {
+		t.Fatalf("unexpected tool arguments %#v", arguments)
+	}
+
+	thinking := requests[0]["additionalModelRequestFields"].(map[string]any)["thinking"].(map[string]any)
+	if thinking["type"] != "enabled" || thinking["budget_tokens"] != float64(defaultThinkingBudget) {
+		t.Fatalf("unexpected thinking %#v", thinking)
+	}
+	spec := requests[0]["toolConfig"].(map[string]any)["tools"].([]any)[0].(map[string]any)["toolSpec"].(map[string]any)
+	if spec["name"] != "weather" || spec["inputSchema"].(map[string]any)["json"] == nil {
+		t.Fatalf("unexpected tool spec %#v", spec)
+	}
+
+	messages := requests[1]["messages"].([]any)
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %#v", messages)
+	}
+	assistant := messages[1].(map[string]any)["content"].([]any)
+	reasoning := assistant[0].(map[string]any)["reasoningContent"].(map[string]any)["reasoningText"].(map[string]any)
+	if reasoning["signature"] != "sig" {
+		t.Fatalf("reasoning was not sent back: %#v", assistant)
+	}
+	toolResult := messages[2].(map[string]any)["content"].([]any)[0].(map[string]any)["toolResult"].(map[string]any)
+	if toolResult["toolUseId"] != "t1" || toolResult["content"].([]any)[0].(map[string]any)["text"] != "sunny" {
+		t.Fatalf("unexpected tool result %#v", toolResult)
+	}
+}
+
+func TestChatStructuredOutput(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"output":{"message":{"role":"assistant","content":[{"toolUse":{"toolUseId":"t1","name":"answer","input":{"answer":"42"}}}]}},"stopReason":"tool_use"}`))
+	}))
+	defer server.Close()
+
+	result, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Answer?"}},
+		Output: &core.Schema{Name: "answer", Schema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"answer": map[string]any{"type": "string"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if result.Text != `{"answer":"42"}` || result.FinishReason != core.FinishReasonStop {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	choice := request["toolConfig"].(map[string]any)["toolChoice"].(map[string]any)
+	if choice["tool"].(map[string]any)["name"] != "answer" {
+		t.Fatalf("unexpected tool choice %#v", choice)
+	}
+}
+
+func TestChatUsesAPIKey(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer bedrock-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if r.Header.Get("X-Amz-Date") != "" {
+			t.Error("API key requests must not be signed")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"output":{"message":{"role":"assistant","content":[{"text":"ok"}]}},"stopReason":"end_turn"}`))
+	}))
+	defer server.Close()
+
+	adapter := New(testModel, WithRegion("us-east-1"), WithAPIKey("bedrock-key"), WithBaseURL(server.URL))
+	if _, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+	}); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
+
+func TestChatDecodesErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-amzn-RequestId", "req-err")
+		w.Header().Set("x-amzn-ErrorType", "ThrottlingException:http://internal.amazon.com/coral/com.amazon.bedrock/")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"message":"Too many requests, please wait before trying again."}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+	})
+	var rateLimit *core.RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("expected RateLimitError, got %T %v", err, err)
+	}
+	if rateLimit.RequestID != "req-err" || !strings.Contains(rateLimit.Message, "ThrottlingException") {
+		t.Fatalf("unexpected error %+v", rateLimit)
+	}
+}
+
+func TestChatRejectsThinkingForOtherModels(t *testing.T) {
+	t.Parallel()
+
+	adapter := New("amazon.nova-pro-v1:0", WithRegion("us-east-1"), WithCredentials("AKID", "secret", ""))
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		Thinking: "enabled",
+	})
+	if err == nil {
+		t.Fatal("expected error for thinking on a non-Anthropic model")
+	}
+}
@@ -0,0 +1,705 @@
+package bedrock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// toMessagesAndSystem converts params into Converse messages and system
+// blocks. System-role messages join the system prompts, and consecutive
+// messages of the same role are merged, since Converse requires user and
+// assistant turns to alternate.
+func toMessagesAndSystem(params *core.ChatParams) ([]message, []systemBlock, error) {
+	if params == nil {
+		return nil, nil, errors.New("bedrock: chat params are required")
+	}
+
+	system := make([]systemBlock, 0, len(params.SystemPrompts))
+	for _, prompt := range params.SystemPrompts {
+		if prompt = strings.TrimSpace(prompt); prompt != "" {
+			system = append(system, systemBlock{Text: prompt})
+		}
+	}
+
+	messages := make([]message, 0, len(params.Messages))
+	for i, union := range params.Messages {
+		msg, systemText, err := toMessage(union)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bedrock: invalid message at index %d: %w", i, err)
+		}
+		if msg == nil {
+			if systemText = strings.TrimSpace(systemText); systemText != "" {
+				system = append(system, systemBlock{Text: systemText})
+			}
+			continue
+		}
+		if messageCacheControl(union) != nil {
+			msg.Content = append(msg.Content, contentBlock{CachePoint: &cachePoint{Type: "default"}})
+		}
+
+		if last := len(messages) - 1; last >= 0 && messages[last].Role == msg.Role {
+			messages[last].Content = append(messages[last].Content, msg.Content...)
+			continue
+		}
+		messages = append(messages, *msg)
+	}
+
+	if params.CacheSystemPrompt != nil && len(system) > 0 {
+		system = append(system, systemBlock{CachePoint: &cachePoint{Type: "default"}})
+	}
+
+	return messages, system, nil
+}
+
+func messageCacheControl(union core.MessageUnion) *core.CacheControl {
+	switch msg := union.(type) {
+	case core.TextMessagePart:
+		return msg.CacheControl
+	case *core.TextMessagePart:
+		if msg != nil {
+			return msg.CacheControl
+		}
+	case core.ContentMessagePart:
+		return msg.CacheControl
+	case *core.ContentMessagePart:
+		if msg != nil {
+			return msg.CacheControl
+		}
+	case core.ToolResultMessagePart:
+		return msg.CacheControl
+	case *core.ToolResultMessagePart:
+		if msg != nil {
+			return msg.CacheControl
+		}
+	}
+	return nil
+}
+
+func toMessage(union core.MessageUnion) (*message, string, error) {
+	switch msg := union.(type) {
+	case core.TextMessagePart:
+		return textMessage(msg.Role, msg.Content)
+	case *core.TextMessagePart:
+		if msg == nil {
+			return nil, "", errors.New("text message is nil")
+		}
+		return textMessage(msg.Role, msg.Content)
+
+	case core.ContentMessagePart:
+		return contentMessage(msg.Role, msg.Parts)
+	case *core.ContentMessagePart:
+		if msg == nil {
+			return nil, "", errors.New("content message is nil")
+		}
+		return contentMessage(msg.Role, msg.Parts)
+
+	case core.AssistantToolCallMessagePart:
+		return assistantToolCallMessage(msg.Role, msg.ToolCalls, msg.ReasoningBlocks)
+	case *core.AssistantToolCallMessagePart:
+		if msg == nil {
+			return nil, "", errors.New("assistant tool call message is nil")
+		}
+		return assistantToolCallMessage(msg.Role, msg.ToolCalls, msg.ReasoningBlocks)
+
+	case core.ToolResultMessagePart:
+		return toolResultMessage(msg.Role, msg.ToolCallID, msg.Content)
+	case *core.ToolResultMessagePart:
+		if msg == nil {
+			return nil, "", errors.New("tool result message is nil")
+		}
+		return toolResultMessage(msg.Role, msg.ToolCallID, msg.Content)
+	}
+
+	return nil, "", fmt.Errorf("unsupported message type %T", union)
+}
+
+func textMessage(role, content string) (*message, string, error) {
+	normalizedRole, err := normalizeRole(role)
+	if err != nil {
+		return nil, "", err
+	}
+	if normalizedRole == core.RoleSystem {
+		return nil, content, nil
+	}
+	if content == "" {
+		return nil, "", errors.New("text message content is required")
+	}
+
+	return &message{Role: normalizedRole, Content: []contentBlock{{Text: content}}}, "", nil
+}
+
+func contentMessage(role string, parts []core.ContentPart) (*message, string, error) {
+	normalizedRole, err := normalizeRole(role)
+	if err != nil {
+		return nil, "", err
+	}
+	if normalizedRole == core.RoleSystem {
+		return nil, "", errors.New("content messages cannot use system role")
+	}
+	if len(parts) == 0 {
+		return nil, "", errors.New("content message must include at least one content part")
+	}
+
+	blocks := make([]contentBlock, 0, len(parts))
+	for i, part := range parts {
+		block, ok, err := toContentBlock(part, i)
+		if err != nil {
+			return nil, "", fmt.Errorf("content part at index %d: %w", i, err)
+		}
+		if ok {
+			blocks = append(blocks, block)
+		}
+	}
+	if len(blocks) == 0 {
+		return nil, "", errors.New("content message has no content for bedrock")
+	}
+
+	return &message{Role: normalizedRole, Content: blocks}, "", nil
+}
+
+// toContentBlock converts one content part. It reports false for parts that
+// are skipped, such as raw parts for other providers.
+func toContentBlock(part core.ContentPart, index int) (contentBlock, bool, error) {
+	switch typed := part.(type) {
+	case core.TextPart:
+		return contentBlock{Text: typed.Text}, typed.Text != "", nil
+	case *core.TextPart:
+		if typed == nil {
+			return contentBlock{}, false, errors.New("text part is nil")
+		}
+		return toContentBlock(*typed, index)
+
+	case core.ImagePart:
+		image, err := imageFromSource(typed.Source)
+		if err != nil {
+			return contentBlock{}, false, err
+		}
+		return contentBlock{Image: image}, true, nil
+	case *core.ImagePart:
+		if typed == nil {
+			return contentBlock{}, false, errors.New("image part is nil")
+		}
+		return toContentBlock(*typed, index)
+
+	case core.DocumentPart:
+		document, err := documentFromSource(typed.Source, typed.Metadata, index)
+		if err != nil {
+			return contentBlock{}, false, err
+		}
+		return contentBlock{Document: document}, true, nil
+	case *core.DocumentPart:
+		if typed == nil {
+			return contentBlock{}, false, errors.New("document part is nil")
+		}
+		return toContentBlock(*typed, index)
+
+	case core.RawPart:
+		if !strings.EqualFold(strings.TrimSpace(typed.Provider), providerName) {
+			return contentBlock{}, false, nil
+		}
+		if !json.Valid(typed.Payload) || !bytes.HasPrefix(bytes.TrimSpace(typed.Payload), []byte("{")) {
+			return contentBlock{}, false, errors.New("bedrock: raw part payload must be a JSON object")
+		}
+		return contentBlock{Raw: typed.Payload}, true, nil
+	case *core.RawPart:
+		if typed == nil {
+			return contentBlock{}, false, errors.New("raw part is nil")
+		}
+		return toContentBlock(*typed, index)
+
+	case core.AudioPart, *core.AudioPart:
+		return contentBlock{}, false, errors.New("bedrock: audio content is not supported")
+	case core.FilePart, *core.FilePart:
+		return contentBlock{}, false, errors.New("bedrock: file content is not supported; use a DocumentPart")
+	case core.SearchResultPart, *core.SearchResultPart:
+		return contentBlock{}, false, errors.New("bedrock: search result content is not supported")
+	}
+
+	return contentBlock{}, false, fmt.Errorf("unsupported content part type %T", part)
+}
+
+func imageFromSource(source core.Source) (*imageBlock, error) {
+	mediaSource, mimeType, err := toMediaSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	format := strings.TrimPrefix(mimeType, "image/")
+	switch format {
+	case "png", "jpeg", "gif", "webp":
+	case "jpg":
+		format = "jpeg"
+	default:
+		return nil, fmt.Errorf("bedrock: unsupported image type %q", mimeType)
+	}
+
+	return &imageBlock{Format: format, Source: mediaSource}, nil
+}
+
+func documentFromSource(source core.Source, metadata map[string]any, index int) (*documentBlock, error) {
+	mediaSource, mimeType, err := toMediaSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	format, ok := documentFormats[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("bedrock: unsupported document type %q", mimeType)
+	}
+
+	// Document names may only hold letters, digits, whitespace, hyphens,
+	// parentheses, and square brackets.
+	name := metadataString(metadata, "name")
+	if name == "" {
+		name = metadataString(metadata, "title")
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '-' || r == '(' || r == ')' || r == '[' || r == ']' || r == ' ' ||
+			('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return ' '
+	}, name)
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		name = "document-" + strconv.Itoa(index+1)
+	}
+
+	return &documentBlock{Format: format, Name: name, Source: mediaSource}, nil
+}
+
+var documentFormats = map[string]string{
+	"application/pdf":    "pdf",
+	"text/csv":           "csv",
+	"application/msword": "doc",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": "docx",
+	"application/vnd.ms-excel": "xls",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": "xlsx",
+	"text/html":     "html",
+	"text/plain":    "txt",
+	"text/markdown": "md",
+}
+
+func metadataString(metadata map[string]any, key string) string {
+	value, _ := metadata[key].(string)
+	return strings.TrimSpace(value)
+}
+
+// toMediaSource converts inline data or an s3:// URL. Converse does not
+// download other URLs.
+func toMediaSource(source core.Source) (mediaSource, string, error) {
+	switch typed := source.(type) {
+	case core.DataSource:
+		data := strings.TrimSpace(typed.Data)
+		if data == "" {
+			return mediaSource{}, "", errors.New("source data is required")
+		}
+		if strings.HasPrefix(strings.ToLower(data), "data:") {
+			return mediaSource{}, "", errors.New("source data must be raw base64")
+		}
+		mimeType := strings.ToLower(strings.TrimSpace(typed.MimeType))
+		if mimeType == "" {
+			return mediaSource{}, "", errors.New("source mime type is required")
+		}
+		return mediaSource{Bytes: data}, mimeType, nil
+	case *core.DataSource:
+		if typed == nil {
+			return mediaSource{}, "", errors.New("data source is nil")
+		}
+		return toMediaSource(*typed)
+
+	case core.URLSource:
+		url := strings.TrimSpace(typed.URL)
+		if !strings.HasPrefix(url, "s3://") {
+			return mediaSource{}, "", fmt.Errorf("bedrock: only s3:// URLs are supported, got %q", url)
+		}
+		mimeType := strings.ToLower(strings.TrimSpace(typed.MimeType))
+		if mimeType == "" {
+			return mediaSource{}, "", errors.New("source mime type is required for s3:// URLs")
+		}
+		return mediaSource{S3Location: &s3Location{URI: url}}, mimeType, nil
+	case *core.URLSource:
+		if typed == nil {
+			return mediaSource{}, "", errors.New("URL source is nil")
+		}
+		return toMediaSource(*typed)
+	case nil:
+		return mediaSource{}, "", errors.New("source is required")
+	}
+
+	return mediaSource{}, "", fmt.Errorf("unsupported source type %T", source)
+}
+
+func assistantToolCallMessage(role string, calls []core.ToolCall, reasoning []core.ReasoningBlock) (*message, string, error) {
+	role = strings.TrimSpace(strings.ToLower(role))
+	if role == "" {
+		role = core.RoleToolCall
+	}
+	if role != core.RoleToolCall && role != core.RoleAssistant {
+		return nil, "", fmt.Errorf("tool call message role must be %q or %q, got %q", core.RoleToolCall, core.RoleAssistant, role)
+	}
+	if len(calls) == 0 {
+		return nil, "", errors.New("assistant tool call message must include at least one tool call")
+	}
+
+	blocks := make([]contentBlock, 0, len(reasoning)+len(calls))
+	for _, block := range reasoning {
+		switch block.Type {
+		case "thinking":
+			blocks = append(blocks, contentBlock{ReasoningContent: &reasoningContent{
+				ReasoningText: &reasoningText{Text: block.Text, Signature: block.Signature},
+			}})
+		case "redacted_thinking":
+			blocks = append(blocks, contentBlock{ReasoningContent: &reasoningContent{RedactedContent: block.Data}})
+		}
+	}
+
+	for i, call := range calls {
+		name := strings.TrimSpace(call.Name)
+		if name == "" {
+			return nil, "", fmt.Errorf("tool call at index %d is missing a name", i)
+		}
+
+		id := strings.TrimSpace(call.ID)
+		if id == "" {
+			id = fmt.Sprintf("call_%d", i+1)
+		}
+
+		input := call.Arguments
+		if input == nil {
+			input = map[string]any{}
+		}
+
+		blocks = append(blocks, contentBlock{ToolUse: &toolUseBlock{ToolUseID: id, Name: name, Input: input}})
+	}
+
+	return &message{Role: core.RoleAssistant, Content: blocks}, "", nil
+}
+
+func toolResultMessage(role, toolCallID, content string) (*message, string, error) {
+	role = strings.TrimSpace(strings.ToLower(role))
+	if role == "" {
+		role = core.RoleToolResult
+	}
+	if role != core.RoleToolResult && role != "tool" && role != core.RoleUser {
+		return nil, "", fmt.Errorf("tool result message role must be %q, %q, or %q, got %q", core.RoleToolResult, "tool", core.RoleUser, role)
+	}
+	if strings.TrimSpace(toolCallID) == "" {
+		return nil, "", errors.New("tool result message tool call ID is required")
+	}
+
+	return &message{
+		Role:    core.RoleUser,
+		Content: []contentBlock{toolResultBlockFor(strings.TrimSpace(toolCallID), content)},
+	}, "", nil
+}
+
+// toolResultBlockFor wraps a tool result. Results of failed server tools,
+// which start with "tool_error: ", are marked as errors.
+func toolResultBlockFor(toolUseID, content string) contentBlock {
+	result := &toolResultBlock{ToolUseID: toolUseID, Content: []toolResultContent{{Text: content}}}
+	if strings.HasPrefix(content, "tool_error: ") {
+		result.Status = "error"
+	}
+	return contentBlock{ToolResult: result}
+}
+
+func normalizeRole(role string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(role))
+	switch normalized {
+	case "":
+		return "", errors.New("message role is required")
+	case core.RoleSystem, core.RoleUser, core.RoleAssistant:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("unsupported role %q", role)
+	}
+}
+
+func toTools(params *core.ChatParams) ([]tool, map[string]core.ServerTool, map[string]struct{}, error) {
+	if params == nil || len(params.Tools) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	tools := make([]tool, 0, len(params.Tools))
+	serverTools := make(map[string]core.ServerTool)
+	clientTools := make(map[string]struct{})
+	seenNames := make(map[string]struct{})
+
+	for i, union := range params.Tools {
+		switch toolValue := union.(type) {
+		case core.ServerTool:
+			definition, serverTool, err := newServerTool(toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("bedrock: invalid server tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, serverTool.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			serverTools[serverTool.Name] = serverTool
+
+		case *core.ServerTool:
+			if toolValue == nil {
+				return nil, nil, nil, fmt.Errorf("bedrock: server tool at index %d is nil", i)
+			}
+			definition, serverTool, err := newServerTool(*toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("bedrock: invalid server tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, serverTool.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			serverTools[serverTool.Name] = serverTool
+
+		case core.ClientTool:
+			definition, err := newClientTool(toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("bedrock: invalid client tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, definition.ToolSpec.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			clientTools[definition.ToolSpec.Name] = struct{}{}
+
+		case *core.ClientTool:
+			if toolValue == nil {
+				return nil, nil, nil, fmt.Errorf("bedrock: client tool at index %d is nil", i)
+			}
+			definition, err := newClientTool(*toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("bedrock: invalid client tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, definition.ToolSpec.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			clientTools[definition.ToolSpec.Name] = struct{}{}
+
+		default:
+			return nil, nil, nil, fmt.Errorf("bedrock: unsupported tool type %T", union)
+		}
+	}
+
+	return tools, serverTools, clientTools, nil
+}
+
+func newServerTool(toolValue core.ServerTool) (tool, core.ServerTool, error) {
+	name := strings.TrimSpace(toolValue.Name)
+	if name == "" {
+		return tool{}, core.ServerTool{}, errors.New("tool name is required")
+	}
+	if toolValue.Handler == nil {
+		return tool{}, core.ServerTool{}, fmt.Errorf("tool %q handler is required", name)
+	}
+
+	toolValue.Name = name
+	return newToolDefinition(name, toolValue.Description, toolValue.Parameters), toolValue, nil
+}
+
+func newClientTool(toolValue core.ClientTool) (tool, error) {
+	name := strings.TrimSpace(toolValue.Name)
+	if name == "" {
+		return tool{}, errors.New("tool name is required")
+	}
+
+	return newToolDefinition(name, toolValue.Description, toolValue.Parameters), nil
+}
+
+func newToolDefinition(name, description string, parameters map[string]any) tool {
+	if parameters == nil {
+		parameters = map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		}
+	}
+
+	return tool{ToolSpec: &toolSpec{
+		Name:        name,
+		Description: description,
+		InputSchema: inputSchema{JSON: parameters},
+	}}
+}
+
+func assertNewToolName(seen map[string]struct{}, name string) error {
+	if _, exists := seen[name]; exists {
+		return fmt.Errorf("bedrock: duplicate tool name %q", name)
+	}
+	seen[name] = struct{}{}
+	return nil
+}
+
+// outputTool defines the synthetic tool that carries structured output. The
+// schema name is used as the tool name when it is a valid tool name.
+func outputTool(output *core.Schema) tool {
+	name := strings.TrimSpace(output.Name)
+	if !isValidToolName(name) {
+		name = defaultOutputToolName
+	}
+
+	return newToolDefinition(name, "Respond with the final answer by calling this tool. Its input is the structured response.", output.Schema)
+}
+
+func isValidToolName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r == '-' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// requestToolChoice converts the tool choice. Converse has no "none" choice,
+// so ToolChoiceNone is honored by not offering tools; ToolChoiceRequired maps
+// to "any".
+func requestToolChoice(params *core.ChatParams) (*toolChoice, bool, error) {
+	if params == nil || params.ToolChoice == nil {
+		return nil, true, nil
+	}
+
+	if name := strings.TrimSpace(params.ToolChoice.Name); name != "" {
+		return &toolChoice{Tool: &toolChoiceName{Name: name}}, true, nil
+	}
+
+	switch mode := strings.TrimSpace(params.ToolChoice.Mode); mode {
+	case "", core.ToolChoiceAuto:
+		return &toolChoice{Auto: &struct{}{}}, true, nil
+	case core.ToolChoiceNone:
+		return nil, false, nil
+	case core.ToolChoiceRequired:
+		return &toolChoice{Any: &struct{}{}}, true, nil
+	default:
+		return nil, false, fmt.Errorf("bedrock: unsupported tool choice mode %q", mode)
+	}
+}
+
+func toInferenceConfig(params *core.ChatParams) *inferenceConfig {
+	if params == nil {
+		return nil
+	}
+
+	config := inferenceConfig{
+		MaxTokens:   maxTokens(params),
+		Temperature: params.Temperature,
+		TopP:        params.TopP,
+	}
+	for _, stop := range params.StopSequences {
+		if stop != "" {
+			config.StopSequences = append(config.StopSequences, stop)
+		}
+	}
+	if config.MaxTokens == nil && config.Temperature == nil && config.TopP == nil && len(config.StopSequences) == 0 {
+		return nil
+	}
+	return &config
+}
+
+func maxTokens(params *core.ChatParams) *int64 {
+	if params.MaxTokens != nil && *params.MaxTokens > 0 {
+		return params.MaxTokens
+	}
+	if params.MaxOutputTokens != nil && *params.MaxOutputTokens > 0 {
+		return params.MaxOutputTokens
+	}
+	if params.MaxLength > 0 {
+		value := params.MaxLength
+		return &value
+	}
+	return nil
+}
+
+// additionalFields returns the model-specific request fields: top_k, the
+// extended thinking budget, and ModelOptions, which are passed through as is.
+func additionalFields(params *core.ChatParams, budget int64) map[string]any {
+	if params == nil {
+		return nil
+	}
+
+	fields := map[string]any{}
+	if params.TopK != nil {
+		fields["top_k"] = *params.TopK
+	}
+	if budget > 0 {
+		fields["thinking"] = map[string]any{"type": "enabled", "budget_tokens": budget}
+	}
+	for key, value := range params.ModelOptions {
+		if key = strings.TrimSpace(key); key != "" && value != nil {
+			fields[key] = value
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// thinkingBudget derives the extended thinking budget from
+// ChatParams.Thinking, falling back to ChatParams.ReasoningEffort. Thinking
+// accepts "true"/"enabled", "false"/"disabled", an effort level ("low",
+// "medium", "high"), or an explicit budget such as "8192".
+func thinkingBudget(params *core.ChatParams) (int64, error) {
+	if params == nil {
+		return 0, nil
+	}
+
+	raw := strings.ToLower(strings.TrimSpace(params.Thinking))
+	switch raw {
+	case "":
+		return thinkingBudgetForEffort(strings.ToLower(strings.TrimSpace(params.ReasoningEffort))), nil
+	case "false", "disabled", "off", "none":
+		return 0, nil
+	case "true", "enabled", "on":
+		return defaultThinkingBudget, nil
+	}
+
+	if budget := thinkingBudgetForEffort(raw); budget > 0 {
+		return budget, nil
+	}
+
+	budget, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || budget <= 0 {
+		return 0, fmt.Errorf("bedrock: unsupported thinking value %q", params.Thinking)
+	}
+	return max(budget, minThinkingBudget), nil
+}
+
+func thinkingBudgetForEffort(effort string) int64 {
+	switch effort {
+	case "minimal", "low":
+		return minThinkingBudget
+	case "medium":
+		return defaultThinkingBudget
+	case "high":
+		return 16384
+	default:
+		return 0
+	}
+}
+
+// isAnthropicModel reports whether model is an Anthropic model ID, including
+// cross-region inference profiles such as "us.anthropic.claude-...".
+func isAnthropicModel(model string) bool {
+	return strings.Contains(strings.ToLower(model), "anthropic.")
+}
+
+func maxLoops(params *core.ChatParams, hasServerTools bool) int {
+	if !hasServerTools {
+		return 1
+	}
+	if params != nil && params.MaxAgenticLoops > 0 {
+		return int(params.MaxAgenticLoops)
+	}
+	return defaultMaxAgenticLoops
+}
@@ -0,0 +1,154 @@
+package bedrock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// defaultCohereInputType is sent to Cohere embedding models unless
+// ModelOptions sets "input_type", such as "search_query".
+const defaultCohereInputType = "search_document"
+
+// Embed creates one embedding vector for params.Input.
+func (a *Adapter) Embed(ctx context.Context, params *core.EmbedParams) (*core.EmbedResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("bedrock: embed params are required")
+	}
+
+	input := strings.TrimSpace(params.Input)
+	if input == "" {
+		return nil, errors.New("bedrock: embed input is required")
+	}
+
+	vectors, usage, err := a.embed(ctx, []string{input}, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.EmbedResult{Embedding: vectors[0], Usage: usage}, nil
+}
+
+// EmbedMany creates embedding vectors for params.Inputs. Titan models embed
+// one input per request, so EmbedMany sends one request per input for them.
+func (a *Adapter) EmbedMany(ctx context.Context, params *core.EmbedManyParams) (*core.EmbedManyResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("bedrock: embed many params are required")
+	}
+	if len(params.Inputs) == 0 {
+		return nil, errors.New("bedrock: embed many inputs are required")
+	}
+
+	inputs := make([]string, 0, len(params.Inputs))
+	for i, input := range params.Inputs {
+		trimmed := strings.TrimSpace(input)
+		if trimmed == "" {
+			return nil, fmt.Errorf("bedrock: embed many input at index %d is empty", i)
+		}
+		inputs = append(inputs, trimmed)
+	}
+
+	vectors, usage, err := a.embed(ctx, inputs, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.EmbedManyResult{Embeddings: vectors, Usage: usage}, nil
+}
+
+func (a *Adapter) embed(ctx context.Context, inputs []string, dimensions *int64, options map[string]any) ([][]float64, *core.Usage, error) {
+	if dimensions != nil && *dimensions <= 0 {
+		return nil, nil, errors.New("bedrock: embed dimensions must be greater than zero")
+	}
+
+	var (
+		vectors [][]float64
+		usage   *core.Usage
+		err     error
+	)
+	if isCohereEmbedModel(a.Model) {
+		vectors, err = a.embedCohere(ctx, inputs, options)
+	} else {
+		vectors, usage, err = a.embedTitan(ctx, inputs, dimensions, options)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(vectors) != len(inputs) {
+		return nil, nil, fmt.Errorf("bedrock: embeddings response count mismatch: expected %d, got %d", len(inputs), len(vectors))
+	}
+	if dimensions != nil {
+		for i, vector := range vectors {
+			if int64(len(vector)) != *dimensions {
+				return nil, nil, &core.EmbeddingDimensionError{Expected: *dimensions, Actual: int64(len(vector)), Index: i}
+			}
+		}
+	}
+	return vectors, usage, nil
+}
+
+// embedTitan embeds inputs one at a time and sums the reported input tokens.
+func (a *Adapter) embedTitan(ctx context.Context, inputs []string, dimensions *int64, options map[string]any) ([][]float64, *core.Usage, error) {
+	var normalize *bool
+	if value, ok := options["normalize"].(bool); ok {
+		normalize = &value
+	}
+
+	vectors := make([][]float64, 0, len(inputs))
+	usage := &core.Usage{}
+	for _, input := range inputs {
+		var response titanEmbedResponse
+		request := titanEmbedRequest{InputText: input, Dimensions: dimensions, Normalize: normalize}
+		if err := a.invoke(ctx, &request, &response); err != nil {
+			return nil, nil, err
+		}
+		if len(response.Embedding) == 0 {
+			return nil, nil, errors.New("bedrock: embeddings response did not include a vector")
+		}
+		vectors = append(vectors, response.Embedding)
+		usage.PromptTokens += response.InputTextTokenCount
+	}
+	usage.TotalTokens = usage.PromptTokens
+	return vectors, usage, nil
+}
+
+func (a *Adapter) embedCohere(ctx context.Context, inputs []string, options map[string]any) ([][]float64, error) {
+	request := cohereEmbedRequest{Texts: inputs, InputType: defaultCohereInputType}
+	if value, ok := options["input_type"].(string); ok && strings.TrimSpace(value) != "" {
+		request.InputType = strings.TrimSpace(value)
+	}
+	if value, ok := options["truncate"].(string); ok {
+		request.Truncate = strings.TrimSpace(value)
+	}
+
+	var response cohereEmbedResponse
+	if err := a.invoke(ctx, &request, &response); err != nil {
+		return nil, err
+	}
+	return response.Embeddings, nil
+}
+
+func (a *Adapter) invoke(ctx context.Context, body, out any) error {
+	apiRequest, err := a.request(a.modelPath("invoke"), "invoke", body)
+	if err != nil {
+		return err
+	}
+	_, err = a.transport().Send(ctx, apiRequest, out)
+	return err
+}
+
+// isCohereEmbedModel reports whether model is a Cohere embedding model, such
+// as "cohere.embed-english-v3"; other models use the Titan request format.
+func isCohereEmbedModel(model string) bool {
+	return strings.Contains(strings.ToLower(model), "cohere.embed")
+}
@@ -0,0 +1,78 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestEmbedManyTitanSendsOneRequestPerInput(t *testing.T) {
+	t.Parallel()
+
+	var inputs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/model/amazon.titan-embed-text-v2%3A0/invoke" {
+			t.Errorf("unexpected path %q", r.URL.EscapedPath())
+		}
+		var request titanEmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if request.Dimensions == nil || *request.Dimensions != 2 {
+			t.Errorf("unexpected dimensions %v", request.Dimensions)
+		}
+		inputs = append(inputs, request.InputText)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"embedding":[0.1,0.2],"inputTextTokenCount":3}`))
+	}))
+	defer server.Close()
+
+	dimensions := int64(2)
+	adapter := New("amazon.titan-embed-text-v2:0", WithRegion("us-east-1"), WithCredentials("AKID", "secret", ""), WithBaseURL(server.URL))
+	result, err := adapter.EmbedMany(context.Background(), &core.EmbedManyParams{Inputs: []string{"a", "b"}, Dimensions: &dimensions})
+	if err != nil {
+		t.Fatalf("EmbedMany() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(inputs, []string{"a", "b"}) || len(result.Embeddings) != 2 {
+		t.Fatalf("unexpected inputs %v or result %+v", inputs, result)
+	}
+	if result.Usage == nil || result.Usage.PromptTokens != 6 {
+		t.Fatalf("unexpected usage %+v", result.Usage)
+	}
+}
+
+func TestEmbedCohereBatchesInputs(t *testing.T) {
+	t.Parallel()
+
+	var request cohereEmbedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"embeddings":[[0.1,0.2],[0.3,0.4]]}`))
+	}))
+	defer server.Close()
+
+	adapter := New("cohere.embed-english-v3", WithRegion("us-east-1"), WithCredentials("AKID", "secret", ""), WithBaseURL(server.URL))
+	result, err := adapter.EmbedMany(context.Background(), &core.EmbedManyParams{
+		Inputs:       []string{"a", "b"},
+		ModelOptions: map[string]any{"input_type": "search_query"},
+	})
+	if err != nil {
+		t.Fatalf("EmbedMany() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(request.Texts, []string{"a", "b"}) || request.InputType != "search_query" {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	if !reflect.DeepEqual(result.Embeddings, [][]float64{{0.1, 0.2}, {0.3, 0.4}}) {
+		t.Fatalf("unexpected embeddings %v", result.Embeddings)
+	}
+}
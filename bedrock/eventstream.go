@@ -0,0 +1,113 @@
+package bedrock
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// maxEventSize limits a single event stream message.
+const maxEventSize = 16 << 20
+
+// eventMessage is one message of an application/vnd.amazon.eventstream
+// response. Only string headers are kept.
+type eventMessage struct {
+	Headers map[string]string
+	Payload []byte
+}
+
+// eventReader decodes the binary event stream framing used by
+// ConverseStream: a prelude with the total and header lengths and its CRC,
+// the headers, the payload, and a CRC of the whole message.
+type eventReader struct {
+	r       io.Reader
+	prelude [12]byte
+}
+
+func newEventReader(r io.Reader) *eventReader {
+	return &eventReader{r: r}
+}
+
+// Next returns the next message, or io.EOF at the end of the stream.
+func (e *eventReader) Next() (*eventMessage, error) {
+	if _, err := io.ReadFull(e.r, e.prelude[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errors.New("truncated event prelude")
+		}
+		return nil, err
+	}
+
+	totalLength := binary.BigEndian.Uint32(e.prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(e.prelude[4:8])
+	if crc32.ChecksumIEEE(e.prelude[:8]) != binary.BigEndian.Uint32(e.prelude[8:12]) {
+		return nil, errors.New("event prelude checksum mismatch")
+	}
+	if totalLength < 16 || totalLength > maxEventSize || headersLength > totalLength-16 {
+		return nil, fmt.Errorf("invalid event length %d", totalLength)
+	}
+
+	message := make([]byte, totalLength)
+	copy(message, e.prelude[:])
+	if _, err := io.ReadFull(e.r, message[12:]); err != nil {
+		return nil, fmt.Errorf("truncated event: %w", err)
+	}
+	end := totalLength - 4
+	if crc32.ChecksumIEEE(message[:end]) != binary.BigEndian.Uint32(message[end:]) {
+		return nil, errors.New("event checksum mismatch")
+	}
+
+	headers, err := decodeEventHeaders(message[12 : 12+headersLength])
+	if err != nil {
+		return nil, err
+	}
+	return &eventMessage{Headers: headers, Payload: message[12+headersLength : end]}, nil
+}
+
+// decodeEventHeaders decodes header entries of a name length byte, the name,
+// a value type byte, and the value. Values of types other than string are
+// skipped.
+func decodeEventHeaders(data []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	for len(data) > 0 {
+		nameLength := int(data[0])
+		if len(data) < 1+nameLength+1 {
+			return nil, errors.New("truncated event header")
+		}
+		name := string(data[1 : 1+nameLength])
+		valueType := data[1+nameLength]
+		data = data[2+nameLength:]
+
+		var size int
+		switch valueType {
+		case 0, 1: // true, false
+			size = 0
+		case 2: // byte
+			size = 1
+		case 3: // short
+			size = 2
+		case 4: // int
+			size = 4
+		case 5, 8: // long, timestamp
+			size = 8
+		case 9: // uuid
+			size = 16
+		case 6, 7: // bytes, string
+			if len(data) < 2 {
+				return nil, errors.New("truncated event header")
+			}
+			size = 2 + int(binary.BigEndian.Uint16(data))
+		default:
+			return nil, fmt.Errorf("unknown event header type %d", valueType)
+		}
+		if len(data) < size {
+			return nil, errors.New("truncated event header")
+		}
+		if valueType == 7 {
+			headers[name] = string(data[2:size])
+		}
+		data = data[size:]
+	}
+	return headers, nil
+}
@@ -0,0 +1,49 @@
+package bedrock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func init() {
+	core.RegisterProvider(providerName, newFromConfig)
+}
+
+// newFromConfig builds an adapter for core.NewAdapter. It supports the
+// options timeout, region, access_key_id, secret_access_key, and
+// session_token. The API key is used as a Bedrock API key.
+func newFromConfig(config core.ProviderConfig) (core.TextAdapter, error) {
+	opts := []Option{WithBaseURL(config.BaseURL)}
+	if config.APIKey != "" {
+		opts = append(opts, WithAPIKey(config.APIKey))
+	}
+
+	var credentials Credentials
+	for name, value := range config.Options {
+		switch name {
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			opts = append(opts, WithTimeout(timeout))
+		case "region":
+			opts = append(opts, WithRegion(value))
+		case "access_key_id":
+			credentials.AccessKeyID = value
+		case "secret_access_key":
+			credentials.SecretAccessKey = value
+		case "session_token":
+			credentials.SessionToken = value
+		default:
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	if credentials != (Credentials{}) {
+		opts = append(opts, WithCredentials(credentials.AccessKeyID, credentials.SecretAccessKey, credentials.SessionToken))
+	}
+
+	return New(config.Model, opts...), nil
+}
@@ -0,0 +1,27 @@
+package bedrock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestFromDSN(t *testing.T) {
+	t.Parallel()
+
+	adapter, err := core.FromDSN("bedrock://anthropic.claude-3-5-haiku-20241022-v1:0?region=eu-central-1&access_key_id=AKID&secret_access_key=secret&session_token=token&timeout=30s")
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	bedrock := adapter.(*Adapter)
+	if bedrock.Model != "anthropic.claude-3-5-haiku-20241022-v1:0" || bedrock.Region != "eu-central-1" || bedrock.HTTPClient.Timeout != 30*time.Second {
+		t.Fatalf("adapter = %+v", bedrock)
+	}
+	if bedrock.Credentials != (Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}) {
+		t.Fatalf("credentials = %+v", bedrock.Credentials)
+	}
+	if _, err := core.FromDSN("bedrock://amazon.nova-lite-v1:0?profile=default"); err == nil {
+		t.Fatal("FromDSN() expected error for an unknown option")
+	}
+}
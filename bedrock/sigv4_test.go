@@ -0,0 +1,55 @@
+package bedrock
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignRequestVanilla checks the get-vanilla case of the AWS Signature
+// Version 4 test suite.
+func TestSignRequestVanilla(t *testing.T) {
+	t.Parallel()
+
+	target, err := url.Parse("https://example.amazonaws.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := http.Header{}
+	credentials := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signRequest(header, http.MethodGet, target, nil, credentials, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := header.Get("Authorization"); got != want {
+		t.Fatalf("Authorization = %q, want %q", got, want)
+	}
+	if got := header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Fatalf("X-Amz-Date = %q", got)
+	}
+}
+
+func TestSignRequestSessionTokenAndEscapedPath(t *testing.T) {
+	t.Parallel()
+
+	target, err := url.Parse("https://bedrock-runtime.us-east-1.amazonaws.com/model/" + uriEncode("anthropic.claude-v2:1") + "/converse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := canonicalURI(target); got != "/model/anthropic.claude-v2%253A1/converse" {
+		t.Fatalf("canonicalURI() = %q", got)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	signRequest(header, http.MethodPost, target, []byte(`{}`), Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}, "us-east-1", "bedrock", time.Now())
+
+	if header.Get("X-Amz-Security-Token") != "token" {
+		t.Fatalf("missing session token header: %v", header)
+	}
+	want := "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,"
+	if got := header.Get("Authorization"); !strings.Contains(got, want) {
+		t.Fatalf("Authorization = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// encodeEvent frames payload as one event stream message with string headers.
+func encodeEvent(headers map[string]string, payload string) []byte {
+	var encoded bytes.Buffer
+	for _, name := range []string{":message-type", ":event-type", ":exception-type", ":content-type"} {
+		value, ok := headers[name]
+		if !ok {
+			continue
+		}
+		encoded.WriteByte(byte(len(name)))
+		encoded.WriteString(name)
+		encoded.WriteByte(7)
+		_ = binary.Write(&encoded, binary.BigEndian, uint16(len(value)))
+		encoded.WriteString(value)
+	}
+
+	total := 12 + encoded.Len() + len(payload) + 4
+	message := make([]byte, 0, total)
+	message = binary.BigEndian.AppendUint32(message, uint32(total))
+	message = binary.BigEndian.AppendUint32(message, uint32(encoded.Len()))
+	message = binary.BigEndian.AppendUint32(message, crc32.ChecksumIEEE(message))
+	message = append(message, encoded.Bytes()...)
+	message = append(message, payload...)
+	return binary.BigEndian.AppendUint32(message, crc32.ChecksumIEEE(message))
+}
+
+func streamEvents(events ...[2]string) []byte {
+	var out []byte
+	for _, event := range events {
+		out = append(out, encodeEvent(map[string]string{":message-type": "event", ":event-type": event[0]}, event[1])...)
+	}
+	return out
+}
+
+func TestEventReaderRejectsCorruptMessages(t *testing.T) {
+	t.Parallel()
+
+	message := encodeEvent(map[string]string{":event-type": "messageStop"}, `{"stopReason":"end_turn"}`)
+	event, err := newEventReader(bytes.NewReader(message)).Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if event.Headers[":event-type"] != "messageStop" || string(event.Payload) != `{"stopReason":"end_turn"}` {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	message[len(message)-6] ^= 0xff
+	if _, err := newEventReader(bytes.NewReader(message)).Next(); err == nil {
+		t.Fatal("expected checksum error")
+	}
+	if _, err := newEventReader(bytes.NewReader(nil)).Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestChatStreamRunsToolLoop(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/model/anthropic.claude-3-5-sonnet-20240620-v1%3A0/converse-stream" {
+			t.Errorf("unexpected path %q", r.URL.EscapedPath())
+		}
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		w.Header().Set("x-amzn-RequestId", "req-stream")
+		calls++
+		if calls == 1 {
+			_, _ = w.Write(streamEvents(
+				[2]string{"messageStart", `{"role":"assistant"}`},
+				[2]string{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"reasoningContent":{"text":"check "}}}`},
+				[2]string{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"reasoningContent":{"text":"weather"}}}`},
+				[2]string{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"reasoningContent":{"signature":"sig"}}}`},
+				[2]string{"contentBlockStop", `{"contentBlockIndex":0}`},
+				[2]string{"contentBlockStart", `{"contentBlockIndex":1,"start":{"toolUse":{"toolUseId":"t1","name":"weather"}}}`},
+				[2]string{"contentBlockDelta", `{"contentBlockIndex":1,"delta":{"toolUse":{"input":"{\"city\":"}}}`},
+				[2]string{"contentBlockDelta", `{"contentBlockIndex":1,"delta":{"toolUse":{"input":"\"Berlin\"}"}}}`},
+				[2]string{"contentBlockStop", `{"contentBlockIndex":1}`},
+				[2]string{"messageStop", `{"stopReason":"tool_use"}`},
+			))
+			return
+		}
+		_, _ = w.Write(streamEvents(
+			[2]string{"messageStart", `{"role":"assistant"}`},
+			[2]string{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"Sun"}}`},
+			[2]string{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"ny."}}`},
+			[2]string{"contentBlockStop", `{"contentBlockIndex":0}`},
+			[2]string{"messageStop", `{"stopReason":"end_turn"}`},
+			[2]string{"metadata", `{"usage":{"inputTokens":20,"outputTokens":5,"totalTokens":25},"metrics":{"latencyMs":300}}`},
+		))
+	}))
+	defer server.Close()
+
+	var arguments any
+	stream, err := newTestAdapter(server.URL).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather?"}},
+		Tools: []core.ToolUnion{core.ServerTool{
+			Name: "weather",
+			Handler: func(args any) (string, error) {
+				arguments = args
+				return "sunny", nil
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var order []string
+	var done core.StreamChunk
+	content := ""
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkError:
+			t.Fatalf("unexpected chunk error: %s", chunk.Error)
+		case core.StreamChunkContent:
+			content = chunk.Content
+		case core.StreamChunkDone:
+			done = chunk
+		}
+		order = append(order, chunk.Type)
+	}
+
+	expected := []string{
+		core.StreamChunkReasoning,
+		core.StreamChunkReasoning,
+		core.StreamChunkToolCall,
+		core.StreamChunkToolResult,
+		core.StreamChunkContent,
+		core.StreamChunkContent,
+		core.StreamChunkDone,
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("unexpected chunk order: %#v", order)
+	}
+	if content != "Sunny." || arguments.(map[string]any)["city"] != "Berlin" {
+		t.Fatalf("unexpected content %q or arguments %#v", content, arguments)
+	}
+	if done.FinishReason != core.FinishReasonStop || done.Reasoning != "check weather" || done.RequestID != "req-stream" {
+		t.Fatalf("unexpected done chunk %+v", done)
+	}
+	if done.Usage == nil || done.Usage.TotalTokens != 25 || done.Usage.Details["latency_ms"] != 300 || done.Usage.RequestID != "req-stream" {
+		t.Fatalf("unexpected usage %+v", done.Usage)
+	}
+}
+
+func TestChatStreamReportsExceptions(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		w.Header().Set("x-amzn-RequestId", "req-throttled")
+		_, _ = w.Write(streamEvents([2]string{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"Hi"}}`}))
+		_, _ = w.Write(encodeEvent(map[string]string{":message-type": "exception", ":exception-type": "throttlingException"}, `{"message":"Too many tokens"}`))
+	}))
+	defer server.Close()
+
+	stream, err := newTestAdapter(server.URL).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var last core.StreamChunk
+	for chunk := range stream {
+		last = chunk
+	}
+	if last.Type != core.StreamChunkError || last.RequestID != "req-throttled" {
+		t.Fatalf("unexpected last chunk %+v", last)
+	}
+}
@@ -0,0 +1,207 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type converseRequest struct {
+	Messages                     []message        `json:"messages"`
+	System                       []systemBlock    `json:"system,omitempty"`
+	InferenceConfig              *inferenceConfig `json:"inferenceConfig,omitempty"`
+	ToolConfig                   *toolConfig      `json:"toolConfig,omitempty"`
+	AdditionalModelRequestFields map[string]any   `json:"additionalModelRequestFields,omitempty"`
+
+	// OutputTool is the synthetic tool that carries structured output.
+	OutputTool string `json:"-"`
+}
+
+type message struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+type systemBlock struct {
+	Text       string      `json:"text,omitempty"`
+	CachePoint *cachePoint `json:"cachePoint,omitempty"`
+}
+
+type cachePoint struct {
+	Type string `json:"type"`
+}
+
+type contentBlock struct {
+	Text             string            `json:"text,omitempty"`
+	Image            *imageBlock       `json:"image,omitempty"`
+	Document         *documentBlock    `json:"document,omitempty"`
+	ToolUse          *toolUseBlock     `json:"toolUse,omitempty"`
+	ToolResult       *toolResultBlock  `json:"toolResult,omitempty"`
+	ReasoningContent *reasoningContent `json:"reasoningContent,omitempty"`
+	CachePoint       *cachePoint       `json:"cachePoint,omitempty"`
+
+	// Raw replaces the whole block when set, for core.RawPart.
+	Raw json.RawMessage `json:"-"`
+}
+
+type imageBlock struct {
+	Format string      `json:"format"`
+	Source mediaSource `json:"source"`
+}
+
+type documentBlock struct {
+	Format string      `json:"format"`
+	Name   string      `json:"name"`
+	Source mediaSource `json:"source"`
+}
+
+// mediaSource holds base64 bytes or an S3 location.
+type mediaSource struct {
+	Bytes      string      `json:"bytes,omitempty"`
+	S3Location *s3Location `json:"s3Location,omitempty"`
+}
+
+type s3Location struct {
+	URI string `json:"uri"`
+}
+
+type toolUseBlock struct {
+	ToolUseID string `json:"toolUseId"`
+	Name      string `json:"name"`
+	Input     any    `json:"input"`
+}
+
+type toolResultBlock struct {
+	ToolUseID string              `json:"toolUseId"`
+	Content   []toolResultContent `json:"content"`
+	Status    string              `json:"status,omitempty"`
+}
+
+type toolResultContent struct {
+	Text string `json:"text"`
+}
+
+type reasoningContent struct {
+	ReasoningText   *reasoningText `json:"reasoningText,omitempty"`
+	RedactedContent string         `json:"redactedContent,omitempty"`
+}
+
+type reasoningText struct {
+	Text      string `json:"text"`
+	Signature string `json:"signature,omitempty"`
+}
+
+type inferenceConfig struct {
+	MaxTokens     *int64   `json:"maxTokens,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+type toolConfig struct {
+	Tools      []tool      `json:"tools"`
+	ToolChoice *toolChoice `json:"toolChoice,omitempty"`
+}
+
+type tool struct {
+	ToolSpec   *toolSpec   `json:"toolSpec,omitempty"`
+	CachePoint *cachePoint `json:"cachePoint,omitempty"`
+}
+
+type toolSpec struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	InputSchema inputSchema `json:"inputSchema"`
+}
+
+type inputSchema struct {
+	JSON map[string]any `json:"json"`
+}
+
+// toolChoice sets exactly one of its fields.
+type toolChoice struct {
+	Auto *struct{}       `json:"auto,omitempty"`
+	Any  *struct{}       `json:"any,omitempty"`
+	Tool *toolChoiceName `json:"tool,omitempty"`
+}
+
+type toolChoiceName struct {
+	Name string `json:"name"`
+}
+
+type converseResponse struct {
+	Output struct {
+		Message *message `json:"message,omitempty"`
+	} `json:"output"`
+	StopReason string   `json:"stopReason"`
+	Usage      *usage   `json:"usage,omitempty"`
+	Metrics    *metrics `json:"metrics,omitempty"`
+
+	RequestID string        `json:"-"`
+	Duration  time.Duration `json:"-"`
+}
+
+type usage struct {
+	InputTokens           int64 `json:"inputTokens"`
+	OutputTokens          int64 `json:"outputTokens"`
+	TotalTokens           int64 `json:"totalTokens"`
+	CacheReadInputTokens  int64 `json:"cacheReadInputTokens,omitempty"`
+	CacheWriteInputTokens int64 `json:"cacheWriteInputTokens,omitempty"`
+}
+
+type metrics struct {
+	LatencyMs int64 `json:"latencyMs"`
+}
+
+// streamEvent is the payload of one ConverseStream event. The event type
+// comes from the :event-type header, so one struct covers all of them.
+type streamEvent struct {
+	Role              string      `json:"role,omitempty"`
+	ContentBlockIndex int         `json:"contentBlockIndex"`
+	Start             *blockStart `json:"start,omitempty"`
+	Delta             *blockDelta `json:"delta,omitempty"`
+	StopReason        string      `json:"stopReason,omitempty"`
+	Usage             *usage      `json:"usage,omitempty"`
+	Metrics           *metrics    `json:"metrics,omitempty"`
+	Message           string      `json:"message,omitempty"`
+}
+
+type blockStart struct {
+	ToolUse *toolUseBlock `json:"toolUse,omitempty"`
+}
+
+type blockDelta struct {
+	Text             string              `json:"text,omitempty"`
+	ToolUse          *toolUseDelta       `json:"toolUse,omitempty"`
+	ReasoningContent *reasoningTextDelta `json:"reasoningContent,omitempty"`
+}
+
+type toolUseDelta struct {
+	Input string `json:"input"`
+}
+
+type reasoningTextDelta struct {
+	Text            string `json:"text,omitempty"`
+	Signature       string `json:"signature,omitempty"`
+	RedactedContent string `json:"redactedContent,omitempty"`
+}
+
+type titanEmbedRequest struct {
+	InputText  string `json:"inputText"`
+	Dimensions *int64 `json:"dimensions,omitempty"`
+	Normalize  *bool  `json:"normalize,omitempty"`
+}
+
+type titanEmbedResponse struct {
+	Embedding           []float64 `json:"embedding"`
+	InputTextTokenCount int64     `json:"inputTextTokenCount"`
+}
+
+type cohereEmbedRequest struct {
+	Texts     []string `json:"texts"`
+	InputType string   `json:"input_type"`
+	Truncate  string   `json:"truncate,omitempty"`
+}
+
+type cohereEmbedResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+}
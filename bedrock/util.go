@@ -0,0 +1,301 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func (b contentBlock) MarshalJSON() ([]byte, error) {
+	if len(b.Raw) > 0 {
+		return b.Raw, nil
+	}
+	type plain contentBlock
+	return json.Marshal(plain(b))
+}
+
+// requestID returns the AWS request ID of a response.
+func requestID(header http.Header) string {
+	return strings.TrimSpace(header.Get("x-amzn-RequestId"))
+}
+
+func decodeAPIError(resp *http.Response) error {
+	id := requestID(resp.Header)
+	errorType := errorTypeOf(resp.Header.Get("x-amzn-ErrorType"))
+
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
+	if readErr != nil {
+		return &core.APIError{
+			StatusCode: resp.StatusCode,
+			Type:       errorType,
+			Message:    fmt.Sprintf("bedrock: API status %d and failed to read error body: %v", resp.StatusCode, readErr),
+			RequestID:  id,
+			Retryable:  resp.StatusCode >= http.StatusInternalServerError,
+		}
+	}
+
+	var envelope struct {
+		Message      string `json:"message"`
+		MessageUpper string `json:"Message"`
+	}
+	text := ""
+	if err := json.Unmarshal(body, &envelope); err == nil {
+		text = strings.TrimSpace(nonEmpty(envelope.Message, envelope.MessageUpper))
+	}
+	if text == "" {
+		text = strings.TrimSpace(string(body))
+	}
+	if text == "" {
+		text = http.StatusText(resp.StatusCode)
+	}
+
+	return apiError(resp.StatusCode, errorType, text, id, retryAfter(resp.Header))
+}
+
+// apiError builds the typed error for a failed request or a stream
+// exception.
+func apiError(statusCode int, errorType, text, id string, wait time.Duration) error {
+	message := fmt.Sprintf("bedrock: API status %d: %s", statusCode, text)
+	if errorType != "" {
+		message = fmt.Sprintf("bedrock: API error (%s): %s", errorType, text)
+	}
+
+	switch {
+	case statusCode == http.StatusTooManyRequests || errorType == "ThrottlingException":
+		return &core.RateLimitError{Message: message, RetryAfter: wait, RequestID: id}
+	case errorType == "ResourceNotFoundException" && strings.Contains(strings.ToLower(text), "model"):
+		return &core.ModelNotFoundError{Message: message, RequestID: id}
+	}
+
+	return &core.APIError{
+		StatusCode: statusCode,
+		Type:       errorType,
+		Message:    message,
+		RequestID:  id,
+		Retryable: statusCode >= http.StatusInternalServerError ||
+			errorType == "ServiceUnavailableException" || errorType == "ModelNotReadyException",
+		RetryAfter: wait,
+	}
+}
+
+// streamException converts an exception event of a ConverseStream response,
+// such as a throttlingException raised mid-stream.
+func streamException(event *eventMessage, id string) error {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(event.Payload, &payload)
+
+	errorType := event.Headers[":exception-type"]
+	if errorType != "" {
+		errorType = strings.ToUpper(errorType[:1]) + errorType[1:]
+	}
+	statusCode := http.StatusBadRequest
+	switch errorType {
+	case "ThrottlingException":
+		statusCode = http.StatusTooManyRequests
+	case "InternalServerException", "ModelStreamErrorException":
+		statusCode = http.StatusInternalServerError
+	case "ServiceUnavailableException":
+		statusCode = http.StatusServiceUnavailable
+	}
+	return apiError(statusCode, errorType, nonEmpty(payload.Message, "stream exception"), id, 0)
+}
+
+// errorTypeOf strips the namespace and documentation URL from an
+// x-amzn-ErrorType value such as "ValidationException:http://...".
+func errorTypeOf(value string) string {
+	value, _, _ = strings.Cut(strings.TrimSpace(value), ":")
+	if idx := strings.LastIndex(value, "#"); idx >= 0 {
+		value = value[idx+1:]
+	}
+	return value
+}
+
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(header.Get("retry-after")), 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func extractText(content []contentBlock) string {
+	var builder strings.Builder
+	for _, block := range content {
+		builder.WriteString(block.Text)
+	}
+	return builder.String()
+}
+
+func extractReasoning(content []contentBlock) string {
+	parts := make([]string, 0, 1)
+	for _, block := range content {
+		if block.ReasoningContent != nil && block.ReasoningContent.ReasoningText != nil {
+			if text := strings.TrimSpace(block.ReasoningContent.ReasoningText.Text); text != "" {
+				parts = append(parts, text)
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// extractReasoningBlocks returns the reasoning of a response in the form of
+// Claude thinking blocks, which must be sent back with the tool results.
+func extractReasoningBlocks(content []contentBlock) []core.ReasoningBlock {
+	var blocks []core.ReasoningBlock
+	for _, block := range content {
+		reasoning := block.ReasoningContent
+		switch {
+		case reasoning == nil:
+		case reasoning.ReasoningText != nil:
+			blocks = append(blocks, core.ReasoningBlock{
+				Type:      "thinking",
+				Text:      reasoning.ReasoningText.Text,
+				Signature: reasoning.ReasoningText.Signature,
+			})
+		case reasoning.RedactedContent != "":
+			blocks = append(blocks, core.ReasoningBlock{Type: "redacted_thinking", Data: reasoning.RedactedContent})
+		}
+	}
+	return blocks
+}
+
+func extractToolUses(content []contentBlock) []toolUseBlock {
+	var uses []toolUseBlock
+	for _, block := range content {
+		if block.ToolUse != nil {
+			uses = append(uses, *block.ToolUse)
+		}
+	}
+	return uses
+}
+
+func findToolUse(uses []toolUseBlock, name string) (toolUseBlock, bool) {
+	if name == "" {
+		return toolUseBlock{}, false
+	}
+	for _, use := range uses {
+		if use.Name == name {
+			return use, true
+		}
+	}
+	return toolUseBlock{}, false
+}
+
+func toCoreToolCalls(uses []toolUseBlock) []core.ToolCall {
+	out := make([]core.ToolCall, 0, len(uses))
+	for _, use := range uses {
+		input := use.Input
+		if input == nil {
+			input = map[string]any{}
+		}
+		out = append(out, core.ToolCall{ID: use.ToolUseID, Name: use.Name, Arguments: input})
+	}
+	return out
+}
+
+// decodeToolInput decodes the JSON input of a streamed tool use.
+func decodeToolInput(input string) (any, error) {
+	if strings.TrimSpace(input) == "" {
+		return map[string]any{}, nil
+	}
+	var decoded any
+	if err := json.Unmarshal([]byte(input), &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func toCoreUsage(in *usage, m *metrics) *core.Usage {
+	if in == nil {
+		return nil
+	}
+
+	var details map[string]int64
+	addDetail := func(key string, value int64) {
+		if value <= 0 {
+			return
+		}
+		if details == nil {
+			details = make(map[string]int64)
+		}
+		details[key] = value
+	}
+	addDetail("cache_read_input_tokens", in.CacheReadInputTokens)
+	addDetail("cache_write_input_tokens", in.CacheWriteInputTokens)
+	if m != nil {
+		addDetail("latency_ms", m.LatencyMs)
+	}
+
+	total := in.TotalTokens
+	if total == 0 {
+		total = in.InputTokens + in.OutputTokens
+	}
+	return &core.Usage{
+		PromptTokens:     in.InputTokens,
+		CompletionTokens: in.OutputTokens,
+		TotalTokens:      total,
+		Details:          details,
+	}
+}
+
+func responseUsage(response *converseResponse) *core.Usage {
+	out := toCoreUsage(response.Usage, response.Metrics)
+	if out == nil {
+		out = &core.Usage{}
+	}
+	out.Duration = response.Duration
+	out.RequestID = response.RequestID
+	return out
+}
+
+func appendReasoningPart(parts []string, reasoning string) []string {
+	reasoning = strings.TrimSpace(reasoning)
+	if reasoning == "" {
+		return parts
+	}
+	if len(parts) > 0 && parts[len(parts)-1] == reasoning {
+		return parts
+	}
+	return append(parts, reasoning)
+}
+
+func joinReasoningParts(parts []string) string {
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// toCoreFinishReason maps a Converse stopReason onto core.FinishReason.
+func toCoreFinishReason(stopReason string) core.FinishReason {
+	switch strings.TrimSpace(stopReason) {
+	case "", "end_turn":
+		return core.FinishReasonStop
+	case "max_tokens", "model_context_window_exceeded":
+		return core.FinishReasonLength
+	case "stop_sequence":
+		return core.FinishReasonStopSequence
+	case "tool_use":
+		return core.FinishReasonToolCalls
+	case "guardrail_intervened", "content_filtered":
+		return core.FinishReasonContentFilter
+	default:
+		return core.FinishReason(stopReason)
+	}
+}
+
+func nonEmpty(value, fallback string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
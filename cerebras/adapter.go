@@ -0,0 +1,187 @@
+// Package cerebras is an adapter for the Cerebras inference API, which
+// serves the OpenAI Chat Completions format.
+//
+// Cerebras reports usage on the chunks of a stream as it goes, and the
+// adapter reports the last count. Its strict schema mode needs every object
+// of a schema to set additionalProperties to false, which the adapter fills
+// in for strict schemas.
+package cerebras
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+const (
+	providerName       = "cerebras"
+	defaultBaseURL     = "https://api.cerebras.ai/v1"
+	defaultHTTPTimeout = 5 * time.Minute
+	envCerebrasAPIKey  = "CEREBRAS_API_KEY"
+)
+
+type Adapter struct {
+	APIKey  string
+	Model   string
+	BaseURL string
+
+	// StrictSchemas sends every structured output schema and tool
+	// definition in strict mode, as if core.Schema.Strict were set.
+	StrictSchemas bool
+
+	HTTPClient *http.Client
+}
+
+var _ core.TextAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
+
+type Option func(*Adapter)
+
+// New creates a Cerebras adapter.
+//
+// Preferred usage is to use core and add this adapter there.
+//
+// If no API key is provided via options, New reads CEREBRAS_API_KEY.
+func New(model string, opts ...Option) *Adapter {
+	adapter := &Adapter{
+		APIKey:     strings.TrimSpace(os.Getenv(envCerebrasAPIKey)),
+		Model:      strings.TrimSpace(model),
+		BaseURL:    defaultBaseURL,
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(adapter)
+	}
+
+	return adapter
+}
+
+// WithAPIKey sets the API key used by the adapter.
+func WithAPIKey(apiKey string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(apiKey) == "" {
+			return
+		}
+		adapter.APIKey = strings.TrimSpace(apiKey)
+	}
+}
+
+// WithBaseURL sets the API base URL used by the adapter.
+func WithBaseURL(baseURL string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(baseURL) == "" {
+			return
+		}
+		adapter.BaseURL = strings.TrimSpace(baseURL)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the adapter.
+func WithHTTPClient(client *http.Client) Option {
+	return func(adapter *Adapter) {
+		if client == nil {
+			return
+		}
+		adapter.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the timeout on the adapter HTTP client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(adapter *Adapter) {
+		if timeout <= 0 {
+			return
+		}
+		if adapter.HTTPClient == nil {
+			adapter.HTTPClient = &http.Client{}
+		}
+		adapter.HTTPClient.Timeout = timeout
+	}
+}
+
+// WithStrictSchemas sends every structured output schema and tool
+// definition in strict mode, which constrains decoding to the schema.
+func WithStrictSchemas() Option {
+	return func(adapter *Adapter) {
+		adapter.StrictSchemas = true
+	}
+}
+
+// Capabilities reports the chat features of the Cerebras API. Reasoning
+// depends on the model.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:              true,
+		StructuredOutput:   true,
+		StreamingWithTools: true,
+		Reasoning:          true,
+	}
+}
+
+func (a *Adapter) validate() error {
+	if a == nil {
+		return errors.New("cerebras: adapter is nil")
+	}
+
+	if strings.TrimSpace(a.APIKey) == "" {
+		a.APIKey = strings.TrimSpace(os.Getenv(envCerebrasAPIKey))
+	}
+	if strings.TrimSpace(a.APIKey) == "" {
+		return errors.New("cerebras: API key is required (set CEREBRAS_API_KEY or use cerebras.WithAPIKey)")
+	}
+
+	if strings.TrimSpace(a.Model) == "" {
+		return errors.New("cerebras: model is required")
+	}
+
+	return nil
+}
+
+// compat returns the Chat Completions client for the adapter.
+func (a *Adapter) compat() *openaicompat.Client {
+	return &openaicompat.Client{
+		Provider:       providerName,
+		Model:          a.Model,
+		Transport:      a.transport(),
+		PrepareRequest: a.prepareRequest,
+		Usage:          timedUsage,
+	}
+}
+
+func (a *Adapter) client() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// transport returns the client that sends API requests.
+func (a *Adapter) transport() *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   providerName,
+		Header: func(header http.Header) {
+			header.Set("Authorization", "Bearer "+a.APIKey)
+			header.Set("Accept", "application/json")
+		},
+		DecodeError: openaicompat.DecodeError(providerName),
+	}
+}
+
+func (a *Adapter) baseURL() string {
+	if strings.TrimSpace(a.BaseURL) == "" {
+		return defaultBaseURL
+	}
+	return a.BaseURL
+}
@@ -0,0 +1,152 @@
+package cerebras
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+// Chat sends a non-streaming chat request to Cerebras.
+//
+// It supports tool calls, structured output, and reasoning. The Cerebras
+// timings of the last response are reported on Usage.Details.
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().Chat(ctx, params)
+}
+
+// ChatStream sends a streaming chat request to Cerebras.
+//
+// Server tools run between streamed turns. Structured output streams as
+// StreamChunkPartialJSON chunks. Cerebras counts tokens on the chunks as it
+// streams; the done chunk reports the last count, with the timings.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().ChatStream(ctx, params)
+}
+
+// prepareRequest turns on strict mode for StrictSchemas and completes the
+// schemas sent in strict mode.
+func (a *Adapter) prepareRequest(_ *core.ChatParams, request *openaicompat.Request) error {
+	if format := request.ResponseFormat; format != nil && format.JSONSchema != nil {
+		schema := *format.JSONSchema
+		if a.StrictSchemas {
+			schema.Strict = true
+		}
+		if schema.Strict {
+			schema.Schema = strictSchema(schema.Schema)
+		}
+		request.ResponseFormat = &openaicompat.ResponseFormat{Type: format.Type, JSONSchema: &schema}
+	}
+
+	if !a.StrictSchemas {
+		return nil
+	}
+	tools := make([]openaicompat.Tool, len(request.Tools))
+	for i, tool := range request.Tools {
+		tool.Function.Strict = true
+		tool.Function.Parameters = strictSchema(tool.Function.Parameters)
+		tools[i] = tool
+	}
+	request.Tools = tools
+	return nil
+}
+
+// strictSchema returns a copy of schema in which each object schema that
+// does not say otherwise sets additionalProperties to false, as strict mode
+// requires. Nested schemas are completed the same way.
+func strictSchema(schema map[string]any) map[string]any {
+	if schema == nil {
+		return nil
+	}
+
+	out := make(map[string]any, len(schema)+1)
+	for key, value := range schema {
+		switch key {
+		case "properties", "$defs", "definitions":
+			if schemas, ok := value.(map[string]any); ok {
+				nested := make(map[string]any, len(schemas))
+				for name, item := range schemas {
+					nested[name] = strictValue(item)
+				}
+				value = nested
+			}
+		case "items", "additionalProperties", "not", "anyOf", "oneOf", "allOf", "prefixItems":
+			value = strictValue(value)
+		}
+		out[key] = value
+	}
+
+	if _, ok := out["additionalProperties"]; !ok && isObjectSchema(out) {
+		out["additionalProperties"] = false
+	}
+	return out
+}
+
+// strictValue applies strictSchema to a schema or a list of schemas.
+func strictValue(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		return strictSchema(typed)
+	case []any:
+		out := make([]any, len(typed))
+		for i, item := range typed {
+			out[i] = strictValue(item)
+		}
+		return out
+	case []map[string]any:
+		out := make([]map[string]any, len(typed))
+		for i, item := range typed {
+			out[i] = strictSchema(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func isObjectSchema(schema map[string]any) bool {
+	if _, ok := schema["properties"]; ok {
+		return true
+	}
+	switch kind := schema["type"].(type) {
+	case string:
+		return kind == "object"
+	case []any:
+		for _, item := range kind {
+			if item == "object" {
+				return true
+			}
+		}
+	case []string:
+		for _, item := range kind {
+			if item == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// timedUsage adds the timings in the time_info of a Cerebras response or
+// stream chunk to usage.
+func timedUsage(raw []byte, usage *core.Usage) *core.Usage {
+	if usage == nil {
+		return nil
+	}
+
+	var envelope struct {
+		TimeInfo *openaicompat.Timings `json:"time_info"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil || envelope.TimeInfo == nil {
+		return usage
+	}
+	openaicompat.AddTimings(usage, *envelope.TimeInfo)
+	return usage
+}
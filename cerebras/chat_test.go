@@ -0,0 +1,168 @@
+package cerebras
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatCompletesStrictSchemas(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" || r.Header.Get("Authorization") != "Bearer csk-test" {
+			t.Errorf("unexpected request %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"chatcmpl-1","model":"llama-3.3-70b",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"{\"city\":\"Paris\",\"tags\":[]}"},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":20,"completion_tokens":10,"total_tokens":30,"prompt_tokens_details":{"cached_tokens":16}},
+			"time_info":{"queue_time":0.0002,"prompt_time":0.001,"completion_time":0.005,"total_time":0.0075,"created":1750000000}}`)
+	}))
+	defer server.Close()
+
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city": map[string]any{"type": "string"},
+			"tags": map[string]any{"type": "array", "items": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"name": map[string]any{"type": "string"}},
+			}},
+			"labels": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+		},
+	}
+	result, err := New("llama-3.3-70b", WithAPIKey("csk-test"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Capital of France?"}},
+		Output:   &core.Schema{Name: "answer", Strict: true, Schema: schema},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	jsonSchema := body["response_format"].(map[string]any)["json_schema"].(map[string]any)
+	sent := jsonSchema["schema"].(map[string]any)
+	properties := sent["properties"].(map[string]any)
+	items := properties["tags"].(map[string]any)["items"].(map[string]any)
+	labels := properties["labels"].(map[string]any)
+	if jsonSchema["strict"] != true || sent["additionalProperties"] != false || items["additionalProperties"] != false {
+		t.Fatalf("unexpected schema %v", jsonSchema)
+	}
+	if !reflect.DeepEqual(labels["additionalProperties"], map[string]any{"type": "string"}) {
+		t.Fatalf("labels = %v", labels)
+	}
+	if _, ok := schema["additionalProperties"]; ok {
+		t.Fatal("strict mode changed the caller's schema")
+	}
+
+	if result.Usage.TotalTokens != 30 || result.Usage.Details["cached_tokens"] != 16 {
+		t.Fatalf("unexpected usage %+v", result.Usage)
+	}
+	if result.Usage.Details["completion_time_us"] != 5000 || result.Usage.Details["queue_time_us"] != 200 || result.Usage.Details["completion_tokens_per_second"] != 2000 {
+		t.Fatalf("usage details = %v", result.Usage.Details)
+	}
+}
+
+func TestChatLeavesSchemasAloneWithoutStrictMode(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"chatcmpl-2","choices":[{"index":0,"message":{"role":"assistant","content":"{}"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	_, err := New("llama-3.3-70b", WithAPIKey("csk-test"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		Output:   &core.Schema{Name: "answer", Schema: map[string]any{"type": "object"}},
+		Tools:    []core.ToolUnion{core.ClientTool{Name: "lookup", Parameters: map[string]any{"type": "object"}}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	jsonSchema := body["response_format"].(map[string]any)["json_schema"].(map[string]any)
+	function := body["tools"].([]any)[0].(map[string]any)["function"].(map[string]any)
+	if _, ok := jsonSchema["strict"]; ok || !reflect.DeepEqual(jsonSchema["schema"], map[string]any{"type": "object"}) {
+		t.Fatalf("json_schema = %v", jsonSchema)
+	}
+	if _, ok := function["strict"]; ok || !reflect.DeepEqual(function["parameters"], map[string]any{"type": "object"}) {
+		t.Fatalf("function = %v", function)
+	}
+}
+
+func TestChatStreamKeepsLatestUsage(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"chatcmpl-3","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"lookup","arguments":"{\"q\":"}}]}}],"usage":{"prompt_tokens":40,"completion_tokens":1,"total_tokens":41}}
+
+data: {"id":"chatcmpl-3","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"go\"}"}}]}}],"usage":{"prompt_tokens":40,"completion_tokens":6,"total_tokens":46}}
+
+data: {"id":"chatcmpl-3","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":40,"completion_tokens":9,"total_tokens":49},"time_info":{"completion_time":0.003}}
+
+data: {"id":"chatcmpl-3","choices":[],"usage":null}
+
+data: [DONE]
+
+`)
+	}))
+	defer server.Close()
+
+	stream, err := New("qwen-3-32b", WithAPIKey("csk-test"), WithBaseURL(server.URL), WithStrictSchemas()).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Search for go"}},
+		Tools: []core.ToolUnion{core.ClientTool{Name: "lookup", Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"q": map[string]any{"type": "string"}},
+			"required":   []string{"q"},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var call *core.ToolCall
+	var done core.StreamChunk
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkToolCall:
+			call = chunk.ToolCall
+		case core.StreamChunkDone:
+			done = chunk
+		case core.StreamChunkError:
+			t.Fatalf("stream error: %s", chunk.Error)
+		}
+	}
+
+	function := body["tools"].([]any)[0].(map[string]any)["function"].(map[string]any)
+	if function["strict"] != true || function["parameters"].(map[string]any)["additionalProperties"] != false {
+		t.Fatalf("function = %v", function)
+	}
+	if _, ok := body["stream_options"]; ok {
+		t.Fatalf("unexpected stream_options in %v", body)
+	}
+
+	if call == nil || call.ID != "call_a" || !strings.Contains(fmt.Sprint(call.Arguments), "go") {
+		t.Fatalf("call = %+v", call)
+	}
+	if done.FinishReason != core.FinishReasonToolCalls || done.Usage == nil {
+		t.Fatalf("done = %+v", done)
+	}
+	if done.Usage.PromptTokens != 40 || done.Usage.CompletionTokens != 9 || done.Usage.TotalTokens != 49 || done.Usage.Details["completion_tokens_per_second"] != 3000 {
+		t.Fatalf("usage = %+v", done.Usage)
+	}
+}
@@ -0,0 +1,24 @@
+package cerebras
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestFromDSN(t *testing.T) {
+	t.Parallel()
+
+	adapter, err := core.FromDSN("cerebras://csk-key@llama-3.3-70b?timeout=20s&strict_schemas=true")
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	cerebras := adapter.(*Adapter)
+	if cerebras.APIKey != "csk-key" || cerebras.Model != "llama-3.3-70b" || cerebras.HTTPClient.Timeout != 20*time.Second || !cerebras.StrictSchemas {
+		t.Fatalf("adapter = %+v", cerebras)
+	}
+	if _, err := core.FromDSN("cerebras://llama-3.3-70b?gzip=1"); err == nil {
+		t.Fatal("FromDSN() expected error for an unknown option")
+	}
+}
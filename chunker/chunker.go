@@ -0,0 +1,532 @@
+// Package chunker splits text into chunks of a bounded number of tokens for
+// embedding and retrieval.
+//
+// A Splitter cuts at paragraph and sentence boundaries where it can, falls
+// back to words and then characters for longer runs, and repeats the last
+// sentences of each chunk at the start of the next so context is not lost at
+// the cut. With WithMarkdown it also keeps chunks within heading sections
+// and fenced code blocks whole where they fit:
+//
+//	splitter := chunker.New(chunker.WithChunkSize(256), chunker.WithOverlap(32), chunker.WithModel("text-embedding-3-small"))
+//	for _, chunk := range splitter.Split(text) {
+//		fmt.Println(chunk.Index, chunk.Tokens, chunk.Text)
+//	}
+//
+// Documents returns the chunks as core.VectorDocuments, ready to upsert into
+// a core.VectorStore.
+package chunker
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/m43i/go-ai/core"
+)
+
+const (
+	// DefaultChunkSize is the maximum number of tokens per chunk unless
+	// WithChunkSize is used.
+	DefaultChunkSize = 512
+
+	// DefaultOverlap is the number of tokens repeated between consecutive
+	// chunks unless WithOverlap is used.
+	DefaultOverlap = 64
+)
+
+// Chunk is a piece of the split text.
+type Chunk struct {
+	Text  string
+	Index int
+
+	// Start and End are the byte offsets of Text in the split text.
+	Start int
+	End   int
+
+	// Tokens is the number of tokens in Text by the splitter's tokenizer.
+	Tokens int
+
+	// Headings is the path of Markdown headings the chunk is under, outermost
+	// first, when splitting Markdown.
+	Headings []string
+}
+
+// Splitter splits text into chunks. The zero value splits into chunks of
+// DefaultChunkSize tokens without overlap; New also sets DefaultOverlap.
+type Splitter struct {
+	// ChunkSize is the maximum number of tokens per chunk.
+	ChunkSize int
+
+	// Overlap is the maximum number of tokens of whole sentences repeated
+	// from the end of a chunk at the start of the next. It is capped at half
+	// the chunk size, and zero disables it.
+	Overlap int
+
+	// Tokenizer counts tokens. Nil uses DefaultTokenizer.
+	Tokenizer Tokenizer
+
+	// Markdown splits at headings first and keeps fenced code blocks
+	// together.
+	Markdown bool
+}
+
+// Option configures a Splitter.
+type Option func(*Splitter)
+
+// New returns a Splitter with DefaultChunkSize and DefaultOverlap.
+func New(opts ...Option) *Splitter {
+	splitter := &Splitter{ChunkSize: DefaultChunkSize, Overlap: DefaultOverlap}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(splitter)
+	}
+	return splitter
+}
+
+// WithChunkSize sets the maximum number of tokens per chunk.
+func WithChunkSize(tokens int) Option {
+	return func(s *Splitter) {
+		if tokens > 0 {
+			s.ChunkSize = tokens
+		}
+	}
+}
+
+// WithOverlap sets the number of tokens repeated between consecutive chunks.
+// Zero disables the overlap.
+func WithOverlap(tokens int) Option {
+	return func(s *Splitter) {
+		if tokens >= 0 {
+			s.Overlap = tokens
+		}
+	}
+}
+
+// WithTokenizer sets the tokenizer that counts tokens.
+func WithTokenizer(tokenizer Tokenizer) Option {
+	return func(s *Splitter) {
+		s.Tokenizer = tokenizer
+	}
+}
+
+// WithModel counts tokens with the tokenizer registered for the family of
+// model, such as the embedding model the chunks are meant for.
+func WithModel(model string) Option {
+	return func(s *Splitter) {
+		s.Tokenizer = TokenizerFor(model)
+	}
+}
+
+// WithMarkdown splits at Markdown headings first and keeps fenced code
+// blocks together.
+func WithMarkdown() Option {
+	return func(s *Splitter) {
+		s.Markdown = true
+	}
+}
+
+// Split splits text into chunks of at most ChunkSize tokens. A single word
+// longer than the chunk size is cut between characters.
+func (s *Splitter) Split(text string) []Chunk {
+	state := s.newState(text)
+
+	sections := []section{{start: 0, end: len(text)}}
+	if state.markdown {
+		sections = markdownSections(text)
+	}
+
+	var chunks []Chunk
+	for _, sec := range sections {
+		pieces := state.pieces(sec.start, sec.end)
+		chunks = append(chunks, state.pack(pieces, sec.headings)...)
+	}
+	for idx := range chunks {
+		chunks[idx].Index = idx
+	}
+	return chunks
+}
+
+// Documents splits text and returns its chunks as documents with IDs of the
+// form "<id>#<index>". Each document carries a copy of metadata with the
+// source ID under "source_id", the chunk index under "chunk_index", and, for
+// Markdown, the heading path joined by " > " under "headings".
+func (s *Splitter) Documents(id, text string, metadata map[string]any) []core.VectorDocument {
+	chunks := s.Split(text)
+	documents := make([]core.VectorDocument, len(chunks))
+	for idx, chunk := range chunks {
+		meta := maps.Clone(metadata)
+		if meta == nil {
+			meta = make(map[string]any, 3)
+		}
+		meta["source_id"] = id
+		meta["chunk_index"] = chunk.Index
+		if len(chunk.Headings) > 0 {
+			meta["headings"] = strings.Join(chunk.Headings, " > ")
+		}
+		documents[idx] = core.VectorDocument{ID: fmt.Sprintf("%s#%d", id, chunk.Index), Text: chunk.Text, Metadata: meta}
+	}
+	return documents
+}
+
+// state holds the settings of one Split call.
+type state struct {
+	text      string
+	size      int
+	overlap   int
+	tokenizer Tokenizer
+	markdown  bool
+}
+
+func (s *Splitter) newState(text string) *state {
+	st := &state{text: text, size: DefaultChunkSize, tokenizer: DefaultTokenizer}
+	if s != nil {
+		if s.ChunkSize > 0 {
+			st.size = s.ChunkSize
+		}
+		st.overlap = max(s.Overlap, 0)
+		if s.Tokenizer != nil {
+			st.tokenizer = s.Tokenizer
+		}
+		st.markdown = s.Markdown
+	}
+	st.overlap = min(st.overlap, st.size/2)
+	return st
+}
+
+func (st *state) count(start, end int) int {
+	return st.tokenizer.Count(st.text[start:end])
+}
+
+// piece is an atomic span of text for packing: a sentence, or a part of a
+// sentence too long for one chunk. Consecutive pieces cover the text without
+// gaps.
+type piece struct {
+	start, end int
+
+	// paragraph marks the first piece of a paragraph, the preferred place
+	// to end a chunk.
+	paragraph bool
+}
+
+// pieces splits [start, end) into paragraphs and those into sentences,
+// cutting sentences longer than a chunk into words and then characters.
+func (st *state) pieces(start, end int) []piece {
+	var out []piece
+	for _, paragraph := range spans(start, end, st.paragraphBoundaries(start, end)) {
+		first := len(out)
+		for _, sentence := range spans(paragraph[0], paragraph[1], st.sentenceBoundaries(paragraph[0], paragraph[1])) {
+			out = append(out, st.fit(sentence[0], sentence[1])...)
+		}
+		if first < len(out) {
+			out[first].paragraph = true
+		}
+	}
+	return out
+}
+
+// fit returns [start, end) as pieces of at most one chunk each.
+func (st *state) fit(start, end int) []piece {
+	if st.count(start, end) <= st.size {
+		return []piece{{start: start, end: end}}
+	}
+
+	var out []piece
+	for _, word := range spans(start, end, wordBoundaries(st.text, start, end)) {
+		if st.count(word[0], word[1]) <= st.size {
+			out = append(out, piece{start: word[0], end: word[1]})
+			continue
+		}
+		out = append(out, st.cutRunes(word[0], word[1])...)
+	}
+	return out
+}
+
+// cutRunes cuts [start, end) between characters into the longest pieces
+// that fit in a chunk.
+func (st *state) cutRunes(start, end int) []piece {
+	var offsets []int
+	for offset := range st.text[start:end] {
+		if offset > 0 {
+			offsets = append(offsets, start+offset)
+		}
+	}
+	offsets = append(offsets, end)
+
+	var out []piece
+	for start < end {
+		// The first offset whose prefix no longer fits, searched among the
+		// offsets after start.
+		from := sort.SearchInts(offsets, start+1)
+		n := sort.Search(len(offsets)-from, func(i int) bool {
+			return st.count(start, offsets[from+i]) > st.size
+		})
+		cut := offsets[from+max(n-1, 0)]
+		out = append(out, piece{start: start, end: cut})
+		start = cut
+	}
+	return out
+}
+
+// pack joins consecutive pieces into chunks of at most size tokens, ending
+// chunks at paragraph boundaries when that keeps them at least half full,
+// and starts each chunk with up to overlap tokens from the previous one.
+func (st *state) pack(pieces []piece, headings []string) []Chunk {
+	var chunks []Chunk
+	for first := 0; first < len(pieces); {
+		end := first + 1
+		for end < len(pieces) && st.count(pieces[first].start, pieces[end].end) <= st.size {
+			end++
+		}
+		if end < len(pieces) {
+			for cut := end - 1; cut > first; cut-- {
+				if !pieces[cut].paragraph {
+					continue
+				}
+				if 2*st.count(pieces[first].start, pieces[cut-1].end) >= st.size {
+					end = cut
+				}
+				break
+			}
+		}
+
+		if chunk, ok := st.chunk(pieces[first].start, pieces[end-1].end, headings); ok {
+			chunks = append(chunks, chunk)
+		}
+		if end == len(pieces) {
+			break
+		}
+
+		next := end
+		for next-1 > first && st.count(pieces[next-1].start, pieces[end-1].end) <= st.overlap {
+			next--
+		}
+		for next < end && st.count(pieces[next].start, pieces[end].end) > st.size {
+			next++
+		}
+		first = next
+	}
+	return chunks
+}
+
+// chunk returns [start, end) without surrounding whitespace as a chunk, or
+// false when it is only whitespace.
+func (st *state) chunk(start, end int, headings []string) (Chunk, bool) {
+	raw := st.text[start:end]
+	trimmed := strings.TrimLeftFunc(raw, unicode.IsSpace)
+	start += len(raw) - len(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	if trimmed == "" {
+		return Chunk{}, false
+	}
+	return Chunk{
+		Text:     trimmed,
+		Start:    start,
+		End:      start + len(trimmed),
+		Tokens:   st.tokenizer.Count(trimmed),
+		Headings: headings,
+	}, true
+}
+
+// spans returns the spans between consecutive cuts in [start, end).
+func spans(start, end int, cuts []int) [][2]int {
+	out := make([][2]int, 0, len(cuts)+1)
+	for _, cut := range cuts {
+		out = append(out, [2]int{start, cut})
+		start = cut
+	}
+	return append(out, [2]int{start, end})
+}
+
+// paragraphBoundaries returns the starts of the paragraphs after the first in
+// [start, end): lines following one or more blank lines. Blank lines in
+// fenced code blocks do not count when splitting Markdown.
+func (st *state) paragraphBoundaries(start, end int) []int {
+	var cuts []int
+	blank, fenced := false, false
+	for lineStart := start; lineStart < end; {
+		lineEnd := strings.IndexByte(st.text[lineStart:end], '\n')
+		if lineEnd < 0 {
+			lineEnd = end
+		} else {
+			lineEnd += lineStart + 1
+		}
+		line := strings.TrimSpace(st.text[lineStart:lineEnd])
+
+		switch {
+		case st.markdown && isFence(line):
+			if !fenced && blank && lineStart > start {
+				cuts = append(cuts, lineStart)
+			}
+			fenced = !fenced
+			blank = false
+		case fenced:
+		case line == "":
+			blank = true
+		default:
+			if blank && lineStart > start {
+				cuts = append(cuts, lineStart)
+			}
+			blank = false
+		}
+		lineStart = lineEnd
+	}
+	return cuts
+}
+
+// sentenceBoundaries returns the starts of the sentences after the first in
+// [start, end). A sentence ends after terminal punctuation and any closing
+// quotes or brackets followed by whitespace, after CJK terminal punctuation,
+// or at a line break. Fenced code blocks are one sentence when splitting
+// Markdown.
+func (st *state) sentenceBoundaries(start, end int) []int {
+	text := st.text[start:end]
+	if st.markdown && isFence(strings.TrimSpace(firstLine(text))) {
+		return nil
+	}
+
+	var cuts []int
+	for i := 0; i < len(text); {
+		r, width := utf8.DecodeRuneInString(text[i:])
+		i += width
+
+		terminal := false
+		switch {
+		case r == '\n':
+			terminal = true
+		case r == '.' || r == '!' || r == '?' || r == '…':
+			for i < len(text) && strings.ContainsRune(`"')]}”’»`, rune(text[i])) {
+				i++
+			}
+			next, _ := utf8.DecodeRuneInString(text[i:])
+			terminal = i < len(text) && unicode.IsSpace(next)
+		case r == '。' || r == '！' || r == '？':
+			terminal = true
+		}
+		if !terminal {
+			continue
+		}
+
+		for i < len(text) {
+			next, width := utf8.DecodeRuneInString(text[i:])
+			if !unicode.IsSpace(next) {
+				break
+			}
+			i += width
+		}
+		if i < len(text) {
+			cuts = append(cuts, start+i)
+		}
+	}
+	return cuts
+}
+
+// wordBoundaries returns the starts of the words after the first in
+// [start, end), each word keeping the whitespace that follows it.
+func wordBoundaries(text string, start, end int) []int {
+	var cuts []int
+	space := false
+	for offset, r := range text[start:end] {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space && offset > 0 {
+			cuts = append(cuts, start+offset)
+		}
+		space = false
+	}
+	return cuts
+}
+
+// section is a span of Markdown under a path of headings.
+type section struct {
+	start, end int
+	headings   []string
+}
+
+// markdownSections splits text at ATX headings outside fenced code blocks.
+func markdownSections(text string) []section {
+	type heading struct {
+		level int
+		title string
+	}
+	var stack []heading
+	path := func() []string {
+		if len(stack) == 0 {
+			return nil
+		}
+		titles := make([]string, len(stack))
+		for i, h := range stack {
+			titles[i] = h.title
+		}
+		return titles
+	}
+
+	var sections []section
+	current := section{}
+	fenced := false
+	for lineStart := 0; lineStart < len(text); {
+		lineEnd := strings.IndexByte(text[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(text)
+		} else {
+			lineEnd += lineStart + 1
+		}
+		line := strings.TrimRight(text[lineStart:lineEnd], "\r\n")
+
+		if isFence(strings.TrimSpace(line)) {
+			fenced = !fenced
+		} else if level, title, ok := parseHeading(line); ok && !fenced {
+			if lineStart > current.start {
+				current.end = lineStart
+				sections = append(sections, current)
+			}
+			for len(stack) > 0 && stack[len(stack)-1].level >= level {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, heading{level: level, title: title})
+			current = section{start: lineStart, headings: path()}
+		}
+		lineStart = lineEnd
+	}
+	current.end = len(text)
+	return append(sections, current)
+}
+
+// parseHeading parses an ATX heading such as "## Install".
+func parseHeading(line string) (int, string, bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return 0, "", false
+	}
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+	rest := trimmed[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(rest), "#"))
+	return level, title, true
+}
+
+func isFence(line string) bool {
+	return strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~")
+}
+
+func firstLine(text string) string {
+	text = strings.TrimLeftFunc(text, unicode.IsSpace)
+	if idx := strings.IndexByte(text, '\n'); idx >= 0 {
+		return text[:idx]
+	}
+	return text
+}
@@ -0,0 +1,190 @@
+package chunker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// words counts whitespace-separated words, which makes expected chunk
+// boundaries easy to reason about.
+var words = TokenizerFunc(func(text string) int {
+	return len(strings.Fields(text))
+})
+
+func texts(chunks []Chunk) []string {
+	out := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		out[i] = chunk.Text
+	}
+	return out
+}
+
+func checkOffsets(t *testing.T, text string, chunks []Chunk) {
+	t.Helper()
+	for i, chunk := range chunks {
+		if chunk.Index != i || text[chunk.Start:chunk.End] != chunk.Text {
+			t.Fatalf("chunk %d = %+v does not match its offsets", i, chunk)
+		}
+	}
+}
+
+func TestSplitShortText(t *testing.T) {
+	t.Parallel()
+
+	text := "  Hello, world.  \n"
+	chunks := New().Split(text)
+	if len(chunks) != 1 || chunks[0].Text != "Hello, world." || chunks[0].Start != 2 || chunks[0].Tokens != 4 {
+		t.Fatalf("chunks = %+v", chunks)
+	}
+	if chunks := New().Split(" \n\n "); len(chunks) != 0 {
+		t.Fatalf("whitespace chunks = %+v", chunks)
+	}
+}
+
+func TestSplitSentencesWithOverlap(t *testing.T) {
+	t.Parallel()
+
+	text := "One two three. Four five six. Seven eight nine. Ten eleven twelve."
+	chunks := New(WithTokenizer(words), WithChunkSize(6), WithOverlap(3)).Split(text)
+	want := []string{
+		"One two three. Four five six.",
+		"Four five six. Seven eight nine.",
+		"Seven eight nine. Ten eleven twelve.",
+	}
+	if got := texts(chunks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("chunks = %q", got)
+	}
+	checkOffsets(t, text, chunks)
+
+	chunks = New(WithTokenizer(words), WithChunkSize(6), WithOverlap(0)).Split(text)
+	want = []string{"One two three. Four five six.", "Seven eight nine. Ten eleven twelve."}
+	if got := texts(chunks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("chunks without overlap = %q", got)
+	}
+}
+
+func TestSplitPrefersParagraphs(t *testing.T) {
+	t.Parallel()
+
+	text := "Alpha beta gamma. Delta epsilon.\n\nZeta eta. Theta iota kappa lambda."
+	chunks := New(WithTokenizer(words), WithChunkSize(8), WithOverlap(0)).Split(text)
+	want := []string{"Alpha beta gamma. Delta epsilon.", "Zeta eta. Theta iota kappa lambda."}
+	if got := texts(chunks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("chunks = %q", got)
+	}
+	checkOffsets(t, text, chunks)
+}
+
+func TestSplitLongSentence(t *testing.T) {
+	t.Parallel()
+
+	text := "a b c d e f g h"
+	chunks := New(WithTokenizer(words), WithChunkSize(3), WithOverlap(0)).Split(text)
+	if got, want := texts(chunks), []string{"a b c", "d e f", "g h"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("chunks = %q", got)
+	}
+
+	long := strings.Repeat("x", 10)
+	chunks = New(WithTokenizer(Estimator{CharsPerToken: 1}), WithChunkSize(4), WithOverlap(0)).Split(long)
+	if got, want := texts(chunks), []string{"xxxx", "xxxx", "xx"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("rune chunks = %q", got)
+	}
+	checkOffsets(t, long, chunks)
+}
+
+func TestSplitCJK(t *testing.T) {
+	t.Parallel()
+
+	text := "今日は晴れです。明日は雨です。"
+	chunks := New(WithChunkSize(8), WithOverlap(0)).Split(text)
+	if got, want := texts(chunks), []string{"今日は晴れです。", "明日は雨です。"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("chunks = %q", got)
+	}
+	checkOffsets(t, text, chunks)
+}
+
+func TestSplitMarkdown(t *testing.T) {
+	t.Parallel()
+
+	text := "Intro text.\n\n# Guide\n\nOverview.\n\n## Install\n\n```sh\ngo get example.com/a\n\ngo get example.com/b\n```\n\n# FAQ\n\nAnswers."
+	chunks := New(WithMarkdown(), WithTokenizer(words), WithChunkSize(20)).Split(text)
+
+	wantTexts := []string{
+		"Intro text.",
+		"# Guide\n\nOverview.",
+		"## Install\n\n```sh\ngo get example.com/a\n\ngo get example.com/b\n```",
+		"# FAQ\n\nAnswers.",
+	}
+	if got := texts(chunks); !reflect.DeepEqual(got, wantTexts) {
+		t.Fatalf("chunks = %q", got)
+	}
+	wantHeadings := [][]string{nil, {"Guide"}, {"Guide", "Install"}, {"FAQ"}}
+	for i, chunk := range chunks {
+		if !reflect.DeepEqual(chunk.Headings, wantHeadings[i]) {
+			t.Fatalf("chunk %d headings = %q", i, chunk.Headings)
+		}
+	}
+	checkOffsets(t, text, chunks)
+
+	// A code block that does not fit is split by lines, not at the blank line
+	// inside it.
+	chunks = New(WithMarkdown(), WithTokenizer(words), WithChunkSize(5), WithOverlap(0)).Split("```\na b\n\nc d\n```")
+	if got, want := texts(chunks), []string{"```\na b\n\nc d", "```"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("fenced chunks = %q", got)
+	}
+}
+
+func TestDocuments(t *testing.T) {
+	t.Parallel()
+
+	metadata := map[string]any{"lang": "en"}
+	documents := New(WithMarkdown(), WithTokenizer(words), WithChunkSize(5), WithOverlap(0)).Documents("guide", "# Setup\n\nRun it now. Then stop.", metadata)
+	if len(documents) != 2 {
+		t.Fatalf("documents = %+v", documents)
+	}
+	first := documents[0]
+	if first.ID != "guide#0" || first.Text != "# Setup\n\nRun it now." {
+		t.Fatalf("first document = %+v", first)
+	}
+	if want := map[string]any{"lang": "en", "source_id": "guide", "chunk_index": 0, "headings": "Setup"}; !reflect.DeepEqual(first.Metadata, want) {
+		t.Fatalf("metadata = %#v", first.Metadata)
+	}
+	if documents[1].ID != "guide#1" || documents[1].Metadata["chunk_index"] != 1 {
+		t.Fatalf("second document = %+v", documents[1])
+	}
+	if len(metadata) != 1 {
+		t.Fatalf("input metadata was modified: %#v", metadata)
+	}
+}
+
+func TestTokenizerFor(t *testing.T) {
+	t.Parallel()
+
+	if got := TokenizerFor("claude-sonnet-4-5"); got != ClaudeTokenizer {
+		t.Fatalf("TokenizerFor(claude) = %#v", got)
+	}
+	if got := TokenizerFor("Llama3.1:8b"); got != OpenTokenizer {
+		t.Fatalf("TokenizerFor(llama) = %#v", got)
+	}
+	if got := TokenizerFor("unknown"); got != DefaultTokenizer {
+		t.Fatalf("TokenizerFor(unknown) = %#v", got)
+	}
+
+	exact := TokenizerFunc(func(string) int { return 1 })
+	RegisterTokenizer("test-chunker-model", exact)
+	if got := TokenizerFor("test-chunker-model-v2").Count("anything at all"); got != 1 {
+		t.Fatalf("registered tokenizer count = %d", got)
+	}
+}
+
+func TestEstimator(t *testing.T) {
+	t.Parallel()
+
+	if got := (Estimator{CharsPerToken: 4}).Count("abcde"); got != 2 {
+		t.Fatalf("Count(latin) = %d", got)
+	}
+	if got := DefaultTokenizer.Count("日本語ab"); got != 4 {
+		t.Fatalf("Count(mixed) = %d", got)
+	}
+}
@@ -0,0 +1,113 @@
+package chunker
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Tokenizer counts the tokens of a text as a model would.
+//
+// The built-in tokenizers estimate counts from characters. Register an exact
+// tokenizer, such as a BPE implementation for a model family, with
+// RegisterTokenizer.
+type Tokenizer interface {
+	Count(text string) int
+}
+
+// TokenizerFunc adapts a function to a Tokenizer.
+type TokenizerFunc func(text string) int
+
+// Count implements Tokenizer.
+func (f TokenizerFunc) Count(text string) int {
+	return f(text)
+}
+
+// Estimator estimates tokens from characters: CharsPerToken characters of
+// alphabetic scripts per token, and one token per Han, Hiragana, Katakana, or
+// Hangul character, which BPE vocabularies rarely merge.
+type Estimator struct {
+	CharsPerToken float64
+}
+
+// Count implements Tokenizer.
+func (e Estimator) Count(text string) int {
+	charsPerToken := e.CharsPerToken
+	if charsPerToken <= 0 {
+		charsPerToken = 4
+	}
+
+	chars, wide := 0, 0
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			wide++
+			continue
+		}
+		chars++
+	}
+	return wide + int(math.Ceil(float64(chars)/charsPerToken))
+}
+
+var (
+	// DefaultTokenizer estimates four characters per token, which errs on
+	// the high side for English text and code with most models.
+	DefaultTokenizer Tokenizer = Estimator{CharsPerToken: 4}
+
+	// ClaudeTokenizer estimates Claude models, whose vocabulary yields
+	// somewhat more tokens per character than OpenAI's.
+	ClaudeTokenizer Tokenizer = Estimator{CharsPerToken: 3.5}
+
+	// OpenTokenizer estimates open-weight model families such as Llama,
+	// Mistral, Gemma, and Qwen.
+	OpenTokenizer Tokenizer = Estimator{CharsPerToken: 3.7}
+)
+
+var tokenizers = struct {
+	mu       sync.RWMutex
+	prefixes map[string]Tokenizer
+}{prefixes: map[string]Tokenizer{
+	"gpt-":            DefaultTokenizer,
+	"o1":              DefaultTokenizer,
+	"o3":              DefaultTokenizer,
+	"o4":              DefaultTokenizer,
+	"text-embedding-": DefaultTokenizer,
+	"claude-":         ClaudeTokenizer,
+	"llama":           OpenTokenizer,
+	"mistral":         OpenTokenizer,
+	"mixtral":         OpenTokenizer,
+	"gemma":           OpenTokenizer,
+	"qwen":            OpenTokenizer,
+	"deepseek":        OpenTokenizer,
+	"phi":             OpenTokenizer,
+}}
+
+// RegisterTokenizer makes TokenizerFor return tokenizer for models whose
+// name starts with prefix, such as "gpt-4o" or "claude-". The longest
+// matching prefix wins.
+func RegisterTokenizer(prefix string, tokenizer Tokenizer) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" || tokenizer == nil {
+		return
+	}
+	tokenizers.mu.Lock()
+	tokenizers.prefixes[prefix] = tokenizer
+	tokenizers.mu.Unlock()
+}
+
+// TokenizerFor returns the tokenizer registered for the family of model, or
+// DefaultTokenizer.
+func TokenizerFor(model string) Tokenizer {
+	model = strings.ToLower(strings.TrimSpace(model))
+
+	tokenizers.mu.RLock()
+	defer tokenizers.mu.RUnlock()
+
+	best, bestLen := DefaultTokenizer, -1
+	for prefix, tokenizer := range tokenizers.prefixes {
+		if strings.HasPrefix(model, prefix) && len(prefix) > bestLen {
+			best, bestLen = tokenizer, len(prefix)
+		}
+	}
+	return best
+}
@@ -2,32 +2,65 @@ package claude
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
 )
 
 const (
-	defaultBaseURL         = "https://api.anthropic.com/v1"
-	defaultMaxAgenticLoops = 8
-	defaultHTTPTimeout     = 5 * time.Minute
-	defaultVersion         = "2023-06-01"
-	envAnthropicAPIKey     = "ANTHROPIC_API_KEY"
-	envClaudeAPIKey        = "CLAUDE_API_KEY"
+	providerName            = "claude"
+	defaultBaseURL          = "https://api.anthropic.com/v1"
+	defaultMaxAgenticLoops  = 8
+	defaultHTTPTimeout      = 5 * time.Minute
+	defaultVersion          = "2023-06-01"
+	longOutputBeta          = "output-128k-2025-02-19"
+	mcpConnectorBeta        = "mcp-client-2025-04-04"
+	interleavedThinkingBeta = "interleaved-thinking-2025-05-14"
+	codeExecutionBeta       = "code-execution-2025-05-22"
+	maxStandardOutputTokens = 64000
+	maxLongOutputTokens     = 128000
+	defaultMaxTokens        = 1024
+	defaultThinkingBudget   = 4096
+	minThinkingBudget       = 1024
+	defaultOutputToolName   = "structured_output"
+	envAnthropicAPIKey      = "ANTHROPIC_API_KEY"
+	envClaudeAPIKey         = "CLAUDE_API_KEY"
+)
+
+// Structured output modes supported by the adapter.
+const (
+	// OutputModeNative requests structured output through output_config.
+	OutputModeNative = "native"
+	// OutputModeTool defines a synthetic tool whose input schema is the output
+	// schema, forces the model to call it, and returns the tool input as JSON.
+	OutputModeTool = "tool"
 )
 
 type Adapter struct {
-	APIKey           string
-	Model            string
-	BaseURL          string
-	AnthropicVersion string
-	HTTPClient       *http.Client
+	APIKey              string
+	Model               string
+	BaseURL             string
+	AnthropicVersion    string
+	BetaFeatures        []string
+	OutputMode          string
+	InterleavedThinking bool
+	CheckPromptSize     bool
+	Memory              MemoryStore
+	Retry               RetryPolicy
+	Gzip                bool
+	HTTPClient          *http.Client
+
+	fileIDs *fileIDCache
 }
 
 var _ core.TextAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
 
 type Option func(*Adapter)
 
@@ -43,6 +76,7 @@ func New(model string, opts ...Option) *Adapter {
 		BaseURL:          defaultBaseURL,
 		AnthropicVersion: defaultVersion,
 		HTTPClient:       &http.Client{Timeout: defaultHTTPTimeout},
+		fileIDs:          &fileIDCache{},
 	}
 
 	for _, opt := range opts {
@@ -115,22 +149,124 @@ func WithAnthropicVersion(version string) Option {
 	}
 }
 
+// WithBetaFeatures enables Anthropic beta features by sending them in the
+// anthropic-beta request header. Repeated calls add to the list.
+func WithBetaFeatures(features ...string) Option {
+	return func(adapter *Adapter) {
+		for _, feature := range features {
+			feature = strings.TrimSpace(feature)
+			if feature == "" || slices.Contains(adapter.BetaFeatures, feature) {
+				continue
+			}
+			adapter.BetaFeatures = append(adapter.BetaFeatures, feature)
+		}
+	}
+}
+
+// WithRetryPolicy enables retries of rate limited, overloaded, and other
+// transient server errors.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(adapter *Adapter) {
+		if policy.MaxRetries < 0 {
+			policy.MaxRetries = 0
+		}
+		adapter.Retry = policy
+	}
+}
+
+// WithInterleavedThinking lets the model think between tool calls by sending
+// the interleaved thinking beta header on requests that use both extended
+// thinking and tools.
+func WithInterleavedThinking() Option {
+	return func(adapter *Adapter) {
+		adapter.InterleavedThinking = true
+	}
+}
+
+// WithGzip compresses request bodies of 1 KiB or more with gzip and asks for
+// gzip responses. It cuts upload time for prompts with large inline images
+// or documents; use it only with endpoints, such as a gateway, that accept
+// gzip-encoded request bodies.
+func WithGzip() Option {
+	return func(adapter *Adapter) {
+		adapter.Gzip = true
+	}
+}
+
+// WithPromptSizeCheck counts the prompt tokens of each request before sending
+// it and returns a *core.ContextLengthExceededError when the prompt plus
+// max_tokens cannot fit the model's context window from the model catalog.
+// Each check costs one token counting request.
+func WithPromptSizeCheck() Option {
+	return func(adapter *Adapter) {
+		adapter.CheckPromptSize = true
+	}
+}
+
+// WithMemory enables the Claude memory tool on every request, storing the
+// files the model reads and writes in store.
+func WithMemory(store MemoryStore) Option {
+	return func(adapter *Adapter) {
+		if store == nil {
+			return
+		}
+		adapter.Memory = store
+	}
+}
+
+// WithOutputMode selects how structured output is requested: OutputModeNative
+// (the default) or OutputModeTool.
+func WithOutputMode(mode string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(mode) == "" {
+			return
+		}
+		adapter.OutputMode = strings.TrimSpace(mode)
+	}
+}
+
+// Capabilities reports the chat features of the Messages API. It has no
+// audio input, and ChatStream replays a non-streaming call when tools or
+// structured output are used.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:            true,
+		Vision:           true,
+		Documents:        true,
+		StructuredOutput: true,
+		Reasoning:        true,
+	}
+}
+
 func (a *Adapter) validate() error {
 	if a == nil {
 		return errors.New("claude: adapter is nil")
 	}
 
-	if strings.TrimSpace(a.APIKey) == "" {
-		a.APIKey = resolveAPIKey()
-	}
-	if strings.TrimSpace(a.APIKey) == "" {
-		return errors.New("claude: API key is required (set ANTHROPIC_API_KEY/CLAUDE_API_KEY or use claude.WithAPIKey)")
+	if err := a.validateAPIKey(); err != nil {
+		return err
 	}
 
 	if strings.TrimSpace(a.Model) == "" {
 		return errors.New("claude: model is required")
 	}
 
+	switch a.outputMode() {
+	case OutputModeNative, OutputModeTool:
+	default:
+		return fmt.Errorf("claude: unsupported output mode %q", a.OutputMode)
+	}
+
+	return nil
+}
+
+func (a *Adapter) validateAPIKey() error {
+	if strings.TrimSpace(a.APIKey) == "" {
+		a.APIKey = resolveAPIKey()
+	}
+	if strings.TrimSpace(a.APIKey) == "" {
+		return errors.New("claude: API key is required (set ANTHROPIC_API_KEY/CLAUDE_API_KEY or use claude.WithAPIKey)")
+	}
 	return nil
 }
 
@@ -155,6 +291,52 @@ func (a *Adapter) version() string {
 	return strings.TrimSpace(a.AnthropicVersion)
 }
 
+func (a *Adapter) outputMode() string {
+	if strings.TrimSpace(a.OutputMode) == "" {
+		return OutputModeNative
+	}
+	return strings.TrimSpace(a.OutputMode)
+}
+
+// transport returns the client that sends API requests with the adapter's
+// credentials and retry policy.
+func (a *Adapter) transport() *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   "claude",
+		Header: func(header http.Header) {
+			header.Set("x-api-key", a.APIKey)
+			if version := a.version(); version != "" {
+				header.Set("anthropic-version", version)
+			}
+		},
+		DecodeError: decodeAPIError,
+		Retry:       a.Retry.retry,
+		Gzip:        a.Gzip,
+	}
+}
+
+// betas returns the anthropic-beta header for a request using the adapter's
+// beta features plus extra.
+func (a *Adapter) betas(extra ...string) http.Header {
+	if header := a.betaHeader(extra...); header != "" {
+		return http.Header{"Anthropic-Beta": {header}}
+	}
+	return nil
+}
+
+func (a *Adapter) betaHeader(extra ...string) string {
+	features := make([]string, 0, len(a.BetaFeatures)+len(extra))
+	for _, feature := range slices.Concat(a.BetaFeatures, extra) {
+		feature = strings.TrimSpace(feature)
+		if feature != "" && !slices.Contains(features, feature) {
+			features = append(features, feature)
+		}
+	}
+	return strings.Join(features, ",")
+}
+
 func resolveAPIKey() string {
 	key := strings.TrimSpace(os.Getenv(envAnthropicAPIKey))
 	if key != "" {
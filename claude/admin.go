@@ -0,0 +1,244 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+const envAnthropicAdminAPIKey = "ANTHROPIC_ADMIN_API_KEY"
+
+// AdminClient calls the Anthropic Admin API usage and cost report endpoints.
+//
+// It requires an admin API key (sk-ant-admin...), which is separate from the
+// key used for messages.
+type AdminClient struct {
+	APIKey           string
+	BaseURL          string
+	AnthropicVersion string
+	HTTPClient       *http.Client
+}
+
+// NewAdminClient creates an Admin API client.
+//
+// It accepts the adapter options; WithAPIKey sets the admin key. If no key is
+// provided, NewAdminClient reads ANTHROPIC_ADMIN_API_KEY.
+func NewAdminClient(opts ...Option) *AdminClient {
+	config := &Adapter{
+		APIKey:           strings.TrimSpace(os.Getenv(envAnthropicAdminAPIKey)),
+		BaseURL:          defaultBaseURL,
+		AnthropicVersion: defaultVersion,
+		HTTPClient:       &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(config)
+	}
+
+	return &AdminClient{
+		APIKey:           config.APIKey,
+		BaseURL:          config.BaseURL,
+		AnthropicVersion: config.AnthropicVersion,
+		HTTPClient:       config.HTTPClient,
+	}
+}
+
+// ReportParams selects the time range and grouping of a usage or cost report.
+type ReportParams struct {
+	StartingAt time.Time
+	EndingAt   time.Time
+
+	// BucketWidth is the bucket size, such as "1m", "1h", or "1d".
+	BucketWidth string
+	// GroupBy lists dimensions such as "model", "workspace_id", or "api_key_id".
+	GroupBy []string
+
+	Limit int
+	// Page is the NextPage cursor of a previous report.
+	Page string
+}
+
+// UsageReport is a page of token usage buckets.
+type UsageReport struct {
+	Buckets  []UsageBucket
+	HasMore  bool
+	NextPage string
+}
+
+type UsageBucket struct {
+	StartingAt time.Time
+	EndingAt   time.Time
+	Results    []UsageResult
+}
+
+// UsageResult is the token usage of one group within a bucket. Grouping
+// fields are empty unless the report was grouped by them.
+type UsageResult struct {
+	UncachedInputTokens        int64
+	CacheCreation5mInputTokens int64
+	CacheCreation1hInputTokens int64
+	CacheReadInputTokens       int64
+	OutputTokens               int64
+	WebSearchRequests          int64
+
+	APIKeyID      string
+	WorkspaceID   string
+	Model         string
+	ServiceTier   string
+	ContextWindow string
+}
+
+// CostReport is a page of cost buckets.
+type CostReport struct {
+	Buckets  []CostBucket
+	HasMore  bool
+	NextPage string
+}
+
+type CostBucket struct {
+	StartingAt time.Time
+	EndingAt   time.Time
+	Results    []CostResult
+}
+
+// CostResult is the cost of one group within a bucket. Amount is the decimal
+// string reported by the API, in the lowest units of Currency.
+type CostResult struct {
+	Currency string
+	Amount   string
+
+	WorkspaceID   string
+	Description   string
+	CostType      string
+	Model         string
+	ServiceTier   string
+	TokenType     string
+	ContextWindow string
+}
+
+// UsageReport returns token usage for the organization's Messages API calls.
+func (c *AdminClient) UsageReport(ctx context.Context, params ReportParams) (*UsageReport, error) {
+	var response usageReportResponse
+	if err := c.get(ctx, "/organizations/usage_report/messages", params, &response); err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{HasMore: response.HasMore, NextPage: response.NextPage}
+	for _, bucket := range response.Data {
+		out := UsageBucket{StartingAt: bucket.StartingAt, EndingAt: bucket.EndingAt}
+		for _, result := range bucket.Results {
+			item := UsageResult{
+				UncachedInputTokens:  result.UncachedInputTokens,
+				CacheReadInputTokens: result.CacheReadInputTokens,
+				OutputTokens:         result.OutputTokens,
+				APIKeyID:             result.APIKeyID,
+				WorkspaceID:          result.WorkspaceID,
+				Model:                result.Model,
+				ServiceTier:          result.ServiceTier,
+				ContextWindow:        result.ContextWindow,
+			}
+			if result.CacheCreation != nil {
+				item.CacheCreation5mInputTokens = result.CacheCreation.Ephemeral5mInputTokens
+				item.CacheCreation1hInputTokens = result.CacheCreation.Ephemeral1hInputTokens
+			}
+			if result.ServerToolUse != nil {
+				item.WebSearchRequests = result.ServerToolUse.WebSearchRequests
+			}
+			out.Results = append(out.Results, item)
+		}
+		report.Buckets = append(report.Buckets, out)
+	}
+
+	return report, nil
+}
+
+// CostReport returns the organization's costs.
+func (c *AdminClient) CostReport(ctx context.Context, params ReportParams) (*CostReport, error) {
+	var response costReportResponse
+	if err := c.get(ctx, "/organizations/cost_report", params, &response); err != nil {
+		return nil, err
+	}
+
+	report := &CostReport{HasMore: response.HasMore, NextPage: response.NextPage}
+	for _, bucket := range response.Data {
+		out := CostBucket{StartingAt: bucket.StartingAt, EndingAt: bucket.EndingAt}
+		for _, result := range bucket.Results {
+			out.Results = append(out.Results, CostResult{
+				Currency:      result.Currency,
+				Amount:        result.Amount,
+				WorkspaceID:   result.WorkspaceID,
+				Description:   result.Description,
+				CostType:      result.CostType,
+				Model:         result.Model,
+				ServiceTier:   result.ServiceTier,
+				TokenType:     result.TokenType,
+				ContextWindow: result.ContextWindow,
+			})
+		}
+		report.Buckets = append(report.Buckets, out)
+	}
+
+	return report, nil
+}
+
+func (c *AdminClient) get(ctx context.Context, path string, params ReportParams, out any) error {
+	if c == nil {
+		return errors.New("claude: admin client is nil")
+	}
+	if strings.TrimSpace(c.APIKey) == "" {
+		return errors.New("claude: admin API key is required (set ANTHROPIC_ADMIN_API_KEY or use claude.WithAPIKey)")
+	}
+	if params.StartingAt.IsZero() {
+		return errors.New("claude: report starting time is required")
+	}
+
+	client := &httpclient.Client{
+		HTTPClient: c.HTTPClient,
+		BaseURL:    nonEmpty(strings.TrimSpace(c.BaseURL), defaultBaseURL),
+		Provider:   "claude",
+		Header: func(header http.Header) {
+			header.Set("x-api-key", c.APIKey)
+			header.Set("anthropic-version", nonEmpty(strings.TrimSpace(c.AnthropicVersion), defaultVersion))
+		},
+		DecodeError: decodeAPIError,
+	}
+	if client.HTTPClient == nil {
+		client.HTTPClient = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	_, err := client.Send(ctx, httpclient.Request{Path: path + "?" + reportQuery(params).Encode()}, out)
+	return err
+}
+
+func reportQuery(params ReportParams) url.Values {
+	query := url.Values{}
+	query.Set("starting_at", params.StartingAt.UTC().Format(time.RFC3339))
+	if !params.EndingAt.IsZero() {
+		query.Set("ending_at", params.EndingAt.UTC().Format(time.RFC3339))
+	}
+	if width := strings.TrimSpace(params.BucketWidth); width != "" {
+		query.Set("bucket_width", width)
+	}
+	for _, group := range params.GroupBy {
+		if group = strings.TrimSpace(group); group != "" {
+			query.Add("group_by[]", group)
+		}
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	if page := strings.TrimSpace(params.Page); page != "" {
+		query.Set("page", page)
+	}
+	return query
+}
@@ -0,0 +1,115 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestAdminUsageReport(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/organizations/usage_report/messages" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "admin-key" {
+			t.Fatalf("expected admin key header, got %q", got)
+		}
+		query := r.URL.Query()
+		if got := query.Get("starting_at"); got != "2025-01-01T00:00:00Z" {
+			t.Fatalf("unexpected starting_at %q", got)
+		}
+		if got := query.Get("bucket_width"); got != "1d" {
+			t.Fatalf("unexpected bucket_width %q", got)
+		}
+		if got := query["group_by[]"]; len(got) != 2 || got[0] != "model" || got[1] != "workspace_id" {
+			t.Fatalf("unexpected group_by %#v", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"starting_at":"2025-01-01T00:00:00Z","ending_at":"2025-01-02T00:00:00Z","results":[{"uncached_input_tokens":10,"cache_creation":{"ephemeral_5m_input_tokens":3,"ephemeral_1h_input_tokens":4},"cache_read_input_tokens":5,"output_tokens":7,"server_tool_use":{"web_search_requests":2},"model":"claude-test","workspace_id":"wrk_1"}]}],"has_more":true,"next_page":"page_2"}`))
+	}))
+	defer server.Close()
+
+	client := NewAdminClient(WithAPIKey("admin-key"), WithBaseURL(server.URL))
+	report, err := client.UsageReport(context.Background(), ReportParams{
+		StartingAt:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		BucketWidth: "1d",
+		GroupBy:     []string{"model", "workspace_id"},
+	})
+	if err != nil {
+		t.Fatalf("usage report returned error: %v", err)
+	}
+	if !report.HasMore || report.NextPage != "page_2" {
+		t.Fatalf("unexpected pagination %#v", report)
+	}
+	if len(report.Buckets) != 1 || len(report.Buckets[0].Results) != 1 {
+		t.Fatalf("unexpected buckets %#v", report.Buckets)
+	}
+	result := report.Buckets[0].Results[0]
+	if result.UncachedInputTokens != 10 || result.CacheCreation5mInputTokens != 3 || result.CacheCreation1hInputTokens != 4 || result.CacheReadInputTokens != 5 || result.OutputTokens != 7 || result.WebSearchRequests != 2 {
+		t.Fatalf("unexpected usage result %#v", result)
+	}
+	if result.Model != "claude-test" || result.WorkspaceID != "wrk_1" {
+		t.Fatalf("unexpected grouping fields %#v", result)
+	}
+}
+
+func TestAdminCostReport(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/organizations/cost_report" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("page"); got != "page_2" {
+			t.Fatalf("unexpected page %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"starting_at":"2025-01-01T00:00:00Z","ending_at":"2025-01-02T00:00:00Z","results":[{"currency":"USD","amount":"123.45","cost_type":"tokens","model":"claude-test","token_type":"uncached_input_tokens"}]}],"has_more":false}`))
+	}))
+	defer server.Close()
+
+	client := NewAdminClient(WithAPIKey("admin-key"), WithBaseURL(server.URL))
+	report, err := client.CostReport(context.Background(), ReportParams{
+		StartingAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Page:       "page_2",
+	})
+	if err != nil {
+		t.Fatalf("cost report returned error: %v", err)
+	}
+	if len(report.Buckets) != 1 || len(report.Buckets[0].Results) != 1 {
+		t.Fatalf("unexpected buckets %#v", report.Buckets)
+	}
+	result := report.Buckets[0].Results[0]
+	if result.Currency != "USD" || result.Amount != "123.45" || result.CostType != "tokens" || result.TokenType != "uncached_input_tokens" {
+		t.Fatalf("unexpected cost result %#v", result)
+	}
+}
+
+func TestAdminReportErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"permission_error","message":"admin key required"}}`))
+	}))
+	defer server.Close()
+
+	client := NewAdminClient(WithAPIKey("regular-key"), WithBaseURL(server.URL))
+	_, err := client.UsageReport(context.Background(), ReportParams{StartingAt: time.Now()})
+	var apiErr *core.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden || apiErr.Type != "permission_error" {
+		t.Fatalf("expected permission API error, got %v", err)
+	}
+
+	if _, err := client.CostReport(context.Background(), ReportParams{}); err == nil {
+		t.Fatal("expected error without starting time")
+	}
+}
@@ -0,0 +1,107 @@
+package claude
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// roundTripFunc serves benchmark responses without a network round trip.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// cannedClient returns a client that answers every request with body.
+func cannedClient(contentType string, body []byte) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			_, _ = io.Copy(io.Discard, req.Body)
+			_ = req.Body.Close()
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {contentType}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+			Request:    req,
+		}, nil
+	})}
+}
+
+func benchmarkMessagesStream(deltas int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"type\":\"message\",\"role\":\"assistant\",\"usage\":{\"input_tokens\":10,\"output_tokens\":1}}}\n\n")
+	buf.WriteString("event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"thinking\",\"thinking\":\"\"}}\n\n")
+	for i := range deltas / 2 {
+		fmt.Fprintf(&buf, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"thinking_delta\",\"thinking\":\"step %d \"}}\n\n", i)
+	}
+	buf.WriteString("event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n")
+	buf.WriteString("event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":1,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n")
+	for i := range deltas / 2 {
+		fmt.Fprintf(&buf, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"text_delta\",\"text\":\"token %d \"}}\n\n", i)
+	}
+	buf.WriteString("event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":1}\n\n")
+	buf.WriteString("event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":1000}}\n\n")
+	buf.WriteString("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	return buf.Bytes()
+}
+
+func benchmarkConversation(turns int) *core.ChatParams {
+	messages := []core.MessageUnion{core.TextMessagePart{Role: core.RoleSystem, Content: "You are a helpful assistant."}}
+	for i := range turns {
+		id := fmt.Sprintf("toolu_%d", i)
+		messages = append(messages,
+			core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+				core.TextPart{Text: fmt.Sprintf("What is the weather in city %d?", i)},
+				core.ImagePart{Source: core.URLSource{URL: "https://example.com/map.png"}},
+			}},
+			core.ToolCallMessagePart{Role: core.RoleToolCall, ToolCalls: []core.ToolCall{
+				{ID: id, Name: "get_weather", Arguments: map[string]any{"city": i, "unit": "celsius"}},
+			}},
+			core.ToolResultMessagePart{Role: core.RoleToolResult, ToolCallID: id, Name: "get_weather", Content: `{"temperature":21}`},
+			core.TextMessagePart{Role: core.RoleAssistant, Content: "It is 21 degrees and sunny."},
+		)
+	}
+	return &core.ChatParams{Messages: messages}
+}
+
+// BenchmarkChatStream measures reading a stream of 500 thinking and 500 text
+// deltas and emitting their chunks.
+func BenchmarkChatStream(b *testing.B) {
+	body := benchmarkMessagesStream(1000)
+	adapter := New("claude-test", WithAPIKey("test-key"), WithHTTPClient(cannedClient("text/event-stream", body)))
+	params := &core.ChatParams{Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hello"}}}
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		stream, err := adapter.ChatStream(context.Background(), params)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for chunk := range stream {
+			if chunk.Type == core.StreamChunkError {
+				b.Fatal(chunk.Error)
+			}
+		}
+	}
+}
+
+// BenchmarkToMessagesAndSystem measures converting a 20 turn conversation
+// with tool calls and images to Messages API messages.
+func BenchmarkToMessagesAndSystem(b *testing.B) {
+	params := benchmarkConversation(20)
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, _, err := toMessagesAndSystem(params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
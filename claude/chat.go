@@ -1,15 +1,18 @@
 package claude
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/sse"
+	"github.com/m43i/go-ai/internal/streamtext"
 )
 
 // Chat sends a non-streaming messages request to Claude.
@@ -19,14 +22,22 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 	if err := a.validate(); err != nil {
 		return nil, err
 	}
+	params, err := a.resolveFileParts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
 
 	requestTemplate, messages, serverTools, clientTools, maxLoopCount, err := a.buildRequestTemplate(params)
 	if err != nil {
 		return nil, err
 	}
+	if err := a.checkPromptSize(ctx, &requestTemplate, messages); err != nil {
+		return nil, err
+	}
 
 	conversation := cloneCoreMessages(params)
 	reasoningParts := make([]string, 0, 4)
+	var providerCalls []core.ProviderToolCall
 
 	for range maxLoopCount {
 		request := requestTemplate
@@ -36,27 +47,68 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 		if err != nil {
 			return nil, err
 		}
+		if response.Container != nil && response.Container.ID != "" {
+			// Later loop iterations must run in the same container.
+			requestTemplate.Container = response.Container.ID
+		}
 
 		reasoningParts = appendReasoningPart(reasoningParts, extractReasoning(response.Content))
+		providerCalls = appendProviderToolCalls(providerCalls, response.Content)
 
 		toolUses := extractToolUses(response.Content)
+		if len(toolUses) == 0 && response.StopReason == "pause_turn" {
+			// A long-running server tool turn was paused; send it back to resume.
+			messages = append(messages, message{Role: "assistant", Content: response.Content})
+			continue
+		}
+		if output, ok := findToolUse(toolUses, request.OutputTool); ok {
+			encoded, err := json.Marshal(output.Input)
+			if err != nil {
+				return nil, fmt.Errorf("claude: encode structured output: %w", err)
+			}
+			text := string(encoded)
+			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: text})
+			return &core.ChatResult{
+				Text:              text,
+				Reasoning:         joinReasoningParts(reasoningParts),
+				Messages:          append([]core.MessageUnion(nil), conversation...),
+				ProviderToolCalls: providerCalls,
+				FinishReason:      core.FinishReasonStop,
+				RawFinishReason:   response.StopReason,
+				Usage:             responseUsage(response),
+				RateLimit:         response.RateLimit,
+				RequestID:         response.RequestID,
+				ContainerID:       requestTemplate.Container,
+			}, nil
+		}
 		if len(toolUses) == 0 {
 			text := extractText(response.Content)
 			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: text})
 			return &core.ChatResult{
-				Text:         text,
-				Reasoning:    joinReasoningParts(reasoningParts),
-				Messages:     append([]core.MessageUnion(nil), conversation...),
-				ToolCalls:    nil,
-				FinishReason: nonEmpty(response.StopReason, "stop"),
-				Usage:        toCoreUsage(response.Usage),
+				Text:              text,
+				Reasoning:         joinReasoningParts(reasoningParts),
+				Messages:          append([]core.MessageUnion(nil), conversation...),
+				ToolCalls:         nil,
+				Citations:         extractCitations(response.Content),
+				ProviderToolCalls: providerCalls,
+				FinishReason:      toCoreFinishReason(response.StopReason),
+				RawFinishReason:   response.StopReason,
+				StopSequence:      response.StopSequence,
+				Usage:             responseUsage(response),
+				RateLimit:         response.RateLimit,
+				RequestID:         response.RequestID,
+				ContainerID:       requestTemplate.Container,
 			}, nil
 		}
 
 		messages = append(messages, message{Role: "assistant", Content: response.Content})
 
 		coreCalls := toCoreToolCalls(toolUses)
-		conversation = append(conversation, core.ToolCallMessagePart{Role: core.RoleToolCall, ToolCalls: coreCalls})
+		conversation = append(conversation, core.ToolCallMessagePart{
+			Role:            core.RoleToolCall,
+			ToolCalls:       coreCalls,
+			ReasoningBlocks: extractReasoningBlocks(response.Content),
+		})
 
 		resultBlocks := make([]contentBlock, 0, len(toolUses))
 		pendingClientCalls := make([]core.ToolCall, 0)
@@ -88,12 +140,17 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 
 		if len(pendingClientCalls) > 0 {
 			return &core.ChatResult{
-				Text:         "",
-				Reasoning:    joinReasoningParts(reasoningParts),
-				Messages:     append([]core.MessageUnion(nil), conversation...),
-				ToolCalls:    pendingClientCalls,
-				FinishReason: "tool_calls",
-				Usage:        toCoreUsage(response.Usage),
+				Text:              "",
+				Reasoning:         joinReasoningParts(reasoningParts),
+				Messages:          append([]core.MessageUnion(nil), conversation...),
+				ToolCalls:         pendingClientCalls,
+				ProviderToolCalls: providerCalls,
+				FinishReason:      core.FinishReasonToolCalls,
+				RawFinishReason:   response.StopReason,
+				Usage:             responseUsage(response),
+				RateLimit:         response.RateLimit,
+				RequestID:         response.RequestID,
+				ContainerID:       requestTemplate.Container,
 			}, nil
 		}
 
@@ -113,30 +170,44 @@ func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-ch
 	if err := a.validate(); err != nil {
 		return nil, err
 	}
+	params, err := a.resolveFileParts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
 
 	request, messages, serverTools, clientTools, _, err := a.buildRequestTemplate(params)
 	if err != nil {
 		return nil, err
 	}
+	// The Chat fallback checks the prompt size itself.
+	fallback := len(serverTools) > 0 || len(clientTools) > 0 || (params != nil && params.Output != nil)
+	if !fallback {
+		if err := a.checkPromptSize(ctx, &request, messages); err != nil {
+			return nil, err
+		}
+	}
 
+	start := time.Now()
 	out := make(chan core.StreamChunk, 64)
 
 	go func() {
 		defer close(out)
 
-		if len(serverTools) > 0 || len(clientTools) > 0 || (params != nil && params.Output != nil) {
+		if fallback {
 			result, err := a.Chat(ctx, params)
 			if err != nil {
-				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error()}
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: core.RequestIDOf(err)}
 				return
 			}
 
 			emitChunksFromResult(out, params, result)
 			out <- core.StreamChunk{
-				Type:         core.StreamChunkDone,
-				FinishReason: nonEmpty(result.FinishReason, defaultFinishReason(result)),
-				Reasoning:    result.Reasoning,
-				Usage:        result.Usage,
+				Type:            core.StreamChunkDone,
+				FinishReason:    result.FinishReason,
+				RawFinishReason: result.RawFinishReason,
+				Reasoning:       result.Reasoning,
+				Usage:           result.Usage,
+				RequestID:       result.RequestID,
 			}
 			return
 		}
@@ -144,70 +215,68 @@ func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-ch
 		request.Messages = messages
 		request.Stream = true
 
-		url := strings.TrimRight(a.baseURL(), "/") + "/messages"
 		body, err := marshalMessageRequest(&request)
 		if err != nil {
 			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("claude: marshal stream request: %v", err)}
 			return
 		}
 
-		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		httpResp, err := a.transport().Do(ctx, httpclient.Request{Path: "/messages", Body: body, Header: a.betas(request.Betas...)})
 		if err != nil {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("claude: build stream request: %v", err)}
-			return
-		}
-
-		httpReq.Header.Set("x-api-key", a.APIKey)
-		if version := a.version(); version != "" {
-			httpReq.Header.Set("anthropic-version", version)
-		}
-		httpReq.Header.Set("content-type", "application/json")
-
-		httpResp, err := a.client().Do(httpReq)
-		if err != nil {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("claude: stream request failed: %v", err)}
+			out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: core.RequestIDOf(err)}
 			return
 		}
 		defer httpResp.Body.Close()
+		requestID := httpclient.RequestID(httpResp.Header)
 
-		if httpResp.StatusCode >= http.StatusBadRequest {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: decodeAPIError(httpResp).Error()}
-			return
-		}
-
-		scanner := bufio.NewScanner(httpResp.Body)
-		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		reader := sse.NewReader(httpResp.Body)
+		defer reader.Release()
 
 		var content strings.Builder
-		reasoning := ""
+		var reasoning streamtext.Builder
+		finishReason := ""
+		var streamUsage usage
 		var usage *core.Usage
 
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line == "" || strings.HasPrefix(line, ":") || !strings.HasPrefix(line, "data:") {
-				continue
+		for {
+			sseEvent, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("claude: stream read failed: %v", err), RequestID: requestID}
+				return
 			}
 
-			payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-			if payload == "" || payload == "[DONE]" {
+			payload := bytes.TrimSpace(sseEvent.Data)
+			if len(payload) == 0 || bytes.Equal(payload, []byte("[DONE]")) {
 				continue
 			}
 
 			var event streamEvent
-			if err := json.Unmarshal([]byte(payload), &event); err != nil {
-				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("claude: decode stream event: %v", err)}
+			if err := json.Unmarshal(payload, &event); err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("claude: decode stream event: %v", err), RequestID: requestID}
 				return
 			}
 
+			if event.Message != nil && event.Message.Usage != nil {
+				streamUsage = *event.Message.Usage
+				usage = toCoreUsage(&streamUsage)
+			}
 			if event.Usage != nil {
-				usage = toCoreUsage(event.Usage)
+				streamUsage = mergeUsage(streamUsage, *event.Usage)
+				usage = toCoreUsage(&streamUsage)
 			}
 
 			if event.Type == "error" && event.Error != nil {
-				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("claude: stream error (%s): %s", event.Error.Type, event.Error.Message)}
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("claude: stream error (%s): %s", event.Error.Type, event.Error.Message), RequestID: requestID}
 				return
 			}
 
+			if event.Type == "message_delta" && event.Delta != nil && event.Delta.StopReason != "" {
+				finishReason = event.Delta.StopReason
+			}
+
 			if event.Type == "content_block_delta" && event.Delta != nil {
 				if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
 					content.WriteString(event.Delta.Text)
@@ -222,34 +291,27 @@ func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-ch
 					if incomingReasoning == "" {
 						incomingReasoning = event.Delta.Text
 					}
-					nextReasoning, reasoningDelta := appendStreamSegment(reasoning, incomingReasoning)
-					reasoning = nextReasoning
-					if reasoningDelta != "" {
+					if reasoningDelta := reasoning.Append(incomingReasoning); reasoningDelta != "" {
 						out <- core.StreamChunk{
 							Type:      core.StreamChunkReasoning,
 							Role:      core.RoleAssistant,
 							Delta:     reasoningDelta,
-							Reasoning: reasoning,
+							Reasoning: reasoning.String(),
 						}
 					}
 				}
 			}
 
 			if event.Type == "message_stop" {
-				out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: "stop", Reasoning: reasoning, Usage: usage}
+				out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: toCoreFinishReason(finishReason), RawFinishReason: finishReason, Reasoning: reasoning.String(), Usage: usage, RequestID: requestID}
 				return
 			}
 		}
 
-		if err := scanner.Err(); err != nil {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("claude: stream read failed: %v", err)}
-			return
-		}
-
-		out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: "stop", Reasoning: reasoning, Usage: usage}
+		out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: toCoreFinishReason(finishReason), RawFinishReason: finishReason, Reasoning: reasoning.String(), Usage: usage, RequestID: requestID}
 	}()
 
-	return out, nil
+	return core.TimeStream(ctx, out, start), nil
 }
 
 func (a *Adapter) buildRequestTemplate(params *core.ChatParams) (messageRequest, []message, map[string]core.ServerTool, map[string]struct{}, int, error) {
@@ -263,57 +325,107 @@ func (a *Adapter) buildRequestTemplate(params *core.ChatParams) (messageRequest,
 		return messageRequest{}, nil, nil, nil, 0, err
 	}
 
+	if a.Memory != nil {
+		for _, existing := range tools {
+			if existing.Name == memoryToolName {
+				return messageRequest{}, nil, nil, nil, 0, fmt.Errorf("claude: tool name %q conflicts with the memory tool", memoryToolName)
+			}
+		}
+		tools = append([]tool{{Type: memoryToolType, Name: memoryToolName}}, tools...)
+		if serverTools == nil {
+			serverTools = make(map[string]core.ServerTool)
+		}
+		serverTools[memoryToolName] = memoryServerTool(a.Memory)
+	}
+
+	thinking, err := thinkingFromParams(params)
+	if err != nil {
+		return messageRequest{}, nil, nil, nil, 0, err
+	}
+
 	request := messageRequest{
-		Model:        a.Model,
-		System:       system,
-		Tools:        tools,
-		MaxTokens:    maxTokens(params),
-		Temperature:  temperature(params),
-		TopP:         topP(params),
-		Metadata:     metadata(params),
-		OutputConfig: outputConfig(params),
-		ModelOptions: modelOptions(params),
+		Model:         a.Model,
+		System:        system,
+		Tools:         tools,
+		MaxTokens:     maxTokens(a.Model, params, thinking),
+		Temperature:   temperature(params),
+		TopP:          topP(params),
+		TopK:          topK(params),
+		StopSequences: stopSequences(params),
+		Metadata:      metadata(params),
+		OutputConfig:  outputConfig(params, a.outputMode() == OutputModeNative),
+		Thinking:      thinking,
+		ModelOptions:  modelOptions(params),
+	}
+	if params != nil {
+		request.Container = strings.TrimSpace(params.ContainerID)
 	}
 
 	if len(tools) > 0 {
-		request.ToolChoice = &toolChoice{Type: "auto"}
+		request.ToolChoice, err = requestToolChoice(params)
+		if err != nil {
+			return messageRequest{}, nil, nil, nil, 0, err
+		}
 	}
 
-	return request, messages, serverTools, clientTools, maxLoops(params, len(serverTools) > 0), nil
-}
+	if a.outputMode() == OutputModeTool && params.Output != nil && params.Output.Schema != nil {
+		definition := outputTool(params.Output)
+		for _, existing := range tools {
+			if existing.Name == definition.Name {
+				return messageRequest{}, nil, nil, nil, 0, fmt.Errorf("claude: output tool name %q conflicts with a registered tool", definition.Name)
+			}
+		}
 
-func (a *Adapter) postMessages(ctx context.Context, request *messageRequest) (*messageResponse, error) {
-	body, err := marshalMessageRequest(request)
-	if err != nil {
-		return nil, fmt.Errorf("claude: marshal request: %w", err)
+		request.Tools = append(request.Tools, definition)
+		request.OutputTool = definition.Name
+		request.ToolChoice = outputToolChoice(definition.Name, len(tools) > 0, thinking != nil)
 	}
 
-	url := strings.TrimRight(a.baseURL(), "/") + "/messages"
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("claude: build request: %w", err)
+	if request.ToolChoice != nil && params.ParallelToolCalls != nil && !*params.ParallelToolCalls {
+		request.ToolChoice.DisableParallelToolUse = true
 	}
 
-	httpReq.Header.Set("x-api-key", a.APIKey)
-	if version := a.version(); version != "" {
-		httpReq.Header.Set("anthropic-version", version)
+	request.Betas, err = outputBetas(request.MaxTokens)
+	if err != nil {
+		return messageRequest{}, nil, nil, nil, 0, err
 	}
-	httpReq.Header.Set("content-type", "application/json")
 
-	httpResp, err := a.client().Do(httpReq)
+	request.MCPServers, err = toMCPServers(params)
 	if err != nil {
-		return nil, fmt.Errorf("claude: request failed: %w", err)
+		return messageRequest{}, nil, nil, nil, 0, err
+	}
+	if len(request.MCPServers) > 0 {
+		request.Betas = append(request.Betas, mcpConnectorBeta)
+	}
+	request.Betas = append(request.Betas, providerToolBetas(request.Tools)...)
+	if a.Memory != nil {
+		request.Betas = append(request.Betas, contextManagementBeta)
+	}
+	request.Betas = append(request.Betas, contextManagementBetas(request.ModelOptions)...)
+	if hasFileParts(params) {
+		request.Betas = append(request.Betas, filesBeta)
 	}
-	defer httpResp.Body.Close()
+	if a.InterleavedThinking && thinking != nil && (len(request.Tools) > 0 || len(request.MCPServers) > 0) {
+		request.Betas = append(request.Betas, interleavedThinkingBeta)
+	}
+
+	return request, messages, serverTools, clientTools, maxLoops(params, len(serverTools) > 0 || hasProviderTools(tools) || len(request.MCPServers) > 0), nil
+}
 
-	if httpResp.StatusCode >= http.StatusBadRequest {
-		return nil, decodeAPIError(httpResp)
+func (a *Adapter) postMessages(ctx context.Context, request *messageRequest) (*messageResponse, error) {
+	body, err := marshalMessageRequest(request)
+	if err != nil {
+		return nil, fmt.Errorf("claude: marshal request: %w", err)
 	}
 
 	var response messageResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("claude: decode response: %w", err)
+	httpResp, err := a.transport().Send(ctx, httpclient.Request{Path: "/messages", Body: body, Header: a.betas(request.Betas...)}, &response)
+	if err != nil {
+		return nil, err
 	}
+	response.RequestID = httpclient.RequestID(httpResp.Header)
+	response.RateLimit = parseRateLimit(httpResp.Header)
+	response.Duration = httpResp.Duration
 
 	return &response, nil
 }
@@ -333,13 +445,38 @@ func emitChunksFromResult(out chan<- core.StreamChunk, params *core.ChatParams,
 		return
 	}
 
-	if strings.TrimSpace(result.Reasoning) != "" {
-		reasoning := strings.TrimSpace(result.Reasoning)
+	// Reasoning is emitted in step order: each tool call step's thinking
+	// precedes its tool calls, and the remainder precedes the final text.
+	reasoning := strings.TrimSpace(result.Reasoning)
+	emitted := ""
+	emitReasoning := func(next string) {
+		if next == "" || len(next) <= len(emitted) || !strings.HasPrefix(reasoning, next) {
+			return
+		}
+		delta := next[len(emitted):]
+		emitted = next
 		out <- core.StreamChunk{
 			Type:      core.StreamChunkReasoning,
 			Role:      core.RoleAssistant,
-			Delta:     reasoning,
-			Reasoning: reasoning,
+			Delta:     delta,
+			Reasoning: emitted,
+		}
+	}
+	emitStepReasoning := func(blocks []core.ReasoningBlock) {
+		step := reasoningBlocksText(blocks)
+		if step == "" {
+			return
+		}
+		if emitted != "" {
+			step = emitted + "\n" + step
+		}
+		emitReasoning(step)
+	}
+	emitToolCalls := func(m core.ToolCallMessagePart) {
+		emitStepReasoning(m.ReasoningBlocks)
+		for _, call := range m.ToolCalls {
+			c := call
+			out <- core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &c}
 		}
 	}
 
@@ -355,24 +492,20 @@ func emitChunksFromResult(out chan<- core.StreamChunk, params *core.ChatParams,
 		switch m := message.(type) {
 		case core.TextMessagePart:
 			if m.Role == core.RoleAssistant {
+				emitReasoning(reasoning)
 				out <- core.StreamChunk{Type: core.StreamChunkContent, Role: core.RoleAssistant, Delta: m.Content, Content: m.Content}
 			}
 		case *core.TextMessagePart:
 			if m != nil && m.Role == core.RoleAssistant {
+				emitReasoning(reasoning)
 				out <- core.StreamChunk{Type: core.StreamChunkContent, Role: core.RoleAssistant, Delta: m.Content, Content: m.Content}
 			}
 
 		case core.ToolCallMessagePart:
-			for _, call := range m.ToolCalls {
-				c := call
-				out <- core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &c}
-			}
+			emitToolCalls(m)
 		case *core.ToolCallMessagePart:
 			if m != nil {
-				for _, call := range m.ToolCalls {
-					c := call
-					out <- core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &c}
-				}
+				emitToolCalls(*m)
 			}
 
 		case core.ToolResultMessagePart:
@@ -383,6 +516,8 @@ func emitChunksFromResult(out chan<- core.StreamChunk, params *core.ChatParams,
 			}
 		}
 	}
+
+	emitReasoning(reasoning)
 }
 
 func toCoreUsage(in *usage) *core.Usage {
@@ -403,15 +538,49 @@ func toCoreUsage(in *usage) *core.Usage {
 
 	addDetail("cache_creation_input_tokens", in.CacheCreationInputTokens)
 	addDetail("cache_read_input_tokens", in.CacheReadInputTokens)
+	if in.CacheCreation != nil {
+		addDetail("cache_creation_5m_input_tokens", in.CacheCreation.Ephemeral5mInputTokens)
+		addDetail("cache_creation_1h_input_tokens", in.CacheCreation.Ephemeral1hInputTokens)
+	}
+	if in.ServerToolUse != nil {
+		addDetail("web_search_requests", in.ServerToolUse.WebSearchRequests)
+	}
 
 	return &core.Usage{
 		PromptTokens:     in.InputTokens,
 		CompletionTokens: in.OutputTokens,
 		TotalTokens:      in.InputTokens + in.OutputTokens,
 		Details:          details,
+		ServiceTier:      in.ServiceTier,
 	}
 }
 
+// responseUsage converts the response usage, adds the request duration and
+// ID, and reports context edits the API applied as usage details.
+func responseUsage(response *messageResponse) *core.Usage {
+	out := toCoreUsage(response.Usage)
+	if out == nil {
+		out = &core.Usage{}
+	}
+	out.Duration = response.Duration
+	out.RequestID = response.RequestID
+	if response.ContextManagement == nil {
+		return out
+	}
+
+	for _, edit := range response.ContextManagement.AppliedEdits {
+		if edit.ClearedToolUses <= 0 && edit.ClearedInputTokens <= 0 {
+			continue
+		}
+		if out.Details == nil {
+			out.Details = make(map[string]int64)
+		}
+		out.Details["context_cleared_tool_uses"] += edit.ClearedToolUses
+		out.Details["context_cleared_input_tokens"] += edit.ClearedInputTokens
+	}
+	return out
+}
+
 func appendReasoningPart(parts []string, reasoning string) []string {
 	reasoning = strings.TrimSpace(reasoning)
 	if reasoning == "" {
@@ -430,11 +599,22 @@ func joinReasoningParts(parts []string) string {
 	return strings.TrimSpace(strings.Join(parts, "\n"))
 }
 
-func defaultFinishReason(result *core.ChatResult) string {
-	if result != nil && len(result.ToolCalls) > 0 {
-		return "tool_calls"
+// toCoreFinishReason maps a Claude stop_reason onto core.FinishReason.
+func toCoreFinishReason(stopReason string) core.FinishReason {
+	switch strings.TrimSpace(stopReason) {
+	case "", "end_turn":
+		return core.FinishReasonStop
+	case "max_tokens", "model_context_window_exceeded":
+		return core.FinishReasonLength
+	case "stop_sequence":
+		return core.FinishReasonStopSequence
+	case "tool_use":
+		return core.FinishReasonToolCalls
+	case "refusal":
+		return core.FinishReasonContentFilter
+	default:
+		return core.FinishReason(stopReason)
 	}
-	return "stop"
 }
 
 func nonEmpty(value, fallback string) string {
@@ -444,18 +624,3 @@ func nonEmpty(value, fallback string) string {
 	}
 	return value
 }
-
-func appendStreamSegment(current, incoming string) (next string, delta string) {
-	if incoming == "" {
-		return current, ""
-	}
-
-	if strings.HasPrefix(incoming, current) {
-		return incoming, incoming[len(current):]
-	}
-	if strings.HasPrefix(current, incoming) {
-		return current, ""
-	}
-
-	return current + incoming, incoming
-}
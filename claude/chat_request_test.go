@@ -3,9 +3,12 @@ package claude
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/m43i/go-ai/core"
 )
@@ -160,3 +163,796 @@ func TestChatRequestDefaultsMaxTokensAndAccountsForThinkingBudget(t *testing.T)
 		t.Fatalf("expected max_tokens to exceed thinking budget, got %#v", request["max_tokens"])
 	}
 }
+
+func TestChatRequestDerivesThinkingFromParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		thinking        string
+		reasoningEffort string
+		wantBudget      float64
+		wantMaxTokens   float64
+	}{
+		{name: "explicit budget", thinking: "2048", wantBudget: 2048, wantMaxTokens: 2049},
+		{name: "enabled", thinking: "enabled", wantBudget: 4096, wantMaxTokens: 4097},
+		{name: "reasoning effort", reasoningEffort: "high", wantBudget: 16384, wantMaxTokens: 16385},
+		{name: "disabled", thinking: "false", reasoningEffort: "high", wantMaxTokens: 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var request map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Fatalf("decode request: %v", err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"thinking","thinking":"hmm","signature":"sig"},{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":2}}`))
+			}))
+			defer server.Close()
+
+			adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+			result, err := core.Chat(context.Background(), core.TextOptions{
+				Adapter: adapter,
+				Messages: []core.MessageUnion{
+					core.TextMessagePart{Role: core.RoleUser, Content: "hi"},
+				},
+				Thinking:        tt.thinking,
+				ReasoningEffort: tt.reasoningEffort,
+			})
+			if err != nil {
+				t.Fatalf("chat returned error: %v", err)
+			}
+			if result.Reasoning != "hmm" {
+				t.Fatalf("expected thinking block to be surfaced, got %q", result.Reasoning)
+			}
+			if request["max_tokens"].(float64) != tt.wantMaxTokens {
+				t.Fatalf("unexpected max_tokens: %#v", request["max_tokens"])
+			}
+
+			if tt.wantBudget == 0 {
+				if _, ok := request["thinking"]; ok {
+					t.Fatalf("expected thinking to be omitted, got %#v", request["thinking"])
+				}
+				return
+			}
+
+			thinking, ok := request["thinking"].(map[string]any)
+			if !ok {
+				t.Fatalf("expected thinking config, got %#v", request["thinking"])
+			}
+			if thinking["type"] != "enabled" || thinking["budget_tokens"].(float64) != tt.wantBudget {
+				t.Fatalf("unexpected thinking config: %#v", thinking)
+			}
+		})
+	}
+}
+
+func TestChatRequestRejectsInvalidThinkingValue(t *testing.T) {
+	t.Parallel()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL("http://127.0.0.1:0"))
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "hi"},
+		},
+		Thinking: "lots",
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported thinking value") {
+		t.Fatalf("expected thinking validation error, got %v", err)
+	}
+}
+
+func TestChatRequestWebSearchProviderTool(t *testing.T) {
+	t.Parallel()
+
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		requests = append(requests, request)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"server_tool_use","id":"srvtoolu_1","name":"web_search","input":{"query":"go release"}},{"type":"web_search_tool_result","tool_use_id":"srvtoolu_1","content":[{"type":"web_search_result","url":"https://go.dev/doc/devel/release","title":"Release History","encrypted_content":"abc"}]}],"stop_reason":"pause_turn","usage":{"input_tokens":5,"output_tokens":1}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"msg_2","role":"assistant","content":[{"type":"text","text":"Go 1.25 is out.","citations":[{"type":"web_search_result_location","url":"https://go.dev/doc/devel/release","title":"Release History","encrypted_index":"idx","cited_text":"go1.25"}]}],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":4,"server_tool_use":{"web_search_requests":1}}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	result, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "Latest Go release?"},
+		},
+		Tools: []core.ToolUnion{
+			core.ProviderTool{
+				Type: "web_search",
+				Options: map[string]any{
+					"maxUses":        3,
+					"allowedDomains": []string{"go.dev"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected paused turn to be resumed, got %d requests", len(requests))
+	}
+	tools := requests[0]["tools"].([]any)
+	definition := tools[0].(map[string]any)
+	if definition["type"] != "web_search_20250305" || definition["name"] != "web_search" {
+		t.Fatalf("unexpected web search tool definition: %#v", definition)
+	}
+	if definition["max_uses"].(float64) != 3 {
+		t.Fatalf("expected max_uses option, got %#v", definition)
+	}
+	if domains := definition["allowed_domains"].([]any); len(domains) != 1 || domains[0] != "go.dev" {
+		t.Fatalf("expected allowed_domains option, got %#v", definition)
+	}
+
+	resumed := requests[1]["messages"].([]any)
+	assistant := resumed[len(resumed)-1].(map[string]any)
+	blocks := assistant["content"].([]any)
+	if assistant["role"] != "assistant" || blocks[0].(map[string]any)["type"] != "server_tool_use" || blocks[1].(map[string]any)["type"] != "web_search_tool_result" {
+		t.Fatalf("expected server tool blocks to be sent back, got %#v", assistant)
+	}
+
+	if result.Text != "Go 1.25 is out." {
+		t.Fatalf("unexpected text: %q", result.Text)
+	}
+	if len(result.Citations) != 1 || result.Citations[0].URL != "https://go.dev/doc/devel/release" || result.Citations[0].Title != "Release History" {
+		t.Fatalf("unexpected citations: %#v", result.Citations)
+	}
+	if result.Usage.Details["web_search_requests"] != 1 {
+		t.Fatalf("expected web search request count, got %#v", result.Usage.Details)
+	}
+}
+
+func TestChatRequestForwardsStopSequences(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"1, 2, 3"}],"stop_reason":"stop_sequence","stop_sequence":"4","usage":{"input_tokens":1,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	result, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "Count to ten"},
+		},
+		StopSequences: []string{"4", "", "END"},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	sequences, ok := request["stop_sequences"].([]any)
+	if !ok || len(sequences) != 2 || sequences[0] != "4" || sequences[1] != "END" {
+		t.Fatalf("unexpected stop_sequences: %#v", request["stop_sequences"])
+	}
+	if result.FinishReason != core.FinishReasonStopSequence || result.RawFinishReason != "stop_sequence" || result.StopSequence != "4" {
+		t.Fatalf("unexpected finish: %q %q", result.FinishReason, result.StopSequence)
+	}
+}
+
+func TestChatRequestForwardsTopPAndTopK(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	topP := 0.9
+	topK := int64(40)
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	_, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "hi"},
+		},
+		TopP: &topP,
+		TopK: &topK,
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if request["top_p"].(float64) != 0.9 {
+		t.Fatalf("top_p not set correctly: %#v", request)
+	}
+	if request["top_k"].(float64) != 40 {
+		t.Fatalf("top_k not set correctly: %#v", request)
+	}
+}
+
+func TestChatRequestSendsBetaFeaturesHeader(t *testing.T) {
+	t.Parallel()
+
+	var beta string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		beta = r.Header.Get("anthropic-beta")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test",
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithBetaFeatures("feature-a", " feature-b "),
+		WithBetaFeatures("feature-a", ""),
+	)
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "hi"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if beta != "feature-a,feature-b" {
+		t.Fatalf("unexpected anthropic-beta header: %q", beta)
+	}
+}
+
+func TestChatRequestEnablesLongOutputBeta(t *testing.T) {
+	t.Parallel()
+
+	var beta string
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		beta = r.Header.Get("anthropic-beta")
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	maxOutput := int64(100000)
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL), WithBetaFeatures("feature-a"))
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "Write a long report"},
+		},
+		MaxOutputTokens: &maxOutput,
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if request["max_tokens"].(float64) != 100000 {
+		t.Fatalf("unexpected max_tokens: %#v", request["max_tokens"])
+	}
+	if beta != "feature-a,"+longOutputBeta {
+		t.Fatalf("unexpected anthropic-beta header: %q", beta)
+	}
+
+	tooMany := int64(200000)
+	_, err = adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "Write a long report"},
+		},
+		MaxOutputTokens: &tooMany,
+	})
+	if err == nil || !strings.Contains(err.Error(), "exceeds the 128000 token limit") {
+		t.Fatalf("expected max output validation error, got %v", err)
+	}
+}
+
+func TestChatRequestToolOutputModeForcesSchemaTool(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"answer","input":{"answer":"42"}}],"stop_reason":"tool_use","usage":{"input_tokens":1,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	schema := core.Schema{
+		Name: "answer",
+		Schema: map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{"answer": map[string]any{"type": "string"}},
+			"required":             []string{"answer"},
+			"additionalProperties": false,
+		},
+	}
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL), WithOutputMode(OutputModeTool))
+	result, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "What is the answer?"},
+		},
+		Output: &schema,
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	if _, ok := request["output_config"]; ok {
+		t.Fatalf("expected no output_config in tool mode, got %#v", request["output_config"])
+	}
+	tools := request["tools"].([]any)
+	definition := tools[0].(map[string]any)
+	if definition["name"] != "answer" || definition["input_schema"] == nil {
+		t.Fatalf("unexpected output tool: %#v", definition)
+	}
+	choice := request["tool_choice"].(map[string]any)
+	if choice["type"] != "tool" || choice["name"] != "answer" {
+		t.Fatalf("expected forced tool choice, got %#v", choice)
+	}
+
+	if result.Text != `{"answer":"42"}` || result.FinishReason != "stop" || len(result.ToolCalls) != 0 {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+	decoded, err := core.DecodeLast[struct {
+		Answer string `json:"answer"`
+	}](result)
+	if err != nil {
+		t.Fatalf("decode structured output: %v", err)
+	}
+	if decoded.Answer != "42" {
+		t.Fatalf("unexpected decoded answer: %#v", decoded)
+	}
+}
+
+func TestValidateRejectsUnknownOutputMode(t *testing.T) {
+	t.Parallel()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithOutputMode("prompt"))
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported output mode") {
+		t.Fatalf("expected output mode error, got %v", err)
+	}
+}
+
+func TestChatRequestMCPConnector(t *testing.T) {
+	t.Parallel()
+
+	var beta string
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		beta = r.Header.Get("anthropic-beta")
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"mcp_tool_use","id":"mcptoolu_1","name":"search_issues","server_name":"tracker","input":{"query":"login"}},{"type":"mcp_tool_result","tool_use_id":"mcptoolu_1","is_error":false,"content":[{"type":"text","text":"2 open issues"}]},{"type":"text","text":"There are 2 open login issues."}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	result, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "Any login issues?"},
+		},
+		MCPServers: []core.MCPServer{
+			{
+				Name:               "tracker",
+				URL:                "https://mcp.example.com/sse",
+				AuthorizationToken: "token",
+				AllowedTools:       []string{"search_issues"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	if beta != mcpConnectorBeta {
+		t.Fatalf("unexpected anthropic-beta header: %q", beta)
+	}
+	servers := request["mcp_servers"].([]any)
+	definition := servers[0].(map[string]any)
+	if definition["type"] != "url" || definition["name"] != "tracker" || definition["url"] != "https://mcp.example.com/sse" || definition["authorization_token"] != "token" {
+		t.Fatalf("unexpected MCP server definition: %#v", definition)
+	}
+	config := definition["tool_configuration"].(map[string]any)
+	if config["enabled"] != true || config["allowed_tools"].([]any)[0] != "search_issues" {
+		t.Fatalf("unexpected tool configuration: %#v", config)
+	}
+
+	if result.Text != "There are 2 open login issues." {
+		t.Fatalf("unexpected text: %q", result.Text)
+	}
+	if len(result.ProviderToolCalls) != 1 {
+		t.Fatalf("expected one provider tool call, got %#v", result.ProviderToolCalls)
+	}
+	call := result.ProviderToolCalls[0]
+	if call.ID != "mcptoolu_1" || call.Name != "search_issues" || call.Server != "tracker" || call.Result != "2 open issues" || call.IsError {
+		t.Fatalf("unexpected provider tool call: %#v", call)
+	}
+}
+
+func TestChatReplaysThinkingBlocksWithClientToolResults(t *testing.T) {
+	t.Parallel()
+
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		requests = append(requests, request)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"thinking","thinking":"Need the weather.","signature":"sig-1"},{"type":"redacted_thinking","data":"opaque"},{"type":"tool_use","id":"toolu_1","name":"weather","input":{"city":"Berlin"}}],"stop_reason":"tool_use","usage":{"input_tokens":1,"output_tokens":2}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"msg_2","role":"assistant","content":[{"type":"text","text":"It is sunny."}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	options := core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "Weather in Berlin?"},
+		},
+		Tools:    []core.ToolUnion{core.ClientTool{Name: "weather", Description: "Get the weather"}},
+		Thinking: "enabled",
+	}
+
+	first, err := core.Chat(context.Background(), options)
+	if err != nil {
+		t.Fatalf("first chat returned error: %v", err)
+	}
+	if len(first.ToolCalls) != 1 {
+		t.Fatalf("expected a pending client tool call, got %#v", first.ToolCalls)
+	}
+
+	options.Messages = append(first.Messages, core.ToolResultMessagePart{
+		Role:       core.RoleToolResult,
+		ToolCallID: first.ToolCalls[0].ID,
+		Name:       "weather",
+		Content:    "sunny",
+	})
+	if _, err := core.Chat(context.Background(), options); err != nil {
+		t.Fatalf("second chat returned error: %v", err)
+	}
+
+	messages := requests[1]["messages"].([]any)
+	assistant := messages[1].(map[string]any)
+	blocks := assistant["content"].([]any)
+	if len(blocks) != 3 {
+		t.Fatalf("expected thinking, redacted thinking, and tool use blocks, got %#v", blocks)
+	}
+	thinking := blocks[0].(map[string]any)
+	if thinking["type"] != "thinking" || thinking["thinking"] != "Need the weather." || thinking["signature"] != "sig-1" {
+		t.Fatalf("unexpected thinking block: %#v", thinking)
+	}
+	redacted := blocks[1].(map[string]any)
+	if redacted["type"] != "redacted_thinking" || redacted["data"] != "opaque" {
+		t.Fatalf("unexpected redacted thinking block: %#v", redacted)
+	}
+	if blocks[2].(map[string]any)["type"] != "tool_use" {
+		t.Fatalf("expected tool use after thinking blocks, got %#v", blocks[2])
+	}
+}
+
+func TestChatSurfacesRequestIDAndRateLimitHeaders(t *testing.T) {
+	t.Parallel()
+
+	reset := time.Now().Add(30 * time.Second).UTC().Format(time.RFC3339)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("request-id", "req_123")
+		w.Header().Set("anthropic-ratelimit-requests-limit", "50")
+		w.Header().Set("anthropic-ratelimit-requests-remaining", "49")
+		w.Header().Set("anthropic-ratelimit-requests-reset", reset)
+		w.Header().Set("anthropic-ratelimit-tokens-limit", "40000")
+		w.Header().Set("anthropic-ratelimit-tokens-remaining", "39000")
+		_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if result.RequestID != "req_123" {
+		t.Fatalf("unexpected request id: %q", result.RequestID)
+	}
+	rateLimit := result.RateLimit
+	if rateLimit == nil || rateLimit.LimitRequests != 50 || rateLimit.RemainingRequests != 49 || rateLimit.LimitTokens != 40000 || rateLimit.RemainingTokens != 39000 {
+		t.Fatalf("unexpected rate limit: %#v", rateLimit)
+	}
+	if rateLimit.ResetRequests <= 0 || rateLimit.ResetRequests > 30*time.Second {
+		t.Fatalf("unexpected requests reset: %v", rateLimit.ResetRequests)
+	}
+}
+
+func TestChatReturnsTypedAPIErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("request-id", "req_err")
+		if r.Header.Get("x-api-key") == "limited" {
+			w.Header().Set("retry-after", "3")
+			w.Header().Set("anthropic-ratelimit-requests-limit", "50")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"bad input"}}`))
+	}))
+	defer server.Close()
+
+	params := &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	}
+
+	_, err := New("claude-test", WithAPIKey("limited"), WithBaseURL(server.URL)).Chat(context.Background(), params)
+	var rateLimitErr *core.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if rateLimitErr.RetryAfter != 3*time.Second || rateLimitErr.RequestID != "req_err" || rateLimitErr.RateLimit == nil {
+		t.Fatalf("unexpected rate limit error: %#v", rateLimitErr)
+	}
+
+	_, err = New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL)).Chat(context.Background(), params)
+	var apiErr *core.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected API error, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Type != "invalid_request_error" || apiErr.RequestID != "req_err" {
+		t.Fatalf("unexpected API error: %#v", apiErr)
+	}
+	if apiErr.Error() != "claude: API error (invalid_request_error): bad input" {
+		t.Fatalf("unexpected error message: %q", apiErr.Error())
+	}
+}
+
+func TestChatRequestDisablesParallelToolUse(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	parallel := false
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	_, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "hi"},
+		},
+		Tools:             []core.ToolUnion{core.ClientTool{Name: "lookup", Description: "Look something up"}},
+		ParallelToolCalls: &parallel,
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	choice := request["tool_choice"].(map[string]any)
+	if choice["type"] != "auto" || choice["disable_parallel_tool_use"] != true {
+		t.Fatalf("unexpected tool choice: %#v", choice)
+	}
+}
+
+func TestChatParsesDetailedUsage(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":5,"cache_creation_input_tokens":300,"cache_read_input_tokens":200,"cache_creation":{"ephemeral_5m_input_tokens":100,"ephemeral_1h_input_tokens":200},"service_tier":"priority"}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	usage := result.Usage
+	if usage.ServiceTier != "priority" {
+		t.Fatalf("unexpected service tier: %q", usage.ServiceTier)
+	}
+	want := map[string]int64{
+		"cache_creation_input_tokens":    300,
+		"cache_read_input_tokens":        200,
+		"cache_creation_5m_input_tokens": 100,
+		"cache_creation_1h_input_tokens": 200,
+	}
+	for key, value := range want {
+		if usage.Details[key] != value {
+			t.Fatalf("unexpected %s: %#v", key, usage.Details)
+		}
+	}
+}
+
+func TestChatRequestContextManagement(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	var beta string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		beta = r.Header.Get("anthropic-beta")
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":2},"context_management":{"applied_edits":[{"type":"clear_tool_uses_20250919","cleared_tool_uses":4,"cleared_input_tokens":9000}]}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+		ModelOptions: map[string]any{
+			"contextManagement": ContextManagement{ClearToolUses: &ClearToolUses{
+				TriggerInputTokens: 30000,
+				KeepToolUses:       3,
+				ExcludeTools:       []string{"web_search"},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	if beta != contextManagementBeta {
+		t.Fatalf("expected context management beta header, got %q", beta)
+	}
+	management, ok := request["context_management"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected context_management in request, got %#v", request["context_management"])
+	}
+	edits, ok := management["edits"].([]any)
+	if !ok || len(edits) != 1 {
+		t.Fatalf("expected one edit, got %#v", management["edits"])
+	}
+	edit := edits[0].(map[string]any)
+	if edit["type"] != clearToolUsesEdit {
+		t.Fatalf("unexpected edit type %#v", edit["type"])
+	}
+	if trigger := edit["trigger"].(map[string]any); trigger["type"] != "input_tokens" || trigger["value"] != float64(30000) {
+		t.Fatalf("unexpected trigger %#v", trigger)
+	}
+	if keep := edit["keep"].(map[string]any); keep["type"] != "tool_uses" || keep["value"] != float64(3) {
+		t.Fatalf("unexpected keep %#v", keep)
+	}
+	if _, ok := edit["clear_at_least"]; ok {
+		t.Fatalf("expected clear_at_least to be omitted, got %#v", edit["clear_at_least"])
+	}
+
+	if result.Usage.Details["context_cleared_tool_uses"] != 4 || result.Usage.Details["context_cleared_input_tokens"] != 9000 {
+		t.Fatalf("expected applied edits in usage details, got %#v", result.Usage.Details)
+	}
+}
+
+func TestChatReplaysCodeExecutionContainer(t *testing.T) {
+	t.Parallel()
+
+	var requests []map[string]any
+	var betas []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		requests = append(requests, request)
+		betas = append(betas, r.Header.Get("anthropic-beta"))
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"server_tool_use","id":"srvtoolu_1","name":"code_execution","input":{"code":"open('a.txt','w')"}}],"stop_reason":"pause_turn","container":{"id":"container_new","expires_at":"2025-06-01T00:00:00Z"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"msg_2","role":"assistant","content":[{"type":"text","text":"done"}],"stop_reason":"end_turn","container":{"id":"container_new"}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages:    []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "write a file"}},
+		Tools:       []core.ToolUnion{core.ProviderTool{Type: "code_execution"}},
+		ContainerID: "container_old",
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	if requests[0]["container"] != "container_old" {
+		t.Fatalf("expected caller container on first request, got %#v", requests[0]["container"])
+	}
+	if requests[1]["container"] != "container_new" {
+		t.Fatalf("expected returned container to be replayed, got %#v", requests[1]["container"])
+	}
+	tools := requests[0]["tools"].([]any)
+	if definition := tools[0].(map[string]any); definition["type"] != "code_execution_20250522" || definition["name"] != "code_execution" {
+		t.Fatalf("unexpected code execution tool %#v", definition)
+	}
+	if betas[0] != codeExecutionBeta {
+		t.Fatalf("expected code execution beta header, got %q", betas[0])
+	}
+	if result.ContainerID != "container_new" {
+		t.Fatalf("expected container id on result, got %q", result.ContainerID)
+	}
+}
+
+func TestChatStreamReportsRequestID(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("request-id", "req_stream")
+		_, _ = w.Write([]byte("data: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n"))
+		if r.Header.Get("x-api-key") == "overloaded" {
+			_, _ = w.Write([]byte("data: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"))
+			return
+		}
+		_, _ = w.Write([]byte("data: {\"type\":\"message_stop\"}\n\n"))
+	}))
+	defer server.Close()
+
+	params := &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	}
+	last := func(apiKey string) core.StreamChunk {
+		stream, err := New("claude-test", WithAPIKey(apiKey), WithBaseURL(server.URL)).ChatStream(context.Background(), params)
+		if err != nil {
+			t.Fatalf("chat stream: %v", err)
+		}
+		var chunk core.StreamChunk
+		for chunk = range stream {
+		}
+		return chunk
+	}
+
+	if done := last("test-key"); done.Type != core.StreamChunkDone || done.RequestID != "req_stream" {
+		t.Fatalf("unexpected done chunk: %#v", done)
+	}
+	if failed := last("overloaded"); failed.Type != core.StreamChunkError || failed.RequestID != "req_stream" {
+		t.Fatalf("unexpected error chunk: %#v", failed)
+	}
+}
@@ -0,0 +1,71 @@
+package claude
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	contextManagementBeta = "context-management-2025-06-27"
+	contextManagementKey  = "context_management"
+	clearToolUsesEdit     = "clear_tool_uses_20250919"
+)
+
+// ContextManagement configures server-side context editing for a request.
+//
+// Pass it per request as ModelOptions["context_management"]; the adapter
+// sends the context management beta header when that option is set.
+type ContextManagement struct {
+	// ClearToolUses clears the oldest tool results once the prompt grows
+	// past a threshold.
+	ClearToolUses *ClearToolUses
+}
+
+// ClearToolUses configures clearing of old tool results. Zero values use the
+// API defaults.
+type ClearToolUses struct {
+	// TriggerInputTokens is the prompt size in input tokens that starts clearing.
+	TriggerInputTokens int64
+	// KeepToolUses is the number of most recent tool uses to keep.
+	KeepToolUses int64
+	// ClearAtLeastInputTokens is the minimum number of input tokens to clear
+	// once triggered, so a cache breakpoint is not invalidated for little gain.
+	ClearAtLeastInputTokens int64
+	// ExcludeTools lists tools whose results are never cleared.
+	ExcludeTools []string
+	// ClearToolInputs also clears the tool call arguments.
+	ClearToolInputs bool
+}
+
+func (c ContextManagement) MarshalJSON() ([]byte, error) {
+	edits := make([]contextEdit, 0, 1)
+	if c.ClearToolUses != nil {
+		edit := contextEdit{
+			Type:            clearToolUsesEdit,
+			ExcludeTools:    c.ClearToolUses.ExcludeTools,
+			ClearToolInputs: c.ClearToolUses.ClearToolInputs,
+		}
+		if c.ClearToolUses.TriggerInputTokens > 0 {
+			edit.Trigger = &contextEditValue{Type: "input_tokens", Value: c.ClearToolUses.TriggerInputTokens}
+		}
+		if c.ClearToolUses.KeepToolUses > 0 {
+			edit.Keep = &contextEditValue{Type: "tool_uses", Value: c.ClearToolUses.KeepToolUses}
+		}
+		if c.ClearToolUses.ClearAtLeastInputTokens > 0 {
+			edit.ClearAtLeast = &contextEditValue{Type: "input_tokens", Value: c.ClearToolUses.ClearAtLeastInputTokens}
+		}
+		edits = append(edits, edit)
+	}
+	return json.Marshal(struct {
+		Edits []contextEdit `json:"edits"`
+	}{Edits: edits})
+}
+
+func contextManagementBetas(params map[string]any) []string {
+	for key, value := range params {
+		if value != nil && jsonKey(strings.TrimSpace(key)) == contextManagementKey {
+			return []string{contextManagementBeta}
+		}
+	}
+	return nil
+}
@@ -1,41 +1,100 @@
 package claude
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/m43i/go-ai/core"
 )
 
-func toMessagesAndSystem(params *core.ChatParams) ([]message, string, error) {
+func toMessagesAndSystem(params *core.ChatParams) ([]message, any, error) {
 	if params == nil {
-		return nil, "", errors.New("claude: chat params are required")
+		return nil, nil, errors.New("claude: chat params are required")
 	}
 
 	messages := make([]message, 0, len(params.Messages))
-	systemParts := make([]string, 0, len(params.SystemPrompts)+2)
+	systemBlocks := make([]contentBlock, 0, len(params.SystemPrompts)+2)
 	for _, prompt := range params.SystemPrompts {
 		prompt = strings.TrimSpace(prompt)
 		if prompt != "" {
-			systemParts = append(systemParts, prompt)
+			systemBlocks = append(systemBlocks, contentBlock{Type: "text", Text: prompt})
 		}
 	}
 
 	for i, union := range params.Messages {
 		msg, systemText, err := toMessage(union)
 		if err != nil {
-			return nil, "", fmt.Errorf("claude: invalid message at index %d: %w", i, err)
+			return nil, nil, fmt.Errorf("claude: invalid message at index %d: %w", i, err)
 		}
+
+		cache := toCacheControl(messageCacheControl(union))
 		if systemText != "" {
-			systemParts = append(systemParts, systemText)
+			systemBlocks = append(systemBlocks, contentBlock{Type: "text", Text: systemText, CacheControl: cache})
 		}
 		if msg != nil {
+			if cache != nil && len(msg.Content) > 0 {
+				msg.Content[len(msg.Content)-1].CacheControl = cache
+			}
 			messages = append(messages, *msg)
 		}
 	}
 
-	return messages, strings.Join(systemParts, "\n\n"), nil
+	if cache := toCacheControl(params.CacheSystemPrompt); cache != nil && len(systemBlocks) > 0 {
+		systemBlocks[len(systemBlocks)-1].CacheControl = cache
+	}
+
+	return messages, systemPrompt(systemBlocks), nil
+}
+
+// systemPrompt returns the system field value. A single uncached prompt is
+// sent as a plain string; otherwise each prompt becomes its own text block so
+// cache breakpoints can sit between them.
+func systemPrompt(blocks []contentBlock) any {
+	switch {
+	case len(blocks) == 0:
+		return nil
+	case len(blocks) == 1 && blocks[0].CacheControl == nil:
+		return blocks[0].Text
+	default:
+		return blocks
+	}
+}
+
+func messageCacheControl(union core.MessageUnion) *core.CacheControl {
+	switch msg := union.(type) {
+	case core.TextMessagePart:
+		return msg.CacheControl
+	case *core.TextMessagePart:
+		if msg != nil {
+			return msg.CacheControl
+		}
+	case core.ContentMessagePart:
+		return msg.CacheControl
+	case *core.ContentMessagePart:
+		if msg != nil {
+			return msg.CacheControl
+		}
+	case core.ToolResultMessagePart:
+		return msg.CacheControl
+	case *core.ToolResultMessagePart:
+		if msg != nil {
+			return msg.CacheControl
+		}
+	}
+
+	return nil
+}
+
+func toCacheControl(in *core.CacheControl) *cacheControl {
+	if in == nil {
+		return nil
+	}
+
+	return &cacheControl{Type: "ephemeral", TTL: strings.TrimSpace(in.TTL)}
 }
 
 func toMessage(union core.MessageUnion) (*message, string, error) {
@@ -57,12 +116,12 @@ func toMessage(union core.MessageUnion) (*message, string, error) {
 		return contentMessage(msg.Role, msg.Parts)
 
 	case core.AssistantToolCallMessagePart:
-		return assistantToolCallMessage(msg.Role, msg.ToolCalls)
+		return assistantToolCallMessage(msg.Role, msg.ToolCalls, msg.ReasoningBlocks)
 	case *core.AssistantToolCallMessagePart:
 		if msg == nil {
 			return nil, "", errors.New("assistant tool call message is nil")
 		}
-		return assistantToolCallMessage(msg.Role, msg.ToolCalls)
+		return assistantToolCallMessage(msg.Role, msg.ToolCalls, msg.ReasoningBlocks)
 
 	case core.ToolResultMessagePart:
 		return toolResultMessage(msg.Role, msg.ToolCallID, msg.Content)
@@ -118,6 +177,9 @@ func toContentBlocks(parts []core.ContentPart) ([]contentBlock, error) {
 
 	out := make([]contentBlock, 0, len(parts))
 	for i, part := range parts {
+		if raw, ok := rawPartValue(part); ok && !isOwnRawPart(raw) {
+			continue
+		}
 		block, err := toContentBlock(part)
 		if err != nil {
 			return nil, fmt.Errorf("content part at index %d: %w", i, err)
@@ -155,17 +217,119 @@ func toContentBlock(part core.ContentPart) (contentBlock, error) {
 		return contentBlock{}, errors.New("claude: audio content is not supported by the Messages API")
 
 	case core.DocumentPart:
-		return documentBlock(typed.Source)
+		return documentBlock(typed.Source, typed.Metadata)
 	case *core.DocumentPart:
 		if typed == nil {
 			return contentBlock{}, errors.New("document part is nil")
 		}
-		return documentBlock(typed.Source)
+		return documentBlock(typed.Source, typed.Metadata)
+
+	case core.SearchResultPart:
+		return searchResultBlock(typed)
+	case *core.SearchResultPart:
+		if typed == nil {
+			return contentBlock{}, errors.New("search result part is nil")
+		}
+		return searchResultBlock(*typed)
+
+	case core.FilePart:
+		return fileBlock(typed)
+	case *core.FilePart:
+		if typed == nil {
+			return contentBlock{}, errors.New("file part is nil")
+		}
+		return fileBlock(*typed)
+
+	case core.RawPart:
+		payload, err := rawPartPayload(typed)
+		return contentBlock{Raw: payload}, err
+	case *core.RawPart:
+		if typed == nil {
+			return contentBlock{}, errors.New("raw part is nil")
+		}
+		payload, err := rawPartPayload(*typed)
+		return contentBlock{Raw: payload}, err
 	}
 
 	return contentBlock{}, fmt.Errorf("unsupported content part type %T", part)
 }
 
+// fileBlock references an uploaded file as an image block for image MIME
+// types and as a document block otherwise. Chat and ChatStream upload
+// FileParts that carry data before converting messages.
+func fileBlock(part core.FilePart) (contentBlock, error) {
+	fileID := strings.TrimSpace(part.FileID)
+	if fileID == "" {
+		return contentBlock{}, errors.New("file part requires a FileID or data")
+	}
+
+	source := &mediaSource{Type: "file", FileID: fileID}
+	if isClaudeImageMimeType(strings.TrimSpace(part.MimeType)) {
+		return contentBlock{Type: "image", Source: source}, nil
+	}
+	return contentBlock{Type: "document", Source: source}, nil
+}
+
+func rawPartValue(part core.ContentPart) (core.RawPart, bool) {
+	switch typed := part.(type) {
+	case core.RawPart:
+		return typed, true
+	case *core.RawPart:
+		if typed == nil {
+			return core.RawPart{}, false
+		}
+		return *typed, true
+	}
+	return core.RawPart{}, false
+}
+
+// isOwnRawPart reports whether a raw part targets this adapter; raw parts for
+// other providers are skipped.
+func isOwnRawPart(part core.RawPart) bool {
+	return strings.EqualFold(strings.TrimSpace(part.Provider), providerName)
+}
+
+func rawPartPayload(part core.RawPart) (json.RawMessage, error) {
+	payload := bytes.TrimSpace(part.Payload)
+	if len(payload) == 0 || payload[0] != '{' || !json.Valid(payload) {
+		return nil, errors.New("raw part payload must be a JSON object")
+	}
+	return json.RawMessage(payload), nil
+}
+
+func searchResultBlock(part core.SearchResultPart) (contentBlock, error) {
+	source := strings.TrimSpace(part.Source)
+	if source == "" {
+		return contentBlock{}, errors.New("search result source is required")
+	}
+	title := strings.TrimSpace(part.Title)
+	if title == "" {
+		return contentBlock{}, errors.New("search result title is required")
+	}
+
+	texts := make([]contentBlock, 0, len(part.Texts))
+	for _, text := range part.Texts {
+		if strings.TrimSpace(text) != "" {
+			texts = append(texts, contentBlock{Type: "text", Text: text})
+		}
+	}
+	if len(texts) == 0 {
+		return contentBlock{}, errors.New("search result must include text content")
+	}
+
+	block := contentBlock{
+		Type:         "search_result",
+		SearchSource: source,
+		Title:        title,
+		Content:      texts,
+	}
+	if part.Citations {
+		enabled := true
+		block.Citations = &citations{Enabled: &enabled}
+	}
+	return block, nil
+}
+
 func imageBlock(source core.Source) (contentBlock, error) {
 	if source == nil {
 		return contentBlock{}, errors.New("image source is required")
@@ -182,7 +346,9 @@ func imageBlock(source core.Source) (contentBlock, error) {
 	return contentBlock{Type: "image", Source: ms}, nil
 }
 
-func documentBlock(source core.Source) (contentBlock, error) {
+// documentBlock converts a document part. The "title", "context", and
+// "citations" (bool) metadata keys map onto the matching document fields.
+func documentBlock(source core.Source, metadata map[string]any) (contentBlock, error) {
 	if source == nil {
 		return contentBlock{}, errors.New("document source is required")
 	}
@@ -198,7 +364,22 @@ func documentBlock(source core.Source) (contentBlock, error) {
 		return contentBlock{}, fmt.Errorf("unsupported document mime type %q", ms.MediaType)
 	}
 
-	return contentBlock{Type: "document", Source: ms}, nil
+	block := contentBlock{
+		Type:    "document",
+		Source:  ms,
+		Title:   metadataString(metadata, "title"),
+		Context: metadataString(metadata, "context"),
+	}
+	if enabled, ok := metadata["citations"].(bool); ok {
+		block.Citations = &citations{Enabled: &enabled}
+	}
+
+	return block, nil
+}
+
+func metadataString(metadata map[string]any, key string) string {
+	value, _ := metadata[key].(string)
+	return strings.TrimSpace(value)
 }
 
 func isClaudeImageMimeType(mimeType string) bool {
@@ -258,7 +439,7 @@ func dataMediaSource(source core.DataSource) (*mediaSource, error) {
 	return &mediaSource{Type: "base64", MediaType: mimeType, Data: data}, nil
 }
 
-func assistantToolCallMessage(role string, calls []core.ToolCall) (*message, string, error) {
+func assistantToolCallMessage(role string, calls []core.ToolCall, reasoning []core.ReasoningBlock) (*message, string, error) {
 	role = strings.TrimSpace(strings.ToLower(role))
 	if role == "" {
 		role = core.RoleToolCall
@@ -270,7 +451,16 @@ func assistantToolCallMessage(role string, calls []core.ToolCall) (*message, str
 		return nil, "", errors.New("assistant tool call message must include at least one tool call")
 	}
 
-	blocks := make([]contentBlock, 0, len(calls))
+	blocks := make([]contentBlock, 0, len(reasoning)+len(calls))
+	for _, block := range reasoning {
+		switch block.Type {
+		case "thinking":
+			blocks = append(blocks, contentBlock{Type: "thinking", Thinking: block.Text, Signature: block.Signature})
+		case "redacted_thinking":
+			blocks = append(blocks, contentBlock{Type: "redacted_thinking", Data: block.Data})
+		}
+	}
+
 	for i, call := range calls {
 		name := strings.TrimSpace(call.Name)
 		if name == "" {
@@ -413,14 +603,81 @@ func toTools(params *core.ChatParams) ([]tool, map[string]core.ServerTool, map[s
 			tools = append(tools, definition)
 			clientTools[definition.Name] = struct{}{}
 
+		case core.ProviderTool:
+			definition, err := newProviderTool(toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("claude: invalid provider tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, definition.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+
+		case *core.ProviderTool:
+			if toolValue == nil {
+				return nil, nil, nil, fmt.Errorf("claude: provider tool at index %d is nil", i)
+			}
+			definition, err := newProviderTool(*toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("claude: invalid provider tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, definition.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+
 		default:
 			return nil, nil, nil, fmt.Errorf("claude: unsupported tool type %T", union)
 		}
 	}
 
+	if cache := toCacheControl(params.CacheTools); cache != nil && len(tools) > 0 {
+		tools[len(tools)-1].CacheControl = cache
+	}
+
 	return tools, serverTools, clientTools, nil
 }
 
+// newProviderTool builds a definition for a tool Anthropic executes itself.
+// Unversioned types such as "web_search" resolve to the current version.
+func newProviderTool(toolValue core.ProviderTool) (tool, error) {
+	toolType := strings.TrimSpace(toolValue.Type)
+	if toolType == "" {
+		return tool{}, errors.New("tool type is required")
+	}
+
+	name := strings.TrimSpace(toolValue.Name)
+	if versioned, ok := providerToolVersions[toolType]; ok {
+		if name == "" {
+			name = toolType
+		}
+		toolType = versioned
+	}
+	if name == "" {
+		name = providerToolName(toolType)
+	}
+
+	return tool{Type: toolType, Name: name, Options: toolValue.Options}, nil
+}
+
+var providerToolVersions = map[string]string{
+	"web_search":     "web_search_20250305",
+	"code_execution": "code_execution_20250522",
+}
+
+// providerToolName strips the date suffix from a versioned tool type, so
+// "web_search_20250305" is named "web_search".
+func providerToolName(toolType string) string {
+	idx := strings.LastIndex(toolType, "_")
+	if idx <= 0 {
+		return toolType
+	}
+	if _, err := strconv.Atoi(toolType[idx+1:]); err != nil {
+		return toolType
+	}
+	return toolType[:idx]
+}
+
 func newServerTool(toolValue core.ServerTool) (tool, core.ServerTool, error) {
 	name := strings.TrimSpace(toolValue.Name)
 	if name == "" {
@@ -463,25 +720,110 @@ func assertNewToolName(seen map[string]struct{}, name string) error {
 	return nil
 }
 
-func maxTokens(params *core.ChatParams) int64 {
-	base := int64(1024)
+// maxTokens returns the required max_tokens value. Without an explicit limit
+// it defaults to defaultMaxTokens; explicit limits and the raise above a
+// thinking budget are clamped to the model's maximum output from the model
+// catalog.
+func maxTokens(model string, params *core.ChatParams, thinking *thinkingConfig) int64 {
+	base := int64(defaultMaxTokens)
 	if params == nil {
 		return base
 	}
 	if params.MaxTokens != nil && *params.MaxTokens > 0 {
-		base = *params.MaxTokens
+		base = core.ClampOutputTokens(model, *params.MaxTokens)
 	} else if params.MaxOutputTokens != nil && *params.MaxOutputTokens > 0 {
-		base = *params.MaxOutputTokens
+		base = core.ClampOutputTokens(model, *params.MaxOutputTokens)
 	} else if params.MaxLength > 0 {
-		base = params.MaxLength
+		base = core.ClampOutputTokens(model, params.MaxLength)
 	}
 
-	if budget := thinkingBudgetTokens(params.ModelOptions); budget >= base {
-		return budget + 1
+	budget := thinkingBudgetTokens(params.ModelOptions)
+	if budget == 0 && thinking != nil {
+		budget = thinking.BudgetTokens
+	}
+	if budget >= base {
+		return core.ClampOutputTokens(model, budget+1)
 	}
 	return base
 }
 
+// outputBetas returns the beta features needed for maxTokens. Outputs above
+// the standard limit require the extended output beta, up to its own limit.
+func outputBetas(maxTokens int64) ([]string, error) {
+	if maxTokens > maxLongOutputTokens {
+		return nil, fmt.Errorf("claude: max output tokens %d exceeds the %d token limit", maxTokens, maxLongOutputTokens)
+	}
+	if maxTokens > maxStandardOutputTokens {
+		return []string{longOutputBeta}, nil
+	}
+	return nil, nil
+}
+
+func stopSequences(params *core.ChatParams) []string {
+	if params == nil || len(params.StopSequences) == 0 {
+		return nil
+	}
+
+	out := make([]string, 0, len(params.StopSequences))
+	for _, sequence := range params.StopSequences {
+		if sequence != "" {
+			out = append(out, sequence)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// thinkingFromParams derives the extended thinking configuration from
+// ChatParams.Thinking, falling back to ChatParams.ReasoningEffort.
+//
+// Thinking accepts "true"/"enabled", "false"/"disabled", an effort level
+// ("low", "medium", "high"), or an explicit budget such as "8192".
+func thinkingFromParams(params *core.ChatParams) (*thinkingConfig, error) {
+	if params == nil {
+		return nil, nil
+	}
+
+	raw := strings.ToLower(strings.TrimSpace(params.Thinking))
+	switch raw {
+	case "":
+		if budget := thinkingBudgetForEffort(strings.ToLower(strings.TrimSpace(params.ReasoningEffort))); budget > 0 {
+			return &thinkingConfig{Type: "enabled", BudgetTokens: budget}, nil
+		}
+		return nil, nil
+	case "false", "disabled", "off", "none":
+		return nil, nil
+	case "true", "enabled", "on":
+		return &thinkingConfig{Type: "enabled", BudgetTokens: defaultThinkingBudget}, nil
+	}
+
+	if budget := thinkingBudgetForEffort(raw); budget > 0 {
+		return &thinkingConfig{Type: "enabled", BudgetTokens: budget}, nil
+	}
+
+	budget, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || budget <= 0 {
+		return nil, fmt.Errorf("claude: unsupported thinking value %q", params.Thinking)
+	}
+
+	return &thinkingConfig{Type: "enabled", BudgetTokens: max(budget, minThinkingBudget)}, nil
+}
+
+func thinkingBudgetForEffort(effort string) int64 {
+	switch effort {
+	case "minimal", "low":
+		return minThinkingBudget
+	case "medium":
+		return defaultThinkingBudget
+	case "high":
+		return 16384
+	default:
+		return 0
+	}
+}
+
 func thinkingBudgetTokens(modelOptions map[string]any) int64 {
 	thinking, ok := modelOptions["thinking"].(map[string]any)
 	if !ok || thinking["type"] != "enabled" {
@@ -514,6 +856,13 @@ func topP(params *core.ChatParams) *float64 {
 	return params.TopP
 }
 
+func topK(params *core.ChatParams) *int64 {
+	if params == nil {
+		return nil
+	}
+	return params.TopK
+}
+
 func metadata(params *core.ChatParams) map[string]any {
 	if params == nil || len(params.Metadata) == 0 {
 		return nil
@@ -528,23 +877,141 @@ func modelOptions(params *core.ChatParams) map[string]any {
 	return params.ModelOptions
 }
 
-func outputConfig(params *core.ChatParams) map[string]any {
+// outputConfig builds output_config. The json_schema format is only included
+// when includeFormat is set; the tool output mode carries the schema itself.
+func outputConfig(params *core.ChatParams, includeFormat bool) any {
 	if params == nil || params.Output == nil || params.Output.Schema == nil {
 		return nil
 	}
 
-	config := map[string]any{
-		"format": map[string]any{
+	config := map[string]any{}
+	if includeFormat {
+		config["format"] = map[string]any{
 			"type":   "json_schema",
 			"schema": params.Output.Schema,
-		},
+		}
 	}
 	if effort := strings.TrimSpace(params.ReasoningEffort); effort != "" {
 		config["effort"] = effort
 	}
+	if len(config) == 0 {
+		return nil
+	}
 	return config
 }
 
+// outputTool defines the synthetic tool used by OutputModeTool. The schema
+// name is used as the tool name when it is a valid tool name.
+func outputTool(output *core.Schema) tool {
+	name := strings.TrimSpace(output.Name)
+	if !isValidToolName(name) {
+		name = defaultOutputToolName
+	}
+
+	return newToolDefinition(name, "Respond with the final answer by calling this tool. Its input is the structured response.", output.Schema)
+}
+
+func isValidToolName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r == '-' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// requestToolChoice converts the tool choice into a Claude tool_choice,
+// defaulting to automatic choice. ToolChoiceRequired maps to "any".
+func requestToolChoice(params *core.ChatParams) (*toolChoice, error) {
+	if params == nil || params.ToolChoice == nil {
+		return &toolChoice{Type: "auto"}, nil
+	}
+
+	if name := strings.TrimSpace(params.ToolChoice.Name); name != "" {
+		return &toolChoice{Type: "tool", Name: name}, nil
+	}
+
+	switch mode := strings.TrimSpace(params.ToolChoice.Mode); mode {
+	case "", core.ToolChoiceAuto:
+		return &toolChoice{Type: "auto"}, nil
+	case core.ToolChoiceNone:
+		return &toolChoice{Type: "none"}, nil
+	case core.ToolChoiceRequired:
+		return &toolChoice{Type: "any"}, nil
+	default:
+		return nil, fmt.Errorf("claude: unsupported tool choice mode %q", mode)
+	}
+}
+
+// outputToolChoice forces the output tool. With other tools registered the
+// model may call any tool, and extended thinking only permits automatic choice.
+func outputToolChoice(name string, hasOtherTools, thinking bool) *toolChoice {
+	switch {
+	case thinking:
+		return &toolChoice{Type: "auto"}
+	case hasOtherTools:
+		return &toolChoice{Type: "any"}
+	default:
+		return &toolChoice{Type: "tool", Name: name}
+	}
+}
+
+func toMCPServers(params *core.ChatParams) ([]mcpServer, error) {
+	if params == nil || len(params.MCPServers) == 0 {
+		return nil, nil
+	}
+
+	out := make([]mcpServer, 0, len(params.MCPServers))
+	for i, server := range params.MCPServers {
+		name := strings.TrimSpace(server.Name)
+		url := strings.TrimSpace(server.URL)
+		if name == "" {
+			return nil, fmt.Errorf("claude: MCP server at index %d is missing a name", i)
+		}
+		if url == "" {
+			return nil, fmt.Errorf("claude: MCP server %q is missing a URL", name)
+		}
+
+		definition := mcpServer{
+			Type:               "url",
+			URL:                url,
+			Name:               name,
+			AuthorizationToken: strings.TrimSpace(server.AuthorizationToken),
+		}
+		if len(server.AllowedTools) > 0 {
+			definition.ToolConfiguration = &mcpToolConfiguration{Enabled: true, AllowedTools: server.AllowedTools}
+		}
+		out = append(out, definition)
+	}
+
+	return out, nil
+}
+
+// hasProviderTools reports whether any tool runs on Anthropic's side, which
+// may pause a turn that must be resumed.
+func hasProviderTools(tools []tool) bool {
+	for _, definition := range tools {
+		if definition.Type != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// providerToolBetas returns the beta features the provider tools need.
+func providerToolBetas(tools []tool) []string {
+	var betas []string
+	for _, definition := range tools {
+		if strings.HasPrefix(definition.Type, "code_execution_") {
+			betas = append(betas, codeExecutionBeta)
+		}
+	}
+	return betas
+}
+
 func maxLoops(params *core.ChatParams, hasServerTools bool) int {
 	if !hasServerTools {
 		return 1
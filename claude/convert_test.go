@@ -1,6 +1,7 @@
 package claude
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -486,6 +487,56 @@ func TestDocumentBlockPointerNil(t *testing.T) {
 	}
 }
 
+func TestDocumentBlockMetadata(t *testing.T) {
+	t.Parallel()
+
+	part := core.DocumentPart{
+		Source: core.DataSource{Data: "cGRm", MimeType: "application/pdf"},
+		Metadata: map[string]any{
+			"title":     "Annual Report",
+			"context":   "Fiscal year 2025",
+			"citations": true,
+		},
+	}
+	result, err := toContentBlock(part)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal block: %v", err)
+	}
+	var encoded map[string]any
+	if err := json.Unmarshal(body, &encoded); err != nil {
+		t.Fatalf("unmarshal block: %v", err)
+	}
+	if encoded["title"] != "Annual Report" || encoded["context"] != "Fiscal year 2025" {
+		t.Fatalf("unexpected document fields: %s", body)
+	}
+	if config, ok := encoded["citations"].(map[string]any); !ok || config["enabled"] != true {
+		t.Fatalf("expected citations config, got %s", body)
+	}
+}
+
+func TestExtractCitationsFromDocumentLocations(t *testing.T) {
+	t.Parallel()
+
+	var response messageResponse
+	payload := `{"content":[{"type":"text","text":"Revenue grew 12%.","citations":[{"type":"page_location","cited_text":"Revenue increased 12%","document_index":1,"document_title":"Annual Report","start_page_number":3,"end_page_number":4}]}]}`
+	if err := json.Unmarshal([]byte(payload), &response); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+
+	got := extractCitations(response.Content)
+	if len(got) != 1 {
+		t.Fatalf("expected one citation, got %#v", got)
+	}
+	if got[0].Type != "page_location" || got[0].Title != "Annual Report" || got[0].CitedText != "Revenue increased 12%" || got[0].DocumentIndex != 1 {
+		t.Fatalf("unexpected citation: %#v", got[0])
+	}
+}
+
 // ---------------------------------------------------------------------------
 // mediaSourceFromSource
 // ---------------------------------------------------------------------------
@@ -687,3 +738,283 @@ func TestToMessagesAndSystemNilParams(t *testing.T) {
 		t.Fatal("expected error for nil params")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Prompt caching
+// ---------------------------------------------------------------------------
+
+func TestToMessagesAndSystemCacheControl(t *testing.T) {
+	t.Parallel()
+
+	params := &core.ChatParams{
+		SystemPrompts:     []string{"Large static instructions."},
+		CacheSystemPrompt: &core.CacheControl{TTL: "1h"},
+		Messages: []core.MessageUnion{
+			core.ContentMessagePart{
+				Role: "user",
+				Parts: []core.ContentPart{
+					core.TextPart{Text: "Reference document"},
+					core.TextPart{Text: "Question"},
+				},
+				CacheControl: &core.CacheControl{},
+			},
+			core.TextMessagePart{Role: "assistant", Content: "Answer"},
+		},
+	}
+
+	messages, system, err := toMessagesAndSystem(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	blocks, ok := system.([]contentBlock)
+	if !ok || len(blocks) != 1 {
+		t.Fatalf("expected system as a single text block, got %#v", system)
+	}
+	if blocks[0].CacheControl == nil || blocks[0].CacheControl.Type != "ephemeral" || blocks[0].CacheControl.TTL != "1h" {
+		t.Fatalf("unexpected system cache control: %#v", blocks[0].CacheControl)
+	}
+
+	if messages[0].Content[0].CacheControl != nil {
+		t.Fatal("expected breakpoint only on the last block of the message")
+	}
+	if cache := messages[0].Content[1].CacheControl; cache == nil || cache.Type != "ephemeral" || cache.TTL != "" {
+		t.Fatalf("unexpected message cache control: %#v", cache)
+	}
+	if messages[1].Content[0].CacheControl != nil {
+		t.Fatal("expected unmarked message to have no cache control")
+	}
+}
+
+func TestToMessagesAndSystemCacheControlFromSystemMessage(t *testing.T) {
+	t.Parallel()
+
+	params := &core.ChatParams{
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: "system", Content: "Be brief.", CacheControl: &core.CacheControl{}},
+			core.TextMessagePart{Role: "user", Content: "Hi"},
+		},
+	}
+
+	_, system, err := toMessagesAndSystem(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	blocks, ok := system.([]contentBlock)
+	if !ok || len(blocks) != 1 || blocks[0].Text != "Be brief." || blocks[0].CacheControl == nil {
+		t.Fatalf("expected cached system block, got %#v", system)
+	}
+}
+
+func TestToToolsCacheControlMarksLastTool(t *testing.T) {
+	t.Parallel()
+
+	params := &core.ChatParams{
+		Tools: []core.ToolUnion{
+			core.ClientTool{Name: "first", Description: "first tool"},
+			core.ClientTool{Name: "second", Description: "second tool"},
+		},
+		CacheTools: &core.CacheControl{TTL: "5m"},
+	}
+
+	tools, _, _, err := toTools(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tools[0].CacheControl != nil {
+		t.Fatal("expected first tool to have no cache control")
+	}
+	if cache := tools[1].CacheControl; cache == nil || cache.Type != "ephemeral" || cache.TTL != "5m" {
+		t.Fatalf("unexpected tool cache control: %#v", cache)
+	}
+}
+
+func TestToMessagesAndSystemMultipleSystemBlocks(t *testing.T) {
+	t.Parallel()
+
+	params := &core.ChatParams{
+		SystemPrompts: []string{"Static instructions.", "  "},
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: "system", Content: "Reference corpus.", CacheControl: &core.CacheControl{}},
+			core.TextMessagePart{Role: "system", Content: "Today is Monday."},
+			core.TextMessagePart{Role: "user", Content: "Hi"},
+		},
+	}
+
+	_, system, err := toMessagesAndSystem(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	blocks, ok := system.([]contentBlock)
+	if !ok || len(blocks) != 3 {
+		t.Fatalf("expected three system blocks, got %#v", system)
+	}
+	if blocks[0].Text != "Static instructions." || blocks[1].Text != "Reference corpus." || blocks[2].Text != "Today is Monday." {
+		t.Fatalf("unexpected system block order: %#v", blocks)
+	}
+	if blocks[0].CacheControl != nil || blocks[1].CacheControl == nil || blocks[2].CacheControl != nil {
+		t.Fatalf("expected breakpoint only on the marked system block: %#v", blocks)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Search results
+// ---------------------------------------------------------------------------
+
+func TestSearchResultBlock(t *testing.T) {
+	t.Parallel()
+
+	part := core.SearchResultPart{
+		Source:    "https://docs.example.com/refunds",
+		Title:     "Refund policy",
+		Texts:     []string{"Refunds are issued within 14 days.", " "},
+		Citations: true,
+	}
+	block, err := toContentBlock(part)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, err := json.Marshal(block)
+	if err != nil {
+		t.Fatalf("marshal block: %v", err)
+	}
+	var encoded map[string]any
+	if err := json.Unmarshal(body, &encoded); err != nil {
+		t.Fatalf("unmarshal block: %v", err)
+	}
+	if encoded["type"] != "search_result" || encoded["source"] != "https://docs.example.com/refunds" || encoded["title"] != "Refund policy" {
+		t.Fatalf("unexpected search result block: %s", body)
+	}
+	content := encoded["content"].([]any)
+	if len(content) != 1 || content[0].(map[string]any)["text"] != "Refunds are issued within 14 days." {
+		t.Fatalf("unexpected search result content: %s", body)
+	}
+	if config, ok := encoded["citations"].(map[string]any); !ok || config["enabled"] != true {
+		t.Fatalf("expected citations config, got %s", body)
+	}
+}
+
+func TestSearchResultBlockRequiresFields(t *testing.T) {
+	t.Parallel()
+
+	if _, err := toContentBlock(core.SearchResultPart{Title: "t", Texts: []string{"x"}}); err == nil || !strings.Contains(err.Error(), "source is required") {
+		t.Fatalf("expected source error, got %v", err)
+	}
+	if _, err := toContentBlock(core.SearchResultPart{Source: "s", Texts: []string{"x"}}); err == nil || !strings.Contains(err.Error(), "title is required") {
+		t.Fatalf("expected title error, got %v", err)
+	}
+	if _, err := toContentBlock(core.SearchResultPart{Source: "s", Title: "t"}); err == nil || !strings.Contains(err.Error(), "text content") {
+		t.Fatalf("expected content error, got %v", err)
+	}
+}
+
+func TestExtractCitationsFromSearchResultLocations(t *testing.T) {
+	t.Parallel()
+
+	var response messageResponse
+	payload := `{"content":[{"type":"text","text":"Within two weeks.","citations":[{"type":"search_result_location","source":"https://docs.example.com/refunds","title":"Refund policy","cited_text":"Refunds are issued within 14 days.","search_result_index":0,"start_block_index":0,"end_block_index":1}]}]}`
+	if err := json.Unmarshal([]byte(payload), &response); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+
+	got := extractCitations(response.Content)
+	if len(got) != 1 || got[0].URL != "https://docs.example.com/refunds" || got[0].Title != "Refund policy" || got[0].CitedText == "" {
+		t.Fatalf("unexpected citations: %#v", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// toCoreFinishReason
+// ---------------------------------------------------------------------------
+
+func TestToCoreFinishReason(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]core.FinishReason{
+		"end_turn":      core.FinishReasonStop,
+		"":              core.FinishReasonStop,
+		"max_tokens":    core.FinishReasonLength,
+		"stop_sequence": core.FinishReasonStopSequence,
+		"tool_use":      core.FinishReasonToolCalls,
+		"refusal":       core.FinishReasonContentFilter,
+		"pause_turn":    core.FinishReason("pause_turn"),
+	}
+	for raw, want := range tests {
+		if got := toCoreFinishReason(raw); got != want {
+			t.Fatalf("toCoreFinishReason(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestToContentBlocksInjectsOwnRawParts(t *testing.T) {
+	t.Parallel()
+
+	blocks, err := toContentBlocks([]core.ContentPart{
+		core.RawPart{Provider: "openai", Payload: json.RawMessage(`{"type":"input_video"}`)},
+		core.TextPart{Text: "Hi"},
+		&core.RawPart{Provider: "claude", Payload: json.RawMessage(` {"type":"container_upload","file_id":"file_1"} `)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, err := json.Marshal(blocks)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	expected := `[{"type":"text","text":"Hi"},{"type":"container_upload","file_id":"file_1"}]`
+	if string(body) != expected {
+		t.Fatalf("unexpected blocks JSON %s", body)
+	}
+}
+
+func TestRequestToolChoice(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		choice   *core.ToolChoice
+		expected toolChoice
+	}{
+		{nil, toolChoice{Type: "auto"}},
+		{&core.ToolChoice{Mode: core.ToolChoiceNone}, toolChoice{Type: "none"}},
+		{&core.ToolChoice{Mode: core.ToolChoiceRequired}, toolChoice{Type: "any"}},
+		{&core.ToolChoice{Mode: core.ToolChoiceNone, Name: "weather"}, toolChoice{Type: "tool", Name: "weather"}},
+	}
+	for _, test := range tests {
+		choice, err := requestToolChoice(&core.ChatParams{ToolChoice: test.choice})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *choice != test.expected {
+			t.Fatalf("tool choice %#v: got %#v, want %#v", test.choice, *choice, test.expected)
+		}
+	}
+
+	if _, err := requestToolChoice(&core.ChatParams{ToolChoice: &core.ToolChoice{Mode: "sometimes"}}); err == nil {
+		t.Fatal("expected error for unknown tool choice mode")
+	}
+}
+
+func TestMaxTokensUsesModelCatalog(t *testing.T) {
+	t.Parallel()
+
+	if got := maxTokens("claude-sonnet-4-5-20250929", &core.ChatParams{}, nil); got != defaultMaxTokens {
+		t.Fatalf("expected default %d, got %d", defaultMaxTokens, got)
+	}
+	if got := maxTokens("claude-3-7-sonnet-20250219", &core.ChatParams{}, nil); got != defaultMaxTokens {
+		t.Fatalf("expected default %d without the extended output beta, got %d", defaultMaxTokens, got)
+	}
+
+	requested := int64(200000)
+	if got := maxTokens("claude-opus-4-1", &core.ChatParams{MaxTokens: &requested}, nil); got != 32000 {
+		t.Fatalf("expected clamp to 32000, got %d", got)
+	}
+	if got := maxTokens("claude-private-model", &core.ChatParams{MaxTokens: &requested}, nil); got != requested {
+		t.Fatalf("unknown models must not be clamped, got %d", got)
+	}
+	if got := maxTokens("claude-opus-4-1", &core.ChatParams{}, &thinkingConfig{Type: "enabled", BudgetTokens: 40000}); got != 32000 {
+		t.Fatalf("expected thinking raise clamped to 32000, got %d", got)
+	}
+}
@@ -0,0 +1,71 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatUploadsFilePartOnceAndSendsFilesBeta(t *testing.T) {
+	t.Parallel()
+
+	var uploads atomic.Int32
+	var request map[string]any
+	var messagesBeta string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/files":
+			uploads.Add(1)
+			if !strings.Contains(r.Header.Get("anthropic-beta"), filesBeta) {
+				t.Fatalf("upload is missing files beta header: %q", r.Header.Get("anthropic-beta"))
+			}
+			if _, _, err := r.FormFile("file"); err != nil {
+				t.Fatalf("read uploaded file: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"id":"file_011","type":"file"}`))
+		case "/messages":
+			messagesBeta = r.Header.Get("anthropic-beta")
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"done"}],"stop_reason":"end_turn"}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	params := &core.ChatParams{Messages: []core.MessageUnion{&core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+		core.FilePart{Data: []byte("png-bytes"), MimeType: "image/png"},
+		core.FilePart{Data: []byte("%PDF-1.7"), MimeType: "application/pdf"},
+		core.FilePart{Data: []byte("png-bytes"), MimeType: "image/png"},
+	}}}}
+
+	if _, err := adapter.Chat(context.Background(), params); err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	if uploads.Load() != 2 {
+		t.Fatalf("expected 2 uploads, got %d", uploads.Load())
+	}
+	if !strings.Contains(messagesBeta, filesBeta) {
+		t.Fatalf("messages request is missing files beta header: %q", messagesBeta)
+	}
+	content := request["messages"].([]any)[0].(map[string]any)["content"].([]any)
+	image := content[0].(map[string]any)
+	document := content[1].(map[string]any)
+	if image["type"] != "image" || image["source"].(map[string]any)["file_id"] != "file_011" || image["source"].(map[string]any)["type"] != "file" {
+		t.Fatalf("unexpected image block %#v", image)
+	}
+	if document["type"] != "document" || document["source"].(map[string]any)["type"] != "file" {
+		t.Fatalf("unexpected document block %#v", document)
+	}
+}
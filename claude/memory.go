@@ -0,0 +1,305 @@
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/m43i/go-ai/core"
+)
+
+const (
+	memoryToolType = "memory_20250818"
+	memoryToolName = "memory"
+	memoryRoot     = "/memories"
+)
+
+// MemoryStore persists the files of the Claude memory tool on the caller side.
+//
+// Paths are absolute, cleaned, and rooted at /memories. Read returns an error
+// wrapping fs.ErrNotExist for missing files.
+type MemoryStore interface {
+	Read(path string) (string, error)
+	Write(path, content string) error
+	Delete(path string) error
+	// List returns the paths of all files below dir.
+	List(dir string) ([]string, error)
+}
+
+// InMemoryStore is a MemoryStore that keeps files in process memory.
+type InMemoryStore struct {
+	mu    sync.RWMutex
+	files map[string]string
+}
+
+var _ MemoryStore = (*InMemoryStore)(nil)
+
+// NewInMemoryStore creates an empty in-memory MemoryStore.
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{files: make(map[string]string)}
+}
+
+func (s *InMemoryStore) Read(path string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	content, ok := s.files[path]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", path, fs.ErrNotExist)
+	}
+	return content, nil
+}
+
+func (s *InMemoryStore) Write(path, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.files == nil {
+		s.files = make(map[string]string)
+	}
+	s.files[path] = content
+	return nil
+}
+
+func (s *InMemoryStore) Delete(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.files[path]; !ok {
+		return fmt.Errorf("%s: %w", path, fs.ErrNotExist)
+	}
+	delete(s.files, path)
+	return nil
+}
+
+func (s *InMemoryStore) List(dir string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefix := strings.TrimRight(dir, "/") + "/"
+	var out []string
+	for name := range s.files {
+		if strings.HasPrefix(name, prefix) {
+			out = append(out, name)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+type memoryCommand struct {
+	Command    string `json:"command"`
+	Path       string `json:"path"`
+	ViewRange  []int  `json:"view_range"`
+	FileText   string `json:"file_text"`
+	OldStr     string `json:"old_str"`
+	NewStr     string `json:"new_str"`
+	InsertLine int    `json:"insert_line"`
+	InsertText string `json:"insert_text"`
+	OldPath    string `json:"old_path"`
+	NewPath    string `json:"new_path"`
+}
+
+// memoryServerTool wires the memory tool commands to store as a server tool
+// handler, so the agentic loop runs them like any other server tool.
+func memoryServerTool(store MemoryStore) core.ServerTool {
+	return core.ServerTool{
+		Name: memoryToolName,
+		Handler: func(input any) (string, error) {
+			encoded, err := json.Marshal(input)
+			if err != nil {
+				return "", err
+			}
+			var command memoryCommand
+			if err := json.Unmarshal(encoded, &command); err != nil {
+				return "", fmt.Errorf("invalid memory command: %w", err)
+			}
+			return runMemoryCommand(store, command)
+		},
+	}
+}
+
+func runMemoryCommand(store MemoryStore, command memoryCommand) (string, error) {
+	switch command.Command {
+	case "view":
+		target, err := memoryPath(command.Path)
+		if err != nil {
+			return "", err
+		}
+		return viewMemory(store, target, command.ViewRange)
+
+	case "create":
+		target, err := memoryFilePath(command.Path)
+		if err != nil {
+			return "", err
+		}
+		if err := store.Write(target, command.FileText); err != nil {
+			return "", err
+		}
+		return "File created successfully at " + target, nil
+
+	case "str_replace":
+		target, err := memoryFilePath(command.Path)
+		if err != nil {
+			return "", err
+		}
+		content, err := store.Read(target)
+		if err != nil {
+			return "", err
+		}
+		switch count := strings.Count(content, command.OldStr); {
+		case command.OldStr == "" || count == 0:
+			return "", fmt.Errorf("text to replace was not found in %s", target)
+		case count > 1:
+			return "", fmt.Errorf("text to replace appears %d times in %s; it must be unique", count, target)
+		}
+		if err := store.Write(target, strings.Replace(content, command.OldStr, command.NewStr, 1)); err != nil {
+			return "", err
+		}
+		return "File " + target + " has been edited", nil
+
+	case "insert":
+		target, err := memoryFilePath(command.Path)
+		if err != nil {
+			return "", err
+		}
+		content, err := store.Read(target)
+		if err != nil {
+			return "", err
+		}
+		lines := strings.Split(content, "\n")
+		if command.InsertLine < 0 || command.InsertLine > len(lines) {
+			return "", fmt.Errorf("insert line %d is out of range (0-%d)", command.InsertLine, len(lines))
+		}
+		inserted := strings.Split(strings.TrimSuffix(command.InsertText, "\n"), "\n")
+		lines = slices.Insert(lines, command.InsertLine, inserted...)
+		if err := store.Write(target, strings.Join(lines, "\n")); err != nil {
+			return "", err
+		}
+		return "Text inserted at line " + fmt.Sprint(command.InsertLine) + " in " + target, nil
+
+	case "delete":
+		target, err := memoryFilePath(command.Path)
+		if err != nil {
+			return "", err
+		}
+		if err := deleteMemory(store, target); err != nil {
+			return "", err
+		}
+		return "Deleted " + target, nil
+
+	case "rename":
+		from, err := memoryFilePath(command.OldPath)
+		if err != nil {
+			return "", err
+		}
+		to, err := memoryFilePath(command.NewPath)
+		if err != nil {
+			return "", err
+		}
+		content, err := store.Read(from)
+		if err != nil {
+			return "", err
+		}
+		if err := store.Write(to, content); err != nil {
+			return "", err
+		}
+		if err := store.Delete(from); err != nil {
+			return "", err
+		}
+		return "Renamed " + from + " to " + to, nil
+
+	default:
+		return "", fmt.Errorf("unsupported memory command %q", command.Command)
+	}
+}
+
+func viewMemory(store MemoryStore, target string, viewRange []int) (string, error) {
+	content, err := store.Read(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		files, listErr := store.List(target)
+		if listErr != nil {
+			return "", listErr
+		}
+		if len(files) == 0 && target != memoryRoot {
+			return "", err
+		}
+		var builder strings.Builder
+		builder.WriteString("Directory: " + target)
+		for _, file := range files {
+			builder.WriteString("\n- " + file)
+		}
+		return builder.String(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(content, "\n")
+	start, end := 1, len(lines)
+	if len(viewRange) == 2 {
+		start = max(viewRange[0], 1)
+		if viewRange[1] > 0 {
+			end = min(viewRange[1], len(lines))
+		}
+	}
+
+	var builder strings.Builder
+	for number := start; number <= end; number++ {
+		if builder.Len() > 0 {
+			builder.WriteByte('\n')
+		}
+		fmt.Fprintf(&builder, "%6d\t%s", number, lines[number-1])
+	}
+	return builder.String(), nil
+}
+
+func deleteMemory(store MemoryStore, target string) error {
+	err := store.Delete(target)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	files, listErr := store.List(target)
+	if listErr != nil {
+		return listErr
+	}
+	if len(files) == 0 {
+		return err
+	}
+	for _, file := range files {
+		if err := store.Delete(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// memoryPath cleans a memory tool path and rejects paths outside /memories.
+func memoryPath(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, "/") {
+		return "", fmt.Errorf("memory path %q must be absolute", raw)
+	}
+	cleaned := path.Clean(raw)
+	if cleaned != memoryRoot && !strings.HasPrefix(cleaned, memoryRoot+"/") {
+		return "", fmt.Errorf("memory path %q is outside %s", raw, memoryRoot)
+	}
+	return cleaned, nil
+}
+
+func memoryFilePath(raw string) (string, error) {
+	cleaned, err := memoryPath(raw)
+	if err != nil {
+		return "", err
+	}
+	if cleaned == memoryRoot {
+		return "", fmt.Errorf("memory path %q must name a file", raw)
+	}
+	return cleaned, nil
+}
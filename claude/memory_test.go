@@ -0,0 +1,134 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestMemoryCommands(t *testing.T) {
+	t.Parallel()
+
+	store := NewInMemoryStore()
+	run := func(command memoryCommand) string {
+		t.Helper()
+		out, err := runMemoryCommand(store, command)
+		if err != nil {
+			t.Fatalf("%s returned error: %v", command.Command, err)
+		}
+		return out
+	}
+
+	run(memoryCommand{Command: "create", Path: "/memories/notes.md", FileText: "alpha\ngamma"})
+	run(memoryCommand{Command: "insert", Path: "/memories/notes.md", InsertLine: 1, InsertText: "beta\n"})
+	run(memoryCommand{Command: "str_replace", Path: "/memories/notes.md", OldStr: "gamma", NewStr: "delta"})
+
+	if got := run(memoryCommand{Command: "view", Path: "/memories/notes.md"}); got != "     1\talpha\n     2\tbeta\n     3\tdelta" {
+		t.Fatalf("unexpected file view %q", got)
+	}
+	if got := run(memoryCommand{Command: "view", Path: "/memories/notes.md", ViewRange: []int{2, 2}}); got != "     2\tbeta" {
+		t.Fatalf("unexpected ranged view %q", got)
+	}
+
+	run(memoryCommand{Command: "rename", OldPath: "/memories/notes.md", NewPath: "/memories/project/notes.md"})
+	if got := run(memoryCommand{Command: "view", Path: "/memories"}); got != "Directory: /memories\n- /memories/project/notes.md" {
+		t.Fatalf("unexpected directory view %q", got)
+	}
+
+	run(memoryCommand{Command: "delete", Path: "/memories/project"})
+	if _, err := store.Read("/memories/project/notes.md"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected file to be deleted, got %v", err)
+	}
+}
+
+func TestMemoryCommandErrors(t *testing.T) {
+	t.Parallel()
+
+	store := NewInMemoryStore()
+	_ = store.Write("/memories/a.md", "x x")
+
+	tests := []memoryCommand{
+		{Command: "view", Path: "/memories/../etc/passwd"},
+		{Command: "create", Path: "relative.md", FileText: "x"},
+		{Command: "create", Path: "/memories", FileText: "x"},
+		{Command: "str_replace", Path: "/memories/a.md", OldStr: "x", NewStr: "y"},
+		{Command: "str_replace", Path: "/memories/a.md", OldStr: "z", NewStr: "y"},
+		{Command: "view", Path: "/memories/missing.md"},
+		{Command: "format", Path: "/memories/a.md"},
+	}
+	for _, command := range tests {
+		if _, err := runMemoryCommand(store, command); err == nil {
+			t.Fatalf("expected error for %#v", command)
+		}
+	}
+}
+
+func TestChatRunsMemoryToolAgainstStore(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	var firstRequest map[string]any
+	var beta string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			beta = r.Header.Get("anthropic-beta")
+			if err := json.NewDecoder(r.Body).Decode(&firstRequest); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"memory","input":{"command":"create","path":"/memories/user.md","file_text":"likes tea"}}],"stop_reason":"tool_use"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"msg_2","role":"assistant","content":[{"type":"text","text":"Noted."}],"stop_reason":"end_turn"}`))
+	}))
+	defer server.Close()
+
+	store := NewInMemoryStore()
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL), WithMemory(store))
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "I like tea"}},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if result.Text != "Noted." {
+		t.Fatalf("unexpected result text %q", result.Text)
+	}
+	if beta != contextManagementBeta {
+		t.Fatalf("expected context management beta header, got %q", beta)
+	}
+
+	tools, ok := firstRequest["tools"].([]any)
+	if !ok || len(tools) != 1 {
+		t.Fatalf("expected memory tool definition, got %#v", firstRequest["tools"])
+	}
+	definition := tools[0].(map[string]any)
+	if definition["type"] != memoryToolType || definition["name"] != memoryToolName {
+		t.Fatalf("unexpected memory tool definition %#v", definition)
+	}
+
+	content, err := store.Read("/memories/user.md")
+	if err != nil || content != "likes tea" {
+		t.Fatalf("expected memory file to be written, got %q (%v)", content, err)
+	}
+
+	var toolResult string
+	for _, message := range result.Messages {
+		if part, ok := message.(core.ToolResultMessagePart); ok {
+			toolResult = part.Content
+		}
+	}
+	if !strings.HasPrefix(toolResult, "File created successfully") {
+		t.Fatalf("unexpected tool result %q", toolResult)
+	}
+}
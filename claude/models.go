@@ -0,0 +1,59 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+const modelsPageLimit = 1000
+
+var _ core.ModelAdapter = (*Adapter)(nil)
+
+// ListModels returns the models available to the API key, newest first.
+func (a *Adapter) ListModels(ctx context.Context) ([]core.ModelInfo, error) {
+	if a == nil {
+		return nil, errors.New("claude: adapter is nil")
+	}
+	if err := a.validateAPIKey(); err != nil {
+		return nil, err
+	}
+
+	var models []core.ModelInfo
+	afterID := ""
+	for {
+		page, err := a.listModelsPage(ctx, afterID)
+		if err != nil {
+			return nil, err
+		}
+		for _, model := range page.Data {
+			models = append(models, core.ModelInfo{
+				ID:          model.ID,
+				DisplayName: model.DisplayName,
+				CreatedAt:   model.CreatedAt,
+			})
+		}
+		if !page.HasMore || page.LastID == "" {
+			return models, nil
+		}
+		afterID = page.LastID
+	}
+}
+
+func (a *Adapter) listModelsPage(ctx context.Context, afterID string) (*modelListResponse, error) {
+	query := url.Values{}
+	query.Set("limit", fmt.Sprint(modelsPageLimit))
+	if afterID != "" {
+		query.Set("after_id", afterID)
+	}
+
+	var response modelListResponse
+	if _, err := a.transport().Send(ctx, httpclient.Request{Path: "/models?" + query.Encode(), Header: a.betas()}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
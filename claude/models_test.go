@@ -0,0 +1,48 @@
+package claude
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListModelsFollowsPages(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/models" {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Fatalf("expected api key header, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("after_id") {
+		case "":
+			_, _ = w.Write([]byte(`{"data":[{"type":"model","id":"claude-b","display_name":"Claude B","created_at":"2025-02-01T00:00:00Z"}],"has_more":true,"first_id":"claude-b","last_id":"claude-b"}`))
+		case "claude-b":
+			_, _ = w.Write([]byte(`{"data":[{"type":"model","id":"claude-a","display_name":"Claude A","created_at":"2025-01-01T00:00:00Z"}],"has_more":false,"first_id":"claude-a","last_id":"claude-a"}`))
+		default:
+			t.Fatalf("unexpected after_id %q", r.URL.Query().Get("after_id"))
+		}
+	}))
+	defer server.Close()
+
+	// ListModels does not need a model.
+	adapter := New("", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	models, err := adapter.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("list models returned error: %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("expected 2 models, got %#v", models)
+	}
+	if models[0].ID != "claude-b" || models[0].DisplayName != "Claude B" || !models[0].CreatedAt.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected first model %#v", models[0])
+	}
+	if models[1].ID != "claude-a" {
+		t.Fatalf("unexpected second model %#v", models[1])
+	}
+}
@@ -0,0 +1,59 @@
+package claude
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func init() {
+	core.RegisterProvider(providerName, newFromConfig)
+}
+
+// newFromConfig builds an adapter for core.NewAdapter. It supports the
+// options timeout, version, beta (comma-separated features), output_mode,
+// interleaved_thinking, gzip, and prompt_size_check.
+func newFromConfig(config core.ProviderConfig) (core.TextAdapter, error) {
+	opts := []Option{WithAPIKey(config.APIKey), WithBaseURL(config.BaseURL)}
+	for name, value := range config.Options {
+		switch name {
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("option timeout: %w", err)
+			}
+			opts = append(opts, WithTimeout(timeout))
+		case "version":
+			opts = append(opts, WithAnthropicVersion(value))
+		case "beta":
+			opts = append(opts, WithBetaFeatures(strings.Split(value, ",")...))
+		case "output_mode":
+			if value != OutputModeNative && value != OutputModeTool {
+				return nil, fmt.Errorf("option output_mode: unknown mode %q", value)
+			}
+			opts = append(opts, WithOutputMode(value))
+		case "interleaved_thinking", "gzip", "prompt_size_check":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			if !enabled {
+				continue
+			}
+			switch name {
+			case "interleaved_thinking":
+				opts = append(opts, WithInterleavedThinking())
+			case "gzip":
+				opts = append(opts, WithGzip())
+			default:
+				opts = append(opts, WithPromptSizeCheck())
+			}
+		default:
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	return New(config.Model, opts...), nil
+}
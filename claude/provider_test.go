@@ -0,0 +1,32 @@
+package claude
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestFromDSN(t *testing.T) {
+	t.Parallel()
+
+	adapter, err := core.FromDSN("claude://sk-test@claude-3-5-haiku-latest?version=2024-01-01&beta=a,b&timeout=30s&gzip=true&interleaved_thinking=false")
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	claude := adapter.(*Adapter)
+	if claude.Model != "claude-3-5-haiku-latest" || claude.APIKey != "sk-test" || claude.AnthropicVersion != "2024-01-01" || claude.BaseURL != defaultBaseURL {
+		t.Fatalf("adapter = %+v", claude)
+	}
+	if !slices.Equal(claude.BetaFeatures, []string{"a", "b"}) || claude.HTTPClient.Timeout != 30*time.Second || !claude.Gzip || claude.InterleavedThinking {
+		t.Fatalf("adapter = %+v", claude)
+	}
+
+	if _, err := core.FromDSN("claude://claude-3-5-haiku-latest?temperature=1"); err == nil {
+		t.Fatal("FromDSN() expected error for an unknown option")
+	}
+	if _, err := core.FromDSN("claude://claude-3-5-haiku-latest?gzip=maybe"); err == nil {
+		t.Fatal("FromDSN() expected error for an invalid boolean")
+	}
+}
@@ -0,0 +1,91 @@
+package claude
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+const (
+	defaultRetryBaseDelay       = 500 * time.Millisecond
+	defaultRetryOverloadedDelay = 5 * time.Second
+	defaultRetryMaxDelay        = time.Minute
+	statusOverloaded            = 529
+)
+
+// RetryPolicy controls how the adapter retries requests that fail with a
+// retryable API error: rate limits, overloaded responses, and other 5xx
+// errors. The zero value disables retries.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// BaseDelay is the initial backoff for server errors. It doubles with
+	// each attempt.
+	BaseDelay time.Duration
+	// OverloadedDelay is the initial backoff for overloaded (529) responses,
+	// which take longer to recover than other server errors.
+	OverloadedDelay time.Duration
+	// MaxDelay caps the backoff between attempts.
+	MaxDelay time.Duration
+}
+
+// retry reports whether a request that failed with err on attempt is retried
+// under the policy, and the backoff before it is.
+func (p RetryPolicy) retry(attempt int, err error) (time.Duration, bool) {
+	if attempt >= p.MaxRetries || !isRetryable(err) {
+		return 0, false
+	}
+	return p.delay(attempt, err), true
+}
+
+func isRetryable(err error) bool {
+	var rateLimitErr *core.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return true
+	}
+	var apiErr *core.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable
+	}
+	return false
+}
+
+func isOverloaded(err error) bool {
+	var apiErr *core.APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == statusOverloaded || apiErr.Type == "overloaded_error")
+}
+
+// delay returns the backoff before the retry following attempt. A retry-after
+// header reported by the API takes precedence.
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	var rateLimitErr *core.RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > 0 {
+		return rateLimitErr.RetryAfter
+	}
+	var apiErr *core.APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+
+	base := p.BaseDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	if isOverloaded(err) {
+		base = p.OverloadedDelay
+		if base <= 0 {
+			base = defaultRetryOverloadedDelay
+		}
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	delay := base << min(attempt, 16)
+	if delay <= 0 || delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
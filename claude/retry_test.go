@@ -0,0 +1,97 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatRetriesOverloadedResponses(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(statusOverloaded)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test",
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 2, OverloadedDelay: time.Millisecond}),
+	)
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if result.Text != "ok" || attempts.Load() != 3 {
+		t.Fatalf("expected success on third attempt, got %q after %d attempts", result.Text, attempts.Load())
+	}
+}
+
+func TestChatDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"bad input"}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test",
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}),
+	)
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	})
+	var apiErr *core.APIError
+	if !errors.As(err, &apiErr) || apiErr.Retryable {
+		t.Fatalf("expected non-retryable API error, got %v", err)
+	}
+	if attempts.Load() != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts.Load())
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{}
+	overloaded := &core.APIError{StatusCode: statusOverloaded, Type: "overloaded_error", Retryable: true}
+	unavailable := &core.APIError{StatusCode: http.StatusServiceUnavailable, Retryable: true}
+
+	if got := policy.delay(0, unavailable); got != defaultRetryBaseDelay {
+		t.Fatalf("unexpected server error delay: %v", got)
+	}
+	if got := policy.delay(2, unavailable); got != 4*defaultRetryBaseDelay {
+		t.Fatalf("expected exponential backoff, got %v", got)
+	}
+	if got := policy.delay(0, overloaded); got != defaultRetryOverloadedDelay {
+		t.Fatalf("expected longer overloaded delay, got %v", got)
+	}
+	if got := policy.delay(10, overloaded); got != defaultRetryMaxDelay {
+		t.Fatalf("expected delay to be capped, got %v", got)
+	}
+	if got := policy.delay(0, &core.RateLimitError{RetryAfter: 7 * time.Second}); got != 7*time.Second {
+		t.Fatalf("expected retry-after to take precedence, got %v", got)
+	}
+}
@@ -64,3 +64,129 @@ func TestChatStreamReasoningUsesIncrementalDelta(t *testing.T) {
 		t.Fatalf("unexpected final reasoning: %q", doneReasoning)
 	}
 }
+
+func TestChatStreamReportsStopReason(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprintln(w, "data: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"1, 2, 3\"}}")
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"stop_sequence\",\"stop_sequence\":\"4\"},\"usage\":{\"output_tokens\":5}}")
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "data: {\"type\":\"message_stop\"}")
+		_, _ = fmt.Fprintln(w)
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	stream, err := adapter.ChatStream(context.Background(), &core.ChatParams{Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Count"}}})
+	if err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	var done core.StreamChunk
+	for chunk := range stream {
+		if chunk.Type == core.StreamChunkDone {
+			done = chunk
+		}
+	}
+	if done.FinishReason != core.FinishReasonStopSequence || done.RawFinishReason != "stop_sequence" {
+		t.Fatalf("unexpected finish reason: %q (raw %q)", done.FinishReason, done.RawFinishReason)
+	}
+}
+
+func TestChatStreamMergesMessageStartUsage(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprintln(w, "data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"role\":\"assistant\",\"content\":[],\"usage\":{\"input_tokens\":12,\"output_tokens\":1,\"cache_read_input_tokens\":8,\"service_tier\":\"standard\"}}}")
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "data: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\"}}")
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":7}}")
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "data: {\"type\":\"message_stop\"}")
+		_, _ = fmt.Fprintln(w)
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	stream, err := adapter.ChatStream(context.Background(), &core.ChatParams{Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	var usage *core.Usage
+	for chunk := range stream {
+		if chunk.Type == core.StreamChunkDone {
+			usage = chunk.Usage
+		}
+	}
+	if usage == nil || usage.PromptTokens != 12 || usage.CompletionTokens != 7 || usage.TotalTokens != 19 {
+		t.Fatalf("unexpected usage: %#v", usage)
+	}
+	if usage.Details["cache_read_input_tokens"] != 8 || usage.ServiceTier != "standard" {
+		t.Fatalf("expected message_start usage details to be kept: %#v", usage)
+	}
+}
+
+func TestChatStreamInterleavesReasoningWithToolCalls(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("anthropic-beta"); got != interleavedThinkingBeta {
+			t.Fatalf("expected interleaved thinking beta header, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		calls++
+		if calls == 1 {
+			_, _ = w.Write([]byte(`{"id":"msg_1","role":"assistant","content":[{"type":"thinking","thinking":"check weather","signature":"sig1"},{"type":"tool_use","id":"toolu_1","name":"weather","input":{}}],"stop_reason":"tool_use"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"msg_2","role":"assistant","content":[{"type":"thinking","thinking":"it is sunny","signature":"sig2"},{"type":"text","text":"Sunny."}],"stop_reason":"end_turn"}`))
+	}))
+	defer server.Close()
+
+	adapter := New("claude-test", WithAPIKey("test-key"), WithBaseURL(server.URL), WithInterleavedThinking())
+	stream, err := adapter.ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather?"}},
+		Tools: []core.ToolUnion{core.ServerTool{
+			Name:    "weather",
+			Handler: func(any) (string, error) { return "sunny", nil },
+		}},
+		Thinking: "enabled",
+	})
+	if err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	var order []string
+	var reasoning []string
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkError:
+			t.Fatalf("unexpected chunk error: %s", chunk.Error)
+		case core.StreamChunkReasoning:
+			reasoning = append(reasoning, chunk.Reasoning)
+		}
+		order = append(order, chunk.Type)
+	}
+
+	expected := []string{
+		core.StreamChunkReasoning,
+		core.StreamChunkToolCall,
+		core.StreamChunkToolResult,
+		core.StreamChunkReasoning,
+		core.StreamChunkContent,
+		core.StreamChunkDone,
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("unexpected chunk order: %#v", order)
+	}
+	if !reflect.DeepEqual(reasoning, []string{"check weather", "check weather\nit is sunny"}) {
+		t.Fatalf("unexpected reasoning snapshots: %#v", reasoning)
+	}
+}
@@ -0,0 +1,84 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+var _ core.TokenCounterAdapter = (*Adapter)(nil)
+
+type countTokensRequest struct {
+	Model      string          `json:"model"`
+	System     any             `json:"system,omitempty"`
+	Messages   []message       `json:"messages"`
+	Thinking   *thinkingConfig `json:"thinking,omitempty"`
+	Tools      []tool          `json:"tools,omitempty"`
+	ToolChoice *toolChoice     `json:"tool_choice,omitempty"`
+	MCPServers []mcpServer     `json:"mcp_servers,omitempty"`
+}
+
+type countTokensResponse struct {
+	InputTokens int64 `json:"input_tokens"`
+}
+
+// CountTokens returns the number of input tokens params would use, as counted
+// by the Messages API token counting endpoint.
+func (a *Adapter) CountTokens(ctx context.Context, params *core.ChatParams) (int64, error) {
+	if err := a.validate(); err != nil {
+		return 0, err
+	}
+	params, err := a.resolveFileParts(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+
+	request, messages, _, _, _, err := a.buildRequestTemplate(params)
+	if err != nil {
+		return 0, err
+	}
+	return a.countTokens(ctx, &request, messages)
+}
+
+func (a *Adapter) countTokens(ctx context.Context, request *messageRequest, messages []message) (int64, error) {
+	body, err := json.Marshal(countTokensRequest{
+		Model:      request.Model,
+		System:     request.System,
+		Messages:   messages,
+		Thinking:   request.Thinking,
+		Tools:      request.Tools,
+		ToolChoice: request.ToolChoice,
+		MCPServers: request.MCPServers,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claude: marshal token count request: %w", err)
+	}
+
+	var response countTokensResponse
+	apiRequest := httpclient.Request{Path: "/messages/count_tokens", Name: "token count", Body: body, Header: a.betas(request.Betas...)}
+	if _, err := a.transport().Send(ctx, apiRequest, &response); err != nil {
+		return 0, err
+	}
+	return response.InputTokens, nil
+}
+
+// checkPromptSize counts the prompt tokens of request when CheckPromptSize is
+// set and the model catalog knows the model's context window, and fails when
+// the prompt plus max_tokens cannot fit.
+func (a *Adapter) checkPromptSize(ctx context.Context, request *messageRequest, messages []message) error {
+	if !a.CheckPromptSize {
+		return nil
+	}
+	if spec, ok := core.LookupModel(a.Model); !ok || spec.ContextWindow <= 0 {
+		return nil
+	}
+
+	tokens, err := a.countTokens(ctx, request, messages)
+	if err != nil {
+		return err
+	}
+	return core.CheckContextWindow(a.Model, tokens, request.MaxTokens)
+}
@@ -0,0 +1,91 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestPromptSizeCheckFailsBeforeSending(t *testing.T) {
+	t.Parallel()
+
+	var countRequest map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages/count_tokens" {
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&countRequest); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"input_tokens":190000}`))
+	}))
+	defer server.Close()
+
+	maxTokens := int64(20000)
+	params := &core.ChatParams{
+		SystemPrompts: []string{"Be brief."},
+		Messages:      []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "long document"}},
+		MaxTokens:     &maxTokens,
+	}
+
+	adapter := New("claude-sonnet-4-5", WithAPIKey("test-key"), WithBaseURL(server.URL), WithPromptSizeCheck())
+	_, err := adapter.Chat(context.Background(), params)
+
+	var exceeded *core.ContextLengthExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("expected ContextLengthExceededError, got %v", err)
+	}
+	if exceeded.PromptTokens != 190000 || exceeded.OutputTokens != 20000 || exceeded.ContextWindow != 200000 {
+		t.Fatalf("unexpected error fields %#v", exceeded)
+	}
+	if countRequest["system"] != "Be brief." || countRequest["max_tokens"] != nil {
+		t.Fatalf("unexpected token count request %#v", countRequest)
+	}
+
+	tokens, err := core.CountTokens(context.Background(), adapter, params)
+	if err != nil || tokens != 190000 {
+		t.Fatalf("unexpected token count %d, %v", tokens, err)
+	}
+}
+
+func TestPromptSizeCheckAllowsLargePromptWithDefaultMaxTokens(t *testing.T) {
+	t.Parallel()
+
+	var chatRequest map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/messages/count_tokens":
+			_, _ = w.Write([]byte(`{"input_tokens":150000}`))
+		case "/messages":
+			if beta := r.Header.Get("anthropic-beta"); strings.Contains(beta, longOutputBeta) {
+				t.Errorf("unexpected beta header %q", beta)
+			}
+			if err := json.NewDecoder(r.Body).Decode(&chatRequest); err != nil {
+				t.Errorf("decode request: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":150000,"output_tokens":1}}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	adapter := New("claude-3-7-sonnet-20250219", WithAPIKey("test-key"), WithBaseURL(server.URL), WithPromptSizeCheck())
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "long document"}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Text != "ok" || chatRequest["max_tokens"] != float64(defaultMaxTokens) {
+		t.Fatalf("unexpected result %q for request %#v", result.Text, chatRequest)
+	}
+}
@@ -1,18 +1,42 @@
 package claude
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
 type messageRequest struct {
-	Model        string         `json:"model"`
-	System       string         `json:"system,omitempty"`
-	Messages     []message      `json:"messages"`
-	MaxTokens    int64          `json:"max_tokens"`
-	Temperature  *float64       `json:"temperature,omitempty"`
-	TopP         *float64       `json:"top_p,omitempty"`
-	Metadata     map[string]any `json:"metadata,omitempty"`
-	OutputConfig any            `json:"output_config,omitempty"`
-	Tools        []tool         `json:"tools,omitempty"`
-	ToolChoice   *toolChoice    `json:"tool_choice,omitempty"`
-	Stream       bool           `json:"stream,omitempty"`
-	ModelOptions map[string]any `json:"-"`
+	Model         string          `json:"model"`
+	System        any             `json:"system,omitempty"`
+	Messages      []message       `json:"messages"`
+	MaxTokens     int64           `json:"max_tokens"`
+	Temperature   *float64        `json:"temperature,omitempty"`
+	TopP          *float64        `json:"top_p,omitempty"`
+	TopK          *int64          `json:"top_k,omitempty"`
+	StopSequences []string        `json:"stop_sequences,omitempty"`
+	Metadata      map[string]any  `json:"metadata,omitempty"`
+	OutputConfig  any             `json:"output_config,omitempty"`
+	Thinking      *thinkingConfig `json:"thinking,omitempty"`
+	Tools         []tool          `json:"tools,omitempty"`
+	ToolChoice    *toolChoice     `json:"tool_choice,omitempty"`
+	MCPServers    []mcpServer     `json:"mcp_servers,omitempty"`
+	Container     string          `json:"container,omitempty"`
+	Stream        bool            `json:"stream,omitempty"`
+	ModelOptions  map[string]any  `json:"-"`
+
+	// Betas lists anthropic-beta features this request needs in addition to
+	// the adapter-wide BetaFeatures.
+	Betas []string `json:"-"`
+
+	// OutputTool names the synthetic structured output tool, if any.
+	OutputTool string `json:"-"`
+}
+
+type thinkingConfig struct {
+	Type         string `json:"type"`
+	BudgetTokens int64  `json:"budget_tokens,omitempty"`
 }
 
 type message struct {
@@ -21,16 +45,64 @@ type message struct {
 }
 
 type contentBlock struct {
-	Type      string       `json:"type"`
-	Text      string       `json:"text,omitempty"`
-	Thinking  string       `json:"thinking,omitempty"`
-	Signature string       `json:"signature,omitempty"`
-	Source    *mediaSource `json:"source,omitempty"`
-	ID        string       `json:"id,omitempty"`
-	Name      string       `json:"name,omitempty"`
-	Input     any          `json:"input,omitempty"`
-	ToolUseID string       `json:"tool_use_id,omitempty"`
-	Content   any          `json:"content,omitempty"`
+	Type       string       `json:"type"`
+	Text       string       `json:"text,omitempty"`
+	Thinking   string       `json:"thinking,omitempty"`
+	Signature  string       `json:"signature,omitempty"`
+	Data       string       `json:"data,omitempty"`
+	Source     *mediaSource `json:"source,omitempty"`
+	ID         string       `json:"id,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	Input      any          `json:"input,omitempty"`
+	ToolUseID  string       `json:"tool_use_id,omitempty"`
+	Content    any          `json:"content,omitempty"`
+	Citations  *citations   `json:"citations,omitempty"`
+	Title      string       `json:"title,omitempty"`
+	Context    string       `json:"context,omitempty"`
+	ServerName string       `json:"server_name,omitempty"`
+	IsError    bool         `json:"is_error,omitempty"`
+
+	CacheControl *cacheControl `json:"cache_control,omitempty"`
+
+	// SearchSource is the source of a search_result block, which the API
+	// encodes as a string "source" field.
+	SearchSource string `json:"-"`
+
+	// Raw replaces the whole block when set, for core.RawPart. Cache control
+	// is not merged into raw blocks.
+	Raw json.RawMessage `json:"-"`
+}
+
+// citations holds a text block's citation list in responses and a document
+// block's citation settings in requests, which share the "citations" field.
+type citations struct {
+	Enabled *bool
+	Items   []citation
+}
+
+type citation struct {
+	Type           string `json:"type"`
+	URL            string `json:"url,omitempty"`
+	Title          string `json:"title,omitempty"`
+	EncryptedIndex string `json:"encrypted_index,omitempty"`
+	CitedText      string `json:"cited_text,omitempty"`
+
+	DocumentIndex   int    `json:"document_index,omitempty"`
+	DocumentTitle   string `json:"document_title,omitempty"`
+	StartCharIndex  int    `json:"start_char_index,omitempty"`
+	EndCharIndex    int    `json:"end_char_index,omitempty"`
+	StartPageNumber int    `json:"start_page_number,omitempty"`
+	EndPageNumber   int    `json:"end_page_number,omitempty"`
+	StartBlockIndex int    `json:"start_block_index,omitempty"`
+	EndBlockIndex   int    `json:"end_block_index,omitempty"`
+
+	Source            string `json:"source,omitempty"`
+	SearchResultIndex int    `json:"search_result_index,omitempty"`
+}
+
+type cacheControl struct {
+	Type string `json:"type"`
+	TTL  string `json:"ttl,omitempty"`
 }
 
 type mediaSource struct {
@@ -38,37 +110,101 @@ type mediaSource struct {
 	MediaType string `json:"media_type,omitempty"`
 	Data      string `json:"data,omitempty"`
 	URL       string `json:"url,omitempty"`
+	FileID    string `json:"file_id,omitempty"`
 }
 
 type tool struct {
+	Type        string         `json:"type,omitempty"`
 	Name        string         `json:"name"`
 	Description string         `json:"description,omitempty"`
 	InputSchema map[string]any `json:"input_schema,omitempty"`
+
+	CacheControl *cacheControl `json:"cache_control,omitempty"`
+
+	// Options holds extra fields of a server tool definition, such as
+	// max_uses for web search. They are merged into the encoded tool.
+	Options map[string]any `json:"-"`
+}
+
+type contextEdit struct {
+	Type            string            `json:"type"`
+	Trigger         *contextEditValue `json:"trigger,omitempty"`
+	Keep            *contextEditValue `json:"keep,omitempty"`
+	ClearAtLeast    *contextEditValue `json:"clear_at_least,omitempty"`
+	ExcludeTools    []string          `json:"exclude_tools,omitempty"`
+	ClearToolInputs bool              `json:"clear_tool_inputs,omitempty"`
+}
+
+type contextEditValue struct {
+	Type  string `json:"type"`
+	Value int64  `json:"value"`
+}
+
+type mcpServer struct {
+	Type               string                `json:"type"`
+	URL                string                `json:"url"`
+	Name               string                `json:"name"`
+	AuthorizationToken string                `json:"authorization_token,omitempty"`
+	ToolConfiguration  *mcpToolConfiguration `json:"tool_configuration,omitempty"`
+}
+
+type mcpToolConfiguration struct {
+	Enabled      bool     `json:"enabled"`
+	AllowedTools []string `json:"allowed_tools,omitempty"`
 }
 
 type toolChoice struct {
-	Type string `json:"type"`
+	Type                   string `json:"type"`
+	Name                   string `json:"name,omitempty"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
 }
 
 type messageResponse struct {
-	ID         string         `json:"id"`
-	Role       string         `json:"role"`
-	Content    []contentBlock `json:"content"`
-	StopReason string         `json:"stop_reason"`
-	Usage      *usage         `json:"usage,omitempty"`
+	ID           string         `json:"id"`
+	Role         string         `json:"role"`
+	Content      []contentBlock `json:"content"`
+	StopReason   string         `json:"stop_reason"`
+	StopSequence string         `json:"stop_sequence,omitempty"`
+	Usage        *usage         `json:"usage,omitempty"`
+
+	ContextManagement *contextManagementResponse `json:"context_management,omitempty"`
+	Container         *container                 `json:"container,omitempty"`
+
+	RequestID string          `json:"-"`
+	RateLimit *core.RateLimit `json:"-"`
+	Duration  time.Duration   `json:"-"`
+}
+
+type container struct {
+	ID        string `json:"id"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+type contextManagementResponse struct {
+	AppliedEdits []appliedContextEdit `json:"applied_edits"`
+}
+
+type appliedContextEdit struct {
+	Type               string `json:"type"`
+	ClearedToolUses    int64  `json:"cleared_tool_uses,omitempty"`
+	ClearedInputTokens int64  `json:"cleared_input_tokens,omitempty"`
 }
 
 type streamEvent struct {
-	Type  string       `json:"type"`
-	Delta *streamDelta `json:"delta,omitempty"`
-	Error *streamError `json:"error,omitempty"`
-	Usage *usage       `json:"usage,omitempty"`
+	Type    string           `json:"type"`
+	Message *messageResponse `json:"message,omitempty"`
+	Delta   *streamDelta     `json:"delta,omitempty"`
+	Error   *streamError     `json:"error,omitempty"`
+	Usage   *usage           `json:"usage,omitempty"`
 }
 
 type streamDelta struct {
 	Type     string `json:"type"`
 	Text     string `json:"text"`
 	Thinking string `json:"thinking,omitempty"`
+
+	StopReason   string `json:"stop_reason,omitempty"`
+	StopSequence string `json:"stop_sequence,omitempty"`
 }
 
 type streamError struct {
@@ -81,4 +217,76 @@ type usage struct {
 	OutputTokens             int64 `json:"output_tokens"`
 	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens,omitempty"`
 	CacheReadInputTokens     int64 `json:"cache_read_input_tokens,omitempty"`
+
+	ServerToolUse *serverToolUsage `json:"server_tool_use,omitempty"`
+	CacheCreation *cacheCreation   `json:"cache_creation,omitempty"`
+	ServiceTier   string           `json:"service_tier,omitempty"`
+}
+
+type cacheCreation struct {
+	Ephemeral5mInputTokens int64 `json:"ephemeral_5m_input_tokens,omitempty"`
+	Ephemeral1hInputTokens int64 `json:"ephemeral_1h_input_tokens,omitempty"`
+}
+
+type serverToolUsage struct {
+	WebSearchRequests int64 `json:"web_search_requests,omitempty"`
+}
+
+type modelListResponse struct {
+	Data    []modelInfo `json:"data"`
+	HasMore bool        `json:"has_more"`
+	FirstID string      `json:"first_id"`
+	LastID  string      `json:"last_id"`
+}
+
+type modelInfo struct {
+	Type        string    `json:"type"`
+	ID          string    `json:"id"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type usageReportResponse struct {
+	Data []struct {
+		StartingAt time.Time           `json:"starting_at"`
+		EndingAt   time.Time           `json:"ending_at"`
+		Results    []usageReportResult `json:"results"`
+	} `json:"data"`
+	HasMore  bool   `json:"has_more"`
+	NextPage string `json:"next_page"`
+}
+
+type usageReportResult struct {
+	UncachedInputTokens  int64            `json:"uncached_input_tokens"`
+	CacheCreation        *cacheCreation   `json:"cache_creation"`
+	CacheReadInputTokens int64            `json:"cache_read_input_tokens"`
+	OutputTokens         int64            `json:"output_tokens"`
+	ServerToolUse        *serverToolUsage `json:"server_tool_use"`
+	APIKeyID             string           `json:"api_key_id"`
+	WorkspaceID          string           `json:"workspace_id"`
+	Model                string           `json:"model"`
+	ServiceTier          string           `json:"service_tier"`
+	ContextWindow        string           `json:"context_window"`
+}
+
+type costReportResponse struct {
+	Data []struct {
+		StartingAt time.Time          `json:"starting_at"`
+		EndingAt   time.Time          `json:"ending_at"`
+		Results    []costReportResult `json:"results"`
+	} `json:"data"`
+	HasMore  bool   `json:"has_more"`
+	NextPage string `json:"next_page"`
+}
+
+type costReportResult struct {
+	Currency      string `json:"currency"`
+	Amount        string `json:"amount"`
+	WorkspaceID   string `json:"workspace_id"`
+	Description   string `json:"description"`
+	CostType      string `json:"cost_type"`
+	Model         string `json:"model"`
+	ServiceTier   string `json:"service_tier"`
+	TokenType     string `json:"token_type"`
+	ContextWindow string `json:"context_window"`
 }
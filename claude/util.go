@@ -1,12 +1,18 @@
 package claude
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
 )
 
 func marshalMessageRequest(request *messageRequest) ([]byte, error) {
@@ -32,6 +38,69 @@ func marshalMessageRequest(request *messageRequest) ([]byte, error) {
 	return json.Marshal(envelope)
 }
 
+func (b contentBlock) MarshalJSON() ([]byte, error) {
+	if len(b.Raw) > 0 {
+		return b.Raw, nil
+	}
+	type plain contentBlock
+	if b.Type != "search_result" {
+		return json.Marshal(plain(b))
+	}
+	return json.Marshal(struct {
+		plain
+		Source string `json:"source"`
+	}{plain: plain(b), Source: b.SearchSource})
+}
+
+func (c citations) MarshalJSON() ([]byte, error) {
+	if c.Enabled != nil {
+		return json.Marshal(map[string]bool{"enabled": *c.Enabled})
+	}
+	return json.Marshal(c.Items)
+}
+
+func (c *citations) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var config struct {
+			Enabled bool `json:"enabled"`
+		}
+		if err := json.Unmarshal(trimmed, &config); err != nil {
+			return err
+		}
+		c.Enabled = &config.Enabled
+		return nil
+	}
+	return json.Unmarshal(trimmed, &c.Items)
+}
+
+// MarshalJSON merges server tool options into the tool definition.
+func (t tool) MarshalJSON() ([]byte, error) {
+	type plain tool
+	body, err := json.Marshal(plain(t))
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Options) == 0 {
+		return body, nil
+	}
+
+	var envelope map[string]any
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	for key, value := range t.Options {
+		key = strings.TrimSpace(key)
+		if key != "" && value != nil {
+			if _, exists := envelope[jsonKey(key)]; !exists {
+				envelope[jsonKey(key)] = value
+			}
+		}
+	}
+
+	return json.Marshal(envelope)
+}
+
 func jsonKey(key string) string {
 	switch key {
 	case "maxTokens":
@@ -73,6 +142,33 @@ func camelToSnake(value string) string {
 	return builder.String()
 }
 
+// mergeUsage applies a message_delta usage update to the usage reported by
+// message_start. Fields the update leaves unset keep their earlier value.
+func mergeUsage(base, update usage) usage {
+	if update.InputTokens > 0 {
+		base.InputTokens = update.InputTokens
+	}
+	if update.OutputTokens > 0 {
+		base.OutputTokens = update.OutputTokens
+	}
+	if update.CacheCreationInputTokens > 0 {
+		base.CacheCreationInputTokens = update.CacheCreationInputTokens
+	}
+	if update.CacheReadInputTokens > 0 {
+		base.CacheReadInputTokens = update.CacheReadInputTokens
+	}
+	if update.ServerToolUse != nil {
+		base.ServerToolUse = update.ServerToolUse
+	}
+	if update.CacheCreation != nil {
+		base.CacheCreation = update.CacheCreation
+	}
+	if update.ServiceTier != "" {
+		base.ServiceTier = update.ServiceTier
+	}
+	return base
+}
+
 func extractText(content []contentBlock) string {
 	var builder strings.Builder
 	for _, block := range content {
@@ -105,6 +201,33 @@ func extractReasoning(content []contentBlock) string {
 	return strings.TrimSpace(strings.Join(parts, "\n"))
 }
 
+// extractReasoningBlocks returns the thinking and redacted_thinking blocks
+// that must be replayed alongside tool results.
+func extractReasoningBlocks(content []contentBlock) []core.ReasoningBlock {
+	var out []core.ReasoningBlock
+	for _, block := range content {
+		switch block.Type {
+		case "thinking":
+			out = append(out, core.ReasoningBlock{Type: block.Type, Text: block.Thinking, Signature: block.Signature})
+		case "redacted_thinking":
+			out = append(out, core.ReasoningBlock{Type: block.Type, Data: block.Data})
+		}
+	}
+	return out
+}
+
+// reasoningBlocksText joins the text of thinking blocks the same way
+// extractReasoning does.
+func reasoningBlocksText(blocks []core.ReasoningBlock) string {
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Type == "thinking" && strings.TrimSpace(block.Text) != "" {
+			parts = append(parts, strings.TrimSpace(block.Text))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
 func extractToolUses(content []contentBlock) []contentBlock {
 	out := make([]contentBlock, 0)
 	for _, block := range content {
@@ -115,6 +238,83 @@ func extractToolUses(content []contentBlock) []contentBlock {
 	return out
 }
 
+func extractCitations(content []contentBlock) []core.Citation {
+	var out []core.Citation
+	for _, block := range content {
+		if block.Type != "text" || block.Citations == nil {
+			continue
+		}
+		for _, item := range block.Citations.Items {
+			out = append(out, core.Citation{
+				Type:          item.Type,
+				URL:           nonEmpty(item.URL, item.Source),
+				Title:         nonEmpty(item.Title, item.DocumentTitle),
+				CitedText:     item.CitedText,
+				DocumentIndex: max(item.DocumentIndex, item.SearchResultIndex),
+			})
+		}
+	}
+	return out
+}
+
+// appendProviderToolCalls records mcp_tool_use blocks and fills in results
+// from the matching mcp_tool_result blocks.
+func appendProviderToolCalls(calls []core.ProviderToolCall, content []contentBlock) []core.ProviderToolCall {
+	for _, block := range content {
+		switch block.Type {
+		case "mcp_tool_use":
+			calls = append(calls, core.ProviderToolCall{
+				ID:        block.ID,
+				Name:      block.Name,
+				Server:    block.ServerName,
+				Arguments: block.Input,
+			})
+		case "mcp_tool_result":
+			for i := range calls {
+				if calls[i].ID == block.ToolUseID {
+					calls[i].Result = blockContentText(block.Content)
+					calls[i].IsError = block.IsError
+				}
+			}
+		}
+	}
+	return calls
+}
+
+// blockContentText flattens a tool result's content, which is either a string
+// or a list of text blocks.
+func blockContentText(content any) string {
+	switch typed := content.(type) {
+	case string:
+		return typed
+	case []any:
+		var builder strings.Builder
+		for _, item := range typed {
+			block, ok := item.(map[string]any)
+			if !ok || block["type"] != "text" {
+				continue
+			}
+			text, _ := block["text"].(string)
+			builder.WriteString(text)
+		}
+		return builder.String()
+	default:
+		return ""
+	}
+}
+
+func findToolUse(toolUses []contentBlock, name string) (contentBlock, bool) {
+	if name == "" {
+		return contentBlock{}, false
+	}
+	for _, use := range toolUses {
+		if use.Name == name {
+			return use, true
+		}
+	}
+	return contentBlock{}, false
+}
+
 func toolResultBlock(toolUseID, result string) contentBlock {
 	return contentBlock{
 		Type:      "tool_result",
@@ -124,9 +324,30 @@ func toolResultBlock(toolUseID, result string) contentBlock {
 }
 
 func decodeAPIError(resp *http.Response) error {
+	message, errorType := decodeAPIErrorBody(resp)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &core.RateLimitError{
+			Message:    message,
+			RetryAfter: retryAfter(resp.Header),
+			RateLimit:  parseRateLimit(resp.Header),
+			RequestID:  httpclient.RequestID(resp.Header),
+		}
+	}
+
+	return &core.APIError{
+		StatusCode: resp.StatusCode,
+		Type:       errorType,
+		Message:    message,
+		RequestID:  httpclient.RequestID(resp.Header),
+		Retryable:  resp.StatusCode >= http.StatusInternalServerError || errorType == "overloaded_error",
+		RetryAfter: retryAfter(resp.Header),
+	}
+}
+
+func decodeAPIErrorBody(resp *http.Response) (string, string) {
 	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
 	if readErr != nil {
-		return fmt.Errorf("claude: API status %d and failed to read error body: %w", resp.StatusCode, readErr)
+		return fmt.Sprintf("claude: API status %d and failed to read error body: %v", resp.StatusCode, readErr), ""
 	}
 
 	var envelope struct {
@@ -139,9 +360,9 @@ func decodeAPIError(resp *http.Response) error {
 
 	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error.Message != "" {
 		if envelope.Error.Type != "" {
-			return fmt.Errorf("claude: API error (%s): %s", envelope.Error.Type, envelope.Error.Message)
+			return fmt.Sprintf("claude: API error (%s): %s", envelope.Error.Type, envelope.Error.Message), envelope.Error.Type
 		}
-		return fmt.Errorf("claude: API error: %s", envelope.Error.Message)
+		return fmt.Sprintf("claude: API error: %s", envelope.Error.Message), ""
 	}
 
 	text := strings.TrimSpace(string(body))
@@ -149,5 +370,58 @@ func decodeAPIError(resp *http.Response) error {
 		text = http.StatusText(resp.StatusCode)
 	}
 
-	return fmt.Errorf("claude: API status %d: %s", resp.StatusCode, text)
+	return fmt.Sprintf("claude: API status %d: %s", resp.StatusCode, text), ""
+}
+
+// parseRateLimit reads the anthropic-ratelimit-* headers. Returns nil when
+// none are present.
+func parseRateLimit(header http.Header) *core.RateLimit {
+	if header == nil {
+		return nil
+	}
+
+	rateLimit := core.RateLimit{
+		LimitRequests:     headerInt(header, "anthropic-ratelimit-requests-limit"),
+		LimitTokens:       headerInt(header, "anthropic-ratelimit-tokens-limit"),
+		RemainingRequests: headerInt(header, "anthropic-ratelimit-requests-remaining"),
+		RemainingTokens:   headerInt(header, "anthropic-ratelimit-tokens-remaining"),
+		ResetRequests:     headerReset(header, "anthropic-ratelimit-requests-reset"),
+		ResetTokens:       headerReset(header, "anthropic-ratelimit-tokens-reset"),
+	}
+	if rateLimit == (core.RateLimit{}) {
+		return nil
+	}
+	return &rateLimit
+}
+
+func retryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(header.Get("retry-after")), 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func headerInt(header http.Header, key string) int64 {
+	value, err := strconv.ParseInt(strings.TrimSpace(header.Get(key)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// headerReset converts an RFC 3339 reset timestamp into the time remaining
+// until the limit resets.
+func headerReset(header http.Header, key string) time.Duration {
+	at, err := time.Parse(time.RFC3339, strings.TrimSpace(header.Get(key)))
+	if err != nil {
+		return 0
+	}
+	if wait := time.Until(at); wait > 0 {
+		return wait
+	}
+	return 0
 }
@@ -0,0 +1,210 @@
+// Package cohere is an adapter for the Cohere v2 chat and embed APIs.
+//
+// Documents passed as core.SearchResultPart or plain-text core.DocumentPart
+// are sent as grounding documents, and the citations Cohere returns for them
+// are reported on core.ChatResult.Citations.
+package cohere
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+const (
+	providerName           = "cohere"
+	defaultBaseURL         = "https://api.cohere.com/v2"
+	defaultMaxAgenticLoops = 8
+	defaultHTTPTimeout     = 5 * time.Minute
+	defaultThinkingBudget  = 4096
+	defaultEmbedInputType  = "search_document"
+	envCohereAPIKey        = "COHERE_API_KEY"
+	envCoAPIKey            = "CO_API_KEY"
+)
+
+// Citation modes supported by the chat API.
+const (
+	CitationModeFast     = "FAST"
+	CitationModeAccurate = "ACCURATE"
+	CitationModeOff      = "OFF"
+)
+
+type Adapter struct {
+	APIKey  string
+	Model   string
+	BaseURL string
+
+	// CitationMode selects how citations are generated for grounded
+	// responses: CitationModeFast, CitationModeAccurate, or CitationModeOff.
+	// The API default applies when empty.
+	CitationMode string
+
+	Gzip       bool
+	HTTPClient *http.Client
+}
+
+var _ core.TextAdapter = (*Adapter)(nil)
+var _ core.EmbeddingAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
+
+type Option func(*Adapter)
+
+// New creates a Cohere adapter.
+//
+// Preferred usage is to use core and add this adapter there.
+//
+// If no API key is provided via options, New reads COHERE_API_KEY and then CO_API_KEY.
+func New(model string, opts ...Option) *Adapter {
+	adapter := &Adapter{
+		APIKey:     resolveAPIKey(),
+		Model:      strings.TrimSpace(model),
+		BaseURL:    defaultBaseURL,
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(adapter)
+	}
+
+	return adapter
+}
+
+// WithAPIKey sets the API key used by the adapter.
+func WithAPIKey(apiKey string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(apiKey) == "" {
+			return
+		}
+		adapter.APIKey = strings.TrimSpace(apiKey)
+	}
+}
+
+// WithBaseURL sets the API base URL used by the adapter.
+func WithBaseURL(baseURL string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(baseURL) == "" {
+			return
+		}
+		adapter.BaseURL = strings.TrimSpace(baseURL)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the adapter.
+func WithHTTPClient(client *http.Client) Option {
+	return func(adapter *Adapter) {
+		if client == nil {
+			return
+		}
+		adapter.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the timeout on the adapter HTTP client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(adapter *Adapter) {
+		if timeout <= 0 {
+			return
+		}
+		if adapter.HTTPClient == nil {
+			adapter.HTTPClient = &http.Client{}
+		}
+		adapter.HTTPClient.Timeout = timeout
+	}
+}
+
+// WithCitationMode sets the citation mode of chat requests, such as
+// CitationModeAccurate.
+func WithCitationMode(mode string) Option {
+	return func(adapter *Adapter) {
+		adapter.CitationMode = strings.ToUpper(strings.TrimSpace(mode))
+	}
+}
+
+// WithGzip compresses request bodies of 1 KiB or more with gzip and asks for
+// gzip responses.
+func WithGzip() Option {
+	return func(adapter *Adapter) {
+		adapter.Gzip = true
+	}
+}
+
+// Capabilities reports the chat features of the Cohere v2 API. Vision and
+// reasoning depend on the model.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:            true,
+		Vision:           true,
+		Documents:        true,
+		StructuredOutput: true,
+		Reasoning:        true,
+	}
+}
+
+func (a *Adapter) validate() error {
+	if a == nil {
+		return errors.New("cohere: adapter is nil")
+	}
+
+	if strings.TrimSpace(a.APIKey) == "" {
+		a.APIKey = resolveAPIKey()
+	}
+	if strings.TrimSpace(a.APIKey) == "" {
+		return errors.New("cohere: API key is required (set COHERE_API_KEY or use cohere.WithAPIKey)")
+	}
+
+	if strings.TrimSpace(a.Model) == "" {
+		return errors.New("cohere: model is required")
+	}
+
+	switch a.CitationMode {
+	case "", CitationModeFast, CitationModeAccurate, CitationModeOff:
+	default:
+		return errors.New("cohere: citation mode must be FAST, ACCURATE, or OFF")
+	}
+
+	return nil
+}
+
+func (a *Adapter) client() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// transport returns the client that sends API requests.
+func (a *Adapter) transport() *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   providerName,
+		Header: func(header http.Header) {
+			header.Set("Authorization", "Bearer "+a.APIKey)
+			header.Set("Accept", "application/json")
+		},
+		DecodeError: decodeAPIError,
+		Gzip:        a.Gzip,
+	}
+}
+
+func (a *Adapter) baseURL() string {
+	if strings.TrimSpace(a.BaseURL) == "" {
+		return defaultBaseURL
+	}
+	return a.BaseURL
+}
+
+func resolveAPIKey() string {
+	if key := strings.TrimSpace(os.Getenv(envCohereAPIKey)); key != "" {
+		return key
+	}
+	return strings.TrimSpace(os.Getenv(envCoAPIKey))
+}
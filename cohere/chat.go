@@ -0,0 +1,381 @@
+package cohere
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/partialjson"
+	"github.com/m43i/go-ai/internal/sse"
+)
+
+// Chat sends a non-streaming chat request to Cohere.
+//
+// It supports tool calls, structured output, grounding documents with
+// citations, and reasoning.
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	requestTemplate, serverTools, clientTools, maxLoopCount, err := a.buildRequest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := requestTemplate.Messages
+	conversation := cloneCoreMessages(params)
+	reasoningParts := make([]string, 0, 4)
+
+	for range maxLoopCount {
+		request := requestTemplate
+		request.Messages = messages
+
+		response, err := a.postChat(ctx, &request)
+		if err != nil {
+			return nil, err
+		}
+
+		reasoningParts = appendReasoningPart(reasoningParts, extractReasoning(response.Message.Content))
+
+		if len(response.Message.ToolCalls) == 0 {
+			text := extractText(response.Message.Content)
+			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: text})
+			return &core.ChatResult{
+				Text:            text,
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				Citations:       toCoreCitations(response.Message.Citations, request.Documents),
+				FinishReason:    toCoreFinishReason(response.FinishReason),
+				RawFinishReason: response.FinishReason,
+				Usage:           responseUsage(response),
+				RequestID:       response.RequestID,
+			}, nil
+		}
+
+		coreCalls, err := toCoreToolCalls(response.Message.ToolCalls)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message{
+			Role:      core.RoleAssistant,
+			Content:   thinkingParts(response.Message.Content),
+			ToolPlan:  response.Message.ToolPlan,
+			ToolCalls: response.Message.ToolCalls,
+		})
+		conversation = append(conversation, core.ToolCallMessagePart{
+			Role:            core.RoleToolCall,
+			ToolCalls:       coreCalls,
+			ReasoningBlocks: reasoningBlocks(response.Message),
+		})
+
+		pendingClientCalls := make([]core.ToolCall, 0)
+		for _, call := range coreCalls {
+			if serverTool, ok := serverTools[call.Name]; ok {
+				result, callErr := serverTool.Handler(call.Arguments)
+				if callErr != nil {
+					result = "tool_error: " + callErr.Error()
+				}
+
+				messages = append(messages, *toolMessage(call.ID, result))
+				conversation = append(conversation, core.ToolResultMessagePart{
+					Role:       core.RoleToolResult,
+					ToolCallID: call.ID,
+					Name:       call.Name,
+					Content:    result,
+				})
+				continue
+			}
+
+			if _, ok := clientTools[call.Name]; ok {
+				pendingClientCalls = append(pendingClientCalls, call)
+				continue
+			}
+
+			return nil, fmt.Errorf("cohere: tool %q was requested but not registered", call.Name)
+		}
+
+		if len(pendingClientCalls) > 0 {
+			return &core.ChatResult{
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				ToolCalls:       pendingClientCalls,
+				FinishReason:    core.FinishReasonToolCalls,
+				RawFinishReason: response.FinishReason,
+				Usage:           responseUsage(response),
+				RequestID:       response.RequestID,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cohere: reached max tool loop count (%d)", maxLoopCount)
+}
+
+// ChatStream sends a streaming chat request to Cohere.
+//
+// Structured output streams as StreamChunkPartialJSON chunks. When tools are
+// configured, ChatStream emits chunks derived from a non-streaming Chat call
+// to preserve consistent behavior. Citations are only reported by Chat.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	request, _, _, _, err := a.buildRequest(params)
+	if err != nil {
+		return nil, err
+	}
+	fallback := len(request.Tools) > 0
+
+	start := time.Now()
+	out := make(chan core.StreamChunk, 64)
+
+	go func() {
+		defer close(out)
+
+		if fallback {
+			result, err := a.Chat(ctx, params)
+			if err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: core.RequestIDOf(err)}
+				return
+			}
+
+			emitChunksFromResult(out, params, result)
+			out <- core.StreamChunk{
+				Type:            core.StreamChunkDone,
+				FinishReason:    result.FinishReason,
+				RawFinishReason: result.RawFinishReason,
+				Reasoning:       result.Reasoning,
+				Usage:           result.Usage,
+				RequestID:       result.RequestID,
+			}
+			return
+		}
+
+		request.Stream = true
+		body, err := marshalWithModelOptions(&request, request.ModelOptions)
+		if err != nil {
+			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("cohere: marshal stream request: %v", err)}
+			return
+		}
+
+		httpResp, err := a.transport().Do(ctx, httpclient.Request{Path: "/chat", Name: "stream", Body: body})
+		if err != nil {
+			out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: core.RequestIDOf(err)}
+			return
+		}
+		defer httpResp.Body.Close()
+		requestID := httpclient.RequestID(httpResp.Header)
+
+		reader := sse.NewReader(httpResp.Body)
+		defer reader.Release()
+
+		var content, reasoning strings.Builder
+		var partial partialjson.Assembler
+		finishReason := ""
+		var usage *core.Usage
+
+		for {
+			sseEvent, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("cohere: stream read failed: %v", err), RequestID: requestID}
+				return
+			}
+
+			payload := bytes.TrimSpace(sseEvent.Data)
+			if len(payload) == 0 {
+				continue
+			}
+
+			var event streamEvent
+			if err := json.Unmarshal(payload, &event); err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("cohere: decode stream event: %v", err), RequestID: requestID}
+				return
+			}
+
+			switch event.Type {
+			case "content-delta":
+				if event.Delta == nil || event.Delta.Message == nil || event.Delta.Message.Content == nil {
+					continue
+				}
+				part := event.Delta.Message.Content
+
+				if part.Thinking != "" {
+					reasoning.WriteString(part.Thinking)
+					out <- core.StreamChunk{
+						Type:      core.StreamChunkReasoning,
+						Role:      core.RoleAssistant,
+						Delta:     part.Thinking,
+						Reasoning: reasoning.String(),
+					}
+				}
+
+				if part.Text == "" {
+					continue
+				}
+				content.WriteString(part.Text)
+				if request.ResponseFormat != nil {
+					partial.WriteString(part.Text)
+					out <- core.StreamChunk{
+						Type:    core.StreamChunkPartialJSON,
+						Role:    core.RoleAssistant,
+						Delta:   part.Text,
+						Content: partial.Partial(),
+					}
+				} else {
+					out <- core.StreamChunk{
+						Type:    core.StreamChunkContent,
+						Role:    core.RoleAssistant,
+						Delta:   part.Text,
+						Content: content.String(),
+					}
+				}
+
+			case "message-end":
+				if event.Delta != nil {
+					finishReason = event.Delta.FinishReason
+					usage = toCoreUsage(event.Delta.Usage)
+					if event.Delta.Error != "" {
+						out <- core.StreamChunk{Type: core.StreamChunkError, Error: "cohere: stream error: " + event.Delta.Error, RequestID: requestID}
+						return
+					}
+				}
+				if usage != nil {
+					usage.RequestID = requestID
+				}
+				out <- core.StreamChunk{
+					Type:            core.StreamChunkDone,
+					FinishReason:    toCoreFinishReason(finishReason),
+					RawFinishReason: finishReason,
+					Reasoning:       strings.TrimSpace(reasoning.String()),
+					Usage:           usage,
+					RequestID:       requestID,
+				}
+				return
+			}
+		}
+
+		out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: toCoreFinishReason(finishReason), RawFinishReason: finishReason, Reasoning: strings.TrimSpace(reasoning.String()), Usage: usage, RequestID: requestID}
+	}()
+
+	return core.TimeStream(ctx, out, start), nil
+}
+
+// buildRequest converts params into a chat request template holding the
+// initial messages.
+func (a *Adapter) buildRequest(params *core.ChatParams) (chatRequest, map[string]core.ServerTool, map[string]struct{}, int, error) {
+	messages, documents, err := toMessages(params)
+	if err != nil {
+		return chatRequest{}, nil, nil, 0, err
+	}
+
+	tools, serverTools, clientTools, err := toTools(params)
+	if err != nil {
+		return chatRequest{}, nil, nil, 0, err
+	}
+
+	thinkingConfig, err := toThinking(params)
+	if err != nil {
+		return chatRequest{}, nil, nil, 0, err
+	}
+
+	request := chatRequest{
+		Model:          a.Model,
+		Messages:       messages,
+		Tools:          tools,
+		Documents:      documents,
+		ResponseFormat: toResponseFormat(params.Output),
+		Thinking:       thinkingConfig,
+		MaxTokens:      maxTokens(params),
+		Temperature:    params.Temperature,
+		P:              params.TopP,
+		K:              params.TopK,
+		Seed:           params.Seed,
+		StopSequences:  params.StopSequences,
+		ModelOptions:   modelOptions(params),
+	}
+	if a.CitationMode != "" {
+		request.CitationOptions = &citationOptions{Mode: a.CitationMode}
+	}
+	if err := applyToolChoice(&request, params); err != nil {
+		return chatRequest{}, nil, nil, 0, err
+	}
+
+	return request, serverTools, clientTools, maxLoops(params, len(serverTools) > 0), nil
+}
+
+func (a *Adapter) postChat(ctx context.Context, request *chatRequest) (*chatResponse, error) {
+	body, err := marshalWithModelOptions(request, request.ModelOptions)
+	if err != nil {
+		return nil, fmt.Errorf("cohere: marshal chat request: %w", err)
+	}
+
+	var response chatResponse
+	httpResp, err := a.transport().Send(ctx, httpclient.Request{Path: "/chat", Name: "chat", Body: body}, &response)
+	if err != nil {
+		return nil, err
+	}
+	response.Duration = httpResp.Duration
+	response.RequestID = httpclient.RequestID(httpResp.Header)
+
+	return &response, nil
+}
+
+func cloneCoreMessages(params *core.ChatParams) []core.MessageUnion {
+	if params == nil || len(params.Messages) == 0 {
+		return nil
+	}
+
+	out := make([]core.MessageUnion, 0, len(params.Messages)+8)
+	out = append(out, params.Messages...)
+	return out
+}
+
+func emitChunksFromResult(out chan<- core.StreamChunk, params *core.ChatParams, result *core.ChatResult) {
+	if result == nil {
+		return
+	}
+
+	if reasoning := strings.TrimSpace(result.Reasoning); reasoning != "" {
+		out <- core.StreamChunk{
+			Type:      core.StreamChunkReasoning,
+			Role:      core.RoleAssistant,
+			Delta:     reasoning,
+			Reasoning: reasoning,
+		}
+	}
+
+	start := 0
+	if params != nil {
+		start = len(params.Messages)
+	}
+	if start < 0 || start > len(result.Messages) {
+		start = 0
+	}
+
+	for _, message := range result.Messages[start:] {
+		switch m := message.(type) {
+		case core.TextMessagePart:
+			if m.Role == core.RoleAssistant {
+				out <- core.StreamChunk{Type: core.StreamChunkContent, Role: core.RoleAssistant, Delta: m.Content, Content: m.Content}
+			}
+		case core.ToolCallMessagePart:
+			for _, call := range m.ToolCalls {
+				c := call
+				out <- core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &c}
+			}
+		case core.ToolResultMessagePart:
+			out <- core.StreamChunk{Type: core.StreamChunkToolResult, ToolCallID: m.ToolCallID, Content: m.Content}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package cohere
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatMapsDocumentsAndCitations(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("unexpected Authorization %q", r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-request-id", "req-1")
+		_, _ = w.Write([]byte(`{
+			"id": "c1",
+			"finish_reason": "COMPLETE",
+			"message": {
+				"role": "assistant",
+				"content": [{"type": "text", "text": "Emperor penguins are the tallest."}],
+				"citations": [{
+					"start": 0, "end": 16, "text": "Emperor penguins", "type": "TEXT_CONTENT",
+					"sources": [{"type": "document", "id": "doc_1", "document": {"id": "doc_1", "title": "Penguins", "text": "..."}}]
+				}]
+			},
+			"usage": {"billed_units": {"input_tokens": 20, "output_tokens": 7}, "tokens": {"input_tokens": 120, "output_tokens": 7}}
+		}`))
+	}))
+	defer server.Close()
+
+	seed := int64(7)
+	adapter := New("command-r-plus", WithAPIKey("test-key"), WithBaseURL(server.URL), WithCitationMode("accurate"))
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		SystemPrompts: []string{"Answer from the documents."},
+		Messages: []core.MessageUnion{
+			core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+				core.DocumentPart{
+					Source:   core.DataSource{Data: base64.StdEncoding.EncodeToString([]byte("Emperor penguins are tall.")), MimeType: "text/plain"},
+					Metadata: map[string]any{"title": "Tall birds"},
+				},
+				core.SearchResultPart{Source: "https://example.com/penguins", Title: "Penguins", Texts: []string{"Emperor penguins are the tallest."}},
+				core.TextPart{Text: "Which penguins are the tallest?"},
+			}},
+		},
+		Seed:         &seed,
+		ModelOptions: map[string]any{"safety_mode": "STRICT"},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if result.Text != "Emperor penguins are the tallest." || result.FinishReason != core.FinishReasonStop || result.RequestID != "req-1" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	want := core.Citation{Type: "document", URL: "https://example.com/penguins", Title: "Penguins", StartIndex: 0, EndIndex: 16, CitedText: "Emperor penguins", DocumentIndex: 1}
+	if len(result.Citations) != 1 || result.Citations[0] != want {
+		t.Fatalf("unexpected citations %+v", result.Citations)
+	}
+	if result.Usage.PromptTokens != 120 || result.Usage.Details["billed_input_tokens"] != 20 {
+		t.Fatalf("unexpected usage %+v", result.Usage)
+	}
+
+	documents := request["documents"].([]any)
+	if len(documents) != 2 {
+		t.Fatalf("unexpected documents %#v", documents)
+	}
+	first := documents[0].(map[string]any)
+	if first["id"] != "doc_0" || first["data"].(map[string]any)["text"] != "Emperor penguins are tall." || first["data"].(map[string]any)["title"] != "Tall birds" {
+		t.Fatalf("unexpected document %#v", first)
+	}
+	messages := request["messages"].([]any)
+	if len(messages) != 2 || messages[0].(map[string]any)["role"] != "system" {
+		t.Fatalf("unexpected messages %#v", messages)
+	}
+	user := messages[1].(map[string]any)["content"].([]any)
+	if len(user) != 1 || user[0].(map[string]any)["text"] != "Which penguins are the tallest?" {
+		t.Fatalf("documents should be moved out of the message: %#v", user)
+	}
+	if request["citation_options"].(map[string]any)["mode"] != "ACCURATE" || request["seed"] != float64(7) || request["safety_mode"] != "STRICT" {
+		t.Fatalf("unexpected request %#v", request)
+	}
+}
+
+func TestChatRunsServerTools(t *testing.T) {
+	t.Parallel()
+
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		requests = append(requests, request)
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"finish_reason":"TOOL_CALL","message":{"role":"assistant","tool_plan":"I will check the weather.","tool_calls":[{"id":"call_1","type":"function","function":{"name":"weather","arguments":"{\"city\":\"Berlin\"}"}}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"finish_reason":"COMPLETE","message":{"role":"assistant","content":[{"type":"text","text":"Sunny."}]}}`))
+	}))
+	defer server.Close()
+
+	var arguments any
+	result, err := New("command-a-03-2025", WithAPIKey("test-key"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather?"}},
+		Tools: []core.ToolUnion{core.ServerTool{
+			Name: "weather",
+			Handler: func(args any) (string, error) {
+				arguments = args
+				return "sunny", nil
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if result.Text != "Sunny." || arguments.(map[string]any)["city"] != "Berlin" {
+		t.Fatalf("unexpected result %+v, arguments %#v", result, arguments)
+	}
+	function := requests[0]["tools"].([]any)[0].(map[string]any)["function"].(map[string]any)
+	if function["name"] != "weather" || function["parameters"] == nil {
+		t.Fatalf("unexpected tool %#v", function)
+	}
+
+	messages := requests[1]["messages"].([]any)
+	assistant := messages[1].(map[string]any)
+	if assistant["tool_plan"] != "I will check the weather." || assistant["tool_calls"] == nil {
+		t.Fatalf("unexpected assistant message %#v", assistant)
+	}
+	tool := messages[2].(map[string]any)
+	if tool["role"] != "tool" || tool["tool_call_id"] != "call_1" || tool["content"].([]any)[0].(map[string]any)["text"] != "sunny" {
+		t.Fatalf("unexpected tool message %#v", tool)
+	}
+
+	calls, ok := result.Messages[1].(core.ToolCallMessagePart)
+	if !ok || len(calls.ReasoningBlocks) != 1 || calls.ReasoningBlocks[0].Type != "tool_plan" {
+		t.Fatalf("tool plan was not kept on the conversation: %#v", result.Messages[1])
+	}
+}
+
+func TestChatStructuredOutputAndToolChoice(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"finish_reason":"COMPLETE","message":{"role":"assistant","content":[{"type":"text","text":"{\"answer\":\"42\"}"}]}}`))
+	}))
+	defer server.Close()
+
+	_, err := New("command-r", WithAPIKey("test-key"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Answer?"}},
+		Output:   &core.Schema{Name: "answer", Schema: map[string]any{"type": "object"}},
+		Tools: []core.ToolUnion{
+			core.ClientTool{Name: "lookup"},
+			core.ClientTool{Name: "search"},
+		},
+		ToolChoice: &core.ToolChoice{Name: "search"},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	format := request["response_format"].(map[string]any)
+	if format["type"] != "json_object" || format["json_schema"].(map[string]any)["type"] != "object" {
+		t.Fatalf("unexpected response_format %#v", format)
+	}
+	tools := request["tools"].([]any)
+	if len(tools) != 1 || request["tool_choice"] != "REQUIRED" {
+		t.Fatalf("named tool choice should offer only that tool: %#v", request)
+	}
+}
+
+func TestChatDecodesRateLimitErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"message":"trial key rate limit exceeded"}`))
+	}))
+	defer server.Close()
+
+	_, err := New("command-r", WithAPIKey("test-key"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+	})
+	var rateLimit *core.RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("expected RateLimitError, got %T %v", err, err)
+	}
+	if rateLimit.RetryAfter.Seconds() != 2 || rateLimit.Message != "cohere: API error: trial key rate limit exceeded" {
+		t.Fatalf("unexpected error %+v", rateLimit)
+	}
+}
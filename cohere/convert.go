@@ -0,0 +1,622 @@
+package cohere
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// toMessages converts params into chat messages and the grounding documents
+// they carry. System prompts become system messages. Search result parts and
+// text document parts are moved into the request documents, which Cohere
+// accepts only at the top level; messages left without other content are
+// dropped.
+func toMessages(params *core.ChatParams) ([]message, []document, error) {
+	if params == nil {
+		return nil, nil, errors.New("cohere: chat params are required")
+	}
+
+	messages := make([]message, 0, len(params.SystemPrompts)+len(params.Messages))
+	for _, prompt := range params.SystemPrompts {
+		if prompt = strings.TrimSpace(prompt); prompt != "" {
+			messages = append(messages, message{Role: core.RoleSystem, Content: []contentPart{{Type: "text", Text: prompt}}})
+		}
+	}
+
+	var documents []document
+	for i, union := range params.Messages {
+		msg, err := toMessage(union, &documents)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cohere: invalid message at index %d: %w", i, err)
+		}
+		if msg != nil {
+			messages = append(messages, *msg)
+		}
+	}
+
+	return messages, documents, nil
+}
+
+func toMessage(union core.MessageUnion, documents *[]document) (*message, error) {
+	switch msg := union.(type) {
+	case core.TextMessagePart:
+		return textMessage(msg.Role, msg.Content)
+	case *core.TextMessagePart:
+		if msg == nil {
+			return nil, errors.New("text message is nil")
+		}
+		return textMessage(msg.Role, msg.Content)
+
+	case core.ContentMessagePart:
+		return contentMessage(msg.Role, msg.Parts, documents)
+	case *core.ContentMessagePart:
+		if msg == nil {
+			return nil, errors.New("content message is nil")
+		}
+		return contentMessage(msg.Role, msg.Parts, documents)
+
+	case core.AssistantToolCallMessagePart:
+		return assistantToolCallMessage(msg.Role, msg.ToolCalls, msg.ReasoningBlocks)
+	case *core.AssistantToolCallMessagePart:
+		if msg == nil {
+			return nil, errors.New("assistant tool call message is nil")
+		}
+		return assistantToolCallMessage(msg.Role, msg.ToolCalls, msg.ReasoningBlocks)
+
+	case core.ToolResultMessagePart:
+		return toolResultMessage(msg.Role, msg.ToolCallID, msg.Content)
+	case *core.ToolResultMessagePart:
+		if msg == nil {
+			return nil, errors.New("tool result message is nil")
+		}
+		return toolResultMessage(msg.Role, msg.ToolCallID, msg.Content)
+	}
+
+	return nil, fmt.Errorf("unsupported message type %T", union)
+}
+
+func textMessage(role, content string) (*message, error) {
+	normalizedRole, err := normalizeRole(role)
+	if err != nil {
+		return nil, err
+	}
+	if content == "" {
+		return nil, errors.New("text message content is required")
+	}
+
+	return &message{Role: normalizedRole, Content: []contentPart{{Type: "text", Text: content}}}, nil
+}
+
+func contentMessage(role string, parts []core.ContentPart, documents *[]document) (*message, error) {
+	normalizedRole, err := normalizeRole(role)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, errors.New("content message must include at least one content part")
+	}
+
+	content := make([]contentPart, 0, len(parts))
+	grounded := false
+	for i, part := range parts {
+		if doc, ok, err := toDocument(part, len(*documents)); err != nil {
+			return nil, fmt.Errorf("content part at index %d: %w", i, err)
+		} else if ok {
+			*documents = append(*documents, doc)
+			grounded = true
+			continue
+		}
+
+		converted, ok, err := toContentPart(part)
+		if err != nil {
+			return nil, fmt.Errorf("content part at index %d: %w", i, err)
+		}
+		if ok {
+			content = append(content, converted)
+		}
+	}
+	if len(content) == 0 {
+		if grounded {
+			return nil, nil
+		}
+		return nil, errors.New("content message has no content for cohere")
+	}
+
+	return &message{Role: normalizedRole, Content: content}, nil
+}
+
+// toContentPart converts one non-document content part. It reports false for
+// parts that are skipped, such as raw parts for other providers.
+func toContentPart(part core.ContentPart) (contentPart, bool, error) {
+	switch typed := part.(type) {
+	case core.TextPart:
+		return contentPart{Type: "text", Text: typed.Text}, typed.Text != "", nil
+	case *core.TextPart:
+		if typed == nil {
+			return contentPart{}, false, errors.New("text part is nil")
+		}
+		return toContentPart(*typed)
+
+	case core.ImagePart:
+		url, err := imageURLFromSource(typed.Source)
+		if err != nil {
+			return contentPart{}, false, err
+		}
+		detail, _ := typed.Metadata["detail"].(string)
+		return contentPart{Type: "image_url", ImageURL: &imageURL{URL: url, Detail: strings.TrimSpace(detail)}}, true, nil
+	case *core.ImagePart:
+		if typed == nil {
+			return contentPart{}, false, errors.New("image part is nil")
+		}
+		return toContentPart(*typed)
+
+	case core.RawPart:
+		if !strings.EqualFold(strings.TrimSpace(typed.Provider), providerName) {
+			return contentPart{}, false, nil
+		}
+		if !json.Valid(typed.Payload) || !bytes.HasPrefix(bytes.TrimSpace(typed.Payload), []byte("{")) {
+			return contentPart{}, false, errors.New("cohere: raw part payload must be a JSON object")
+		}
+		return contentPart{Raw: typed.Payload}, true, nil
+	case *core.RawPart:
+		if typed == nil {
+			return contentPart{}, false, errors.New("raw part is nil")
+		}
+		return toContentPart(*typed)
+
+	case core.AudioPart, *core.AudioPart:
+		return contentPart{}, false, errors.New("cohere: audio content is not supported")
+	case core.FilePart, *core.FilePart:
+		return contentPart{}, false, errors.New("cohere: file content is not supported; use a DocumentPart with text")
+	}
+
+	return contentPart{}, false, fmt.Errorf("unsupported content part type %T", part)
+}
+
+// toDocument converts search result parts and text document parts into
+// grounding documents with the ID "doc_<index>". It reports false for other
+// parts.
+func toDocument(part core.ContentPart, index int) (document, bool, error) {
+	id := "doc_" + strconv.Itoa(index)
+
+	switch typed := part.(type) {
+	case core.SearchResultPart:
+		data := map[string]any{"text": strings.Join(typed.Texts, "\n\n")}
+		if title := strings.TrimSpace(typed.Title); title != "" {
+			data["title"] = title
+		}
+		if source := strings.TrimSpace(typed.Source); source != "" {
+			data["url"] = source
+		}
+		return document{ID: id, Data: data}, true, nil
+	case *core.SearchResultPart:
+		if typed == nil {
+			return document{}, false, errors.New("search result part is nil")
+		}
+		return toDocument(*typed, index)
+
+	case core.DocumentPart:
+		text, err := documentText(typed.Source)
+		if err != nil {
+			return document{}, false, err
+		}
+		data := map[string]any{"text": text}
+		for _, key := range []string{"title", "url"} {
+			if value, _ := typed.Metadata[key].(string); strings.TrimSpace(value) != "" {
+				data[key] = strings.TrimSpace(value)
+			}
+		}
+		return document{ID: id, Data: data}, true, nil
+	case *core.DocumentPart:
+		if typed == nil {
+			return document{}, false, errors.New("document part is nil")
+		}
+		return toDocument(*typed, index)
+	}
+
+	return document{}, false, nil
+}
+
+// documentText decodes a base64 text document. Cohere grounds responses on
+// text only, so binary formats such as PDF are rejected.
+func documentText(source core.Source) (string, error) {
+	var data core.DataSource
+	switch typed := source.(type) {
+	case core.DataSource:
+		data = typed
+	case *core.DataSource:
+		if typed == nil {
+			return "", errors.New("document data source is nil")
+		}
+		data = *typed
+	case nil:
+		return "", errors.New("document source is required")
+	default:
+		return "", fmt.Errorf("cohere: documents must be inline data, got %T", source)
+	}
+
+	mimeType := strings.ToLower(strings.TrimSpace(data.MimeType))
+	if !strings.HasPrefix(mimeType, "text/") && mimeType != "application/json" {
+		return "", fmt.Errorf("cohere: unsupported document type %q; only text documents are supported", data.MimeType)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data.Data))
+	if err != nil {
+		return "", fmt.Errorf("cohere: document data must be base64: %w", err)
+	}
+	return string(decoded), nil
+}
+
+func imageURLFromSource(source core.Source) (string, error) {
+	switch typed := source.(type) {
+	case core.URLSource:
+		url := strings.TrimSpace(typed.URL)
+		if url == "" {
+			return "", errors.New("image URL is required")
+		}
+		return url, nil
+	case *core.URLSource:
+		if typed == nil {
+			return "", errors.New("image URL source is nil")
+		}
+		return imageURLFromSource(*typed)
+
+	case core.DataSource:
+		data := strings.TrimSpace(typed.Data)
+		if data == "" {
+			return "", errors.New("image data is required")
+		}
+		if strings.HasPrefix(data, "data:") {
+			return "", errors.New("image data must be raw base64")
+		}
+		mimeType := strings.TrimSpace(typed.MimeType)
+		if mimeType == "" {
+			return "", errors.New("image mime type is required")
+		}
+		return fmt.Sprintf("data:%s;base64,%s", mimeType, data), nil
+	case *core.DataSource:
+		if typed == nil {
+			return "", errors.New("image data source is nil")
+		}
+		return imageURLFromSource(*typed)
+	}
+
+	return "", fmt.Errorf("unsupported image source type %T", source)
+}
+
+// assistantToolCallMessage converts tool calls. Reasoning blocks of type
+// "thinking" are sent back as thinking content and a "tool_plan" block as
+// the message tool plan.
+func assistantToolCallMessage(role string, calls []core.ToolCall, reasoning []core.ReasoningBlock) (*message, error) {
+	role = strings.TrimSpace(strings.ToLower(role))
+	if role == "" {
+		role = core.RoleToolCall
+	}
+	if role != core.RoleToolCall && role != core.RoleAssistant {
+		return nil, fmt.Errorf("tool call message role must be %q or %q, got %q", core.RoleToolCall, core.RoleAssistant, role)
+	}
+	if len(calls) == 0 {
+		return nil, errors.New("assistant tool call message must include at least one tool call")
+	}
+
+	msg := &message{Role: core.RoleAssistant}
+	for _, block := range reasoning {
+		switch block.Type {
+		case "thinking":
+			msg.Content = append(msg.Content, contentPart{Type: "thinking", Thinking: block.Text})
+		case "tool_plan":
+			msg.ToolPlan = block.Text
+		}
+	}
+
+	for i, call := range calls {
+		name := strings.TrimSpace(call.Name)
+		if name == "" {
+			return nil, fmt.Errorf("tool call at index %d is missing a name", i)
+		}
+
+		id := strings.TrimSpace(call.ID)
+		if id == "" {
+			id = fmt.Sprintf("call_%d", i+1)
+		}
+
+		arguments, err := encodeArguments(call.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("tool call %q arguments: %w", name, err)
+		}
+
+		msg.ToolCalls = append(msg.ToolCalls, toolCall{
+			ID:       id,
+			Type:     "function",
+			Function: toolCallFunction{Name: name, Arguments: arguments},
+		})
+	}
+
+	return msg, nil
+}
+
+func encodeArguments(arguments any) (string, error) {
+	switch typed := arguments.(type) {
+	case nil:
+		return "{}", nil
+	case string:
+		if strings.TrimSpace(typed) == "" {
+			return "{}", nil
+		}
+		return typed, nil
+	case json.RawMessage:
+		return string(typed), nil
+	}
+
+	encoded, err := json.Marshal(arguments)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func toolResultMessage(role, toolCallID, content string) (*message, error) {
+	role = strings.TrimSpace(strings.ToLower(role))
+	if role == "" {
+		role = core.RoleToolResult
+	}
+	if role != core.RoleToolResult && role != "tool" {
+		return nil, fmt.Errorf("tool result message role must be %q or %q, got %q", core.RoleToolResult, "tool", role)
+	}
+	if strings.TrimSpace(toolCallID) == "" {
+		return nil, errors.New("tool result message tool call ID is required")
+	}
+
+	return toolMessage(strings.TrimSpace(toolCallID), content), nil
+}
+
+func toolMessage(toolCallID, content string) *message {
+	return &message{
+		Role:       "tool",
+		ToolCallID: toolCallID,
+		Content:    []contentPart{{Type: "text", Text: content}},
+	}
+}
+
+func normalizeRole(role string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(role))
+	switch normalized {
+	case "":
+		return "", errors.New("message role is required")
+	case core.RoleSystem, core.RoleUser, core.RoleAssistant:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("unsupported role %q", role)
+	}
+}
+
+func toTools(params *core.ChatParams) ([]tool, map[string]core.ServerTool, map[string]struct{}, error) {
+	if params == nil || len(params.Tools) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	tools := make([]tool, 0, len(params.Tools))
+	serverTools := make(map[string]core.ServerTool)
+	clientTools := make(map[string]struct{})
+	seenNames := make(map[string]struct{})
+
+	for i, union := range params.Tools {
+		switch toolValue := union.(type) {
+		case core.ServerTool:
+			definition, serverTool, err := newServerTool(toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("cohere: invalid server tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, serverTool.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			serverTools[serverTool.Name] = serverTool
+
+		case *core.ServerTool:
+			if toolValue == nil {
+				return nil, nil, nil, fmt.Errorf("cohere: server tool at index %d is nil", i)
+			}
+			definition, serverTool, err := newServerTool(*toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("cohere: invalid server tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, serverTool.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			serverTools[serverTool.Name] = serverTool
+
+		case core.ClientTool:
+			definition, err := newClientTool(toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("cohere: invalid client tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, definition.Function.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			clientTools[definition.Function.Name] = struct{}{}
+
+		case *core.ClientTool:
+			if toolValue == nil {
+				return nil, nil, nil, fmt.Errorf("cohere: client tool at index %d is nil", i)
+			}
+			definition, err := newClientTool(*toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("cohere: invalid client tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, definition.Function.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			clientTools[definition.Function.Name] = struct{}{}
+
+		default:
+			return nil, nil, nil, fmt.Errorf("cohere: unsupported tool type %T", union)
+		}
+	}
+
+	return tools, serverTools, clientTools, nil
+}
+
+func newServerTool(toolValue core.ServerTool) (tool, core.ServerTool, error) {
+	name := strings.TrimSpace(toolValue.Name)
+	if name == "" {
+		return tool{}, core.ServerTool{}, errors.New("tool name is required")
+	}
+	if toolValue.Handler == nil {
+		return tool{}, core.ServerTool{}, fmt.Errorf("tool %q handler is required", name)
+	}
+
+	toolValue.Name = name
+	return newToolDefinition(name, toolValue.Description, toolValue.Parameters), toolValue, nil
+}
+
+func newClientTool(toolValue core.ClientTool) (tool, error) {
+	name := strings.TrimSpace(toolValue.Name)
+	if name == "" {
+		return tool{}, errors.New("tool name is required")
+	}
+
+	return newToolDefinition(name, toolValue.Description, toolValue.Parameters), nil
+}
+
+func newToolDefinition(name, description string, parameters map[string]any) tool {
+	if parameters == nil {
+		parameters = map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		}
+	}
+
+	return tool{Type: "function", Function: toolFunction{Name: name, Description: description, Parameters: parameters}}
+}
+
+func assertNewToolName(seen map[string]struct{}, name string) error {
+	if _, exists := seen[name]; exists {
+		return fmt.Errorf("cohere: duplicate tool name %q", name)
+	}
+	seen[name] = struct{}{}
+	return nil
+}
+
+// applyToolChoice sets the tool choice on request. Cohere cannot force a
+// specific tool, so a named choice offers only that tool and requires a
+// call.
+func applyToolChoice(request *chatRequest, params *core.ChatParams) error {
+	if params == nil || params.ToolChoice == nil || len(request.Tools) == 0 {
+		return nil
+	}
+
+	if name := strings.TrimSpace(params.ToolChoice.Name); name != "" {
+		for _, definition := range request.Tools {
+			if definition.Function.Name == name {
+				request.Tools = []tool{definition}
+				request.ToolChoice = "REQUIRED"
+				return nil
+			}
+		}
+		return fmt.Errorf("cohere: tool choice %q does not match a tool", name)
+	}
+
+	switch mode := strings.TrimSpace(params.ToolChoice.Mode); mode {
+	case "", core.ToolChoiceAuto:
+	case core.ToolChoiceNone:
+		request.ToolChoice = "NONE"
+	case core.ToolChoiceRequired:
+		request.ToolChoice = "REQUIRED"
+	default:
+		return fmt.Errorf("cohere: unsupported tool choice mode %q", mode)
+	}
+	return nil
+}
+
+func toResponseFormat(output *core.Schema) *responseFormat {
+	if output == nil {
+		return nil
+	}
+	return &responseFormat{Type: "json_object", JSONSchema: output.Schema}
+}
+
+func maxTokens(params *core.ChatParams) *int64 {
+	if params.MaxTokens != nil && *params.MaxTokens > 0 {
+		return params.MaxTokens
+	}
+	if params.MaxOutputTokens != nil && *params.MaxOutputTokens > 0 {
+		return params.MaxOutputTokens
+	}
+	if params.MaxLength > 0 {
+		value := params.MaxLength
+		return &value
+	}
+	return nil
+}
+
+// toThinking derives the reasoning setting from ChatParams.Thinking, falling
+// back to ChatParams.ReasoningEffort. Thinking accepts "true"/"enabled",
+// "false"/"disabled", an effort level ("low", "medium", "high"), or an
+// explicit token budget such as "8192".
+func toThinking(params *core.ChatParams) (*thinking, error) {
+	if params == nil {
+		return nil, nil
+	}
+
+	raw := strings.ToLower(strings.TrimSpace(params.Thinking))
+	switch raw {
+	case "":
+		if budget := thinkingBudgetForEffort(strings.ToLower(strings.TrimSpace(params.ReasoningEffort))); budget > 0 {
+			return &thinking{Type: "enabled", TokenBudget: &budget}, nil
+		}
+		return nil, nil
+	case "false", "disabled", "off", "none":
+		return &thinking{Type: "disabled"}, nil
+	case "true", "enabled", "on":
+		return &thinking{Type: "enabled"}, nil
+	}
+
+	if budget := thinkingBudgetForEffort(raw); budget > 0 {
+		return &thinking{Type: "enabled", TokenBudget: &budget}, nil
+	}
+
+	budget, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || budget <= 0 {
+		return nil, fmt.Errorf("cohere: unsupported thinking value %q", params.Thinking)
+	}
+	return &thinking{Type: "enabled", TokenBudget: &budget}, nil
+}
+
+func thinkingBudgetForEffort(effort string) int64 {
+	switch effort {
+	case "minimal", "low":
+		return 1024
+	case "medium":
+		return defaultThinkingBudget
+	case "high":
+		return 16384
+	default:
+		return 0
+	}
+}
+
+func modelOptions(params *core.ChatParams) map[string]any {
+	if params == nil || len(params.ModelOptions) == 0 {
+		return nil
+	}
+	return params.ModelOptions
+}
+
+func maxLoops(params *core.ChatParams, hasServerTools bool) int {
+	if !hasServerTools {
+		return 1
+	}
+	if params != nil && params.MaxAgenticLoops > 0 {
+		return int(params.MaxAgenticLoops)
+	}
+	return defaultMaxAgenticLoops
+}
@@ -0,0 +1,109 @@
+package cohere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+// Embed creates one embedding vector for params.Input.
+//
+// Inputs are embedded as "search_document" unless ModelOptions sets
+// "input_type", such as "search_query" for queries.
+func (a *Adapter) Embed(ctx context.Context, params *core.EmbedParams) (*core.EmbedResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("cohere: embed params are required")
+	}
+
+	input := strings.TrimSpace(params.Input)
+	if input == "" {
+		return nil, errors.New("cohere: embed input is required")
+	}
+
+	vectors, usage, err := a.embed(ctx, []string{input}, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.EmbedResult{Embedding: vectors[0], Usage: usage}, nil
+}
+
+// EmbedMany creates embedding vectors for params.Inputs.
+func (a *Adapter) EmbedMany(ctx context.Context, params *core.EmbedManyParams) (*core.EmbedManyResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("cohere: embed many params are required")
+	}
+	if len(params.Inputs) == 0 {
+		return nil, errors.New("cohere: embed many inputs are required")
+	}
+
+	inputs := make([]string, 0, len(params.Inputs))
+	for i, input := range params.Inputs {
+		trimmed := strings.TrimSpace(input)
+		if trimmed == "" {
+			return nil, fmt.Errorf("cohere: embed many input at index %d is empty", i)
+		}
+		inputs = append(inputs, trimmed)
+	}
+
+	vectors, usage, err := a.embed(ctx, inputs, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.EmbedManyResult{Embeddings: vectors, Usage: usage}, nil
+}
+
+func (a *Adapter) embed(ctx context.Context, inputs []string, dimensions *int64, options map[string]any) ([][]float64, *core.Usage, error) {
+	if dimensions != nil && *dimensions <= 0 {
+		return nil, nil, errors.New("cohere: embed dimensions must be greater than zero")
+	}
+
+	request := embedRequest{
+		Model:           a.Model,
+		Texts:           inputs,
+		InputType:       defaultEmbedInputType,
+		EmbeddingTypes:  []string{"float"},
+		OutputDimension: dimensions,
+	}
+	if value, ok := options["input_type"].(string); ok && strings.TrimSpace(value) != "" {
+		request.InputType = strings.TrimSpace(value)
+	}
+	if value, ok := options["truncate"].(string); ok {
+		request.Truncate = strings.ToUpper(strings.TrimSpace(value))
+	}
+
+	var response embedResponse
+	if _, err := a.transport().Send(ctx, httpclient.Request{Path: "/embed", Name: "embed", Body: &request}, &response); err != nil {
+		return nil, nil, err
+	}
+
+	vectors := response.Embeddings.Float
+	if len(vectors) != len(inputs) {
+		return nil, nil, fmt.Errorf("cohere: embeddings response count mismatch: expected %d, got %d", len(inputs), len(vectors))
+	}
+	if dimensions != nil {
+		for i, vector := range vectors {
+			if int64(len(vector)) != *dimensions {
+				return nil, nil, &core.EmbeddingDimensionError{Expected: *dimensions, Actual: int64(len(vector)), Index: i}
+			}
+		}
+	}
+
+	var usage *core.Usage
+	if response.Meta != nil && response.Meta.BilledUnits != nil {
+		tokens := int64(response.Meta.BilledUnits.InputTokens)
+		usage = &core.Usage{PromptTokens: tokens, TotalTokens: tokens}
+	}
+	return vectors, usage, nil
+}
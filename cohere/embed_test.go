@@ -0,0 +1,46 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestEmbedManySendsInputType(t *testing.T) {
+	t.Parallel()
+
+	var request embedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embed" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"e1","embeddings":{"float":[[0.1,0.2],[0.3,0.4]]},"meta":{"billed_units":{"input_tokens":4}}}`))
+	}))
+	defer server.Close()
+
+	dimensions := int64(2)
+	result, err := New("embed-v4.0", WithAPIKey("test-key"), WithBaseURL(server.URL)).EmbedMany(context.Background(), &core.EmbedManyParams{
+		Inputs:       []string{"a", "b"},
+		Dimensions:   &dimensions,
+		ModelOptions: map[string]any{"input_type": "search_query"},
+	})
+	if err != nil {
+		t.Fatalf("EmbedMany() error = %v", err)
+	}
+
+	if request.InputType != "search_query" || !reflect.DeepEqual(request.EmbeddingTypes, []string{"float"}) || request.OutputDimension == nil || *request.OutputDimension != 2 {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	if !reflect.DeepEqual(result.Embeddings, [][]float64{{0.1, 0.2}, {0.3, 0.4}}) || result.Usage.PromptTokens != 4 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,42 @@
+package cohere
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func init() {
+	core.RegisterProvider(providerName, newFromConfig)
+}
+
+// newFromConfig builds an adapter for core.NewAdapter. It supports the
+// options timeout, citation_mode, and gzip.
+func newFromConfig(config core.ProviderConfig) (core.TextAdapter, error) {
+	opts := []Option{WithAPIKey(config.APIKey), WithBaseURL(config.BaseURL)}
+	for name, value := range config.Options {
+		switch name {
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			opts = append(opts, WithTimeout(timeout))
+		case "citation_mode":
+			opts = append(opts, WithCitationMode(value))
+		case "gzip":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			if enabled {
+				opts = append(opts, WithGzip())
+			}
+		default:
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	return New(config.Model, opts...), nil
+}
@@ -0,0 +1,24 @@
+package cohere
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestFromDSN(t *testing.T) {
+	t.Parallel()
+
+	adapter, err := core.FromDSN("cohere://co-key@command-r-plus?citation_mode=fast&timeout=30s&gzip=1")
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	cohere := adapter.(*Adapter)
+	if cohere.APIKey != "co-key" || cohere.Model != "command-r-plus" || cohere.CitationMode != CitationModeFast || cohere.HTTPClient.Timeout != 30*time.Second || !cohere.Gzip {
+		t.Fatalf("adapter = %+v", cohere)
+	}
+	if _, err := core.FromDSN("cohere://command-r?safety=strict"); err == nil {
+		t.Fatal("FromDSN() expected error for an unknown option")
+	}
+}
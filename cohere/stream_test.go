@@ -0,0 +1,71 @@
+package cohere
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatStreamEmitsReasoningAndContent(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("x-request-id", "req-stream")
+		_, _ = w.Write([]byte(`event: message-start
+data: {"type":"message-start","id":"c1","delta":{"message":{"role":"assistant"}}}
+
+event: content-delta
+data: {"type":"content-delta","index":0,"delta":{"message":{"content":{"thinking":"Think."}}}}
+
+event: content-delta
+data: {"type":"content-delta","index":1,"delta":{"message":{"content":{"text":"Hello"}}}}
+
+event: content-delta
+data: {"type":"content-delta","index":1,"delta":{"message":{"content":{"text":" there"}}}}
+
+event: message-end
+data: {"type":"message-end","delta":{"finish_reason":"COMPLETE","usage":{"tokens":{"input_tokens":5,"output_tokens":2}}}}
+
+`))
+	}))
+	defer server.Close()
+
+	stream, err := New("command-a-reasoning-08-2025", WithAPIKey("test-key"), WithBaseURL(server.URL)).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		Thinking: "enabled",
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var order []string
+	var done core.StreamChunk
+	content := ""
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkError:
+			t.Fatalf("unexpected chunk error: %s", chunk.Error)
+		case core.StreamChunkContent:
+			content = chunk.Content
+		case core.StreamChunkDone:
+			done = chunk
+		}
+		order = append(order, chunk.Type)
+	}
+
+	expected := []string{core.StreamChunkReasoning, core.StreamChunkContent, core.StreamChunkContent, core.StreamChunkDone}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("unexpected chunk order: %#v", order)
+	}
+	if content != "Hello there" || done.Reasoning != "Think." || done.FinishReason != core.FinishReasonStop || done.RequestID != "req-stream" {
+		t.Fatalf("unexpected stream result %q, %+v", content, done)
+	}
+	if done.Usage == nil || done.Usage.TotalTokens != 7 {
+		t.Fatalf("unexpected usage %+v", done.Usage)
+	}
+}
@@ -0,0 +1,183 @@
+package cohere
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type chatRequest struct {
+	Model           string           `json:"model"`
+	Messages        []message        `json:"messages"`
+	Tools           []tool           `json:"tools,omitempty"`
+	ToolChoice      string           `json:"tool_choice,omitempty"`
+	Documents       []document       `json:"documents,omitempty"`
+	CitationOptions *citationOptions `json:"citation_options,omitempty"`
+	ResponseFormat  *responseFormat  `json:"response_format,omitempty"`
+	Thinking        *thinking        `json:"thinking,omitempty"`
+	MaxTokens       *int64           `json:"max_tokens,omitempty"`
+	Temperature     *float64         `json:"temperature,omitempty"`
+	P               *float64         `json:"p,omitempty"`
+	K               *int64           `json:"k,omitempty"`
+	Seed            *int64           `json:"seed,omitempty"`
+	StopSequences   []string         `json:"stop_sequences,omitempty"`
+	Stream          bool             `json:"stream,omitempty"`
+
+	ModelOptions map[string]any `json:"-"`
+}
+
+type message struct {
+	Role       string        `json:"role"`
+	Content    []contentPart `json:"content,omitempty"`
+	ToolPlan   string        `json:"tool_plan,omitempty"`
+	ToolCalls  []toolCall    `json:"tool_calls,omitempty"`
+	ToolCallID string        `json:"tool_call_id,omitempty"`
+}
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	Thinking string    `json:"thinking,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+
+	// Raw replaces the whole part when set, for core.RawPart.
+	Raw json.RawMessage `json:"-"`
+}
+
+type imageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// document is a grounding document. Data holds its fields, such as "title"
+// and "text", which the model cites.
+type document struct {
+	ID   string         `json:"id,omitempty"`
+	Data map[string]any `json:"data"`
+}
+
+type tool struct {
+	Type     string       `json:"type"`
+	Function toolFunction `json:"function"`
+}
+
+type toolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+type toolCall struct {
+	ID       string           `json:"id,omitempty"`
+	Type     string           `json:"type,omitempty"`
+	Function toolCallFunction `json:"function"`
+}
+
+type toolCallFunction struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+type citationOptions struct {
+	Mode string `json:"mode"`
+}
+
+type responseFormat struct {
+	Type       string         `json:"type"`
+	JSONSchema map[string]any `json:"json_schema,omitempty"`
+}
+
+type thinking struct {
+	Type        string `json:"type"`
+	TokenBudget *int64 `json:"token_budget,omitempty"`
+}
+
+type chatResponse struct {
+	ID           string          `json:"id"`
+	FinishReason string          `json:"finish_reason"`
+	Message      responseMessage `json:"message"`
+	Usage        *usage          `json:"usage,omitempty"`
+
+	RequestID string        `json:"-"`
+	Duration  time.Duration `json:"-"`
+}
+
+type responseMessage struct {
+	Role      string        `json:"role"`
+	Content   []contentPart `json:"content,omitempty"`
+	ToolPlan  string        `json:"tool_plan,omitempty"`
+	ToolCalls []toolCall    `json:"tool_calls,omitempty"`
+	Citations []citation    `json:"citations,omitempty"`
+}
+
+// citation marks the span [Start, End) of the response text that is
+// supported by Sources.
+type citation struct {
+	Start   int              `json:"start"`
+	End     int              `json:"end"`
+	Text    string           `json:"text"`
+	Type    string           `json:"type,omitempty"`
+	Sources []citationSource `json:"sources,omitempty"`
+}
+
+type citationSource struct {
+	Type     string         `json:"type"`
+	ID       string         `json:"id"`
+	Document map[string]any `json:"document,omitempty"`
+}
+
+// usage reports billed units and, separately, the tokens the model
+// processed. Counts are floats in the API.
+type usage struct {
+	BilledUnits  *billedUnits `json:"billed_units,omitempty"`
+	Tokens       *tokenCounts `json:"tokens,omitempty"`
+	CachedTokens float64      `json:"cached_tokens,omitempty"`
+}
+
+type billedUnits struct {
+	InputTokens  float64 `json:"input_tokens"`
+	OutputTokens float64 `json:"output_tokens"`
+	SearchUnits  float64 `json:"search_units,omitempty"`
+}
+
+type tokenCounts struct {
+	InputTokens  float64 `json:"input_tokens"`
+	OutputTokens float64 `json:"output_tokens"`
+}
+
+// streamEvent is one server-sent event of a streamed chat. Its type, such as
+// "content-delta" or "message-end", is repeated in the data.
+type streamEvent struct {
+	Type  string       `json:"type"`
+	Index int          `json:"index"`
+	Delta *streamDelta `json:"delta,omitempty"`
+}
+
+type streamDelta struct {
+	Message      *streamMessage `json:"message,omitempty"`
+	FinishReason string         `json:"finish_reason,omitempty"`
+	Usage        *usage         `json:"usage,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+type streamMessage struct {
+	Content *contentPart `json:"content,omitempty"`
+}
+
+type embedRequest struct {
+	Model           string   `json:"model"`
+	Texts           []string `json:"texts"`
+	InputType       string   `json:"input_type"`
+	EmbeddingTypes  []string `json:"embedding_types"`
+	Truncate        string   `json:"truncate,omitempty"`
+	OutputDimension *int64   `json:"output_dimension,omitempty"`
+}
+
+type embedResponse struct {
+	ID         string `json:"id"`
+	Embeddings struct {
+		Float [][]float64 `json:"float"`
+	} `json:"embeddings"`
+	Meta *struct {
+		BilledUnits *billedUnits `json:"billed_units,omitempty"`
+	} `json:"meta,omitempty"`
+}
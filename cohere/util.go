@@ -0,0 +1,286 @@
+package cohere
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+func (p contentPart) MarshalJSON() ([]byte, error) {
+	if len(p.Raw) > 0 {
+		return p.Raw, nil
+	}
+	type plain contentPart
+	return json.Marshal(plain(p))
+}
+
+// marshalWithModelOptions encodes request and sets each model option as a
+// top-level field, such as "safety_mode" or "frequency_penalty".
+func marshalWithModelOptions(request any, options map[string]any) ([]byte, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	if len(options) == 0 {
+		return body, nil
+	}
+
+	var envelope map[string]any
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	for key, value := range options {
+		key = strings.TrimSpace(key)
+		if key == "" || value == nil {
+			continue
+		}
+		envelope[key] = value
+	}
+
+	return json.Marshal(envelope)
+}
+
+func decodeAPIError(resp *http.Response) error {
+	requestID := httpclient.RequestID(resp.Header)
+
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
+	var message string
+	if readErr != nil {
+		message = fmt.Sprintf("cohere: API status %d and failed to read error body: %v", resp.StatusCode, readErr)
+	} else {
+		var envelope struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &envelope); err == nil && strings.TrimSpace(envelope.Message) != "" {
+			message = "cohere: API error: " + strings.TrimSpace(envelope.Message)
+		} else {
+			text := strings.TrimSpace(string(body))
+			if text == "" {
+				text = http.StatusText(resp.StatusCode)
+			}
+			message = fmt.Sprintf("cohere: API status %d: %s", resp.StatusCode, text)
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &core.RateLimitError{Message: message, RetryAfter: retryAfter(resp.Header), RequestID: requestID}
+	}
+	return &core.APIError{
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		RequestID:  requestID,
+		Retryable:  resp.StatusCode >= http.StatusInternalServerError,
+		RetryAfter: retryAfter(resp.Header),
+	}
+}
+
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(header.Get("retry-after")), 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func extractText(content []contentPart) string {
+	var builder strings.Builder
+	for _, part := range content {
+		if part.Type == "text" {
+			builder.WriteString(part.Text)
+		}
+	}
+	return builder.String()
+}
+
+func extractReasoning(content []contentPart) string {
+	parts := make([]string, 0, 1)
+	for _, part := range content {
+		if part.Type == "thinking" && strings.TrimSpace(part.Thinking) != "" {
+			parts = append(parts, strings.TrimSpace(part.Thinking))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// thinkingParts returns the thinking content of a response, which is sent
+// back with the tool calls it preceded.
+func thinkingParts(content []contentPart) []contentPart {
+	var out []contentPart
+	for _, part := range content {
+		if part.Type == "thinking" {
+			out = append(out, contentPart{Type: "thinking", Thinking: part.Thinking})
+		}
+	}
+	return out
+}
+
+// reasoningBlocks keeps the thinking and tool plan of a tool call response on
+// the core conversation, so that it can be replayed.
+func reasoningBlocks(msg responseMessage) []core.ReasoningBlock {
+	var blocks []core.ReasoningBlock
+	for _, part := range msg.Content {
+		if part.Type == "thinking" {
+			blocks = append(blocks, core.ReasoningBlock{Type: "thinking", Text: part.Thinking})
+		}
+	}
+	if msg.ToolPlan != "" {
+		blocks = append(blocks, core.ReasoningBlock{Type: "tool_plan", Text: msg.ToolPlan})
+	}
+	return blocks
+}
+
+func toCoreToolCalls(calls []toolCall) ([]core.ToolCall, error) {
+	out := make([]core.ToolCall, 0, len(calls))
+	for _, call := range calls {
+		var arguments any = map[string]any{}
+		if strings.TrimSpace(call.Function.Arguments) != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &arguments); err != nil {
+				return nil, fmt.Errorf("cohere: invalid arguments for tool %q: %w", call.Function.Name, err)
+			}
+		}
+		out = append(out, core.ToolCall{ID: call.ID, Name: call.Function.Name, Arguments: arguments})
+	}
+	return out, nil
+}
+
+// toCoreCitations converts the citations of the response text, one per cited
+// source. DocumentIndex is the position of the cited document in documents.
+// Citations of the tool plan are skipped.
+func toCoreCitations(citations []citation, documents []document) []core.Citation {
+	var out []core.Citation
+	for _, item := range citations {
+		if item.Type != "" && item.Type != "TEXT_CONTENT" {
+			continue
+		}
+
+		base := core.Citation{
+			Type:       "document",
+			StartIndex: item.Start,
+			EndIndex:   item.End,
+			CitedText:  item.Text,
+		}
+		if len(item.Sources) == 0 {
+			out = append(out, base)
+			continue
+		}
+
+		for _, source := range item.Sources {
+			entry := base
+			entry.Type = nonEmpty(source.Type, "document")
+			entry.URL, _ = source.Document["url"].(string)
+			entry.Title, _ = source.Document["title"].(string)
+			for i, doc := range documents {
+				if doc.ID == source.ID {
+					entry.DocumentIndex = i
+					if entry.URL == "" {
+						entry.URL, _ = doc.Data["url"].(string)
+					}
+					if entry.Title == "" {
+						entry.Title, _ = doc.Data["title"].(string)
+					}
+					break
+				}
+			}
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// toCoreUsage reports the tokens the model processed, or the billed units
+// when the response has no token counts.
+func toCoreUsage(in *usage) *core.Usage {
+	if in == nil {
+		return nil
+	}
+
+	out := &core.Usage{}
+	switch {
+	case in.Tokens != nil:
+		out.PromptTokens = int64(in.Tokens.InputTokens)
+		out.CompletionTokens = int64(in.Tokens.OutputTokens)
+	case in.BilledUnits != nil:
+		out.PromptTokens = int64(in.BilledUnits.InputTokens)
+		out.CompletionTokens = int64(in.BilledUnits.OutputTokens)
+	}
+	out.TotalTokens = out.PromptTokens + out.CompletionTokens
+
+	addDetail := func(key string, value float64) {
+		if value <= 0 {
+			return
+		}
+		if out.Details == nil {
+			out.Details = make(map[string]int64)
+		}
+		out.Details[key] = int64(value)
+	}
+	if in.BilledUnits != nil {
+		addDetail("billed_input_tokens", in.BilledUnits.InputTokens)
+		addDetail("billed_output_tokens", in.BilledUnits.OutputTokens)
+		addDetail("search_units", in.BilledUnits.SearchUnits)
+	}
+	addDetail("cached_tokens", in.CachedTokens)
+
+	return out
+}
+
+func responseUsage(response *chatResponse) *core.Usage {
+	out := toCoreUsage(response.Usage)
+	if out == nil {
+		out = &core.Usage{}
+	}
+	out.Duration = response.Duration
+	out.RequestID = response.RequestID
+	return out
+}
+
+func appendReasoningPart(parts []string, reasoning string) []string {
+	reasoning = strings.TrimSpace(reasoning)
+	if reasoning == "" {
+		return parts
+	}
+	if len(parts) > 0 && parts[len(parts)-1] == reasoning {
+		return parts
+	}
+	return append(parts, reasoning)
+}
+
+func joinReasoningParts(parts []string) string {
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// toCoreFinishReason maps a Cohere finish_reason onto core.FinishReason.
+func toCoreFinishReason(finishReason string) core.FinishReason {
+	switch strings.TrimSpace(finishReason) {
+	case "", "COMPLETE":
+		return core.FinishReasonStop
+	case "STOP_SEQUENCE":
+		return core.FinishReasonStopSequence
+	case "MAX_TOKENS":
+		return core.FinishReasonLength
+	case "TOOL_CALL":
+		return core.FinishReasonToolCalls
+	case "ERROR", "TIMEOUT":
+		return core.FinishReasonError
+	default:
+		return core.FinishReason(strings.ToLower(finishReason))
+	}
+}
+
+func nonEmpty(value, fallback string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -35,3 +35,43 @@ type ImageAdapter interface {
 type TranscriptionAdapter interface {
 	Transcribe(ctx context.Context, params *TranscriptionParams) (*TranscriptionResult, error)
 }
+
+// ModerationAdapter defines content moderation capabilities for a model provider adapter.
+//
+// Preferred usage is to use core and add a provider adapter there. This
+// interface stays available for direct adapter calls when needed.
+type ModerationAdapter interface {
+	Moderate(ctx context.Context, params *ModerationParams) (*ModerationResult, error)
+}
+
+// ModelAdapter defines model discovery for a model provider adapter.
+//
+// Preferred usage is to use core and add a provider adapter there. This
+// interface stays available for direct adapter calls when needed.
+type ModelAdapter interface {
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+}
+
+// HealthAdapter defines a connectivity check for a model provider adapter.
+//
+// Preferred usage is to use core and add a provider adapter there. This
+// interface stays available for direct adapter calls when needed.
+type HealthAdapter interface {
+	Ping(ctx context.Context) error
+}
+
+// TokenCounterAdapter defines prompt token counting for a model provider adapter.
+//
+// Preferred usage is to use core and add a provider adapter there. This
+// interface stays available for direct adapter calls when needed.
+type TokenCounterAdapter interface {
+	CountTokens(ctx context.Context, params *ChatParams) (int64, error)
+}
+
+// CapabilityAdapter reports the chat features an adapter supports.
+//
+// Use CheckCapabilities to validate ChatParams against them before sending a
+// request.
+type CapabilityAdapter interface {
+	Capabilities() Capabilities
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capability names reported by CapabilityError.
+const (
+	CapabilityTools            = "tools"
+	CapabilityVision           = "vision"
+	CapabilityAudioInput       = "audio input"
+	CapabilityDocuments        = "documents"
+	CapabilityStructuredOutput = "structured output"
+	CapabilityReasoning        = "reasoning"
+)
+
+// Capabilities describes the chat features an adapter supports. They describe
+// the adapter and its provider API; an individual model may support less, for
+// example an Ollama model without vision.
+type Capabilities struct {
+	Tools            bool
+	Vision           bool
+	AudioInput       bool
+	Documents        bool
+	StructuredOutput bool
+	// StreamingWithTools reports whether ChatStream streams turns that use
+	// tools or structured output. Adapters without it still accept such
+	// requests but replay a non-streaming Chat call as chunks.
+	StreamingWithTools bool
+	Reasoning          bool
+}
+
+// CapabilityError is returned by ValidateChatParams when params use a feature
+// the adapter does not support.
+type CapabilityError struct {
+	Capability string
+	Message    string
+}
+
+func (e *CapabilityError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Message
+}
+
+// CheckCapabilities validates params against the adapter's capabilities when
+// the adapter implements CapabilityAdapter. Adapters that do not report
+// capabilities are not checked.
+func CheckCapabilities(adapter TextAdapter, params *ChatParams) error {
+	reporter, ok := adapter.(CapabilityAdapter)
+	if !ok {
+		return nil
+	}
+	return ValidateChatParams(reporter.Capabilities(), params)
+}
+
+// ValidateChatParams reports the first feature used by params that capabilities
+// does not include, as a *CapabilityError, so requests fail before they reach
+// the provider.
+func ValidateChatParams(capabilities Capabilities, params *ChatParams) error {
+	if params == nil {
+		return nil
+	}
+
+	if len(params.Tools) > 0 && !capabilities.Tools {
+		return capabilityError(CapabilityTools, "remove Tools or use an adapter with tool support")
+	}
+	if params.Output != nil && !capabilities.StructuredOutput {
+		return capabilityError(CapabilityStructuredOutput, "remove Output and decode the text response instead")
+	}
+	if (strings.TrimSpace(params.Thinking) != "" || strings.TrimSpace(params.ReasoningEffort) != "") && !capabilities.Reasoning {
+		return capabilityError(CapabilityReasoning, "remove Thinking and ReasoningEffort")
+	}
+
+	for i, union := range params.Messages {
+		for j, part := range contentParts(union) {
+			capability := partCapability(part)
+			if capability == "" || hasCapability(capabilities, capability) {
+				continue
+			}
+			return capabilityError(capability, fmt.Sprintf("remove content part %d of message %d", j, i))
+		}
+	}
+
+	return nil
+}
+
+func capabilityError(capability, hint string) error {
+	return &CapabilityError{
+		Capability: capability,
+		Message:    fmt.Sprintf("core: adapter does not support %s (%s)", capability, hint),
+	}
+}
+
+func hasCapability(capabilities Capabilities, capability string) bool {
+	switch capability {
+	case CapabilityVision:
+		return capabilities.Vision
+	case CapabilityAudioInput:
+		return capabilities.AudioInput
+	case CapabilityDocuments:
+		return capabilities.Documents
+	default:
+		return true
+	}
+}
+
+// partCapability returns the capability a content part needs, or "" when
+// every adapter supports it.
+func partCapability(part ContentPart) string {
+	switch typed := part.(type) {
+	case ImagePart, *ImagePart:
+		return CapabilityVision
+	case AudioPart, *AudioPart:
+		return CapabilityAudioInput
+	case DocumentPart, *DocumentPart:
+		return CapabilityDocuments
+	case FilePart:
+		return fileCapability(typed.MimeType)
+	case *FilePart:
+		if typed == nil {
+			return ""
+		}
+		return fileCapability(typed.MimeType)
+	}
+	return ""
+}
+
+func fileCapability(mimeType string) string {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(mimeType)), "image/") {
+		return CapabilityVision
+	}
+	return CapabilityDocuments
+}
+
+func contentParts(union MessageUnion) []ContentPart {
+	switch msg := union.(type) {
+	case ContentMessagePart:
+		return msg.Parts
+	case *ContentMessagePart:
+		if msg != nil {
+			return msg.Parts
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type capabilityAdapterStub struct {
+	capabilities Capabilities
+}
+
+func (s capabilityAdapterStub) Chat(context.Context, *ChatParams) (*ChatResult, error) {
+	return &ChatResult{}, nil
+}
+
+func (s capabilityAdapterStub) ChatStream(context.Context, *ChatParams) (<-chan StreamChunk, error) {
+	return nil, nil
+}
+
+func (s capabilityAdapterStub) Capabilities() Capabilities {
+	return s.capabilities
+}
+
+func TestValidateChatParamsReportsUnsupportedFeature(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		params     *ChatParams
+		capability string
+	}{
+		{"tools", &ChatParams{Tools: []ToolUnion{ClientTool{Name: "lookup"}}}, CapabilityTools},
+		{"output", &ChatParams{Output: &Schema{Name: "result"}}, CapabilityStructuredOutput},
+		{"reasoning", &ChatParams{ReasoningEffort: "high"}, CapabilityReasoning},
+		{"image", contentParams(ImagePart{}), CapabilityVision},
+		{"image file", contentParams(&FilePart{FileID: "file-1", MimeType: "image/png"}), CapabilityVision},
+		{"audio", contentParams(&AudioPart{}), CapabilityAudioInput},
+		{"document file", contentParams(FilePart{FileID: "file-1", MimeType: "application/pdf"}), CapabilityDocuments},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateChatParams(Capabilities{}, test.params)
+			var capabilityErr *CapabilityError
+			if !errors.As(err, &capabilityErr) {
+				t.Fatalf("expected CapabilityError, got %v", err)
+			}
+			if capabilityErr.Capability != test.capability {
+				t.Fatalf("expected capability %q, got %q", test.capability, capabilityErr.Capability)
+			}
+		})
+	}
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	t.Parallel()
+
+	params := contentParams(TextPart{Text: "Describe"}, ImagePart{})
+	if err := CheckCapabilities(capabilityAdapterStub{capabilities: Capabilities{Vision: true}}, params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := CheckCapabilities(capabilityAdapterStub{}, params)
+	if err == nil || err.Error() != "core: adapter does not support vision (remove content part 1 of message 0)" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := CheckCapabilities(textAdapterFunc(nil), params); err != nil {
+		t.Fatalf("adapters without capabilities must not be checked: %v", err)
+	}
+}
+
+type textAdapterFunc func(context.Context, *ChatParams) (*ChatResult, error)
+
+func (f textAdapterFunc) Chat(ctx context.Context, params *ChatParams) (*ChatResult, error) {
+	return f(ctx, params)
+}
+
+func (f textAdapterFunc) ChatStream(context.Context, *ChatParams) (<-chan StreamChunk, error) {
+	return nil, nil
+}
+
+func contentParams(parts ...ContentPart) *ChatParams {
+	return &ChatParams{Messages: []MessageUnion{ContentMessagePart{Role: RoleUser, Parts: parts}}}
+}
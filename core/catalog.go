@@ -0,0 +1,154 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ModelSpec describes the published limits and input modalities of a model.
+// Zero limits mean the limit is unknown.
+type ModelSpec struct {
+	// Name is the model name, or the prefix shared by its dated snapshots,
+	// such as "gpt-4o" or "claude-sonnet-4-5".
+	Name     string
+	Provider string
+
+	ContextWindow   int64
+	MaxOutputTokens int64
+
+	Vision     bool
+	AudioInput bool
+	Documents  bool
+
+	// Deprecated marks models the provider has deprecated or retired;
+	// Replacement names the suggested successor, if any.
+	Deprecated  bool
+	Replacement string
+}
+
+var catalog = struct {
+	mu    sync.RWMutex
+	specs map[string]ModelSpec
+}{specs: builtinModelSpecs()}
+
+// LookupModel returns the catalog entry for model. Dated snapshots and other
+// suffixed names resolve to the longest registered name they extend, so
+// "gpt-4o-2024-08-06" finds "gpt-4o" and "claude-sonnet-4-5-20250929" finds
+// "claude-sonnet-4-5".
+func LookupModel(model string) (ModelSpec, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return ModelSpec{}, false
+	}
+
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	if spec, ok := catalog.specs[model]; ok {
+		return spec, true
+	}
+
+	var best ModelSpec
+	found := false
+	for name, spec := range catalog.specs {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best.Name) {
+			best, found = spec, true
+		}
+	}
+	return best, found
+}
+
+// RegisterModel adds spec to the catalog, replacing any entry with the same
+// name. Use it to describe fine-tuned, self-hosted, or newly released models.
+func RegisterModel(spec ModelSpec) {
+	spec.Name = strings.ToLower(strings.TrimSpace(spec.Name))
+	if spec.Name == "" {
+		return
+	}
+
+	catalog.mu.Lock()
+	catalog.specs[spec.Name] = spec
+	catalog.mu.Unlock()
+}
+
+// ClampOutputTokens limits requested to the catalog's MaxOutputTokens for
+// model. Unknown models and models without a known limit keep requested.
+func ClampOutputTokens(model string, requested int64) int64 {
+	spec, ok := LookupModel(model)
+	if !ok || spec.MaxOutputTokens <= 0 || requested <= spec.MaxOutputTokens {
+		return requested
+	}
+	return spec.MaxOutputTokens
+}
+
+// CheckContextWindow returns a *ContextLengthExceededError when promptTokens
+// plus outputTokens exceed the catalog's context window for model. Unknown
+// models and models without a known window are not checked.
+func CheckContextWindow(model string, promptTokens, outputTokens int64) error {
+	spec, ok := LookupModel(model)
+	if !ok || spec.ContextWindow <= 0 || promptTokens+outputTokens <= spec.ContextWindow {
+		return nil
+	}
+	return &ContextLengthExceededError{
+		Model:         model,
+		PromptTokens:  promptTokens,
+		OutputTokens:  outputTokens,
+		ContextWindow: spec.ContextWindow,
+	}
+}
+
+// CatalogModels returns every catalog entry sorted by name.
+func CatalogModels() []ModelSpec {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	out := make([]ModelSpec, 0, len(catalog.specs))
+	for _, spec := range catalog.specs {
+		out = append(out, spec)
+	}
+	slices.SortFunc(out, func(a, b ModelSpec) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return out
+}
+
+func builtinModelSpecs() map[string]ModelSpec {
+	specs := []ModelSpec{
+		{Name: "gpt-5", Provider: "openai", ContextWindow: 400000, MaxOutputTokens: 128000, Vision: true, Documents: true},
+		{Name: "gpt-5-mini", Provider: "openai", ContextWindow: 400000, MaxOutputTokens: 128000, Vision: true, Documents: true},
+		{Name: "gpt-5-nano", Provider: "openai", ContextWindow: 400000, MaxOutputTokens: 128000, Vision: true, Documents: true},
+		{Name: "gpt-4.1", Provider: "openai", ContextWindow: 1047576, MaxOutputTokens: 32768, Vision: true, Documents: true},
+		{Name: "gpt-4.1-mini", Provider: "openai", ContextWindow: 1047576, MaxOutputTokens: 32768, Vision: true, Documents: true},
+		{Name: "gpt-4.1-nano", Provider: "openai", ContextWindow: 1047576, MaxOutputTokens: 32768, Vision: true, Documents: true},
+		{Name: "gpt-4o", Provider: "openai", ContextWindow: 128000, MaxOutputTokens: 16384, Vision: true, Documents: true},
+		{Name: "gpt-4o-mini", Provider: "openai", ContextWindow: 128000, MaxOutputTokens: 16384, Vision: true, Documents: true},
+		{Name: "gpt-4o-audio-preview", Provider: "openai", ContextWindow: 128000, MaxOutputTokens: 16384, AudioInput: true},
+		{Name: "gpt-4o-mini-audio-preview", Provider: "openai", ContextWindow: 128000, MaxOutputTokens: 16384, AudioInput: true},
+		{Name: "gpt-4-turbo", Provider: "openai", ContextWindow: 128000, MaxOutputTokens: 4096, Vision: true},
+		{Name: "gpt-4.5-preview", Provider: "openai", ContextWindow: 128000, MaxOutputTokens: 16384, Vision: true, Deprecated: true, Replacement: "gpt-4.1"},
+		{Name: "gpt-3.5-turbo", Provider: "openai", ContextWindow: 16385, MaxOutputTokens: 4096},
+		{Name: "o1", Provider: "openai", ContextWindow: 200000, MaxOutputTokens: 100000, Vision: true, Documents: true},
+		{Name: "o3", Provider: "openai", ContextWindow: 200000, MaxOutputTokens: 100000, Vision: true, Documents: true},
+		{Name: "o3-mini", Provider: "openai", ContextWindow: 200000, MaxOutputTokens: 100000},
+		{Name: "o4-mini", Provider: "openai", ContextWindow: 200000, MaxOutputTokens: 100000, Vision: true, Documents: true},
+
+		{Name: "claude-opus-4-1", Provider: "claude", ContextWindow: 200000, MaxOutputTokens: 32000, Vision: true, Documents: true},
+		{Name: "claude-opus-4", Provider: "claude", ContextWindow: 200000, MaxOutputTokens: 32000, Vision: true, Documents: true},
+		{Name: "claude-sonnet-4-5", Provider: "claude", ContextWindow: 200000, MaxOutputTokens: 64000, Vision: true, Documents: true},
+		{Name: "claude-sonnet-4", Provider: "claude", ContextWindow: 200000, MaxOutputTokens: 64000, Vision: true, Documents: true},
+		{Name: "claude-haiku-4-5", Provider: "claude", ContextWindow: 200000, MaxOutputTokens: 64000, Vision: true, Documents: true},
+		// Claude 3.7 Sonnet reaches 128k output tokens with the extended output beta.
+		{Name: "claude-3-7-sonnet", Provider: "claude", ContextWindow: 200000, MaxOutputTokens: 128000, Vision: true, Documents: true, Deprecated: true, Replacement: "claude-sonnet-4-5"},
+		{Name: "claude-3-5-sonnet", Provider: "claude", ContextWindow: 200000, MaxOutputTokens: 8192, Vision: true, Documents: true, Deprecated: true, Replacement: "claude-sonnet-4-5"},
+		{Name: "claude-3-5-haiku", Provider: "claude", ContextWindow: 200000, MaxOutputTokens: 8192, Documents: true},
+		{Name: "claude-3-opus", Provider: "claude", ContextWindow: 200000, MaxOutputTokens: 4096, Vision: true, Documents: true, Deprecated: true, Replacement: "claude-opus-4-1"},
+		{Name: "claude-3-haiku", Provider: "claude", ContextWindow: 200000, MaxOutputTokens: 4096, Vision: true},
+	}
+
+	out := make(map[string]ModelSpec, len(specs))
+	for _, spec := range specs {
+		out[spec.Name] = spec
+	}
+	return out
+}
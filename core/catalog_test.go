@@ -0,0 +1,69 @@
+package core
+
+import "testing"
+
+func TestLookupModelMatchesSnapshots(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"gpt-4o":                     "gpt-4o",
+		"GPT-4o-2024-08-06":          "gpt-4o",
+		"gpt-4o-mini-2024-07-18":     "gpt-4o-mini",
+		"claude-sonnet-4-5-20250929": "claude-sonnet-4-5",
+		"claude-sonnet-4-20250514":   "claude-sonnet-4",
+	}
+	for model, expected := range tests {
+		spec, ok := LookupModel(model)
+		if !ok || spec.Name != expected {
+			t.Fatalf("LookupModel(%q) = %q, %v; want %q", model, spec.Name, ok, expected)
+		}
+	}
+
+	if _, ok := LookupModel("gpt-4omni"); ok {
+		t.Fatal("prefix matches must end at a dash")
+	}
+	if _, ok := LookupModel("unknown-model"); ok {
+		t.Fatal("unexpected match for unknown model")
+	}
+}
+
+func TestRegisterModelExtendsCatalog(t *testing.T) {
+	t.Parallel()
+
+	RegisterModel(ModelSpec{Name: "Acme-Chat", Provider: "ollama", ContextWindow: 32768, MaxOutputTokens: 4096})
+
+	spec, ok := LookupModel("acme-chat-q4")
+	if !ok || spec.ContextWindow != 32768 || spec.MaxOutputTokens != 4096 {
+		t.Fatalf("unexpected spec %#v, %v", spec, ok)
+	}
+
+	found := false
+	for _, model := range CatalogModels() {
+		if model.Name == "acme-chat" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("registered model missing from CatalogModels")
+	}
+}
+
+func TestCheckContextWindow(t *testing.T) {
+	t.Parallel()
+
+	if err := CheckContextWindow("gpt-4o", 100000, 16384); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := CheckContextWindow("unknown-model", 1<<30, 0); err != nil {
+		t.Fatalf("unknown models must not be checked: %v", err)
+	}
+
+	err := CheckContextWindow("gpt-4o", 120000, 16384)
+	exceeded, ok := err.(*ContextLengthExceededError)
+	if !ok || exceeded.ContextWindow != 128000 {
+		t.Fatalf("expected ContextLengthExceededError, got %v", err)
+	}
+	if err.Error() != "prompt of 120000 tokens plus 16384 output tokens exceeds the 128000-token context window of gpt-4o" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+}
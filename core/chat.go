@@ -1,5 +1,10 @@
 package core
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type MessageUnion interface {
 	isMessageUnion()
 }
@@ -17,11 +22,51 @@ const (
 	StreamChunkToolResult = "tool_result"
 	StreamChunkDone       = "done"
 	StreamChunkError      = "error"
+
+	// StreamChunkPartialJSON carries structured output as it streams: Delta
+	// is the raw text delta and Content is the output so far, completed into
+	// valid JSON with CompletePartialJSON.
+	StreamChunkPartialJSON = "partial_json"
+)
+
+// FinishReason is the normalized reason a model stopped generating. Adapters
+// map provider values onto the constants below and pass values without an
+// equivalent through unchanged; the provider's own value is kept as
+// RawFinishReason.
+type FinishReason string
+
+const (
+	// FinishReasonStop means the model finished its response naturally.
+	FinishReasonStop FinishReason = "stop"
+	// FinishReasonLength means the output token limit or context window was
+	// reached.
+	FinishReasonLength FinishReason = "length"
+	// FinishReasonToolCalls means the model is waiting for tool results.
+	FinishReasonToolCalls FinishReason = "tool_calls"
+	// FinishReasonContentFilter means the provider withheld or refused the
+	// response.
+	FinishReasonContentFilter FinishReason = "content_filter"
+	// FinishReasonStopSequence means one of ChatParams.StopSequences was
+	// generated.
+	FinishReasonStopSequence FinishReason = "stop_sequence"
+	// FinishReasonError means generation failed on the provider side.
+	FinishReasonError FinishReason = "error"
 )
 
 type TextMessagePart struct {
 	Role    string
 	Content string
+
+	// Name identifies the participant, for providers that accept message
+	// names. ID, CreatedAt, and Metadata are not sent to providers; they are
+	// kept on the message for persistence and application use.
+	Name      string
+	ID        string
+	CreatedAt time.Time
+	Metadata  map[string]any
+
+	// CacheControl marks this message as the end of a cacheable prompt prefix.
+	CacheControl *CacheControl
 }
 
 func (TextMessagePart) isMessageUnion() {}
@@ -57,6 +102,44 @@ type DocumentPart struct {
 
 func (DocumentPart) isContentPart() {}
 
+// FilePart references a file stored with the provider or carries its bytes.
+//
+// FileID is sent as is when set. Otherwise adapters for providers with a
+// Files API upload Data once and reuse the returned ID whenever the same
+// content is sent again, so a file attached early in a conversation is not
+// re-uploaded on every turn.
+type FilePart struct {
+	FileID   string
+	Data     []byte
+	Filename string
+	MimeType string
+}
+
+func (FilePart) isContentPart() {}
+
+// RawPart is injected verbatim into the content array of requests sent by the
+// adapter named by Provider, such as "openai" or "claude", and skipped by all
+// other adapters. It allows new provider block types to be used before they
+// have a first-class part. Payload must be a JSON object.
+type RawPart struct {
+	Provider string
+	Payload  json.RawMessage
+}
+
+func (RawPart) isContentPart() {}
+
+// SearchResultPart supplies a pre-retrieved passage, such as a RAG search hit,
+// so that citations can point at the caller's own documents. Source identifies
+// the passage (for example a URL or document ID) and Texts holds its content.
+type SearchResultPart struct {
+	Source    string
+	Title     string
+	Texts     []string
+	Citations bool
+}
+
+func (SearchResultPart) isContentPart() {}
+
 type Source interface {
 	isSource()
 }
@@ -78,6 +161,17 @@ func (URLSource) isSource() {}
 type ContentMessagePart struct {
 	Role  string
 	Parts []ContentPart
+
+	// Name identifies the participant, for providers that accept message
+	// names. ID, CreatedAt, and Metadata are not sent to providers; they are
+	// kept on the message for persistence and application use.
+	Name      string
+	ID        string
+	CreatedAt time.Time
+	Metadata  map[string]any
+
+	// CacheControl marks this message as the end of a cacheable prompt prefix.
+	CacheControl *CacheControl
 }
 
 func (ContentMessagePart) isMessageUnion() {}
@@ -85,6 +179,26 @@ func (ContentMessagePart) isMessageUnion() {}
 type ToolCallMessagePart struct {
 	Role      string
 	ToolCalls []ToolCall
+
+	// ReasoningBlocks holds the provider reasoning that preceded the tool calls.
+	// Providers such as Claude require these blocks to be replayed unchanged
+	// when the conversation continues with the tool results.
+	ReasoningBlocks []ReasoningBlock
+
+	// ID, CreatedAt, and Metadata are kept on the message for persistence and
+	// application use; they are not sent to providers.
+	ID        string
+	CreatedAt time.Time
+	Metadata  map[string]any
+}
+
+// ReasoningBlock is an opaque provider reasoning block, such as a Claude
+// thinking or redacted_thinking block. Callers should pass it back as is.
+type ReasoningBlock struct {
+	Type      string
+	Text      string
+	Signature string
+	Data      string
 }
 
 func (ToolCallMessagePart) isMessageUnion() {}
@@ -94,18 +208,50 @@ type AssistantToolCallMessagePart = ToolCallMessagePart
 type ToolResultMessagePart struct {
 	Role       string
 	ToolCallID string
-	Name       string
-	Content    string
+	// Name is the name of the tool that produced the result.
+	Name    string
+	Content string
+
+	// ID, CreatedAt, and Metadata are kept on the message for persistence and
+	// application use; they are not sent to providers.
+	ID        string
+	CreatedAt time.Time
+	Metadata  map[string]any
+
+	// CacheControl marks this message as the end of a cacheable prompt prefix.
+	CacheControl *CacheControl
 }
 
 func (ToolResultMessagePart) isMessageUnion() {}
 
+// CacheControl places a prompt caching breakpoint for providers that support
+// explicit prompt caching. Everything up to and including the marked item is
+// cached. Adapters whose provider caches automatically ignore it.
+type CacheControl struct {
+	// TTL optionally selects the cache lifetime, such as "5m" or "1h".
+	TTL string
+}
+
 type Usage struct {
 	PromptTokens     int64
 	CompletionTokens int64
 	TotalTokens      int64
 	ReasoningTokens  int64
 	Details          map[string]int64
+
+	// ServiceTier is the provider service tier that processed the request,
+	// such as "standard" or "priority", when reported.
+	ServiceTier string
+
+	// Duration is the time from sending the request until the response was
+	// complete. TimeToFirstToken is the time until the first streamed
+	// output and is zero for non-streaming calls. With agentic loops they
+	// describe the last request, like the token counts.
+	Duration         time.Duration
+	TimeToFirstToken time.Duration
+
+	// RequestID is the provider request identifier, when reported.
+	RequestID string
 }
 
 type StreamChunk struct {
@@ -116,9 +262,44 @@ type StreamChunk struct {
 	Reasoning    string
 	ToolCall     *ToolCall
 	ToolCallID   string
-	FinishReason string
-	Usage        *Usage
-	Error        string
+	FinishReason FinishReason
+	// RawFinishReason is the finish reason as reported by the provider.
+	RawFinishReason string
+	Usage           *Usage
+	Error           string
+
+	// Err is the error behind an error chunk when the adapter has one, so
+	// callers can match it with errors.As. Error holds its message.
+	Err error
+
+	// RequestID is the provider request identifier on done and error chunks,
+	// when reported.
+	RequestID string
+}
+
+// Citation references a source the model used while producing its response,
+// such as a URL returned by a provider-side web search.
+type Citation struct {
+	Type       string
+	URL        string
+	Title      string
+	StartIndex int
+	EndIndex   int
+
+	// CitedText is the quoted source passage, and DocumentIndex identifies the
+	// cited document among those sent with the request.
+	CitedText     string
+	DocumentIndex int
+}
+
+// Candidate is one alternative response when several were generated for the
+// same request.
+type Candidate struct {
+	Text            string
+	Reasoning       string
+	ToolCalls       []ToolCall
+	FinishReason    FinishReason
+	RawFinishReason string
 }
 
 type ChatResult struct {
@@ -126,15 +307,74 @@ type ChatResult struct {
 	Reasoning string
 	Messages  []MessageUnion
 	ToolCalls []ToolCall
-
-	FinishReason string
+	Citations []Citation
+
+	// ProviderToolCalls lists tools the provider executed itself, such as MCP
+	// server tools.
+	ProviderToolCalls []ProviderToolCall
+
+	FinishReason FinishReason
+	// RawFinishReason is the finish reason as reported by the provider, such
+	// as Claude's "end_turn".
+	RawFinishReason string
+	// StopSequence is the stop sequence that ended generation, if any.
+	StopSequence string
 	Usage        *Usage
+	RateLimit    *RateLimit
+	// RequestID is the provider request identifier, when reported.
+	RequestID string
+	// ContainerID identifies the code execution container the provider used,
+	// if any. Pass it as ChatParams.ContainerID to reuse the container.
+	ContainerID string
+
+	// Candidates holds every alternative response when the provider returned
+	// more than one for ChatParams.CandidateCount. The other fields describe
+	// the first candidate.
+	Candidates []Candidate
+
+	// Continuations is the number of follow-up requests core.Chat issued to
+	// complete a response cut off at the output token limit.
+	Continuations int32
+}
+
+// AllCandidates returns the alternative responses of r. When the provider
+// returned a single response, it is the only candidate.
+func (r *ChatResult) AllCandidates() []Candidate {
+	if r == nil {
+		return nil
+	}
+	if len(r.Candidates) > 0 {
+		return r.Candidates
+	}
+	return []Candidate{{
+		Text:            r.Text,
+		Reasoning:       r.Reasoning,
+		ToolCalls:       r.ToolCalls,
+		FinishReason:    r.FinishReason,
+		RawFinishReason: r.RawFinishReason,
+	}}
 }
 
 type ChatParams struct {
 	Tools  []ToolUnion
 	Output *Schema
 
+	// MCPServers lists remote MCP servers the provider may call tools on.
+	MCPServers []MCPServer
+
+	// ParallelToolCalls controls whether the model may request several tool
+	// calls in one turn. Nil keeps the provider default; false limits the
+	// model to at most one tool call per turn.
+	ParallelToolCalls *bool
+
+	// ToolChoice controls whether and which tool the model calls. Nil lets
+	// the model decide, like ToolChoiceAuto.
+	ToolChoice *ToolChoice
+
+	// ContainerID reuses a provider-side code execution container from an
+	// earlier ChatResult, so files persist across turns of one conversation.
+	ContainerID string
+
 	SystemPrompts []string
 	Messages      []MessageUnion
 
@@ -144,15 +384,48 @@ type ChatParams struct {
 	ModelOptions map[string]any
 	Metadata     map[string]any
 
+	// CacheSystemPrompt and CacheTools place prompt caching breakpoints after
+	// the system prompt and the tool definitions respectively.
+	CacheSystemPrompt *CacheControl
+	CacheTools        *CacheControl
+
 	MaxTokens       *int64
 	MaxOutputTokens *int64
 	Temperature     *float64
 	TopP            *float64
+
+	// TopK limits sampling to the K most likely tokens. It is ignored by
+	// adapters whose provider has no top-k sampling.
+	TopK *int64
+
+	// Seed requests deterministic sampling for repeated requests with the
+	// same parameters. It is ignored by adapters whose provider has no seed.
+	Seed *int64
+
 	Thinking        string
 	ReasoningEffort string
 
+	// StopSequences lists strings that end generation when the model emits
+	// them. Adapters send it as OpenAI stop, Claude stop_sequences, and Ollama
+	// options.stop; the OpenAI Responses API has no equivalent and ignores it.
+	StopSequences []string
+
+	// LogitBias maps provider token IDs to a bias added to their logits before
+	// sampling. It is ignored by adapters whose provider has no logit bias.
+	LogitBias map[int64]float64
+
+	// CandidateCount requests several alternative responses in one call,
+	// returned as ChatResult.Candidates. It is ignored by adapters whose
+	// provider generates a single response.
+	CandidateCount *int64
+
 	MaxAgenticLoops int32
 	MaxLength       int64
+
+	// MaxContinuations lets core.Chat issue up to this many follow-up
+	// requests when a response stops at the output token limit, stitching
+	// the text into one result. Zero disables auto-continue.
+	MaxContinuations int32
 }
 
 // TextOptions is the minimal text interface: common options live
@@ -163,21 +436,70 @@ type TextOptions struct {
 	Tools  []ToolUnion
 	Output *Schema
 
+	// MCPServers lists remote MCP servers the provider may call tools on.
+	MCPServers []MCPServer
+
+	// ParallelToolCalls controls whether the model may request several tool
+	// calls in one turn. Nil keeps the provider default; false limits the
+	// model to at most one tool call per turn.
+	ParallelToolCalls *bool
+
+	// ToolChoice controls whether and which tool the model calls. Nil lets
+	// the model decide, like ToolChoiceAuto.
+	ToolChoice *ToolChoice
+
+	// ContainerID reuses a provider-side code execution container from an
+	// earlier ChatResult, so files persist across turns of one conversation.
+	ContainerID string
+
 	SystemPrompts []string
 	Messages      []MessageUnion
 
 	ModelOptions map[string]any
 	Metadata     map[string]any
 
+	// CacheSystemPrompt and CacheTools place prompt caching breakpoints after
+	// the system prompt and the tool definitions respectively.
+	CacheSystemPrompt *CacheControl
+	CacheTools        *CacheControl
+
 	MaxTokens       *int64
 	MaxOutputTokens *int64
 	Temperature     *float64
 	TopP            *float64
+
+	// TopK limits sampling to the K most likely tokens. It is ignored by
+	// adapters whose provider has no top-k sampling.
+	TopK *int64
+
+	// Seed requests deterministic sampling for repeated requests with the
+	// same parameters. It is ignored by adapters whose provider has no seed.
+	Seed *int64
+
 	Thinking        string
 	ReasoningEffort string
 
+	// StopSequences lists strings that end generation when the model emits
+	// them. Adapters send it as OpenAI stop, Claude stop_sequences, and Ollama
+	// options.stop; the OpenAI Responses API has no equivalent and ignores it.
+	StopSequences []string
+
+	// LogitBias maps provider token IDs to a bias added to their logits before
+	// sampling. It is ignored by adapters whose provider has no logit bias.
+	LogitBias map[int64]float64
+
+	// CandidateCount requests several alternative responses in one call,
+	// returned as ChatResult.Candidates. It is ignored by adapters whose
+	// provider generates a single response.
+	CandidateCount *int64
+
 	MaxAgenticLoops int32
 	MaxLength       int64
+
+	// MaxContinuations lets core.Chat issue up to this many follow-up
+	// requests when a response stops at the output token limit, stitching
+	// the text into one result. Zero disables auto-continue.
+	MaxContinuations int32
 }
 
 func (o *TextOptions) chatParams() *ChatParams {
@@ -186,19 +508,31 @@ func (o *TextOptions) chatParams() *ChatParams {
 	}
 
 	return &ChatParams{
-		Tools:           o.Tools,
-		Output:          o.Output,
-		SystemPrompts:   o.SystemPrompts,
-		Messages:        o.Messages,
-		ModelOptions:    o.ModelOptions,
-		Metadata:        o.Metadata,
-		MaxTokens:       o.MaxTokens,
-		MaxOutputTokens: o.MaxOutputTokens,
-		Temperature:     o.Temperature,
-		TopP:            o.TopP,
-		Thinking:        o.Thinking,
-		ReasoningEffort: o.ReasoningEffort,
-		MaxAgenticLoops: o.MaxAgenticLoops,
-		MaxLength:       o.MaxLength,
+		Tools:             o.Tools,
+		MCPServers:        o.MCPServers,
+		ParallelToolCalls: o.ParallelToolCalls,
+		ToolChoice:        o.ToolChoice,
+		ContainerID:       o.ContainerID,
+		Output:            o.Output,
+		SystemPrompts:     o.SystemPrompts,
+		Messages:          o.Messages,
+		ModelOptions:      o.ModelOptions,
+		Metadata:          o.Metadata,
+		CacheSystemPrompt: o.CacheSystemPrompt,
+		CacheTools:        o.CacheTools,
+		MaxTokens:         o.MaxTokens,
+		MaxOutputTokens:   o.MaxOutputTokens,
+		Temperature:       o.Temperature,
+		TopP:              o.TopP,
+		TopK:              o.TopK,
+		Seed:              o.Seed,
+		Thinking:          o.Thinking,
+		ReasoningEffort:   o.ReasoningEffort,
+		StopSequences:     o.StopSequences,
+		LogitBias:         o.LogitBias,
+		CandidateCount:    o.CandidateCount,
+		MaxAgenticLoops:   o.MaxAgenticLoops,
+		MaxLength:         o.MaxLength,
+		MaxContinuations:  o.MaxContinuations,
 	}
 }
@@ -106,3 +106,18 @@ func TestChatStreamDelegatesToAdapter(t *testing.T) {
 		t.Fatalf("expected stream channel %#v, got %#v", expected, stream)
 	}
 }
+
+func TestAllCandidatesFallsBackToResult(t *testing.T) {
+	t.Parallel()
+
+	result := &ChatResult{Text: "hi", FinishReason: FinishReasonStop}
+	candidates := result.AllCandidates()
+	if len(candidates) != 1 || candidates[0].Text != "hi" || candidates[0].FinishReason != FinishReasonStop {
+		t.Fatalf("unexpected candidates %#v", candidates)
+	}
+
+	result.Candidates = []Candidate{{Text: "a"}, {Text: "b"}}
+	if len(result.AllCandidates()) != 2 {
+		t.Fatalf("expected provider candidates, got %#v", result.AllCandidates())
+	}
+}
@@ -0,0 +1,241 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extensionMimeTypes covers file extensions whose content cannot be told
+// apart by sniffing, such as Markdown and CSV, which sniff as plain text.
+var extensionMimeTypes = map[string]string{
+	".md":       "text/markdown",
+	".markdown": "text/markdown",
+	".csv":      "text/csv",
+	".txt":      "text/plain",
+	".mp3":      "audio/mpeg",
+	".wav":      "audio/wav",
+	".flac":     "audio/flac",
+	".ogg":      "audio/ogg",
+	".oga":      "audio/ogg",
+	".m4a":      "audio/mp4",
+	".webm":     "audio/webm",
+}
+
+// ImageFromFile reads an image file and returns it as an ImagePart with
+// base64 data and a sniffed MIME type.
+func ImageFromFile(path string) (ImagePart, error) {
+	source, err := sourceFromFile(path, "image/")
+	if err != nil {
+		return ImagePart{}, err
+	}
+	return ImagePart{Source: source}, nil
+}
+
+// ImageFromReader reads r to the end and returns it as an ImagePart.
+func ImageFromReader(r io.Reader) (ImagePart, error) {
+	source, err := sourceFromReader(r, "image/")
+	if err != nil {
+		return ImagePart{}, err
+	}
+	return ImagePart{Source: source}, nil
+}
+
+// ImageFromBytes returns data as an ImagePart. data may also be a data URL.
+func ImageFromBytes(data []byte) (ImagePart, error) {
+	source, err := sourceFromBytes(data, "", "image/")
+	if err != nil {
+		return ImagePart{}, err
+	}
+	return ImagePart{Source: source}, nil
+}
+
+// AudioFromFile reads an audio file and returns it as an AudioPart with
+// base64 data and a sniffed MIME type.
+func AudioFromFile(path string) (AudioPart, error) {
+	source, err := sourceFromFile(path, "audio/")
+	if err != nil {
+		return AudioPart{}, err
+	}
+	return AudioPart{Source: source}, nil
+}
+
+// AudioFromReader reads r to the end and returns it as an AudioPart.
+func AudioFromReader(r io.Reader) (AudioPart, error) {
+	source, err := sourceFromReader(r, "audio/")
+	if err != nil {
+		return AudioPart{}, err
+	}
+	return AudioPart{Source: source}, nil
+}
+
+// AudioFromBytes returns data as an AudioPart. data may also be a data URL.
+func AudioFromBytes(data []byte) (AudioPart, error) {
+	source, err := sourceFromBytes(data, "", "audio/")
+	if err != nil {
+		return AudioPart{}, err
+	}
+	return AudioPart{Source: source}, nil
+}
+
+// DocumentFromFile reads a document such as a PDF and returns it as a
+// DocumentPart. The file name is kept in Metadata["filename"].
+func DocumentFromFile(path string) (DocumentPart, error) {
+	source, err := sourceFromFile(path, "")
+	if err != nil {
+		return DocumentPart{}, err
+	}
+	return DocumentPart{Source: source, Metadata: map[string]any{"filename": filepath.Base(path)}}, nil
+}
+
+// DocumentFromReader reads r to the end and returns it as a DocumentPart.
+func DocumentFromReader(r io.Reader) (DocumentPart, error) {
+	source, err := sourceFromReader(r, "")
+	if err != nil {
+		return DocumentPart{}, err
+	}
+	return DocumentPart{Source: source}, nil
+}
+
+// DocumentFromBytes returns data as a DocumentPart. data may also be a data
+// URL.
+func DocumentFromBytes(data []byte) (DocumentPart, error) {
+	source, err := sourceFromBytes(data, "", "")
+	if err != nil {
+		return DocumentPart{}, err
+	}
+	return DocumentPart{Source: source}, nil
+}
+
+// ParseDataURL splits a base64 data URL such as "data:image/png;base64,..."
+// into a DataSource with raw base64 data, as adapters expect.
+func ParseDataURL(url string) (DataSource, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(url), "data:")
+	if !ok {
+		return DataSource{}, errors.New("core: data URL must start with \"data:\"")
+	}
+	header, data, ok := strings.Cut(rest, ",")
+	if !ok {
+		return DataSource{}, errors.New("core: data URL has no data")
+	}
+	if !strings.HasSuffix(strings.ToLower(header), ";base64") {
+		return DataSource{}, errors.New("core: data URL must be base64 encoded")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return DataSource{}, fmt.Errorf("core: decode data URL: %w", err)
+	}
+
+	mimeType := normalizeMimeType(header[:len(header)-len(";base64")])
+	if mimeType == "" {
+		mimeType = DetectMimeType(decoded)
+	}
+	return DataSource{Data: data, MimeType: mimeType}, nil
+}
+
+// DetectMimeType sniffs the MIME type of data. It extends
+// http.DetectContentType with audio formats the standard library does not
+// recognise, such as FLAC and MP3 without an ID3 tag, and drops parameters
+// like charset.
+func DetectMimeType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return "audio/flac"
+	case len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0 && data[1]&0x06 == 0x02:
+		// MPEG layer III frame sync without a leading ID3 tag.
+		return "audio/mpeg"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp" && (string(data[8:11]) == "M4A" || string(data[8:12]) == "M4B "):
+		return "audio/mp4"
+	}
+	return normalizeMimeType(http.DetectContentType(data))
+}
+
+// normalizeMimeType lowercases mimeType, drops its parameters, and maps the
+// aliases reported by sniffing to the names providers accept.
+func normalizeMimeType(mimeType string) string {
+	mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+	switch mimeType {
+	case "audio/wave", "audio/x-wav":
+		return "audio/wav"
+	case "audio/mp3":
+		return "audio/mpeg"
+	case "application/ogg":
+		return "audio/ogg"
+	}
+	return mimeType
+}
+
+func sourceFromFile(path, kind string) (DataSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return DataSource{}, fmt.Errorf("core: read %s: %w", path, err)
+	}
+	return sourceFromBytes(data, filepath.Ext(path), kind)
+}
+
+func sourceFromReader(r io.Reader, kind string) (DataSource, error) {
+	if r == nil {
+		return DataSource{}, errors.New("core: reader is nil")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return DataSource{}, fmt.Errorf("core: read content: %w", err)
+	}
+	return sourceFromBytes(data, "", kind)
+}
+
+// sourceFromBytes encodes data as a DataSource and checks that its MIME type
+// starts with kind, when set. A file extension refines types that sniffing
+// cannot tell apart.
+func sourceFromBytes(data []byte, extension, kind string) (DataSource, error) {
+	if len(data) == 0 {
+		return DataSource{}, errors.New("core: content is empty")
+	}
+
+	var source DataSource
+	if bytes.HasPrefix(data, []byte("data:")) {
+		parsed, err := ParseDataURL(string(data))
+		if err != nil {
+			return DataSource{}, err
+		}
+		source = parsed
+	} else {
+		mimeType := DetectMimeType(data)
+		if extension != "" && (mimeType == "text/plain" || mimeType == "application/octet-stream" || strings.HasPrefix(mimeType, "video/")) {
+			if byExtension := extensionMimeType(extension); byExtension != "" {
+				mimeType = byExtension
+			}
+		}
+		source = DataSource{Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+	}
+
+	source.MimeType = mediaMimeType(source.MimeType, kind)
+	if kind != "" && !strings.HasPrefix(source.MimeType, kind) {
+		return DataSource{}, fmt.Errorf("core: content type %q is not %s", source.MimeType, strings.TrimSuffix(kind, "/"))
+	}
+	return source, nil
+}
+
+// mediaMimeType maps the video type that WebM and MP4 containers sniff as to
+// the audio type when the content is used as kind "audio/".
+func mediaMimeType(mimeType, kind string) string {
+	if kind == "audio/" && strings.HasPrefix(mimeType, "video/") {
+		return "audio/" + strings.TrimPrefix(mimeType, "video/")
+	}
+	return mimeType
+}
+
+func extensionMimeType(extension string) string {
+	extension = strings.ToLower(extension)
+	if mimeType, ok := extensionMimeTypes[extension]; ok {
+		return mimeType
+	}
+	return normalizeMimeType(mime.TypeByExtension(extension))
+}
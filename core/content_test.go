@@ -0,0 +1,126 @@
+package core
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestImageFromBytesSniffsMimeType(t *testing.T) {
+	t.Parallel()
+
+	part, err := ImageFromBytes(pngHeader)
+	if err != nil {
+		t.Fatalf("image from bytes: %v", err)
+	}
+	source, ok := part.Source.(DataSource)
+	if !ok {
+		t.Fatalf("expected DataSource, got %T", part.Source)
+	}
+	if source.MimeType != "image/png" || source.Data != base64.StdEncoding.EncodeToString(pngHeader) {
+		t.Fatalf("unexpected source %#v", source)
+	}
+}
+
+func TestImageFromBytesAcceptsDataURL(t *testing.T) {
+	t.Parallel()
+
+	encoded := base64.StdEncoding.EncodeToString(pngHeader)
+	part, err := ImageFromBytes([]byte("data:image/png;base64," + encoded))
+	if err != nil {
+		t.Fatalf("image from data URL: %v", err)
+	}
+	if source := part.Source.(DataSource); source.Data != encoded || source.MimeType != "image/png" {
+		t.Fatalf("expected the data URL prefix to be stripped, got %#v", source)
+	}
+}
+
+func TestImageFromReaderRejectsOtherContent(t *testing.T) {
+	t.Parallel()
+
+	_, err := ImageFromReader(strings.NewReader("just some text"))
+	if err == nil || !strings.Contains(err.Error(), `"text/plain" is not image`) {
+		t.Fatalf("expected a content type error, got %v", err)
+	}
+}
+
+func TestAudioFromBytesSniffsFormats(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][]byte{
+		"audio/wav":  []byte("RIFF\x24\x00\x00\x00WAVEfmt "),
+		"audio/mpeg": {0xFF, 0xFB, 0x90, 0x64},
+		"audio/flac": []byte("fLaC\x00\x00\x00\x22"),
+		"audio/ogg":  []byte("OggS\x00\x02\x00\x00"),
+		"audio/webm": {0x1A, 0x45, 0xDF, 0xA3, 0x42, 0x82, 0x84, 'w', 'e', 'b', 'm'},
+	}
+	for expected, data := range cases {
+		part, err := AudioFromBytes(data)
+		if err != nil {
+			t.Fatalf("%s: %v", expected, err)
+		}
+		if got := part.Source.(DataSource).MimeType; got != expected {
+			t.Fatalf("expected %s, got %s", expected, got)
+		}
+	}
+}
+
+func TestDocumentFromFileUsesExtensionForText(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(path, []byte("# Notes\n"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	part, err := DocumentFromFile(path)
+	if err != nil {
+		t.Fatalf("document from file: %v", err)
+	}
+	if source := part.Source.(DataSource); source.MimeType != "text/markdown" {
+		t.Fatalf("expected text/markdown, got %q", source.MimeType)
+	}
+	if part.Metadata["filename"] != "notes.md" {
+		t.Fatalf("expected filename metadata, got %#v", part.Metadata)
+	}
+
+	pdf := filepath.Join(dir, "report.bin")
+	if err := os.WriteFile(pdf, []byte("%PDF-1.7\n"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if part, err := DocumentFromFile(pdf); err != nil || part.Source.(DataSource).MimeType != "application/pdf" {
+		t.Fatalf("expected sniffed application/pdf, got %#v, %v", part, err)
+	}
+}
+
+func TestParseDataURL(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ParseDataURL("data:text/plain,hello"); err == nil {
+		t.Fatal("expected an error for a non-base64 data URL")
+	}
+	if _, err := ParseDataURL("data:image/png;base64,%%%"); err == nil {
+		t.Fatal("expected an error for invalid base64")
+	}
+
+	source, err := ParseDataURL("data:;base64," + base64.StdEncoding.EncodeToString(pngHeader))
+	if err != nil {
+		t.Fatalf("parse data URL: %v", err)
+	}
+	if source.MimeType != "image/png" {
+		t.Fatalf("expected the MIME type to be sniffed, got %q", source.MimeType)
+	}
+}
+
+func TestAudioFromBytesRejectsEmpty(t *testing.T) {
+	t.Parallel()
+
+	if _, err := AudioFromBytes(nil); err == nil {
+		t.Fatal("expected an error for empty content")
+	}
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	continuePrompt = "Your previous response was cut off. Continue exactly where it stopped, " +
+		"without repeating any text or adding commentary."
+	continueJSONPrompt = "Your previous response was cut off in the middle of the JSON value. " +
+		"Continue the JSON exactly where it stopped. Output only the remaining characters, " +
+		"without code fences, repetition, or commentary."
+)
+
+// continueTruncated issues follow-up requests while result stopped at the
+// output token limit, up to params.MaxContinuations, and returns one result
+// with the stitched text and the combined usage.
+//
+// Each follow-up replays the conversation with the text so far as the
+// assistant turn and asks the model to continue it. Structured output is not
+// requested again, since a schema would force a fresh value; instead the
+// pieces are concatenated verbatim, code fences around them are removed, and
+// continuing stops once the text is valid JSON. Results with tool calls are
+// returned as they are.
+func continueTruncated(ctx context.Context, adapter TextAdapter, params *ChatParams, result *ChatResult) (*ChatResult, error) {
+	if params == nil || params.MaxContinuations <= 0 || result == nil {
+		return result, nil
+	}
+
+	structured := params.Output != nil
+	text := result.Text
+	messages := result.Messages
+	last := result
+	usage := result.Usage
+	var count int32
+
+	for count < params.MaxContinuations && continuable(last) {
+		if structured && completeJSON(text) {
+			break
+		}
+
+		prompt := continuePrompt
+		if structured {
+			prompt = continueJSONPrompt
+		}
+
+		next := *params
+		next.Output = nil
+		next.MaxContinuations = 0
+		next.Messages = append(withAssistantText(messages, text), TextMessagePart{Role: RoleUser, Content: prompt})
+
+		continued, err := adapter.Chat(ctx, &next)
+		if err != nil {
+			return nil, err
+		}
+		if continued == nil {
+			break
+		}
+
+		count++
+		piece := continued.Text
+		if structured {
+			piece = stripCodeFence(piece)
+		}
+		text += piece
+		usage = usage.Merge(continued.Usage)
+		last = continued
+	}
+
+	if count == 0 {
+		return result, nil
+	}
+
+	out := *result
+	out.Text = text
+	out.Messages = withAssistantText(messages, text)
+	out.FinishReason = last.FinishReason
+	out.RawFinishReason = last.RawFinishReason
+	out.StopSequence = last.StopSequence
+	out.Usage = usage
+	out.RateLimit = last.RateLimit
+	out.RequestID = last.RequestID
+	out.Candidates = nil
+	out.Continuations = count
+	return &out, nil
+}
+
+func continuable(result *ChatResult) bool {
+	return result.FinishReason == FinishReasonLength && len(result.ToolCalls) == 0 && strings.TrimSpace(result.Text) != ""
+}
+
+// completeJSON reports whether text, or the code fence it opens with, holds a
+// complete JSON value.
+func completeJSON(text string) bool {
+	text = strings.TrimSpace(text)
+	if fenced, ok := fencedBlock(text); ok && strings.HasPrefix(text, "```") {
+		text = fenced
+	}
+	return json.Valid([]byte(text))
+}
+
+// withAssistantText returns a copy of messages whose final assistant text
+// message holds text, appending one when the conversation does not end with
+// assistant text.
+func withAssistantText(messages []MessageUnion, text string) []MessageUnion {
+	out := make([]MessageUnion, len(messages), len(messages)+2)
+	copy(out, messages)
+
+	if n := len(out); n > 0 {
+		switch message := out[n-1].(type) {
+		case TextMessagePart:
+			if message.Role == RoleAssistant {
+				message.Content = text
+				out[n-1] = message
+				return out
+			}
+		case *TextMessagePart:
+			if message != nil && message.Role == RoleAssistant {
+				replaced := *message
+				replaced.Content = text
+				out[n-1] = replaced
+				return out
+			}
+		}
+	}
+	return append(out, TextMessagePart{Role: RoleAssistant, Content: text})
+}
+
+// stripCodeFence removes a Markdown code fence wrapped around a continuation
+// piece. Other whitespace is kept, since the piece may resume inside a JSON
+// string.
+func stripCodeFence(piece string) string {
+	if trimmed := strings.TrimLeft(piece, " \t\r\n"); strings.HasPrefix(trimmed, "```") {
+		piece = ""
+		if newline := strings.IndexByte(trimmed, '\n'); newline >= 0 {
+			piece = trimmed[newline+1:]
+		}
+	}
+	if trimmed := strings.TrimRight(piece, " \t\r\n"); strings.HasSuffix(trimmed, "```") {
+		piece = strings.TrimRight(strings.TrimSuffix(trimmed, "```"), " \t\r\n")
+	}
+	return piece
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestChatContinuesLengthTruncatedText(t *testing.T) {
+	t.Parallel()
+
+	replies := []*ChatResult{
+		{Text: "The quick brown", FinishReason: FinishReasonLength, Usage: &Usage{PromptTokens: 10, CompletionTokens: 3, TotalTokens: 13}},
+		{Text: " fox jumps", FinishReason: FinishReasonLength, Usage: &Usage{PromptTokens: 15, CompletionTokens: 2, TotalTokens: 17}},
+		{Text: " over the dog.", FinishReason: FinishReasonStop, Usage: &Usage{PromptTokens: 18, CompletionTokens: 4, TotalTokens: 22}},
+	}
+	var calls []*ChatParams
+	adapter := textAdapterStub{
+		chatFn: func(_ context.Context, params *ChatParams) (*ChatResult, error) {
+			calls = append(calls, params)
+			reply := *replies[len(calls)-1]
+			reply.Messages = append(append([]MessageUnion{}, params.Messages...), TextMessagePart{Role: RoleAssistant, Content: reply.Text})
+			return &reply, nil
+		},
+	}
+
+	result, err := Chat(context.Background(), adapter, &ChatParams{
+		Messages:         []MessageUnion{TextMessagePart{Role: RoleUser, Content: "Write a sentence."}},
+		MaxContinuations: 3,
+	})
+	if err != nil {
+		t.Fatalf("chat: %v", err)
+	}
+
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(calls))
+	}
+	second := calls[1].Messages
+	if len(second) != 3 {
+		t.Fatalf("expected continuation conversation of 3 messages, got %#v", second)
+	}
+	if assistant, ok := second[1].(TextMessagePart); !ok || assistant.Role != RoleAssistant || assistant.Content != "The quick brown" {
+		t.Fatalf("unexpected assistant turn: %#v", second[1])
+	}
+	if prompt, ok := second[2].(TextMessagePart); !ok || prompt.Role != RoleUser || prompt.Content != continuePrompt {
+		t.Fatalf("unexpected continue prompt: %#v", second[2])
+	}
+	if assistant := calls[2].Messages[1].(TextMessagePart); assistant.Content != "The quick brown fox jumps" {
+		t.Fatalf("expected stitched assistant turn, got %q", assistant.Content)
+	}
+
+	if result.Text != "The quick brown fox jumps over the dog." {
+		t.Fatalf("unexpected text %q", result.Text)
+	}
+	if result.FinishReason != FinishReasonStop || result.Continuations != 2 {
+		t.Fatalf("unexpected finish %q after %d continuations", result.FinishReason, result.Continuations)
+	}
+	if result.Usage == nil || result.Usage.PromptTokens != 43 || result.Usage.CompletionTokens != 9 || result.Usage.TotalTokens != 52 {
+		t.Fatalf("unexpected usage %#v", result.Usage)
+	}
+	if len(result.Messages) != 2 {
+		t.Fatalf("expected user and assistant messages, got %#v", result.Messages)
+	}
+	if assistant := result.Messages[1].(TextMessagePart); assistant.Content != result.Text {
+		t.Fatalf("expected stitched assistant message, got %q", assistant.Content)
+	}
+}
+
+func TestChatContinuationStopsAtLimit(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	adapter := textAdapterStub{
+		chatFn: func(context.Context, *ChatParams) (*ChatResult, error) {
+			calls++
+			return &ChatResult{Text: "a", FinishReason: FinishReasonLength}, nil
+		},
+	}
+
+	result, err := Chat(context.Background(), adapter, &ChatParams{MaxContinuations: 2})
+	if err != nil {
+		t.Fatalf("chat: %v", err)
+	}
+	if calls != 3 || result.Text != "aaa" || result.FinishReason != FinishReasonLength {
+		t.Fatalf("unexpected result after %d calls: %#v", calls, result)
+	}
+}
+
+func TestChatWithoutMaxContinuationsReturnsTruncatedResult(t *testing.T) {
+	t.Parallel()
+
+	expected := &ChatResult{Text: "cut", FinishReason: FinishReasonLength}
+	calls := 0
+	adapter := textAdapterStub{
+		chatFn: func(context.Context, *ChatParams) (*ChatResult, error) {
+			calls++
+			return expected, nil
+		},
+	}
+
+	result, err := Chat(context.Background(), adapter, &ChatParams{})
+	if err != nil {
+		t.Fatalf("chat: %v", err)
+	}
+	if calls != 1 || result != expected {
+		t.Fatalf("expected the adapter result unchanged, got %#v after %d calls", result, calls)
+	}
+}
+
+func TestChatContinuesStructuredOutput(t *testing.T) {
+	t.Parallel()
+
+	replies := []string{
+		"```json\n{\"title\": \"Go", // cut inside a string
+		"```json\n pher\", \"tags\": [\"a\"",
+		"```\n, \"b\"]}\n```",
+	}
+	var calls []*ChatParams
+	adapter := textAdapterStub{
+		chatFn: func(_ context.Context, params *ChatParams) (*ChatResult, error) {
+			calls = append(calls, params)
+			return &ChatResult{Text: replies[len(calls)-1], FinishReason: FinishReasonLength}, nil
+		},
+	}
+
+	result, err := Chat(context.Background(), adapter, &ChatParams{
+		Output:           &Schema{Name: "doc"},
+		MaxContinuations: 5,
+	})
+	if err != nil {
+		t.Fatalf("chat: %v", err)
+	}
+
+	if len(calls) != 3 {
+		t.Fatalf("expected continuing to stop once the JSON is complete, got %d requests", len(calls))
+	}
+	if calls[0].Output == nil || calls[1].Output != nil {
+		t.Fatal("expected structured output only on the first request")
+	}
+	if prompt := calls[1].Messages[len(calls[1].Messages)-1].(TextMessagePart); prompt.Content != continueJSONPrompt {
+		t.Fatalf("unexpected continue prompt %q", prompt.Content)
+	}
+
+	var decoded struct {
+		Title string   `json:"title"`
+		Tags  []string `json:"tags"`
+	}
+	if err := DecodeLastInto(result, &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded.Title != "Go pher" || len(decoded.Tags) != 2 {
+		t.Fatalf("unexpected decoded value %#v", decoded)
+	}
+}
+
+func TestChatDoesNotContinueToolCalls(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	adapter := textAdapterStub{
+		chatFn: func(context.Context, *ChatParams) (*ChatResult, error) {
+			calls++
+			return &ChatResult{Text: "calling", FinishReason: FinishReasonLength, ToolCalls: []ToolCall{{ID: "call_1", Name: "lookup"}}}, nil
+		},
+	}
+
+	if _, err := Chat(context.Background(), adapter, &ChatParams{MaxContinuations: 2}); err != nil {
+		t.Fatalf("chat: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single request, got %d", calls)
+	}
+}
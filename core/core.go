@@ -8,6 +8,9 @@ import (
 
 // Chat sends a non-streaming chat request through the provided adapter.
 //
+// With ChatParams.MaxContinuations set, responses cut off at the output token
+// limit are continued with follow-up requests and returned as one result.
+//
 // Preferred usage is to use core and add a provider adapter there; this
 // helper exists for direct adapter calls.
 func Chat(ctx context.Context, request any, params ...*ChatParams) (*ChatResult, error) {
@@ -15,7 +18,11 @@ func Chat(ctx context.Context, request any, params ...*ChatParams) (*ChatResult,
 	if err != nil {
 		return nil, err
 	}
-	return adapter.Chat(ctx, chatParams)
+	result, err := adapter.Chat(ctx, chatParams)
+	if err != nil {
+		return result, err
+	}
+	return continueTruncated(ctx, adapter, chatParams, result)
 }
 
 // ChatStream sends a streaming chat request through the provided adapter.
@@ -100,3 +107,36 @@ func GenerateImage(ctx context.Context, adapter ImageAdapter, params *ImageParam
 func Transcribe(ctx context.Context, adapter TranscriptionAdapter, params *TranscriptionParams) (*TranscriptionResult, error) {
 	return adapter.Transcribe(ctx, params)
 }
+
+// Moderate classifies texts as harmful or not through the provided adapter.
+//
+// Preferred usage is to use core and add a provider adapter there; this
+// helper exists for direct adapter calls.
+func Moderate(ctx context.Context, adapter ModerationAdapter, params *ModerationParams) (*ModerationResult, error) {
+	return adapter.Moderate(ctx, params)
+}
+
+// ListModels returns the models available through the provided adapter.
+//
+// Preferred usage is to use core and add a provider adapter there; this
+// helper exists for direct adapter calls.
+func ListModels(ctx context.Context, adapter ModelAdapter) ([]ModelInfo, error) {
+	return adapter.ListModels(ctx)
+}
+
+// Ping checks that the provider behind the adapter is reachable.
+//
+// Preferred usage is to use core and add a provider adapter there; this
+// helper exists for direct adapter calls.
+func Ping(ctx context.Context, adapter HealthAdapter) error {
+	return adapter.Ping(ctx)
+}
+
+// CountTokens returns the number of prompt tokens params would use with the
+// provided adapter.
+//
+// Preferred usage is to use core and add a provider adapter there; this
+// helper exists for direct adapter calls.
+func CountTokens(ctx context.Context, adapter TokenCounterAdapter, params *ChatParams) (int64, error) {
+	return adapter.CountTokens(ctx, params)
+}
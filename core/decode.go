@@ -37,7 +37,8 @@ func LastAssistantText(result *ChatResult) (string, error) {
 
 // DecodeLast decodes the final assistant text in result into T.
 //
-// The assistant text must be valid JSON for the target type.
+// Text that is not valid JSON on its own is passed through ExtractJSON, so
+// code fences, surrounding prose, and truncated output are tolerated.
 func DecodeLast[T any](result *ChatResult) (T, error) {
 	var out T
 
@@ -46,8 +47,8 @@ func DecodeLast[T any](result *ChatResult) (T, error) {
 		return out, err
 	}
 
-	if err := json.Unmarshal([]byte(text), &out); err != nil {
-		return out, fmt.Errorf("decode last assistant message: %w", err)
+	if err := unmarshalAssistantJSON(text, &out); err != nil {
+		return out, err
 	}
 
 	return out, nil
@@ -55,7 +56,7 @@ func DecodeLast[T any](result *ChatResult) (T, error) {
 
 // DecodeLastInto decodes the final assistant text in result into out.
 //
-// The assistant text must be valid JSON for the target value.
+// Like DecodeLast, it extracts the JSON with ExtractJSON when needed.
 func DecodeLastInto(result *ChatResult, out any) error {
 	if out == nil {
 		return errors.New("decode target is nil")
@@ -66,9 +67,117 @@ func DecodeLastInto(result *ChatResult, out any) error {
 		return err
 	}
 
+	return unmarshalAssistantJSON(text, out)
+}
+
+func unmarshalAssistantJSON(text string, out any) error {
+	if !json.Valid([]byte(text)) {
+		extracted, err := ExtractJSON(text)
+		if err != nil {
+			return fmt.Errorf("decode last assistant message: %w", err)
+		}
+		text = extracted
+	}
+
 	if err := json.Unmarshal([]byte(text), out); err != nil {
 		return fmt.Errorf("decode last assistant message: %w", err)
 	}
-
 	return nil
 }
+
+// ExtractJSON returns the JSON value embedded in model output. It handles
+// Markdown code fences, prose before or after the value, and output that was
+// cut off mid-value, which is completed with CompletePartialJSON.
+func ExtractJSON(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text != "" && json.Valid([]byte(text)) {
+		return text, nil
+	}
+
+	if fenced, ok := fencedBlock(text); ok {
+		text = fenced
+		if json.Valid([]byte(text)) {
+			return text, nil
+		}
+	}
+
+	unclosed := -1
+	start := strings.IndexAny(text, "{[")
+	for start >= 0 {
+		if end, ok := matchingBracket(text, start); ok {
+			if candidate := text[start : end+1]; json.Valid([]byte(candidate)) {
+				return candidate, nil
+			}
+		} else if unclosed < 0 {
+			unclosed = start
+		}
+
+		next := strings.IndexAny(text[start+1:], "{[")
+		if next < 0 {
+			break
+		}
+		start += next + 1
+	}
+
+	// No complete value, so recover the first one that was cut off.
+	if unclosed >= 0 {
+		if completed := CompletePartialJSON(text[unclosed:]); completed != "" {
+			return completed, nil
+		}
+	}
+
+	return "", errors.New("no JSON value found in text")
+}
+
+// fencedBlock returns the contents of the first Markdown code fence in text.
+// An unclosed fence runs to the end of text.
+func fencedBlock(text string) (string, bool) {
+	open := strings.Index(text, "```")
+	if open < 0 {
+		return "", false
+	}
+	body := text[open+3:]
+	if newline := strings.IndexByte(body, '\n'); newline >= 0 && !strings.ContainsAny(body[:newline], "{[") {
+		body = body[newline+1:]
+	}
+	if end := strings.Index(body, "```"); end >= 0 {
+		body = body[:end]
+	}
+	return strings.TrimSpace(body), true
+}
+
+// matchingBracket returns the index of the bracket closing the object or
+// array that starts at text[start], skipping brackets inside strings.
+func matchingBracket(text string, start int) (int, bool) {
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestExtractJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		`{"a":1}`:                  `{"a":1}`,
+		"```json\n{\"a\": 1}\n```": `{"a": 1}`,
+		"```\n[1, 2]\n```\nLet me know if you need more.":  `[1, 2]`,
+		"Here is the result [as requested]:\n{\"a\": [1]}": `{"a": [1]}`,
+		"Sure! {\"a\": \"}\"} Hope this helps.":            `{"a": "}"}`,
+		"The answer is {\"items\": [\"x\", \"y":            `{"items": ["x", "y"]}`,
+		"```json\n{\"done\": true":                         `{"done": true}`,
+	}
+	for input, want := range tests {
+		got, err := ExtractJSON(input)
+		if err != nil {
+			t.Fatalf("ExtractJSON(%q) returned error: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("ExtractJSON(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	if _, err := ExtractJSON("no json here"); err == nil {
+		t.Fatal("expected error for text without JSON")
+	}
+}
+
+func TestDecodeLastExtractsFencedJSON(t *testing.T) {
+	t.Parallel()
+
+	result := &ChatResult{Text: "Here you go:\n```json\n{\"city\": \"Paris\"}\n```"}
+	decoded, err := DecodeLast[struct {
+		City string `json:"city"`
+	}](result)
+	if err != nil {
+		t.Fatalf("DecodeLast returned error: %v", err)
+	}
+	if decoded.City != "Paris" {
+		t.Fatalf("unexpected decoded value %#v", decoded)
+	}
+}
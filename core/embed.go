@@ -3,6 +3,10 @@ package core
 type EmbedParams struct {
 	Input      string
 	Dimensions *int64
+
+	// ModelOptions holds provider-specific options that are passed through to
+	// the selected adapter.
+	ModelOptions map[string]any
 }
 
 type EmbedResult struct {
@@ -13,6 +17,10 @@ type EmbedResult struct {
 type EmbedManyParams struct {
 	Inputs     []string
 	Dimensions *int64
+
+	// ModelOptions holds provider-specific options that are passed through to
+	// the selected adapter.
+	ModelOptions map[string]any
 }
 
 type EmbedManyResult struct {
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RateLimit describes the provider rate limit state reported alongside a
+// response. Zero values mean the provider did not report that field.
+type RateLimit struct {
+	LimitRequests     int64
+	LimitTokens       int64
+	RemainingRequests int64
+	RemainingTokens   int64
+	ResetRequests     time.Duration
+	ResetTokens       time.Duration
+}
+
+// RateLimitError is returned by adapters when the provider rejects a request
+// because a rate limit was exceeded.
+//
+// Use errors.As to detect it and RetryAfter or RateLimit to pace retries.
+type RateLimitError struct {
+	Message    string
+	RetryAfter time.Duration
+	RateLimit  *RateLimit
+	RequestID  string
+}
+
+func (e *RateLimitError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Message
+}
+
+// APIError is returned by adapters when the provider responds with an error
+// status. RequestID identifies the request for provider support, when the
+// provider reports one.
+//
+// Retryable reports whether the provider considers the failure transient,
+// such as an overloaded or unavailable service; RetryAfter is the wait the
+// provider asked for, if any.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+	RequestID  string
+	Retryable  bool
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Message
+}
+
+// ModelNotFoundError is returned by adapters when the requested model does
+// not exist on the provider, such as an Ollama model that has not been
+// pulled.
+type ModelNotFoundError struct {
+	Model     string
+	Message   string
+	RequestID string
+}
+
+func (e *ModelNotFoundError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Message
+}
+
+// EmbeddingDimensionError is returned by adapters when a returned embedding
+// does not have the length requested through Dimensions.
+type EmbeddingDimensionError struct {
+	Expected int64
+	Actual   int64
+	// Index is the position of the offending vector in the response.
+	Index int
+}
+
+func (e *EmbeddingDimensionError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return fmt.Sprintf("embedding at index %d has %d dimensions, expected %d", e.Index, e.Actual, e.Expected)
+}
+
+// ContextLengthExceededError is returned by adapters when a prompt plus the
+// requested output cannot fit in the model's context window, before the
+// request is sent. OutputTokens is zero when no output was reserved.
+type ContextLengthExceededError struct {
+	Model         string
+	PromptTokens  int64
+	OutputTokens  int64
+	ContextWindow int64
+}
+
+func (e *ContextLengthExceededError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return fmt.Sprintf("prompt of %d tokens plus %d output tokens exceeds the %d-token context window of %s", e.PromptTokens, e.OutputTokens, e.ContextWindow, e.Model)
+}
+
+// RequestIDOf returns the provider request identifier carried by err, such as
+// a *RateLimitError or *APIError anywhere in its chain, or "" when there is
+// none. Log it to correlate failed calls, or quote it in provider support
+// tickets.
+func RequestIDOf(err error) string {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.RequestID != "" {
+		return rateLimitErr.RequestID
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RequestID != "" {
+		return apiErr.RequestID
+	}
+	var notFound *ModelNotFoundError
+	if errors.As(err, &notFound) {
+		return notFound.RequestID
+	}
+	return ""
+}
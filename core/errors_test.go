@@ -0,0 +1,27 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRequestIDOf(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{&RateLimitError{RequestID: "req_1"}, "req_1"},
+		{fmt.Errorf("chat: %w", &APIError{RequestID: "req_2"}), "req_2"},
+		{&ModelNotFoundError{RequestID: "req_3"}, "req_3"},
+		{&APIError{}, ""},
+		{errors.New("network down"), ""},
+		{nil, ""},
+	} {
+		if got := RequestIDOf(tc.err); got != tc.want {
+			t.Fatalf("RequestIDOf(%v) = %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
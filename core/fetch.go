@@ -0,0 +1,494 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultFetchMaxBytes        = 20 << 20
+	defaultFetchMaxCacheEntries = 64
+	defaultFetchTimeout         = 30 * time.Second
+)
+
+var (
+	defaultFetchClient = &http.Client{Timeout: defaultFetchTimeout}
+
+	// lookupNetIP resolves hosts for checkPublicHost.
+	lookupNetIP = net.DefaultResolver.LookupNetIP
+
+	// blockedPrefixes are refused by the address check in addition to the
+	// loopback, private, link-local, and unspecified addresses.
+	blockedPrefixes = []netip.Prefix{
+		netip.MustParsePrefix("0.0.0.0/8"),
+		netip.MustParsePrefix("100.64.0.0/10"),
+	}
+
+	// embeddedIPv4Prefixes are IPv6 forms carrying an IPv4 address in their
+	// last four bytes, which is checked as well.
+	embeddedIPv4Prefixes = []netip.Prefix{
+		netip.MustParsePrefix("::/96"),
+		netip.MustParsePrefix("64:ff9b::/96"),
+	}
+)
+
+// URLFetcher downloads media given as URLSource so it can be sent as a
+// DataSource to providers that only accept inline data, such as Ollama
+// images and OpenAI audio. Adapters use it when configured with their
+// WithURLFetcher option; InlineURLs can also be called directly.
+//
+// The zero value is ready to use. A URLFetcher is safe for concurrent use and
+// should be shared, so its cache is too.
+type URLFetcher struct {
+	// HTTPClient downloads the media. Nil uses a client with a 30 second
+	// timeout.
+	HTTPClient *http.Client
+
+	// MaxBytes limits the size of each download, 20 MiB when zero.
+	MaxBytes int64
+
+	// AllowedHosts restricts downloads, including redirects, to these hosts.
+	// An entry starting with "." also matches every subdomain, so
+	// ".example.com" allows "cdn.example.com". Empty allows any public host.
+	//
+	// Without AllowedHosts, hosts that resolve to loopback, private,
+	// link-local, carrier-grade NAT, or unspecified addresses are refused, so
+	// that a URL cannot reach internal services or the cloud metadata
+	// endpoint. The address is checked again for every connection the
+	// client dials, including redirects, so a DNS server answering
+	// differently for the connection is refused too. That check needs an
+	// *http.Transport (or none) on HTTPClient, connects without a proxy, and
+	// is skipped for other transports.
+	AllowedHosts []string
+
+	// AllowPrivateNetworks turns off the address check, for media served on
+	// the local network. It has no effect with AllowedHosts.
+	AllowPrivateNetworks bool
+
+	// MaxCacheEntries bounds how many downloads are kept by URL, 64 when
+	// zero. A negative value disables the cache.
+	MaxCacheEntries int
+
+	mu    sync.Mutex
+	cache map[string]DataSource
+	order []string
+
+	// base and transport hold the checked copy of the HTTPClient transport.
+	base      *http.Transport
+	transport *http.Transport
+}
+
+// Fetch downloads source and returns it as base64 data. The MIME type is
+// source.MimeType when set, otherwise the response Content-Type, otherwise
+// sniffed from the content.
+func (f *URLFetcher) Fetch(ctx context.Context, source URLSource) (DataSource, error) {
+	if f == nil {
+		return DataSource{}, errors.New("core: url fetcher is nil")
+	}
+
+	rawURL := strings.TrimSpace(source.URL)
+	data, ok := f.cached(rawURL)
+	if !ok {
+		var err error
+		if data, err = f.download(ctx, rawURL); err != nil {
+			return DataSource{}, err
+		}
+		f.store(rawURL, data)
+	}
+
+	if declared := normalizeMimeType(source.MimeType); declared != "" {
+		data.MimeType = declared
+	}
+	return data, nil
+}
+
+// InlineURLs returns params with the URLSource of every image, audio, and
+// document part replaced by the downloaded DataSource. When capabilities are
+// given, only parts needing one of them are inlined, for example
+// CapabilityAudioInput for a provider that accepts image URLs but not audio
+// URLs. The caller's params are not modified, and params is returned as is
+// when nothing needs fetching.
+func (f *URLFetcher) InlineURLs(ctx context.Context, params *ChatParams, capabilities ...string) (*ChatParams, error) {
+	if f == nil || params == nil {
+		return params, nil
+	}
+
+	var messages []MessageUnion
+	for i, union := range params.Messages {
+		var message ContentMessagePart
+		switch typed := union.(type) {
+		case ContentMessagePart:
+			message = typed
+		case *ContentMessagePart:
+			if typed == nil {
+				continue
+			}
+			message = *typed
+		default:
+			continue
+		}
+
+		var parts []ContentPart
+		for j, part := range message.Parts {
+			capability := partCapability(part)
+			if capability == "" || (len(capabilities) > 0 && !slices.Contains(capabilities, capability)) {
+				continue
+			}
+			source, ok := partURLSource(part)
+			if !ok {
+				continue
+			}
+
+			data, err := f.Fetch(ctx, source)
+			if err != nil {
+				return nil, fmt.Errorf("core: message %d: content part %d: %w", i, j, err)
+			}
+			kind := mediaKind(capability)
+			data.MimeType = mediaMimeType(data.MimeType, kind)
+			if kind != "" && !strings.HasPrefix(data.MimeType, kind) {
+				return nil, fmt.Errorf("core: message %d: content part %d: %q has type %q, not %s", i, j, source.URL, data.MimeType, strings.TrimSuffix(kind, "/"))
+			}
+
+			if parts == nil {
+				parts = append([]ContentPart(nil), message.Parts...)
+			}
+			parts[j] = withSource(part, data)
+		}
+		if parts == nil {
+			continue
+		}
+
+		if messages == nil {
+			messages = append([]MessageUnion(nil), params.Messages...)
+		}
+		message.Parts = parts
+		messages[i] = message
+	}
+
+	if messages == nil {
+		return params, nil
+	}
+
+	copied := *params
+	copied.Messages = messages
+	return &copied, nil
+}
+
+func (f *URLFetcher) download(ctx context.Context, rawURL string) (DataSource, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return DataSource{}, fmt.Errorf("URL %q must be an http or https URL", rawURL)
+	}
+	if err := f.checkTarget(ctx, parsed); err != nil {
+		return DataSource{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return DataSource{}, fmt.Errorf("build request for %q: %w", rawURL, err)
+	}
+
+	httpResp, err := f.client().Do(httpReq)
+	if err != nil {
+		return DataSource{}, fmt.Errorf("fetch %q: %w", rawURL, err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode >= http.StatusBadRequest {
+		return DataSource{}, fmt.Errorf("fetch %q: status %d", rawURL, httpResp.StatusCode)
+	}
+
+	limit := f.MaxBytes
+	if limit <= 0 {
+		limit = defaultFetchMaxBytes
+	}
+	if httpResp.ContentLength > limit {
+		return DataSource{}, fmt.Errorf("%q exceeds %d bytes", rawURL, limit)
+	}
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, limit+1))
+	if err != nil {
+		return DataSource{}, fmt.Errorf("read %q: %w", rawURL, err)
+	}
+	if int64(len(body)) > limit {
+		return DataSource{}, fmt.Errorf("%q exceeds %d bytes", rawURL, limit)
+	}
+	if len(body) == 0 {
+		return DataSource{}, fmt.Errorf("%q is empty", rawURL)
+	}
+
+	mimeType := normalizeMimeType(httpResp.Header.Get("Content-Type"))
+	if mimeType == "" || mimeType == "application/octet-stream" || mimeType == "binary/octet-stream" {
+		mimeType = DetectMimeType(body)
+	}
+	return DataSource{Data: base64.StdEncoding.EncodeToString(body), MimeType: mimeType}, nil
+}
+
+// client returns the HTTP client with redirects checked like the first
+// request and, without AllowedHosts, every dialed address checked.
+func (f *URLFetcher) client() *http.Client {
+	client := f.HTTPClient
+	if client == nil {
+		client = defaultFetchClient
+	}
+	if len(f.AllowedHosts) == 0 && f.AllowPrivateNetworks {
+		return client
+	}
+
+	checked := *client
+	next := client.CheckRedirect
+	checked.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := f.checkTarget(req.Context(), req.URL); err != nil {
+			return err
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	if len(f.AllowedHosts) == 0 {
+		if transport := f.publicTransport(client.Transport); transport != nil {
+			checked.Transport = transport
+		}
+	}
+	return &checked
+}
+
+// publicTransport returns a copy of transport that refuses private addresses
+// when it dials, or nil when transport is not an *http.Transport. The copy is
+// kept so its connections are reused.
+func (f *URLFetcher) publicTransport(transport http.RoundTripper) *http.Transport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	base, ok := transport.(*http.Transport)
+	if !ok {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.base != base {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: checkDialAddress}
+		f.transport = base.Clone()
+		f.transport.Proxy = nil
+		f.transport.Dial = nil
+		f.transport.DialTLS = nil
+		f.transport.DialTLSContext = nil
+		f.transport.DialContext = dialer.DialContext
+		f.base = base
+	}
+	return f.transport
+}
+
+// checkTarget checks the host of target against AllowedHosts or, without
+// them, its addresses against the private networks.
+func (f *URLFetcher) checkTarget(ctx context.Context, target *url.URL) error {
+	if len(f.AllowedHosts) > 0 {
+		return f.checkHost(target)
+	}
+	if f.AllowPrivateNetworks {
+		return nil
+	}
+	return checkPublicHost(ctx, target.Hostname())
+}
+
+func (f *URLFetcher) checkHost(target *url.URL) error {
+	host := strings.ToLower(target.Hostname())
+	for _, allowed := range f.AllowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == "" {
+			continue
+		}
+		if host == allowed || (strings.HasPrefix(allowed, ".") && (strings.HasSuffix(host, allowed) || host == allowed[1:])) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %q is not allowed", host)
+}
+
+// checkPublicHost refuses a host that is, or resolves to, a private address.
+func checkPublicHost(ctx context.Context, host string) error {
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		resolved, err := lookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return fmt.Errorf("resolve host %q: %w", host, err)
+		}
+		addrs = resolved
+	}
+
+	for _, addr := range addrs {
+		if privateAddr(addr) {
+			return fmt.Errorf("host %q has the private address %s (set AllowedHosts or AllowPrivateNetworks to allow it)", host, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// checkDialAddress is a net.Dialer Control hook that refuses connections to
+// private addresses, whatever the host name resolved to.
+func checkDialAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("dial %s %q: %w", network, address, err)
+	}
+	if addr := addrPort.Addr(); privateAddr(addr) {
+		return fmt.Errorf("private address %s refused (set AllowedHosts or AllowPrivateNetworks to allow it)", addr.Unmap())
+	}
+	return nil
+}
+
+// privateAddr reports whether addr, or the IPv4 address embedded in it, is a
+// loopback, private, link-local, carrier-grade NAT, or unspecified address.
+func privateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if blockedAddr(addr) {
+		return true
+	}
+	for _, prefix := range embeddedIPv4Prefixes {
+		if prefix.Contains(addr) {
+			bytes := addr.As16()
+			return blockedAddr(netip.AddrFrom4([4]byte(bytes[12:])))
+		}
+	}
+	return false
+}
+
+func blockedAddr(addr netip.Addr) bool {
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *URLFetcher) cached(rawURL string) (DataSource, bool) {
+	if f.MaxCacheEntries < 0 {
+		return DataSource{}, false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.cache[rawURL]
+	return data, ok
+}
+
+func (f *URLFetcher) store(rawURL string, data DataSource) {
+	limit := f.MaxCacheEntries
+	if limit < 0 {
+		return
+	}
+	if limit == 0 {
+		limit = defaultFetchMaxCacheEntries
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cache == nil {
+		f.cache = make(map[string]DataSource)
+	}
+	if _, ok := f.cache[rawURL]; !ok {
+		f.order = append(f.order, rawURL)
+	}
+	f.cache[rawURL] = data
+	for len(f.order) > limit {
+		delete(f.cache, f.order[0])
+		f.order = f.order[1:]
+	}
+}
+
+// partURLSource returns the URLSource of an image, audio, or document part.
+func partURLSource(part ContentPart) (URLSource, bool) {
+	var source Source
+	switch typed := part.(type) {
+	case ImagePart:
+		source = typed.Source
+	case *ImagePart:
+		if typed != nil {
+			source = typed.Source
+		}
+	case AudioPart:
+		source = typed.Source
+	case *AudioPart:
+		if typed != nil {
+			source = typed.Source
+		}
+	case DocumentPart:
+		source = typed.Source
+	case *DocumentPart:
+		if typed != nil {
+			source = typed.Source
+		}
+	}
+
+	switch typed := source.(type) {
+	case URLSource:
+		return typed, true
+	case *URLSource:
+		if typed != nil {
+			return *typed, true
+		}
+	}
+	return URLSource{}, false
+}
+
+// withSource returns a copy of part with source in place of its URLSource.
+func withSource(part ContentPart, source DataSource) ContentPart {
+	switch typed := part.(type) {
+	case ImagePart:
+		typed.Source = source
+		return typed
+	case *ImagePart:
+		copied := *typed
+		copied.Source = source
+		return copied
+	case AudioPart:
+		typed.Source = source
+		return typed
+	case *AudioPart:
+		copied := *typed
+		copied.Source = source
+		return copied
+	case DocumentPart:
+		typed.Source = source
+		return typed
+	case *DocumentPart:
+		copied := *typed
+		copied.Source = source
+		return copied
+	}
+	return part
+}
+
+// mediaKind returns the MIME type prefix fetched content must have for a
+// part needing capability.
+func mediaKind(capability string) string {
+	switch capability {
+	case CapabilityVision:
+		return "image/"
+	case CapabilityAudioInput:
+		return "audio/"
+	}
+	return ""
+}
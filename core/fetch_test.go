@@ -0,0 +1,202 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var wavHeader = []byte("RIFF\x24\x00\x00\x00WAVEfmt ")
+
+func TestURLFetcherInlinesAndCaches(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/clip.wav":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write(wavHeader)
+		case "/photo.png":
+			_, _ = w.Write(pngHeader)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	original := []ContentPart{
+		TextPart{Text: "listen"},
+		AudioPart{Source: URLSource{URL: server.URL + "/clip.wav"}, Metadata: map[string]any{"k": "v"}},
+		ImagePart{Source: URLSource{URL: server.URL + "/photo.png"}},
+	}
+	params := &ChatParams{Messages: []MessageUnion{
+		&ContentMessagePart{Role: RoleUser, Name: "alice", Parts: original},
+	}}
+
+	fetcher := &URLFetcher{AllowPrivateNetworks: true}
+	for range 2 {
+		inlined, err := fetcher.InlineURLs(context.Background(), params, CapabilityAudioInput)
+		if err != nil {
+			t.Fatalf("inline: %v", err)
+		}
+
+		message, ok := inlined.Messages[0].(ContentMessagePart)
+		if !ok || message.Name != "alice" {
+			t.Fatalf("expected the message fields to be kept, got %#v", inlined.Messages[0])
+		}
+		audio := message.Parts[1].(AudioPart)
+		source, ok := audio.Source.(DataSource)
+		if !ok || source.MimeType != "audio/wav" || source.Data != base64.StdEncoding.EncodeToString(wavHeader) {
+			t.Fatalf("unexpected audio source %#v", audio.Source)
+		}
+		if audio.Metadata["k"] != "v" {
+			t.Fatalf("expected part metadata to be kept, got %#v", audio.Metadata)
+		}
+		if _, ok := message.Parts[2].(ImagePart).Source.(URLSource); !ok {
+			t.Fatal("expected the image URL to be left alone")
+		}
+	}
+
+	if hits.Load() != 1 {
+		t.Fatalf("expected one download, got %d", hits.Load())
+	}
+	if _, ok := original[1].(AudioPart).Source.(URLSource); !ok {
+		t.Fatal("expected the caller's params to be unchanged")
+	}
+}
+
+func TestURLFetcherReturnsParamsWithoutURLs(t *testing.T) {
+	t.Parallel()
+
+	params := &ChatParams{Messages: []MessageUnion{TextMessagePart{Role: RoleUser, Content: "hi"}}}
+	inlined, err := (&URLFetcher{}).InlineURLs(context.Background(), params)
+	if err != nil || inlined != params {
+		t.Fatalf("expected params unchanged, got %#v, %v", inlined, err)
+	}
+}
+
+func TestURLFetcherEnforcesAllowedHosts(t *testing.T) {
+	t.Parallel()
+
+	allowed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://localhost:1/elsewhere.png", http.StatusFound)
+	}))
+	defer allowed.Close()
+	host, _ := url.Parse(allowed.URL)
+
+	fetcher := &URLFetcher{AllowedHosts: []string{host.Hostname()}}
+
+	if _, err := fetcher.Fetch(context.Background(), URLSource{URL: "http://example.com/a.png"}); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("expected a host error, got %v", err)
+	}
+	if _, err := fetcher.Fetch(context.Background(), URLSource{URL: allowed.URL + "/a.png"}); err == nil || !strings.Contains(err.Error(), `host "localhost" is not allowed`) {
+		t.Fatalf("expected the redirect to be rejected, got %v", err)
+	}
+
+	subdomains := &URLFetcher{AllowedHosts: []string{".example.com"}}
+	for rawURL, ok := range map[string]bool{
+		"https://cdn.example.com/a": true,
+		"https://example.com/a":     true,
+		"https://badexample.com/a":  false,
+	} {
+		parsed, _ := url.Parse(rawURL)
+		if err := subdomains.checkHost(parsed); (err == nil) != ok {
+			t.Fatalf("checkHost(%q) = %v, want allowed %v", rawURL, err, ok)
+		}
+	}
+}
+
+func TestURLFetcherRefusesPrivateAddresses(t *testing.T) {
+	t.Parallel()
+
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(pngHeader)
+	}))
+	defer internal.Close()
+
+	fetcher := &URLFetcher{}
+	for _, rawURL := range []string{
+		internal.URL + "/a.png",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/a.png",
+		"http://100.64.0.1/a.png",
+		"http://[::1]/a.png",
+		"http://[::ffff:127.0.0.1]/a.png",
+		"http://[::ffff:a9fe:a9fe]/a.png",
+		"http://[64:ff9b::a9fe:a9fe]/a.png",
+	} {
+		if _, err := fetcher.Fetch(context.Background(), URLSource{URL: rawURL}); err == nil || !strings.Contains(err.Error(), "private address") {
+			t.Fatalf("Fetch(%q) error = %v, want a private address error", rawURL, err)
+		}
+	}
+
+	parsed, _ := url.Parse("http://93.184.215.14/a.png")
+	if err := fetcher.checkTarget(context.Background(), parsed); err != nil {
+		t.Fatalf("checkTarget(%q) = %v", parsed, err)
+	}
+	if _, err := (&URLFetcher{AllowPrivateNetworks: true}).Fetch(context.Background(), URLSource{URL: internal.URL + "/a.png"}); err != nil {
+		t.Fatalf("Fetch() with AllowPrivateNetworks error = %v", err)
+	}
+}
+
+// TestURLFetcherChecksDialedAddress replaces lookupNetIP, so it does not run
+// in parallel.
+func TestURLFetcherChecksDialedAddress(t *testing.T) {
+	var requests atomic.Int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(pngHeader)
+	}))
+	defer internal.Close()
+
+	lookup := lookupNetIP
+	lookupNetIP = func(context.Context, string, string) ([]netip.Addr, error) {
+		return []netip.Addr{netip.MustParseAddr("93.184.215.14")}, nil
+	}
+	defer func() { lookupNetIP = lookup }()
+
+	_, port, _ := net.SplitHostPort(internal.Listener.Addr().String())
+	rawURL := "http://localhost:" + port + "/a.png"
+	_, err := (&URLFetcher{}).Fetch(context.Background(), URLSource{URL: rawURL})
+	if err == nil || !strings.Contains(err.Error(), "private address 127.0.0.1 refused") {
+		t.Fatalf("Fetch(%q) error = %v, want a private address error", rawURL, err)
+	}
+	if requests.Load() != 0 {
+		t.Fatalf("expected no request to reach the server, got %d", requests.Load())
+	}
+}
+
+func TestURLFetcherRejectsLargeAndMismatchedContent(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(pngHeader)
+	}))
+	defer server.Close()
+
+	small := &URLFetcher{MaxBytes: 4, AllowPrivateNetworks: true}
+	if _, err := small.Fetch(context.Background(), URLSource{URL: server.URL}); err == nil || !strings.Contains(err.Error(), "exceeds 4 bytes") {
+		t.Fatalf("expected a size error, got %v", err)
+	}
+
+	params := &ChatParams{Messages: []MessageUnion{ContentMessagePart{
+		Role:  RoleUser,
+		Parts: []ContentPart{AudioPart{Source: URLSource{URL: server.URL}}},
+	}}}
+	if _, err := (&URLFetcher{AllowPrivateNetworks: true}).InlineURLs(context.Background(), params); err == nil || !strings.Contains(err.Error(), `has type "image/png", not audio`) {
+		t.Fatalf("expected a type error, got %v", err)
+	}
+
+	if _, err := (&URLFetcher{}).Fetch(context.Background(), URLSource{URL: "file:///etc/passwd"}); err == nil {
+		t.Fatal("expected non-HTTP URLs to be rejected")
+	}
+}
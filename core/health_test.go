@@ -0,0 +1,26 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type healthAdapterStub struct {
+	err error
+}
+
+func (s healthAdapterStub) Ping(context.Context) error {
+	return s.err
+}
+
+func TestPing(t *testing.T) {
+	if err := Ping(context.Background(), healthAdapterStub{}); err != nil {
+		t.Fatalf("ping returned error: %v", err)
+	}
+
+	expected := errors.New("unreachable")
+	if err := Ping(context.Background(), healthAdapterStub{err: expected}); !errors.Is(err, expected) {
+		t.Fatalf("expected %v, got %v", expected, err)
+	}
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// MemoryVectorStore is a VectorStore that keeps documents in memory and
+// searches them exhaustively. It suits tests, prototypes, and corpora of up
+// to some ten thousand documents. A MemoryVectorStore is safe for concurrent
+// use.
+type MemoryVectorStore struct {
+	adapter EmbeddingAdapter
+
+	mu         sync.RWMutex
+	documents  []VectorDocument
+	index      map[string]int
+	dimensions int
+}
+
+var _ VectorStore = (*MemoryVectorStore)(nil)
+
+// NewMemoryVectorStore returns an empty MemoryVectorStore that embeds
+// documents and queries without embeddings through adapter. adapter may be
+// nil when every document and query carries its embedding.
+func NewMemoryVectorStore(adapter EmbeddingAdapter) *MemoryVectorStore {
+	return &MemoryVectorStore{adapter: adapter, index: make(map[string]int)}
+}
+
+// Len returns the number of stored documents.
+func (s *MemoryVectorStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.documents)
+}
+
+// Upsert implements VectorStore. All embeddings must have the same length.
+func (s *MemoryVectorStore) Upsert(ctx context.Context, documents ...VectorDocument) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	documents = slices.Clone(documents)
+	if err := EmbedDocuments(ctx, s.adapter, documents); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dimensions := s.dimensions
+	if len(s.documents) == 0 {
+		dimensions = len(documents[0].Embedding)
+	}
+	for _, document := range documents {
+		if len(document.Embedding) != dimensions {
+			return fmt.Errorf("core: document %q has %d dimensions, the store has %d", document.ID, len(document.Embedding), dimensions)
+		}
+	}
+	s.dimensions = dimensions
+
+	for _, document := range documents {
+		document.Embedding = slices.Clone(document.Embedding)
+		document.Metadata = maps.Clone(document.Metadata)
+		if idx, ok := s.index[document.ID]; ok {
+			s.documents[idx] = document
+			continue
+		}
+		s.index[document.ID] = len(s.documents)
+		s.documents = append(s.documents, document)
+	}
+	return nil
+}
+
+// Query implements VectorStore. Documents with equal scores are returned in
+// the order they were first added.
+func (s *MemoryVectorStore) Query(ctx context.Context, query *VectorQuery) ([]VectorMatch, error) {
+	embedding, err := QueryEmbedding(ctx, s.adapter, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := query.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	topK := query.TopK
+	if topK <= 0 {
+		topK = DefaultVectorQueryTopK
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.documents) == 0 {
+		return nil, nil
+	}
+	if len(embedding) != s.dimensions {
+		return nil, fmt.Errorf("core: query has %d dimensions, the store has %d", len(embedding), s.dimensions)
+	}
+
+	candidates := make([]int, 0, len(s.documents))
+	vectors := make([][]float64, 0, len(s.documents))
+	for idx, document := range s.documents {
+		if query.Filter.Match(document.Metadata) {
+			candidates = append(candidates, idx)
+			vectors = append(vectors, document.Embedding)
+		}
+	}
+
+	ranked, err := TopK(embedding, vectors, topK)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]VectorMatch, 0, len(ranked))
+	for _, match := range ranked {
+		if query.MinScore != nil && match.Score < *query.MinScore {
+			break
+		}
+		document := s.documents[candidates[match.Index]]
+		document.Embedding = slices.Clone(document.Embedding)
+		document.Metadata = maps.Clone(document.Metadata)
+		matches = append(matches, VectorMatch{VectorDocument: document, Score: match.Score})
+	}
+	return matches, nil
+}
+
+// Delete implements VectorStore.
+func (s *MemoryVectorStore) Delete(_ context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := false
+	for _, id := range ids {
+		if idx, ok := s.index[id]; ok {
+			s.documents[idx].ID = ""
+			delete(s.index, id)
+			removed = true
+		}
+	}
+	if !removed {
+		return nil
+	}
+
+	s.documents = slices.DeleteFunc(s.documents, func(document VectorDocument) bool {
+		return document.ID == ""
+	})
+	for idx, document := range s.documents {
+		s.index[document.ID] = idx
+	}
+	return nil
+}
@@ -0,0 +1,482 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockResponse is one scripted reply of a MockAdapter.
+type MockResponse struct {
+	// Result is the reply Chat returns. When it has ToolCalls, server tools
+	// among them run and the next reply answers the tool results, as with a
+	// provider adapter. Nil assembles the reply from Chunks.
+	Result *ChatResult
+
+	// Chunks is the timeline ChatStream sends for this reply. Empty derives
+	// the chunks from Result.
+	Chunks []MockChunk
+
+	// Err fails the request with this error.
+	Err error
+}
+
+// MockChunk is a stream chunk sent after Delay. Content and Reasoning are
+// filled in with the text so far when left empty.
+type MockChunk struct {
+	StreamChunk
+	Delay time.Duration
+}
+
+// MockEmbedding is one scripted reply of a MockAdapter to an embedding input.
+type MockEmbedding struct {
+	Embedding []float64
+	Err       error
+}
+
+// MockAdapter is a TextAdapter and EmbeddingAdapter for tests. It answers
+// requests with scripted replies in order and records every request, so tests
+// of code built on core need neither a provider nor its wire format.
+//
+// Create one with NewMockAdapter and script it with the Reply methods. A
+// MockAdapter is safe for concurrent use.
+type MockAdapter struct {
+	// ChatFunc answers chat requests once the scripted replies are used up.
+	// Nil fails them.
+	ChatFunc func(ctx context.Context, params *ChatParams) (*ChatResult, error)
+
+	// EmbedFunc answers embedding inputs once the scripted embeddings are
+	// used up. Nil fails them.
+	EmbedFunc func(ctx context.Context, input string) ([]float64, error)
+
+	mu          sync.Mutex
+	responses   []MockResponse
+	embeddings  []MockEmbedding
+	requests    []*ChatParams
+	embedInputs []string
+	toolCallIDs int
+}
+
+var (
+	_ TextAdapter      = (*MockAdapter)(nil)
+	_ EmbeddingAdapter = (*MockAdapter)(nil)
+)
+
+// NewMockAdapter returns a MockAdapter that answers with responses in order.
+func NewMockAdapter(responses ...MockResponse) *MockAdapter {
+	return (&MockAdapter{}).Script(responses...)
+}
+
+// Script appends responses to the replies.
+func (m *MockAdapter) Script(responses ...MockResponse) *MockAdapter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses = append(m.responses, responses...)
+	return m
+}
+
+// Reply appends a reply with text that stops naturally.
+func (m *MockAdapter) Reply(text string) *MockAdapter {
+	return m.Script(MockResponse{Result: &ChatResult{Text: text, FinishReason: FinishReasonStop, RawFinishReason: string(FinishReasonStop)}})
+}
+
+// ReplyToolCalls appends a reply that calls tools. Calls without an ID get
+// one, such as "call_1".
+func (m *MockAdapter) ReplyToolCalls(calls ...ToolCall) *MockAdapter {
+	m.mu.Lock()
+	toolCalls := make([]ToolCall, len(calls))
+	for i, call := range calls {
+		if strings.TrimSpace(call.ID) == "" {
+			m.toolCallIDs++
+			call.ID = fmt.Sprintf("call_%d", m.toolCallIDs)
+		}
+		toolCalls[i] = call
+	}
+	m.mu.Unlock()
+
+	return m.Script(MockResponse{Result: &ChatResult{ToolCalls: toolCalls, FinishReason: FinishReasonToolCalls, RawFinishReason: string(FinishReasonToolCalls)}})
+}
+
+// ReplyStream appends a reply that ChatStream sends as chunks; Chat returns
+// the result they add up to.
+func (m *MockAdapter) ReplyStream(chunks ...MockChunk) *MockAdapter {
+	return m.Script(MockResponse{Chunks: chunks})
+}
+
+// ReplyError appends a reply that fails the request with err.
+func (m *MockAdapter) ReplyError(err error) *MockAdapter {
+	return m.Script(MockResponse{Err: err})
+}
+
+// ReplyEmbeddings appends one embedding per input, in order.
+func (m *MockAdapter) ReplyEmbeddings(embeddings ...[]float64) *MockAdapter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, embedding := range embeddings {
+		m.embeddings = append(m.embeddings, MockEmbedding{Embedding: embedding})
+	}
+	return m
+}
+
+// ReplyEmbeddingError appends an embedding reply that fails with err.
+func (m *MockAdapter) ReplyEmbeddingError(err error) *MockAdapter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.embeddings = append(m.embeddings, MockEmbedding{Err: err})
+	return m
+}
+
+// Requests returns the chat requests received so far. A reply with server
+// tool calls is followed by a request that carries the tool results, as a
+// provider adapter sends it.
+func (m *MockAdapter) Requests() []*ChatParams {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*ChatParams(nil), m.requests...)
+}
+
+// EmbedInputs returns the embedding inputs received so far.
+func (m *MockAdapter) EmbedInputs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.embedInputs...)
+}
+
+// Remaining returns how many scripted chat replies have not been used.
+func (m *MockAdapter) Remaining() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.responses)
+}
+
+// Chat answers params with the next scripted replies, running server tools
+// between them.
+func (m *MockAdapter) Chat(ctx context.Context, params *ChatParams) (*ChatResult, error) {
+	if params == nil {
+		params = &ChatParams{}
+	}
+
+	conversation := append([]MessageUnion(nil), params.Messages...)
+	request := params
+	for {
+		response, err := m.next(ctx, request, false)
+		if err != nil {
+			return nil, err
+		}
+		result := response.result()
+
+		if len(result.ToolCalls) == 0 {
+			if result.Messages == nil {
+				result.Messages = append(conversation, TextMessagePart{Role: RoleAssistant, Content: result.Text})
+			}
+			return result, nil
+		}
+
+		conversation = append(conversation, ToolCallMessagePart{Role: RoleToolCall, ToolCalls: result.ToolCalls})
+		serverTools, clientTools := mockTools(params.Tools)
+
+		var pending []ToolCall
+		for _, call := range result.ToolCalls {
+			if tool, ok := serverTools[call.Name]; ok {
+				output, callErr := tool.Handler(call.Arguments)
+				if callErr != nil {
+					output = "tool_error: " + callErr.Error()
+				}
+				conversation = append(conversation, ToolResultMessagePart{Role: RoleToolResult, ToolCallID: call.ID, Name: call.Name, Content: output})
+				continue
+			}
+			if _, ok := clientTools[call.Name]; ok {
+				pending = append(pending, call)
+				continue
+			}
+			return nil, fmt.Errorf("core: mock tool %q was requested but not registered", call.Name)
+		}
+
+		if len(pending) > 0 {
+			result.ToolCalls = pending
+			result.Messages = append([]MessageUnion(nil), conversation...)
+			return result, nil
+		}
+
+		next := *params
+		next.Messages = append([]MessageUnion(nil), conversation...)
+		request = &next
+	}
+}
+
+// ChatStream sends the chunks of the next scripted reply as they are, without
+// running tools. Replies without chunks are answered by Chat and sent as the
+// chunks of its result.
+func (m *MockAdapter) ChatStream(ctx context.Context, params *ChatParams) (<-chan StreamChunk, error) {
+	start := time.Now()
+	out := make(chan StreamChunk, 16)
+
+	response, err := m.next(ctx, params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(out)
+
+		if response != nil {
+			sendMockChunks(ctx, out, response.Chunks)
+			return
+		}
+
+		result, err := m.Chat(ctx, params)
+		if err != nil {
+			out <- StreamChunk{Type: StreamChunkError, Error: err.Error(), Err: err, RequestID: RequestIDOf(err)}
+			return
+		}
+		sendResultChunks(out, params, result)
+	}()
+
+	return TimeStream(ctx, out, start), nil
+}
+
+// Embed answers with the next scripted embedding.
+func (m *MockAdapter) Embed(ctx context.Context, params *EmbedParams) (*EmbedResult, error) {
+	if params == nil {
+		return nil, errors.New("core: embed params are required")
+	}
+	embedding, err := m.nextEmbedding(ctx, params.Input)
+	if err != nil {
+		return nil, err
+	}
+	return &EmbedResult{Embedding: embedding}, nil
+}
+
+// EmbedMany answers each input with the next scripted embedding.
+func (m *MockAdapter) EmbedMany(ctx context.Context, params *EmbedManyParams) (*EmbedManyResult, error) {
+	if params == nil {
+		return nil, errors.New("core: embed many params are required")
+	}
+	embeddings := make([][]float64, 0, len(params.Inputs))
+	for _, input := range params.Inputs {
+		embedding, err := m.nextEmbedding(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, embedding)
+	}
+	return &EmbedManyResult{Embeddings: embeddings}, nil
+}
+
+// next records params and returns the next reply. With streamOnly, it only
+// takes a reply that has chunks or an error and otherwise returns nil, which
+// leaves the reply to Chat.
+func (m *MockAdapter) next(ctx context.Context, params *ChatParams, streamOnly bool) (*MockResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	if len(m.responses) > 0 {
+		response := m.responses[0]
+		if streamOnly && len(response.Chunks) == 0 && response.Err == nil {
+			m.mu.Unlock()
+			return nil, nil
+		}
+		m.responses = m.responses[1:]
+		m.requests = append(m.requests, params)
+		m.mu.Unlock()
+		if response.Err != nil {
+			return nil, response.Err
+		}
+		return &response, nil
+	}
+	if streamOnly {
+		m.mu.Unlock()
+		return nil, nil
+	}
+	m.requests = append(m.requests, params)
+	count := len(m.requests)
+	chatFunc := m.ChatFunc
+	m.mu.Unlock()
+
+	if chatFunc == nil {
+		return nil, fmt.Errorf("core: mock adapter has no reply scripted for request %d", count)
+	}
+	result, err := chatFunc(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = &ChatResult{}
+	}
+	return &MockResponse{Result: result}, nil
+}
+
+func (m *MockAdapter) nextEmbedding(ctx context.Context, input string) ([]float64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.embedInputs = append(m.embedInputs, input)
+	count := len(m.embedInputs)
+	if len(m.embeddings) > 0 {
+		reply := m.embeddings[0]
+		m.embeddings = m.embeddings[1:]
+		m.mu.Unlock()
+		return reply.Embedding, reply.Err
+	}
+	embedFunc := m.EmbedFunc
+	m.mu.Unlock()
+
+	if embedFunc == nil {
+		return nil, fmt.Errorf("core: mock adapter has no embedding scripted for input %d", count)
+	}
+	return embedFunc(ctx, input)
+}
+
+// result returns a copy of the reply's result, assembled from its chunks
+// when it has none.
+func (r *MockResponse) result() *ChatResult {
+	if r.Result != nil {
+		result := *r.Result
+		result.ToolCalls = append([]ToolCall(nil), r.Result.ToolCalls...)
+		return &result
+	}
+
+	result := &ChatResult{}
+	var text, reasoning strings.Builder
+	for _, chunk := range r.Chunks {
+		switch chunk.Type {
+		case StreamChunkContent, StreamChunkPartialJSON:
+			text.WriteString(chunk.Delta)
+		case StreamChunkReasoning:
+			reasoning.WriteString(chunk.Delta)
+		case StreamChunkToolCall:
+			if chunk.ToolCall != nil {
+				result.ToolCalls = append(result.ToolCalls, *chunk.ToolCall)
+			}
+		case StreamChunkDone:
+			result.FinishReason = chunk.FinishReason
+			result.RawFinishReason = chunk.RawFinishReason
+			result.Usage = chunk.Usage
+		}
+	}
+	result.Text = text.String()
+	result.Reasoning = reasoning.String()
+	return result
+}
+
+func mockTools(tools []ToolUnion) (map[string]ServerTool, map[string]struct{}) {
+	serverTools := make(map[string]ServerTool)
+	clientTools := make(map[string]struct{})
+	for _, tool := range tools {
+		switch typed := tool.(type) {
+		case ServerTool:
+			serverTools[typed.Name] = typed
+		case *ServerTool:
+			if typed != nil {
+				serverTools[typed.Name] = *typed
+			}
+		case ClientTool:
+			clientTools[typed.Name] = struct{}{}
+		case *ClientTool:
+			if typed != nil {
+				clientTools[typed.Name] = struct{}{}
+			}
+		}
+	}
+	return serverTools, clientTools
+}
+
+// sendMockChunks sends chunks after their delays until ctx is done.
+func sendMockChunks(ctx context.Context, out chan<- StreamChunk, chunks []MockChunk) {
+	var content, reasoning strings.Builder
+	for _, chunk := range chunks {
+		if chunk.Delay > 0 {
+			timer := time.NewTimer(chunk.Delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+
+		switch chunk.Type {
+		case StreamChunkContent, StreamChunkPartialJSON:
+			content.WriteString(chunk.Delta)
+			if chunk.Content == "" {
+				chunk.Content = content.String()
+			}
+		case StreamChunkReasoning:
+			reasoning.WriteString(chunk.Delta)
+			if chunk.Reasoning == "" {
+				chunk.Reasoning = reasoning.String()
+			}
+		}
+		if chunk.Role == "" && (chunk.Type == StreamChunkContent || chunk.Type == StreamChunkReasoning || chunk.Type == StreamChunkPartialJSON) {
+			chunk.Role = RoleAssistant
+		}
+
+		select {
+		case out <- chunk.StreamChunk:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sendResultChunks sends result as the chunks a provider adapter derives
+// from a non-streaming reply.
+func sendResultChunks(out chan<- StreamChunk, params *ChatParams, result *ChatResult) {
+	if result.Reasoning != "" {
+		out <- StreamChunk{Type: StreamChunkReasoning, Role: RoleAssistant, Delta: result.Reasoning, Reasoning: result.Reasoning}
+	}
+
+	start := 0
+	if params != nil && len(params.Messages) <= len(result.Messages) {
+		start = len(params.Messages)
+	}
+	for _, message := range result.Messages[start:] {
+		switch typed := message.(type) {
+		case TextMessagePart:
+			sendResultText(out, &typed)
+		case *TextMessagePart:
+			sendResultText(out, typed)
+		case ToolCallMessagePart:
+			sendResultToolCalls(out, &typed)
+		case *ToolCallMessagePart:
+			sendResultToolCalls(out, typed)
+		case ToolResultMessagePart:
+			out <- StreamChunk{Type: StreamChunkToolResult, ToolCallID: typed.ToolCallID, Content: typed.Content}
+		case *ToolResultMessagePart:
+			if typed != nil {
+				out <- StreamChunk{Type: StreamChunkToolResult, ToolCallID: typed.ToolCallID, Content: typed.Content}
+			}
+		}
+	}
+
+	out <- StreamChunk{
+		Type:            StreamChunkDone,
+		FinishReason:    result.FinishReason,
+		RawFinishReason: result.RawFinishReason,
+		Reasoning:       result.Reasoning,
+		Usage:           result.Usage,
+		RequestID:       result.RequestID,
+	}
+}
+
+func sendResultText(out chan<- StreamChunk, message *TextMessagePart) {
+	if message != nil && message.Role == RoleAssistant && message.Content != "" {
+		out <- StreamChunk{Type: StreamChunkContent, Role: RoleAssistant, Delta: message.Content, Content: message.Content}
+	}
+}
+
+func sendResultToolCalls(out chan<- StreamChunk, message *ToolCallMessagePart) {
+	if message == nil {
+		return
+	}
+	for _, call := range message.ToolCalls {
+		out <- StreamChunk{Type: StreamChunkToolCall, ToolCall: &call}
+	}
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMockAdapterRepliesInOrder(t *testing.T) {
+	t.Parallel()
+
+	sentinel := errors.New("rate limited")
+	adapter := NewMockAdapter().Reply("first").ReplyError(sentinel).Reply("second")
+	params := &ChatParams{Messages: []MessageUnion{TextMessagePart{Role: RoleUser, Content: "hi"}}}
+
+	result, err := Chat(context.Background(), adapter, params)
+	if err != nil || result.Text != "first" || result.FinishReason != FinishReasonStop {
+		t.Fatalf("first Chat() = %#v, %v", result, err)
+	}
+	if len(result.Messages) != 2 || !reflect.DeepEqual(result.Messages[1], TextMessagePart{Role: RoleAssistant, Content: "first"}) {
+		t.Fatalf("Messages = %#v", result.Messages)
+	}
+	if _, err := Chat(context.Background(), adapter, params); !errors.Is(err, sentinel) {
+		t.Fatalf("second Chat() error = %v, want scripted error", err)
+	}
+	if result, err := Chat(context.Background(), adapter, params); err != nil || result.Text != "second" {
+		t.Fatalf("third Chat() = %#v, %v", result, err)
+	}
+	if _, err := Chat(context.Background(), adapter, params); err == nil || !strings.Contains(err.Error(), "no reply scripted for request 4") {
+		t.Fatalf("unscripted Chat() error = %v", err)
+	}
+	if requests := adapter.Requests(); len(requests) != 4 || requests[0] != params || adapter.Remaining() != 0 {
+		t.Fatalf("Requests() = %d, Remaining() = %d", len(requests), adapter.Remaining())
+	}
+}
+
+func TestMockAdapterRunsServerToolsBetweenReplies(t *testing.T) {
+	t.Parallel()
+
+	var got any
+	weather := ServerTool{Name: "get_weather", Handler: func(arguments any) (string, error) {
+		got = arguments
+		return `{"temperature":21}`, nil
+	}}
+	adapter := NewMockAdapter().
+		ReplyToolCalls(ToolCall{Name: "get_weather", Arguments: map[string]any{"city": "Paris"}}).
+		Reply("It is 21 degrees.")
+
+	result, err := Chat(context.Background(), adapter, &ChatParams{
+		Tools:    []ToolUnion{weather},
+		Messages: []MessageUnion{TextMessagePart{Role: RoleUser, Content: "Weather in Paris?"}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Text != "It is 21 degrees." || !reflect.DeepEqual(got, map[string]any{"city": "Paris"}) {
+		t.Fatalf("Text = %q, tool arguments = %#v", result.Text, got)
+	}
+
+	requests := adapter.Requests()
+	if len(requests) != 2 || len(requests[1].Messages) != 3 {
+		t.Fatalf("requests = %d, follow-up messages = %#v", len(requests), requests[len(requests)-1].Messages)
+	}
+	toolResult, ok := requests[1].Messages[2].(ToolResultMessagePart)
+	if !ok || toolResult.ToolCallID != "call_1" || toolResult.Content != `{"temperature":21}` {
+		t.Fatalf("follow-up tool result = %#v", requests[1].Messages[2])
+	}
+	if len(result.Messages) != 4 {
+		t.Fatalf("Messages = %#v", result.Messages)
+	}
+}
+
+func TestMockAdapterReturnsClientToolCalls(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewMockAdapter().ReplyToolCalls(ToolCall{ID: "call_a", Name: "confirm"})
+	params := &ChatParams{Tools: []ToolUnion{&ClientTool{Name: "confirm"}}}
+
+	result, err := Chat(context.Background(), adapter, params)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.FinishReason != FinishReasonToolCalls || len(result.ToolCalls) != 1 || result.ToolCalls[0].ID != "call_a" {
+		t.Fatalf("result = %#v", result)
+	}
+
+	adapter.ReplyToolCalls(ToolCall{Name: "unknown"})
+	if _, err := Chat(context.Background(), adapter, params); err == nil || !strings.Contains(err.Error(), `tool "unknown" was requested but not registered`) {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
+
+func TestMockAdapterStreamsTimeline(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewMockAdapter().ReplyStream(
+		MockChunk{StreamChunk: StreamChunk{Type: StreamChunkReasoning, Delta: "Think"}},
+		MockChunk{StreamChunk: StreamChunk{Type: StreamChunkContent, Delta: "Hel"}, Delay: time.Millisecond},
+		MockChunk{StreamChunk: StreamChunk{Type: StreamChunkContent, Delta: "lo"}},
+		MockChunk{StreamChunk: StreamChunk{Type: StreamChunkDone, FinishReason: FinishReasonStop, Usage: &Usage{CompletionTokens: 2}}},
+	)
+
+	stream, err := ChatStream(context.Background(), adapter, &ChatParams{})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	var chunks []StreamChunk
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+
+	if len(chunks) != 4 || chunks[2].Content != "Hello" || chunks[2].Role != RoleAssistant || chunks[0].Reasoning != "Think" {
+		t.Fatalf("chunks = %#v", chunks)
+	}
+	done := chunks[3]
+	if done.Usage == nil || done.Usage.CompletionTokens != 2 || done.Usage.TimeToFirstToken <= 0 || done.Usage.Duration < time.Millisecond {
+		t.Fatalf("done usage = %#v", done.Usage)
+	}
+}
+
+func TestMockAdapterStreamDerivesChunksFromReplies(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewMockAdapter().
+		ReplyToolCalls(ToolCall{Name: "lookup"}).
+		Reply("found it")
+	tool := ServerTool{Name: "lookup", Handler: func(any) (string, error) { return "42", nil }}
+
+	stream, err := adapter.ChatStream(context.Background(), &ChatParams{Tools: []ToolUnion{tool}})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	var types []string
+	for chunk := range stream {
+		types = append(types, chunk.Type)
+	}
+	want := []string{StreamChunkToolCall, StreamChunkToolResult, StreamChunkContent, StreamChunkDone}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("chunk types = %v, want %v", types, want)
+	}
+
+	adapter.ReplyError(errors.New("unavailable"))
+	if _, err := adapter.ChatStream(context.Background(), &ChatParams{}); err == nil || err.Error() != "unavailable" {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+}
+
+func TestMockAdapterChatAssemblesStreamReply(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewMockAdapter().ReplyStream(
+		MockChunk{StreamChunk: StreamChunk{Type: StreamChunkContent, Delta: "a"}},
+		MockChunk{StreamChunk: StreamChunk{Type: StreamChunkContent, Delta: "b"}},
+		MockChunk{StreamChunk: StreamChunk{Type: StreamChunkDone, FinishReason: FinishReasonLength}},
+	)
+	result, err := adapter.Chat(context.Background(), &ChatParams{})
+	if err != nil || result.Text != "ab" || result.FinishReason != FinishReasonLength {
+		t.Fatalf("Chat() = %#v, %v", result, err)
+	}
+}
+
+func TestMockAdapterEmbeddings(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewMockAdapter().ReplyEmbeddings([]float64{1, 0}, []float64{0, 1}).ReplyEmbeddingError(errors.New("too long"))
+
+	result, err := EmbedMany(context.Background(), adapter, &EmbedManyParams{Inputs: []string{"a", "b"}})
+	if err != nil || !reflect.DeepEqual(result.Embeddings, [][]float64{{1, 0}, {0, 1}}) {
+		t.Fatalf("EmbedMany() = %#v, %v", result, err)
+	}
+	if _, err := Embed(context.Background(), adapter, &EmbedParams{Input: "c"}); err == nil || err.Error() != "too long" {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	adapter.EmbedFunc = func(ctx context.Context, input string) ([]float64, error) {
+		return []float64{float64(len(input))}, nil
+	}
+	if result, err := Embed(context.Background(), adapter, &EmbedParams{Input: "four"}); err != nil || result.Embedding[0] != 4 {
+		t.Fatalf("Embed() = %#v, %v", result, err)
+	}
+	if inputs := adapter.EmbedInputs(); !reflect.DeepEqual(inputs, []string{"a", "b", "c", "four"}) {
+		t.Fatalf("EmbedInputs() = %v", inputs)
+	}
+}
@@ -0,0 +1,10 @@
+package core
+
+import "time"
+
+// ModelInfo describes a model reported by a provider's model discovery endpoint.
+type ModelInfo struct {
+	ID          string
+	DisplayName string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+type modelAdapterStub struct {
+	listModelsFn func(context.Context) ([]ModelInfo, error)
+}
+
+func (s modelAdapterStub) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return s.listModelsFn(ctx)
+}
+
+func TestListModels(t *testing.T) {
+	adapter := modelAdapterStub{
+		listModelsFn: func(context.Context) ([]ModelInfo, error) {
+			return []ModelInfo{{ID: "model-a"}, {ID: "model-b"}}, nil
+		},
+	}
+
+	models, err := ListModels(context.Background(), adapter)
+	if err != nil {
+		t.Fatalf("list models returned error: %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "model-a" || models[1].ID != "model-b" {
+		t.Fatalf("unexpected models %#v", models)
+	}
+}
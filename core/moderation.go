@@ -0,0 +1,31 @@
+package core
+
+// ModerationParams configures a content moderation request.
+type ModerationParams struct {
+	// Inputs are the texts to classify, each moderated on its own.
+	Inputs []string
+
+	// ModelOptions holds provider-specific options that are passed through to
+	// the selected adapter.
+	ModelOptions map[string]any
+}
+
+// ModerationResult holds one Moderation per input, in input order.
+type ModerationResult struct {
+	Model   string
+	Results []Moderation
+}
+
+// Moderation is the classification of one input.
+type Moderation struct {
+	// Flagged reports whether the provider considers the input harmful in
+	// any category.
+	Flagged bool
+
+	// Categories reports per category, such as "harassment" or
+	// "self-harm/intent", whether the input was flagged for it.
+	Categories map[string]bool
+
+	// Scores holds the provider's confidence per category, from 0 to 1.
+	Scores map[string]float64
+}
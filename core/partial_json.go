@@ -0,0 +1,18 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/m43i/go-ai/internal/partialjson"
+)
+
+// CompletePartialJSON turns the prefix of a JSON document, such as structured
+// output that is still streaming, into valid JSON. It closes an unterminated
+// string and any open objects and arrays, dropping a trailing incomplete key
+// or value. It returns "" when no valid prefix exists yet.
+// The text is scanned once, so the cost is linear in its length.
+func CompletePartialJSON(partial string) string {
+	var assembler partialjson.Assembler
+	assembler.WriteString(strings.TrimSpace(partial))
+	return assembler.Partial()
+}
@@ -0,0 +1,30 @@
+package core
+
+import "testing"
+
+func TestCompletePartialJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		partial string
+		want    string
+	}{
+		{``, ``},
+		{`{`, `{}`},
+		{`{"name": "Go`, `{"name": "Go"}`},
+		{`{"name": "Go", "tags": ["fast", "sim`, `{"name": "Go", "tags": ["fast", "sim"]}`},
+		{`{"name": "Go", `, `{"name": "Go"}`},
+		{`{"name": "Go", "year`, `{"name": "Go"}`},
+		{`{"name": "Go", "year": `, `{"name": "Go"}`},
+		{`{"name": "Go", "year": 20`, `{"name": "Go", "year": 20}`},
+		{`{"ok": tr`, `{}`},
+		{`{"path": "a\`, `{"path": "a"}`},
+		{`[{"a": 1}, {"b": [1, 2`, `[{"a": 1}, {"b": [1, 2]}]`},
+		{`{"a": 1}`, `{"a": 1}`},
+	}
+	for _, tt := range tests {
+		if got := CompletePartialJSON(tt.partial); got != tt.want {
+			t.Fatalf("CompletePartialJSON(%q) = %q, want %q", tt.partial, got, tt.want)
+		}
+	}
+}
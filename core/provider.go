@@ -0,0 +1,185 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ProviderConfig describes an adapter to build with NewAdapter. Empty fields
+// keep the provider's defaults, which include reading API keys from the
+// provider's usual environment variables.
+type ProviderConfig struct {
+	// Provider is the registered provider name, such as "openai", "claude",
+	// or "ollama".
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+	APIKey   string `json:"api_key,omitempty"`
+	BaseURL  string `json:"base_url,omitempty"`
+
+	// Options holds provider-specific settings by name, such as "timeout" or
+	// Claude's "version". Providers reject options they do not know.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// ProviderFactory builds an adapter from a config.
+type ProviderFactory func(config ProviderConfig) (TextAdapter, error)
+
+var providers = struct {
+	mu        sync.RWMutex
+	factories map[string]ProviderFactory
+}{factories: make(map[string]ProviderFactory)}
+
+// RegisterProvider makes a provider available to NewAdapter, FromEnv, and
+// FromDSN under name, replacing any provider with the same name. The
+// provider packages of this module register themselves when imported:
+//
+//	import _ "github.com/m43i/go-ai/claude"
+func RegisterProvider(name string, factory ProviderFactory) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || factory == nil {
+		return
+	}
+
+	providers.mu.Lock()
+	providers.factories[name] = factory
+	providers.mu.Unlock()
+}
+
+// Providers returns the names of the registered providers, sorted.
+func Providers() []string {
+	providers.mu.RLock()
+	defer providers.mu.RUnlock()
+
+	names := make([]string, 0, len(providers.factories))
+	for name := range providers.factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// NewAdapter builds an adapter of the registered provider config.Provider.
+// The adapter implements TextAdapter; type-assert it for other capabilities,
+// such as EmbeddingAdapter.
+func NewAdapter(config ProviderConfig) (TextAdapter, error) {
+	name := strings.ToLower(strings.TrimSpace(config.Provider))
+	providers.mu.RLock()
+	factory, ok := providers.factories[name]
+	providers.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("core: unknown provider %q (forgotten import?)", config.Provider)
+	}
+
+	config.Provider = name
+	adapter, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("core: %s: %w", name, err)
+	}
+	return adapter, nil
+}
+
+// FromEnv builds an adapter of provider from environment variables named
+// after it: the model from <PROVIDER>_MODEL, such as OPENAI_MODEL, and the
+// base URL from <PROVIDER>_BASE_URL, with dashes in the name turned into
+// underscores. The API key comes from the provider's usual variable, such as
+// OPENAI_API_KEY or ANTHROPIC_API_KEY.
+func FromEnv(provider string) (TextAdapter, error) {
+	prefix := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(provider), "-", "_"))
+	return NewAdapter(ProviderConfig{
+		Provider: provider,
+		Model:    strings.TrimSpace(os.Getenv(prefix + "_MODEL")),
+		BaseURL:  strings.TrimSpace(os.Getenv(prefix + "_BASE_URL")),
+	})
+}
+
+// FromDSN builds an adapter from a DSN string; see ParseDSN.
+func FromDSN(dsn string) (TextAdapter, error) {
+	config, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return NewAdapter(config)
+}
+
+// ParseDSN parses a DSN of the form
+//
+//	provider://[api-key@]model[?option=value&...]
+//
+// such as "claude://claude-3-5-haiku-latest?version=2023-06-01" or
+// "ollama://llama3.2:3b?base_url=http://gpu-box:11434". The options api_key
+// and base_url set the matching config fields; all other options go to
+// ProviderConfig.Options. Model names may contain slashes and colons.
+func ParseDSN(dsn string) (ProviderConfig, error) {
+	provider, rest, ok := strings.Cut(strings.TrimSpace(dsn), "://")
+	if !ok || provider == "" {
+		return ProviderConfig{}, errors.New(`core: DSN must start with "provider://"`)
+	}
+	config := ProviderConfig{Provider: provider}
+
+	rest, rawQuery, _ := strings.Cut(rest, "?")
+	if at := strings.LastIndex(rest, "@"); at >= 0 {
+		key, err := url.PathUnescape(rest[:at])
+		if err != nil {
+			return ProviderConfig{}, fmt.Errorf("core: DSN API key: %w", err)
+		}
+		config.APIKey, rest = key, rest[at+1:]
+	}
+	model, err := url.PathUnescape(rest)
+	if err != nil {
+		return ProviderConfig{}, fmt.Errorf("core: DSN model: %w", err)
+	}
+	config.Model = model
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ProviderConfig{}, fmt.Errorf("core: DSN options: %w", err)
+	}
+	for name, values := range query {
+		value := values[len(values)-1]
+		switch name {
+		case "api_key":
+			config.APIKey = value
+		case "base_url":
+			config.BaseURL = value
+		default:
+			if config.Options == nil {
+				config.Options = make(map[string]string)
+			}
+			config.Options[name] = value
+		}
+	}
+	return config, nil
+}
+
+// LoadConfig builds an adapter from a JSON config file holding a
+// ProviderConfig, such as
+//
+//	{"provider": "openai", "model": "gpt-4o-mini", "api_key": "${OPENAI_API_KEY}", "options": {"timeout": "30s"}}
+//
+// Environment variables in string values are expanded, so that secrets can
+// stay out of the file.
+func LoadConfig(path string) (TextAdapter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("core: read config: %w", err)
+	}
+	var config ProviderConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("core: parse config %s: %w", path, err)
+	}
+
+	config.Provider = os.ExpandEnv(config.Provider)
+	config.Model = os.ExpandEnv(config.Model)
+	config.APIKey = os.ExpandEnv(config.APIKey)
+	config.BaseURL = os.ExpandEnv(config.BaseURL)
+	for name, value := range config.Options {
+		config.Options[name] = os.ExpandEnv(value)
+	}
+	return NewAdapter(config)
+}
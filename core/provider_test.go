@@ -0,0 +1,99 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// configAdapter is a TextAdapter that remembers the config it was built from.
+type configAdapter struct {
+	*MockAdapter
+	config ProviderConfig
+}
+
+func init() {
+	RegisterProvider("Test-Provider", func(config ProviderConfig) (TextAdapter, error) {
+		return configAdapter{MockAdapter: NewMockAdapter(), config: config}, nil
+	})
+}
+
+func TestParseDSN(t *testing.T) {
+	t.Parallel()
+
+	config, err := ParseDSN("claude://claude-3-5-haiku-latest?version=2023-06-01&timeout=30s")
+	if err != nil {
+		t.Fatalf("ParseDSN() error = %v", err)
+	}
+	want := ProviderConfig{Provider: "claude", Model: "claude-3-5-haiku-latest", Options: map[string]string{"version": "2023-06-01", "timeout": "30s"}}
+	if !reflect.DeepEqual(config, want) {
+		t.Fatalf("config = %+v", config)
+	}
+
+	config, err = ParseDSN("ollama://s%40cret@library/llama3.2:3b?base_url=http://gpu-box:11434")
+	if err != nil {
+		t.Fatalf("ParseDSN() error = %v", err)
+	}
+	if config.APIKey != "s@cret" || config.Model != "library/llama3.2:3b" || config.BaseURL != "http://gpu-box:11434" || config.Options != nil {
+		t.Fatalf("config = %+v", config)
+	}
+
+	for _, dsn := range []string{"", "gpt-4o", "://gpt-4o", "openai://gpt-4o?a=%zz"} {
+		if _, err := ParseDSN(dsn); err == nil {
+			t.Fatalf("ParseDSN(%q) expected error", dsn)
+		}
+	}
+}
+
+func TestNewAdapterFromRegistry(t *testing.T) {
+	t.Parallel()
+
+	adapter, err := FromDSN("test-provider://key@model-1?region=eu")
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	config := adapter.(configAdapter).config
+	if config.Provider != "test-provider" || config.APIKey != "key" || config.Model != "model-1" || config.Options["region"] != "eu" {
+		t.Fatalf("config = %+v", config)
+	}
+
+	if _, err := FromDSN("nope://model"); err == nil || !strings.Contains(err.Error(), `unknown provider "nope"`) {
+		t.Fatalf("FromDSN() unknown provider error = %v", err)
+	}
+	found := false
+	for _, name := range Providers() {
+		found = found || name == "test-provider"
+	}
+	if !found {
+		t.Fatalf("Providers() = %v", Providers())
+	}
+}
+
+func TestFromEnvAndLoadConfig(t *testing.T) {
+	t.Setenv("TEST_PROVIDER_MODEL", "env-model")
+	t.Setenv("TEST_PROVIDER_BASE_URL", "http://localhost:8080")
+	t.Setenv("TEST_SECRET", "s3cret")
+
+	adapter, err := FromEnv("test-provider")
+	if err != nil {
+		t.Fatalf("FromEnv() error = %v", err)
+	}
+	if config := adapter.(configAdapter).config; config.Model != "env-model" || config.BaseURL != "http://localhost:8080" {
+		t.Fatalf("config = %+v", config)
+	}
+
+	path := filepath.Join(t.TempDir(), "adapter.json")
+	data := `{"provider": "test-provider", "model": "file-model", "api_key": "${TEST_SECRET}", "options": {"region": "$TEST_SECRET"}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	adapter, err = LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config := adapter.(configAdapter).config; config.Model != "file-model" || config.APIKey != "s3cret" || config.Options["region"] != "s3cret" {
+		t.Fatalf("config = %+v", config)
+	}
+}
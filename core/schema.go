@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -61,6 +64,84 @@ func NewSchema(name string, v any) (Schema, error) {
 	}, nil
 }
 
+// NewSchemaFromJSON builds a Schema from a JSON schema document maintained
+// outside Go, such as a schema file.
+//
+// The document must describe an object. The returned schema is not strict;
+// set Strict when the document meets the provider's strict-mode rules.
+func NewSchemaFromJSON(name string, data []byte) (Schema, error) {
+	var document map[string]any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return Schema{}, fmt.Errorf("invalid schema document: %w", err)
+	}
+	return NewSchemaFromMap(name, document)
+}
+
+// NewSchemaFromMap builds a Schema from a decoded JSON schema document. See
+// NewSchemaFromJSON.
+func NewSchemaFromMap(name string, document map[string]any) (Schema, error) {
+	if name == "" {
+		return Schema{}, errors.New("schema name must not be empty")
+	}
+	if document == nil {
+		return Schema{}, errors.New("schema document is nil")
+	}
+	if err := validateSchemaDocument(document); err != nil {
+		return Schema{}, err
+	}
+
+	return Schema{
+		Name:   name,
+		Schema: document,
+	}, nil
+}
+
+// validateSchemaDocument checks the structure providers rely on: an object
+// root whose properties is an object and whose required names listed
+// properties.
+func validateSchemaDocument(document map[string]any) error {
+	if kind, ok := document["type"]; ok && kind != "object" {
+		return fmt.Errorf("schema root must have type \"object\", got %v", kind)
+	}
+
+	var props map[string]any
+	if raw, ok := document["properties"]; ok {
+		props, ok = raw.(map[string]any)
+		if !ok {
+			return errors.New("schema properties must be an object")
+		}
+	}
+	if _, ok := document["type"]; !ok && props == nil {
+		return errors.New("schema root must have type \"object\" or properties")
+	}
+
+	raw, ok := document["required"]
+	if !ok {
+		return nil
+	}
+	var required []string
+	switch typed := raw.(type) {
+	case []string:
+		required = typed
+	case []any:
+		for _, item := range typed {
+			name, ok := item.(string)
+			if !ok {
+				return errors.New("schema required must list property names")
+			}
+			required = append(required, name)
+		}
+	default:
+		return errors.New("schema required must be an array")
+	}
+	for _, name := range required {
+		if _, ok := props[name]; !ok {
+			return fmt.Errorf("schema requires undefined property %q", name)
+		}
+	}
+	return nil
+}
+
 // MarshalJSON encodes Schema into the response_format payload expected by chat APIs.
 func (s Schema) MarshalJSON() ([]byte, error) {
 	if s.Name == "" || s.Schema == nil {
@@ -88,13 +169,78 @@ func (s Schema) String() string {
 	return string(b)
 }
 
-var timeType = reflect.TypeFor[time.Time]()
+// SchemaProvider is implemented by types that describe their own JSON
+// schema, such as UUIDs, decimal strings, or domain enums. NewSchema uses the
+// returned schema instead of reflecting over the type.
+type SchemaProvider interface {
+	JSONSchema() map[string]any
+}
+
+var (
+	timeType           = reflect.TypeFor[time.Time]()
+	rawMessageType     = reflect.TypeFor[json.RawMessage]()
+	schemaProviderType = reflect.TypeFor[SchemaProvider]()
+)
+
+// schemaProviderFor returns the SchemaProvider of t, whether JSONSchema has a
+// value or a pointer receiver.
+func schemaProviderFor(t reflect.Type) (SchemaProvider, bool) {
+	if t.Kind() == reflect.Interface {
+		return nil, false
+	}
+	if t.Implements(schemaProviderType) {
+		return reflect.Zero(t).Interface().(SchemaProvider), true
+	}
+	if reflect.PointerTo(t).Implements(schemaProviderType) {
+		return reflect.New(t).Interface().(SchemaProvider), true
+	}
+	return nil, false
+}
+
+// fieldSchemaFor returns the schema of a struct field. A schema tag holding a
+// JSON schema document replaces the reflected schema, which lets flexible
+// fields such as any or json.RawMessage describe their expected shape.
+func fieldSchemaFor(f reflect.StructField, visited map[reflect.Type]bool) (map[string]any, error) {
+	raw, ok := f.Tag.Lookup("schema")
+	if !ok {
+		return schemaForType(f.Type, visited)
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		return nil, fmt.Errorf("invalid schema tag: %w", err)
+	}
+	if out == nil {
+		return nil, errors.New("invalid schema tag: must be a JSON object")
+	}
+	return out, nil
+}
 
 func schemaForType(t reflect.Type, visited map[reflect.Type]bool) (map[string]any, error) {
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
 
+	if provider, ok := schemaProviderFor(t); ok {
+		schema := provider.JSONSchema()
+		if len(schema) == 0 {
+			return nil, fmt.Errorf("%s: JSONSchema returned an empty schema", t)
+		}
+		return maps.Clone(schema), nil
+	}
+
+	if t.Implements(unionFieldType) {
+		t = reflect.Zero(t).Interface().(unionField).unionInterface()
+	}
+	if spec, ok := lookupUnion(t); ok {
+		return unionSchema(spec, visited)
+	}
+
+	// Arbitrary JSON values accept any schema.
+	if t == rawMessageType || t.Kind() == reflect.Interface {
+		return map[string]any{}, nil
+	}
+
 	if t == timeType {
 		return map[string]any{
 			"type":   "string",
@@ -127,7 +273,7 @@ func schemaForType(t reflect.Type, visited map[reflect.Type]bool) (map[string]an
 			fieldType := f.Type
 			isPtr := fieldType.Kind() == reflect.Pointer
 
-			fieldSchema, err := schemaForType(fieldType, visited)
+			fieldSchema, err := fieldSchemaFor(f, visited)
 			if err != nil {
 				return nil, fmt.Errorf("field %s: %w", f.Name, err)
 			}
@@ -135,6 +281,9 @@ func schemaForType(t reflect.Type, visited map[reflect.Type]bool) (map[string]an
 			if desc := f.Tag.Get("description"); desc != "" {
 				fieldSchema["description"] = desc
 			}
+			if err := applyConstraintTags(fieldSchema, f.Tag); err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
 
 			props[name] = fieldSchema
 
@@ -194,6 +343,62 @@ func schemaForType(t reflect.Type, visited map[reflect.Type]bool) (map[string]an
 	}
 }
 
+// applyConstraintTags copies the minimum, maximum, minLength, maxLength,
+// pattern, and format struct tags onto a field schema. Numeric tags apply to
+// integer and number fields, the others to string fields.
+func applyConstraintTags(schema map[string]any, tag reflect.StructTag) error {
+	kind, _ := schema["type"].(string)
+
+	for _, key := range []string{"minimum", "maximum"} {
+		raw, ok := tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		if kind != "integer" && kind != "number" {
+			return fmt.Errorf("%s tag requires a numeric field, got %s", key, kind)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag %q: %w", key, raw, err)
+		}
+		schema[key] = value
+	}
+
+	for _, key := range []string{"minLength", "maxLength"} {
+		raw, ok := tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		if kind != "string" {
+			return fmt.Errorf("%s tag requires a string field, got %s", key, kind)
+		}
+		value, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag %q: %w", key, raw, err)
+		}
+		schema[key] = value
+	}
+
+	if pattern, ok := tag.Lookup("pattern"); ok {
+		if kind != "string" {
+			return fmt.Errorf("pattern tag requires a string field, got %s", kind)
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern tag %q: %w", pattern, err)
+		}
+		schema["pattern"] = pattern
+	}
+
+	if format, ok := tag.Lookup("format"); ok {
+		if kind != "string" {
+			return fmt.Errorf("format tag requires a string field, got %s", kind)
+		}
+		schema["format"] = format
+	}
+
+	return nil
+}
+
 func parseJSONTag(f reflect.StructField) (name string, omitempty bool, skip bool) {
 	tag := f.Tag.Get("json")
 	if tag == "-" {
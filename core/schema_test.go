@@ -0,0 +1,181 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewSchemaAppliesConstraintTags(t *testing.T) {
+	t.Parallel()
+
+	type signup struct {
+		Email string  `json:"email" format:"email" maxLength:"254"`
+		Name  string  `json:"name" minLength:"1" pattern:"^[A-Za-z ]+$"`
+		Age   int     `json:"age" minimum:"18" maximum:"130"`
+		Score float64 `json:"score" minimum:"0.5"`
+	}
+
+	schema, err := NewSchema("signup", signup{})
+	if err != nil {
+		t.Fatalf("NewSchema returned error: %v", err)
+	}
+
+	props := schema.Schema["properties"].(map[string]any)
+	expected := map[string]any{
+		"email": map[string]any{"type": "string", "format": "email", "maxLength": uint64(254)},
+		"name":  map[string]any{"type": "string", "minLength": uint64(1), "pattern": "^[A-Za-z ]+$"},
+		"age":   map[string]any{"type": "integer", "minimum": float64(18), "maximum": float64(130)},
+		"score": map[string]any{"type": "number", "minimum": 0.5},
+	}
+	if !reflect.DeepEqual(props, expected) {
+		t.Fatalf("unexpected properties:\n got %#v\nwant %#v", props, expected)
+	}
+}
+
+func TestNewSchemaRejectsInvalidConstraintTags(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]any{
+		"requires a numeric field": struct {
+			Name string `json:"name" minimum:"1"`
+		}{},
+		"requires a string field": struct {
+			Count int `json:"count" maxLength:"3"`
+		}{},
+		"invalid pattern tag": struct {
+			Code string `json:"code" pattern:"["`
+		}{},
+		"invalid maximum tag": struct {
+			Count int `json:"count" maximum:"many"`
+		}{},
+	}
+	for want, value := range tests {
+		if _, err := NewSchema("invalid", value); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestNewSchemaSupportsFlexibleFields(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		Payload  json.RawMessage `json:"payload"`
+		Metadata any             `json:"metadata" description:"Free-form metadata"`
+		Extra    map[string]any  `json:"extra"`
+		Tags     any             `json:"tags" schema:"{\"type\":\"array\",\"items\":{\"type\":\"string\"}}"`
+	}
+
+	schema, err := NewSchema("event", event{})
+	if err != nil {
+		t.Fatalf("NewSchema returned error: %v", err)
+	}
+
+	props := schema.Schema["properties"].(map[string]any)
+	expected := map[string]any{
+		"payload":  map[string]any{},
+		"metadata": map[string]any{"description": "Free-form metadata"},
+		"extra":    map[string]any{"type": "object", "additionalProperties": map[string]any{}},
+		"tags":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+	}
+	if !reflect.DeepEqual(props, expected) {
+		t.Fatalf("unexpected properties:\n got %#v\nwant %#v", props, expected)
+	}
+
+	if _, err := NewSchema("bad", struct {
+		Value any `json:"value" schema:"not json"`
+	}{}); err == nil || !strings.Contains(err.Error(), "invalid schema tag") {
+		t.Fatalf("expected invalid schema tag error, got %v", err)
+	}
+}
+
+type testUUID [16]byte
+
+func (testUUID) JSONSchema() map[string]any {
+	return map[string]any{"type": "string", "format": "uuid"}
+}
+
+type testPriority string
+
+func (*testPriority) JSONSchema() map[string]any {
+	return map[string]any{"type": "string", "enum": []string{"low", "high"}}
+}
+
+func TestNewSchemaUsesSchemaProvider(t *testing.T) {
+	t.Parallel()
+
+	type ticket struct {
+		ID       testUUID      `json:"id"`
+		Priority *testPriority `json:"priority" description:"Urgency"`
+	}
+
+	schema, err := NewSchema("ticket", ticket{})
+	if err != nil {
+		t.Fatalf("NewSchema returned error: %v", err)
+	}
+
+	props := schema.Schema["properties"].(map[string]any)
+	expected := map[string]any{
+		"id":       map[string]any{"type": "string", "format": "uuid"},
+		"priority": map[string]any{"type": "string", "enum": []string{"low", "high"}, "description": "Urgency"},
+	}
+	if !reflect.DeepEqual(props, expected) {
+		t.Fatalf("unexpected properties:\n got %#v\nwant %#v", props, expected)
+	}
+	if _, ok := (testUUID{}).JSONSchema()["description"]; ok {
+		t.Fatal("expected provider schema not to be modified")
+	}
+}
+
+type testOpaque struct{}
+
+func (testOpaque) JSONSchema() map[string]any { return nil }
+
+func TestNewSchemaRejectsEmptyProviderSchema(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewSchema("record", struct {
+		Value testOpaque `json:"value" description:"Opaque value"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "empty schema") {
+		t.Fatalf("expected empty schema error, got %v", err)
+	}
+}
+
+func TestNewSchemaFromJSON(t *testing.T) {
+	t.Parallel()
+
+	schema, err := NewSchemaFromJSON("city", []byte(`{
+		"type": "object",
+		"properties": {"name": {"type": "string"}, "population": {"type": "integer"}},
+		"required": ["name"]
+	}`))
+	if err != nil {
+		t.Fatalf("NewSchemaFromJSON returned error: %v", err)
+	}
+	if schema.Name != "city" || schema.Strict {
+		t.Fatalf("unexpected schema %#v", schema)
+	}
+	if _, ok := schema.Schema["properties"].(map[string]any)["population"]; !ok {
+		t.Fatalf("expected properties to be kept, got %#v", schema.Schema)
+	}
+
+	tests := map[string]string{
+		`not json`:                            "invalid schema document",
+		`{"type": "array"}`:                   "must have type",
+		`{"description": "x"}`:                "must have type",
+		`{"type": "object", "properties": 1}`: "properties must be an object",
+		`{"type": "object", "properties": {}, "required": ["name"]}`: "undefined property",
+	}
+	for document, want := range tests {
+		if _, err := NewSchemaFromJSON("bad", []byte(document)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("NewSchemaFromJSON(%s) error = %v, want %q", document, err, want)
+		}
+	}
+
+	if _, err := NewSchemaFromMap("", map[string]any{"type": "object"}); err == nil {
+		t.Fatal("expected error for empty name")
+	}
+}
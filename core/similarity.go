@@ -0,0 +1,149 @@
+package core
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// SimilarityFunc scores how similar two vectors of the same length are;
+// higher scores are more similar.
+type SimilarityFunc func(a, b []float64) (float64, error)
+
+// Match is a vector found by TopK, identified by its index in the searched
+// vectors.
+type Match struct {
+	Index int
+	Score float64
+}
+
+// DotProduct returns the dot product of a and b. For normalized vectors it
+// equals their cosine similarity and is cheaper to compute.
+func DotProduct(a, b []float64) (float64, error) {
+	if err := checkLengths(a, b); err != nil {
+		return 0, err
+	}
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum, nil
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, from -1
+// for opposite vectors to 1 for vectors pointing the same way. It is 0 when
+// either vector is all zeros.
+func CosineSimilarity(a, b []float64) (float64, error) {
+	if err := checkLengths(a, b); err != nil {
+		return 0, err
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0, nil
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB)), nil
+}
+
+// Norm returns the Euclidean length of v.
+func Norm(v []float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+// Normalize returns a copy of v scaled to unit length, so DotProduct of
+// normalized vectors is their cosine similarity. A vector of zeros is returned
+// as a copy unchanged.
+func Normalize(v []float64) []float64 {
+	out := make([]float64, len(v))
+	norm := Norm(v)
+	if norm == 0 {
+		copy(out, v)
+		return out
+	}
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+// TopK returns the k vectors most similar to query by cosine similarity,
+// most similar first. Vectors with equal scores keep their order. When k
+// exceeds the number of vectors every vector is returned; k <= 0 returns
+// none.
+func TopK(query []float64, vectors [][]float64, k int) ([]Match, error) {
+	return TopKFunc(query, vectors, k, CosineSimilarity)
+}
+
+// TopKFunc is like TopK but scores vectors with similarity, such as
+// DotProduct for normalized vectors.
+func TopKFunc(query []float64, vectors [][]float64, k int, similarity SimilarityFunc) ([]Match, error) {
+	if similarity == nil {
+		similarity = CosineSimilarity
+	}
+	if k <= 0 || len(vectors) == 0 {
+		return nil, nil
+	}
+	k = min(k, len(vectors))
+
+	// The heap holds the best k matches so far with the worst on top.
+	best := make(matchHeap, 0, k)
+	for idx, vector := range vectors {
+		score, err := similarity(query, vector)
+		if err != nil {
+			return nil, fmt.Errorf("core: vector %d: %w", idx, err)
+		}
+		match := Match{Index: idx, Score: score}
+		if len(best) < k {
+			heap.Push(&best, match)
+			continue
+		}
+		if best.less(best[0], match) {
+			best[0] = match
+			heap.Fix(&best, 0)
+		}
+	}
+
+	out := make([]Match, len(best))
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(&best).(Match)
+	}
+	return out, nil
+}
+
+func checkLengths(a, b []float64) error {
+	if len(a) != len(b) {
+		return fmt.Errorf("vectors have different lengths: %d and %d", len(a), len(b))
+	}
+	return nil
+}
+
+// matchHeap is a min-heap of matches ordered from worst to best: lower
+// scores first, and later indexes first among equal scores.
+type matchHeap []Match
+
+func (h matchHeap) less(a, b Match) bool {
+	if a.Score != b.Score {
+		return a.Score < b.Score
+	}
+	return a.Index > b.Index
+}
+
+func (h matchHeap) Len() int           { return len(h) }
+func (h matchHeap) Less(i, j int) bool { return h.less(h[i], h[j]) }
+func (h matchHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *matchHeap) Push(x any)        { *h = append(*h, x.(Match)) }
+
+func (h *matchHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		a, b       []float64
+		dot, cosim float64
+	}{
+		{"same direction", []float64{1, 2, 3}, []float64{2, 4, 6}, 28, 1},
+		{"orthogonal", []float64{1, 0}, []float64{0, 5}, 0, 0},
+		{"opposite", []float64{1, 1}, []float64{-1, -1}, -2, -1},
+		{"zero vector", []float64{0, 0}, []float64{1, 1}, 0, 0},
+		{"empty", nil, nil, 0, 0},
+	}
+	for _, tc := range tests {
+		dot, err := DotProduct(tc.a, tc.b)
+		if err != nil || dot != tc.dot {
+			t.Fatalf("%s: DotProduct() = %v, %v", tc.name, dot, err)
+		}
+		cosim, err := CosineSimilarity(tc.a, tc.b)
+		if err != nil || math.Abs(cosim-tc.cosim) > 1e-12 {
+			t.Fatalf("%s: CosineSimilarity() = %v, %v", tc.name, cosim, err)
+		}
+	}
+
+	if _, err := CosineSimilarity([]float64{1}, []float64{1, 2}); err == nil || !strings.Contains(err.Error(), "1 and 2") {
+		t.Fatalf("CosineSimilarity() error = %v", err)
+	}
+	if _, err := DotProduct([]float64{1, 2}, []float64{1}); err == nil {
+		t.Fatal("DotProduct() expected error for different lengths")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	v := []float64{3, 4}
+	got := Normalize(v)
+	if !reflect.DeepEqual(got, []float64{0.6, 0.8}) || Norm(got) != 1 {
+		t.Fatalf("Normalize() = %v", got)
+	}
+	if !reflect.DeepEqual(v, []float64{3, 4}) {
+		t.Fatalf("Normalize() modified its input: %v", v)
+	}
+
+	zero := []float64{0, 0}
+	if got := Normalize(zero); !reflect.DeepEqual(got, zero) || &got[0] == &zero[0] {
+		t.Fatalf("Normalize(zero) = %v", got)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	t.Parallel()
+
+	vectors := [][]float64{
+		{0, 1},
+		{1, 0},
+		{1, 1},
+		{-1, 0},
+		{2, 0},
+	}
+
+	got, err := TopK([]float64{1, 0}, vectors, 3)
+	if err != nil {
+		t.Fatalf("TopK() error = %v", err)
+	}
+	indexes := make([]int, len(got))
+	for i, match := range got {
+		indexes[i] = match.Index
+	}
+	// Vectors 1 and 4 tie; the earlier one ranks first.
+	if !reflect.DeepEqual(indexes, []int{1, 4, 2}) || got[0].Score != 1 || math.Abs(got[2].Score-math.Sqrt2/2) > 1e-12 {
+		t.Fatalf("TopK() = %+v", got)
+	}
+
+	all, err := TopK([]float64{1, 0}, vectors, 10)
+	if err != nil || len(all) != len(vectors) || all[len(all)-1].Index != 3 {
+		t.Fatalf("TopK(k > n) = %+v, %v", all, err)
+	}
+	if none, err := TopK([]float64{1, 0}, vectors, 0); err != nil || none != nil {
+		t.Fatalf("TopK(k = 0) = %+v, %v", none, err)
+	}
+
+	byDot, err := TopKFunc([]float64{1, 0}, vectors, 1, DotProduct)
+	if err != nil || len(byDot) != 1 || byDot[0] != (Match{Index: 4, Score: 2}) {
+		t.Fatalf("TopKFunc() = %+v, %v", byDot, err)
+	}
+
+	if _, err := TopK([]float64{1, 0}, [][]float64{{1, 0}, {1}}, 1); err == nil || !strings.Contains(err.Error(), "vector 1") {
+		t.Fatalf("TopK() error = %v", err)
+	}
+}
+
+func BenchmarkTopK(b *testing.B) {
+	vectors := make([][]float64, 10000)
+	for i := range vectors {
+		vector := make([]float64, 256)
+		for j := range vector {
+			vector[j] = math.Sin(float64(i*256 + j))
+		}
+		vectors[i] = vector
+	}
+	query := vectors[42]
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := TopK(query, vectors, 10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
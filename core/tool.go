@@ -26,3 +26,49 @@ type ClientTool struct {
 }
 
 func (ClientTool) isToolUnion() {}
+
+// ProviderTool is a built-in tool executed by the model provider itself, such
+// as web search. Type is the provider-specific tool type and Options are merged
+// into the tool definition sent to the provider.
+type ProviderTool struct {
+	Type    string
+	Name    string
+	Options map[string]any
+}
+
+func (ProviderTool) isToolUnion() {}
+
+// MCPServer is a remote MCP server that the provider connects to on the
+// caller's behalf. When AllowedTools is set, only those tools may be used.
+type MCPServer struct {
+	Name               string
+	URL                string
+	AuthorizationToken string
+	AllowedTools       []string
+}
+
+// ProviderToolCall records a tool call the provider executed itself, such as
+// a call to an MCP server tool, together with its result.
+type ProviderToolCall struct {
+	ID        string
+	Name      string
+	Server    string
+	Arguments any
+	Result    string
+	IsError   bool
+}
+
+// Tool choice modes.
+const (
+	ToolChoiceAuto     = "auto"
+	ToolChoiceNone     = "none"
+	ToolChoiceRequired = "required"
+)
+
+// ToolChoice controls whether and which tool the model calls. Mode is one of
+// ToolChoiceAuto, ToolChoiceNone, or ToolChoiceRequired; Name instead forces
+// a call to the named tool and takes precedence over Mode.
+type ToolChoice struct {
+	Mode string
+	Name string
+}
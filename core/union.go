@@ -0,0 +1,240 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// unionSpec describes a registered union interface.
+type unionSpec struct {
+	discriminator string
+	variants      map[string]reflect.Type
+	names         map[reflect.Type]string
+}
+
+var (
+	unionsMu sync.RWMutex
+	unions   = map[reflect.Type]unionSpec{}
+)
+
+// RegisterUnion registers the concrete variants of the interface type T for
+// schema generation and decoding.
+//
+// Each variant is keyed by the value its discriminator property holds, such
+// as "answer" or "clarification". NewSchema describes fields of type T, or
+// Union[T], as an anyOf over the variant schemas, each with the
+// discriminator as a required string property. Union[T] decodes the variant
+// the discriminator names.
+func RegisterUnion[T any](discriminator string, variants map[string]T) error {
+	unionType := reflect.TypeFor[T]()
+	if unionType.Kind() != reflect.Interface {
+		return fmt.Errorf("union type must be an interface, got %s", unionType)
+	}
+	if discriminator == "" {
+		return errors.New("union discriminator must not be empty")
+	}
+	if len(variants) == 0 {
+		return fmt.Errorf("union %s has no variants", unionType)
+	}
+
+	spec := unionSpec{
+		discriminator: discriminator,
+		variants:      make(map[string]reflect.Type, len(variants)),
+		names:         make(map[reflect.Type]string, len(variants)),
+	}
+	for name, variant := range variants {
+		variantType := reflect.TypeOf(variant)
+		if name == "" || variantType == nil {
+			return fmt.Errorf("union %s has an empty variant", unionType)
+		}
+		structType := variantType
+		if structType.Kind() == reflect.Pointer {
+			structType = structType.Elem()
+		}
+		if structType.Kind() != reflect.Struct {
+			return fmt.Errorf("union %s variant %q must be a struct, got %s", unionType, name, variantType)
+		}
+		spec.variants[name] = variantType
+		spec.names[variantType] = name
+	}
+
+	unionsMu.Lock()
+	unions[unionType] = spec
+	unionsMu.Unlock()
+	return nil
+}
+
+func lookupUnion(t reflect.Type) (unionSpec, bool) {
+	unionsMu.RLock()
+	defer unionsMu.RUnlock()
+	spec, ok := unions[t]
+	return spec, ok
+}
+
+// Union holds one variant of the registered union interface T and encodes
+// it with its discriminator. Use it for struct fields that decode model
+// output, since encoding/json cannot decode into an interface.
+type Union[T any] struct {
+	Value T
+}
+
+func (Union[T]) unionInterface() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// unionField is implemented by every Union[T].
+type unionField interface {
+	unionInterface() reflect.Type
+}
+
+var unionFieldType = reflect.TypeFor[unionField]()
+
+// MarshalJSON encodes the variant with its discriminator property set.
+func (u Union[T]) MarshalJSON() ([]byte, error) {
+	value := reflect.ValueOf(any(u.Value))
+	if !value.IsValid() {
+		return []byte("null"), nil
+	}
+
+	spec, ok := lookupUnion(reflect.TypeFor[T]())
+	if !ok {
+		return nil, fmt.Errorf("union %s is not registered", reflect.TypeFor[T]())
+	}
+	name, ok := spec.names[value.Type()]
+	if !ok {
+		return nil, fmt.Errorf("union %s has no variant for %s", reflect.TypeFor[T](), value.Type())
+	}
+
+	body, err := json.Marshal(u.Value)
+	if err != nil {
+		return nil, err
+	}
+	var envelope map[string]any
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("union variant %q must encode as a JSON object: %w", name, err)
+	}
+	envelope[spec.discriminator] = name
+	return json.Marshal(envelope)
+}
+
+// UnmarshalJSON decodes the variant named by the discriminator property.
+func (u *Union[T]) UnmarshalJSON(data []byte) error {
+	value, err := DecodeUnion[T](data)
+	if err != nil {
+		return err
+	}
+	u.Value = value
+	return nil
+}
+
+// DecodeUnion decodes data into the variant of the registered union
+// interface T that its discriminator property names.
+func DecodeUnion[T any](data []byte) (T, error) {
+	var out T
+
+	unionType := reflect.TypeFor[T]()
+	spec, ok := lookupUnion(unionType)
+	if !ok {
+		return out, fmt.Errorf("union %s is not registered", unionType)
+	}
+
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return out, fmt.Errorf("decode union %s: %w", unionType, err)
+	}
+	var name string
+	if err := json.Unmarshal(envelope[spec.discriminator], &name); err != nil || name == "" {
+		return out, fmt.Errorf("decode union %s: missing %q discriminator", unionType, spec.discriminator)
+	}
+	variantType, ok := spec.variants[name]
+	if !ok {
+		return out, fmt.Errorf("decode union %s: unknown variant %q", unionType, name)
+	}
+
+	var target reflect.Value
+	if variantType.Kind() == reflect.Pointer {
+		target = reflect.New(variantType.Elem())
+	} else {
+		target = reflect.New(variantType)
+	}
+	if err := json.Unmarshal(data, target.Interface()); err != nil {
+		return out, fmt.Errorf("decode union %s variant %q: %w", unionType, name, err)
+	}
+	if variantType.Kind() != reflect.Pointer {
+		target = target.Elem()
+	}
+
+	return target.Interface().(T), nil
+}
+
+// unionSchema builds the anyOf schema of a registered union interface.
+func unionSchema(spec unionSpec, visited map[reflect.Type]bool) (map[string]any, error) {
+	names := make([]string, 0, len(spec.variants))
+	for name := range spec.variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	variants := make([]any, 0, len(names))
+	for _, name := range names {
+		variant, err := schemaForType(spec.variants[name], visited)
+		if err != nil {
+			return nil, fmt.Errorf("variant %q: %w", name, err)
+		}
+
+		// The variant schema may belong to a SchemaProvider, so it is copied
+		// before the discriminator is added.
+		variant = cloneSchemaValue(variant).(map[string]any)
+		if kind, ok := variant["type"]; ok && kind != "object" {
+			return nil, fmt.Errorf("variant %q: schema type %v cannot carry the %q discriminator", name, kind, spec.discriminator)
+		}
+		props, _ := variant["properties"].(map[string]any)
+		if props == nil {
+			props = map[string]any{}
+			variant["properties"] = props
+		}
+		props[spec.discriminator] = map[string]any{"type": "string", "enum": []string{name}}
+
+		required := []string{spec.discriminator}
+		switch names := variant["required"].(type) {
+		case []string:
+			required = append(required, names...)
+		case []any:
+			for _, field := range names {
+				if field, ok := field.(string); ok {
+					required = append(required, field)
+				}
+			}
+		}
+		variant["required"] = required
+		variants = append(variants, variant)
+	}
+
+	return map[string]any{"anyOf": variants}, nil
+}
+
+// cloneSchemaValue returns a deep copy of a schema value.
+func cloneSchemaValue(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		for key, item := range typed {
+			out[key] = cloneSchemaValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(typed))
+		for i, item := range typed {
+			out[i] = cloneSchemaValue(item)
+		}
+		return out
+	case []string:
+		return append([]string(nil), typed...)
+	default:
+		return value
+	}
+}
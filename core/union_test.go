@@ -0,0 +1,159 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type testReply interface{ isTestReply() }
+
+type testAnswer struct {
+	Text string `json:"text"`
+}
+
+type testClarification struct {
+	Question string   `json:"question"`
+	Options  []string `json:"options,omitempty"`
+}
+
+func (testAnswer) isTestReply()         {}
+func (*testClarification) isTestReply() {}
+
+type testTurn struct {
+	Reply Union[testReply] `json:"reply"`
+}
+
+func init() {
+	if err := RegisterUnion("kind", map[string]testReply{
+		"answer":        testAnswer{},
+		"clarification": &testClarification{},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+func TestNewSchemaDescribesUnionVariants(t *testing.T) {
+	t.Parallel()
+
+	schema, err := NewSchema("turn", testTurn{})
+	if err != nil {
+		t.Fatalf("NewSchema returned error: %v", err)
+	}
+
+	reply := schema.Schema["properties"].(map[string]any)["reply"].(map[string]any)
+	expected := map[string]any{"anyOf": []any{
+		map[string]any{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"kind": map[string]any{"type": "string", "enum": []string{"answer"}},
+				"text": map[string]any{"type": "string"},
+			},
+			"required": []string{"kind", "text"},
+		},
+		map[string]any{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"kind":     map[string]any{"type": "string", "enum": []string{"clarification"}},
+				"question": map[string]any{"type": "string"},
+				"options":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+			"required": []string{"kind", "question"},
+		},
+	}}
+	if !reflect.DeepEqual(reply, expected) {
+		t.Fatalf("unexpected union schema:\n got %#v\nwant %#v", reply, expected)
+	}
+}
+
+func TestUnionRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var turn testTurn
+	if err := json.Unmarshal([]byte(`{"reply":{"kind":"clarification","question":"Which city?"}}`), &turn); err != nil {
+		t.Fatalf("unmarshal returned error: %v", err)
+	}
+	clarification, ok := turn.Reply.Value.(*testClarification)
+	if !ok || clarification.Question != "Which city?" {
+		t.Fatalf("unexpected variant %#v", turn.Reply.Value)
+	}
+
+	body, err := json.Marshal(testTurn{Reply: Union[testReply]{Value: testAnswer{Text: "Paris"}}})
+	if err != nil {
+		t.Fatalf("marshal returned error: %v", err)
+	}
+	if string(body) != `{"reply":{"kind":"answer","text":"Paris"}}` {
+		t.Fatalf("unexpected encoding %s", body)
+	}
+
+	if _, err := DecodeUnion[testReply]([]byte(`{"kind":"unknown"}`)); err == nil {
+		t.Fatal("expected error for unknown variant")
+	}
+}
+
+func TestRegisterUnionRejectsNonInterface(t *testing.T) {
+	t.Parallel()
+
+	if err := RegisterUnion("kind", map[string]testAnswer{"answer": {}}); err == nil {
+		t.Fatal("expected error for non-interface union type")
+	}
+}
+
+type testShape interface{ isTestShape() }
+
+type testCircle struct {
+	Radius float64 `json:"radius"`
+}
+
+type testLabel struct{}
+
+var testCircleSchema = map[string]any{
+	"type":       "object",
+	"properties": map[string]any{"radius": map[string]any{"type": "number"}},
+	"required":   []any{"radius"},
+}
+
+func (testCircle) isTestShape()               {}
+func (testCircle) JSONSchema() map[string]any { return testCircleSchema }
+
+type testLabeled interface{ isTestLabeled() }
+
+func (testLabel) isTestLabeled()             {}
+func (testLabel) JSONSchema() map[string]any { return map[string]any{"type": "string"} }
+
+func TestNewSchemaCopiesProviderVariants(t *testing.T) {
+	t.Parallel()
+
+	if err := RegisterUnion("shape", map[string]testShape{"circle": testCircle{}}); err != nil {
+		t.Fatalf("RegisterUnion returned error: %v", err)
+	}
+	schema, err := NewSchema("drawing", struct {
+		Shape Union[testShape] `json:"shape"`
+	}{})
+	if err != nil {
+		t.Fatalf("NewSchema returned error: %v", err)
+	}
+
+	circle := schema.Schema["properties"].(map[string]any)["shape"].(map[string]any)["anyOf"].([]any)[0].(map[string]any)
+	if required := circle["required"]; !reflect.DeepEqual(required, []string{"shape", "radius"}) {
+		t.Fatalf("unexpected required %#v", required)
+	}
+	if _, ok := testCircleSchema["properties"].(map[string]any)["shape"]; ok {
+		t.Fatal("provider schema was modified")
+	}
+}
+
+func TestNewSchemaRejectsNonObjectVariants(t *testing.T) {
+	t.Parallel()
+
+	if err := RegisterUnion("kind", map[string]testLabeled{"label": testLabel{}}); err != nil {
+		t.Fatalf("RegisterUnion returned error: %v", err)
+	}
+	if _, err := NewSchema("tag", struct {
+		Value Union[testLabeled] `json:"value"`
+	}{}); err == nil {
+		t.Fatal("expected error for non-object variant")
+	}
+}
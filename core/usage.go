@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Add adds the token counts, details, and duration of other to u. The
+// service tier and request ID of other replace those of u when set, and the
+// time to first token of u is kept unless it is unset. A nil u or other is a
+// no-op.
+func (u *Usage) Add(other *Usage) {
+	if u == nil || other == nil {
+		return
+	}
+
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.ReasoningTokens += other.ReasoningTokens
+	u.Duration += other.Duration
+
+	if len(other.Details) > 0 {
+		details := make(map[string]int64, len(u.Details)+len(other.Details))
+		for key, value := range u.Details {
+			details[key] = value
+		}
+		for key, value := range other.Details {
+			details[key] += value
+		}
+		u.Details = details
+	}
+	if u.TimeToFirstToken == 0 {
+		u.TimeToFirstToken = other.TimeToFirstToken
+	}
+	if other.ServiceTier != "" {
+		u.ServiceTier = other.ServiceTier
+	}
+	if other.RequestID != "" {
+		u.RequestID = other.RequestID
+	}
+}
+
+// Merge returns the sum of u and other as a new Usage, following the rules of
+// Add, without modifying either. A nil operand counts as empty; Merge returns
+// nil when both are nil.
+func (u *Usage) Merge(other *Usage) *Usage {
+	if u == nil && other == nil {
+		return nil
+	}
+
+	out := &Usage{}
+	out.Add(u)
+	out.Add(other)
+	return out
+}
+
+// TimeStream forwards the chunks of in and sets Usage.Duration and
+// Usage.TimeToFirstToken, measured from start, on the StreamChunkDone chunk,
+// adding a Usage when the provider reported none. Adapters wrap their
+// ChatStream channel with it. Once ctx is done, chunks are no longer
+// forwarded, but in is still drained so the producer can finish.
+func TimeStream(ctx context.Context, in <-chan StreamChunk, start time.Time) <-chan StreamChunk {
+	out := make(chan StreamChunk, cap(in))
+	go func() {
+		defer close(out)
+
+		var firstToken time.Duration
+		canceled := false
+		for chunk := range in {
+			if canceled {
+				continue
+			}
+			if firstToken == 0 && (chunk.Delta != "" || chunk.Reasoning != "" || chunk.ToolCall != nil) {
+				firstToken = time.Since(start)
+			}
+			if chunk.Type == StreamChunkDone {
+				usage := &Usage{}
+				if chunk.Usage != nil {
+					*usage = *chunk.Usage
+				}
+				usage.Duration = time.Since(start)
+				usage.TimeToFirstToken = firstToken
+				chunk.Usage = usage
+			}
+
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				canceled = true
+			}
+		}
+	}()
+	return out
+}
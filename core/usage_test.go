@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestUsageAdd(t *testing.T) {
+	t.Parallel()
+
+	total := &Usage{
+		PromptTokens:     10,
+		CompletionTokens: 5,
+		TotalTokens:      15,
+		Details:          map[string]int64{"cached_prompt_tokens": 4},
+		Duration:         time.Second,
+		TimeToFirstToken: 200 * time.Millisecond,
+		RequestID:        "req_1",
+	}
+	details := total.Details
+
+	total.Add(&Usage{
+		PromptTokens:     7,
+		CompletionTokens: 3,
+		TotalTokens:      10,
+		ReasoningTokens:  2,
+		Details:          map[string]int64{"cached_prompt_tokens": 1, "prompt_audio_tokens": 6},
+		Duration:         500 * time.Millisecond,
+		TimeToFirstToken: 100 * time.Millisecond,
+		ServiceTier:      "priority",
+		RequestID:        "req_2",
+	})
+
+	if total.PromptTokens != 17 || total.CompletionTokens != 8 || total.TotalTokens != 25 || total.ReasoningTokens != 2 {
+		t.Fatalf("unexpected token counts %#v", total)
+	}
+	if total.Details["cached_prompt_tokens"] != 5 || total.Details["prompt_audio_tokens"] != 6 {
+		t.Fatalf("unexpected details %#v", total.Details)
+	}
+	if details["cached_prompt_tokens"] != 4 {
+		t.Fatal("expected the original details map to be left unchanged")
+	}
+	if total.Duration != 1500*time.Millisecond || total.TimeToFirstToken != 200*time.Millisecond {
+		t.Fatalf("unexpected timing %v, %v", total.Duration, total.TimeToFirstToken)
+	}
+	if total.ServiceTier != "priority" || total.RequestID != "req_2" {
+		t.Fatalf("unexpected tier %q or request ID %q", total.ServiceTier, total.RequestID)
+	}
+
+	total.Add(nil)
+	var missing *Usage
+	missing.Add(total)
+}
+
+func TestUsageMerge(t *testing.T) {
+	t.Parallel()
+
+	a := &Usage{PromptTokens: 1, TotalTokens: 1}
+	b := &Usage{CompletionTokens: 2, TotalTokens: 2}
+
+	merged := a.Merge(b)
+	if merged == a || merged == b || merged.TotalTokens != 3 || merged.PromptTokens != 1 || merged.CompletionTokens != 2 {
+		t.Fatalf("unexpected merge %#v", merged)
+	}
+	if a.TotalTokens != 1 || b.TotalTokens != 2 {
+		t.Fatal("expected the operands to be left unchanged")
+	}
+
+	var missing *Usage
+	if got := missing.Merge(b); got == nil || got == b || got.TotalTokens != 2 {
+		t.Fatalf("expected a copy of b, got %#v", got)
+	}
+	if missing.Merge(nil) != nil {
+		t.Fatal("expected nil when both operands are nil")
+	}
+}
+
+func TestTimeStreamSetsTiming(t *testing.T) {
+	t.Parallel()
+
+	in := make(chan StreamChunk, 3)
+	in <- StreamChunk{Type: StreamChunkContent, Delta: "hi"}
+	in <- StreamChunk{Type: StreamChunkDone, Usage: &Usage{TotalTokens: 3}}
+	close(in)
+
+	start := time.Now().Add(-time.Second)
+	var chunks []StreamChunk
+	for chunk := range TimeStream(context.Background(), in, start) {
+		chunks = append(chunks, chunk)
+	}
+
+	if len(chunks) != 2 || chunks[0].Usage != nil {
+		t.Fatalf("unexpected chunks %#v", chunks)
+	}
+	usage := chunks[1].Usage
+	if usage == nil || usage.TotalTokens != 3 {
+		t.Fatalf("expected the done chunk usage to be kept, got %#v", usage)
+	}
+	if usage.TimeToFirstToken < time.Second || usage.Duration < usage.TimeToFirstToken {
+		t.Fatalf("unexpected timing %v, %v", usage.TimeToFirstToken, usage.Duration)
+	}
+}
+
+func TestTimeStreamDrainsAfterCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan StreamChunk)
+	out := TimeStream(ctx, in, time.Now())
+	cancel()
+
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for range 3 {
+			in <- StreamChunk{Type: StreamChunkContent, Delta: "x"}
+		}
+		close(in)
+	}()
+
+	<-sent
+	for range out {
+	}
+}
@@ -0,0 +1,385 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DefaultVectorQueryTopK is the number of matches a VectorQuery returns when
+// TopK is zero.
+const DefaultVectorQueryTopK = 10
+
+// VectorStore stores embedded documents and finds the ones most similar to a
+// query, the retrieval step of retrieval-augmented generation.
+//
+// Implementations embed documents and queries that only have text with the
+// EmbeddingAdapter they were created with.
+type VectorStore interface {
+	// Upsert adds documents, replacing stored documents with the same ID.
+	Upsert(ctx context.Context, documents ...VectorDocument) error
+
+	// Query returns the stored documents most similar to query, most similar
+	// first.
+	Query(ctx context.Context, query *VectorQuery) ([]VectorMatch, error)
+
+	// Delete removes the documents with the given IDs. Unknown IDs are
+	// ignored.
+	Delete(ctx context.Context, ids ...string) error
+}
+
+// VectorDocument is a document in a VectorStore. Embedding may be left empty
+// when Text is set, in which case the store embeds Text.
+type VectorDocument struct {
+	ID        string
+	Text      string
+	Embedding []float64
+	Metadata  map[string]any
+}
+
+// VectorQuery selects documents by similarity to Embedding, or to Text when
+// Embedding is empty, among those matching Filter.
+type VectorQuery struct {
+	Embedding []float64
+	Text      string
+
+	// TopK is the maximum number of matches. Zero means
+	// DefaultVectorQueryTopK.
+	TopK int
+
+	// MinScore drops matches scoring below it. Scores are cosine
+	// similarities from -1 to 1.
+	MinScore *float64
+
+	// Filter restricts the query to documents whose metadata matches it. Nil
+	// matches every document.
+	Filter *Filter
+}
+
+// VectorMatch is a document found by a query and its similarity score.
+type VectorMatch struct {
+	VectorDocument
+	Score float64
+}
+
+// FilterOp is the operation of a Filter.
+type FilterOp string
+
+const (
+	FilterEq  FilterOp = "eq"
+	FilterNe  FilterOp = "ne"
+	FilterIn  FilterOp = "in"
+	FilterGt  FilterOp = "gt"
+	FilterGte FilterOp = "gte"
+	FilterLt  FilterOp = "lt"
+	FilterLte FilterOp = "lte"
+	FilterAnd FilterOp = "and"
+	FilterOr  FilterOp = "or"
+	FilterNot FilterOp = "not"
+)
+
+// Filter is a condition on document metadata. Build filters with Eq, Ne, In,
+// Gt, Gte, Lt, Lte, And, Or, and Not; stores translate them to their own
+// query language.
+//
+// Comparisons apply to the metadata value under Key. Numbers compare by
+// value whatever their Go type, and Gt, Gte, Lt, and Lte also order strings
+// byte-wise. A document without Key matches only Ne and negations.
+//
+// Stores may restrict string ranges to what their query language orders:
+// the qdrant store only accepts RFC 3339 timestamps, which it compares as
+// points in time.
+type Filter struct {
+	Op    FilterOp
+	Key   string
+	Value any
+
+	// Values holds the candidates of FilterIn.
+	Values []any
+
+	// Filters holds the operands of FilterAnd, FilterOr, and FilterNot.
+	Filters []*Filter
+}
+
+// Eq matches documents whose metadata value for key equals value.
+func Eq(key string, value any) *Filter {
+	return &Filter{Op: FilterEq, Key: key, Value: value}
+}
+
+// Ne matches documents whose metadata value for key is missing or differs
+// from value.
+func Ne(key string, value any) *Filter {
+	return &Filter{Op: FilterNe, Key: key, Value: value}
+}
+
+// In matches documents whose metadata value for key equals one of values.
+func In(key string, values ...any) *Filter {
+	return &Filter{Op: FilterIn, Key: key, Values: values}
+}
+
+// Gt matches documents whose metadata value for key is greater than value.
+func Gt(key string, value any) *Filter {
+	return &Filter{Op: FilterGt, Key: key, Value: value}
+}
+
+// Gte matches documents whose metadata value for key is at least value.
+func Gte(key string, value any) *Filter {
+	return &Filter{Op: FilterGte, Key: key, Value: value}
+}
+
+// Lt matches documents whose metadata value for key is less than value.
+func Lt(key string, value any) *Filter {
+	return &Filter{Op: FilterLt, Key: key, Value: value}
+}
+
+// Lte matches documents whose metadata value for key is at most value.
+func Lte(key string, value any) *Filter {
+	return &Filter{Op: FilterLte, Key: key, Value: value}
+}
+
+// And matches documents matching every filter.
+func And(filters ...*Filter) *Filter {
+	return &Filter{Op: FilterAnd, Filters: filters}
+}
+
+// Or matches documents matching at least one filter.
+func Or(filters ...*Filter) *Filter {
+	return &Filter{Op: FilterOr, Filters: filters}
+}
+
+// Not matches documents that do not match filter.
+func Not(filter *Filter) *Filter {
+	return &Filter{Op: FilterNot, Filters: []*Filter{filter}}
+}
+
+// Validate reports whether f and its operands are well formed.
+func (f *Filter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	switch f.Op {
+	case FilterEq, FilterNe, FilterIn, FilterGt, FilterGte, FilterLt, FilterLte:
+		if strings.TrimSpace(f.Key) == "" {
+			return fmt.Errorf("core: %s filter has no key", f.Op)
+		}
+		if f.Op == FilterIn && len(f.Values) == 0 {
+			return fmt.Errorf("core: in filter on %q has no values", f.Key)
+		}
+		return nil
+	case FilterAnd, FilterOr, FilterNot:
+		if len(f.Filters) == 0 || (f.Op == FilterNot && len(f.Filters) != 1) {
+			return fmt.Errorf("core: %s filter has %d operands", f.Op, len(f.Filters))
+		}
+		for _, operand := range f.Filters {
+			if operand == nil {
+				return fmt.Errorf("core: %s filter has a nil operand", f.Op)
+			}
+			if err := operand.Validate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("core: unsupported filter op %q", f.Op)
+}
+
+// Match reports whether metadata matches f. A nil filter matches everything.
+func (f *Filter) Match(metadata map[string]any) bool {
+	if f == nil {
+		return true
+	}
+
+	switch f.Op {
+	case FilterAnd:
+		for _, operand := range f.Filters {
+			if !operand.Match(metadata) {
+				return false
+			}
+		}
+		return true
+	case FilterOr:
+		for _, operand := range f.Filters {
+			if operand.Match(metadata) {
+				return true
+			}
+		}
+		return false
+	case FilterNot:
+		return len(f.Filters) == 1 && !f.Filters[0].Match(metadata)
+	}
+
+	value, ok := metadata[f.Key]
+	switch f.Op {
+	case FilterEq:
+		return ok && filterEqual(value, f.Value)
+	case FilterNe:
+		return !ok || !filterEqual(value, f.Value)
+	case FilterIn:
+		for _, candidate := range f.Values {
+			if ok && filterEqual(value, candidate) {
+				return true
+			}
+		}
+		return false
+	case FilterGt, FilterGte, FilterLt, FilterLte:
+		if !ok {
+			return false
+		}
+		cmp, comparable := filterCompare(value, f.Value)
+		if !comparable {
+			return false
+		}
+		switch f.Op {
+		case FilterGt:
+			return cmp > 0
+		case FilterGte:
+			return cmp >= 0
+		case FilterLt:
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	}
+	return false
+}
+
+func filterEqual(a, b any) bool {
+	if x, ok := FilterNumber(a); ok {
+		y, ok := FilterNumber(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func filterCompare(a, b any) (int, bool) {
+	if x, ok := FilterNumber(a); ok {
+		y, ok := FilterNumber(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+	x, ok := a.(string)
+	if !ok {
+		return 0, false
+	}
+	y, ok := b.(string)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
+
+// FilterNumber returns a numeric filter or metadata value of any Go number
+// type as a float64. It is meant for VectorStore implementations, so that
+// they compare numbers as Filter.Match does.
+func FilterNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// EmbedDocuments fills in the embeddings of documents that only have text,
+// with one EmbedMany call through adapter. It is meant for VectorStore
+// implementations.
+func EmbedDocuments(ctx context.Context, adapter EmbeddingAdapter, documents []VectorDocument) error {
+	var inputs []string
+	var indexes []int
+	for idx, document := range documents {
+		if strings.TrimSpace(document.ID) == "" {
+			return fmt.Errorf("core: document %d has no ID", idx)
+		}
+		if len(document.Embedding) > 0 {
+			continue
+		}
+		if document.Text == "" {
+			return fmt.Errorf("core: document %q has neither an embedding nor text", document.ID)
+		}
+		inputs = append(inputs, document.Text)
+		indexes = append(indexes, idx)
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+	if adapter == nil {
+		return fmt.Errorf("core: document %q has no embedding and the store has no embedding adapter", documents[indexes[0]].ID)
+	}
+
+	result, err := adapter.EmbedMany(ctx, &EmbedManyParams{Inputs: inputs})
+	if err != nil {
+		return err
+	}
+	if result == nil || len(result.Embeddings) != len(inputs) {
+		return fmt.Errorf("core: embedding adapter returned %d embeddings for %d documents", embeddingCount(result), len(inputs))
+	}
+	for i, idx := range indexes {
+		documents[idx].Embedding = result.Embeddings[i]
+	}
+	return nil
+}
+
+// QueryEmbedding returns the embedding of query, embedding its text through
+// adapter when it has no embedding. It is meant for VectorStore
+// implementations.
+func QueryEmbedding(ctx context.Context, adapter EmbeddingAdapter, query *VectorQuery) ([]float64, error) {
+	if query == nil {
+		return nil, errors.New("core: vector query is required")
+	}
+	if len(query.Embedding) > 0 {
+		return query.Embedding, nil
+	}
+	if query.Text == "" {
+		return nil, errors.New("core: vector query has neither an embedding nor text")
+	}
+	if adapter == nil {
+		return nil, errors.New("core: text query needs a store with an embedding adapter")
+	}
+
+	result, err := adapter.Embed(ctx, &EmbedParams{Input: query.Text})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || len(result.Embedding) == 0 {
+		return nil, errors.New("core: embedding adapter returned no embedding for the query")
+	}
+	return result.Embedding, nil
+}
+
+func embeddingCount(result *EmbedManyResult) int {
+	if result == nil {
+		return 0
+	}
+	return len(result.Embeddings)
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	metadata := map[string]any{"lang": "en", "year": 2024, "score": 0.5, "draft": false}
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{"nil", nil, true},
+		{"eq", Eq("lang", "en"), true},
+		{"eq number types", Eq("year", 2024.0), true},
+		{"eq missing", Eq("author", "x"), false},
+		{"ne", Ne("lang", "de"), true},
+		{"ne missing", Ne("author", "x"), true},
+		{"in", In("lang", "de", "en"), true},
+		{"in miss", In("lang", "de", "fr"), false},
+		{"gt", Gt("year", 2023), true},
+		{"gte", Gte("year", int64(2024)), true},
+		{"lt", Lt("score", 0.5), false},
+		{"lte", Lte("score", 0.5), true},
+		{"string order", Lt("lang", "fr"), true},
+		{"incomparable", Gt("lang", 1), false},
+		{"bool", Eq("draft", false), true},
+		{"and", And(Eq("lang", "en"), Gte("year", 2020)), true},
+		{"and miss", And(Eq("lang", "en"), Gte("year", 2025)), false},
+		{"or", Or(Eq("lang", "de"), Eq("draft", false)), true},
+		{"not", Not(Eq("lang", "en")), false},
+	}
+	for _, tc := range tests {
+		if err := tc.filter.Validate(); err != nil {
+			t.Fatalf("%s: Validate() error = %v", tc.name, err)
+		}
+		if got := tc.filter.Match(metadata); got != tc.want {
+			t.Fatalf("%s: Match() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	for _, invalid := range []*Filter{Eq("", 1), In("lang"), And(), Not(nil), {Op: "like", Key: "lang"}} {
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("Validate(%+v) expected error", invalid)
+		}
+	}
+}
+
+func TestMemoryVectorStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemoryVectorStore(nil)
+	err := store.Upsert(ctx,
+		VectorDocument{ID: "a", Text: "east", Embedding: []float64{1, 0}, Metadata: map[string]any{"lang": "en"}},
+		VectorDocument{ID: "b", Text: "north", Embedding: []float64{0, 1}, Metadata: map[string]any{"lang": "de"}},
+		VectorDocument{ID: "c", Text: "north-east", Embedding: []float64{1, 1}, Metadata: map[string]any{"lang": "en"}},
+	)
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+
+	matches, err := store.Query(ctx, &VectorQuery{Embedding: []float64{1, 0.1}, TopK: 2})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if ids := matchIDs(matches); !reflect.DeepEqual(ids, []string{"a", "c"}) || matches[0].Text != "east" || matches[0].Metadata["lang"] != "en" {
+		t.Fatalf("Query() = %+v", matches)
+	}
+
+	matches, err = store.Query(ctx, &VectorQuery{Embedding: []float64{1, 0}, Filter: Eq("lang", "de")})
+	if err != nil || !reflect.DeepEqual(matchIDs(matches), []string{"b"}) {
+		t.Fatalf("filtered Query() = %+v, %v", matches, err)
+	}
+
+	minScore := 0.5
+	matches, err = store.Query(ctx, &VectorQuery{Embedding: []float64{1, 0}, MinScore: &minScore})
+	if err != nil || !reflect.DeepEqual(matchIDs(matches), []string{"a", "c"}) {
+		t.Fatalf("Query(MinScore) = %+v, %v", matches, err)
+	}
+
+	// Returned documents are copies.
+	matches[0].Metadata["lang"] = "changed"
+	if err := store.Upsert(ctx, VectorDocument{ID: "a", Embedding: []float64{0, -1}}); err != nil {
+		t.Fatalf("replacing Upsert() error = %v", err)
+	}
+	if err := store.Delete(ctx, "b", "missing"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	matches, err = store.Query(ctx, &VectorQuery{Embedding: []float64{0, -1}})
+	if err != nil || !reflect.DeepEqual(matchIDs(matches), []string{"a", "c"}) || matches[0].Score != 1 || matches[0].Metadata != nil || store.Len() != 2 {
+		t.Fatalf("Query() after replace and delete = %+v, %v", matches, err)
+	}
+
+	if err := store.Upsert(ctx, VectorDocument{ID: "d", Embedding: []float64{1, 2, 3}}); err == nil || !strings.Contains(err.Error(), "3 dimensions") {
+		t.Fatalf("Upsert() dimension error = %v", err)
+	}
+	if _, err := store.Query(ctx, &VectorQuery{Embedding: []float64{1}}); err == nil {
+		t.Fatal("Query() expected dimension error")
+	}
+	if _, err := store.Query(ctx, &VectorQuery{Text: "north"}); err == nil || !strings.Contains(err.Error(), "embedding adapter") {
+		t.Fatalf("text Query() without adapter error = %v", err)
+	}
+	if err := store.Upsert(ctx, VectorDocument{Text: "no id"}); err == nil || !strings.Contains(err.Error(), "no ID") {
+		t.Fatalf("Upsert() without ID error = %v", err)
+	}
+}
+
+func TestMemoryVectorStoreEmbedsText(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	adapter := NewMockAdapter().ReplyEmbeddings([]float64{1, 0}, []float64{0, 1}, []float64{0.1, 1})
+	store := NewMemoryVectorStore(adapter)
+
+	if err := store.Upsert(ctx, VectorDocument{ID: "cats", Text: "Cats purr."}, VectorDocument{ID: "dogs", Text: "Dogs bark."}); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	matches, err := store.Query(ctx, &VectorQuery{Text: "Which animal barks?", TopK: 1})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if !reflect.DeepEqual(matchIDs(matches), []string{"dogs"}) || !reflect.DeepEqual(matches[0].Embedding, []float64{0, 1}) {
+		t.Fatalf("Query() = %+v", matches)
+	}
+	if want := []string{"Cats purr.", "Dogs bark.", "Which animal barks?"}; !reflect.DeepEqual(adapter.EmbedInputs(), want) {
+		t.Fatalf("embedded inputs = %q", adapter.EmbedInputs())
+	}
+}
+
+func matchIDs(matches []VectorMatch) []string {
+	ids := make([]string, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID
+	}
+	return ids
+}
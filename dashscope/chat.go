@@ -0,0 +1,147 @@
+package dashscope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+// Chat sends a non-streaming chat request to DashScope.
+//
+// It supports tool calls, structured output in JSON mode, and reasoning.
+// The reasoning_content of each response is reported on Reasoning. The
+// open-source Qwen3 models think by default and answer non-streaming
+// requests only with Thinking set to "false".
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().Chat(ctx, params)
+}
+
+// ChatStream sends a streaming chat request to DashScope.
+//
+// reasoning_content deltas stream as StreamChunkReasoning chunks before the
+// content. Server tools run between streamed turns, and structured output
+// streams as StreamChunkPartialJSON chunks. Usage is reported on the done
+// chunk.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().ChatStream(ctx, params)
+}
+
+// prepareRequest sets enable_thinking from ChatParams.Thinking, falling back
+// to ChatParams.ReasoningEffort, which DashScope does not accept itself.
+// Thinking accepts "true"/"enabled" or an effort level to enable thinking,
+// "false"/"disabled" to disable it, and a token count such as "4096" to
+// enable it with that thinking_budget.
+func prepareRequest(params *core.ChatParams, request *openaicompat.Request) error {
+	raw := strings.ToLower(strings.TrimSpace(params.Thinking))
+	if raw == "" {
+		raw = strings.ToLower(request.ReasoningEffort)
+	}
+	request.ReasoningEffort = ""
+
+	var enable bool
+	var budget int64
+	switch raw {
+	case "":
+		return nil
+	case "true", "enabled", "on", "minimal", "low", "medium", "high":
+		enable = true
+	case "false", "disabled", "off", "none":
+	default:
+		var err error
+		budget, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || budget <= 0 {
+			return fmt.Errorf("dashscope: unsupported thinking value %q", raw)
+		}
+		enable = true
+	}
+
+	if request.Extra == nil {
+		request.Extra = make(map[string]any, 2)
+	}
+	request.Extra["enable_thinking"] = enable
+	if budget > 0 {
+		request.Extra["thinking_budget"] = budget
+	}
+	return nil
+}
+
+// Embed creates an embedding vector for one input with a text-embedding
+// model, such as "text-embedding-v4". ModelOptions are sent as top-level
+// request fields.
+func (a *Adapter) Embed(ctx context.Context, params *core.EmbedParams) (*core.EmbedResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("dashscope: embed params are required")
+	}
+	input := strings.TrimSpace(params.Input)
+	if input == "" {
+		return nil, errors.New("dashscope: embed input is required")
+	}
+
+	vectors, usage, err := a.embed(ctx, []string{input}, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &core.EmbedResult{Embedding: vectors[0], Usage: usage}, nil
+}
+
+// EmbedMany creates one embedding vector per input. The text-embedding
+// models accept ten inputs per request, so EmbedMany sends larger inputs in
+// batches of ten and sums their usage.
+func (a *Adapter) EmbedMany(ctx context.Context, params *core.EmbedManyParams) (*core.EmbedManyResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("dashscope: embed many params are required")
+	}
+	if len(params.Inputs) == 0 {
+		return nil, errors.New("dashscope: embed many inputs are required")
+	}
+
+	inputs := make([]string, 0, len(params.Inputs))
+	for i, input := range params.Inputs {
+		trimmed := strings.TrimSpace(input)
+		if trimmed == "" {
+			return nil, fmt.Errorf("dashscope: embed many input at index %d is empty", i)
+		}
+		inputs = append(inputs, trimmed)
+	}
+
+	vectors, usage, err := a.embed(ctx, inputs, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &core.EmbedManyResult{Embeddings: vectors, Usage: usage}, nil
+}
+
+func (a *Adapter) embed(ctx context.Context, inputs []string, dimensions *int64, options map[string]any) ([][]float64, *core.Usage, error) {
+	compat := a.compat()
+	vectors := make([][]float64, 0, len(inputs))
+	usage := &core.Usage{}
+	for start := 0; start < len(inputs); start += embedBatchSize {
+		batch, batchUsage, err := compat.Embed(ctx, inputs[start:min(start+embedBatchSize, len(inputs))], dimensions, options)
+		if err != nil {
+			return nil, nil, err
+		}
+		vectors = append(vectors, batch...)
+		if batchUsage != nil {
+			usage.PromptTokens += batchUsage.PromptTokens
+			usage.TotalTokens += batchUsage.TotalTokens
+		}
+	}
+	return vectors, usage, nil
+}
@@ -0,0 +1,179 @@
+package dashscope
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatSetsEnableThinking(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		thinking string
+		effort   string
+		enable   any
+		budget   any
+	}{
+		{thinking: "", enable: nil, budget: nil},
+		{thinking: "false", enable: false, budget: nil},
+		{thinking: "enabled", enable: true, budget: nil},
+		{thinking: "4096", enable: true, budget: float64(4096)},
+		{effort: "high", enable: true, budget: nil},
+	}
+	for _, tt := range tests {
+		var body map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/chat/completions" || r.Header.Get("Authorization") != "Bearer sk-test" {
+				t.Errorf("unexpected request %s %q", r.URL.Path, r.Header.Get("Authorization"))
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			fmt.Fprint(w, `{"id":"c1","choices":[{"index":0,"message":{"role":"assistant","reasoning_content":"9.11 < 9.8.","content":"9.8 is larger."},"finish_reason":"stop"}],"usage":{"prompt_tokens":20,"completion_tokens":10,"total_tokens":30,"prompt_tokens_details":{"cached_tokens":16}}}`)
+		}))
+
+		result, err := New("qwen-plus", WithAPIKey("sk-test"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+			Messages:        []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Which is larger, 9.11 or 9.8?"}},
+			Thinking:        tt.thinking,
+			ReasoningEffort: tt.effort,
+		})
+		server.Close()
+		if err != nil {
+			t.Fatalf("Chat(%q, %q) error = %v", tt.thinking, tt.effort, err)
+		}
+
+		if body["enable_thinking"] != tt.enable || body["thinking_budget"] != tt.budget || body["reasoning_effort"] != nil {
+			t.Fatalf("Chat(%q, %q) sent %v", tt.thinking, tt.effort, body)
+		}
+		if result.Text != "9.8 is larger." || result.Reasoning != "9.11 < 9.8." || result.Usage.Details["cached_tokens"] != 16 {
+			t.Fatalf("unexpected result %+v", result)
+		}
+	}
+}
+
+func TestChatRejectsUnsupportedThinking(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("qwen-plus", WithAPIKey("sk-test"), WithBaseURL("http://127.0.0.1:0")).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		Thinking: "sometimes",
+	})
+	if err == nil || !strings.Contains(err.Error(), `dashscope: unsupported thinking value "sometimes"`) {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
+
+func TestChatStreamReportsReasoning(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["enable_thinking"] != true || body["stream_options"] == nil {
+			t.Errorf("unexpected request body %v", body)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"c2","choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"Compare decimals."}}]}
+
+data: {"id":"c2","choices":[{"index":0,"delta":{"content":"9.8"}}]}
+
+data: {"id":"c2","choices":[{"index":0,"delta":{"content":" is larger."},"finish_reason":"stop"}]}
+
+data: {"id":"c2","choices":[],"usage":{"prompt_tokens":20,"completion_tokens":12,"total_tokens":32,"completion_tokens_details":{"reasoning_tokens":4}}}
+
+data: [DONE]
+
+`)
+	}))
+	defer server.Close()
+
+	stream, err := New("qwen3-235b-a22b", WithAPIKey("sk-test"), WithBaseURL(server.URL)).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Which is larger, 9.11 or 9.8?"}},
+		Thinking: "true",
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var reasoning, content strings.Builder
+	var done core.StreamChunk
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkReasoning:
+			reasoning.WriteString(chunk.Delta)
+		case core.StreamChunkContent:
+			content.WriteString(chunk.Delta)
+		case core.StreamChunkDone:
+			done = chunk
+		case core.StreamChunkError:
+			t.Fatalf("stream error: %s", chunk.Error)
+		}
+	}
+	if reasoning.String() != "Compare decimals." || content.String() != "9.8 is larger." {
+		t.Fatalf("reasoning = %q, content = %q", reasoning.String(), content.String())
+	}
+	if done.Usage == nil || done.Usage.TotalTokens != 32 || done.Usage.ReasoningTokens != 4 {
+		t.Fatalf("done = %+v", done)
+	}
+}
+
+func TestEmbedManySendsBatchesOfTen(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var body struct {
+			Model      string   `json:"model"`
+			Input      []string `json:"input"`
+			Dimensions int64    `json:"dimensions"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "text-embedding-v4" || body.Dimensions != 2 || len(body.Input) > embedBatchSize {
+			t.Errorf("unexpected request body %+v", body)
+		}
+
+		data := make([]string, len(body.Input))
+		for i, input := range body.Input {
+			data[i] = fmt.Sprintf(`{"index":%d,"embedding":[%s,0.5]}`, i, strings.TrimPrefix(input, "doc "))
+		}
+		fmt.Fprintf(w, `{"data":[%s],"usage":{"prompt_tokens":%d,"total_tokens":%d}}`, strings.Join(data, ","), len(body.Input), len(body.Input))
+	}))
+	defer server.Close()
+
+	inputs := make([]string, 23)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("doc %d", i)
+	}
+	dimensions := int64(2)
+	result, err := New("text-embedding-v4", WithAPIKey("sk-test"), WithBaseURL(server.URL)).EmbedMany(context.Background(), &core.EmbedManyParams{
+		Inputs:     inputs,
+		Dimensions: &dimensions,
+	})
+	if err != nil {
+		t.Fatalf("EmbedMany() error = %v", err)
+	}
+
+	if requests.Load() != 3 || len(result.Embeddings) != 23 || result.Usage.PromptTokens != 23 || result.Usage.TotalTokens != 23 {
+		t.Fatalf("requests = %d, result = %+v", requests.Load(), result)
+	}
+	for i, vector := range result.Embeddings {
+		if vector[0] != float64(i) {
+			t.Fatalf("embedding %d = %v", i, vector)
+		}
+	}
+
+	single, err := New("text-embedding-v4", WithAPIKey("sk-test"), WithBaseURL(server.URL)).Embed(context.Background(), &core.EmbedParams{Input: "doc 7", Dimensions: &dimensions})
+	if err != nil || single.Embedding[0] != 7 {
+		t.Fatalf("Embed() = %+v, %v", single, err)
+	}
+}
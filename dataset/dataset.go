@@ -0,0 +1,271 @@
+// Package dataset turns chat traffic into JSONL datasets for fine-tuning and
+// evaluation.
+//
+// A Record pairs the ChatParams of a request with its ChatResult. Exporters
+// write records as JSONL lines: OpenAIExporter in the OpenAI fine-tuning chat
+// format, and JSONLExporter in a provider-neutral format that keeps the
+// request options, usage, and metadata. A Recorder wraps a TextAdapter and
+// exports every call that passes through it:
+//
+//	file, err := os.Create("traffic.jsonl")
+//	adapter := dataset.NewRecorder(openai.New("gpt-4o-mini"), dataset.NewOpenAIExporter(file))
+//	result, err := core.Chat(ctx, adapter, params) // also written to traffic.jsonl
+package dataset
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// Record is one chat request and its response.
+type Record struct {
+	Params *core.ChatParams
+	Result *core.ChatResult
+
+	// Time is when the request was sent. Zero omits it from JSONL records.
+	Time time.Time
+
+	// Metadata is written with JSONL records, such as a user or session ID.
+	Metadata map[string]any
+}
+
+// Conversation returns the messages of the request followed by the messages
+// of the response, including tool calls and results of an agentic loop.
+func (r Record) Conversation() []core.MessageUnion {
+	var input []core.MessageUnion
+	if r.Params != nil {
+		input = r.Params.Messages
+	}
+	return append(slices.Clip(input), r.Response()...)
+}
+
+// Response returns the messages of the response: the tool calls and results
+// of an agentic loop and the final assistant message.
+func (r Record) Response() []core.MessageUnion {
+	result := r.Result
+	if result == nil {
+		return nil
+	}
+	inputLen := 0
+	if r.Params != nil {
+		inputLen = len(r.Params.Messages)
+	}
+
+	var messages []core.MessageUnion
+	if len(result.Messages) > inputLen {
+		messages = slices.Clone(result.Messages[inputLen:])
+	}
+	last := lastAssistant(messages)
+	if len(result.ToolCalls) > 0 && !last.toolCalls {
+		messages = append(messages, core.ToolCallMessagePart{Role: core.RoleToolCall, ToolCalls: result.ToolCalls})
+	} else if strings.TrimSpace(result.Text) != "" && last.text != result.Text {
+		messages = append(messages, core.TextMessagePart{Role: core.RoleAssistant, Content: result.Text})
+	}
+	return messages
+}
+
+type assistantMessage struct {
+	text      string
+	toolCalls bool
+}
+
+// lastAssistant describes the last message when the model wrote it.
+func lastAssistant(messages []core.MessageUnion) assistantMessage {
+	if len(messages) == 0 {
+		return assistantMessage{}
+	}
+	switch message := messages[len(messages)-1].(type) {
+	case core.TextMessagePart:
+		if message.Role == core.RoleAssistant {
+			return assistantMessage{text: message.Content}
+		}
+	case *core.TextMessagePart:
+		if message != nil && message.Role == core.RoleAssistant {
+			return assistantMessage{text: message.Content}
+		}
+	case core.ToolCallMessagePart, *core.ToolCallMessagePart:
+		return assistantMessage{toolCalls: true}
+	}
+	return assistantMessage{}
+}
+
+// Exporter writes records to a dataset.
+type Exporter interface {
+	Export(record Record) error
+}
+
+// Recorder is a TextAdapter that exports every successful call to the
+// wrapped adapter. Streams are exported once they are done. A Recorder is
+// safe for concurrent use if its Exporter is; the exporters of this package
+// are.
+type Recorder struct {
+	Adapter  core.TextAdapter
+	Exporter Exporter
+
+	// Filter selects the records to export, such as a sample of the traffic
+	// or the calls of consenting users. Nil exports all records.
+	Filter func(ctx context.Context, record *Record) bool
+
+	// OnError is called when exporting a record fails. The call itself still
+	// succeeds. Nil ignores export errors.
+	OnError func(err error)
+}
+
+var _ core.TextAdapter = (*Recorder)(nil)
+
+// NewRecorder returns a Recorder that exports the calls to adapter.
+func NewRecorder(adapter core.TextAdapter, exporter Exporter) *Recorder {
+	return &Recorder{Adapter: adapter, Exporter: exporter}
+}
+
+// Chat implements core.TextAdapter.
+func (r *Recorder) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if r.Adapter == nil {
+		return nil, errors.New("dataset: adapter is required")
+	}
+	start := time.Now()
+	result, err := r.Adapter.Chat(ctx, params)
+	if err == nil && result != nil {
+		r.export(ctx, Record{Params: params, Result: result, Time: start})
+	}
+	return result, err
+}
+
+// ChatStream implements core.TextAdapter. The exported result is assembled
+// from the chunks.
+func (r *Recorder) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if r.Adapter == nil {
+		return nil, errors.New("dataset: adapter is required")
+	}
+	start := time.Now()
+	in, err := r.Adapter.ChatStream(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan core.StreamChunk)
+	go func() {
+		defer close(out)
+		assembler := newStreamAssembler(params)
+		failed := false
+		for chunk := range in {
+			assembler.add(chunk)
+			failed = failed || chunk.Type == core.StreamChunkError
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				// Keep draining so the adapter can finish, but stop forwarding.
+				for range in {
+				}
+				return
+			}
+		}
+		if !failed && assembler.done != nil {
+			r.export(ctx, Record{Params: params, Result: assembler.result(), Time: start})
+		}
+	}()
+	return out, nil
+}
+
+func (r *Recorder) export(ctx context.Context, record Record) {
+	if r.Exporter == nil {
+		return
+	}
+	if r.Filter != nil && !r.Filter(ctx, &record) {
+		return
+	}
+	if err := r.Exporter.Export(record); err != nil && r.OnError != nil {
+		r.OnError(err)
+	}
+}
+
+// streamAssembler rebuilds a ChatResult, including the messages of an
+// agentic loop, from stream chunks.
+type streamAssembler struct {
+	messages  []core.MessageUnion
+	text      strings.Builder
+	reasoning strings.Builder
+	calls     []core.ToolCall
+	names     map[string]string
+
+	// done is the StreamChunkDone chunk, nil until the stream completed.
+	done *core.StreamChunk
+}
+
+func newStreamAssembler(params *core.ChatParams) *streamAssembler {
+	assembler := &streamAssembler{names: make(map[string]string)}
+	if params != nil {
+		assembler.messages = slices.Clone(params.Messages)
+	}
+	return assembler
+}
+
+func (a *streamAssembler) add(chunk core.StreamChunk) {
+	switch chunk.Type {
+	case core.StreamChunkContent:
+		a.flushCalls()
+		a.text.WriteString(chunk.Delta)
+	case core.StreamChunkReasoning:
+		a.reasoning.WriteString(chunk.Delta)
+	case core.StreamChunkToolCall:
+		if chunk.ToolCall == nil {
+			return
+		}
+		a.flushText()
+		a.calls = append(a.calls, *chunk.ToolCall)
+		a.names[chunk.ToolCall.ID] = chunk.ToolCall.Name
+	case core.StreamChunkToolResult:
+		a.flushText()
+		a.flushCalls()
+		a.messages = append(a.messages, core.ToolResultMessagePart{
+			Role:       core.RoleToolResult,
+			ToolCallID: chunk.ToolCallID,
+			Name:       a.names[chunk.ToolCallID],
+			Content:    chunk.Content,
+		})
+	case core.StreamChunkDone:
+		a.done = &chunk
+	}
+}
+
+// flushText ends the assistant text before a tool call.
+func (a *streamAssembler) flushText() {
+	if a.text.Len() == 0 {
+		return
+	}
+	a.messages = append(a.messages, core.TextMessagePart{Role: core.RoleAssistant, Content: a.text.String()})
+	a.text.Reset()
+}
+
+// flushCalls ends a turn of tool calls once their results or more text
+// arrive.
+func (a *streamAssembler) flushCalls() {
+	if len(a.calls) == 0 {
+		return
+	}
+	a.messages = append(a.messages, core.ToolCallMessagePart{Role: core.RoleToolCall, ToolCalls: a.calls})
+	a.calls = nil
+}
+
+func (a *streamAssembler) result() *core.ChatResult {
+	result := &core.ChatResult{
+		Text:      a.text.String(),
+		Reasoning: a.reasoning.String(),
+		ToolCalls: a.calls,
+	}
+	if a.done != nil {
+		result.FinishReason = a.done.FinishReason
+		result.RawFinishReason = a.done.RawFinishReason
+		result.Usage = a.done.Usage
+		result.RequestID = a.done.RequestID
+	}
+	a.flushText()
+	a.flushCalls()
+	result.Messages = a.messages
+	return result
+}
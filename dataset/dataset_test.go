@@ -0,0 +1,232 @@
+package dataset
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func decodeLines(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var value map[string]any
+		if err := json.Unmarshal([]byte(line), &value); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		out = append(out, value)
+	}
+	return out
+}
+
+func roundTrip(v any) any {
+	data, _ := json.Marshal(v)
+	var out any
+	_ = json.Unmarshal(data, &out)
+	return out
+}
+
+// toolLoop runs a chat with a server tool through a mock adapter and
+// returns the record of it.
+func toolLoop(t *testing.T) Record {
+	t.Helper()
+	adapter := core.NewMockAdapter().
+		ReplyToolCalls(core.ToolCall{Name: "get_weather", Arguments: map[string]any{"city": "Paris"}}).
+		Reply("It is sunny in Paris.")
+	params := &core.ChatParams{
+		SystemPrompts: []string{"You are a weather bot."},
+		Messages:      []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather in Paris?"}},
+		Tools: []core.ToolUnion{core.ServerTool{
+			Name:        "get_weather",
+			Description: "Current weather",
+			Parameters:  map[string]any{"type": "object"},
+			Handler:     func(any) (string, error) { return "sunny", nil },
+		}},
+	}
+	result, err := core.Chat(context.Background(), adapter, params)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	return Record{Params: params, Result: result}
+}
+
+func TestOpenAIExporter(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	exporter := NewOpenAIExporter(&out)
+	if err := exporter.Export(toolLoop(t)); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := roundTrip(map[string]any{
+		"messages": []any{
+			map[string]any{"role": "system", "content": "You are a weather bot."},
+			map[string]any{"role": "user", "content": "Weather in Paris?"},
+			map[string]any{"role": "assistant", "tool_calls": []any{map[string]any{
+				"id": "call_1", "type": "function", "function": map[string]any{"name": "get_weather", "arguments": `{"city":"Paris"}`},
+			}}},
+			map[string]any{"role": "tool", "tool_call_id": "call_1", "content": "sunny"},
+			map[string]any{"role": "assistant", "content": "It is sunny in Paris."},
+		},
+		"tools": []any{map[string]any{"type": "function", "function": map[string]any{
+			"name": "get_weather", "description": "Current weather", "parameters": map[string]any{"type": "object"},
+		}}},
+	})
+	if got := decodeLines(t, out.Bytes()); !reflect.DeepEqual(got[0], want) {
+		t.Fatalf("example = %s", out.String())
+	}
+}
+
+func TestOpenAIExporterContent(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	exporter := NewOpenAIExporter(&out)
+	record := Record{
+		Params: &core.ChatParams{Messages: []core.MessageUnion{core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+			core.TextPart{Text: "What is this?"},
+			core.ImagePart{Source: core.DataSource{Data: "aGVsbG8=", MimeType: "image/png"}, Metadata: map[string]any{"detail": "low"}},
+		}}}},
+		Result: &core.ChatResult{Text: "A cat."},
+	}
+	if err := exporter.Export(record); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	messages := decodeLines(t, out.Bytes())[0]["messages"].([]any)
+	parts := messages[0].(map[string]any)["content"].([]any)
+	if image := parts[1].(map[string]any)["image_url"]; !reflect.DeepEqual(image, map[string]any{"url": "data:image/png;base64,aGVsbG8=", "detail": "low"}) {
+		t.Fatalf("image = %#v", image)
+	}
+	if last := messages[1].(map[string]any); last["role"] != "assistant" || last["content"] != "A cat." {
+		t.Fatalf("assistant message = %#v", last)
+	}
+
+	audio := Record{
+		Params: &core.ChatParams{Messages: []core.MessageUnion{core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{core.AudioPart{Source: core.DataSource{Data: "AA==", MimeType: "audio/wav"}}}}}},
+		Result: &core.ChatResult{Text: "Hello."},
+	}
+	if err := exporter.Export(audio); err == nil || !strings.Contains(err.Error(), "text and image content only") {
+		t.Fatalf("Export() audio error = %v", err)
+	}
+	if err := exporter.Export(Record{Params: record.Params, Result: &core.ChatResult{}}); err == nil {
+		t.Fatal("Export() expected error for a record without a response")
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != 1 {
+		t.Fatalf("wrote %d lines", lines)
+	}
+}
+
+func TestJSONLExporter(t *testing.T) {
+	t.Parallel()
+
+	record := toolLoop(t)
+	temperature := 0.2
+	record.Params.Temperature = &temperature
+	record.Result.Usage = &core.Usage{PromptTokens: 12, CompletionTokens: 8, TotalTokens: 20, Duration: 1500 * time.Millisecond}
+	record.Time = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	record.Metadata = map[string]any{"session": "s-1"}
+
+	var out bytes.Buffer
+	if err := NewJSONLExporter(&out).Export(record); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	line := decodeLines(t, out.Bytes())[0]
+
+	want := roundTrip(map[string]any{
+		"time":     "2026-01-02T03:04:05Z",
+		"system":   []string{"You are a weather bot."},
+		"messages": []any{map[string]any{"role": "user", "content": "Weather in Paris?"}},
+		"tools":    []any{map[string]any{"type": "function", "name": "get_weather", "description": "Current weather", "parameters": map[string]any{"type": "object"}}},
+		"options":  map[string]any{"temperature": 0.2},
+		"response": map[string]any{
+			"text":          "It is sunny in Paris.",
+			"finish_reason": "stop",
+			"messages": []any{
+				map[string]any{"role": "assistant", "tool_calls": []any{map[string]any{"id": "call_1", "name": "get_weather", "arguments": map[string]any{"city": "Paris"}}}},
+				map[string]any{"role": "tool", "name": "get_weather", "tool_call_id": "call_1", "content": "sunny"},
+				map[string]any{"role": "assistant", "content": "It is sunny in Paris."},
+			},
+		},
+		"usage":    map[string]any{"prompt_tokens": 12, "completion_tokens": 8, "total_tokens": 20, "duration_ms": 1500},
+		"metadata": map[string]any{"session": "s-1"},
+	})
+	if !reflect.DeepEqual(any(line), want) {
+		t.Fatalf("record = %s", out.String())
+	}
+}
+
+type failingExporter struct{}
+
+func (failingExporter) Export(Record) error { return errors.New("disk full") }
+
+func TestRecorderChat(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	recorder := NewRecorder(core.NewMockAdapter().Reply("Hi!").Reply("Skipped.").ReplyError(errors.New("boom")), NewJSONLExporter(&out))
+	recorder.Filter = func(_ context.Context, record *Record) bool {
+		record.Metadata = map[string]any{"kept": true}
+		return record.Result.Text != "Skipped."
+	}
+
+	for range 3 {
+		_, _ = core.Chat(context.Background(), recorder, &core.ChatParams{Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hello"}}})
+	}
+	lines := decodeLines(t, out.Bytes())
+	if len(lines) != 1 || lines[0]["response"].(map[string]any)["text"] != "Hi!" || lines[0]["time"] == nil || lines[0]["metadata"] == nil {
+		t.Fatalf("lines = %s", out.String())
+	}
+
+	var exportErr error
+	failing := NewRecorder(core.NewMockAdapter().Reply("Hi!"), failingExporter{})
+	failing.OnError = func(err error) { exportErr = err }
+	if _, err := failing.Chat(context.Background(), &core.ChatParams{}); err != nil || exportErr == nil {
+		t.Fatalf("Chat() error = %v, export error = %v", err, exportErr)
+	}
+}
+
+func TestRecorderChatStream(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	adapter := core.NewMockAdapter().ReplyStream(
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "Let me check. "}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &core.ToolCall{ID: "call_9", Name: "lookup", Arguments: map[string]any{"q": "x"}}}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkToolResult, ToolCallID: "call_9", Content: "42"}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "The answer "}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "is 42."}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkDone, FinishReason: core.FinishReasonStop, Usage: &core.Usage{TotalTokens: 7}}},
+	)
+	recorder := NewRecorder(adapter, NewOpenAIExporter(&out))
+
+	stream, err := core.ChatStream(context.Background(), recorder, &core.ChatParams{Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Answer?"}}})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	var text strings.Builder
+	for chunk := range stream {
+		text.WriteString(chunk.Delta)
+	}
+	if text.String() != "Let me check. The answer is 42." {
+		t.Fatalf("streamed text = %q", text.String())
+	}
+
+	want := roundTrip([]any{
+		map[string]any{"role": "user", "content": "Answer?"},
+		map[string]any{"role": "assistant", "content": "Let me check. "},
+		map[string]any{"role": "assistant", "tool_calls": []any{map[string]any{"id": "call_9", "type": "function", "function": map[string]any{"name": "lookup", "arguments": `{"q":"x"}`}}}},
+		map[string]any{"role": "tool", "tool_call_id": "call_9", "content": "42"},
+		map[string]any{"role": "assistant", "content": "The answer is 42."},
+	})
+	if got := decodeLines(t, out.Bytes())[0]["messages"]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("messages = %s", out.String())
+	}
+}
@@ -0,0 +1,356 @@
+package dataset
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// JSONLExporter writes records in a provider-neutral JSONL format: the
+// request messages under "messages", the generated messages and final text
+// under "response", and the request options, usage, and metadata alongside.
+// Input and expected output are kept apart, so records can serve as
+// evaluation cases as well as training data.
+//
+// A JSONLExporter is safe for concurrent use.
+type JSONLExporter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+var _ Exporter = (*JSONLExporter)(nil)
+
+// NewJSONLExporter returns a JSONLExporter that writes to w.
+func NewJSONLExporter(w io.Writer) *JSONLExporter {
+	return &JSONLExporter{encoder: newEncoder(w)}
+}
+
+type jsonlRecord struct {
+	Time     *time.Time     `json:"time,omitempty"`
+	System   []string       `json:"system,omitempty"`
+	Messages []jsonlMessage `json:"messages"`
+	Tools    []jsonlTool    `json:"tools,omitempty"`
+	Options  *jsonlOptions  `json:"options,omitempty"`
+	Response jsonlResponse  `json:"response"`
+	Usage    *jsonlUsage    `json:"usage,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Schema   *jsonlSchema   `json:"output_schema,omitempty"`
+}
+
+type jsonlMessage struct {
+	Role       string          `json:"role"`
+	Content    string          `json:"content,omitempty"`
+	Parts      []jsonlPart     `json:"parts,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	ToolCalls  []jsonlToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+type jsonlPart struct {
+	Type     string   `json:"type"`
+	Text     string   `json:"text,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	MimeType string   `json:"mime_type,omitempty"`
+	Data     string   `json:"data,omitempty"`
+	FileID   string   `json:"file_id,omitempty"`
+	Filename string   `json:"filename,omitempty"`
+	Source   string   `json:"source,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	Texts    []string `json:"texts,omitempty"`
+}
+
+type jsonlToolCall struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Arguments any    `json:"arguments,omitempty"`
+}
+
+type jsonlTool struct {
+	Type        string         `json:"type"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+type jsonlOptions struct {
+	MaxTokens       *int64         `json:"max_tokens,omitempty"`
+	Temperature     *float64       `json:"temperature,omitempty"`
+	TopP            *float64       `json:"top_p,omitempty"`
+	TopK            *int64         `json:"top_k,omitempty"`
+	Seed            *int64         `json:"seed,omitempty"`
+	StopSequences   []string       `json:"stop_sequences,omitempty"`
+	ReasoningEffort string         `json:"reasoning_effort,omitempty"`
+	ModelOptions    map[string]any `json:"model_options,omitempty"`
+}
+
+type jsonlSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+}
+
+type jsonlResponse struct {
+	Text         string         `json:"text,omitempty"`
+	Reasoning    string         `json:"reasoning,omitempty"`
+	Messages     []jsonlMessage `json:"messages,omitempty"`
+	FinishReason string         `json:"finish_reason,omitempty"`
+}
+
+type jsonlUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+	ReasoningTokens  int64 `json:"reasoning_tokens,omitempty"`
+	DurationMS       int64 `json:"duration_ms,omitempty"`
+}
+
+// Export implements Exporter.
+func (e *JSONLExporter) Export(record Record) error {
+	line, err := jsonlRecordFor(record)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.encoder.Encode(line); err != nil {
+		return fmt.Errorf("dataset: write record: %w", err)
+	}
+	return nil
+}
+
+func jsonlRecordFor(record Record) (*jsonlRecord, error) {
+	line := &jsonlRecord{Messages: []jsonlMessage{}, Metadata: record.Metadata}
+	if !record.Time.IsZero() {
+		at := record.Time.UTC()
+		line.Time = &at
+	}
+
+	if params := record.Params; params != nil {
+		line.System = params.SystemPrompts
+		for _, tool := range params.Tools {
+			if converted, ok := jsonlToolFor(tool); ok {
+				line.Tools = append(line.Tools, converted)
+			}
+		}
+		messages, err := jsonlMessagesFor(params.Messages)
+		if err != nil {
+			return nil, err
+		}
+		line.Messages = messages
+		line.Options = jsonlOptionsFor(params)
+		if params.Output != nil {
+			line.Schema = &jsonlSchema{Name: params.Output.Name, Schema: params.Output.Schema}
+		}
+	}
+
+	if result := record.Result; result != nil {
+		messages, err := jsonlMessagesFor(record.Response())
+		if err != nil {
+			return nil, err
+		}
+		line.Response = jsonlResponse{Text: result.Text, Reasoning: result.Reasoning, Messages: messages, FinishReason: string(result.FinishReason)}
+		if usage := result.Usage; usage != nil {
+			line.Usage = &jsonlUsage{
+				PromptTokens:     usage.PromptTokens,
+				CompletionTokens: usage.CompletionTokens,
+				TotalTokens:      usage.TotalTokens,
+				ReasoningTokens:  usage.ReasoningTokens,
+				DurationMS:       usage.Duration.Milliseconds(),
+			}
+		}
+	}
+	return line, nil
+}
+
+func jsonlOptionsFor(params *core.ChatParams) *jsonlOptions {
+	maxTokens := params.MaxTokens
+	if params.MaxOutputTokens != nil {
+		maxTokens = params.MaxOutputTokens
+	}
+	options := jsonlOptions{
+		MaxTokens:       maxTokens,
+		Temperature:     params.Temperature,
+		TopP:            params.TopP,
+		TopK:            params.TopK,
+		Seed:            params.Seed,
+		StopSequences:   params.StopSequences,
+		ReasoningEffort: params.ReasoningEffort,
+		ModelOptions:    params.ModelOptions,
+	}
+	if options.MaxTokens == nil && options.Temperature == nil && options.TopP == nil && options.TopK == nil && options.Seed == nil &&
+		len(options.StopSequences) == 0 && options.ReasoningEffort == "" && len(options.ModelOptions) == 0 {
+		return nil
+	}
+	return &options
+}
+
+func jsonlMessagesFor(messages []core.MessageUnion) ([]jsonlMessage, error) {
+	out := make([]jsonlMessage, 0, len(messages))
+	for idx, message := range messages {
+		converted, ok, err := jsonlMessageFor(message)
+		if err != nil {
+			return nil, fmt.Errorf("dataset: message %d: %w", idx, err)
+		}
+		if ok {
+			out = append(out, converted)
+		}
+	}
+	return out, nil
+}
+
+func jsonlMessageFor(message core.MessageUnion) (jsonlMessage, bool, error) {
+	switch typed := message.(type) {
+	case core.TextMessagePart:
+		return jsonlMessage{Role: typed.Role, Content: typed.Content, Name: typed.Name}, true, nil
+	case *core.TextMessagePart:
+		if typed != nil {
+			return jsonlMessageFor(*typed)
+		}
+		return jsonlMessage{}, false, nil
+
+	case core.ContentMessagePart:
+		parts := make([]jsonlPart, 0, len(typed.Parts))
+		for _, part := range typed.Parts {
+			converted, ok := jsonlPartFor(part)
+			if !ok {
+				return jsonlMessage{}, false, fmt.Errorf("unsupported content part type %T", part)
+			}
+			parts = append(parts, converted)
+		}
+		return jsonlMessage{Role: typed.Role, Parts: parts, Name: typed.Name}, true, nil
+	case *core.ContentMessagePart:
+		if typed != nil {
+			return jsonlMessageFor(*typed)
+		}
+		return jsonlMessage{}, false, nil
+
+	case core.ToolCallMessagePart:
+		calls := make([]jsonlToolCall, len(typed.ToolCalls))
+		for i, call := range typed.ToolCalls {
+			calls[i] = jsonlToolCall{ID: call.ID, Name: call.Name, Arguments: jsonArguments(call.Arguments)}
+		}
+		return jsonlMessage{Role: core.RoleAssistant, ToolCalls: calls}, true, nil
+	case *core.ToolCallMessagePart:
+		if typed != nil {
+			return jsonlMessageFor(*typed)
+		}
+		return jsonlMessage{}, false, nil
+
+	case core.ToolResultMessagePart:
+		return jsonlMessage{Role: "tool", Content: typed.Content, Name: typed.Name, ToolCallID: typed.ToolCallID}, true, nil
+	case *core.ToolResultMessagePart:
+		if typed != nil {
+			return jsonlMessageFor(*typed)
+		}
+		return jsonlMessage{}, false, nil
+	}
+	return jsonlMessage{}, false, fmt.Errorf("unsupported message type %T", message)
+}
+
+func jsonlPartFor(part core.ContentPart) (jsonlPart, bool) {
+	switch typed := part.(type) {
+	case core.TextPart:
+		return jsonlPart{Type: "text", Text: typed.Text}, true
+	case *core.TextPart:
+		if typed != nil {
+			return jsonlPartFor(*typed)
+		}
+	case core.ImagePart:
+		return withSource(jsonlPart{Type: "image"}, typed.Source), true
+	case *core.ImagePart:
+		if typed != nil {
+			return jsonlPartFor(*typed)
+		}
+	case core.AudioPart:
+		return withSource(jsonlPart{Type: "audio"}, typed.Source), true
+	case *core.AudioPart:
+		if typed != nil {
+			return jsonlPartFor(*typed)
+		}
+	case core.DocumentPart:
+		return withSource(jsonlPart{Type: "document"}, typed.Source), true
+	case *core.DocumentPart:
+		if typed != nil {
+			return jsonlPartFor(*typed)
+		}
+	case core.FilePart:
+		converted := jsonlPart{Type: "file", FileID: typed.FileID, Filename: typed.Filename, MimeType: typed.MimeType}
+		if typed.FileID == "" && len(typed.Data) > 0 {
+			converted.Data = base64.StdEncoding.EncodeToString(typed.Data)
+		}
+		return converted, true
+	case *core.FilePart:
+		if typed != nil {
+			return jsonlPartFor(*typed)
+		}
+	case core.SearchResultPart:
+		return jsonlPart{Type: "search_result", Source: typed.Source, Title: typed.Title, Texts: typed.Texts}, true
+	case *core.SearchResultPart:
+		if typed != nil {
+			return jsonlPartFor(*typed)
+		}
+	}
+	return jsonlPart{}, false
+}
+
+// withSource sets the URL or the inline base64 data of part from source.
+func withSource(part jsonlPart, source core.Source) jsonlPart {
+	switch typed := source.(type) {
+	case core.URLSource:
+		part.URL, part.MimeType = typed.URL, typed.MimeType
+	case *core.URLSource:
+		if typed != nil {
+			part.URL, part.MimeType = typed.URL, typed.MimeType
+		}
+	case core.DataSource:
+		part.Data, part.MimeType = typed.Data, typed.MimeType
+	case *core.DataSource:
+		if typed != nil {
+			part.Data, part.MimeType = typed.Data, typed.MimeType
+		}
+	}
+	return part
+}
+
+func jsonlToolFor(tool core.ToolUnion) (jsonlTool, bool) {
+	switch typed := tool.(type) {
+	case core.ProviderTool:
+		name := typed.Name
+		if name == "" {
+			name = typed.Type
+		}
+		return jsonlTool{Type: "provider", Name: name}, true
+	case *core.ProviderTool:
+		if typed != nil {
+			return jsonlToolFor(*typed)
+		}
+		return jsonlTool{}, false
+	}
+	function, ok := functionFor(tool)
+	if !ok {
+		return jsonlTool{}, false
+	}
+	return jsonlTool{Type: "function", Name: function.Name, Description: function.Description, Parameters: function.Parameters}, true
+}
+
+// jsonArguments returns tool call arguments as a JSON value, decoding
+// arguments given as a JSON string.
+func jsonArguments(arguments any) any {
+	switch typed := arguments.(type) {
+	case string:
+		if json.Valid([]byte(typed)) {
+			return json.RawMessage(typed)
+		}
+	case json.RawMessage:
+		if json.Valid(typed) {
+			return typed
+		}
+		return string(typed)
+	}
+	return arguments
+}
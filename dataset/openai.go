@@ -0,0 +1,265 @@
+package dataset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// OpenAIExporter writes records in the OpenAI fine-tuning chat format, one
+// {"messages": [...], "tools": [...]} object per line. System prompts become
+// system messages, and the response, including the tool calls of an agentic
+// loop, becomes the assistant messages to learn from.
+//
+// The format has text and image content only; Export rejects records with
+// other content parts. An OpenAIExporter is safe for concurrent use.
+type OpenAIExporter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+var _ Exporter = (*OpenAIExporter)(nil)
+
+// NewOpenAIExporter returns an OpenAIExporter that writes to w.
+func NewOpenAIExporter(w io.Writer) *OpenAIExporter {
+	return &OpenAIExporter{encoder: newEncoder(w)}
+}
+
+type openAIExample struct {
+	Messages          []openAIMessage `json:"messages"`
+	Tools             []openAITool    `json:"tools,omitempty"`
+	ParallelToolCalls *bool           `json:"parallel_tool_calls,omitempty"`
+}
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    any              `json:"content,omitempty"`
+	Name       string           `json:"name,omitempty"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAIContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string             `json:"id"`
+	Type     string             `json:"type"`
+	Function openAIFunctionCall `json:"function"`
+}
+
+type openAIFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type openAITool struct {
+	Type     string         `json:"type"`
+	Function openAIFunction `json:"function"`
+}
+
+type openAIFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+// Export implements Exporter. Records without an assistant response are
+// rejected, since there is nothing to learn from them.
+func (e *OpenAIExporter) Export(record Record) error {
+	example, err := openAIExampleFor(record)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.encoder.Encode(example); err != nil {
+		return fmt.Errorf("dataset: write record: %w", err)
+	}
+	return nil
+}
+
+func openAIExampleFor(record Record) (*openAIExample, error) {
+	if len(record.Response()) == 0 {
+		return nil, errors.New("dataset: record has no assistant response")
+	}
+
+	example := &openAIExample{}
+	if params := record.Params; params != nil {
+		for _, prompt := range params.SystemPrompts {
+			if strings.TrimSpace(prompt) != "" {
+				example.Messages = append(example.Messages, openAIMessage{Role: core.RoleSystem, Content: prompt})
+			}
+		}
+		for _, tool := range params.Tools {
+			if function, ok := functionFor(tool); ok {
+				example.Tools = append(example.Tools, openAITool{Type: "function", Function: function})
+			}
+		}
+		example.ParallelToolCalls = params.ParallelToolCalls
+	}
+
+	for idx, message := range record.Conversation() {
+		converted, err := openAIMessageFor(message)
+		if err != nil {
+			return nil, fmt.Errorf("dataset: message %d: %w", idx, err)
+		}
+		if converted != nil {
+			example.Messages = append(example.Messages, *converted)
+		}
+	}
+	return example, nil
+}
+
+func openAIMessageFor(message core.MessageUnion) (*openAIMessage, error) {
+	switch typed := message.(type) {
+	case core.TextMessagePart:
+		return &openAIMessage{Role: typed.Role, Content: typed.Content, Name: typed.Name}, nil
+	case *core.TextMessagePart:
+		if typed == nil {
+			return nil, nil
+		}
+		return openAIMessageFor(*typed)
+
+	case core.ContentMessagePart:
+		parts := make([]openAIContentPart, 0, len(typed.Parts))
+		for _, part := range typed.Parts {
+			converted, err := openAIPartFor(part)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, converted)
+		}
+		return &openAIMessage{Role: typed.Role, Content: parts, Name: typed.Name}, nil
+	case *core.ContentMessagePart:
+		if typed == nil {
+			return nil, nil
+		}
+		return openAIMessageFor(*typed)
+
+	case core.ToolCallMessagePart:
+		calls := make([]openAIToolCall, len(typed.ToolCalls))
+		for i, call := range typed.ToolCalls {
+			calls[i] = openAIToolCall{ID: call.ID, Type: "function", Function: openAIFunctionCall{Name: call.Name, Arguments: argumentsJSON(call.Arguments)}}
+		}
+		return &openAIMessage{Role: core.RoleAssistant, ToolCalls: calls}, nil
+	case *core.ToolCallMessagePart:
+		if typed == nil {
+			return nil, nil
+		}
+		return openAIMessageFor(*typed)
+
+	case core.ToolResultMessagePart:
+		return &openAIMessage{Role: "tool", Content: typed.Content, ToolCallID: typed.ToolCallID}, nil
+	case *core.ToolResultMessagePart:
+		if typed == nil {
+			return nil, nil
+		}
+		return openAIMessageFor(*typed)
+	}
+	return nil, fmt.Errorf("unsupported message type %T", message)
+}
+
+func openAIPartFor(part core.ContentPart) (openAIContentPart, error) {
+	switch typed := part.(type) {
+	case core.TextPart:
+		return openAIContentPart{Type: "text", Text: typed.Text}, nil
+	case *core.TextPart:
+		if typed != nil {
+			return openAIPartFor(*typed)
+		}
+	case core.ImagePart:
+		url, err := sourceURL(typed.Source)
+		if err != nil {
+			return openAIContentPart{}, err
+		}
+		detail, _ := typed.Metadata["detail"].(string)
+		return openAIContentPart{Type: "image_url", ImageURL: &openAIImageURL{URL: url, Detail: detail}}, nil
+	case *core.ImagePart:
+		if typed != nil {
+			return openAIPartFor(*typed)
+		}
+	}
+	return openAIContentPart{}, fmt.Errorf("OpenAI fine-tuning supports text and image content only, got %T", part)
+}
+
+// sourceURL returns the URL of source, as a data URL for inline data.
+func sourceURL(source core.Source) (string, error) {
+	switch typed := source.(type) {
+	case core.URLSource:
+		return typed.URL, nil
+	case *core.URLSource:
+		if typed != nil {
+			return typed.URL, nil
+		}
+	case core.DataSource:
+		return "data:" + typed.MimeType + ";base64," + typed.Data, nil
+	case *core.DataSource:
+		if typed != nil {
+			return sourceURL(*typed)
+		}
+	}
+	return "", fmt.Errorf("unsupported source type %T", source)
+}
+
+// functionFor returns the function definition of a client or server tool.
+// Provider tools have none.
+func functionFor(tool core.ToolUnion) (openAIFunction, bool) {
+	switch typed := tool.(type) {
+	case core.ClientTool:
+		return openAIFunction{Name: typed.Name, Description: typed.Description, Parameters: typed.Parameters}, true
+	case *core.ClientTool:
+		if typed != nil {
+			return functionFor(*typed)
+		}
+	case core.ServerTool:
+		return openAIFunction{Name: typed.Name, Description: typed.Description, Parameters: typed.Parameters}, true
+	case *core.ServerTool:
+		if typed != nil {
+			return functionFor(*typed)
+		}
+	}
+	return openAIFunction{}, false
+}
+
+// argumentsJSON returns tool call arguments as a JSON object string.
+func argumentsJSON(arguments any) string {
+	switch typed := arguments.(type) {
+	case nil:
+		return "{}"
+	case string:
+		if strings.TrimSpace(typed) == "" {
+			return "{}"
+		}
+		return typed
+	case json.RawMessage:
+		return string(typed)
+	}
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// newEncoder returns an encoder that writes one JSON value per line, leaving
+// HTML characters in prompts unescaped.
+func newEncoder(w io.Writer) *json.Encoder {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder
+}
@@ -0,0 +1,178 @@
+// Package deepseek is an adapter for the DeepSeek chat API, which serves the
+// OpenAI Chat Completions format.
+//
+// The reasoning_content of reasoning models is reported as reasoning in both
+// Chat and ChatStream, and sent back within a tool loop as thinking mode
+// requires. The context cache hits and misses DeepSeek reports are added to
+// core.Usage.Details.
+package deepseek
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+const (
+	providerName       = "deepseek"
+	defaultBaseURL     = "https://api.deepseek.com"
+	defaultHTTPTimeout = 5 * time.Minute
+	envDeepSeekAPIKey  = "DEEPSEEK_API_KEY"
+)
+
+type Adapter struct {
+	APIKey  string
+	Model   string
+	BaseURL string
+
+	HTTPClient *http.Client
+}
+
+var _ core.TextAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
+
+type Option func(*Adapter)
+
+// New creates a DeepSeek adapter.
+//
+// Preferred usage is to use core and add this adapter there.
+//
+// If no API key is provided via options, New reads DEEPSEEK_API_KEY.
+func New(model string, opts ...Option) *Adapter {
+	adapter := &Adapter{
+		APIKey:     strings.TrimSpace(os.Getenv(envDeepSeekAPIKey)),
+		Model:      strings.TrimSpace(model),
+		BaseURL:    defaultBaseURL,
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(adapter)
+	}
+
+	return adapter
+}
+
+// WithAPIKey sets the API key used by the adapter.
+func WithAPIKey(apiKey string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(apiKey) == "" {
+			return
+		}
+		adapter.APIKey = strings.TrimSpace(apiKey)
+	}
+}
+
+// WithBaseURL sets the API base URL used by the adapter.
+func WithBaseURL(baseURL string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(baseURL) == "" {
+			return
+		}
+		adapter.BaseURL = strings.TrimSpace(baseURL)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the adapter.
+func WithHTTPClient(client *http.Client) Option {
+	return func(adapter *Adapter) {
+		if client == nil {
+			return
+		}
+		adapter.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the timeout on the adapter HTTP client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(adapter *Adapter) {
+		if timeout <= 0 {
+			return
+		}
+		if adapter.HTTPClient == nil {
+			adapter.HTTPClient = &http.Client{}
+		}
+		adapter.HTTPClient.Timeout = timeout
+	}
+}
+
+// Capabilities reports the chat features of the DeepSeek API. Structured
+// output uses JSON mode with the schema in a system message.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:              true,
+		StructuredOutput:   true,
+		StreamingWithTools: true,
+		Reasoning:          true,
+	}
+}
+
+func (a *Adapter) validate() error {
+	if a == nil {
+		return errors.New("deepseek: adapter is nil")
+	}
+
+	if strings.TrimSpace(a.APIKey) == "" {
+		a.APIKey = strings.TrimSpace(os.Getenv(envDeepSeekAPIKey))
+	}
+	if strings.TrimSpace(a.APIKey) == "" {
+		return errors.New("deepseek: API key is required (set DEEPSEEK_API_KEY or use deepseek.WithAPIKey)")
+	}
+
+	if strings.TrimSpace(a.Model) == "" {
+		return errors.New("deepseek: model is required")
+	}
+
+	return nil
+}
+
+// compat returns the Chat Completions client for the adapter.
+func (a *Adapter) compat() *openaicompat.Client {
+	return &openaicompat.Client{
+		Provider:         providerName,
+		Model:            a.Model,
+		Transport:        a.transport(),
+		StreamUsage:      true,
+		JSONObjectOutput: true,
+		ReplayReasoning:  true,
+		PrepareRequest:   prepareRequest,
+		Usage:            cacheUsage,
+	}
+}
+
+func (a *Adapter) client() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// transport returns the client that sends API requests.
+func (a *Adapter) transport() *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   providerName,
+		Header: func(header http.Header) {
+			header.Set("Authorization", "Bearer "+a.APIKey)
+			header.Set("Accept", "application/json")
+		},
+		DecodeError: openaicompat.DecodeError(providerName),
+	}
+}
+
+func (a *Adapter) baseURL() string {
+	if strings.TrimSpace(a.BaseURL) == "" {
+		return defaultBaseURL
+	}
+	return a.BaseURL
+}
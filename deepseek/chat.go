@@ -0,0 +1,89 @@
+package deepseek
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+// Chat sends a non-streaming chat request to DeepSeek.
+//
+// It supports tool calls, structured output in JSON mode, and reasoning.
+// The reasoning_content of each response is reported on Reasoning.
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().Chat(ctx, params)
+}
+
+// ChatStream sends a streaming chat request to DeepSeek.
+//
+// reasoning_content deltas stream as StreamChunkReasoning chunks before the
+// content. Server tools run between streamed turns, and structured output
+// streams as StreamChunkPartialJSON chunks.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().ChatStream(ctx, params)
+}
+
+// prepareRequest sets thinking mode from ChatParams.Thinking, falling back
+// to ChatParams.ReasoningEffort, which DeepSeek does not accept itself.
+// Thinking accepts "true"/"enabled" or an effort level to enable it and
+// "false"/"disabled" to disable it.
+func prepareRequest(params *core.ChatParams, request *openaicompat.Request) error {
+	raw := strings.ToLower(strings.TrimSpace(params.Thinking))
+	if raw == "" {
+		raw = strings.ToLower(request.ReasoningEffort)
+	}
+	request.ReasoningEffort = ""
+
+	var mode string
+	switch raw {
+	case "":
+		return nil
+	case "true", "enabled", "on", "minimal", "low", "medium", "high":
+		mode = "enabled"
+	case "false", "disabled", "off", "none":
+		mode = "disabled"
+	default:
+		return fmt.Errorf("deepseek: unsupported thinking value %q", raw)
+	}
+
+	if request.Extra == nil {
+		request.Extra = make(map[string]any)
+	}
+	request.Extra["thinking"] = map[string]any{"type": mode}
+	return nil
+}
+
+// cacheUsage adds the context cache counts of a response or the last stream
+// chunk to usage as prompt_cache_hit_tokens and prompt_cache_miss_tokens.
+func cacheUsage(raw []byte, usage *core.Usage) *core.Usage {
+	if usage == nil {
+		return nil
+	}
+
+	var envelope struct {
+		Usage *struct {
+			PromptCacheHitTokens  int64 `json:"prompt_cache_hit_tokens"`
+			PromptCacheMissTokens int64 `json:"prompt_cache_miss_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil || envelope.Usage == nil {
+		return usage
+	}
+
+	if usage.Details == nil {
+		usage.Details = make(map[string]int64)
+	}
+	usage.Details["prompt_cache_hit_tokens"] = envelope.Usage.PromptCacheHitTokens
+	usage.Details["prompt_cache_miss_tokens"] = envelope.Usage.PromptCacheMissTokens
+	return usage
+}
@@ -0,0 +1,194 @@
+package deepseek
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatReportsReasoningAndCacheUsage(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("unexpected request %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if !reflect.DeepEqual(body["thinking"], map[string]any{"type": "enabled"}) || body["reasoning_effort"] != nil {
+			t.Errorf("unexpected thinking settings %v", body)
+		}
+		fmt.Fprint(w, `{"id":"c1","choices":[{"index":0,"message":{"role":"assistant","reasoning_content":"9.11 < 9.8.","content":"9.8 is larger."},"finish_reason":"stop"}],"usage":{"prompt_tokens":30,"completion_tokens":20,"total_tokens":50,"prompt_cache_hit_tokens":24,"prompt_cache_miss_tokens":6,"completion_tokens_details":{"reasoning_tokens":12}}}`)
+	}))
+	defer server.Close()
+
+	result, err := New("deepseek-chat", WithAPIKey("sk-test"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages:        []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Which is larger, 9.11 or 9.8?"}},
+		ReasoningEffort: "high",
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if result.Text != "9.8 is larger." || result.Reasoning != "9.11 < 9.8." {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	usage := result.Usage
+	if usage == nil || usage.ReasoningTokens != 12 || usage.Details["prompt_cache_hit_tokens"] != 24 || usage.Details["prompt_cache_miss_tokens"] != 6 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
+
+func TestChatSendsReasoningBackWithinToolLoop(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []map[string]any `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		if calls.Add(1) == 1 {
+			fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"","reasoning_content":"Check the date.","tool_calls":[{"id":"call_0","type":"function","function":{"name":"today","arguments":"{}"}}]},"finish_reason":"tool_calls"}]}`)
+			return
+		}
+		assistant := body.Messages[len(body.Messages)-2]
+		if assistant["reasoning_content"] != "Check the date." {
+			t.Errorf("reasoning_content was not sent back: %v", assistant)
+		}
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","reasoning_content":"Got it.","content":"It is Friday."},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	result, err := New("deepseek-reasoner", WithAPIKey("sk-test"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "What day is it?"}},
+		Tools: []core.ToolUnion{core.ServerTool{Name: "today", Handler: func(any) (string, error) {
+			return "Friday", nil
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if result.Text != "It is Friday." || result.Reasoning != "Check the date.\nGot it." {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	toolCall, ok := result.Messages[1].(core.ToolCallMessagePart)
+	if !ok || len(toolCall.ReasoningBlocks) != 1 || toolCall.ReasoningBlocks[0].Text != "Check the date." {
+		t.Fatalf("unexpected tool call message %#v", result.Messages[1])
+	}
+}
+
+func TestChatUsesJSONModeForStructuredOutput(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages       []map[string]any `json:"messages"`
+			ResponseFormat map[string]any   `json:"response_format"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.ResponseFormat["type"] != "json_object" {
+			t.Errorf("unexpected response format %v", body.ResponseFormat)
+		}
+		if len(body.Messages) != 3 || body.Messages[1]["role"] != "system" || !strings.Contains(body.Messages[1]["content"].(string), `"answer"`) {
+			t.Errorf("unexpected messages %v", body.Messages)
+		}
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"{\"answer\":42}"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	result, err := New("deepseek-chat", WithAPIKey("sk-test"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		SystemPrompts: []string{"You are a calculator."},
+		Messages:      []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "6 times 7"}},
+		Output: &core.Schema{Name: "result", Schema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"answer": map[string]any{"type": "integer"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Text != `{"answer":42}` {
+		t.Fatalf("unexpected text %q", result.Text)
+	}
+}
+
+func TestChatStreamEmitsReasoningContentFirst(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if !reflect.DeepEqual(body["stream_options"], map[string]any{"include_usage": true}) {
+			t.Errorf("unexpected stream options %v", body["stream_options"])
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"role":"assistant","content":null,"reasoning_content":"Hmm"}}],"usage":null}
+
+data: {"choices":[{"index":0,"delta":{"content":null,"reasoning_content":", easy."}}],"usage":null}
+
+data: {"choices":[{"index":0,"delta":{"content":"Four.","reasoning_content":null},"finish_reason":"stop"}],"usage":null}
+
+data: {"choices":[],"usage":{"prompt_tokens":8,"completion_tokens":6,"total_tokens":14,"prompt_cache_hit_tokens":0,"prompt_cache_miss_tokens":8}}
+
+data: [DONE]
+
+`)
+	}))
+	defer server.Close()
+
+	stream, err := New("deepseek-reasoner", WithAPIKey("sk-test"), WithBaseURL(server.URL)).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "2+2?"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var order []string
+	var done core.StreamChunk
+	reasoning := ""
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkError:
+			t.Fatalf("unexpected chunk error: %s", chunk.Error)
+		case core.StreamChunkReasoning:
+			reasoning = chunk.Reasoning
+		case core.StreamChunkDone:
+			done = chunk
+		}
+		order = append(order, chunk.Type)
+	}
+
+	expected := []string{core.StreamChunkReasoning, core.StreamChunkReasoning, core.StreamChunkContent, core.StreamChunkDone}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("unexpected chunk order: %#v", order)
+	}
+	if reasoning != "Hmm, easy." || done.Reasoning != "Hmm, easy." {
+		t.Fatalf("unexpected reasoning %q, %+v", reasoning, done)
+	}
+	if done.Usage == nil || done.Usage.TotalTokens != 14 || done.Usage.Details["prompt_cache_miss_tokens"] != 8 {
+		t.Fatalf("unexpected usage %+v", done.Usage)
+	}
+}
+
+func TestChatRejectsUnknownThinkingValue(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("deepseek-chat", WithAPIKey("sk-test"), WithBaseURL("http://127.0.0.1:0")).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		Thinking: "sometimes",
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported thinking value") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
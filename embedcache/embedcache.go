@@ -0,0 +1,286 @@
+// Package embedcache caches embeddings by content so that embedding the same
+// text again, such as unchanged chunks of a re-indexed document, costs no
+// provider call.
+//
+//	store, err := embedcache.NewFileStore(".cache/embeddings")
+//	adapter := embedcache.New(openai.New("text-embedding-3-small"),
+//		embedcache.WithStore(store),
+//	)
+//
+// The returned Adapter is a core.EmbeddingAdapter and can be used anywhere
+// the wrapped one is, for example by a core.VectorStore.
+package embedcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// Store holds embeddings by cache key. Keys are hex strings.
+//
+// The Adapter treats Store errors as misses, so a failing store slows
+// embedding down but never fails it.
+type Store interface {
+	Get(ctx context.Context, key string) ([]float64, bool, error)
+	Set(ctx context.Context, key string, embedding []float64) error
+}
+
+// Stats counts the inputs an Adapter has served.
+type Stats struct {
+	// Hits is the number of inputs answered without calling the wrapped
+	// adapter, including repeats within one EmbedMany call.
+	Hits int64
+
+	// Misses is the number of inputs sent to the wrapped adapter.
+	Misses int64
+
+	// StoreErrors is the number of failed Store reads and writes.
+	StoreErrors int64
+}
+
+// HitRate returns the share of inputs that were hits, or 0 before any input.
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Adapter is a core.EmbeddingAdapter that answers inputs from a Store and
+// embeds only the inputs it has not seen through the wrapped adapter. An
+// Adapter is safe for concurrent use if its Store is.
+type Adapter struct {
+	// Adapter embeds the inputs missing from the store.
+	Adapter core.EmbeddingAdapter
+
+	// Store holds the embeddings.
+	Store Store
+
+	// Namespace is part of every cache key, so that several models can share
+	// a persistent store. New defaults it to the type and model of the
+	// wrapped adapter, such as "openai.Adapter/text-embedding-3-small".
+	Namespace string
+
+	hits        atomic.Int64
+	misses      atomic.Int64
+	storeErrors atomic.Int64
+}
+
+var _ core.EmbeddingAdapter = (*Adapter)(nil)
+
+// Option configures an Adapter.
+type Option func(*Adapter)
+
+// New wraps adapter with a cache. Without WithStore, embeddings are kept in
+// an unbounded MemoryStore. Without WithNamespace, the namespace names the
+// type of adapter and the value of its Model field.
+func New(adapter core.EmbeddingAdapter, opts ...Option) *Adapter {
+	cached := &Adapter{Adapter: adapter}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(cached)
+	}
+	if cached.Store == nil {
+		cached.Store = NewMemoryStore(0)
+	}
+	if cached.Namespace == "" {
+		cached.Namespace = defaultNamespace(adapter)
+	}
+	return cached
+}
+
+// defaultNamespace names adapter by its type and, when it has a Model field,
+// its model.
+func defaultNamespace(adapter core.EmbeddingAdapter) string {
+	value := reflect.ValueOf(adapter)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return ""
+	}
+
+	namespace := value.Type().String()
+	if value.Kind() == reflect.Struct {
+		if model := value.FieldByName("Model"); model.IsValid() && model.Kind() == reflect.String && model.String() != "" {
+			namespace += "/" + model.String()
+		}
+	}
+	return namespace
+}
+
+// WithStore sets the store that holds the embeddings.
+func WithStore(store Store) Option {
+	return func(a *Adapter) {
+		a.Store = store
+	}
+}
+
+// WithNamespace sets the namespace that keeps the embeddings of different
+// models apart in a shared store, in place of the default from the wrapped
+// adapter. Set it when the adapter's type and model do not tell its models
+// apart, such as for a deployment name that is reused for another model.
+func WithNamespace(namespace string) Option {
+	return func(a *Adapter) {
+		if strings.TrimSpace(namespace) == "" {
+			return
+		}
+		a.Namespace = namespace
+	}
+}
+
+// Stats returns the hit and miss counts so far.
+func (a *Adapter) Stats() Stats {
+	return Stats{Hits: a.hits.Load(), Misses: a.misses.Load(), StoreErrors: a.storeErrors.Load()}
+}
+
+// Embed implements core.EmbeddingAdapter. Hits carry no usage.
+func (a *Adapter) Embed(ctx context.Context, params *core.EmbedParams) (*core.EmbedResult, error) {
+	if params == nil {
+		return nil, errors.New("embedcache: embed params are required")
+	}
+	if a.Adapter == nil {
+		return nil, errors.New("embedcache: adapter is required")
+	}
+
+	key, err := a.key(params.Input, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+	if embedding, ok := a.get(ctx, key); ok {
+		a.hits.Add(1)
+		return &core.EmbedResult{Embedding: embedding}, nil
+	}
+
+	a.misses.Add(1)
+	result, err := a.Adapter.Embed(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if result != nil && len(result.Embedding) > 0 {
+		a.set(ctx, key, result.Embedding)
+	}
+	return result, nil
+}
+
+// EmbedMany implements core.EmbeddingAdapter. It sends each distinct input
+// missing from the store once, in one call to the wrapped adapter, and
+// returns that call's usage.
+func (a *Adapter) EmbedMany(ctx context.Context, params *core.EmbedManyParams) (*core.EmbedManyResult, error) {
+	if params == nil {
+		return nil, errors.New("embedcache: embed many params are required")
+	}
+	if a.Adapter == nil {
+		return nil, errors.New("embedcache: adapter is required")
+	}
+
+	embeddings := make([][]float64, len(params.Inputs))
+	var missing, missingKeys []string
+	pending := make(map[string][]int)
+	for idx, input := range params.Inputs {
+		key, err := a.key(input, params.Dimensions, params.ModelOptions)
+		if err != nil {
+			return nil, err
+		}
+		if indexes, ok := pending[key]; ok {
+			pending[key] = append(indexes, idx)
+			continue
+		}
+		if embedding, ok := a.get(ctx, key); ok {
+			embeddings[idx] = embedding
+			continue
+		}
+		pending[key] = []int{idx}
+		missing = append(missing, input)
+		missingKeys = append(missingKeys, key)
+	}
+
+	var usage *core.Usage
+	if len(missing) > 0 {
+		request := *params
+		request.Inputs = missing
+		result, err := a.Adapter.EmbedMany(ctx, &request)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil || len(result.Embeddings) != len(missing) {
+			count := 0
+			if result != nil {
+				count = len(result.Embeddings)
+			}
+			return nil, fmt.Errorf("embedcache: adapter returned %d embeddings for %d inputs", count, len(missing))
+		}
+		usage = result.Usage
+
+		for i, embedding := range result.Embeddings {
+			key := missingKeys[i]
+			for n, idx := range pending[key] {
+				if n > 0 {
+					embedding = slices.Clone(embedding)
+				}
+				embeddings[idx] = embedding
+			}
+			if len(embedding) > 0 {
+				a.set(ctx, key, embedding)
+			}
+		}
+	}
+
+	a.misses.Add(int64(len(missing)))
+	a.hits.Add(int64(len(params.Inputs) - len(missing)))
+	return &core.EmbedManyResult{Embeddings: embeddings, Usage: usage}, nil
+}
+
+func (a *Adapter) get(ctx context.Context, key string) ([]float64, bool) {
+	embedding, ok, err := a.Store.Get(ctx, key)
+	if err != nil {
+		a.storeErrors.Add(1)
+		return nil, false
+	}
+	if !ok || len(embedding) == 0 {
+		return nil, false
+	}
+	return slices.Clone(embedding), true
+}
+
+func (a *Adapter) set(ctx context.Context, key string, embedding []float64) {
+	if err := a.Store.Set(ctx, key, slices.Clone(embedding)); err != nil {
+		a.storeErrors.Add(1)
+	}
+}
+
+// keyFields are the request fields that change an embedding, hashed into the
+// cache key. Map keys in ModelOptions marshal in sorted order, so equal
+// options give equal keys.
+type keyFields struct {
+	Namespace    string         `json:"namespace,omitempty"`
+	Dimensions   *int64         `json:"dimensions,omitempty"`
+	ModelOptions map[string]any `json:"model_options,omitempty"`
+	Input        string         `json:"input"`
+}
+
+// key returns the SHA-256 of the namespace, request options, and input.
+func (a *Adapter) key(input string, dimensions *int64, modelOptions map[string]any) (string, error) {
+	data, err := json.Marshal(keyFields{Namespace: a.Namespace, Dimensions: dimensions, ModelOptions: modelOptions, Input: input})
+	if err != nil {
+		return "", fmt.Errorf("embedcache: cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
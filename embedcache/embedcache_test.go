@@ -0,0 +1,227 @@
+package embedcache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestEmbedCachesByContent(t *testing.T) {
+	t.Parallel()
+
+	mock := core.NewMockAdapter().ReplyEmbeddings([]float64{1, 0}, []float64{0, 1})
+	adapter := New(mock)
+	ctx := context.Background()
+
+	for range 2 {
+		result, err := adapter.Embed(ctx, &core.EmbedParams{Input: "hello"})
+		if err != nil || !reflect.DeepEqual(result.Embedding, []float64{1, 0}) {
+			t.Fatalf("Embed() = %+v, %v", result, err)
+		}
+		result.Embedding[0] = 42
+	}
+
+	dimensions := int64(2)
+	result, err := adapter.Embed(ctx, &core.EmbedParams{Input: "hello", Dimensions: &dimensions})
+	if err != nil || !reflect.DeepEqual(result.Embedding, []float64{0, 1}) {
+		t.Fatalf("Embed() with dimensions = %+v, %v", result, err)
+	}
+
+	if got := mock.EmbedInputs(); !reflect.DeepEqual(got, []string{"hello", "hello"}) {
+		t.Fatalf("embedded inputs = %q", got)
+	}
+	if stats := adapter.Stats(); stats != (Stats{Hits: 1, Misses: 2}) || stats.HitRate() != 1.0/3 {
+		t.Fatalf("Stats() = %+v", stats)
+	}
+}
+
+func TestEmbedManyEmbedsOnlyMisses(t *testing.T) {
+	t.Parallel()
+
+	mock := core.NewMockAdapter().ReplyEmbeddings([]float64{1}, []float64{2}, []float64{3})
+	adapter := New(mock)
+	ctx := context.Background()
+
+	if _, err := adapter.Embed(ctx, &core.EmbedParams{Input: "a"}); err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	result, err := adapter.EmbedMany(ctx, &core.EmbedManyParams{Inputs: []string{"b", "a", "c", "b"}})
+	if err != nil {
+		t.Fatalf("EmbedMany() error = %v", err)
+	}
+	if want := [][]float64{{2}, {1}, {3}, {2}}; !reflect.DeepEqual(result.Embeddings, want) {
+		t.Fatalf("embeddings = %v", result.Embeddings)
+	}
+	if got := mock.EmbedInputs(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("embedded inputs = %q", got)
+	}
+	if stats := adapter.Stats(); stats != (Stats{Hits: 2, Misses: 3}) {
+		t.Fatalf("Stats() = %+v", stats)
+	}
+
+	result, err = adapter.EmbedMany(ctx, &core.EmbedManyParams{Inputs: []string{"c", "a"}})
+	if err != nil || !reflect.DeepEqual(result.Embeddings, [][]float64{{3}, {1}}) || result.Usage != nil {
+		t.Fatalf("EmbedMany() = %+v, %v", result, err)
+	}
+	if len(mock.EmbedInputs()) != 3 {
+		t.Fatalf("embedded inputs = %q", mock.EmbedInputs())
+	}
+}
+
+func TestNamespaceSeparatesModels(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore(0)
+	small := New(core.NewMockAdapter().ReplyEmbeddings([]float64{1}), WithStore(store), WithNamespace("small"))
+	large := New(core.NewMockAdapter().ReplyEmbeddings([]float64{2, 2}), WithStore(store), WithNamespace("large"))
+	ctx := context.Background()
+
+	if result, err := small.Embed(ctx, &core.EmbedParams{Input: "text"}); err != nil || len(result.Embedding) != 1 {
+		t.Fatalf("small Embed() = %+v, %v", result, err)
+	}
+	if result, err := large.Embed(ctx, &core.EmbedParams{Input: "text"}); err != nil || len(result.Embedding) != 2 {
+		t.Fatalf("large Embed() = %+v, %v", result, err)
+	}
+	if store.Len() != 2 {
+		t.Fatalf("store has %d entries", store.Len())
+	}
+}
+
+type modelEmbedder struct {
+	Model string
+}
+
+func (e *modelEmbedder) Embed(context.Context, *core.EmbedParams) (*core.EmbedResult, error) {
+	return &core.EmbedResult{Embedding: []float64{float64(len(e.Model))}}, nil
+}
+
+func (e *modelEmbedder) EmbedMany(_ context.Context, params *core.EmbedManyParams) (*core.EmbedManyResult, error) {
+	embeddings := make([][]float64, len(params.Inputs))
+	for i := range embeddings {
+		embeddings[i] = []float64{float64(len(e.Model))}
+	}
+	return &core.EmbedManyResult{Embeddings: embeddings}, nil
+}
+
+func TestDefaultNamespaceNamesModel(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore(0)
+	small := New(&modelEmbedder{Model: "small"}, WithStore(store))
+	large := New(&modelEmbedder{Model: "large-v2"}, WithStore(store))
+	if small.Namespace != "embedcache.modelEmbedder/small" {
+		t.Fatalf("Namespace = %q", small.Namespace)
+	}
+	ctx := context.Background()
+
+	if result, err := small.Embed(ctx, &core.EmbedParams{Input: "text"}); err != nil || result.Embedding[0] != 5 {
+		t.Fatalf("small Embed() = %+v, %v", result, err)
+	}
+	if result, err := large.Embed(ctx, &core.EmbedParams{Input: "text"}); err != nil || result.Embedding[0] != 8 {
+		t.Fatalf("large Embed() = %+v, %v", result, err)
+	}
+	if store.Len() != 2 {
+		t.Fatalf("store has %d entries", store.Len())
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) ([]float64, bool, error) {
+	return nil, false, errors.New("unavailable")
+}
+
+func (failingStore) Set(context.Context, string, []float64) error {
+	return errors.New("unavailable")
+}
+
+func TestStoreErrorsFallBackToAdapter(t *testing.T) {
+	t.Parallel()
+
+	adapter := New(core.NewMockAdapter().ReplyEmbeddings([]float64{1}), WithStore(failingStore{}))
+	result, err := adapter.Embed(context.Background(), &core.EmbedParams{Input: "text"})
+	if err != nil || !reflect.DeepEqual(result.Embedding, []float64{1}) {
+		t.Fatalf("Embed() = %+v, %v", result, err)
+	}
+	if stats := adapter.Stats(); stats != (Stats{Misses: 1, StoreErrors: 2}) {
+		t.Fatalf("Stats() = %+v", stats)
+	}
+
+	failing := New(core.NewMockAdapter().ReplyEmbeddingError(errors.New("quota")))
+	if _, err := failing.EmbedMany(context.Background(), &core.EmbedManyParams{Inputs: []string{"x"}}); err == nil {
+		t.Fatal("EmbedMany() expected adapter error")
+	}
+	if failing.Store.(*MemoryStore).Len() != 0 {
+		t.Fatal("failed embedding was cached")
+	}
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+	_ = store.Set(ctx, "a", []float64{1})
+	_ = store.Set(ctx, "b", []float64{2})
+	if _, ok, _ := store.Get(ctx, "a"); !ok {
+		t.Fatal("Get(a) missed")
+	}
+	_ = store.Set(ctx, "c", []float64{3})
+
+	if _, ok, _ := store.Get(ctx, "b"); ok {
+		t.Fatal("Get(b) hit after eviction")
+	}
+	if embedding, ok, _ := store.Get(ctx, "a"); !ok || !reflect.DeepEqual(embedding, []float64{1}) {
+		t.Fatalf("Get(a) = %v, %v", embedding, ok)
+	}
+	if store.Len() != 2 {
+		t.Fatalf("Len() = %d", store.Len())
+	}
+}
+
+func TestFileStorePersists(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "cache")
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+
+	first := New(core.NewMockAdapter().ReplyEmbeddings([]float64{0.25, -1, 1e-9}), WithStore(store))
+	if _, err := first.Embed(ctx, &core.EmbedParams{Input: "persisted"}); err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	reopened, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	second := New(core.NewMockAdapter(), WithStore(reopened))
+	result, err := second.Embed(ctx, &core.EmbedParams{Input: "persisted"})
+	if err != nil || !reflect.DeepEqual(result.Embedding, []float64{0.25, -1, 1e-9}) {
+		t.Fatalf("Embed() from disk = %+v, %v", result, err)
+	}
+	if stats := second.Stats(); stats.Hits != 1 {
+		t.Fatalf("Stats() = %+v", stats)
+	}
+
+	if _, _, err := store.Get(ctx, "../../etc/passwd"); err == nil {
+		t.Fatal("Get() expected error for a path key")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "ab"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ab", "abcdef"), []byte{1, 2, 3}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Get(ctx, "abcdef"); err == nil {
+		t.Fatal("Get() expected error for a corrupt entry")
+	}
+}
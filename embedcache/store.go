@@ -0,0 +1,172 @@
+package embedcache
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// MemoryStore is a Store that keeps embeddings in memory, evicting the least
+// recently used ones beyond its capacity. A MemoryStore is safe for
+// concurrent use.
+type MemoryStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key       string
+	embedding []float64
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns a MemoryStore that holds up to maxEntries
+// embeddings. Zero or less means no limit.
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{maxEntries: max(maxEntries, 0), order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Len returns the number of stored embeddings.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(_ context.Context, key string) ([]float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	s.order.MoveToFront(element)
+	return slices.Clone(element.Value.(*memoryEntry).embedding), true, nil
+}
+
+// Set implements Store.
+func (s *MemoryStore) Set(_ context.Context, key string, embedding []float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.entries[key]; ok {
+		element.Value.(*memoryEntry).embedding = slices.Clone(embedding)
+		s.order.MoveToFront(element)
+		return nil
+	}
+	s.entries[key] = s.order.PushFront(&memoryEntry{key: key, embedding: slices.Clone(embedding)})
+	if s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// FileStore is a Store that keeps each embedding in a file under a
+// directory, so the cache survives restarts and can be shared by processes on
+// one machine. Files hold the vector as little-endian float64 values and are
+// spread over subdirectories by the first two characters of the key.
+type FileStore struct {
+	dir string
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore returns a FileStore in dir, creating the directory if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, errors.New("embedcache: file store directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("embedcache: create file store: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Dir returns the directory of the store.
+func (s *FileStore) Dir() string {
+	return s.dir
+}
+
+// Get implements Store.
+func (s *FileStore) Get(_ context.Context, key string) ([]float64, bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("embedcache: read %s: %w", key, err)
+	}
+	if len(data) == 0 || len(data)%8 != 0 {
+		return nil, false, fmt.Errorf("embedcache: entry %s is corrupt (%d bytes)", key, len(data))
+	}
+
+	embedding := make([]float64, len(data)/8)
+	for i := range embedding {
+		embedding[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:]))
+	}
+	return embedding, true, nil
+}
+
+// Set implements Store. The entry is written to a temporary file and renamed
+// into place, so concurrent readers never see a partial entry.
+func (s *FileStore) Set(_ context.Context, key string, embedding []float64) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, 8*len(embedding))
+	for i, value := range embedding {
+		binary.LittleEndian.PutUint64(data[i*8:], math.Float64bits(value))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("embedcache: write %s: %w", key, err)
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), key+".tmp*")
+	if err != nil {
+		return fmt.Errorf("embedcache: write %s: %w", key, err)
+	}
+	_, writeErr := file.Write(data)
+	closeErr := file.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("embedcache: write %s: %w", key, err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("embedcache: write %s: %w", key, err)
+	}
+	return nil
+}
+
+// path returns the file of key, rejecting keys that could leave the
+// directory.
+func (s *FileStore) path(key string) (string, error) {
+	if len(key) < 3 {
+		return "", fmt.Errorf("embedcache: invalid key %q", key)
+	}
+	for _, r := range key {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-' || r == '_') {
+			return "", fmt.Errorf("embedcache: invalid key %q", key)
+		}
+	}
+	return filepath.Join(s.dir, key[:2], key), nil
+}
@@ -0,0 +1,270 @@
+// Package eval runs test cases against one or more text adapters and scores
+// the responses, for regression testing of prompts and comparing models.
+//
+//	evaluator := eval.New(
+//		eval.WithScorers(eval.Contains(), eval.Judge(claude.New("claude-sonnet-4-5"))),
+//	)
+//	report, err := evaluator.Run(ctx, cases,
+//		eval.Target{Name: "gpt-4o-mini", Adapter: openai.New("gpt-4o-mini")},
+//		eval.Target{Name: "llama3.1", Adapter: ollama.New("llama3.1")},
+//	)
+//	report.WriteMarkdown(os.Stdout)
+//
+// Heuristic scorers check the response text directly; Judge asks a model to
+// grade it against the case's criteria and reference answer.
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// DefaultConcurrency is the number of cases run at once unless
+// WithConcurrency is used.
+const DefaultConcurrency = 4
+
+// Case is one test case: a prompt and what a good response looks like.
+type Case struct {
+	// Name identifies the case in reports. Empty uses "case <n>".
+	Name string
+
+	// Input is sent as the last user message.
+	Input string
+
+	// Messages is the conversation before Input, or the whole conversation
+	// when Input is empty.
+	Messages []core.MessageUnion
+
+	// SystemPrompt is added after the system prompts of the target.
+	SystemPrompt string
+
+	// Expected is the reference answer, used by ExactMatch and Contains and
+	// shown to the judge.
+	Expected string
+
+	// Criteria are the qualities the judge grades the response on, such as
+	// "cites the refund policy".
+	Criteria []string
+
+	// Scorers are applied to this case in addition to the evaluator's.
+	Scorers []Scorer
+
+	Metadata map[string]any
+}
+
+// Target is an adapter under test, with the request options to use for every
+// case.
+type Target struct {
+	// Name identifies the target in reports. Empty uses "target <n>".
+	Name    string
+	Adapter core.TextAdapter
+
+	// Params is the template request; the messages of each case are
+	// appended to its messages and its system prompt to its system prompts.
+	// Nil sends only the case.
+	Params *core.ChatParams
+}
+
+// Sample is the response of a target to a case, as passed to scorers.
+type Sample struct {
+	Case   *Case
+	Target string
+
+	// Output is the response text.
+	Output string
+
+	// Result is the full response.
+	Result *core.ChatResult
+}
+
+// Evaluator runs cases against targets and scores the responses.
+type Evaluator struct {
+	// Scorers are applied to every response.
+	Scorers []Scorer
+
+	// Concurrency is the number of requests in flight at once.
+	Concurrency int
+
+	// Repeats runs each case this many times per target, to measure how
+	// consistently a target passes. Zero runs each case once.
+	Repeats int
+}
+
+// Option configures an Evaluator.
+type Option func(*Evaluator)
+
+// New returns an Evaluator with DefaultConcurrency.
+func New(opts ...Option) *Evaluator {
+	evaluator := &Evaluator{Concurrency: DefaultConcurrency}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(evaluator)
+	}
+	return evaluator
+}
+
+// WithScorers adds scorers applied to every response.
+func WithScorers(scorers ...Scorer) Option {
+	return func(e *Evaluator) {
+		e.Scorers = append(e.Scorers, scorers...)
+	}
+}
+
+// WithConcurrency sets the number of requests in flight at once.
+func WithConcurrency(n int) Option {
+	return func(e *Evaluator) {
+		if n > 0 {
+			e.Concurrency = n
+		}
+	}
+}
+
+// WithRepeats runs each case n times per target.
+func WithRepeats(n int) Option {
+	return func(e *Evaluator) {
+		if n > 0 {
+			e.Repeats = n
+		}
+	}
+}
+
+// Run sends every case to every target and scores the responses. A failed
+// request or scorer is recorded in its Result rather than ending the run;
+// Run only fails for invalid input or when ctx is done.
+func (e *Evaluator) Run(ctx context.Context, cases []Case, targets ...Target) (*Report, error) {
+	if len(cases) == 0 {
+		return nil, errors.New("eval: at least one case is required")
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("eval: at least one target is required")
+	}
+	names := make([]string, len(targets))
+	for idx, target := range targets {
+		if target.Adapter == nil {
+			return nil, fmt.Errorf("eval: target %d has no adapter", idx)
+		}
+		names[idx] = target.Name
+		if names[idx] == "" {
+			names[idx] = fmt.Sprintf("target %d", idx+1)
+		}
+		if slices.Contains(names[:idx], names[idx]) {
+			return nil, fmt.Errorf("eval: duplicate target name %q", names[idx])
+		}
+	}
+	caseNames := make([]string, len(cases))
+	for idx, c := range cases {
+		if c.Input == "" && len(c.Messages) == 0 {
+			return nil, fmt.Errorf("eval: case %d has neither input nor messages", idx)
+		}
+		caseNames[idx] = c.Name
+		if caseNames[idx] == "" {
+			caseNames[idx] = fmt.Sprintf("case %d", idx+1)
+		}
+	}
+
+	repeats, concurrency := 1, DefaultConcurrency
+	var scorers []Scorer
+	if e != nil {
+		repeats = max(e.Repeats, 1)
+		if e.Concurrency > 0 {
+			concurrency = e.Concurrency
+		}
+		scorers = e.Scorers
+	}
+
+	// Results are laid out case-major, then target, then repeat, and filled
+	// in by index so their order does not depend on scheduling.
+	results := make([]Result, len(cases)*len(targets)*repeats)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(results)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				caseIdx := idx / (len(targets) * repeats)
+				targetIdx := idx / repeats % len(targets)
+				c := cases[caseIdx]
+				c.Name = caseNames[caseIdx]
+				results[idx] = run(ctx, &c, names[targetIdx], targets[targetIdx], append(slices.Clip(scorers), c.Scorers...))
+				results[idx].Repeat = idx % repeats
+			}
+		}()
+	}
+	for idx := range results {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Report{Targets: names, Cases: caseNames, Results: results}, nil
+}
+
+// run sends one case to one target and scores the response.
+func run(ctx context.Context, c *Case, name string, target Target, scorers []Scorer) Result {
+	result := Result{Case: c.Name, Target: name}
+
+	start := time.Now()
+	response, err := core.Chat(ctx, target.Adapter, chatParams(target.Params, c))
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if response != nil {
+		result.Output = response.Text
+		result.Usage = response.Usage
+	}
+
+	sample := &Sample{Case: c, Target: name, Output: result.Output, Result: response}
+	for _, scorer := range scorers {
+		if scorer == nil {
+			continue
+		}
+		score, err := scorer.Score(ctx, sample)
+		if err != nil {
+			result.Scores = append(result.Scores, Score{Scorer: scorer.Name(), Error: err.Error()})
+			continue
+		}
+		if score == nil {
+			continue
+		}
+		if score.Scorer == "" {
+			score.Scorer = scorer.Name()
+		}
+		result.Scores = append(result.Scores, *score)
+	}
+	return result
+}
+
+// chatParams returns the request for c, built on a copy of template.
+func chatParams(template *core.ChatParams, c *Case) *core.ChatParams {
+	params := &core.ChatParams{}
+	if template != nil {
+		copied := *template
+		params = &copied
+	}
+	params.Messages = slices.Concat(params.Messages, c.Messages)
+	if c.Input != "" {
+		params.Messages = append(params.Messages, core.TextMessagePart{Role: core.RoleUser, Content: c.Input})
+	}
+	if strings.TrimSpace(c.SystemPrompt) != "" {
+		params.SystemPrompts = append(slices.Clip(params.SystemPrompts), c.SystemPrompt)
+	}
+	return params
+}
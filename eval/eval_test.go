@@ -0,0 +1,175 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// answering returns a mock adapter that answers each prompt with answers,
+// keyed by the last user message.
+func answering(answers map[string]string) *core.MockAdapter {
+	mock := core.NewMockAdapter()
+	mock.ChatFunc = func(_ context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+		prompt := params.Messages[len(params.Messages)-1].(core.TextMessagePart).Content
+		answer, ok := answers[prompt]
+		if !ok {
+			return nil, errors.New("unavailable")
+		}
+		return &core.ChatResult{Text: answer, FinishReason: core.FinishReasonStop, Usage: &core.Usage{TotalTokens: 10}}, nil
+	}
+	return mock
+}
+
+func TestRunComparesTargets(t *testing.T) {
+	t.Parallel()
+
+	cases := []Case{
+		{Name: "capital", Input: "Capital of France?", Expected: "Paris"},
+		{Name: "math", Input: "2+2?", Expected: "4"},
+		{Input: "Broken?", Scorers: []Scorer{Matches(regexp.MustCompile(`\d`))}},
+	}
+	good := answering(map[string]string{"Capital of France?": "Paris", "2+2?": "4", "Broken?": "42"})
+	bad := answering(map[string]string{"Capital of France?": "It is Paris.", "2+2?": "5"})
+
+	report, err := New(WithScorers(ExactMatch(), Contains()), WithConcurrency(2)).Run(context.Background(), cases,
+		Target{Name: "good", Adapter: good, Params: &core.ChatParams{SystemPrompts: []string{"Answer briefly."}}},
+		Target{Name: "bad", Adapter: bad},
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(report.Results) != 6 || report.Results[1].Case != "capital" || report.Results[1].Target != "bad" || report.Cases[2] != "case 3" {
+		t.Fatalf("results = %+v", report.Results)
+	}
+	if request := good.Requests()[0]; len(request.SystemPrompts) != 1 || request.SystemPrompts[0] != "Answer briefly." {
+		t.Fatalf("request = %+v", request)
+	}
+
+	goodSummary, _ := report.Summary("good")
+	if goodSummary.Runs != 3 || goodSummary.Passed != 3 || goodSummary.MeanScore != 1 || goodSummary.Tokens != 30 {
+		t.Fatalf("good summary = %+v", goodSummary)
+	}
+	badSummary, _ := report.Summary("bad")
+	if badSummary.Passed != 0 || badSummary.Errors != 1 || badSummary.PassRate() != 0 {
+		t.Fatalf("bad summary = %+v", badSummary)
+	}
+
+	// "It is Paris." contains the expected answer but does not match it.
+	capital := report.Results[1]
+	if len(capital.Scores) != 2 || capital.Scores[0].Pass || !capital.Scores[1].Pass || capital.MeanScore() != 0.5 {
+		t.Fatalf("capital scores = %+v", capital.Scores)
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteMarkdown(&out); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	for _, want := range []string{
+		"| good | 100% (3/3) | 1.00 | 0 | 30 |",
+		"| Case | good | bad |",
+		"| capital | 1.00 | 0.50 ✗ |",
+		"- **case 3** on bad: error: unavailable",
+		`- **math** on bad: exact_match: expected "4"; contains: missing ["4"]`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("markdown is missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunRepeats(t *testing.T) {
+	t.Parallel()
+
+	adapter := answering(map[string]string{"hi": "hello"})
+	report, err := New(WithRepeats(3), WithScorers(Contains("hello"))).Run(context.Background(), []Case{{Input: "hi"}}, Target{Adapter: adapter})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(report.Results) != 3 || report.Results[2].Repeat != 2 || report.Targets[0] != "target 1" || len(adapter.Requests()) != 3 {
+		t.Fatalf("results = %+v", report.Results)
+	}
+}
+
+func TestRunValidates(t *testing.T) {
+	t.Parallel()
+
+	adapter := core.NewMockAdapter()
+	evaluator := New()
+	if _, err := evaluator.Run(context.Background(), nil, Target{Adapter: adapter}); err == nil {
+		t.Fatal("Run() expected error without cases")
+	}
+	if _, err := evaluator.Run(context.Background(), []Case{{}}, Target{Adapter: adapter}); err == nil {
+		t.Fatal("Run() expected error for an empty case")
+	}
+	if _, err := evaluator.Run(context.Background(), []Case{{Input: "x"}}, Target{Name: "a", Adapter: adapter}, Target{Name: "a", Adapter: adapter}); err == nil {
+		t.Fatal("Run() expected error for duplicate targets")
+	}
+}
+
+func TestJudge(t *testing.T) {
+	t.Parallel()
+
+	judge := core.NewMockAdapter().Reply(`{"reasoning": "Correct but cites no policy.", "score": 6}`)
+	scorer := Judge(judge, "Is polite.")
+	sample := &Sample{
+		Case:   &Case{Input: "Can I get a refund?", Expected: "Yes, within 14 days.", Criteria: []string{"Cites the refund policy."}},
+		Output: "Sure, within 14 days!",
+	}
+
+	score, err := scorer.Score(context.Background(), sample)
+	if err != nil {
+		t.Fatalf("Score() error = %v", err)
+	}
+	if score.Value != 0.6 || score.Pass || score.Reason != "Correct but cites no policy." {
+		t.Fatalf("score = %+v", score)
+	}
+
+	request := judge.Requests()[0]
+	if request.Output == nil || request.Output.Name != "verdict" || len(request.SystemPrompts) != 1 {
+		t.Fatalf("judge request = %+v", request)
+	}
+	prompt := request.Messages[0].(core.TextMessagePart).Content
+	for _, want := range []string{"Can I get a refund?", "- Is polite.\n- Cites the refund policy.", "## Reference answer\n\nYes, within 14 days.", "## Response\n\nSure, within 14 days!"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("judge prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+
+	scorer.Threshold = 0.5
+	judge.Reply(`{"reasoning": "ok", "score": 6}`)
+	if score, err := scorer.Score(context.Background(), sample); err != nil || !score.Pass {
+		t.Fatalf("Score() with threshold = %+v, %v", score, err)
+	}
+}
+
+func TestHeuristicScorers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sample := func(output, expected string) *Sample {
+		return &Sample{Case: &Case{Expected: expected}, Output: output}
+	}
+
+	if score, _ := ExactMatch().Score(ctx, sample(" paris\n", "Paris")); !score.Pass {
+		t.Fatalf("ExactMatch() = %+v", score)
+	}
+	if score, _ := ExactMatch().Score(ctx, sample("Paris", "")); score != nil {
+		t.Fatalf("ExactMatch() without expected = %+v", score)
+	}
+	if score, _ := Contains("red", "green", "blue").Score(ctx, sample("Red and BLUE", "")); score.Pass || score.Value != 2.0/3 {
+		t.Fatalf("Contains() = %+v", score)
+	}
+	if score, _ := ValidJSON().Score(ctx, sample("Here:\n```json\n{\"a\": 1}\n```", "")); !score.Pass {
+		t.Fatalf("ValidJSON() fenced = %+v", score)
+	}
+	if score, _ := ValidJSON().Score(ctx, sample(`{"a": [1, 2`, "")); score.Pass {
+		t.Fatalf("ValidJSON() truncated = %+v", score)
+	}
+}
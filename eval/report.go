@@ -0,0 +1,249 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// Result is the response of one target to one case and its scores.
+type Result struct {
+	Case   string `json:"case"`
+	Target string `json:"target"`
+
+	// Repeat counts the runs of the case on the target from zero.
+	Repeat int `json:"repeat,omitempty"`
+
+	Output   string        `json:"output"`
+	Scores   []Score       `json:"scores,omitempty"`
+	Usage    *core.Usage   `json:"usage,omitempty"`
+	Duration time.Duration `json:"duration"`
+
+	// Error is set when the request failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Passed reports whether the request succeeded and every score passed.
+func (r Result) Passed() bool {
+	if r.Error != "" {
+		return false
+	}
+	for _, score := range r.Scores {
+		if !score.Pass {
+			return false
+		}
+	}
+	return true
+}
+
+// MeanScore returns the mean value of the successful scores, or 0 when there
+// are none or the request failed.
+func (r Result) MeanScore() float64 {
+	if r.Error != "" {
+		return 0
+	}
+	total, count := 0.0, 0
+	for _, score := range r.Scores {
+		if score.Error != "" {
+			continue
+		}
+		total += score.Value
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// Report holds the results of a run, case-major in the order of the cases,
+// then targets, then repeats.
+type Report struct {
+	Targets []string `json:"targets"`
+	Cases   []string `json:"cases"`
+	Results []Result `json:"results"`
+}
+
+// Summary aggregates the results of one target.
+type Summary struct {
+	Target string
+
+	// Runs is the number of responses, Passed the passing ones, and Errors
+	// the failed requests.
+	Runs   int
+	Passed int
+	Errors int
+
+	// MeanScore is the mean of the results' mean scores.
+	MeanScore float64
+
+	// Tokens is the total tokens reported by the target.
+	Tokens int64
+
+	// MeanDuration is the mean request time.
+	MeanDuration time.Duration
+}
+
+// PassRate returns the share of passing runs.
+func (s Summary) PassRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Passed) / float64(s.Runs)
+}
+
+// Summaries returns a Summary per target, in the order of the targets.
+func (r *Report) Summaries() []Summary {
+	summaries := make([]Summary, len(r.Targets))
+	index := make(map[string]int, len(r.Targets))
+	for idx, target := range r.Targets {
+		summaries[idx].Target = target
+		index[target] = idx
+	}
+
+	durations := make([]time.Duration, len(r.Targets))
+	for _, result := range r.Results {
+		idx, ok := index[result.Target]
+		if !ok {
+			continue
+		}
+		summary := &summaries[idx]
+		summary.Runs++
+		if result.Passed() {
+			summary.Passed++
+		}
+		if result.Error != "" {
+			summary.Errors++
+		}
+		summary.MeanScore += result.MeanScore()
+		if result.Usage != nil {
+			summary.Tokens += result.Usage.TotalTokens
+		}
+		durations[idx] += result.Duration
+	}
+	for idx := range summaries {
+		if runs := summaries[idx].Runs; runs > 0 {
+			summaries[idx].MeanScore /= float64(runs)
+			summaries[idx].MeanDuration = durations[idx] / time.Duration(runs)
+		}
+	}
+	return summaries
+}
+
+// Summary returns the Summary of target.
+func (r *Report) Summary(target string) (Summary, bool) {
+	for _, summary := range r.Summaries() {
+		if summary.Target == target {
+			return summary, true
+		}
+	}
+	return Summary{}, false
+}
+
+// Failures returns the results that did not pass.
+func (r *Report) Failures() []Result {
+	var failures []Result
+	for _, result := range r.Results {
+		if !result.Passed() {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// WriteMarkdown writes the report as Markdown: a summary table with a row per
+// target, a table with the mean score of each case per target, and the
+// reasons of the failures.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("| Target | Pass rate | Mean score | Errors | Tokens | Mean duration |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, summary := range r.Summaries() {
+		fmt.Fprintf(&b, "| %s | %.0f%% (%d/%d) | %.2f | %d | %d | %s |\n",
+			cell(summary.Target), summary.PassRate()*100, summary.Passed, summary.Runs, summary.MeanScore, summary.Errors, summary.Tokens, summary.MeanDuration.Round(time.Millisecond))
+	}
+
+	b.WriteString("\n| Case |")
+	for _, target := range r.Targets {
+		b.WriteString(" ")
+		b.WriteString(cell(target))
+		b.WriteString(" |")
+	}
+	b.WriteString("\n| --- |")
+	b.WriteString(strings.Repeat(" --- |", len(r.Targets)))
+	b.WriteString("\n")
+	for _, name := range r.Cases {
+		b.WriteString("| ")
+		b.WriteString(cell(name))
+		b.WriteString(" |")
+		for _, target := range r.Targets {
+			b.WriteString(" ")
+			b.WriteString(r.caseCell(name, target))
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+
+	if failures := r.Failures(); len(failures) > 0 {
+		b.WriteString("\n## Failures\n\n")
+		for _, result := range failures {
+			fmt.Fprintf(&b, "- **%s** on %s: %s\n", result.Case, result.Target, failureReason(result))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// caseCell returns the mean score of the runs of a case on a target, marked
+// when any of them failed.
+func (r *Report) caseCell(name, target string) string {
+	total, runs, passed := 0.0, 0, 0
+	for _, result := range r.Results {
+		if result.Case != name || result.Target != target {
+			continue
+		}
+		total += result.MeanScore()
+		runs++
+		if result.Passed() {
+			passed++
+		}
+	}
+	if runs == 0 {
+		return "-"
+	}
+	text := fmt.Sprintf("%.2f", total/float64(runs))
+	if passed < runs {
+		text += " ✗"
+	}
+	return text
+}
+
+func failureReason(result Result) string {
+	if result.Error != "" {
+		return "error: " + result.Error
+	}
+	var reasons []string
+	for _, score := range result.Scores {
+		switch {
+		case score.Error != "":
+			reasons = append(reasons, score.Scorer+" error: "+score.Error)
+		case !score.Pass:
+			reason := score.Scorer
+			if score.Reason != "" {
+				reason += ": " + score.Reason
+			}
+			reasons = append(reasons, reason)
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// cell escapes text for a Markdown table cell.
+func cell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
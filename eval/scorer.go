@@ -0,0 +1,263 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// DefaultJudgeThreshold is the normalized judge score a response needs to
+// pass unless JudgeScorer.Threshold is set.
+const DefaultJudgeThreshold = 0.7
+
+// Score is the grade of one scorer for one response.
+type Score struct {
+	Scorer string `json:"scorer"`
+
+	// Value is the grade from 0 to 1.
+	Value float64 `json:"value"`
+	Pass  bool    `json:"pass"`
+
+	// Reason explains the grade, such as the judge's rationale.
+	Reason string `json:"reason,omitempty"`
+
+	// Error is set when the scorer failed; the score then neither passes nor
+	// counts towards the mean.
+	Error string `json:"error,omitempty"`
+}
+
+// Scorer grades a response. It returns nil when it does not apply to the
+// case, such as ExactMatch for a case without an expected answer.
+type Scorer interface {
+	Name() string
+	Score(ctx context.Context, sample *Sample) (*Score, error)
+}
+
+type funcScorer struct {
+	name string
+	fn   func(ctx context.Context, sample *Sample) (*Score, error)
+}
+
+func (s funcScorer) Name() string { return s.name }
+
+func (s funcScorer) Score(ctx context.Context, sample *Sample) (*Score, error) {
+	return s.fn(ctx, sample)
+}
+
+// NewScorer returns a Scorer named name that grades with fn.
+func NewScorer(name string, fn func(ctx context.Context, sample *Sample) (*Score, error)) Scorer {
+	return funcScorer{name: name, fn: fn}
+}
+
+// passFail returns a score of 1 or 0.
+func passFail(pass bool, reason string) *Score {
+	score := &Score{Pass: pass, Reason: reason}
+	if pass {
+		score.Value = 1
+	}
+	return score
+}
+
+// ExactMatch passes responses equal to the case's expected answer, ignoring
+// surrounding whitespace and case.
+func ExactMatch() Scorer {
+	return NewScorer("exact_match", func(_ context.Context, sample *Sample) (*Score, error) {
+		if sample.Case.Expected == "" {
+			return nil, nil
+		}
+		if strings.EqualFold(strings.TrimSpace(sample.Output), strings.TrimSpace(sample.Case.Expected)) {
+			return passFail(true, ""), nil
+		}
+		return passFail(false, fmt.Sprintf("expected %q", sample.Case.Expected)), nil
+	})
+}
+
+// Contains grades responses by the share of substrings they contain,
+// ignoring case, and passes those that contain all of them. Without
+// substrings it looks for the case's expected answer.
+func Contains(substrings ...string) Scorer {
+	return NewScorer("contains", func(_ context.Context, sample *Sample) (*Score, error) {
+		wanted := substrings
+		if len(wanted) == 0 {
+			if sample.Case.Expected == "" {
+				return nil, nil
+			}
+			wanted = []string{sample.Case.Expected}
+		}
+
+		output := strings.ToLower(sample.Output)
+		var missing []string
+		for _, substring := range wanted {
+			if !strings.Contains(output, strings.ToLower(substring)) {
+				missing = append(missing, substring)
+			}
+		}
+		score := &Score{Value: float64(len(wanted)-len(missing)) / float64(len(wanted)), Pass: len(missing) == 0}
+		if len(missing) > 0 {
+			score.Reason = fmt.Sprintf("missing %q", missing)
+		}
+		return score, nil
+	})
+}
+
+// Matches passes responses that match re.
+func Matches(re *regexp.Regexp) Scorer {
+	return NewScorer("matches", func(_ context.Context, sample *Sample) (*Score, error) {
+		if re.MatchString(sample.Output) {
+			return passFail(true, ""), nil
+		}
+		return passFail(false, fmt.Sprintf("does not match %s", re)), nil
+	})
+}
+
+// ValidJSON passes responses that contain a complete JSON value, alone or in
+// a code fence.
+func ValidJSON() Scorer {
+	return NewScorer("valid_json", func(_ context.Context, sample *Sample) (*Score, error) {
+		text := strings.TrimSpace(sample.Output)
+		if json.Valid([]byte(text)) {
+			return passFail(true, ""), nil
+		}
+		if extracted, err := core.ExtractJSON(text); err == nil && json.Valid([]byte(extracted)) && !truncatedJSON(text, extracted) {
+			return passFail(true, ""), nil
+		}
+		return passFail(false, "no valid JSON value"), nil
+	})
+}
+
+// truncatedJSON reports whether ExtractJSON had to complete the value, which
+// means the response itself was not valid JSON.
+func truncatedJSON(text, extracted string) bool {
+	return !strings.Contains(text, extracted)
+}
+
+// JudgeScorer grades responses with a model, LLM-as-judge. The judge sees
+// the case's input, reference answer, and criteria, and scores the response
+// from 0 to 10.
+type JudgeScorer struct {
+	// Adapter is the judge model. It should support structured output.
+	Adapter core.TextAdapter
+
+	// Criteria are graded for every case, in addition to the case's own.
+	Criteria []string
+
+	// Threshold is the normalized score a response needs to pass. Zero uses
+	// DefaultJudgeThreshold.
+	Threshold float64
+
+	// Instructions replace the judge's default system prompt.
+	Instructions string
+}
+
+var _ Scorer = (*JudgeScorer)(nil)
+
+// Judge returns a JudgeScorer that grades with adapter on criteria, in
+// addition to each case's own criteria.
+func Judge(adapter core.TextAdapter, criteria ...string) *JudgeScorer {
+	return &JudgeScorer{Adapter: adapter, Criteria: criteria}
+}
+
+const judgeInstructions = `You are an impartial evaluator of AI assistant responses. Grade the response to the task strictly against the criteria and, when given, the reference answer. Do not reward length or style the criteria do not ask for.
+
+Answer with JSON: "reasoning" explains the grade in one or two sentences, and "score" is an integer from 0 (fails every criterion) to 10 (meets every criterion fully).`
+
+// verdict is the judge's structured answer.
+type verdict struct {
+	Reasoning string `json:"reasoning" description:"One or two sentences explaining the grade"`
+	Score     int    `json:"score" minimum:"0" maximum:"10" description:"Grade from 0 to 10"`
+}
+
+// Name implements Scorer.
+func (j *JudgeScorer) Name() string {
+	return "judge"
+}
+
+// Score implements Scorer.
+func (j *JudgeScorer) Score(ctx context.Context, sample *Sample) (*Score, error) {
+	if j.Adapter == nil {
+		return nil, errors.New("eval: judge adapter is required")
+	}
+	schema, err := core.NewSchema("verdict", verdict{})
+	if err != nil {
+		return nil, err
+	}
+	instructions := j.Instructions
+	if instructions == "" {
+		instructions = judgeInstructions
+	}
+
+	result, err := core.Chat(ctx, j.Adapter, &core.ChatParams{
+		SystemPrompts: []string{instructions},
+		Messages:      []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: j.prompt(sample)}},
+		Output:        &schema,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("eval: judge: %w", err)
+	}
+	answer, err := core.DecodeLast[verdict](result)
+	if err != nil {
+		return nil, fmt.Errorf("eval: judge: %w", err)
+	}
+
+	threshold := j.Threshold
+	if threshold <= 0 {
+		threshold = DefaultJudgeThreshold
+	}
+	value := min(max(float64(answer.Score)/10, 0), 1)
+	return &Score{Value: value, Pass: value >= threshold, Reason: answer.Reasoning}, nil
+}
+
+// prompt lays out the case and response for the judge.
+func (j *JudgeScorer) prompt(sample *Sample) string {
+	var b strings.Builder
+	b.WriteString("## Task\n\n")
+	for _, message := range sample.Case.Messages {
+		if text, ok := messageText(message); ok {
+			b.WriteString(text)
+			b.WriteString("\n\n")
+		}
+	}
+	if sample.Case.Input != "" {
+		b.WriteString(sample.Case.Input)
+		b.WriteString("\n\n")
+	}
+
+	criteria := append(append([]string(nil), j.Criteria...), sample.Case.Criteria...)
+	if len(criteria) == 0 {
+		criteria = []string{"The response completes the task correctly and helpfully."}
+	}
+	b.WriteString("## Criteria\n\n")
+	for _, criterion := range criteria {
+		b.WriteString("- ")
+		b.WriteString(criterion)
+		b.WriteString("\n")
+	}
+
+	if sample.Case.Expected != "" {
+		b.WriteString("\n## Reference answer\n\n")
+		b.WriteString(sample.Case.Expected)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n## Response\n\n")
+	b.WriteString(sample.Output)
+	return b.String()
+}
+
+// messageText returns the text of a conversation message as "role: text".
+func messageText(message core.MessageUnion) (string, bool) {
+	switch typed := message.(type) {
+	case core.TextMessagePart:
+		return typed.Role + ": " + typed.Content, true
+	case *core.TextMessagePart:
+		if typed != nil {
+			return typed.Role + ": " + typed.Content, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,174 @@
+// Package groq is an adapter for the Groq chat API, which serves the OpenAI
+// Chat Completions format.
+//
+// Groq reports how long a request queued and how long the prompt and the
+// completion took. The adapter adds these timings, and the completion speed
+// they give, to core.Usage.Details.
+package groq
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+const (
+	providerName       = "groq"
+	defaultBaseURL     = "https://api.groq.com/openai/v1"
+	defaultHTTPTimeout = 5 * time.Minute
+	envGroqAPIKey      = "GROQ_API_KEY"
+)
+
+type Adapter struct {
+	APIKey  string
+	Model   string
+	BaseURL string
+
+	HTTPClient *http.Client
+}
+
+var _ core.TextAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
+
+type Option func(*Adapter)
+
+// New creates a Groq adapter.
+//
+// Preferred usage is to use core and add this adapter there.
+//
+// If no API key is provided via options, New reads GROQ_API_KEY.
+func New(model string, opts ...Option) *Adapter {
+	adapter := &Adapter{
+		APIKey:     strings.TrimSpace(os.Getenv(envGroqAPIKey)),
+		Model:      strings.TrimSpace(model),
+		BaseURL:    defaultBaseURL,
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(adapter)
+	}
+
+	return adapter
+}
+
+// WithAPIKey sets the API key used by the adapter.
+func WithAPIKey(apiKey string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(apiKey) == "" {
+			return
+		}
+		adapter.APIKey = strings.TrimSpace(apiKey)
+	}
+}
+
+// WithBaseURL sets the API base URL used by the adapter.
+func WithBaseURL(baseURL string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(baseURL) == "" {
+			return
+		}
+		adapter.BaseURL = strings.TrimSpace(baseURL)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the adapter.
+func WithHTTPClient(client *http.Client) Option {
+	return func(adapter *Adapter) {
+		if client == nil {
+			return
+		}
+		adapter.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the timeout on the adapter HTTP client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(adapter *Adapter) {
+		if timeout <= 0 {
+			return
+		}
+		if adapter.HTTPClient == nil {
+			adapter.HTTPClient = &http.Client{}
+		}
+		adapter.HTTPClient.Timeout = timeout
+	}
+}
+
+// Capabilities reports the chat features of the Groq API. Vision and
+// reasoning depend on the model.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:              true,
+		Vision:             true,
+		StructuredOutput:   true,
+		StreamingWithTools: true,
+		Reasoning:          true,
+	}
+}
+
+func (a *Adapter) validate() error {
+	if a == nil {
+		return errors.New("groq: adapter is nil")
+	}
+
+	if strings.TrimSpace(a.APIKey) == "" {
+		a.APIKey = strings.TrimSpace(os.Getenv(envGroqAPIKey))
+	}
+	if strings.TrimSpace(a.APIKey) == "" {
+		return errors.New("groq: API key is required (set GROQ_API_KEY or use groq.WithAPIKey)")
+	}
+
+	if strings.TrimSpace(a.Model) == "" {
+		return errors.New("groq: model is required")
+	}
+
+	return nil
+}
+
+// compat returns the Chat Completions client for the adapter.
+func (a *Adapter) compat() *openaicompat.Client {
+	return &openaicompat.Client{
+		Provider:  providerName,
+		Model:     a.Model,
+		Transport: a.transport(),
+		Usage:     timedUsage,
+	}
+}
+
+func (a *Adapter) client() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// transport returns the client that sends API requests.
+func (a *Adapter) transport() *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   providerName,
+		Header: func(header http.Header) {
+			header.Set("Authorization", "Bearer "+a.APIKey)
+			header.Set("Accept", "application/json")
+		},
+		DecodeError: openaicompat.DecodeError(providerName),
+	}
+}
+
+func (a *Adapter) baseURL() string {
+	if strings.TrimSpace(a.BaseURL) == "" {
+		return defaultBaseURL
+	}
+	return a.BaseURL
+}
@@ -0,0 +1,72 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+// Chat sends a non-streaming chat request to Groq.
+//
+// It supports tool calls, structured output, and reasoning. The Groq
+// timings of the last response are reported on Usage.Details.
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().Chat(ctx, params)
+}
+
+// ChatStream sends a streaming chat request to Groq.
+//
+// Server tools run between streamed turns. Structured output streams as
+// StreamChunkPartialJSON chunks. Usage, with the Groq timings, is reported
+// on the done chunk.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().ChatStream(ctx, params)
+}
+
+// groqUsage is the usage Groq reports on responses, and on the x_groq field
+// of the last stream chunk.
+type groqUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+	openaicompat.Timings
+}
+
+// timedUsage adds the Groq timings of a response or stream chunk to usage.
+func timedUsage(raw []byte, usage *core.Usage) *core.Usage {
+	var envelope struct {
+		Usage *groqUsage `json:"usage"`
+		XGroq *struct {
+			Usage *groqUsage `json:"usage"`
+		} `json:"x_groq"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return usage
+	}
+
+	timings := envelope.Usage
+	if envelope.XGroq != nil && envelope.XGroq.Usage != nil {
+		timings = envelope.XGroq.Usage
+	}
+	if timings == nil {
+		return usage
+	}
+
+	if usage == nil {
+		usage = &core.Usage{
+			PromptTokens:     timings.PromptTokens,
+			CompletionTokens: timings.CompletionTokens,
+			TotalTokens:      timings.TotalTokens,
+		}
+	}
+	openaicompat.AddTimings(usage, timings.Timings)
+	return usage
+}
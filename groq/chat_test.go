@@ -0,0 +1,111 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatReportsGroqTimings(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" || r.Header.Get("Authorization") != "Bearer gsk-test" {
+			t.Errorf("unexpected request %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["model"] != "llama-3.3-70b-versatile" {
+			t.Errorf("unexpected model %v", body["model"])
+		}
+		w.Header().Set("x-request-id", "req_groq")
+		fmt.Fprint(w, `{"id":"chatcmpl-1","choices":[{"index":0,"message":{"role":"assistant","content":"Hi!"},"finish_reason":"stop"}],"usage":{"queue_time":0.0125,"prompt_tokens":12,"prompt_time":0.0008,"completion_tokens":50,"completion_time":0.2,"total_tokens":62,"total_time":0.2008},"x_groq":{"id":"req_groq"}}`)
+	}))
+	defer server.Close()
+
+	result, err := New("llama-3.3-70b-versatile", WithAPIKey("gsk-test"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if result.Text != "Hi!" || result.RequestID != "req_groq" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	usage := result.Usage
+	if usage == nil || usage.TotalTokens != 62 || usage.RequestID != "req_groq" {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+	expected := map[string]int64{
+		"queue_time_us":                12500,
+		"prompt_time_us":               800,
+		"completion_time_us":           200000,
+		"total_time_us":                200800,
+		"completion_tokens_per_second": 250,
+	}
+	for key, value := range expected {
+		if usage.Details[key] != value {
+			t.Fatalf("Details[%q] = %d, want %d (%v)", key, usage.Details[key], value, usage.Details)
+		}
+	}
+}
+
+func TestChatStreamReadsXGroqUsage(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"chatcmpl-2","choices":[{"index":0,"delta":{"role":"assistant","content":"Fast"}}],"x_groq":{"id":"req_1"}}
+
+data: {"id":"chatcmpl-2","choices":[{"index":0,"delta":{"content":"."},"finish_reason":"stop"}],"x_groq":{"id":"req_1","usage":{"queue_time":0.002,"prompt_tokens":4,"prompt_time":0.001,"completion_tokens":2,"completion_time":0.004,"total_tokens":6,"total_time":0.005}}}
+
+data: [DONE]
+
+`)
+	}))
+	defer server.Close()
+
+	stream, err := New("llama-3.1-8b-instant", WithAPIKey("gsk-test"), WithBaseURL(server.URL)).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var done core.StreamChunk
+	content := ""
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkError:
+			t.Fatalf("unexpected chunk error: %s", chunk.Error)
+		case core.StreamChunkContent:
+			content = chunk.Content
+		case core.StreamChunkDone:
+			done = chunk
+		}
+	}
+
+	if content != "Fast." || done.FinishReason != core.FinishReasonStop {
+		t.Fatalf("unexpected stream result %q, %+v", content, done)
+	}
+	if done.Usage == nil || done.Usage.TotalTokens != 6 || done.Usage.Details["queue_time_us"] != 2000 || done.Usage.Details["completion_tokens_per_second"] != 500 {
+		t.Fatalf("unexpected usage %+v", done.Usage)
+	}
+}
+
+func TestChatRequiresAPIKey(t *testing.T) {
+	t.Setenv(envGroqAPIKey, "")
+
+	_, err := New("llama-3.1-8b-instant").Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hello"}},
+	})
+	if err == nil {
+		t.Fatal("Chat() expected an error without an API key")
+	}
+}
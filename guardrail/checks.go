@@ -0,0 +1,121 @@
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// DenyList blocks texts that contain any of terms as whole words, ignoring
+// case.
+func DenyList(terms ...string) Check {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			quoted = append(quoted, regexp.QuoteMeta(term))
+		}
+	}
+	if len(quoted) == 0 {
+		return NewCheck("deny_list", func(context.Context, string) (*Result, error) { return nil, nil })
+	}
+	pattern := regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+
+	return NewCheck("deny_list", func(_ context.Context, text string) (*Result, error) {
+		match := pattern.FindString(text)
+		if match == "" {
+			return nil, nil
+		}
+		return &Result{Action: Block, Reason: fmt.Sprintf("contains denied term %q", match)}, nil
+	})
+}
+
+// Pattern blocks texts that match re.
+func Pattern(re *regexp.Regexp) Check {
+	return NewCheck("pattern", func(_ context.Context, text string) (*Result, error) {
+		if !re.MatchString(text) {
+			return nil, nil
+		}
+		return &Result{Action: Block, Reason: fmt.Sprintf("matches %s", re)}, nil
+	})
+}
+
+// Redact rewrites texts by replacing the matches of re with replacement,
+// which may refer to submatches as in regexp.Regexp.ReplaceAllString.
+func Redact(re *regexp.Regexp, replacement string) Check {
+	return NewCheck("redact", func(_ context.Context, text string) (*Result, error) {
+		matches := len(re.FindAllStringIndex(text, -1))
+		if matches == 0 {
+			return nil, nil
+		}
+		return &Result{
+			Action: Rewrite,
+			Text:   re.ReplaceAllString(text, replacement),
+			Reason: fmt.Sprintf("redacted %d matches of %s", matches, re),
+		}, nil
+	})
+}
+
+// MaxLength blocks texts longer than limit characters.
+func MaxLength(limit int) Check {
+	return NewCheck("max_length", func(_ context.Context, text string) (*Result, error) {
+		length := utf8.RuneCountInString(text)
+		if length <= limit {
+			return nil, nil
+		}
+		return &Result{Action: Block, Reason: fmt.Sprintf("text is %d characters, over the limit of %d", length, limit)}, nil
+	})
+}
+
+// Moderation blocks texts that adapter flags, such as OpenAI's moderation
+// endpoint. The reason lists the flagged categories, and the annotations
+// hold the category scores.
+func Moderation(adapter core.ModerationAdapter) Check {
+	return NewCheck("moderation", func(ctx context.Context, text string) (*Result, error) {
+		if adapter == nil {
+			return nil, errors.New("moderation adapter is required")
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, nil
+		}
+		result, err := core.Moderate(ctx, adapter, &core.ModerationParams{Inputs: []string{text}})
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Results) == 0 {
+			return nil, errors.New("moderation returned no result")
+		}
+
+		moderation := result.Results[0]
+		if !moderation.Flagged {
+			return nil, nil
+		}
+		var categories []string
+		for category, flagged := range moderation.Categories {
+			if flagged {
+				categories = append(categories, category)
+			}
+		}
+		slices.Sort(categories)
+
+		reason := "flagged"
+		if len(categories) > 0 {
+			reason += " for " + strings.Join(categories, ", ")
+		}
+		return &Result{Action: Block, Reason: reason, Annotations: scoreAnnotations(moderation.Scores)}, nil
+	})
+}
+
+// scoreAnnotations returns moderation scores as Result.Annotations.
+func scoreAnnotations(scores map[string]float64) map[string]any {
+	annotations := make(map[string]any, len(scores))
+	for category, score := range scores {
+		annotations[category] = score
+	}
+	return annotations
+}
@@ -0,0 +1,195 @@
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// ContentBlockedError is returned when a ModerationGate blocks a call.
+type ContentBlockedError struct {
+	Stage Stage
+
+	// Categories are the categories that reached their threshold, sorted.
+	Categories []string
+
+	// Scores holds the moderation scores of all categories.
+	Scores map[string]float64
+
+	// Model is the moderation model, when the provider reports it.
+	Model string
+}
+
+func (e *ContentBlockedError) Error() string {
+	flagged := make([]string, len(e.Categories))
+	for i, category := range e.Categories {
+		flagged[i] = fmt.Sprintf("%s (%.2f)", category, e.Scores[category])
+	}
+	return fmt.Sprintf("guardrail: %s blocked by moderation: %s", e.Stage, strings.Join(flagged, ", "))
+}
+
+// GateOption configures a ModerationGate.
+type GateOption func(*ModerationGate)
+
+// WithThreshold blocks content whose score for category reaches score. A
+// score above 1 never blocks, which ignores the category.
+func WithThreshold(category string, score float64) GateOption {
+	return func(g *ModerationGate) {
+		if g.Thresholds == nil {
+			g.Thresholds = make(map[string]float64)
+		}
+		g.Thresholds[category] = score
+	}
+}
+
+// WithDefaultThreshold blocks content whose score for a category without its
+// own threshold reaches score.
+func WithDefaultThreshold(score float64) GateOption {
+	return func(g *ModerationGate) {
+		g.DefaultThreshold = score
+	}
+}
+
+// WithStages selects the stages to moderate. New gates moderate both.
+func WithStages(stages ...Stage) GateOption {
+	return func(g *ModerationGate) {
+		g.Stages = stages
+	}
+}
+
+// WithGateFinding sets the function that is called for every blocked text.
+func WithGateFinding(fn func(ctx context.Context, finding Finding)) GateOption {
+	return func(g *ModerationGate) {
+		g.OnFinding = fn
+	}
+}
+
+// ModerationGate is a TextAdapter that moderates the user input before the
+// provider call and each assistant message after it, and fails calls with
+// content that reaches a category threshold with a *ContentBlockedError:
+//
+//	gate := guardrail.NewModerationGate(chat, openai.New("omni-moderation-latest"),
+//		guardrail.WithThreshold("violence", 0.4),
+//		guardrail.WithThreshold("sexual", 0.2),
+//	)
+//
+// Without any threshold for a category, the provider's flag decides. A
+// failing moderation call fails the chat call.
+type ModerationGate struct {
+	Adapter   core.TextAdapter
+	Moderator core.ModerationAdapter
+
+	// Thresholds maps categories, such as "violence", to the score at which
+	// they block.
+	Thresholds map[string]float64
+
+	// DefaultThreshold applies to categories without a threshold. Zero leaves
+	// them to the provider's flag.
+	DefaultThreshold float64
+
+	// Stages are the stages to moderate. Nil moderates input and output.
+	Stages []Stage
+
+	// OnFinding is called for every blocked text. Nil ignores them.
+	OnFinding func(ctx context.Context, finding Finding)
+}
+
+var _ core.TextAdapter = (*ModerationGate)(nil)
+
+// NewModerationGate returns a ModerationGate that moderates the calls to
+// adapter with moderator.
+func NewModerationGate(adapter core.TextAdapter, moderator core.ModerationAdapter, opts ...GateOption) *ModerationGate {
+	gate := &ModerationGate{Adapter: adapter, Moderator: moderator}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(gate)
+		}
+	}
+	return gate
+}
+
+// Chat implements core.TextAdapter.
+func (g *ModerationGate) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	return g.guard().Chat(ctx, params)
+}
+
+// ChatStream implements core.TextAdapter. With output moderation, the content
+// of each assistant message is held back until it has been moderated. A
+// blocked message ends the stream with an error chunk whose Err is the
+// *ContentBlockedError.
+func (g *ModerationGate) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	return g.guard().ChatStream(ctx, params)
+}
+
+func (g *ModerationGate) guard() *Guard {
+	guard := &Guard{Adapter: g.Adapter, OnFinding: g.OnFinding}
+	if g.moderates(StageInput) {
+		guard.Input = []Check{g.check(StageInput)}
+	}
+	if g.moderates(StageOutput) {
+		guard.Output = []Check{g.check(StageOutput)}
+	}
+	return guard
+}
+
+func (g *ModerationGate) moderates(stage Stage) bool {
+	return g.Stages == nil || slices.Contains(g.Stages, stage)
+}
+
+func (g *ModerationGate) check(stage Stage) Check {
+	return NewCheck("moderation", func(ctx context.Context, text string) (*Result, error) {
+		if g.Moderator == nil {
+			return nil, errors.New("moderation adapter is required")
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, nil
+		}
+		result, err := core.Moderate(ctx, g.Moderator, &core.ModerationParams{Inputs: []string{text}})
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Results) == 0 {
+			return nil, errors.New("moderation returned no result")
+		}
+
+		moderation := result.Results[0]
+		categories := g.blocked(moderation)
+		if len(categories) == 0 {
+			return nil, nil
+		}
+		blocked := &ContentBlockedError{Stage: stage, Categories: categories, Scores: moderation.Scores, Model: result.Model}
+		return &Result{
+			Action:      Block,
+			Reason:      "flagged for " + strings.Join(categories, ", "),
+			Annotations: scoreAnnotations(moderation.Scores),
+			Err:         blocked,
+		}, nil
+	})
+}
+
+// blocked returns the categories of moderation that reach their threshold,
+// or that the provider flagged when they have none.
+func (g *ModerationGate) blocked(moderation core.Moderation) []string {
+	var categories []string
+	for category, score := range moderation.Scores {
+		threshold, ok := g.Thresholds[category]
+		if !ok {
+			threshold = g.DefaultThreshold
+		}
+		if threshold > 0 && score >= threshold {
+			categories = append(categories, category)
+		}
+	}
+	for category, flagged := range moderation.Categories {
+		_, ok := g.Thresholds[category]
+		if flagged && !ok && g.DefaultThreshold == 0 && !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+	slices.Sort(categories)
+	return categories
+}
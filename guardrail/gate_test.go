@@ -0,0 +1,131 @@
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// scoring moderates texts with the scores of the first entry whose key the
+// text contains.
+type scoring map[string]core.Moderation
+
+func (s scoring) Moderate(_ context.Context, params *core.ModerationParams) (*core.ModerationResult, error) {
+	for term, moderation := range s {
+		if strings.Contains(params.Inputs[0], term) {
+			return &core.ModerationResult{Model: "omni-test", Results: []core.Moderation{moderation}}, nil
+		}
+	}
+	return &core.ModerationResult{Model: "omni-test", Results: []core.Moderation{{}}}, nil
+}
+
+func TestModerationGate(t *testing.T) {
+	t.Parallel()
+
+	moderator := scoring{
+		"fight": {Scores: map[string]float64{"violence": 0.45, "harassment": 0.1}},
+		"insult": {
+			Flagged:    true,
+			Categories: map[string]bool{"harassment": true},
+			Scores:     map[string]float64{"harassment": 0.8, "violence": 0.01},
+		},
+		"brawl": {Scores: map[string]float64{"violence": 0.2}},
+	}
+	adapter := core.NewMockAdapter().Reply("A brawl broke out.").Reply("Let's fight.")
+	gate := NewModerationGate(adapter, moderator, WithThreshold("violence", 0.4))
+
+	_, err := core.Chat(context.Background(), gate, userMessage("Should we fight?"))
+	var blocked *ContentBlockedError
+	if !errors.As(err, &blocked) || blocked.Stage != StageInput || len(blocked.Categories) != 1 || blocked.Categories[0] != "violence" {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if blocked.Scores["harassment"] != 0.1 || blocked.Model != "omni-test" || err.Error() != "guardrail: input blocked by moderation: violence (0.45)" {
+		t.Fatalf("blocked = %+v", blocked)
+	}
+
+	// Without a threshold, the provider's flag decides.
+	if _, err := core.Chat(context.Background(), gate, userMessage("An insult")); !errors.As(err, &blocked) || blocked.Categories[0] != "harassment" {
+		t.Fatalf("Chat() flagged error = %v", err)
+	}
+	if len(adapter.Requests()) != 0 {
+		t.Fatal("blocked input reached the adapter")
+	}
+
+	if result, err := core.Chat(context.Background(), gate, userMessage("What happened?")); err != nil || result.Text != "A brawl broke out." {
+		t.Fatalf("Chat() = %+v, %v", result, err)
+	}
+	if _, err := core.Chat(context.Background(), gate, userMessage("What now?")); !errors.As(err, &blocked) || blocked.Stage != StageOutput {
+		t.Fatalf("Chat() output error = %v", err)
+	}
+}
+
+func TestModerationGateChatStream(t *testing.T) {
+	t.Parallel()
+
+	adapter := core.NewMockAdapter().ReplyStream(
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "Start "}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "a fight."}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkDone, FinishReason: core.FinishReasonStop}},
+	)
+	gate := NewModerationGate(adapter, scoring{"fight": {Scores: map[string]float64{"violence": 0.7}}}, WithThreshold("violence", 0.5))
+
+	stream, err := core.ChatStream(context.Background(), gate, userMessage("What should we do?"))
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	var chunks []core.StreamChunk
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+
+	var blocked *ContentBlockedError
+	if len(chunks) != 1 || chunks[0].Type != core.StreamChunkError || !errors.As(chunks[0].Err, &blocked) {
+		t.Fatalf("chunks = %+v", chunks)
+	}
+	if blocked.Stage != StageOutput || blocked.Scores["violence"] != 0.7 || chunks[0].Error != blocked.Error() {
+		t.Fatalf("blocked = %+v", blocked)
+	}
+}
+
+func TestModerationGateCandidates(t *testing.T) {
+	t.Parallel()
+
+	adapter := core.NewMockAdapter(core.MockResponse{Result: &core.ChatResult{
+		Text:       "Talk it over.",
+		Candidates: []core.Candidate{{Text: "Talk it over."}, {Text: "Start a fight."}},
+	}})
+	gate := NewModerationGate(adapter, scoring{"fight": {Flagged: true, Categories: map[string]bool{"violence": true}}})
+
+	_, err := core.Chat(context.Background(), gate, userMessage("What should we do?"))
+	var blocked *ContentBlockedError
+	if !errors.As(err, &blocked) || blocked.Stage != StageOutput || blocked.Categories[0] != "violence" {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
+
+func TestModerationGateOptions(t *testing.T) {
+	t.Parallel()
+
+	moderator := scoring{"fight": {
+		Flagged:    true,
+		Categories: map[string]bool{"violence": true},
+		Scores:     map[string]float64{"violence": 0.6, "harassment": 0.3},
+	}}
+
+	// A default threshold replaces the provider's flag, and a threshold above
+	// 1 ignores a category.
+	gate := NewModerationGate(core.NewMockAdapter().Reply("ok"), moderator, WithDefaultThreshold(0.25), WithThreshold("violence", 2))
+	_, err := core.Chat(context.Background(), gate, userMessage("fight"))
+	var blocked *ContentBlockedError
+	if !errors.As(err, &blocked) || strings.Join(blocked.Categories, ",") != "harassment" {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	outputOnly := NewModerationGate(core.NewMockAdapter().Reply("ok"), moderator, WithStages(StageOutput))
+	if result, err := core.Chat(context.Background(), outputOnly, userMessage("fight")); err != nil || result.Text != "ok" {
+		t.Fatalf("Chat() output only = %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,531 @@
+// Package guardrail runs checks on the input and output of chat calls.
+//
+// A Guard wraps a TextAdapter. Before the provider call, the text of every
+// user message passes through the input checks; after it, the response text
+// passes through the output checks. A check allows the text, annotates it,
+// rewrites it, such as redacting e-mail addresses, or blocks the call:
+//
+//	adapter := guardrail.New(openai.New("gpt-4o-mini"),
+//		guardrail.WithInputChecks(
+//			guardrail.DenyList("password", "api key"),
+//			guardrail.Moderation(openai.New("omni-moderation-latest")),
+//		),
+//		guardrail.WithOutputChecks(guardrail.Redact(emailPattern, "[email]")),
+//	)
+//	result, err := core.Chat(ctx, adapter, params)
+//	var blocked *guardrail.BlockedError
+//	if errors.As(err, &blocked) {
+//		// blocked.Check and blocked.Reason say why.
+//	}
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// Action is what a check decided about a text.
+type Action int
+
+const (
+	// Allow passes the text on unchanged.
+	Allow Action = iota
+	// Annotate passes the text on unchanged and reports a finding.
+	Annotate
+	// Rewrite replaces the text with Result.Text.
+	Rewrite
+	// Block fails the call with a *BlockedError, or with Result.Err when set.
+	Block
+)
+
+// String returns the lowercase name of a, such as "block".
+func (a Action) String() string {
+	switch a {
+	case Allow:
+		return "allow"
+	case Annotate:
+		return "annotate"
+	case Rewrite:
+		return "rewrite"
+	case Block:
+		return "block"
+	}
+	return fmt.Sprintf("action(%d)", int(a))
+}
+
+// Result is the decision of a check.
+type Result struct {
+	Action Action
+
+	// Text replaces the checked text when Action is Rewrite.
+	Text string
+
+	// Reason explains the decision, such as the denied term that was found.
+	Reason string
+
+	// Annotations carries details for Guard.OnFinding, such as moderation
+	// scores.
+	Annotations map[string]any
+
+	// Err, when set on a blocking result, is returned instead of a
+	// *BlockedError, such as the *ContentBlockedError of a ModerationGate.
+	Err error
+}
+
+// Check inspects a text. It returns nil to allow the text. An error fails
+// the call, so a check whose backend is unavailable never lets a text
+// through unchecked.
+type Check interface {
+	Name() string
+	Check(ctx context.Context, text string) (*Result, error)
+}
+
+type funcCheck struct {
+	name string
+	fn   func(ctx context.Context, text string) (*Result, error)
+}
+
+func (c funcCheck) Name() string { return c.name }
+
+func (c funcCheck) Check(ctx context.Context, text string) (*Result, error) {
+	return c.fn(ctx, text)
+}
+
+// NewCheck returns a Check named name that decides with fn.
+func NewCheck(name string, fn func(ctx context.Context, text string) (*Result, error)) Check {
+	return funcCheck{name: name, fn: fn}
+}
+
+// Stage is the side of the provider call a check ran on.
+type Stage string
+
+const (
+	StageInput  Stage = "input"
+	StageOutput Stage = "output"
+)
+
+// Finding reports a check that did not simply allow a text.
+type Finding struct {
+	Stage  Stage
+	Check  string
+	Action Action
+	Reason string
+
+	// Annotations are the Result.Annotations of the check.
+	Annotations map[string]any
+
+	// MessageIndex is the index of the checked message in ChatParams.Messages
+	// for input checks, and -1 for output checks.
+	MessageIndex int
+}
+
+// BlockedError is returned when a check blocks a call.
+type BlockedError struct {
+	Stage  Stage
+	Check  string
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	message := fmt.Sprintf("guardrail: %s blocked by %s", e.Stage, e.Check)
+	if e.Reason != "" {
+		message += ": " + e.Reason
+	}
+	return message
+}
+
+// Option configures a Guard.
+type Option func(*Guard)
+
+// WithInputChecks appends checks for the user messages of a request.
+func WithInputChecks(checks ...Check) Option {
+	return func(g *Guard) {
+		g.Input = append(g.Input, checks...)
+	}
+}
+
+// WithOutputChecks appends checks for the response text.
+func WithOutputChecks(checks ...Check) Option {
+	return func(g *Guard) {
+		g.Output = append(g.Output, checks...)
+	}
+}
+
+// WithOnFinding sets the function that is called for every finding.
+func WithOnFinding(fn func(ctx context.Context, finding Finding)) Option {
+	return func(g *Guard) {
+		g.OnFinding = fn
+	}
+}
+
+// Guard is a TextAdapter that runs checks on the input and output of the
+// wrapped adapter. Checks run in order, and each sees the text as rewritten
+// by the checks before it.
+//
+// Input checks see the text of user messages; system prompts and tool
+// results are not checked. Output checks see the text of each assistant
+// message. A Guard is safe for concurrent use if its checks are.
+type Guard struct {
+	Adapter core.TextAdapter
+	Input   []Check
+	Output  []Check
+
+	// OnFinding is called for every check that annotated, rewrote, or blocked
+	// a text, such as to log blocked prompts. Nil ignores findings.
+	OnFinding func(ctx context.Context, finding Finding)
+}
+
+var _ core.TextAdapter = (*Guard)(nil)
+
+// New returns a Guard around adapter.
+func New(adapter core.TextAdapter, opts ...Option) *Guard {
+	guard := &Guard{Adapter: adapter}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(guard)
+		}
+	}
+	return guard
+}
+
+// Chat implements core.TextAdapter. When an output check rewrites the
+// response, both ChatResult.Text and the last assistant message change.
+// Output checks run on every candidate, so a blocked alternative fails the
+// whole call.
+func (g *Guard) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if g.Adapter == nil {
+		return nil, errors.New("guardrail: adapter is required")
+	}
+	params, err := g.checkInput(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := g.Adapter.Chat(ctx, params)
+	if err != nil || result == nil || len(g.Output) == 0 {
+		return result, err
+	}
+
+	if len(result.Candidates) > 0 {
+		candidates := slices.Clone(result.Candidates)
+		for i := range candidates {
+			if candidates[i].Text == "" {
+				continue
+			}
+			if candidates[i].Text, err = g.run(ctx, StageOutput, g.Output, candidates[i].Text, -1); err != nil {
+				return nil, err
+			}
+		}
+		first := result.Candidates[0].Text
+		result.Candidates = candidates
+		if result.Text == first {
+			rewriteText(result, candidates[0].Text)
+			return result, nil
+		}
+	}
+	if result.Text == "" {
+		return result, nil
+	}
+
+	text, err := g.run(ctx, StageOutput, g.Output, result.Text, -1)
+	if err != nil {
+		return nil, err
+	}
+	rewriteText(result, text)
+	return result, nil
+}
+
+// rewriteText replaces the response text of result with text.
+func rewriteText(result *core.ChatResult, text string) {
+	if text != result.Text {
+		result.Messages = rewriteLastAssistant(result.Messages, result.Text, text)
+		result.Text = text
+	}
+}
+
+// ChatStream implements core.TextAdapter. With output checks, the content
+// and partial JSON of each assistant message are held back until the message
+// ends, so that no unchecked text reaches the caller; other chunks pass
+// through as they arrive. A blocked message ends the stream with an error
+// chunk whose Err is the *BlockedError or the check's Result.Err.
+func (g *Guard) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if g.Adapter == nil {
+		return nil, errors.New("guardrail: adapter is required")
+	}
+	params, err := g.checkInput(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := g.Adapter.ChatStream(ctx, params)
+	if err != nil || len(g.Output) == 0 {
+		return in, err
+	}
+
+	out := make(chan core.StreamChunk)
+	go func() {
+		defer close(out)
+		defer func() {
+			// Drain so the adapter can finish after a block or cancellation.
+			for range in {
+			}
+		}()
+
+		send := func(chunk core.StreamChunk) bool {
+			select {
+			case out <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// provider and visible hold, per chunk type, the cumulative Content
+		// as sent by the adapter and as seen by the caller, which differ once
+		// a check has rewritten the text.
+		provider := map[string]string{}
+		visible := map[string]string{}
+
+		var pending []core.StreamChunk
+		flush := func() bool {
+			if len(pending) == 0 {
+				return true
+			}
+			var text strings.Builder
+			for _, chunk := range pending {
+				text.WriteString(chunk.Delta)
+			}
+			chunks := pending
+			pending = nil
+			first, last := chunks[0], chunks[len(chunks)-1]
+
+			checked, err := g.run(ctx, StageOutput, g.Output, text.String(), -1)
+			if err != nil {
+				send(core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: last.RequestID})
+				return false
+			}
+
+			kind := first.Type
+			if checked != text.String() {
+				chunks = []core.StreamChunk{{
+					Type:      kind,
+					Role:      first.Role,
+					Delta:     checked,
+					Content:   visible[kind] + checked,
+					RequestID: last.RequestID,
+				}}
+			} else if visible[kind] != provider[kind] {
+				chunks = slices.Clone(chunks)
+				for i := range chunks {
+					if rest, ok := strings.CutPrefix(chunks[i].Content, provider[kind]); ok {
+						chunks[i].Content = visible[kind] + rest
+					}
+				}
+			}
+
+			if last.Content != "" {
+				provider[kind] = last.Content
+			} else {
+				provider[kind] += text.String()
+			}
+			visible[kind] = chunks[len(chunks)-1].Content
+			if visible[kind] == "" {
+				visible[kind] = provider[kind]
+			}
+
+			for _, chunk := range chunks {
+				if !send(chunk) {
+					return false
+				}
+			}
+			return true
+		}
+
+		for chunk := range in {
+			if chunk.Type == core.StreamChunkContent || chunk.Type == core.StreamChunkPartialJSON {
+				if len(pending) > 0 && pending[0].Type != chunk.Type && !flush() {
+					return
+				}
+				pending = append(pending, chunk)
+				continue
+			}
+			if !flush() || !send(chunk) {
+				return
+			}
+		}
+		flush()
+	}()
+	return out, nil
+}
+
+// checkInput runs the input checks on the user messages of params. It
+// returns a copy of params when a check rewrote a message.
+func (g *Guard) checkInput(ctx context.Context, params *core.ChatParams) (*core.ChatParams, error) {
+	if params == nil || len(g.Input) == 0 {
+		return params, nil
+	}
+
+	var messages []core.MessageUnion
+	for idx, message := range params.Messages {
+		rewritten, err := g.checkMessage(ctx, message, idx)
+		if err != nil {
+			return nil, err
+		}
+		if rewritten == nil {
+			continue
+		}
+		if messages == nil {
+			messages = slices.Clone(params.Messages)
+		}
+		messages[idx] = rewritten
+	}
+	if messages == nil {
+		return params, nil
+	}
+
+	checked := *params
+	checked.Messages = messages
+	return &checked, nil
+}
+
+// checkMessage runs the input checks on the text of a user message. It
+// returns the rewritten message, or nil when the message is unchanged.
+func (g *Guard) checkMessage(ctx context.Context, message core.MessageUnion, idx int) (core.MessageUnion, error) {
+	switch typed := message.(type) {
+	case core.TextMessagePart:
+		if typed.Role != core.RoleUser {
+			return nil, nil
+		}
+		text, err := g.run(ctx, StageInput, g.Input, typed.Content, idx)
+		if err != nil || text == typed.Content {
+			return nil, err
+		}
+		typed.Content = text
+		return typed, nil
+	case *core.TextMessagePart:
+		if typed == nil {
+			return nil, nil
+		}
+		return g.checkMessage(ctx, *typed, idx)
+
+	case core.ContentMessagePart:
+		if typed.Role != core.RoleUser {
+			return nil, nil
+		}
+		var parts []core.ContentPart
+		for partIdx, part := range typed.Parts {
+			text, ok := textOf(part)
+			if !ok {
+				continue
+			}
+			checked, err := g.run(ctx, StageInput, g.Input, text, idx)
+			if err != nil {
+				return nil, err
+			}
+			if checked == text {
+				continue
+			}
+			if parts == nil {
+				parts = slices.Clone(typed.Parts)
+			}
+			parts[partIdx] = core.TextPart{Text: checked}
+		}
+		if parts == nil {
+			return nil, nil
+		}
+		typed.Parts = parts
+		return typed, nil
+	case *core.ContentMessagePart:
+		if typed == nil {
+			return nil, nil
+		}
+		return g.checkMessage(ctx, *typed, idx)
+	}
+	return nil, nil
+}
+
+// run passes text through checks and returns it as rewritten by them.
+func (g *Guard) run(ctx context.Context, stage Stage, checks []Check, text string, idx int) (string, error) {
+	for _, check := range checks {
+		if check == nil {
+			continue
+		}
+		result, err := check.Check(ctx, text)
+		if err != nil {
+			return "", fmt.Errorf("guardrail: %s check %s: %w", stage, check.Name(), err)
+		}
+		if result == nil || result.Action == Allow {
+			continue
+		}
+
+		if g.OnFinding != nil {
+			g.OnFinding(ctx, Finding{
+				Stage:        stage,
+				Check:        check.Name(),
+				Action:       result.Action,
+				Reason:       result.Reason,
+				Annotations:  result.Annotations,
+				MessageIndex: idx,
+			})
+		}
+		switch result.Action {
+		case Rewrite:
+			text = result.Text
+		case Block:
+			if result.Err != nil {
+				return "", result.Err
+			}
+			return "", &BlockedError{Stage: stage, Check: check.Name(), Reason: result.Reason}
+		}
+	}
+	return text, nil
+}
+
+func textOf(part core.ContentPart) (string, bool) {
+	switch typed := part.(type) {
+	case core.TextPart:
+		return typed.Text, true
+	case *core.TextPart:
+		if typed != nil {
+			return typed.Text, true
+		}
+	}
+	return "", false
+}
+
+// rewriteLastAssistant replaces the last assistant message when it holds the
+// original response text.
+func rewriteLastAssistant(messages []core.MessageUnion, original, text string) []core.MessageUnion {
+	if len(messages) == 0 {
+		return messages
+	}
+	last := len(messages) - 1
+	switch message := messages[last].(type) {
+	case core.TextMessagePart:
+		if message.Role != core.RoleAssistant || message.Content != original {
+			return messages
+		}
+	case *core.TextMessagePart:
+		if message == nil || message.Role != core.RoleAssistant || message.Content != original {
+			return messages
+		}
+	default:
+		return messages
+	}
+	messages = slices.Clone(messages)
+	switch message := messages[last].(type) {
+	case core.TextMessagePart:
+		message.Content = text
+		messages[last] = message
+	case *core.TextMessagePart:
+		copied := *message
+		copied.Content = text
+		messages[last] = &copied
+	}
+	return messages
+}
@@ -0,0 +1,290 @@
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+var email = regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)+`)
+
+func userMessage(text string) *core.ChatParams {
+	return &core.ChatParams{Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: text}}}
+}
+
+type moderator struct {
+	result core.Moderation
+	err    error
+}
+
+func (m moderator) Moderate(context.Context, *core.ModerationParams) (*core.ModerationResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &core.ModerationResult{Results: []core.Moderation{m.result}}, nil
+}
+
+func TestGuardInput(t *testing.T) {
+	t.Parallel()
+
+	adapter := core.NewMockAdapter().Reply("Noted.")
+	var mu sync.Mutex
+	var findings []Finding
+	guard := New(adapter,
+		WithInputChecks(Redact(email, "[email]"), DenyList("secret token")),
+		WithOnFinding(func(_ context.Context, finding Finding) {
+			mu.Lock()
+			defer mu.Unlock()
+			findings = append(findings, finding)
+		}),
+	)
+
+	params := &core.ChatParams{
+		SystemPrompts: []string{"Write to admin@example.com."},
+		Messages: []core.MessageUnion{core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+			core.TextPart{Text: "Mail me at jane@example.com"},
+			core.ImagePart{Source: core.URLSource{URL: "https://example.com/cat.png"}},
+		}}},
+	}
+	if _, err := core.Chat(context.Background(), guard, params); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	sent := adapter.Requests()[0]
+	if text := sent.Messages[0].(core.ContentMessagePart).Parts[0].(core.TextPart).Text; text != "Mail me at [email]" {
+		t.Fatalf("sent text = %q", text)
+	}
+	if sent.SystemPrompts[0] != "Write to admin@example.com." {
+		t.Fatalf("system prompt = %q", sent.SystemPrompts[0])
+	}
+	if original := params.Messages[0].(core.ContentMessagePart).Parts[0].(core.TextPart).Text; original != "Mail me at jane@example.com" {
+		t.Fatalf("params were modified: %q", original)
+	}
+	if len(findings) != 1 || findings[0].Check != "redact" || findings[0].Action != Rewrite || findings[0].Stage != StageInput {
+		t.Fatalf("findings = %+v", findings)
+	}
+
+	_, err := core.Chat(context.Background(), guard, userMessage("Here is my Secret Token."))
+	var blocked *BlockedError
+	if !errors.As(err, &blocked) || blocked.Check != "deny_list" || blocked.Stage != StageInput || !strings.Contains(blocked.Reason, "Secret Token") {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if len(adapter.Requests()) != 1 {
+		t.Fatalf("blocked request reached the adapter")
+	}
+}
+
+func TestGuardOutput(t *testing.T) {
+	t.Parallel()
+
+	adapter := core.NewMockAdapter().Reply("Contact bob@example.com.").Reply("I will build a weapon.")
+	guard := New(adapter, WithOutputChecks(
+		Redact(email, "[email]"),
+		NewCheck("no_weapons", func(_ context.Context, text string) (*Result, error) {
+			if strings.Contains(text, "weapon") {
+				return &Result{Action: Block, Reason: "weapons"}, nil
+			}
+			return nil, nil
+		}),
+	))
+
+	result, err := core.Chat(context.Background(), guard, userMessage("Who to contact?"))
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Text != "Contact [email]." {
+		t.Fatalf("text = %q", result.Text)
+	}
+	if last := result.Messages[len(result.Messages)-1].(core.TextMessagePart); last.Content != "Contact [email]." {
+		t.Fatalf("last message = %+v", last)
+	}
+
+	_, err = core.Chat(context.Background(), guard, userMessage("Plans?"))
+	var blocked *BlockedError
+	if !errors.As(err, &blocked) || blocked.Stage != StageOutput || blocked.Check != "no_weapons" {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
+
+func TestGuardOutputCandidates(t *testing.T) {
+	t.Parallel()
+
+	reply := func(texts ...string) core.MockResponse {
+		result := &core.ChatResult{Text: texts[0], FinishReason: core.FinishReasonStop}
+		for _, text := range texts {
+			result.Candidates = append(result.Candidates, core.Candidate{Text: text, FinishReason: core.FinishReasonStop})
+		}
+		return core.MockResponse{Result: result}
+	}
+	adapter := core.NewMockAdapter(
+		reply("Mail bob@example.com.", "Mail amy@example.com."),
+		reply("Nothing to share.", "The secret token is 42."),
+	)
+	guard := New(adapter, WithOutputChecks(Redact(email, "[email]"), DenyList("secret token")))
+
+	result, err := core.Chat(context.Background(), guard, userMessage("Who to mail?"))
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Text != "Mail [email]." || result.Candidates[0].Text != "Mail [email]." || result.Candidates[1].Text != "Mail [email]." {
+		t.Fatalf("result = %+v", result)
+	}
+	if last := result.Messages[len(result.Messages)-1].(core.TextMessagePart); last.Content != "Mail [email]." {
+		t.Fatalf("last message = %+v", last)
+	}
+
+	_, err = core.Chat(context.Background(), guard, userMessage("Any secrets?"))
+	var blocked *BlockedError
+	if !errors.As(err, &blocked) || blocked.Stage != StageOutput || blocked.Check != "deny_list" {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
+
+func TestGuardChatStream(t *testing.T) {
+	t.Parallel()
+
+	adapter := core.NewMockAdapter().ReplyStream(
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "Mail "}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "bob@exam"}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &core.ToolCall{ID: "call_1", Name: "lookup"}}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "Done."}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkDone, FinishReason: core.FinishReasonStop}},
+	).ReplyStream(
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "Mail "}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "bob@example.com", RequestID: "req_1"}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkDone, FinishReason: core.FinishReasonStop}},
+	)
+	guard := New(adapter, WithOutputChecks(Redact(email, "[email]"), DenyList("exam")))
+
+	collect := func() []core.StreamChunk {
+		stream, err := core.ChatStream(context.Background(), guard, userMessage("Hi"))
+		if err != nil {
+			t.Fatalf("ChatStream() error = %v", err)
+		}
+		var chunks []core.StreamChunk
+		for chunk := range stream {
+			chunks = append(chunks, chunk)
+		}
+		return chunks
+	}
+
+	// "bob@exam" is no e-mail address, but the whole word "exam" is denied.
+	chunks := collect()
+	if len(chunks) != 1 || chunks[0].Type != core.StreamChunkError || !strings.Contains(chunks[0].Error, "deny_list") {
+		t.Fatalf("blocked chunks = %+v", chunks)
+	}
+
+	chunks = collect()
+	if len(chunks) != 2 || chunks[0].Delta != "Mail [email]" || chunks[0].Content != "Mail [email]" || chunks[0].RequestID != "req_1" || chunks[1].Type != core.StreamChunkDone {
+		t.Fatalf("rewritten chunks = %+v", chunks)
+	}
+}
+
+func TestGuardChatStreamPartialJSON(t *testing.T) {
+	t.Parallel()
+
+	adapter := core.NewMockAdapter().ReplyStream(
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkPartialJSON, Delta: `{"contact":`}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkPartialJSON, Delta: `"bob@example.com"}`}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkDone, FinishReason: core.FinishReasonStop}},
+	).ReplyStream(
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "Mail bob@example.com"}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &core.ToolCall{ID: "call_1", Name: "lookup"}}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: " today."}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkDone, FinishReason: core.FinishReasonStop}},
+	)
+	guard := New(adapter, WithOutputChecks(Redact(email, "[email]")))
+
+	collect := func() []core.StreamChunk {
+		stream, err := core.ChatStream(context.Background(), guard, userMessage("Hi"))
+		if err != nil {
+			t.Fatalf("ChatStream() error = %v", err)
+		}
+		var chunks []core.StreamChunk
+		for chunk := range stream {
+			chunks = append(chunks, chunk)
+		}
+		return chunks
+	}
+
+	chunks := collect()
+	if len(chunks) != 2 || chunks[0].Type != core.StreamChunkPartialJSON || chunks[0].Delta != `{"contact":"[email]"}` || chunks[0].Content != `{"contact":"[email]"}` {
+		t.Fatalf("partial JSON chunks = %+v", chunks)
+	}
+
+	// The cumulative content of later chunks follows the rewritten text.
+	chunks = collect()
+	if len(chunks) != 4 || chunks[0].Content != "Mail [email]" || chunks[2].Delta != " today." || chunks[2].Content != "Mail [email] today." {
+		t.Fatalf("content chunks = %+v", chunks)
+	}
+}
+
+func TestRewriteLastAssistantKeepsMessageFields(t *testing.T) {
+	t.Parallel()
+
+	original := core.TextMessagePart{Role: core.RoleAssistant, Content: "Mail bob@example.com.", Name: "helper", ID: "msg_1", Metadata: map[string]any{"turn": 1}}
+	for _, message := range []core.MessageUnion{original, &original} {
+		messages := rewriteLastAssistant([]core.MessageUnion{message}, original.Content, "Mail [email].")
+		var got core.TextMessagePart
+		switch typed := messages[0].(type) {
+		case core.TextMessagePart:
+			got = typed
+		case *core.TextMessagePart:
+			got = *typed
+		}
+		if got.Content != "Mail [email]." || got.Name != "helper" || got.ID != "msg_1" || got.Metadata["turn"] != 1 {
+			t.Fatalf("rewritten message = %+v", messages[0])
+		}
+	}
+	if original.Content != "Mail bob@example.com." {
+		t.Fatalf("original message changed: %+v", original)
+	}
+}
+
+func TestGuardCheckErrorFailsClosed(t *testing.T) {
+	t.Parallel()
+
+	adapter := core.NewMockAdapter().Reply("unreachable")
+	guard := New(adapter, WithInputChecks(Moderation(moderator{err: errors.New("unavailable")})))
+	if _, err := core.Chat(context.Background(), guard, userMessage("Hello")); err == nil || !strings.Contains(err.Error(), "input check moderation: unavailable") {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if len(adapter.Requests()) != 0 {
+		t.Fatal("request reached the adapter after a failed check")
+	}
+}
+
+func TestChecks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	if result, _ := DenyList("ass").Check(ctx, "Let me assist you."); result != nil {
+		t.Fatalf("DenyList() matched inside a word: %+v", result)
+	}
+	if result, _ := Pattern(regexp.MustCompile(`\d{4}-\d{4}`)).Check(ctx, "card 1234-5678"); result == nil || result.Action != Block {
+		t.Fatalf("Pattern() = %+v", result)
+	}
+	if result, _ := MaxLength(5).Check(ctx, "héllo"); result != nil {
+		t.Fatalf("MaxLength() counted bytes: %+v", result)
+	}
+	if result, _ := MaxLength(4).Check(ctx, "héllo"); result == nil || result.Action != Block {
+		t.Fatalf("MaxLength() = %+v", result)
+	}
+
+	flagged := moderator{result: core.Moderation{
+		Flagged:    true,
+		Categories: map[string]bool{"violence": true, "harassment": true, "sexual": false},
+		Scores:     map[string]float64{"violence": 0.9, "harassment": 0.6, "sexual": 0.01},
+	}}
+	result, err := Moderation(flagged).Check(ctx, "threat")
+	if err != nil || result.Action != Block || result.Reason != "flagged for harassment, violence" || result.Annotations["violence"] != 0.9 {
+		t.Fatalf("Moderation() = %+v, %v", result, err)
+	}
+	if result, _ := Moderation(moderator{}).Check(ctx, "hello"); result != nil {
+		t.Fatalf("Moderation() unflagged = %+v", result)
+	}
+}
@@ -0,0 +1,154 @@
+// Package accesstoken caches the short-lived bearer tokens that some
+// provider APIs authorize requests with, such as the OAuth2 tokens of Vertex
+// AI and the IAM tokens of watsonx.ai.
+//
+// A Cache reuses a token until shortly before it expires and can be told to
+// drop it when the API rejects it. Exchange and Decode post a token request
+// form and read the JSON token responses of these services, including their
+// error envelopes.
+package accesstoken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxResponseSize limits how much of a token response is read.
+const maxResponseSize = 1 << 20
+
+// Token is an access token.
+type Token struct {
+	AccessToken string
+	Expiry      time.Time
+}
+
+// Source returns access tokens.
+type Source interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// Static is a Source that returns one access token, which is never
+// refreshed.
+type Static string
+
+// Token implements Source.
+func (s Static) Token(context.Context) (*Token, error) {
+	return &Token{AccessToken: string(s)}, nil
+}
+
+// Cache is a Source that reuses the token of Source until RefreshWindow
+// before it expires, or until Invalidate is called.
+type Cache struct {
+	Source Source
+
+	// RefreshWindow is how long before its expiry a token is replaced.
+	RefreshWindow time.Duration
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// Token implements Source.
+func (c *Cache) Token(ctx context.Context) (*Token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.valid() {
+		return c.token, nil
+	}
+	token, err := c.Source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.token = token
+	return token, nil
+}
+
+// Invalidate drops the cached token so that the next call fetches a new one.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = nil
+}
+
+// valid reports whether the cached token can be used for another request.
+func (c *Cache) valid() bool {
+	if c.token == nil || c.token.AccessToken == "" {
+		return false
+	}
+	return c.token.Expiry.IsZero() || time.Until(c.token.Expiry) > c.RefreshWindow
+}
+
+// Exchange posts form to tokenURL with client and decodes the token in the
+// response. Errors are prefixed with provider.
+func Exchange(ctx context.Context, client *http.Client, provider, tokenURL string, form url.Values) (*Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%s: build token request: %w", provider, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: token request failed: %w", provider, err)
+	}
+	defer resp.Body.Close()
+	return Decode(provider, resp)
+}
+
+// Decode reads the token in resp. It accepts the OAuth2 response with
+// expires_in and the IBM IAM response with an expiration timestamp, and
+// reports the error of either envelope. Errors are prefixed with provider.
+func Decode(provider string, resp *http.Response) (*Token, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%s: read token response: %w", provider, err)
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Expiration  int64  `json:"expiration"`
+
+		// OAuth2 errors.
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+
+		// IBM IAM errors.
+		ErrorCode    string `json:"errorCode"`
+		ErrorMessage string `json:"errorMessage"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil && resp.StatusCode < http.StatusBadRequest {
+		return nil, fmt.Errorf("%s: decode token response: %w", provider, err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest || payload.AccessToken == "" {
+		var message string
+		switch {
+		case payload.Error != "":
+			message = strings.TrimSpace(payload.Error + ": " + payload.ErrorDescription)
+		case payload.ErrorMessage != "":
+			message = strings.TrimSpace(payload.ErrorCode + ": " + payload.ErrorMessage)
+		default:
+			message = strings.TrimSpace(string(body))
+		}
+		return nil, fmt.Errorf("%s: token request status %d: %s", provider, resp.StatusCode, message)
+	}
+
+	token := &Token{AccessToken: payload.AccessToken}
+	switch {
+	case payload.Expiration > 0:
+		token.Expiry = time.Unix(payload.Expiration, 0)
+	case payload.ExpiresIn > 0:
+		token.Expiry = time.Now().Add(time.Duration(payload.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
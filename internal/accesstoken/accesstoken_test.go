@@ -0,0 +1,95 @@
+package accesstoken
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingSource struct {
+	calls  atomic.Int32
+	expiry time.Duration
+}
+
+func (s *countingSource) Token(context.Context) (*Token, error) {
+	s.calls.Add(1)
+	return &Token{AccessToken: "token", Expiry: time.Now().Add(s.expiry)}, nil
+}
+
+func TestCacheRefreshesBeforeExpiry(t *testing.T) {
+	t.Parallel()
+
+	fresh := &countingSource{expiry: time.Hour}
+	cache := &Cache{Source: fresh, RefreshWindow: time.Minute}
+	for range 3 {
+		if _, err := cache.Token(context.Background()); err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+	}
+	if fresh.calls.Load() != 1 {
+		t.Fatalf("fresh token fetched %d times", fresh.calls.Load())
+	}
+
+	cache.Invalidate()
+	if _, err := cache.Token(context.Background()); err != nil || fresh.calls.Load() != 2 {
+		t.Fatalf("invalidated token fetched %d times, error = %v", fresh.calls.Load(), err)
+	}
+
+	expiring := &countingSource{expiry: 30 * time.Second}
+	cache = &Cache{Source: expiring, RefreshWindow: time.Minute}
+	for range 2 {
+		if _, err := cache.Token(context.Background()); err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+	}
+	if expiring.calls.Load() != 2 {
+		t.Fatalf("expiring token fetched %d times", expiring.calls.Load())
+	}
+}
+
+func TestExchangeDecodesResponses(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PostForm.Get("grant_type") {
+		case "oauth":
+			_, _ = w.Write([]byte(`{"access_token":"oauth-token","expires_in":3600}`))
+		case "iam":
+			_, _ = w.Write([]byte(`{"access_token":"iam-token","expires_in":3600,"expiration":4102444800}`))
+		case "oauth-error":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"Bad assertion"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errorCode":"BXNIM0415E","errorMessage":"Provided API key could not be found."}`))
+		}
+	}))
+	defer server.Close()
+
+	exchange := func(grant string) (*Token, error) {
+		return Exchange(context.Background(), server.Client(), "test", server.URL, map[string][]string{"grant_type": {grant}})
+	}
+
+	token, err := exchange("oauth")
+	if err != nil || token.AccessToken != "oauth-token" || time.Until(token.Expiry) < 59*time.Minute {
+		t.Fatalf("Exchange() = %+v, %v", token, err)
+	}
+	token, err = exchange("iam")
+	if err != nil || token.AccessToken != "iam-token" || token.Expiry.Unix() != 4102444800 {
+		t.Fatalf("Exchange() = %+v, %v", token, err)
+	}
+	if _, err := exchange("oauth-error"); err == nil || !strings.Contains(err.Error(), "test: token request status 400: invalid_grant: Bad assertion") {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if _, err := exchange("iam-error"); err == nil || !strings.Contains(err.Error(), "BXNIM0415E: Provided API key could not be found.") {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+}
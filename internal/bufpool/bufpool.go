@@ -0,0 +1,55 @@
+// Package bufpool pools the buffers used to marshal request bodies, read
+// response bodies, and scan streamed responses, so busy adapters do not
+// allocate them for every request.
+package bufpool
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+)
+
+const (
+	// ScanBufferSize is the initial size of the buffers used by NewScanner.
+	ScanBufferSize = 64 << 10
+
+	// maxPooledSize keeps a buffer that grew for one large body from
+	// holding on to its memory in the pool.
+	maxPooledSize = 4 << 20
+)
+
+var (
+	buffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+	scanBuffers = sync.Pool{New: func() any {
+		buf := make([]byte, ScanBufferSize)
+		return &buf
+	}}
+)
+
+// GetBuffer returns an empty buffer from the pool.
+func GetBuffer() *bytes.Buffer {
+	buf := buffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// PutBuffer returns buf to the pool. buf and any slice of its contents must
+// not be used afterwards.
+func PutBuffer(buf *bytes.Buffer) {
+	if buf == nil || buf.Cap() > maxPooledSize {
+		return
+	}
+	buffers.Put(buf)
+}
+
+// NewScanner returns a bufio.Scanner over r that starts with a pooled buffer
+// and accepts tokens up to maxTokenSize bytes. Call release once scanning is
+// done to return the buffer; tokens must not be used afterwards.
+func NewScanner(r io.Reader, maxTokenSize int) (scanner *bufio.Scanner, release func()) {
+	buf := scanBuffers.Get().(*[]byte)
+	scanner = bufio.NewScanner(r)
+	scanner.Buffer((*buf)[:0], maxTokenSize)
+	return scanner, func() { scanBuffers.Put(buf) }
+}
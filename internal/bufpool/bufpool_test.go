@@ -0,0 +1,57 @@
+package bufpool
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGetBufferIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	buf := GetBuffer()
+	buf.WriteString("leftover")
+	PutBuffer(buf)
+
+	for range 4 {
+		buf := GetBuffer()
+		if buf.Len() != 0 {
+			t.Fatalf("GetBuffer() holds %q", buf.String())
+		}
+		PutBuffer(buf)
+	}
+	PutBuffer(nil)
+}
+
+func TestNewScannerGrowsToMaxTokenSize(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("x", ScanBufferSize*2)
+	scanner, release := NewScanner(strings.NewReader("short\n"+long+"\n"), ScanBufferSize*4)
+	defer release()
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if len(lines) != 2 || lines[0] != "short" || lines[1] != long {
+		t.Fatalf("scanned %d lines", len(lines))
+	}
+}
+
+func TestNewScannerRejectsTokensOverMax(t *testing.T) {
+	t.Parallel()
+
+	scanner, release := NewScanner(strings.NewReader(strings.Repeat("x", ScanBufferSize*3)+"\n"), ScanBufferSize*2)
+	defer release()
+
+	for scanner.Scan() {
+	}
+	if err := scanner.Err(); !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("Err() = %v, want bufio.ErrTooLong", err)
+	}
+}
@@ -0,0 +1,367 @@
+// Package httpclient is the HTTP transport shared by the provider adapters.
+//
+// A Client builds each request from a path and a body, sets the headers the
+// provider needs, turns error responses into errors with the provider's
+// decoder, and retries failed attempts when a retry hook allows it, so every
+// adapter sends requests and reports failures the same way.
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m43i/go-ai/internal/bufpool"
+)
+
+const (
+	// maxErrorBodySize limits how much of an error response the default
+	// decoder reads.
+	maxErrorBodySize = 2 << 20
+
+	// minGzipSize is the smallest request body worth compressing.
+	minGzipSize = 1 << 10
+)
+
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+// Client sends requests to one provider API.
+type Client struct {
+	// HTTPClient sends the requests. Nil uses http.DefaultClient.
+	HTTPClient *http.Client
+
+	// BaseURL is prefixed to each request path.
+	BaseURL string
+
+	// Provider prefixes error messages, such as "openai".
+	Provider string
+
+	// Header sets the headers every request needs, such as authentication.
+	// Request.Header is applied afterwards.
+	Header func(http.Header)
+
+	// DecodeError turns a response with an error status into an error. The
+	// client closes the body afterwards. Nil reports the status and body.
+	DecodeError func(*http.Response) error
+
+	// Retry reports whether a request is sent again after attempt, counted
+	// from zero, failed with err from DecodeError, and how long to wait
+	// first. Transport errors are not retried. Nil disables retries.
+	Retry func(attempt int, err error) (time.Duration, bool)
+
+	// Gzip compresses request bodies of 1 KiB or more and asks for gzip
+	// responses explicitly. Gzip responses are decompressed whether or not
+	// it is set.
+	Gzip bool
+}
+
+// Request describes one API call.
+type Request struct {
+	// Method defaults to POST, or GET when Body is nil.
+	Method string
+
+	// Path is appended to Client.BaseURL. It may include a query.
+	Path string
+
+	// Name describes the request in error messages, such as "embeddings"
+	// in "openai: embeddings request failed".
+	Name string
+
+	// Body is marshaled as JSON, or sent as is when it is a []byte.
+	Body any
+
+	// ContentType of Body, "application/json" when empty.
+	ContentType string
+
+	// Header holds headers for this request only.
+	Header http.Header
+}
+
+// Response is a successful response whose body has been read.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// Duration is the time from sending the first attempt until the body
+	// was read.
+	Duration time.Duration
+}
+
+// Do sends req and returns the response of a successful attempt; the caller
+// closes its body. Error statuses are returned as errors from DecodeError,
+// after any retries.
+func (c *Client) Do(ctx context.Context, req Request) (*http.Response, error) {
+	body, err := c.body(req)
+	if err != nil {
+		return nil, err
+	}
+	defer body.release()
+
+	for attempt := 0; ; attempt++ {
+		httpReq, err := c.newRequest(ctx, req, body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: build %s: %w", c.Provider, label(req.Name, "request"), err)
+		}
+
+		httpResp, err := c.client().Do(httpReq)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s failed: %w", c.Provider, label(req.Name, "request"), err)
+		}
+		if err := decompress(httpResp); err != nil {
+			httpResp.Body.Close()
+			return nil, fmt.Errorf("%s: %s failed: %w", c.Provider, label(req.Name, "request"), err)
+		}
+		if httpResp.StatusCode < http.StatusBadRequest {
+			return httpResp, nil
+		}
+
+		apiErr := c.decodeError(httpResp)
+		httpResp.Body.Close()
+
+		if c.Retry == nil {
+			return nil, apiErr
+		}
+		delay, ok := c.Retry(attempt, apiErr)
+		if !ok {
+			return nil, apiErr
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, apiErr
+		case <-timer.C:
+		}
+	}
+}
+
+// Send sends req, reads the response body, and decodes it as JSON into out
+// unless out is nil.
+func (c *Client) Send(ctx context.Context, req Request, out any) (*Response, error) {
+	start := time.Now()
+	httpResp, err := c.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	buf := bufpool.GetBuffer()
+	defer bufpool.PutBuffer(buf)
+	if _, err := buf.ReadFrom(httpResp.Body); err != nil {
+		return nil, fmt.Errorf("%s: read %s: %w", c.Provider, label(req.Name, "response"), err)
+	}
+	if out != nil {
+		if err := json.Unmarshal(buf.Bytes(), out); err != nil {
+			return nil, fmt.Errorf("%s: decode %s: %w", c.Provider, label(req.Name, "response"), err)
+		}
+	}
+
+	return &Response{
+		StatusCode: httpResp.StatusCode,
+		Header:     httpResp.Header,
+		Body:       bytes.Clone(buf.Bytes()),
+		Duration:   time.Since(start),
+	}, nil
+}
+
+// body returns the encoded request body. Bodies other than []byte are
+// encoded into a pooled buffer.
+func (c *Client) body(req Request) (*requestBody, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body := &requestBody{}
+	body.refs.Store(1)
+
+	switch data := req.Body.(type) {
+	case []byte:
+		body.data = data
+	default:
+		buf := bufpool.GetBuffer()
+		if err := json.NewEncoder(buf).Encode(data); err != nil {
+			bufpool.PutBuffer(buf)
+			return nil, fmt.Errorf("%s: marshal %s: %w", c.Provider, label(req.Name, "request"), err)
+		}
+		// Encode terminates the value with a newline, which Marshal does not.
+		buf.Truncate(buf.Len() - 1)
+		body.buf, body.data = buf, buf.Bytes()
+	}
+
+	if c.Gzip && len(body.data) >= minGzipSize {
+		buf := bufpool.GetBuffer()
+		writer := gzipWriters.Get().(*gzip.Writer)
+		writer.Reset(buf)
+		_, err := writer.Write(body.data)
+		if err == nil {
+			err = writer.Close()
+		}
+		gzipWriters.Put(writer)
+		if err != nil {
+			bufpool.PutBuffer(buf)
+			body.release()
+			return nil, fmt.Errorf("%s: compress %s: %w", c.Provider, label(req.Name, "request"), err)
+		}
+		body.release()
+		body = &requestBody{data: buf.Bytes(), buf: buf, gzip: true}
+		body.refs.Store(1)
+	}
+	return body, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, req Request, body *requestBody) (*http.Request, error) {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+		if req.Body != nil {
+			method = http.MethodPost
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.BaseURL, "/")+req.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		httpReq.ContentLength = int64(len(body.data))
+		if len(body.data) == 0 {
+			httpReq.Body = http.NoBody
+		} else {
+			httpReq.Body = body.reader()
+			httpReq.GetBody = func() (io.ReadCloser, error) { return body.reader(), nil }
+		}
+
+		contentType := req.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		httpReq.Header.Set("Content-Type", contentType)
+		if body.gzip {
+			httpReq.Header.Set("Content-Encoding", "gzip")
+		}
+	}
+	if c.Gzip {
+		httpReq.Header.Set("Accept-Encoding", "gzip")
+	}
+	if c.Header != nil {
+		c.Header(httpReq.Header)
+	}
+	for key, values := range req.Header {
+		httpReq.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	return httpReq, nil
+}
+
+func (c *Client) client() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) decodeError(resp *http.Response) error {
+	if c.DecodeError != nil {
+		return c.DecodeError(resp)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return fmt.Errorf("%s: API status %d and failed to read error body: %w", c.Provider, resp.StatusCode, err)
+	}
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		text = http.StatusText(resp.StatusCode)
+	}
+	return fmt.Errorf("%s: API status %d: %s", c.Provider, resp.StatusCode, text)
+}
+
+// requestBody is an encoded request body. When it is held in a pooled buffer,
+// the buffer goes back to the pool once Do and every request reading it are
+// done, since the transport may still be sending the body after Do returns.
+type requestBody struct {
+	data []byte
+	buf  *bytes.Buffer
+	gzip bool
+	refs atomic.Int32
+}
+
+func (b *requestBody) reader() io.ReadCloser {
+	b.refs.Add(1)
+	return &bodyReader{Reader: bytes.NewReader(b.data), body: b}
+}
+
+func (b *requestBody) release() {
+	if b != nil && b.refs.Add(-1) == 0 && b.buf != nil {
+		bufpool.PutBuffer(b.buf)
+	}
+}
+
+type bodyReader struct {
+	*bytes.Reader
+	body *requestBody
+	once sync.Once
+}
+
+func (r *bodyReader) Close() error {
+	r.once.Do(r.body.release)
+	return nil
+}
+
+// decompress replaces the body of a gzip response that the transport did not
+// decompress itself, which it only does when it added Accept-Encoding.
+func decompress(resp *http.Response) error {
+	if resp.Uncompressed || !strings.EqualFold(strings.TrimSpace(resp.Header.Get("Content-Encoding")), "gzip") {
+		return nil
+	}
+	reader, err := gzip.NewReader(resp.Body)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("decompress response: %w", err)
+	}
+	resp.Body = &gzipBody{Reader: reader, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
+
+// label returns noun qualified by name, such as "embeddings request".
+func label(name, noun string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name + " " + noun
+	}
+	return noun
+}
+
+// RequestID returns the provider request identifier of a response, from the
+// x-request-id header used by OpenAI and most gateways or the request-id
+// header used by Anthropic.
+func RequestID(header http.Header) string {
+	if id := strings.TrimSpace(header.Get("x-request-id")); id != "" {
+		return id
+	}
+	return strings.TrimSpace(header.Get("request-id"))
+}
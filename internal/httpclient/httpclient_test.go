@@ -0,0 +1,305 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendBuildsJSONRequestAndDecodesResponse(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/items" {
+			t.Errorf("request = %s %s, want POST /v1/items", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("X-Extra"); got != "yes" {
+			t.Errorf("X-Extra = %q", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"a"}` {
+			t.Errorf("body = %s", body)
+		}
+		w.Header().Set("X-Request-Id", "req_1")
+		fmt.Fprint(w, `{"id":"item_1"}`)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		BaseURL:  server.URL + "/v1/",
+		Provider: "test",
+		Header: func(header http.Header) {
+			header.Set("Authorization", "Bearer key")
+		},
+	}
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	resp, err := client.Send(context.Background(), Request{
+		Path:   "/items",
+		Body:   map[string]string{"name": "a"},
+		Header: http.Header{"x-extra": {"yes"}},
+	}, &out)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if out.ID != "item_1" {
+		t.Fatalf("ID = %q", out.ID)
+	}
+	if resp.Header.Get("X-Request-Id") != "req_1" || string(resp.Body) != `{"id":"item_1"}` || resp.Duration <= 0 {
+		t.Fatalf("response = %+v", resp)
+	}
+}
+
+func TestSendWithoutBodyUsesGet(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.RawQuery != "limit=2" {
+			t.Errorf("request = %s %s", r.Method, r.URL)
+		}
+		if got := r.Header.Get("Content-Type"); got != "" {
+			t.Errorf("Content-Type = %q, want none", got)
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Provider: "test"}
+	if _, err := client.Send(context.Background(), Request{Path: "/models?limit=2"}, nil); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+}
+
+func TestDoDecodesErrorResponses(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad input", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Provider: "test"}
+	_, err := client.Do(context.Background(), Request{Path: "/", Body: []byte(`{}`)})
+	if err == nil || err.Error() != "test: API status 400: bad input" {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	sentinel := errors.New("decoded")
+	client.DecodeError = func(resp *http.Response) error { return sentinel }
+	if _, err := client.Do(context.Background(), Request{Path: "/"}); !errors.Is(err, sentinel) {
+		t.Fatalf("Do() error = %v, want decoded error", err)
+	}
+}
+
+func TestDoRetriesUntilHookDeclines(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"n":1}` {
+			t.Errorf("attempt %d body = %s", calls.Load(), body)
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	var attempts []int
+	client := &Client{
+		BaseURL:  server.URL,
+		Provider: "test",
+		Retry: func(attempt int, err error) (time.Duration, bool) {
+			attempts = append(attempts, attempt)
+			return time.Millisecond, strings.Contains(err.Error(), "503")
+		},
+	}
+
+	if _, err := client.Send(context.Background(), Request{Path: "/", Body: map[string]int{"n": 1}}, nil); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if calls.Load() != 3 || len(attempts) != 2 || attempts[1] != 1 {
+		t.Fatalf("calls = %d, retry attempts = %v", calls.Load(), attempts)
+	}
+}
+
+func TestDoStopsRetryingWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &Client{
+		BaseURL:  server.URL,
+		Provider: "test",
+		Retry: func(attempt int, err error) (time.Duration, bool) {
+			cancel()
+			return time.Hour, true
+		},
+	}
+
+	_, err := client.Do(ctx, Request{Path: "/"})
+	if err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Fatalf("Do() error = %v, want the API error", err)
+	}
+}
+
+func TestErrorsNameTheRequest(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{BaseURL: "http://127.0.0.1:0", Provider: "test"}
+
+	_, err := client.Do(context.Background(), Request{Name: "embeddings", Body: func() {}})
+	if err == nil || !strings.HasPrefix(err.Error(), "test: marshal embeddings request: ") {
+		t.Fatalf("marshal error = %v", err)
+	}
+
+	_, err = client.Do(context.Background(), Request{Name: "embeddings", Path: "/"})
+	if err == nil || !strings.HasPrefix(err.Error(), "test: embeddings request failed: ") {
+		t.Fatalf("transport error = %v", err)
+	}
+
+	_, err = client.Do(context.Background(), Request{Method: "bad method", Path: "/"})
+	if err == nil || !strings.HasPrefix(err.Error(), "test: build request: ") {
+		t.Fatalf("build error = %v", err)
+	}
+}
+
+func TestRedirectResendsPooledBody(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusTemporaryRedirect)
+			return
+		}
+		if string(body) != `{"n":1}` || r.ContentLength != int64(len(body)) {
+			t.Errorf("redirected body = %q, length %d", body, r.ContentLength)
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Provider: "test"}
+	if _, err := client.Send(context.Background(), Request{Path: "/old", Body: map[string]int{"n": 1}}, nil); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+}
+
+// BenchmarkSend measures one JSON round trip, whose request and response
+// buffers come from the pool.
+func BenchmarkSend(b *testing.B) {
+	payload := strings.Repeat("x", 32<<10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		fmt.Fprintf(w, `{"text":%q}`, payload)
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Provider: "test"}
+	request := Request{Path: "/", Body: map[string]string{"prompt": payload}}
+	b.ReportAllocs()
+	for b.Loop() {
+		var out struct {
+			Text string `json:"text"`
+		}
+		if _, err := client.Send(context.Background(), request, &out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGzipCompressesLargeBodiesAndDecodesResponses(t *testing.T) {
+	t.Parallel()
+
+	prompt := strings.Repeat("a long prompt ", 200)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept-Encoding"); got != "gzip" {
+			t.Errorf("Accept-Encoding = %q", got)
+		}
+		var body io.Reader = r.Body
+		if r.URL.Path == "/large" {
+			if got := r.Header.Get("Content-Encoding"); got != "gzip" {
+				t.Errorf("Content-Encoding = %q, want gzip", got)
+			}
+			reader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("gzip.NewReader() error = %v", err)
+				return
+			}
+			body = reader
+		} else if got := r.Header.Get("Content-Encoding"); got != "" {
+			t.Errorf("small body Content-Encoding = %q, want none", got)
+		}
+
+		var request struct {
+			Prompt string `json:"prompt"`
+		}
+		if err := json.NewDecoder(body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		fmt.Fprintf(writer, `{"length":%d}`, len(request.Prompt))
+		writer.Close()
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Provider: "test", Gzip: true}
+	for path, text := range map[string]string{"/large": prompt, "/small": "hi"} {
+		var out struct {
+			Length int `json:"length"`
+		}
+		resp, err := client.Send(context.Background(), Request{Path: path, Body: map[string]string{"prompt": text}}, &out)
+		if err != nil {
+			t.Fatalf("Send(%s) error = %v", path, err)
+		}
+		if out.Length != len(text) || resp.Header.Get("Content-Encoding") != "" {
+			t.Fatalf("Send(%s) = %d, header %v", path, out.Length, resp.Header)
+		}
+	}
+}
+
+func TestDecodesGzipErrorResponses(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusBadRequest)
+		writer := gzip.NewWriter(w)
+		io.WriteString(writer, "bad input")
+		writer.Close()
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Provider: "test", Gzip: true}
+	_, err := client.Do(context.Background(), Request{Path: "/"})
+	if err == nil || err.Error() != "test: API status 400: bad input" {
+		t.Fatalf("Do() error = %v", err)
+	}
+}
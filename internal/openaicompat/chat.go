@@ -0,0 +1,311 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+// Chat sends a non-streaming chat request and runs server tools until the
+// model answers or calls a client tool.
+func (c *Client) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	requestTemplate, serverTools, clientTools, maxLoopCount, err := c.buildRequest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := requestTemplate.Messages
+	conversation := cloneCoreMessages(params)
+	reasoningParts := make([]string, 0, 4)
+
+	for range maxLoopCount {
+		request := requestTemplate
+		request.Messages = messages
+
+		response, httpResp, err := c.postChat(ctx, &request)
+		if err != nil {
+			return nil, err
+		}
+		if len(response.Choices) == 0 {
+			return nil, fmt.Errorf("%s: chat response has no choices", c.Provider)
+		}
+
+		choice := response.Choices[0]
+		reasoningParts = appendReasoningPart(reasoningParts, choice.Message.reasoning())
+		usage := c.responseUsage(response)
+		rateLimit := RateLimit(httpResp.Header)
+
+		if len(choice.Message.ToolCalls) == 0 {
+			candidates, err := c.toCoreCandidates(response.Choices)
+			if err != nil {
+				return nil, err
+			}
+			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: choice.Message.Content})
+			return &core.ChatResult{
+				Text:            choice.Message.Content,
+				Candidates:      candidates,
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				Citations:       c.citations(response.raw),
+				FinishReason:    toCoreFinishReason(choice.FinishReason),
+				RawFinishReason: choice.FinishReason,
+				Usage:           usage,
+				RateLimit:       rateLimit,
+				RequestID:       response.requestID,
+			}, nil
+		}
+
+		coreCalls, err := c.toCoreToolCalls(choice.Message.ToolCalls)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, c.toolCallMessage(choice.Message.Content, choice.Message.reasoning(), choice.Message.ToolCalls))
+		conversation = append(conversation, core.ToolCallMessagePart{
+			Role:            core.RoleToolCall,
+			ToolCalls:       coreCalls,
+			ReasoningBlocks: reasoningBlocks(choice.Message.reasoning()),
+		})
+
+		results, pendingClientCalls, err := c.runServerTools(coreCalls, serverTools, clientTools)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			messages = append(messages, toolMessage(result.ToolCallID, result.Content))
+			conversation = append(conversation, result)
+		}
+
+		if len(pendingClientCalls) > 0 {
+			return &core.ChatResult{
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				ToolCalls:       pendingClientCalls,
+				FinishReason:    core.FinishReasonToolCalls,
+				RawFinishReason: choice.FinishReason,
+				Usage:           usage,
+				RateLimit:       rateLimit,
+				RequestID:       response.requestID,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: reached max tool loop count (%d)", c.Provider, maxLoopCount)
+}
+
+// toCoreCandidates returns every choice of a response that has several.
+func (c *Client) toCoreCandidates(choices []choice) ([]core.Candidate, error) {
+	if len(choices) < 2 {
+		return nil, nil
+	}
+
+	out := make([]core.Candidate, 0, len(choices))
+	for _, choice := range choices {
+		var calls []core.ToolCall
+		if len(choice.Message.ToolCalls) > 0 {
+			var err error
+			if calls, err = c.toCoreToolCalls(choice.Message.ToolCalls); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, core.Candidate{
+			Text:            choice.Message.Content,
+			Reasoning:       choice.Message.reasoning(),
+			ToolCalls:       calls,
+			FinishReason:    toCoreFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+		})
+	}
+	return out, nil
+}
+
+// toolCallMessage is the assistant message that continues a tool loop.
+func (c *Client) toolCallMessage(content, reasoning string, calls []ToolCall) Message {
+	msg := Message{Role: core.RoleAssistant, Content: content, ToolCalls: calls}
+	if c.ReplayReasoning {
+		msg.ReasoningContent = reasoning
+	}
+	return msg
+}
+
+// reasoningBlocks keeps the reasoning of a tool call response on the core
+// conversation, so that it can be replayed.
+func reasoningBlocks(reasoning string) []core.ReasoningBlock {
+	if reasoning == "" {
+		return nil
+	}
+	return []core.ReasoningBlock{{Type: reasoningBlockType, Text: reasoning}}
+}
+
+func (c *Client) postChat(ctx context.Context, request *Request) (*response, *httpclient.Response, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: marshal chat request: %w", c.Provider, err)
+	}
+
+	httpResp, err := c.Transport.Send(ctx, httpclient.Request{Path: c.chatPath(), Name: "chat", Body: body}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var out response
+	if err := json.Unmarshal(httpResp.Body, &out); err != nil {
+		return nil, nil, fmt.Errorf("%s: decode chat response: %w", c.Provider, err)
+	}
+	out.raw = httpResp.Body
+	out.duration = httpResp.Duration
+	out.requestID = httpclient.RequestID(httpResp.Header)
+
+	return &out, httpResp, nil
+}
+
+// runServerTools runs the calls of registered server tools and returns their
+// results along with the calls of client tools.
+func (c *Client) runServerTools(calls []core.ToolCall, serverTools map[string]core.ServerTool, clientTools map[string]struct{}) ([]core.ToolResultMessagePart, []core.ToolCall, error) {
+	results := make([]core.ToolResultMessagePart, 0, len(calls))
+	var pendingClientCalls []core.ToolCall
+
+	for _, call := range calls {
+		if serverTool, ok := serverTools[call.Name]; ok {
+			result, callErr := serverTool.Handler(call.Arguments)
+			if callErr != nil {
+				result = "tool_error: " + callErr.Error()
+			}
+			results = append(results, core.ToolResultMessagePart{
+				Role:       core.RoleToolResult,
+				ToolCallID: call.ID,
+				Name:       call.Name,
+				Content:    result,
+			})
+			continue
+		}
+
+		if _, ok := clientTools[call.Name]; ok {
+			pendingClientCalls = append(pendingClientCalls, call)
+			continue
+		}
+
+		return nil, nil, fmt.Errorf("%s: tool %q was requested but not registered", c.Provider, call.Name)
+	}
+
+	return results, pendingClientCalls, nil
+}
+
+func (c *Client) toCoreToolCalls(calls []ToolCall) ([]core.ToolCall, error) {
+	out := make([]core.ToolCall, 0, len(calls))
+	for _, call := range calls {
+		var arguments any = map[string]any{}
+		if strings.TrimSpace(call.Function.Arguments) != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &arguments); err != nil {
+				return nil, fmt.Errorf("%s: invalid arguments for tool %q: %w", c.Provider, call.Function.Name, err)
+			}
+		}
+		out = append(out, core.ToolCall{ID: call.ID, Name: call.Function.Name, Arguments: arguments})
+	}
+	return out, nil
+}
+
+// responseUsage reports the usage of a response with the Usage hook
+// applied.
+func (c *Client) responseUsage(response *response) *core.Usage {
+	out := c.chunkUsage(response.raw, toCoreUsage(response.Usage), nil)
+	if out == nil {
+		out = &core.Usage{}
+	}
+	out.Duration = response.duration
+	out.RequestID = response.requestID
+	return out
+}
+
+func (c *Client) citations(raw []byte) []core.Citation {
+	if c.Citations == nil {
+		return nil
+	}
+	return c.Citations(raw)
+}
+
+// chunkUsage applies the Usage hook to the usage parsed from raw, falling
+// back to the usage reported so far.
+func (c *Client) chunkUsage(raw []byte, parsed, current *core.Usage) *core.Usage {
+	if parsed == nil {
+		parsed = current
+	}
+	if c.Usage != nil {
+		return c.Usage(raw, parsed)
+	}
+	return parsed
+}
+
+func toCoreUsage(in *usage) *core.Usage {
+	if in == nil {
+		return nil
+	}
+
+	out := &core.Usage{
+		PromptTokens:     in.PromptTokens,
+		CompletionTokens: in.CompletionTokens,
+		TotalTokens:      in.TotalTokens,
+	}
+	if out.TotalTokens == 0 {
+		out.TotalTokens = out.PromptTokens + out.CompletionTokens
+	}
+	if in.CompletionTokensDetails != nil {
+		out.ReasoningTokens = in.CompletionTokensDetails.ReasoningTokens
+	}
+	if in.PromptTokensDetails != nil && in.PromptTokensDetails.CachedTokens > 0 {
+		out.Details = map[string]int64{"cached_tokens": in.PromptTokensDetails.CachedTokens}
+	}
+	return out
+}
+
+func cloneCoreMessages(params *core.ChatParams) []core.MessageUnion {
+	if len(params.Messages) == 0 {
+		return nil
+	}
+
+	out := make([]core.MessageUnion, 0, len(params.Messages)+8)
+	out = append(out, params.Messages...)
+	return out
+}
+
+func appendReasoningPart(parts []string, reasoning string) []string {
+	reasoning = strings.TrimSpace(reasoning)
+	if reasoning == "" {
+		return parts
+	}
+	if len(parts) > 0 && parts[len(parts)-1] == reasoning {
+		return parts
+	}
+	return append(parts, reasoning)
+}
+
+func joinReasoningParts(parts []string) string {
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// toCoreFinishReason maps a finish_reason onto core.FinishReason.
+func toCoreFinishReason(finishReason string) core.FinishReason {
+	switch strings.TrimSpace(finishReason) {
+	case "", "stop":
+		return core.FinishReasonStop
+	case "length":
+		return core.FinishReasonLength
+	case "tool_calls", "function_call":
+		return core.FinishReasonToolCalls
+	case "content_filter":
+		return core.FinishReasonContentFilter
+	default:
+		return core.FinishReason(finishReason)
+	}
+}
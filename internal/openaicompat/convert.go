@@ -0,0 +1,639 @@
+package openaicompat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// buildRequest converts params into a request template holding the initial
+// messages.
+func (c *Client) buildRequest(params *core.ChatParams) (Request, map[string]core.ServerTool, map[string]struct{}, int, error) {
+	if params == nil {
+		return Request{}, nil, nil, 0, fmt.Errorf("%s: chat params are required", c.Provider)
+	}
+
+	messages, err := c.toMessages(params)
+	if err != nil {
+		return Request{}, nil, nil, 0, err
+	}
+
+	tools, serverTools, clientTools, err := c.toTools(params)
+	if err != nil {
+		return Request{}, nil, nil, 0, err
+	}
+
+	request := Request{
+		Model:           c.Model,
+		Messages:        messages,
+		Tools:           tools,
+		ResponseFormat:  toResponseFormat(params.Output),
+		MaxTokens:       maxTokens(params),
+		Temperature:     params.Temperature,
+		TopP:            params.TopP,
+		Seed:            params.Seed,
+		Stop:            params.StopSequences,
+		LogitBias:       LogitBias(params),
+		N:               CandidateCount(params),
+		ReasoningEffort: strings.TrimSpace(params.ReasoningEffort),
+	}
+	if len(tools) > 0 {
+		request.ParallelToolCalls = params.ParallelToolCalls
+	}
+	if field := strings.TrimSpace(c.ModelField); field != "" && field != "model" {
+		request.Model = ""
+		request.Extra = map[string]any{field: c.Model}
+	}
+	if err := c.applyToolChoice(&request, params); err != nil {
+		return Request{}, nil, nil, 0, err
+	}
+	if c.JSONObjectOutput && params.Output != nil {
+		if err := c.useJSONObjectOutput(&request, params.Output); err != nil {
+			return Request{}, nil, nil, 0, err
+		}
+	}
+	if c.PrepareRequest != nil {
+		if err := c.PrepareRequest(params, &request); err != nil {
+			return Request{}, nil, nil, 0, err
+		}
+	}
+	if len(params.ModelOptions) > 0 {
+		if request.Extra == nil {
+			request.Extra = make(map[string]any, len(params.ModelOptions))
+		}
+		for key, value := range params.ModelOptions {
+			if key = strings.TrimSpace(key); key != "" {
+				request.Extra[key] = value
+			}
+		}
+	}
+
+	return request, serverTools, clientTools, maxLoops(params, len(serverTools) > 0), nil
+}
+
+// toMessages converts params into chat messages. System prompts become
+// system messages.
+func (c *Client) toMessages(params *core.ChatParams) ([]Message, error) {
+	messages := make([]Message, 0, len(params.SystemPrompts)+len(params.Messages))
+	for _, prompt := range params.SystemPrompts {
+		if prompt = strings.TrimSpace(prompt); prompt != "" {
+			messages = append(messages, Message{Role: core.RoleSystem, Content: prompt})
+		}
+	}
+
+	for i, union := range params.Messages {
+		msg, err := c.toMessage(union)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid message at index %d: %w", c.Provider, i, err)
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+func (c *Client) toMessage(union core.MessageUnion) (Message, error) {
+	switch msg := union.(type) {
+	case core.TextMessagePart:
+		return textMessage(msg.Role, msg.Name, msg.Content)
+	case *core.TextMessagePart:
+		if msg == nil {
+			return Message{}, errors.New("text message is nil")
+		}
+		return textMessage(msg.Role, msg.Name, msg.Content)
+
+	case core.ContentMessagePart:
+		return c.contentMessage(msg.Role, msg.Name, msg.Parts)
+	case *core.ContentMessagePart:
+		if msg == nil {
+			return Message{}, errors.New("content message is nil")
+		}
+		return c.contentMessage(msg.Role, msg.Name, msg.Parts)
+
+	case core.AssistantToolCallMessagePart:
+		return c.assistantToolCallMessage(msg.Role, msg.ToolCalls, msg.ReasoningBlocks)
+	case *core.AssistantToolCallMessagePart:
+		if msg == nil {
+			return Message{}, errors.New("assistant tool call message is nil")
+		}
+		return c.assistantToolCallMessage(msg.Role, msg.ToolCalls, msg.ReasoningBlocks)
+
+	case core.ToolResultMessagePart:
+		return toolResultMessage(msg.Role, msg.ToolCallID, msg.Content)
+	case *core.ToolResultMessagePart:
+		if msg == nil {
+			return Message{}, errors.New("tool result message is nil")
+		}
+		return toolResultMessage(msg.Role, msg.ToolCallID, msg.Content)
+	}
+
+	return Message{}, fmt.Errorf("unsupported message type %T", union)
+}
+
+func textMessage(role, name, content string) (Message, error) {
+	normalizedRole, err := normalizeRole(role)
+	if err != nil {
+		return Message{}, err
+	}
+	if content == "" {
+		return Message{}, errors.New("text message content is required")
+	}
+
+	return Message{Role: normalizedRole, Name: strings.TrimSpace(name), Content: content}, nil
+}
+
+func (c *Client) contentMessage(role, name string, parts []core.ContentPart) (Message, error) {
+	normalizedRole, err := normalizeRole(role)
+	if err != nil {
+		return Message{}, err
+	}
+	if len(parts) == 0 {
+		return Message{}, errors.New("content message must include at least one content part")
+	}
+
+	content := make([]ContentPart, 0, len(parts))
+	for i, part := range parts {
+		converted, ok, err := c.toContentPart(part)
+		if err != nil {
+			return Message{}, fmt.Errorf("content part at index %d: %w", i, err)
+		}
+		if ok {
+			content = append(content, converted)
+		}
+	}
+	if len(content) == 0 {
+		return Message{}, fmt.Errorf("content message has no content for %s", c.Provider)
+	}
+
+	return Message{Role: normalizedRole, Name: strings.TrimSpace(name), Content: content}, nil
+}
+
+// toContentPart converts one content part. Text documents and search results
+// are sent as text. It reports false for parts that are skipped, such as raw
+// parts for other providers.
+func (c *Client) toContentPart(part core.ContentPart) (ContentPart, bool, error) {
+	switch typed := part.(type) {
+	case core.TextPart:
+		return ContentPart{Type: "text", Text: typed.Text}, typed.Text != "", nil
+	case *core.TextPart:
+		if typed == nil {
+			return ContentPart{}, false, errors.New("text part is nil")
+		}
+		return c.toContentPart(*typed)
+
+	case core.ImagePart:
+		url, err := imageURLFromSource(typed.Source)
+		if err != nil {
+			return ContentPart{}, false, err
+		}
+		detail, _ := typed.Metadata["detail"].(string)
+		return ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: url, Detail: strings.TrimSpace(detail)}}, true, nil
+	case *core.ImagePart:
+		if typed == nil {
+			return ContentPart{}, false, errors.New("image part is nil")
+		}
+		return c.toContentPart(*typed)
+
+	case core.DocumentPart:
+		text, err := c.documentText(typed.Source)
+		if err != nil {
+			return ContentPart{}, false, err
+		}
+		if title, _ := typed.Metadata["title"].(string); strings.TrimSpace(title) != "" {
+			text = strings.TrimSpace(title) + "\n\n" + text
+		}
+		return ContentPart{Type: "text", Text: text}, true, nil
+	case *core.DocumentPart:
+		if typed == nil {
+			return ContentPart{}, false, errors.New("document part is nil")
+		}
+		return c.toContentPart(*typed)
+
+	case core.SearchResultPart:
+		lines := make([]string, 0, len(typed.Texts)+2)
+		if title := strings.TrimSpace(typed.Title); title != "" {
+			lines = append(lines, title)
+		}
+		if source := strings.TrimSpace(typed.Source); source != "" {
+			lines = append(lines, source)
+		}
+		lines = append(lines, typed.Texts...)
+		return ContentPart{Type: "text", Text: strings.Join(lines, "\n\n")}, true, nil
+	case *core.SearchResultPart:
+		if typed == nil {
+			return ContentPart{}, false, errors.New("search result part is nil")
+		}
+		return c.toContentPart(*typed)
+
+	case core.RawPart:
+		if !strings.EqualFold(strings.TrimSpace(typed.Provider), c.Provider) {
+			return ContentPart{}, false, nil
+		}
+		if !json.Valid(typed.Payload) || !bytes.HasPrefix(bytes.TrimSpace(typed.Payload), []byte("{")) {
+			return ContentPart{}, false, fmt.Errorf("%s: raw part payload must be a JSON object", c.Provider)
+		}
+		return ContentPart{Raw: typed.Payload}, true, nil
+	case *core.RawPart:
+		if typed == nil {
+			return ContentPart{}, false, errors.New("raw part is nil")
+		}
+		return c.toContentPart(*typed)
+
+	case core.AudioPart, *core.AudioPart:
+		return ContentPart{}, false, fmt.Errorf("%s: audio content is not supported", c.Provider)
+	case core.FilePart, *core.FilePart:
+		return ContentPart{}, false, fmt.Errorf("%s: file content is not supported; use a DocumentPart with text", c.Provider)
+	}
+
+	return ContentPart{}, false, fmt.Errorf("unsupported content part type %T", part)
+}
+
+// documentText decodes a base64 text document. Binary formats such as PDF
+// are rejected.
+func (c *Client) documentText(source core.Source) (string, error) {
+	var data core.DataSource
+	switch typed := source.(type) {
+	case core.DataSource:
+		data = typed
+	case *core.DataSource:
+		if typed == nil {
+			return "", errors.New("document data source is nil")
+		}
+		data = *typed
+	case nil:
+		return "", errors.New("document source is required")
+	default:
+		return "", fmt.Errorf("%s: documents must be inline data, got %T", c.Provider, source)
+	}
+
+	mimeType := strings.ToLower(strings.TrimSpace(data.MimeType))
+	if !strings.HasPrefix(mimeType, "text/") && mimeType != "application/json" {
+		return "", fmt.Errorf("%s: unsupported document type %q; only text documents are supported", c.Provider, data.MimeType)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data.Data))
+	if err != nil {
+		return "", fmt.Errorf("%s: document data must be base64: %w", c.Provider, err)
+	}
+	return string(decoded), nil
+}
+
+func imageURLFromSource(source core.Source) (string, error) {
+	switch typed := source.(type) {
+	case core.URLSource:
+		url := strings.TrimSpace(typed.URL)
+		if url == "" {
+			return "", errors.New("image URL is required")
+		}
+		return url, nil
+	case *core.URLSource:
+		if typed == nil {
+			return "", errors.New("image URL source is nil")
+		}
+		return imageURLFromSource(*typed)
+
+	case core.DataSource:
+		data := strings.TrimSpace(typed.Data)
+		if data == "" {
+			return "", errors.New("image data is required")
+		}
+		if strings.HasPrefix(data, "data:") {
+			return "", errors.New("image data must be raw base64")
+		}
+		mimeType := strings.TrimSpace(typed.MimeType)
+		if mimeType == "" {
+			return "", errors.New("image mime type is required")
+		}
+		return fmt.Sprintf("data:%s;base64,%s", mimeType, data), nil
+	case *core.DataSource:
+		if typed == nil {
+			return "", errors.New("image data source is nil")
+		}
+		return imageURLFromSource(*typed)
+	}
+
+	return "", fmt.Errorf("unsupported image source type %T", source)
+}
+
+// assistantToolCallMessage converts tool calls. With ReplayReasoning, a
+// "reasoning_content" reasoning block is sent back as reasoning_content.
+func (c *Client) assistantToolCallMessage(role string, calls []core.ToolCall, reasoning []core.ReasoningBlock) (Message, error) {
+	role = strings.TrimSpace(strings.ToLower(role))
+	if role == "" {
+		role = core.RoleToolCall
+	}
+	if role != core.RoleToolCall && role != core.RoleAssistant {
+		return Message{}, fmt.Errorf("tool call message role must be %q or %q, got %q", core.RoleToolCall, core.RoleAssistant, role)
+	}
+	if len(calls) == 0 {
+		return Message{}, errors.New("assistant tool call message must include at least one tool call")
+	}
+
+	msg := Message{Role: core.RoleAssistant}
+	if c.ReplayReasoning {
+		for _, block := range reasoning {
+			if block.Type == reasoningBlockType {
+				msg.ReasoningContent += block.Text
+			}
+		}
+	}
+	for i, call := range calls {
+		name := strings.TrimSpace(call.Name)
+		if name == "" {
+			return Message{}, fmt.Errorf("tool call at index %d is missing a name", i)
+		}
+
+		id := strings.TrimSpace(call.ID)
+		if id == "" {
+			id = fmt.Sprintf("call_%d", i+1)
+		}
+
+		arguments, err := encodeArguments(call.Arguments)
+		if err != nil {
+			return Message{}, fmt.Errorf("tool call %q arguments: %w", name, err)
+		}
+
+		msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+			ID:       id,
+			Type:     "function",
+			Function: ToolCallFunction{Name: name, Arguments: arguments},
+		})
+	}
+
+	return msg, nil
+}
+
+func encodeArguments(arguments any) (string, error) {
+	switch typed := arguments.(type) {
+	case nil:
+		return "{}", nil
+	case string:
+		if strings.TrimSpace(typed) == "" {
+			return "{}", nil
+		}
+		return typed, nil
+	case json.RawMessage:
+		return string(typed), nil
+	}
+
+	encoded, err := json.Marshal(arguments)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func toolResultMessage(role, toolCallID, content string) (Message, error) {
+	role = strings.TrimSpace(strings.ToLower(role))
+	if role == "" {
+		role = core.RoleToolResult
+	}
+	if role != core.RoleToolResult && role != "tool" {
+		return Message{}, fmt.Errorf("tool result message role must be %q or %q, got %q", core.RoleToolResult, "tool", role)
+	}
+	if strings.TrimSpace(toolCallID) == "" {
+		return Message{}, errors.New("tool result message tool call ID is required")
+	}
+
+	return toolMessage(strings.TrimSpace(toolCallID), content), nil
+}
+
+func toolMessage(toolCallID, content string) Message {
+	return Message{Role: "tool", ToolCallID: toolCallID, Content: content}
+}
+
+func normalizeRole(role string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(role))
+	switch normalized {
+	case "":
+		return "", errors.New("message role is required")
+	case core.RoleSystem, core.RoleUser, core.RoleAssistant:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("unsupported role %q", role)
+	}
+}
+
+func (c *Client) toTools(params *core.ChatParams) ([]Tool, map[string]core.ServerTool, map[string]struct{}, error) {
+	if len(params.Tools) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	tools := make([]Tool, 0, len(params.Tools))
+	serverTools := make(map[string]core.ServerTool)
+	clientTools := make(map[string]struct{})
+	seenNames := make(map[string]struct{})
+
+	for i, union := range params.Tools {
+		switch toolValue := union.(type) {
+		case core.ServerTool:
+			definition, serverTool, err := newServerTool(toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("%s: invalid server tool at index %d: %w", c.Provider, i, err)
+			}
+			if err := c.assertNewToolName(seenNames, serverTool.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			serverTools[serverTool.Name] = serverTool
+
+		case *core.ServerTool:
+			if toolValue == nil {
+				return nil, nil, nil, fmt.Errorf("%s: server tool at index %d is nil", c.Provider, i)
+			}
+			definition, serverTool, err := newServerTool(*toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("%s: invalid server tool at index %d: %w", c.Provider, i, err)
+			}
+			if err := c.assertNewToolName(seenNames, serverTool.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			serverTools[serverTool.Name] = serverTool
+
+		case core.ClientTool:
+			definition, err := newClientTool(toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("%s: invalid client tool at index %d: %w", c.Provider, i, err)
+			}
+			if err := c.assertNewToolName(seenNames, definition.Function.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			clientTools[definition.Function.Name] = struct{}{}
+
+		case *core.ClientTool:
+			if toolValue == nil {
+				return nil, nil, nil, fmt.Errorf("%s: client tool at index %d is nil", c.Provider, i)
+			}
+			definition, err := newClientTool(*toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("%s: invalid client tool at index %d: %w", c.Provider, i, err)
+			}
+			if err := c.assertNewToolName(seenNames, definition.Function.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			tools = append(tools, definition)
+			clientTools[definition.Function.Name] = struct{}{}
+
+		default:
+			return nil, nil, nil, fmt.Errorf("%s: unsupported tool type %T", c.Provider, union)
+		}
+	}
+
+	return tools, serverTools, clientTools, nil
+}
+
+func newServerTool(toolValue core.ServerTool) (Tool, core.ServerTool, error) {
+	name := strings.TrimSpace(toolValue.Name)
+	if name == "" {
+		return Tool{}, core.ServerTool{}, errors.New("tool name is required")
+	}
+	if toolValue.Handler == nil {
+		return Tool{}, core.ServerTool{}, fmt.Errorf("tool %q handler is required", name)
+	}
+
+	toolValue.Name = name
+	return newToolDefinition(name, toolValue.Description, toolValue.Parameters), toolValue, nil
+}
+
+func newClientTool(toolValue core.ClientTool) (Tool, error) {
+	name := strings.TrimSpace(toolValue.Name)
+	if name == "" {
+		return Tool{}, errors.New("tool name is required")
+	}
+
+	return newToolDefinition(name, toolValue.Description, toolValue.Parameters), nil
+}
+
+func newToolDefinition(name, description string, parameters map[string]any) Tool {
+	if parameters == nil {
+		parameters = map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		}
+	}
+
+	return Tool{Type: "function", Function: ToolFunction{Name: name, Description: description, Parameters: parameters}}
+}
+
+func (c *Client) assertNewToolName(seen map[string]struct{}, name string) error {
+	if _, exists := seen[name]; exists {
+		return fmt.Errorf("%s: duplicate tool name %q", c.Provider, name)
+	}
+	seen[name] = struct{}{}
+	return nil
+}
+
+func (c *Client) applyToolChoice(request *Request, params *core.ChatParams) error {
+	if params.ToolChoice == nil || len(request.Tools) == 0 {
+		return nil
+	}
+
+	if name := strings.TrimSpace(params.ToolChoice.Name); name != "" {
+		for _, definition := range request.Tools {
+			if definition.Function.Name == name {
+				request.ToolChoice = map[string]any{"type": "function", "function": map[string]any{"name": name}}
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: tool choice %q does not match a tool", c.Provider, name)
+	}
+
+	switch mode := strings.TrimSpace(params.ToolChoice.Mode); mode {
+	case "":
+	case core.ToolChoiceAuto, core.ToolChoiceNone, core.ToolChoiceRequired:
+		request.ToolChoice = mode
+	default:
+		return fmt.Errorf("%s: unsupported tool choice mode %q", c.Provider, mode)
+	}
+	return nil
+}
+
+// CandidateCount returns the n parameter, which is only sent when more than
+// one choice is requested.
+func CandidateCount(params *core.ChatParams) *int64 {
+	if params == nil || params.CandidateCount == nil || *params.CandidateCount <= 1 {
+		return nil
+	}
+	return params.CandidateCount
+}
+
+// LogitBias converts token biases into the logit_bias map, clamping each bias
+// to the accepted [-100, 100] range.
+func LogitBias(params *core.ChatParams) map[string]int64 {
+	if params == nil || len(params.LogitBias) == 0 {
+		return nil
+	}
+
+	out := make(map[string]int64, len(params.LogitBias))
+	for token, bias := range params.LogitBias {
+		out[strconv.FormatInt(token, 10)] = int64(math.Round(math.Max(-100, math.Min(100, bias))))
+	}
+	return out
+}
+
+func toResponseFormat(output *core.Schema) *ResponseFormat {
+	if output == nil {
+		return nil
+	}
+	name := strings.TrimSpace(output.Name)
+	if name == "" {
+		name = "response"
+	}
+	return &ResponseFormat{
+		Type:       "json_schema",
+		JSONSchema: &JSONSchema{Name: name, Schema: output.Schema, Strict: output.Strict},
+	}
+}
+
+// useJSONObjectOutput requests a json_object response and adds a system
+// message with the schema after the system prompts.
+func (c *Client) useJSONObjectOutput(request *Request, output *core.Schema) error {
+	schema, err := json.Marshal(output.Schema)
+	if err != nil {
+		return fmt.Errorf("%s: marshal output schema: %w", c.Provider, err)
+	}
+
+	instruction := Message{
+		Role:    core.RoleSystem,
+		Content: "Respond with a JSON object only, matching this JSON schema:\n" + string(schema),
+	}
+	position := 0
+	for position < len(request.Messages) && request.Messages[position].Role == core.RoleSystem {
+		position++
+	}
+	request.Messages = append(request.Messages[:position], append([]Message{instruction}, request.Messages[position:]...)...)
+	request.ResponseFormat = &ResponseFormat{Type: "json_object"}
+	return nil
+}
+
+func maxTokens(params *core.ChatParams) *int64 {
+	if params.MaxTokens != nil && *params.MaxTokens > 0 {
+		return params.MaxTokens
+	}
+	if params.MaxOutputTokens != nil && *params.MaxOutputTokens > 0 {
+		return params.MaxOutputTokens
+	}
+	if params.MaxLength > 0 {
+		value := params.MaxLength
+		return &value
+	}
+	return nil
+}
+
+func maxLoops(params *core.ChatParams, hasServerTools bool) int {
+	if !hasServerTools {
+		return 1
+	}
+	if params.MaxAgenticLoops > 0 {
+		return int(params.MaxAgenticLoops)
+	}
+	return defaultMaxAgenticLoops
+}
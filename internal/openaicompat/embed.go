@@ -0,0 +1,72 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+// Embed creates one embedding vector per input with the embeddings
+// endpoint. Options are sent as top-level request fields.
+func (c *Client) Embed(ctx context.Context, inputs []string, dimensions *int64, options map[string]any) ([][]float64, *core.Usage, error) {
+	if err := c.validate(); err != nil {
+		return nil, nil, err
+	}
+	if dimensions != nil && *dimensions <= 0 {
+		return nil, nil, fmt.Errorf("%s: embed dimensions must be greater than zero", c.Provider)
+	}
+
+	request := embeddingRequest{Model: c.Model, Input: inputs, Dimensions: dimensions, EncodingFormat: "float"}
+	body, err := mergeFields(request, options)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: marshal embeddings request: %w", c.Provider, err)
+	}
+
+	var response embeddingResponse
+	if _, err := c.Transport.Send(ctx, httpclient.Request{Path: "/embeddings", Name: "embeddings", Body: body}, &response); err != nil {
+		return nil, nil, err
+	}
+	if len(response.Data) != len(inputs) {
+		return nil, nil, fmt.Errorf("%s: embeddings response count mismatch: expected %d, got %d", c.Provider, len(inputs), len(response.Data))
+	}
+
+	vectors := make([][]float64, len(inputs))
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= len(vectors) {
+			return nil, nil, fmt.Errorf("%s: embeddings response index %d out of range", c.Provider, item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	for i, vector := range vectors {
+		if vector == nil {
+			return nil, nil, fmt.Errorf("%s: embeddings response is missing index %d", c.Provider, i)
+		}
+		if dimensions != nil && int64(len(vector)) != *dimensions {
+			return nil, nil, &core.EmbeddingDimensionError{Expected: *dimensions, Actual: int64(len(vector)), Index: i}
+		}
+	}
+
+	return vectors, toCoreUsage(response.Usage), nil
+}
+
+// ListModels returns the models served by the models endpoint.
+func (c *Client) ListModels(ctx context.Context) ([]Model, error) {
+	if c == nil || c.Transport == nil {
+		return nil, fmt.Errorf("openaicompat: client transport is required")
+	}
+
+	httpResp, err := c.Transport.Send(ctx, httpclient.Request{Method: http.MethodGet, Path: "/models", Name: "models"}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var list modelList
+	if err := json.Unmarshal(httpResp.Body, &list); err != nil {
+		return nil, fmt.Errorf("%s: decode models response: %w", c.Provider, err)
+	}
+	return list.Data, nil
+}
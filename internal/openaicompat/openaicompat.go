@@ -0,0 +1,285 @@
+// Package openaicompat implements chat, embeddings, and model listing for
+// providers that serve the OpenAI Chat Completions wire format, such as Groq.
+//
+// A provider adapter builds a Client for each call with its own transport
+// and hooks for the fields its API adds to the format, so the conversion of
+// messages, the tool loop, and streaming behave the same for each of them.
+package openaicompat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+const (
+	defaultMaxAgenticLoops = 8
+
+	// reasoningBlockType marks the reasoning of a tool call response on
+	// core.ToolCallMessagePart.ReasoningBlocks.
+	reasoningBlockType = "reasoning_content"
+)
+
+// Client sends Chat Completions requests to one provider.
+type Client struct {
+	// Provider prefixes error messages, such as "groq", and selects the
+	// core.RawPart values that are sent as content parts.
+	Provider string
+
+	Model string
+
+	// Transport sends the requests. Its DecodeError is usually
+	// DecodeError(Provider).
+	Transport *httpclient.Client
+
+	// ChatPath is the chat endpoint, "/chat/completions" when empty.
+	ChatPath string
+
+	// StreamPath is the endpoint of streaming chat requests, for APIs that
+	// serve them apart from ChatPath. ChatPath is used when it is empty.
+	StreamPath string
+
+	// ModelField names the request field of the model, "model" when empty,
+	// for APIs such as watsonx.ai that call it model_id.
+	ModelField string
+
+	// StreamUsage asks for usage on the last chunk of a stream with
+	// stream_options.include_usage.
+	StreamUsage bool
+
+	// JSONObjectOutput requests structured output as a json_object response
+	// and describes the schema in a system message, for providers that do
+	// not accept json_schema.
+	JSONObjectOutput bool
+
+	// ReplayReasoning sends the reasoning of a tool call response back as
+	// reasoning_content with its tool calls, for providers whose reasoning
+	// models need it to continue a tool loop.
+	ReplayReasoning bool
+
+	// PrepareRequest adjusts each converted request before it is sent, for
+	// provider fields such as reasoning settings.
+	PrepareRequest func(params *core.ChatParams, request *Request) error
+
+	// Usage reads provider usage fields from a raw response or stream chunk.
+	// It is called with the usage parsed so far, which may be nil, and
+	// returns the usage to report.
+	Usage func(raw []byte, usage *core.Usage) *core.Usage
+
+	// Citations reads the sources of a raw chat response, such as the
+	// search results of an online model, for core.ChatResult.Citations.
+	Citations func(raw []byte) []core.Citation
+}
+
+func (c *Client) chatPath() string {
+	if strings.TrimSpace(c.ChatPath) == "" {
+		return "/chat/completions"
+	}
+	return c.ChatPath
+}
+
+func (c *Client) streamPath() string {
+	if strings.TrimSpace(c.StreamPath) == "" {
+		return c.chatPath()
+	}
+	return c.StreamPath
+}
+
+// DecodeError returns an error decoder for provider. It reads the
+// {"error":{"message"}} envelope of the OpenAI API as well as the
+// {"error":"..."} and {"message":"..."} forms some compatible servers use.
+// Status 429 becomes a core.RateLimitError and a missing model a
+// core.ModelNotFoundError.
+func DecodeError(provider string) func(*http.Response) error {
+	return func(resp *http.Response) error {
+		requestID := httpclient.RequestID(resp.Header)
+		message, errorType, code := decodeErrorBody(provider, resp)
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return &core.RateLimitError{
+				Message:    message,
+				RetryAfter: RetryAfter(resp.Header),
+				RateLimit:  RateLimit(resp.Header),
+				RequestID:  requestID,
+			}
+		}
+		if code == "model_not_found" || (resp.StatusCode == http.StatusNotFound && strings.Contains(strings.ToLower(message), "model")) {
+			return &core.ModelNotFoundError{Message: message, RequestID: requestID}
+		}
+
+		return &core.APIError{
+			StatusCode: resp.StatusCode,
+			Type:       errorType,
+			Message:    message,
+			RequestID:  requestID,
+			Retryable:  resp.StatusCode >= http.StatusInternalServerError,
+			RetryAfter: RetryAfter(resp.Header),
+		}
+	}
+}
+
+func decodeErrorBody(provider string, resp *http.Response) (string, string, string) {
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
+	if readErr != nil {
+		return fmt.Sprintf("%s: API status %d and failed to read error body: %v", provider, resp.StatusCode, readErr), "", ""
+	}
+
+	var envelope struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil {
+		var detail struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+			Code    any    `json:"code"`
+		}
+		var text string
+		switch {
+		case json.Unmarshal(envelope.Error, &detail) == nil && strings.TrimSpace(detail.Message) != "":
+			code := ""
+			if detail.Code != nil {
+				code = fmt.Sprint(detail.Code)
+			}
+			if detail.Type != "" || code != "" {
+				return fmt.Sprintf("%s: API error (%s, %s): %s", provider, detail.Type, code, strings.TrimSpace(detail.Message)), detail.Type, code
+			}
+			return fmt.Sprintf("%s: API error: %s", provider, strings.TrimSpace(detail.Message)), "", ""
+		case json.Unmarshal(envelope.Error, &text) == nil && strings.TrimSpace(text) != "":
+			return fmt.Sprintf("%s: API error: %s", provider, strings.TrimSpace(text)), "", ""
+		case strings.TrimSpace(envelope.Message) != "":
+			return fmt.Sprintf("%s: API error: %s", provider, strings.TrimSpace(envelope.Message)), "", ""
+		}
+	}
+
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		text = http.StatusText(resp.StatusCode)
+	}
+	return fmt.Sprintf("%s: API status %d: %s", provider, resp.StatusCode, text), "", ""
+}
+
+// RateLimit reads the x-ratelimit-* headers of a response. It returns nil
+// when none are set.
+func RateLimit(header http.Header) *core.RateLimit {
+	if header == nil {
+		return nil
+	}
+
+	rateLimit := core.RateLimit{
+		LimitRequests:     headerInt(header, "x-ratelimit-limit-requests"),
+		LimitTokens:       headerInt(header, "x-ratelimit-limit-tokens"),
+		RemainingRequests: headerInt(header, "x-ratelimit-remaining-requests"),
+		RemainingTokens:   headerInt(header, "x-ratelimit-remaining-tokens"),
+		ResetRequests:     headerDuration(header, "x-ratelimit-reset-requests"),
+		ResetTokens:       headerDuration(header, "x-ratelimit-reset-tokens"),
+	}
+	if rateLimit == (core.RateLimit{}) {
+		return nil
+	}
+	return &rateLimit
+}
+
+// RetryAfter reads retry-after-ms or retry-after, in seconds or as an HTTP
+// date.
+func RetryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+	if ms := strings.TrimSpace(header.Get("retry-after-ms")); ms != "" {
+		if value, err := strconv.ParseFloat(ms, 64); err == nil && value > 0 {
+			return time.Duration(value * float64(time.Millisecond))
+		}
+	}
+	value := strings.TrimSpace(header.Get("retry-after"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// Timings are the processing times, in seconds, that Groq and Cerebras
+// report with the usage of a response or stream chunk.
+type Timings struct {
+	QueueTime      float64 `json:"queue_time"`
+	PromptTime     float64 `json:"prompt_time"`
+	CompletionTime float64 `json:"completion_time"`
+	TotalTime      float64 `json:"total_time"`
+}
+
+// AddTimings adds timings to usage.Details: queue_time_us, prompt_time_us,
+// completion_time_us, and total_time_us in microseconds, and
+// completion_tokens_per_second.
+func AddTimings(usage *core.Usage, timings Timings) {
+	AddDetail(usage, "queue_time_us", microseconds(timings.QueueTime))
+	AddDetail(usage, "prompt_time_us", microseconds(timings.PromptTime))
+	AddDetail(usage, "completion_time_us", microseconds(timings.CompletionTime))
+	AddDetail(usage, "total_time_us", microseconds(timings.TotalTime))
+	if timings.CompletionTime > 0 {
+		AddDetail(usage, "completion_tokens_per_second", int64(float64(usage.CompletionTokens)/timings.CompletionTime))
+	}
+}
+
+// AddDetail sets usage.Details[key] to a positive value.
+func AddDetail(usage *core.Usage, key string, value int64) {
+	if value <= 0 {
+		return
+	}
+	if usage.Details == nil {
+		usage.Details = make(map[string]int64)
+	}
+	usage.Details[key] = value
+}
+
+func microseconds(seconds float64) int64 {
+	return int64(seconds * 1e6)
+}
+
+func headerInt(header http.Header, key string) int64 {
+	value, err := strconv.ParseInt(strings.TrimSpace(header.Get(key)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// headerDuration parses reset headers such as "1s", "6m0s", or "20ms".
+func headerDuration(header http.Header, key string) time.Duration {
+	value := strings.TrimSpace(header.Get(key))
+	if value == "" {
+		return 0
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return duration
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return 0
+}
+
+func (c *Client) validate() error {
+	if c == nil || c.Transport == nil {
+		return errors.New("openaicompat: client transport is required")
+	}
+	if strings.TrimSpace(c.Model) == "" {
+		return fmt.Errorf("%s: model is required", c.Provider)
+	}
+	return nil
+}
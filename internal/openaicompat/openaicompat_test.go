@@ -0,0 +1,448 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+func newTestClient(url string) *Client {
+	return &Client{
+		Provider: "test",
+		Model:    "test-model",
+		Transport: &httpclient.Client{
+			BaseURL:     url,
+			Provider:    "test",
+			DecodeError: DecodeError("test"),
+		},
+	}
+}
+
+func TestChatRunsServerToolsAndMergesModelOptions(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+
+		w.Header().Set("x-request-id", "req-chat")
+		w.Header().Set("x-ratelimit-remaining-requests", "99")
+		switch calls.Add(1) {
+		case 1:
+			if body["model"] != "test-model" || body["service_tier"] != "flex" || body["tool_choice"] != "auto" {
+				t.Errorf("unexpected first request %v", body)
+			}
+			messages := body["messages"].([]any)
+			if first := messages[0].(map[string]any); first["role"] != "system" || first["content"] != "Be brief." {
+				t.Errorf("unexpected system message %v", first)
+			}
+			fmt.Fprint(w, `{"id":"c1","choices":[{"index":0,"message":{"role":"assistant","reasoning_content":"Need weather.","tool_calls":[{"id":"call_1","type":"function","function":{"name":"weather","arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`)
+		default:
+			messages := body["messages"].([]any)
+			last := messages[len(messages)-1].(map[string]any)
+			if last["role"] != "tool" || last["tool_call_id"] != "call_1" || last["content"] != "sunny" {
+				t.Errorf("unexpected tool result message %v", last)
+			}
+			fmt.Fprint(w, `{"id":"c2","choices":[{"index":0,"message":{"role":"assistant","content":"It is sunny."},"finish_reason":"stop"}],"usage":{"prompt_tokens":20,"completion_tokens":4,"total_tokens":24,"prompt_tokens_details":{"cached_tokens":8}}}`)
+		}
+	}))
+	defer server.Close()
+
+	result, err := newTestClient(server.URL).Chat(context.Background(), &core.ChatParams{
+		SystemPrompts: []string{"Be brief."},
+		Messages:      []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather in Paris?"}},
+		Tools: []core.ToolUnion{core.ServerTool{
+			Name: "weather",
+			Handler: func(arguments any) (string, error) {
+				if arguments.(map[string]any)["city"] != "Paris" {
+					t.Errorf("unexpected arguments %v", arguments)
+				}
+				return "sunny", nil
+			},
+		}},
+		ToolChoice:   &core.ToolChoice{Mode: core.ToolChoiceAuto},
+		ModelOptions: map[string]any{"service_tier": "flex"},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if result.Text != "It is sunny." || result.Reasoning != "Need weather." || result.FinishReason != core.FinishReasonStop || result.RequestID != "req-chat" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 24 || result.Usage.Details["cached_tokens"] != 8 {
+		t.Fatalf("unexpected usage %+v", result.Usage)
+	}
+	if result.RateLimit == nil || result.RateLimit.RemainingRequests != 99 {
+		t.Fatalf("unexpected rate limit %+v", result.RateLimit)
+	}
+	if len(result.Messages) != 4 {
+		t.Fatalf("unexpected conversation %#v", result.Messages)
+	}
+}
+
+func TestChatReturnsClientToolCalls(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body Request
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","tool_calls":[{"id":"call_9","type":"function","function":{"name":"lookup","arguments":""}}]},"finish_reason":"tool_calls"}]}`)
+	}))
+	defer server.Close()
+
+	result, err := newTestClient(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages:   []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Find it"}},
+		Tools:      []core.ToolUnion{core.ClientTool{Name: "lookup"}},
+		ToolChoice: &core.ToolChoice{Name: "lookup"},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.FinishReason != core.FinishReasonToolCalls || len(result.ToolCalls) != 1 || result.ToolCalls[0].ID != "call_9" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestChatSendsParallelToolCallsLogitBiasAndCandidates(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"Yes."},"finish_reason":"stop"},{"index":1,"message":{"role":"assistant","content":"No."},"finish_reason":"length"}]}`)
+	}))
+	defer server.Close()
+
+	parallel := false
+	count := int64(2)
+	result, err := newTestClient(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages:          []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Well?"}},
+		Tools:             []core.ToolUnion{core.ClientTool{Name: "lookup"}},
+		ParallelToolCalls: &parallel,
+		LogitBias:         map[int64]float64{50256: -150, 42: 2.4},
+		CandidateCount:    &count,
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	bias, _ := body["logit_bias"].(map[string]any)
+	if body["parallel_tool_calls"] != false || body["n"] != float64(2) || bias["50256"] != float64(-100) || bias["42"] != float64(2) {
+		t.Fatalf("unexpected request body %v", body)
+	}
+	if result.Text != "Yes." || len(result.Candidates) != 2 || result.Candidates[1].Text != "No." || result.Candidates[1].FinishReason != core.FinishReasonLength {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestChatStreamAssemblesToolCallsAndKeepsLatestUsage(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["stream"] != true || body["stream_options"] == nil {
+			t.Errorf("unexpected stream request %v", body)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("x-request-id", fmt.Sprintf("req-%d", calls.Add(1)))
+
+		if calls.Load() == 1 {
+			fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"weather","arguments":"{\"ci"}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"ty\":\"Oslo\"}"}}]},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+
+`)
+			return
+		}
+		fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"reasoning":"Cold."}}]}
+
+data: {"choices":[{"index":0,"delta":{"content":"Snow"}}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}
+
+data: {"choices":[{"index":0,"delta":{"content":"ing."},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}
+
+data: [DONE]
+
+`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.StreamUsage = true
+	stream, err := client.ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather in Oslo?"}},
+		Tools: []core.ToolUnion{core.ServerTool{Name: "weather", Handler: func(arguments any) (string, error) {
+			return "snow", nil
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var order []string
+	var done core.StreamChunk
+	var toolCall *core.ToolCall
+	content := ""
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkError:
+			t.Fatalf("unexpected chunk error: %s", chunk.Error)
+		case core.StreamChunkToolCall:
+			toolCall = chunk.ToolCall
+		case core.StreamChunkContent:
+			content = chunk.Content
+		case core.StreamChunkDone:
+			done = chunk
+		}
+		order = append(order, chunk.Type)
+	}
+
+	expected := []string{core.StreamChunkToolCall, core.StreamChunkToolResult, core.StreamChunkReasoning, core.StreamChunkContent, core.StreamChunkContent, core.StreamChunkDone}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("unexpected chunk order: %#v", order)
+	}
+	if toolCall == nil || toolCall.ID != "call_1" || !reflect.DeepEqual(toolCall.Arguments, map[string]any{"city": "Oslo"}) {
+		t.Fatalf("unexpected tool call %+v", toolCall)
+	}
+	if content != "Snowing." || done.Reasoning != "Cold." || done.RequestID != "req-2" {
+		t.Fatalf("unexpected stream result %q, %+v", content, done)
+	}
+	if done.Usage == nil || done.Usage.CompletionTokens != 2 || done.Usage.RequestID != "req-2" {
+		t.Fatalf("unexpected usage %+v", done.Usage)
+	}
+}
+
+func TestChatStreamEmitsPartialJSONAndAppliesUsageHook(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body Request
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.ResponseFormat == nil || body.ResponseFormat.Type != "json_schema" || body.ResponseFormat.JSONSchema.Name != "answer" {
+			t.Errorf("unexpected response format %+v", body.ResponseFormat)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"content":"{\"ok\":"}}]}
+
+data: {"choices":[{"index":0,"delta":{"content":"true}"},"finish_reason":"stop"}],"extra":{"ms":7}}
+
+`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.Usage = func(raw []byte, usage *core.Usage) *core.Usage {
+		var chunk struct {
+			Extra *struct {
+				MS int64 `json:"ms"`
+			} `json:"extra"`
+		}
+		if json.Unmarshal(raw, &chunk) != nil || chunk.Extra == nil {
+			return usage
+		}
+		return &core.Usage{Details: map[string]int64{"ms": chunk.Extra.MS}}
+	}
+
+	stream, err := client.ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "ok?"}},
+		Output:   &core.Schema{Name: "answer", Schema: map[string]any{"type": "object"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var partial string
+	var done core.StreamChunk
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkPartialJSON:
+			partial = chunk.Content
+		case core.StreamChunkDone:
+			done = chunk
+		case core.StreamChunkContent, core.StreamChunkError:
+			t.Fatalf("unexpected chunk %+v", chunk)
+		}
+	}
+	if partial != `{"ok":true}` {
+		t.Fatalf("partial = %q", partial)
+	}
+	if done.Usage == nil || done.Usage.Details["ms"] != 7 {
+		t.Fatalf("unexpected usage %+v", done.Usage)
+	}
+}
+
+func TestDecodeErrorForms(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		check   func(error) bool
+		message string
+	}{
+		{
+			name:   "rate limit",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":{"message":"slow down","type":"tokens","code":"rate_limit_exceeded"}}`,
+			check: func(err error) bool {
+				var rateLimit *core.RateLimitError
+				return errors.As(err, &rateLimit) && rateLimit.RetryAfter > 0 && rateLimit.RequestID == "req-err"
+			},
+			message: "slow down",
+		},
+		{
+			name:   "model not found",
+			status: http.StatusNotFound,
+			body:   `{"error":{"message":"The model does not exist","type":"invalid_request_error","code":"model_not_found"}}`,
+			check: func(err error) bool {
+				var notFound *core.ModelNotFoundError
+				return errors.As(err, &notFound)
+			},
+			message: "does not exist",
+		},
+		{
+			name:   "string error",
+			status: http.StatusBadRequest,
+			body:   `{"error":"bad grammar"}`,
+			check: func(err error) bool {
+				var apiErr *core.APIError
+				return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest && !apiErr.Retryable
+			},
+			message: "test: API error: bad grammar",
+		},
+		{
+			name:   "message field",
+			status: http.StatusServiceUnavailable,
+			body:   `{"message":"overloaded"}`,
+			check: func(err error) bool {
+				var apiErr *core.APIError
+				return errors.As(err, &apiErr) && apiErr.Retryable
+			},
+			message: "overloaded",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("x-request-id", "req-err")
+				w.Header().Set("retry-after", "2")
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			}))
+			defer server.Close()
+
+			_, err := newTestClient(server.URL).Chat(context.Background(), &core.ChatParams{
+				Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+			})
+			if err == nil || !test.check(err) || !strings.Contains(err.Error(), test.message) {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestEmbedOrdersVectorsByIndex(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request map[string]any
+		_ = json.Unmarshal(body, &request)
+		if r.URL.Path != "/embeddings" || request["dimensions"] != float64(2) || request["task"] != "retrieval" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, body)
+		}
+		fmt.Fprint(w, `{"data":[{"index":1,"embedding":[3,4]},{"index":0,"embedding":[1,2]}],"usage":{"prompt_tokens":6,"total_tokens":6}}`)
+	}))
+	defer server.Close()
+
+	dimensions := int64(2)
+	vectors, usage, err := newTestClient(server.URL).Embed(context.Background(), []string{"a", "b"}, &dimensions, map[string]any{"task": "retrieval"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if !reflect.DeepEqual(vectors, [][]float64{{1, 2}, {3, 4}}) || usage == nil || usage.PromptTokens != 6 {
+		t.Fatalf("unexpected embeddings %v, %+v", vectors, usage)
+	}
+}
+
+func TestListModels(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/models" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"a","object":"model","owned_by":"me"},{"id":"b"}]}`)
+	}))
+	defer server.Close()
+
+	models, err := newTestClient(server.URL).ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "a" || models[0].OwnedBy != "me" {
+		t.Fatalf("unexpected models %+v", models)
+	}
+}
+
+func TestMessageMarshalsExtraFields(t *testing.T) {
+	t.Parallel()
+
+	encoded, err := json.Marshal(Message{Role: core.RoleAssistant, Content: "{", Extra: map[string]any{"partial": true}})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(encoded) != `{"content":"{","partial":true,"role":"assistant"}` {
+		t.Fatalf("encoded = %s", encoded)
+	}
+}
+
+func TestAddTimings(t *testing.T) {
+	t.Parallel()
+
+	usage := &core.Usage{CompletionTokens: 200, Details: map[string]int64{"reasoning_tokens": 10}}
+	AddTimings(usage, Timings{QueueTime: 0.0002, PromptTime: 0.004, CompletionTime: 0.5, TotalTime: 0.5042})
+
+	want := map[string]int64{
+		"reasoning_tokens":             10,
+		"queue_time_us":                200,
+		"prompt_time_us":               4000,
+		"completion_time_us":           500000,
+		"total_time_us":                504200,
+		"completion_tokens_per_second": 400,
+	}
+	if !reflect.DeepEqual(usage.Details, want) {
+		t.Fatalf("details = %v, want %v", usage.Details, want)
+	}
+
+	empty := &core.Usage{}
+	AddTimings(empty, Timings{})
+	if empty.Details != nil {
+		t.Fatalf("details = %v, want nil", empty.Details)
+	}
+}
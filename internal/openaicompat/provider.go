@@ -0,0 +1,34 @@
+package openaicompat
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// Builder builds a provider adapter for core.NewAdapter. Empty values keep
+// the adapter's defaults, such as its base URL and the environment variable
+// of its API key.
+type Builder func(model, apiKey, baseURL string, timeout time.Duration) core.TextAdapter
+
+// RegisterProvider registers an adapter under name with
+// core.RegisterProvider. The only supported option is timeout.
+func RegisterProvider(name string, build Builder) {
+	core.RegisterProvider(name, func(config core.ProviderConfig) (core.TextAdapter, error) {
+		var timeout time.Duration
+		for option, value := range config.Options {
+			switch option {
+			case "timeout":
+				parsed, err := time.ParseDuration(value)
+				if err != nil {
+					return nil, fmt.Errorf("option %s: %w", option, err)
+				}
+				timeout = parsed
+			default:
+				return nil, fmt.Errorf("unknown option %q", option)
+			}
+		}
+		return build(config.Model, config.APIKey, config.BaseURL, timeout), nil
+	})
+}
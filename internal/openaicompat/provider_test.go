@@ -0,0 +1,61 @@
+package openaicompat_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	_ "github.com/m43i/go-ai/dashscope"
+	_ "github.com/m43i/go-ai/deepseek"
+	_ "github.com/m43i/go-ai/groq"
+	_ "github.com/m43i/go-ai/lmstudio"
+	_ "github.com/m43i/go-ai/moonshot"
+	_ "github.com/m43i/go-ai/perplexity"
+)
+
+func TestRegisteredProvidersFromDSN(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		dsn     string
+		model   string
+		apiKey  string
+		baseURL string
+		timeout time.Duration
+	}{
+		{dsn: "groq://gsk-key@llama-3.3-70b-versatile?timeout=20s", model: "llama-3.3-70b-versatile", apiKey: "gsk-key", timeout: 20 * time.Second},
+		{dsn: "deepseek://sk-key@deepseek-reasoner?timeout=90s", model: "deepseek-reasoner", apiKey: "sk-key", timeout: 90 * time.Second},
+		{dsn: "perplexity://pplx-key@sonar-pro?timeout=20s", model: "sonar-pro", apiKey: "pplx-key", timeout: 20 * time.Second},
+		{dsn: "lmstudio://qwen/qwen3-8b?base_url=http://studio-box:1234&timeout=20s", model: "qwen/qwen3-8b", baseURL: "http://studio-box:1234", timeout: 20 * time.Second},
+		{dsn: "lmstudio://?base_url=http://studio-box:1234", baseURL: "http://studio-box:1234"},
+		{dsn: "moonshot://sk-key@kimi-k2-0905-preview?base_url=https://api.moonshot.cn/v1&timeout=90s", model: "kimi-k2-0905-preview", apiKey: "sk-key", baseURL: "https://api.moonshot.cn/v1", timeout: 90 * time.Second},
+		{dsn: "dashscope://sk-key@qwen-plus?base_url=https://dashscope-intl.aliyuncs.com/compatible-mode/v1", model: "qwen-plus", apiKey: "sk-key", baseURL: "https://dashscope-intl.aliyuncs.com/compatible-mode/v1"},
+	} {
+		adapter, err := core.FromDSN(tt.dsn)
+		if err != nil {
+			t.Fatalf("FromDSN(%q) error = %v", tt.dsn, err)
+		}
+
+		fields := reflect.ValueOf(adapter).Elem()
+		if model := fields.FieldByName("Model").String(); model != tt.model {
+			t.Fatalf("FromDSN(%q) model = %q", tt.dsn, model)
+		}
+		if tt.apiKey != "" && fields.FieldByName("APIKey").String() != tt.apiKey {
+			t.Fatalf("FromDSN(%q) API key = %q", tt.dsn, fields.FieldByName("APIKey").String())
+		}
+		if tt.baseURL != "" && fields.FieldByName("BaseURL").String() != tt.baseURL {
+			t.Fatalf("FromDSN(%q) base URL = %q", tt.dsn, fields.FieldByName("BaseURL").String())
+		}
+		if client := fields.FieldByName("HTTPClient").Interface().(*http.Client); tt.timeout != 0 && (client == nil || client.Timeout != tt.timeout) {
+			t.Fatalf("FromDSN(%q) HTTP client = %+v", tt.dsn, client)
+		}
+	}
+
+	for _, dsn := range []string{"groq://llama-3.3-70b-versatile?gzip=1", "moonshot://kimi-latest?partial=1", "dashscope://qwen-plus?timeout=soon"} {
+		if _, err := core.FromDSN(dsn); err == nil {
+			t.Fatalf("FromDSN(%q) expected an option error", dsn)
+		}
+	}
+}
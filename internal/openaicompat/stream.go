@@ -0,0 +1,281 @@
+package openaicompat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/partialjson"
+	"github.com/m43i/go-ai/internal/sse"
+)
+
+// streamedTurn is one streamed model response.
+type streamedTurn struct {
+	content      string
+	reasoning    string
+	calls        []ToolCall
+	finishReason string
+	usage        *core.Usage
+	requestID    string
+}
+
+// ChatStream sends a streaming chat request. Server tools run between
+// streamed turns, so tool calls and their results are emitted as they
+// happen. Structured output streams as core.StreamChunkPartialJSON chunks.
+// Only the first of several candidates streams; Chat returns all of them.
+func (c *Client) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	requestTemplate, serverTools, clientTools, maxLoopCount, err := c.buildRequest(params)
+	if err != nil {
+		return nil, err
+	}
+	requestTemplate.Stream = true
+	if c.StreamUsage {
+		requestTemplate.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+
+	start := time.Now()
+	out := make(chan core.StreamChunk, 64)
+
+	go func() {
+		defer close(out)
+
+		messages := requestTemplate.Messages
+		reasoningParts := make([]string, 0, 4)
+
+		for range maxLoopCount {
+			request := requestTemplate
+			request.Messages = messages
+
+			turn, err := c.streamTurn(ctx, &request, out)
+			if err != nil {
+				requestID := core.RequestIDOf(err)
+				if requestID == "" {
+					requestID = turn.requestID
+				}
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: requestID}
+				return
+			}
+
+			reasoningParts = appendReasoningPart(reasoningParts, turn.reasoning)
+
+			if len(turn.calls) == 0 {
+				out <- core.StreamChunk{
+					Type:            core.StreamChunkDone,
+					FinishReason:    toCoreFinishReason(turn.finishReason),
+					RawFinishReason: turn.finishReason,
+					Reasoning:       joinReasoningParts(reasoningParts),
+					Usage:           turn.usage,
+					RequestID:       turn.requestID,
+				}
+				return
+			}
+
+			coreCalls, err := c.toCoreToolCalls(turn.calls)
+			if err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: turn.requestID}
+				return
+			}
+			for i := range coreCalls {
+				call := coreCalls[i]
+				out <- core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &call}
+			}
+
+			messages = append(messages, c.toolCallMessage(turn.content, turn.reasoning, turn.calls))
+
+			results, pendingClientCalls, err := c.runServerTools(coreCalls, serverTools, clientTools)
+			if err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err}
+				return
+			}
+			for _, result := range results {
+				out <- core.StreamChunk{Type: core.StreamChunkToolResult, ToolCallID: result.ToolCallID, Content: result.Content}
+				messages = append(messages, toolMessage(result.ToolCallID, result.Content))
+			}
+
+			if len(pendingClientCalls) > 0 {
+				out <- core.StreamChunk{
+					Type:            core.StreamChunkDone,
+					FinishReason:    core.FinishReasonToolCalls,
+					RawFinishReason: turn.finishReason,
+					Reasoning:       joinReasoningParts(reasoningParts),
+					Usage:           turn.usage,
+					RequestID:       turn.requestID,
+				}
+				return
+			}
+		}
+
+		out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("%s: reached max tool loop count (%d)", c.Provider, maxLoopCount)}
+	}()
+
+	return core.TimeStream(ctx, out, start), nil
+}
+
+// streamTurn streams one response, emitting content and reasoning chunks,
+// and returns the turn once the stream ends. Tool call deltas are assembled
+// by index. Usage may arrive on any chunk; the latest one is kept.
+func (c *Client) streamTurn(ctx context.Context, request *Request, out chan<- core.StreamChunk) (*streamedTurn, error) {
+	turn := &streamedTurn{}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return turn, fmt.Errorf("%s: marshal stream request: %w", c.Provider, err)
+	}
+
+	httpResp, err := c.Transport.Do(ctx, httpclient.Request{
+		Path:   c.streamPath(),
+		Name:   "stream",
+		Body:   body,
+		Header: http.Header{"Accept": {"text/event-stream"}},
+	})
+	if err != nil {
+		return turn, err
+	}
+	defer httpResp.Body.Close()
+	turn.requestID = httpclient.RequestID(httpResp.Header)
+
+	reader := sse.NewReader(httpResp.Body)
+	defer reader.Release()
+
+	var content, reasoning strings.Builder
+	var partial partialjson.Assembler
+	calls := make(map[int]*ToolCall)
+
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return turn, fmt.Errorf("%s: stream read failed: %w", c.Provider, err)
+		}
+
+		payload := bytes.TrimSpace(event.Data)
+		if len(payload) == 0 {
+			continue
+		}
+		if bytes.Equal(payload, []byte("[DONE]")) {
+			break
+		}
+
+		var chunk struct {
+			response
+			Error json.RawMessage `json:"error,omitempty"`
+		}
+		if err := json.Unmarshal(payload, &chunk); err != nil {
+			return turn, fmt.Errorf("%s: decode stream chunk: %w", c.Provider, err)
+		}
+		if len(chunk.Error) > 0 && !bytes.Equal(chunk.Error, []byte("null")) {
+			return turn, fmt.Errorf("%s: stream error: %s", c.Provider, streamErrorMessage(chunk.Error))
+		}
+
+		turn.usage = c.chunkUsage(payload, toCoreUsage(chunk.Usage), turn.usage)
+
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 {
+				continue
+			}
+			delta := choice.Delta
+
+			if text := delta.reasoning(); text != "" {
+				reasoning.WriteString(text)
+				out <- core.StreamChunk{
+					Type:      core.StreamChunkReasoning,
+					Role:      core.RoleAssistant,
+					Delta:     text,
+					Reasoning: reasoning.String(),
+				}
+			}
+
+			if delta.Content != "" {
+				content.WriteString(delta.Content)
+				if request.ResponseFormat != nil {
+					partial.WriteString(delta.Content)
+					out <- core.StreamChunk{
+						Type:    core.StreamChunkPartialJSON,
+						Role:    core.RoleAssistant,
+						Delta:   delta.Content,
+						Content: partial.Partial(),
+					}
+				} else {
+					out <- core.StreamChunk{
+						Type:    core.StreamChunkContent,
+						Role:    core.RoleAssistant,
+						Delta:   delta.Content,
+						Content: content.String(),
+					}
+				}
+			}
+
+			for position, call := range delta.ToolCalls {
+				index := position
+				if call.Index != nil {
+					index = *call.Index
+				}
+				current, ok := calls[index]
+				if !ok {
+					current = &ToolCall{Type: "function"}
+					calls[index] = current
+				}
+				if call.ID != "" {
+					current.ID = call.ID
+				}
+				if call.Function.Name != "" {
+					current.Function.Name = call.Function.Name
+				}
+				current.Function.Arguments += call.Function.Arguments
+			}
+
+			if choice.FinishReason != "" {
+				turn.finishReason = choice.FinishReason
+			}
+		}
+	}
+
+	indexes := make([]int, 0, len(calls))
+	for index := range calls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		call := *calls[index]
+		if call.ID == "" {
+			call.ID = fmt.Sprintf("call_%d", index+1)
+		}
+		turn.calls = append(turn.calls, call)
+	}
+
+	turn.content = content.String()
+	turn.reasoning = reasoning.String()
+	if turn.usage != nil {
+		turn.usage.RequestID = turn.requestID
+	}
+	return turn, nil
+}
+
+// streamErrorMessage reads the message of an error sent inside a stream.
+func streamErrorMessage(raw json.RawMessage) string {
+	var detail struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &detail); err == nil && detail.Message != "" {
+		return detail.Message
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil && text != "" {
+		return text
+	}
+	return string(raw)
+}
@@ -0,0 +1,214 @@
+package openaicompat
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Request is a Chat Completions request. Extra holds provider fields that
+// are sent at the top level of the body; ModelOptions are merged into it.
+type Request struct {
+	Model             string           `json:"model,omitempty"`
+	Messages          []Message        `json:"messages"`
+	Tools             []Tool           `json:"tools,omitempty"`
+	ToolChoice        any              `json:"tool_choice,omitempty"`
+	ParallelToolCalls *bool            `json:"parallel_tool_calls,omitempty"`
+	ResponseFormat    *ResponseFormat  `json:"response_format,omitempty"`
+	MaxTokens         *int64           `json:"max_tokens,omitempty"`
+	Temperature       *float64         `json:"temperature,omitempty"`
+	TopP              *float64         `json:"top_p,omitempty"`
+	Seed              *int64           `json:"seed,omitempty"`
+	Stop              []string         `json:"stop,omitempty"`
+	LogitBias         map[string]int64 `json:"logit_bias,omitempty"`
+	N                 *int64           `json:"n,omitempty"`
+	ReasoningEffort   string           `json:"reasoning_effort,omitempty"`
+	Stream            bool             `json:"stream,omitempty"`
+	StreamOptions     *StreamOptions   `json:"stream_options,omitempty"`
+
+	Extra map[string]any `json:"-"`
+}
+
+// Message is one request message. Content is a string, or a []ContentPart
+// for multimodal messages. Extra holds provider fields of the message, such
+// as Moonshot's "partial".
+type Message struct {
+	Role       string     `json:"role"`
+	Content    any        `json:"content,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+
+	// ReasoningContent is only set when Client.ReplayReasoning is.
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+
+	Extra map[string]any `json:"-"`
+}
+
+type ContentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+
+	// Raw replaces the whole part when set, for core.RawPart.
+	Raw json.RawMessage `json:"-"`
+}
+
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type Tool struct {
+	Type     string       `json:"type"`
+	Function ToolFunction `json:"function"`
+}
+
+type ToolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters"`
+	Strict      bool           `json:"strict,omitempty"`
+}
+
+type ToolCall struct {
+	Index    *int             `json:"index,omitempty"`
+	ID       string           `json:"id,omitempty"`
+	Type     string           `json:"type,omitempty"`
+	Function ToolCallFunction `json:"function"`
+}
+
+type ToolCallFunction struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+type JSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+	Strict bool           `json:"strict,omitempty"`
+}
+
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type response struct {
+	ID      string   `json:"id"`
+	Choices []choice `json:"choices"`
+	Usage   *usage   `json:"usage,omitempty"`
+
+	raw       []byte
+	requestID string
+	duration  time.Duration
+}
+
+type choice struct {
+	Index        int             `json:"index"`
+	Message      responseMessage `json:"message"`
+	Delta        responseMessage `json:"delta"`
+	FinishReason string          `json:"finish_reason"`
+}
+
+// responseMessage is a response message or stream delta. Reasoning models
+// return their reasoning as reasoning_content or, on some providers, as
+// reasoning.
+type responseMessage struct {
+	Role             string     `json:"role,omitempty"`
+	Content          string     `json:"content,omitempty"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	Reasoning        string     `json:"reasoning,omitempty"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
+}
+
+func (m responseMessage) reasoning() string {
+	if m.ReasoningContent != "" {
+		return m.ReasoningContent
+	}
+	return m.Reasoning
+}
+
+type usage struct {
+	PromptTokens            int64                    `json:"prompt_tokens"`
+	CompletionTokens        int64                    `json:"completion_tokens"`
+	TotalTokens             int64                    `json:"total_tokens"`
+	PromptTokensDetails     *promptTokensDetails     `json:"prompt_tokens_details,omitempty"`
+	CompletionTokensDetails *completionTokensDetails `json:"completion_tokens_details,omitempty"`
+}
+
+type promptTokensDetails struct {
+	CachedTokens int64 `json:"cached_tokens,omitempty"`
+}
+
+type completionTokensDetails struct {
+	ReasoningTokens int64 `json:"reasoning_tokens,omitempty"`
+}
+
+type embeddingRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     *int64   `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format,omitempty"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Usage *usage `json:"usage,omitempty"`
+}
+
+// Model is one entry of the model list.
+type Model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object,omitempty"`
+	OwnedBy string `json:"owned_by,omitempty"`
+	Created int64  `json:"created,omitempty"`
+}
+
+type modelList struct {
+	Data []Model `json:"data"`
+}
+
+func (p ContentPart) MarshalJSON() ([]byte, error) {
+	if len(p.Raw) > 0 {
+		return p.Raw, nil
+	}
+	type plain ContentPart
+	return json.Marshal(plain(p))
+}
+
+func (r Request) MarshalJSON() ([]byte, error) {
+	type plain Request
+	return mergeFields(plain(r), r.Extra)
+}
+
+func (m Message) MarshalJSON() ([]byte, error) {
+	type plain Message
+	return mergeFields(plain(m), m.Extra)
+}
+
+// mergeFields encodes value and sets each of fields at its top level.
+func mergeFields(value any, fields map[string]any) ([]byte, error) {
+	body, err := json.Marshal(value)
+	if err != nil || len(fields) == 0 {
+		return body, err
+	}
+
+	var envelope map[string]any
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	for key, field := range fields {
+		if key == "" || field == nil {
+			continue
+		}
+		envelope[key] = field
+	}
+	return json.Marshal(envelope)
+}
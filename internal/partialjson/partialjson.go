@@ -0,0 +1,365 @@
+// Package partialjson assembles a JSON value from fragments as they stream,
+// such as tool call arguments or structured output arriving in pieces.
+//
+// An Assembler scans each fragment once and remembers where the text so far
+// can be cut and closed, so the valid JSON for a prefix is available after
+// every fragment without rescanning the whole value.
+package partialjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrIncomplete is returned by Assembler.Decode when no prefix of the text
+// forms a JSON value yet.
+var ErrIncomplete = errors.New("partialjson: no complete value yet")
+
+// state is what the innermost open object or array expects next.
+type state uint8
+
+const (
+	objectKeyOrEnd state = iota // after '{'
+	objectKey                   // after ','
+	objectColon
+	objectValue
+	objectCommaOrEnd
+	arrayValueOrEnd // after '['
+	arrayValue      // after ','
+	arrayCommaOrEnd
+)
+
+func (s state) closer() byte {
+	if s < arrayValueOrEnd {
+		return '}'
+	}
+	return ']'
+}
+
+// token is the kind of scalar being scanned.
+type token uint8
+
+const (
+	tokenNone token = iota
+	tokenString
+	tokenNumber
+	tokenLiteral
+)
+
+// Assembler accumulates the fragments of one JSON value. The zero value is
+// ready to use. An Assembler is not safe for concurrent use.
+type Assembler struct {
+	buf   []byte
+	stack []state
+	done  bool
+	err   error
+
+	token      token
+	tokenStart int
+	key        bool   // the string token is an object key
+	escape     int    // 1 after a backslash, 2 to 5 inside a \u escape
+	escapeAt   int    // offset of the pending backslash
+	literal    string // the literal a tokenLiteral must spell
+
+	// checkpoint is the longest prefix that becomes valid JSON by closing
+	// the first checkpointDepth open containers, or zero when there is none.
+	checkpoint      int
+	checkpointDepth int
+}
+
+// Write appends a fragment and scans it. After the text becomes invalid JSON,
+// further fragments are kept but not scanned, and the error is returned.
+func (a *Assembler) Write(p []byte) (int, error) {
+	start := len(a.buf)
+	a.buf = append(a.buf, p...)
+	a.scan(start)
+	return len(p), a.err
+}
+
+// WriteString is Write for a string fragment.
+func (a *Assembler) WriteString(s string) (int, error) {
+	start := len(a.buf)
+	a.buf = append(a.buf, s...)
+	a.scan(start)
+	return len(s), a.err
+}
+
+// String returns the text written so far.
+func (a *Assembler) String() string {
+	return string(a.buf)
+}
+
+// Len returns the number of bytes written so far.
+func (a *Assembler) Len() int {
+	return len(a.buf)
+}
+
+// Err returns the error that made the text invalid JSON, if any.
+func (a *Assembler) Err() error {
+	return a.err
+}
+
+// Complete reports whether the text holds a whole JSON value.
+func (a *Assembler) Complete() bool {
+	if a.err != nil {
+		return false
+	}
+	return a.done || (len(a.stack) == 0 && a.token == tokenNumber && a.validNumber(len(a.buf)))
+}
+
+// Partial returns the longest prefix of the text closed into valid JSON: an
+// unterminated string is closed, open objects and arrays are closed, and a
+// trailing incomplete key, literal, or separator is dropped. It returns ""
+// when no prefix is valid yet. After an error it returns the longest valid
+// prefix before the error.
+func (a *Assembler) Partial() string {
+	if a.err == nil {
+		switch {
+		case a.token == tokenString && !a.key:
+			text := a.buf
+			if a.escape != 0 {
+				text = a.buf[:a.escapeAt]
+			}
+			return string(trimPartialRune(text)) + `"` + a.closers(len(a.stack))
+		case a.token == tokenNumber && a.validNumber(len(a.buf)):
+			return string(a.buf) + a.closers(len(a.stack))
+		}
+	}
+	if a.checkpoint == 0 {
+		return ""
+	}
+	return string(a.buf[:a.checkpoint]) + a.closers(a.checkpointDepth)
+}
+
+// Decode unmarshals Partial into v.
+func (a *Assembler) Decode(v any) error {
+	partial := a.Partial()
+	if partial == "" {
+		if a.err != nil {
+			return a.err
+		}
+		return ErrIncomplete
+	}
+	return json.Unmarshal([]byte(partial), v)
+}
+
+// Reset discards the text so the Assembler can be reused for another value.
+func (a *Assembler) Reset() {
+	*a = Assembler{buf: a.buf[:0], stack: a.stack[:0]}
+}
+
+func (a *Assembler) closers(depth int) string {
+	if depth == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.Grow(depth)
+	for i := depth - 1; i >= 0; i-- {
+		builder.WriteByte(a.stack[i].closer())
+	}
+	return builder.String()
+}
+
+func (a *Assembler) scan(start int) {
+	for i := start; i < len(a.buf) && a.err == nil; i++ {
+		a.scanByte(i)
+	}
+}
+
+func (a *Assembler) scanByte(i int) {
+	c := a.buf[i]
+	switch a.token {
+	case tokenString:
+		a.scanString(i, c)
+		return
+	case tokenNumber:
+		if isNumberByte(c) {
+			return
+		}
+		a.token = tokenNone
+		if !a.validNumber(i) {
+			a.fail(i)
+			return
+		}
+		a.endValue(i)
+	case tokenLiteral:
+		n := i - a.tokenStart
+		if c != a.literal[n] {
+			a.fail(i)
+			return
+		}
+		if n+1 == len(a.literal) {
+			a.token = tokenNone
+			a.endValue(i + 1)
+		}
+		return
+	}
+
+	if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+		return
+	}
+	if a.done {
+		a.fail(i)
+		return
+	}
+	if len(a.stack) == 0 {
+		a.beginValue(i, c)
+		return
+	}
+
+	top := &a.stack[len(a.stack)-1]
+	switch *top {
+	case objectKeyOrEnd, objectKey:
+		switch {
+		case c == '"':
+			a.token, a.key, a.tokenStart = tokenString, true, i
+		case c == '}' && *top == objectKeyOrEnd:
+			a.closeContainer(i)
+		default:
+			a.fail(i)
+		}
+	case objectColon:
+		if c != ':' {
+			a.fail(i)
+			return
+		}
+		*top = objectValue
+	case objectCommaOrEnd:
+		switch c {
+		case ',':
+			*top = objectKey
+		case '}':
+			a.closeContainer(i)
+		default:
+			a.fail(i)
+		}
+	case arrayCommaOrEnd:
+		switch c {
+		case ',':
+			*top = arrayValue
+		case ']':
+			a.closeContainer(i)
+		default:
+			a.fail(i)
+		}
+	case arrayValueOrEnd:
+		if c == ']' {
+			a.closeContainer(i)
+			return
+		}
+		a.beginValue(i, c)
+	default:
+		a.beginValue(i, c)
+	}
+}
+
+func (a *Assembler) beginValue(i int, c byte) {
+	switch {
+	case c == '{':
+		a.stack = append(a.stack, objectKeyOrEnd)
+		a.setCheckpoint(i + 1)
+	case c == '[':
+		a.stack = append(a.stack, arrayValueOrEnd)
+		a.setCheckpoint(i + 1)
+	case c == '"':
+		a.token, a.key, a.tokenStart = tokenString, false, i
+	case c == '-' || (c >= '0' && c <= '9'):
+		a.token, a.tokenStart = tokenNumber, i
+	case c == 't':
+		a.token, a.tokenStart, a.literal = tokenLiteral, i, "true"
+	case c == 'f':
+		a.token, a.tokenStart, a.literal = tokenLiteral, i, "false"
+	case c == 'n':
+		a.token, a.tokenStart, a.literal = tokenLiteral, i, "null"
+	default:
+		a.fail(i)
+	}
+}
+
+func (a *Assembler) scanString(i int, c byte) {
+	switch {
+	case a.escape == 1:
+		switch c {
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			a.escape = 0
+		case 'u':
+			a.escape = 2
+		default:
+			a.fail(i)
+		}
+	case a.escape > 1:
+		if !isHex(c) {
+			a.fail(i)
+			return
+		}
+		if a.escape++; a.escape == 6 {
+			a.escape = 0
+		}
+	case c == '\\':
+		a.escape, a.escapeAt = 1, i
+	case c == '"':
+		a.token = tokenNone
+		if a.key {
+			a.stack[len(a.stack)-1] = objectColon
+			return
+		}
+		a.endValue(i + 1)
+	case c < 0x20:
+		a.fail(i)
+	}
+}
+
+func (a *Assembler) closeContainer(i int) {
+	a.stack = a.stack[:len(a.stack)-1]
+	a.endValue(i + 1)
+}
+
+// endValue records a value ending at offset end.
+func (a *Assembler) endValue(end int) {
+	if len(a.stack) == 0 {
+		a.done = true
+	} else {
+		top := &a.stack[len(a.stack)-1]
+		if *top == objectValue {
+			*top = objectCommaOrEnd
+		} else {
+			*top = arrayCommaOrEnd
+		}
+	}
+	a.setCheckpoint(end)
+}
+
+func (a *Assembler) setCheckpoint(end int) {
+	a.checkpoint, a.checkpointDepth = end, len(a.stack)
+}
+
+func (a *Assembler) validNumber(end int) bool {
+	return json.Valid(a.buf[a.tokenStart:end])
+}
+
+func (a *Assembler) fail(i int) {
+	a.err = fmt.Errorf("partialjson: invalid character %q at offset %d", a.buf[i], i)
+}
+
+// trimPartialRune drops a UTF-8 sequence cut off at the end of b.
+func trimPartialRune(b []byte) []byte {
+	start := len(b) - 1
+	for start > 0 && len(b)-start < utf8.UTFMax && !utf8.RuneStart(b[start]) {
+		start--
+	}
+	if start >= 0 && !utf8.FullRune(b[start:]) {
+		return b[:start]
+	}
+	return b
+}
+
+func isNumberByte(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
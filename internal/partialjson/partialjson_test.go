@@ -0,0 +1,154 @@
+package partialjson
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPartial(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{``, ``},
+		{`{`, `{}`},
+		{`{"name": "Go`, `{"name": "Go"}`},
+		{`{"name": "Go", "tags": ["fast", "sim`, `{"name": "Go", "tags": ["fast", "sim"]}`},
+		{`{"name": "Go", `, `{"name": "Go"}`},
+		{`{"name": "Go", "year`, `{"name": "Go"}`},
+		{`{"name": "Go", "year": `, `{"name": "Go"}`},
+		{`{"name": "Go", "year": 20`, `{"name": "Go", "year": 20}`},
+		{`{"year": 2.`, `{}`},
+		{`{"ok": tr`, `{}`},
+		{`{"ok": true`, `{"ok": true}`},
+		{`{"path": "a\`, `{"path": "a"}`},
+		{`{"path": "a\u00`, `{"path": "a"}`},
+		{`{"path": "aé`, `{"path": "aé"}`},
+		{`[{"a": 1}, {"b": [1, 2`, `[{"a": 1}, {"b": [1, 2]}]`},
+		{`[[], {}, [`, `[[], {}, []]`},
+		{`"unterminated`, `"unterminated"`},
+		{`-`, ``},
+		{`{"a": 1}`, `{"a": 1}`},
+		{`{"a": 1} trailing`, `{"a": 1}`},
+		{`{"a": 1, oops`, `{"a": 1}`},
+		{`nope`, ``},
+	}
+	for _, tt := range tests {
+		var assembler Assembler
+		assembler.WriteString(tt.text)
+		if got := assembler.Partial(); got != tt.want {
+			t.Errorf("Partial(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPartialIsValidForEveryPrefix(t *testing.T) {
+	t.Parallel()
+
+	documents := []string{
+		`{"name":"get_weather","arguments":{"city":"Zürich","days":[1,2,3],"metric":true,"note":null}}`,
+		`[{"q": "a\"b\\c\nd", "n": -1.5e-3}, [], {}, "日本語", false]`,
+		`  {"nested": {"deep": [[[{"x": "y"}]]]}, "empty": ""}  `,
+		`12345`,
+	}
+
+	for _, document := range documents {
+		var assembler Assembler
+		for i := 0; i < len(document); i++ {
+			if _, err := assembler.Write([]byte{document[i]}); err != nil {
+				t.Fatalf("Write(%q) error = %v", document[:i+1], err)
+			}
+			partial := assembler.Partial()
+			if partial != "" && !json.Valid([]byte(partial)) {
+				t.Fatalf("Partial after %q = %q, which is not valid JSON", document[:i+1], partial)
+			}
+		}
+		if !assembler.Complete() {
+			t.Fatalf("Complete() = false for %q", document)
+		}
+		if got, want := assembler.Partial(), strings.TrimSpace(document); strings.TrimSpace(got) != want {
+			t.Fatalf("Partial() = %q, want %q", got, want)
+		}
+		if assembler.String() != document || assembler.Len() != len(document) {
+			t.Fatalf("String() = %q", assembler.String())
+		}
+	}
+}
+
+func TestPartialDropsCutOffRune(t *testing.T) {
+	t.Parallel()
+
+	var assembler Assembler
+	assembler.WriteString(`{"city": "Z`)
+	assembler.Write([]byte{0xc3})
+	if got := assembler.Partial(); got != `{"city": "Z"}` {
+		t.Fatalf("Partial() = %q", got)
+	}
+	assembler.Write([]byte{0xbc})
+	if got := assembler.Partial(); got != `{"city": "Zü"}` {
+		t.Fatalf("Partial() = %q", got)
+	}
+}
+
+func TestInvalidTextReportsError(t *testing.T) {
+	t.Parallel()
+
+	var assembler Assembler
+	if _, err := assembler.WriteString(`{"a": [1, 2}`); err == nil {
+		t.Fatal("WriteString() error = nil, want an error for the mismatched bracket")
+	}
+	if _, err := assembler.WriteString(`]`); err == nil || err != assembler.Err() {
+		t.Fatalf("later WriteString() error = %v, want the first error", err)
+	}
+	if assembler.Complete() {
+		t.Fatal("Complete() = true after an error")
+	}
+	if got := assembler.Partial(); got != `{"a": [1, 2]}` {
+		t.Fatalf("Partial() = %q", got)
+	}
+}
+
+func TestDecodeAndReset(t *testing.T) {
+	t.Parallel()
+
+	var assembler Assembler
+	var out map[string]any
+	if err := assembler.Decode(&out); !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("Decode() error = %v, want ErrIncomplete", err)
+	}
+
+	assembler.WriteString(`{"city": "Paris", "days": [1, 2`)
+	if err := assembler.Decode(&out); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if out["city"] != "Paris" || len(out["days"].([]any)) != 2 {
+		t.Fatalf("Decode() = %v", out)
+	}
+
+	assembler.Reset()
+	if assembler.Len() != 0 || assembler.Partial() != "" || assembler.Complete() {
+		t.Fatalf("after Reset: %q", assembler.String())
+	}
+	assembler.WriteString(`[true]`)
+	if !assembler.Complete() || assembler.Partial() != `[true]` {
+		t.Fatalf("after reuse: Partial() = %q", assembler.Partial())
+	}
+}
+
+// BenchmarkStreamedArguments feeds a large argument object in small
+// fragments and takes the partial value after each one, as a stream does.
+func BenchmarkStreamedArguments(b *testing.B) {
+	document := `{"items": [` + strings.Repeat(`{"id": 12345, "name": "widget", "tags": ["a", "b"]}, `, 200) + `{}]}`
+	b.ReportAllocs()
+	for b.Loop() {
+		var assembler Assembler
+		for i := 0; i < len(document); i += 16 {
+			assembler.WriteString(document[i:min(i+16, len(document))])
+			_ = assembler.Partial()
+		}
+	}
+}
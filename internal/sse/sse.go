@@ -0,0 +1,159 @@
+// Package sse reads server-sent event streams, as returned by the OpenAI and
+// Claude streaming APIs.
+//
+// Reader follows the event stream format of the HTML specification: data
+// lines are joined with newlines, "event" and "id" fields are tracked,
+// comment lines are skipped, and lines may end with LF or CRLF. Events are
+// returned as slices of reused buffers, so reading a stream does not allocate
+// per event once the buffers have grown to the largest event.
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+)
+
+// MaxEventSize limits the size of a single line and of the data of a single
+// event.
+const MaxEventSize = 4 << 20
+
+const readerBufferSize = 64 << 10
+
+// ErrEventTooLarge is returned by Reader.Next when a line or the data of an
+// event exceeds MaxEventSize.
+var ErrEventTooLarge = errors.New("sse: event exceeds maximum size")
+
+// Event is one dispatched server-sent event. Its slices point into buffers
+// owned by the Reader and are only valid until the next call to Next.
+type Event struct {
+	// Type is the "event" field, empty for the default "message" type.
+	Type []byte
+	// Data holds the "data" lines of the event joined with "\n".
+	Data []byte
+	// ID is the last "id" field seen on the stream.
+	ID []byte
+}
+
+// Reader reads events from a server-sent event stream.
+type Reader struct {
+	r *bufio.Reader
+
+	long      []byte
+	eventType []byte
+	data      []byte
+	id        []byte
+}
+
+// maxPooledEventSize keeps the buffers of a Reader that grew for one large
+// event out of the pool.
+const maxPooledEventSize = 1 << 20
+
+var readers = sync.Pool{New: func() any {
+	return &Reader{r: bufio.NewReaderSize(nil, readerBufferSize)}
+}}
+
+// NewReader returns a Reader that reads events from r. Its buffers come from
+// a pool; call Release when done with the stream to return them.
+func NewReader(r io.Reader) *Reader {
+	reader := readers.Get().(*Reader)
+	reader.r.Reset(r)
+	return reader
+}
+
+// Release returns the Reader's buffers to the pool. Neither the Reader nor
+// the events it returned may be used afterwards.
+func (r *Reader) Release() {
+	r.r.Reset(nil)
+	if cap(r.long) > maxPooledEventSize || cap(r.data) > maxPooledEventSize {
+		return
+	}
+	r.long, r.eventType, r.data, r.id = r.long[:0], r.eventType[:0], r.data[:0], r.id[:0]
+	readers.Put(r)
+}
+
+// Next returns the next event. It returns io.EOF once the stream ends; an
+// event whose data was not terminated by a blank line before the end of the
+// stream is still returned.
+func (r *Reader) Next() (Event, error) {
+	r.eventType = r.eventType[:0]
+	r.data = r.data[:0]
+	hasData := false
+
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			if err == io.EOF && hasData {
+				return r.event(), nil
+			}
+			return Event{}, err
+		}
+
+		if len(line) == 0 {
+			if hasData {
+				return r.event(), nil
+			}
+			r.eventType = r.eventType[:0]
+			continue
+		}
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value := line, []byte(nil)
+		if colon := bytes.IndexByte(line, ':'); colon >= 0 {
+			field, value = line[:colon], line[colon+1:]
+			if len(value) > 0 && value[0] == ' ' {
+				value = value[1:]
+			}
+		}
+
+		switch string(field) {
+		case "data":
+			if hasData {
+				r.data = append(r.data, '\n')
+			}
+			r.data = append(r.data, value...)
+			hasData = true
+			if len(r.data) > MaxEventSize {
+				return Event{}, ErrEventTooLarge
+			}
+		case "event":
+			r.eventType = append(r.eventType[:0], value...)
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				r.id = append(r.id[:0], value...)
+			}
+		}
+	}
+}
+
+func (r *Reader) event() Event {
+	return Event{Type: r.eventType, Data: r.data, ID: r.id}
+}
+
+// readLine returns the next line without its line ending. The line is only
+// valid until the next read.
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		r.long = append(r.long[:0], line...)
+		for err == bufio.ErrBufferFull {
+			line, err = r.r.ReadSlice('\n')
+			r.long = append(r.long, line...)
+			if len(r.long) > MaxEventSize {
+				return nil, ErrEventTooLarge
+			}
+		}
+		line = r.long
+	}
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return nil, err
+	}
+
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	return line, nil
+}
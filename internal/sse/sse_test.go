@@ -0,0 +1,210 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type collected struct {
+	Type string
+	Data string
+	ID   string
+}
+
+func readAll(t *testing.T, input string) []collected {
+	t.Helper()
+
+	reader := NewReader(strings.NewReader(input))
+	defer reader.Release()
+	var events []collected
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		events = append(events, collected{Type: string(event.Type), Data: string(event.Data), ID: string(event.ID)})
+	}
+}
+
+func TestReaderParsesFields(t *testing.T) {
+	t.Parallel()
+
+	input := ": keep-alive\n" +
+		"event: message_start\n" +
+		"id: 1\n" +
+		"data: {\"a\":1}\n" +
+		"\n" +
+		"data: first\n" +
+		"data:second\n" +
+		"data\n" +
+		"\n" +
+		"event: ignored\n" +
+		"\n" +
+		"event: ping\r\n" +
+		"data:  padded\r\n" +
+		"\r\n"
+
+	events := readAll(t, input)
+	expected := []collected{
+		{Type: "message_start", Data: `{"a":1}`, ID: "1"},
+		{Data: "first\nsecond\n", ID: "1"},
+		{Type: "ping", Data: " padded", ID: "1"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %#v", len(expected), events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Fatalf("event %d: expected %#v, got %#v", i, expected[i], events[i])
+		}
+	}
+}
+
+func TestReaderReturnsUnterminatedEvent(t *testing.T) {
+	t.Parallel()
+
+	events := readAll(t, "data: [DONE]")
+	if len(events) != 1 || events[0].Data != "[DONE]" {
+		t.Fatalf("unexpected events %#v", events)
+	}
+}
+
+func TestReaderHandlesLinesLongerThanBuffer(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("x", readerBufferSize*3)
+	events := readAll(t, "data: "+long+"\n\ndata: short\n\n")
+	if len(events) != 2 || events[0].Data != long || events[1].Data != "short" {
+		t.Fatalf("unexpected events of sizes %d", len(events))
+	}
+}
+
+func TestReleasedReaderStartsClean(t *testing.T) {
+	t.Parallel()
+
+	reader := NewReader(strings.NewReader("id: 7\nevent: first\ndata: partial"))
+	if _, err := reader.Next(); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	reader.Release()
+
+	reader = NewReader(strings.NewReader("data: second\n\n"))
+	defer reader.Release()
+	event, err := reader.Next()
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if len(event.Type) != 0 || len(event.ID) != 0 || string(event.Data) != "second" {
+		t.Fatalf("event = %q %q %q, want only the data of the new stream", event.Type, event.ID, event.Data)
+	}
+}
+
+func TestReaderRejectsOversizedEvents(t *testing.T) {
+	t.Parallel()
+
+	reader := NewReader(strings.NewReader("data: " + strings.Repeat("x", MaxEventSize+1) + "\n\n"))
+	if _, err := reader.Next(); !errors.Is(err, ErrEventTooLarge) {
+		t.Fatalf("expected ErrEventTooLarge, got %v", err)
+	}
+}
+
+const benchmarkChunk = `{"id":"chatcmpl-1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Hello there"},"finish_reason":null}]}`
+
+func benchmarkStream(events int) []byte {
+	var buf bytes.Buffer
+	for range events {
+		buf.WriteString("data: ")
+		buf.WriteString(benchmarkChunk)
+		buf.WriteString("\n\n")
+	}
+	buf.WriteString("data: [DONE]\n\n")
+	return buf.Bytes()
+}
+
+type benchmarkEvent struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+// BenchmarkReader measures reading and decoding a chat completion stream
+// with Reader.
+func BenchmarkReader(b *testing.B) {
+	stream := benchmarkStream(1000)
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		reader := NewReader(bytes.NewReader(stream))
+		for {
+			event, err := reader.Next()
+			if err != nil {
+				break
+			}
+			if bytes.Equal(event.Data, []byte("[DONE]")) {
+				continue
+			}
+			var decoded benchmarkEvent
+			if err := json.Unmarshal(event.Data, &decoded); err != nil {
+				b.Fatal(err)
+			}
+		}
+		reader.Release()
+	}
+}
+
+// BenchmarkScannerLoop measures the bufio.Scanner loop that Reader replaced,
+// which converted every line to a string and back.
+func BenchmarkScannerLoop(b *testing.B) {
+	stream := benchmarkStream(1000)
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		scanner := bufio.NewScanner(bytes.NewReader(stream))
+		scanner.Buffer(make([]byte, 0, 64*1024), MaxEventSize)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, ":") || !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if payload == "[DONE]" {
+				continue
+			}
+			var decoded benchmarkEvent
+			if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkReaderOnly measures parsing alone, which allocates nothing per
+// event once the buffers have grown, and nothing per stream once released
+// readers are reused.
+func BenchmarkReaderOnly(b *testing.B) {
+	stream := benchmarkStream(1000)
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		reader := NewReader(bytes.NewReader(stream))
+		for {
+			if _, err := reader.Next(); err != nil {
+				break
+			}
+		}
+		reader.Release()
+	}
+}
@@ -0,0 +1,44 @@
+// Package streamtext accumulates text that a provider streams either as
+// deltas or as snapshots of everything generated so far.
+package streamtext
+
+import "strings"
+
+// Builder accumulates streamed text. Its zero value is empty and ready to
+// use. Appending grows the text in place, so a stream of n deltas costs
+// O(n) rather than copying the text on every delta.
+type Builder struct {
+	text strings.Builder
+}
+
+// Append adds incoming to the text and returns the part that is new.
+// Incoming text that extends the text so far is a snapshot and only its
+// suffix is added; text that repeats a prefix of it adds nothing; anything
+// else is a delta and is appended as is.
+func (b *Builder) Append(incoming string) (delta string) {
+	if incoming == "" {
+		return ""
+	}
+
+	current := b.text.String()
+	if strings.HasPrefix(incoming, current) {
+		delta = incoming[len(current):]
+	} else if strings.HasPrefix(current, incoming) {
+		return ""
+	} else {
+		delta = incoming
+	}
+	b.text.WriteString(delta)
+	return delta
+}
+
+// String returns the text so far. It does not copy, so calling it after
+// every Append is cheap.
+func (b *Builder) String() string {
+	return b.text.String()
+}
+
+// Len returns the length of the text in bytes.
+func (b *Builder) Len() int {
+	return b.text.Len()
+}
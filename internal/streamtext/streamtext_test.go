@@ -0,0 +1,49 @@
+package streamtext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAppendHandlesCumulativeSnapshots(t *testing.T) {
+	t.Parallel()
+
+	var text Builder
+	for _, step := range []struct{ incoming, delta, want string }{
+		{"The", "The", "The"},
+		{"The user", " user", "The user"},
+		{"The user asks", " asks", "The user asks"},
+		{"The user", "", "The user asks"},
+	} {
+		if delta := text.Append(step.incoming); delta != step.delta || text.String() != step.want {
+			t.Fatalf("Append(%q) = %q, text %q; want %q, text %q", step.incoming, delta, text.String(), step.delta, step.want)
+		}
+	}
+}
+
+func TestAppendHandlesDeltaUpdates(t *testing.T) {
+	t.Parallel()
+
+	var text Builder
+	text.Append("The user")
+	if delta := text.Append(" asks"); delta != " asks" || text.String() != "The user asks" {
+		t.Fatalf("Append() = %q, text %q", delta, text.String())
+	}
+	if delta := text.Append(""); delta != "" || text.Len() != len("The user asks") {
+		t.Fatalf("Append(\"\") = %q, length %d", delta, text.Len())
+	}
+}
+
+// BenchmarkAppendDeltas appends the deltas of a long response and reads the
+// text after each one, as the stream loops do.
+func BenchmarkAppendDeltas(b *testing.B) {
+	deltas := strings.SplitAfter(strings.Repeat("streamed reasoning tokens arrive one small delta at a time ", 250), " ")
+	b.ReportAllocs()
+	for b.Loop() {
+		var text Builder
+		for _, delta := range deltas {
+			text.Append(delta)
+			_ = text.String()
+		}
+	}
+}
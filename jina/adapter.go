@@ -0,0 +1,259 @@
+// Package jina is an adapter for the Jina AI Search Foundation API, which
+// serves embedding and reranker models.
+//
+// The adapter implements core.EmbeddingAdapter with the task and late
+// chunking settings of the jina-embeddings models, and Rerank orders
+// documents by their relevance to a query with a jina-reranker model. Jina
+// serves no chat models, so the adapter is not a core.TextAdapter and is not
+// registered with core.
+package jina
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+const (
+	providerName       = "jina"
+	defaultBaseURL     = "https://api.jina.ai/v1"
+	defaultHTTPTimeout = 5 * time.Minute
+	envJinaAPIKey      = "JINA_API_KEY"
+)
+
+// Embedding tasks of the jina-embeddings-v3 and later models. Each task
+// selects the LoRA adapter the model embeds with.
+const (
+	TaskRetrievalQuery   = "retrieval.query"
+	TaskRetrievalPassage = "retrieval.passage"
+	TaskTextMatching     = "text-matching"
+	TaskClassification   = "classification"
+	TaskSeparation       = "separation"
+	TaskCodeQuery        = "code.query"
+	TaskCodePassage      = "code.passage"
+)
+
+type Adapter struct {
+	APIKey  string
+	Model   string
+	BaseURL string
+
+	// Task is the embedding task sent with each request, such as
+	// TaskRetrievalPassage. Empty leaves it to the model.
+	Task string
+
+	// LateChunking embeds the inputs of one request as chunks of a single
+	// document: the model reads them together and then pools each chunk, so
+	// every vector carries the context of the whole document.
+	LateChunking bool
+
+	HTTPClient *http.Client
+}
+
+var _ core.EmbeddingAdapter = (*Adapter)(nil)
+
+type Option func(*Adapter)
+
+// New creates a Jina AI adapter for an embedding model, such as
+// "jina-embeddings-v3", or a reranker model, such as
+// "jina-reranker-v2-base-multilingual".
+//
+// If no API key is provided via options, New reads JINA_API_KEY.
+func New(model string, opts ...Option) *Adapter {
+	adapter := &Adapter{
+		APIKey:     strings.TrimSpace(os.Getenv(envJinaAPIKey)),
+		Model:      strings.TrimSpace(model),
+		BaseURL:    defaultBaseURL,
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(adapter)
+	}
+
+	return adapter
+}
+
+// WithAPIKey sets the API key used by the adapter.
+func WithAPIKey(apiKey string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(apiKey) == "" {
+			return
+		}
+		adapter.APIKey = strings.TrimSpace(apiKey)
+	}
+}
+
+// WithBaseURL sets the API base URL used by the adapter.
+func WithBaseURL(baseURL string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(baseURL) == "" {
+			return
+		}
+		adapter.BaseURL = strings.TrimSpace(baseURL)
+	}
+}
+
+// WithTask sets the embedding task, such as TaskRetrievalQuery for search
+// queries and TaskRetrievalPassage for the documents they search.
+func WithTask(task string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(task) == "" {
+			return
+		}
+		adapter.Task = strings.TrimSpace(task)
+	}
+}
+
+// WithLateChunking embeds the inputs of each EmbedMany call as chunks of one
+// document.
+func WithLateChunking() Option {
+	return func(adapter *Adapter) {
+		adapter.LateChunking = true
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the adapter.
+func WithHTTPClient(client *http.Client) Option {
+	return func(adapter *Adapter) {
+		if client == nil {
+			return
+		}
+		adapter.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the timeout on the adapter HTTP client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(adapter *Adapter) {
+		if timeout <= 0 {
+			return
+		}
+		if adapter.HTTPClient == nil {
+			adapter.HTTPClient = &http.Client{}
+		}
+		adapter.HTTPClient.Timeout = timeout
+	}
+}
+
+func (a *Adapter) validate() error {
+	if a == nil {
+		return errors.New("jina: adapter is nil")
+	}
+
+	if strings.TrimSpace(a.APIKey) == "" {
+		a.APIKey = strings.TrimSpace(os.Getenv(envJinaAPIKey))
+	}
+	if strings.TrimSpace(a.APIKey) == "" {
+		return errors.New("jina: API key is required (set JINA_API_KEY or use jina.WithAPIKey)")
+	}
+
+	if strings.TrimSpace(a.Model) == "" {
+		return errors.New("jina: model is required")
+	}
+
+	return nil
+}
+
+func (a *Adapter) client() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// transport returns the client that sends API requests.
+func (a *Adapter) transport() *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   providerName,
+		Header: func(header http.Header) {
+			header.Set("Authorization", "Bearer "+a.APIKey)
+			header.Set("Accept", "application/json")
+		},
+		DecodeError: decodeError,
+	}
+}
+
+func (a *Adapter) baseURL() string {
+	if strings.TrimSpace(a.BaseURL) == "" {
+		return defaultBaseURL
+	}
+	return a.BaseURL
+}
+
+// decodeError reads the {"detail": ...} envelope of the Jina API, whose
+// detail is a message or a list of validation errors. Status 429 becomes a
+// core.RateLimitError.
+func decodeError(resp *http.Response) error {
+	requestID := httpclient.RequestID(resp.Header)
+
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
+	var message string
+	if readErr != nil {
+		message = fmt.Sprintf("jina: API status %d and failed to read error body: %v", resp.StatusCode, readErr)
+	} else if detail := errorDetail(body); detail != "" {
+		message = "jina: API error: " + detail
+	} else {
+		text := strings.TrimSpace(string(body))
+		if text == "" {
+			text = http.StatusText(resp.StatusCode)
+		}
+		message = fmt.Sprintf("jina: API status %d: %s", resp.StatusCode, text)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &core.RateLimitError{Message: message, RetryAfter: openaicompat.RetryAfter(resp.Header), RequestID: requestID}
+	}
+	return &core.APIError{
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		RequestID:  requestID,
+		Retryable:  resp.StatusCode >= http.StatusInternalServerError,
+		RetryAfter: openaicompat.RetryAfter(resp.Header),
+	}
+}
+
+func errorDetail(body []byte) string {
+	var envelope struct {
+		Detail json.RawMessage `json:"detail"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || len(envelope.Detail) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(envelope.Detail, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+
+	var items []struct {
+		Loc []any  `json:"loc"`
+		Msg string `json:"msg"`
+	}
+	if err := json.Unmarshal(envelope.Detail, &items); err != nil {
+		return ""
+	}
+	messages := make([]string, 0, len(items))
+	for _, item := range items {
+		if len(item.Loc) > 0 {
+			messages = append(messages, fmt.Sprintf("%v: %s", item.Loc[len(item.Loc)-1], strings.TrimSpace(item.Msg)))
+			continue
+		}
+		messages = append(messages, strings.TrimSpace(item.Msg))
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,149 @@
+package jina
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+type embedRequest struct {
+	Model         string   `json:"model"`
+	Input         []string `json:"input"`
+	Task          string   `json:"task,omitempty"`
+	Dimensions    *int64   `json:"dimensions,omitempty"`
+	LateChunking  bool     `json:"late_chunking,omitempty"`
+	Truncate      *bool    `json:"truncate,omitempty"`
+	EmbeddingType string   `json:"embedding_type"`
+}
+
+type embedResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Usage *struct {
+		PromptTokens int64 `json:"prompt_tokens"`
+		TotalTokens  int64 `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// Embed creates one embedding vector for params.Input.
+//
+// ModelOptions may set "task", overriding Adapter.Task for the call, and
+// "truncate" to cut inputs that exceed the context of the model instead of
+// failing.
+func (a *Adapter) Embed(ctx context.Context, params *core.EmbedParams) (*core.EmbedResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("jina: embed params are required")
+	}
+
+	input := strings.TrimSpace(params.Input)
+	if input == "" {
+		return nil, errors.New("jina: embed input is required")
+	}
+
+	vectors, usage, err := a.embed(ctx, []string{input}, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.EmbedResult{Embedding: vectors[0], Usage: usage}, nil
+}
+
+// EmbedMany creates embedding vectors for params.Inputs in one request.
+//
+// With late chunking the inputs are the consecutive chunks of one document,
+// which must fit the context of the model together. ModelOptions may set
+// "task", "late_chunking", and "truncate", overriding the adapter settings
+// for the call.
+func (a *Adapter) EmbedMany(ctx context.Context, params *core.EmbedManyParams) (*core.EmbedManyResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("jina: embed many params are required")
+	}
+	if len(params.Inputs) == 0 {
+		return nil, errors.New("jina: embed many inputs are required")
+	}
+
+	inputs := make([]string, 0, len(params.Inputs))
+	for i, input := range params.Inputs {
+		trimmed := strings.TrimSpace(input)
+		if trimmed == "" {
+			return nil, fmt.Errorf("jina: embed many input at index %d is empty", i)
+		}
+		inputs = append(inputs, trimmed)
+	}
+
+	vectors, usage, err := a.embed(ctx, inputs, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.EmbedManyResult{Embeddings: vectors, Usage: usage}, nil
+}
+
+func (a *Adapter) embed(ctx context.Context, inputs []string, dimensions *int64, options map[string]any) ([][]float64, *core.Usage, error) {
+	if dimensions != nil && *dimensions <= 0 {
+		return nil, nil, errors.New("jina: embed dimensions must be greater than zero")
+	}
+
+	request := embedRequest{
+		Model:         a.Model,
+		Input:         inputs,
+		Task:          a.Task,
+		Dimensions:    dimensions,
+		LateChunking:  a.LateChunking,
+		EmbeddingType: "float",
+	}
+	if value, ok := options["task"].(string); ok && strings.TrimSpace(value) != "" {
+		request.Task = strings.TrimSpace(value)
+	}
+	if value, ok := options["late_chunking"].(bool); ok {
+		request.LateChunking = value
+	}
+	if value, ok := options["truncate"].(bool); ok {
+		request.Truncate = &value
+	}
+
+	var response embedResponse
+	if _, err := a.transport().Send(ctx, httpclient.Request{Path: "/embeddings", Name: "embeddings", Body: &request}, &response); err != nil {
+		return nil, nil, err
+	}
+	if len(response.Data) != len(inputs) {
+		return nil, nil, fmt.Errorf("jina: embeddings response count mismatch: expected %d, got %d", len(inputs), len(response.Data))
+	}
+
+	vectors := make([][]float64, len(inputs))
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= len(vectors) {
+			return nil, nil, fmt.Errorf("jina: embeddings response index %d out of range", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, nil, fmt.Errorf("jina: embeddings response did not include a vector at index %d", i)
+		}
+		if dimensions != nil && int64(len(vector)) != *dimensions {
+			return nil, nil, &core.EmbeddingDimensionError{Expected: *dimensions, Actual: int64(len(vector)), Index: i}
+		}
+	}
+
+	var usage *core.Usage
+	if response.Usage != nil {
+		usage = &core.Usage{PromptTokens: response.Usage.PromptTokens, TotalTokens: response.Usage.TotalTokens}
+		if usage.PromptTokens == 0 {
+			usage.PromptTokens = usage.TotalTokens
+		}
+	}
+	return vectors, usage, nil
+}
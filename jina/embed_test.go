@@ -0,0 +1,120 @@
+package jina
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestEmbedManySendsTaskAndLateChunking(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" || r.Header.Get("Authorization") != "Bearer jina-key" {
+			t.Errorf("unexpected request %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"model":"jina-embeddings-v3","object":"list","usage":{"total_tokens":12,"prompt_tokens":12},"data":[{"object":"embedding","index":1,"embedding":[0.3,0.4]},{"object":"embedding","index":0,"embedding":[0.1,0.2]}]}`)
+	}))
+	defer server.Close()
+
+	dimensions := int64(2)
+	adapter := New("jina-embeddings-v3", WithAPIKey("jina-key"), WithBaseURL(server.URL), WithTask(TaskRetrievalPassage), WithLateChunking())
+	result, err := adapter.EmbedMany(context.Background(), &core.EmbedManyParams{
+		Inputs:     []string{"Berlin is the capital of Germany.", "Its population is 3.85 million."},
+		Dimensions: &dimensions,
+	})
+	if err != nil {
+		t.Fatalf("EmbedMany() error = %v", err)
+	}
+
+	if body["model"] != "jina-embeddings-v3" || body["task"] != TaskRetrievalPassage || body["late_chunking"] != true || body["dimensions"] != float64(2) || body["embedding_type"] != "float" {
+		t.Fatalf("unexpected request body %v", body)
+	}
+	if _, ok := body["truncate"]; ok {
+		t.Fatalf("unexpected truncate in %v", body)
+	}
+	if result.Embeddings[0][0] != 0.1 || result.Embeddings[1][0] != 0.3 || result.Usage.PromptTokens != 12 || result.Usage.TotalTokens != 12 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestEmbedModelOptionsOverrideAdapterSettings(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"usage":{"total_tokens":4},"data":[{"index":0,"embedding":[0.5,0.5]}]}`)
+	}))
+	defer server.Close()
+
+	adapter := New("jina-embeddings-v3", WithAPIKey("jina-key"), WithBaseURL(server.URL), WithTask(TaskRetrievalPassage), WithLateChunking())
+	result, err := adapter.Embed(context.Background(), &core.EmbedParams{
+		Input:        "capital of Germany",
+		ModelOptions: map[string]any{"task": TaskRetrievalQuery, "late_chunking": false, "truncate": true},
+	})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	if body["task"] != TaskRetrievalQuery || body["truncate"] != true {
+		t.Fatalf("unexpected request body %v", body)
+	}
+	if _, ok := body["late_chunking"]; ok {
+		t.Fatalf("unexpected late_chunking in %v", body)
+	}
+	if len(result.Embedding) != 2 || result.Usage.PromptTokens != 4 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestEmbedDecodesErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["model"] == "jina-embeddings-v3" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"detail":"Rate limit exceeded"}`)
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"detail":[{"loc":["body","task"],"msg":"Input should be 'retrieval.query' or 'retrieval.passage'","type":"literal_error"}]}`)
+	}))
+	defer server.Close()
+
+	_, err := New("jina-embeddings-v3", WithAPIKey("jina-key"), WithBaseURL(server.URL)).Embed(context.Background(), &core.EmbedParams{Input: "Hi"})
+	var rateLimit *core.RateLimitError
+	if !errors.As(err, &rateLimit) || !strings.Contains(err.Error(), "jina: API error: Rate limit exceeded") {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	_, err = New("jina-clip-v2", WithAPIKey("jina-key"), WithBaseURL(server.URL), WithTask("search")).Embed(context.Background(), &core.EmbedParams{Input: "Hi"})
+	var apiErr *core.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity || !strings.Contains(err.Error(), "jina: API error: task: Input should be") {
+		t.Fatalf("Embed() error = %v", err)
+	}
+}
+
+func TestEmbedRequiresAPIKey(t *testing.T) {
+	t.Parallel()
+	if os.Getenv(envJinaAPIKey) != "" {
+		t.Skip("JINA_API_KEY is set")
+	}
+
+	_, err := New("jina-embeddings-v3").Embed(context.Background(), &core.EmbedParams{Input: "Hi"})
+	if err == nil || !strings.Contains(err.Error(), "jina: API key is required") {
+		t.Fatalf("Embed() error = %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package jina
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+// RerankParams are the inputs of Rerank.
+type RerankParams struct {
+	Query     string
+	Documents []string
+
+	// TopN limits the result to the most relevant documents. Zero returns
+	// every document.
+	TopN int
+}
+
+// RerankResult lists documents by descending relevance.
+type RerankResult struct {
+	Results []RankedDocument
+	Usage   *core.Usage
+}
+
+// RankedDocument is one document of a RerankResult.
+type RankedDocument struct {
+	// Index is the position of the document in RerankParams.Documents.
+	Index          int
+	Document       string
+	RelevanceScore float64
+}
+
+type rerankRequest struct {
+	Model           string   `json:"model"`
+	Query           string   `json:"query"`
+	Documents       []string `json:"documents"`
+	TopN            int      `json:"top_n,omitempty"`
+	ReturnDocuments bool     `json:"return_documents"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+	Usage *struct {
+		TotalTokens int64 `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// Rerank orders params.Documents by their relevance to params.Query with a
+// reranker model, such as "jina-reranker-v2-base-multilingual". It is
+// typically applied to the matches of a vector search to keep the best few.
+func (a *Adapter) Rerank(ctx context.Context, params *RerankParams) (*RerankResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("jina: rerank params are required")
+	}
+	query := strings.TrimSpace(params.Query)
+	if query == "" {
+		return nil, errors.New("jina: rerank query is required")
+	}
+	if len(params.Documents) == 0 {
+		return nil, errors.New("jina: rerank documents are required")
+	}
+	for i, document := range params.Documents {
+		if strings.TrimSpace(document) == "" {
+			return nil, fmt.Errorf("jina: rerank document at index %d is empty", i)
+		}
+	}
+	if params.TopN < 0 {
+		return nil, errors.New("jina: rerank top n must not be negative")
+	}
+
+	request := rerankRequest{
+		Model:     a.Model,
+		Query:     query,
+		Documents: params.Documents,
+		TopN:      params.TopN,
+	}
+
+	var response rerankResponse
+	if _, err := a.transport().Send(ctx, httpclient.Request{Path: "/rerank", Name: "rerank", Body: &request}, &response); err != nil {
+		return nil, err
+	}
+
+	result := &RerankResult{Results: make([]RankedDocument, 0, len(response.Results))}
+	for _, item := range response.Results {
+		if item.Index < 0 || item.Index >= len(params.Documents) {
+			return nil, fmt.Errorf("jina: rerank response index %d out of range", item.Index)
+		}
+		result.Results = append(result.Results, RankedDocument{
+			Index:          item.Index,
+			Document:       params.Documents[item.Index],
+			RelevanceScore: item.RelevanceScore,
+		})
+	}
+	if response.Usage != nil {
+		result.Usage = &core.Usage{PromptTokens: response.Usage.TotalTokens, TotalTokens: response.Usage.TotalTokens}
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package jina
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRerankOrdersDocuments(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"model":"jina-reranker-v2-base-multilingual","usage":{"total_tokens":38},"results":[{"index":2,"relevance_score":0.92},{"index":0,"relevance_score":0.31}]}`)
+	}))
+	defer server.Close()
+
+	documents := []string{"Paris is in France.", "Bananas are yellow.", "Berlin is the capital of Germany."}
+	result, err := New("jina-reranker-v2-base-multilingual", WithAPIKey("jina-key"), WithBaseURL(server.URL)).Rerank(context.Background(), &RerankParams{
+		Query:     "What is the capital of Germany?",
+		Documents: documents,
+		TopN:      2,
+	})
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+
+	if body["query"] != "What is the capital of Germany?" || body["top_n"] != float64(2) || body["return_documents"] != false || len(body["documents"].([]any)) != 3 {
+		t.Fatalf("unexpected request body %v", body)
+	}
+	if len(result.Results) != 2 || result.Results[0].Index != 2 || result.Results[0].Document != documents[2] || result.Results[0].RelevanceScore != 0.92 || result.Results[1].Index != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 38 {
+		t.Fatalf("unexpected usage %+v", result.Usage)
+	}
+}
+
+func TestRerankValidatesParams(t *testing.T) {
+	t.Parallel()
+
+	adapter := New("jina-reranker-v2-base-multilingual", WithAPIKey("jina-key"), WithBaseURL("http://127.0.0.1:0"))
+	tests := []struct {
+		params *RerankParams
+		want   string
+	}{
+		{params: nil, want: "jina: rerank params are required"},
+		{params: &RerankParams{Documents: []string{"a"}}, want: "jina: rerank query is required"},
+		{params: &RerankParams{Query: "q"}, want: "jina: rerank documents are required"},
+		{params: &RerankParams{Query: "q", Documents: []string{"a", " "}}, want: "jina: rerank document at index 1 is empty"},
+		{params: &RerankParams{Query: "q", Documents: []string{"a"}, TopN: -1}, want: "jina: rerank top n must not be negative"},
+	}
+	for _, tt := range tests {
+		if _, err := adapter.Rerank(context.Background(), tt.params); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("Rerank(%+v) error = %v, want %q", tt.params, err, tt.want)
+		}
+	}
+}
+
+func TestRerankRejectsUnknownIndex(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"results":[{"index":5,"relevance_score":0.5}]}`)
+	}))
+	defer server.Close()
+
+	_, err := New("jina-reranker-v2-base-multilingual", WithAPIKey("jina-key"), WithBaseURL(server.URL)).Rerank(context.Background(), &RerankParams{Query: "q", Documents: []string{"a"}})
+	if err == nil || !strings.Contains(err.Error(), "jina: rerank response index 5 out of range") {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+}
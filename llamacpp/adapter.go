@@ -0,0 +1,200 @@
+// Package llamacpp is an adapter for llama-server, the HTTP server of
+// llama.cpp. Chat uses its OpenAI-compatible /v1/chat/completions endpoint
+// and Complete the native /completion endpoint.
+//
+// The native sampling settings of llama.cpp, such as Mirostat, min_p, and
+// GBNF grammars, are typed adapter options and are sent with each request
+// of both endpoints.
+package llamacpp
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+const (
+	providerName       = "llamacpp"
+	defaultBaseURL     = "http://localhost:8080"
+	defaultHTTPTimeout = 5 * time.Minute
+	envLlamaCppHost    = "LLAMACPP_HOST"
+	envLlamaAPIKey     = "LLAMA_API_KEY"
+
+	// defaultModel is sent when the adapter has no model. llama-server
+	// answers with the model it was started with whatever the name.
+	defaultModel = "default"
+)
+
+type Adapter struct {
+	// APIKey is only needed when llama-server runs with --api-key.
+	APIKey string
+	// Model may be empty when llama-server serves a single model.
+	Model string
+	// BaseURL is the root of llama-server, without /v1.
+	BaseURL string
+
+	// Sampling is sent with each request; ModelOptions override it.
+	Sampling Sampling
+
+	HTTPClient *http.Client
+}
+
+var _ core.TextAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
+
+type Option func(*Adapter)
+
+// New creates a llama.cpp adapter. The model may be empty when llama-server
+// serves a single model.
+//
+// Preferred usage is to use core and add this adapter there.
+//
+// If no base URL is provided via options, New reads LLAMACPP_HOST and falls
+// back to http://localhost:8080. If no API key is provided, it reads
+// LLAMA_API_KEY, the variable llama-server reads its --api-key from.
+func New(model string, opts ...Option) *Adapter {
+	baseURL := strings.TrimSpace(os.Getenv(envLlamaCppHost))
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	adapter := &Adapter{
+		APIKey:     strings.TrimSpace(os.Getenv(envLlamaAPIKey)),
+		Model:      strings.TrimSpace(model),
+		BaseURL:    baseURL,
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(adapter)
+	}
+
+	return adapter
+}
+
+// WithAPIKey sets the API key of a llama-server started with --api-key.
+func WithAPIKey(apiKey string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(apiKey) == "" {
+			return
+		}
+		adapter.APIKey = strings.TrimSpace(apiKey)
+	}
+}
+
+// WithBaseURL sets the server URL used by the adapter. A trailing /v1 is
+// ignored.
+func WithBaseURL(baseURL string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(baseURL) == "" {
+			return
+		}
+		adapter.BaseURL = strings.TrimSpace(baseURL)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the adapter.
+func WithHTTPClient(client *http.Client) Option {
+	return func(adapter *Adapter) {
+		if client == nil {
+			return
+		}
+		adapter.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the timeout on the adapter HTTP client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(adapter *Adapter) {
+		if timeout <= 0 {
+			return
+		}
+		if adapter.HTTPClient == nil {
+			adapter.HTTPClient = &http.Client{}
+		}
+		adapter.HTTPClient.Timeout = timeout
+	}
+}
+
+// Capabilities reports the chat features of llama-server. Tools need the
+// server to run with --jinja, vision a multimodal projector, and reasoning a
+// reasoning model.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:              true,
+		Vision:             true,
+		StructuredOutput:   true,
+		StreamingWithTools: true,
+		Reasoning:          true,
+	}
+}
+
+func (a *Adapter) validate() error {
+	if a == nil {
+		return errors.New("llamacpp: adapter is nil")
+	}
+	if strings.TrimSpace(a.APIKey) == "" {
+		a.APIKey = strings.TrimSpace(os.Getenv(envLlamaAPIKey))
+	}
+	return nil
+}
+
+func (a *Adapter) model() string {
+	if model := strings.TrimSpace(a.Model); model != "" {
+		return model
+	}
+	return defaultModel
+}
+
+// compat returns the Chat Completions client for the adapter.
+func (a *Adapter) compat() *openaicompat.Client {
+	return &openaicompat.Client{
+		Provider:       providerName,
+		Model:          a.model(),
+		Transport:      a.transport(),
+		ChatPath:       "/v1/chat/completions",
+		StreamUsage:    true,
+		PrepareRequest: a.prepareRequest,
+		Usage:          timedUsage,
+	}
+}
+
+func (a *Adapter) client() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// transport returns the client that sends requests to llama-server.
+func (a *Adapter) transport() *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   providerName,
+		Header: func(header http.Header) {
+			if a.APIKey != "" {
+				header.Set("Authorization", "Bearer "+a.APIKey)
+			}
+			header.Set("Accept", "application/json")
+		},
+		DecodeError: openaicompat.DecodeError(providerName),
+	}
+}
+
+func (a *Adapter) baseURL() string {
+	baseURL := strings.TrimRight(strings.TrimSpace(a.BaseURL), "/")
+	if baseURL == "" {
+		return defaultBaseURL
+	}
+	return strings.TrimSuffix(baseURL, "/v1")
+}
@@ -0,0 +1,97 @@
+package llamacpp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+// Chat sends a non-streaming chat request to /v1/chat/completions.
+//
+// It supports tool calls, structured output, and reasoning. The adapter
+// Sampling and ChatParams.TopK are sent as native fields, and the timings
+// of the response are reported on Usage.Details.
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().Chat(ctx, params)
+}
+
+// ChatStream sends a streaming chat request to /v1/chat/completions.
+//
+// Server tools run between streamed turns. Structured output streams as
+// StreamChunkPartialJSON chunks. Usage, with the timings, is reported on the
+// done chunk.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().ChatStream(ctx, params)
+}
+
+// prepareRequest adds the sampling fields the Chat Completions format has
+// no field for.
+func (a *Adapter) prepareRequest(params *core.ChatParams, request *openaicompat.Request) error {
+	if err := a.Sampling.checkGrammar(len(request.Tools) > 0, request.ResponseFormat != nil); err != nil {
+		return err
+	}
+
+	fields := a.Sampling.fields()
+	if params.TopK != nil {
+		fields["top_k"] = *params.TopK
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	if request.Extra == nil {
+		request.Extra = make(map[string]any, len(fields))
+	}
+	for key, value := range fields {
+		request.Extra[key] = value
+	}
+	return nil
+}
+
+// timings is the timings field llama-server adds to responses and to the
+// last stream chunk. Times are in milliseconds.
+type timings struct {
+	CacheN             int64   `json:"cache_n"`
+	PromptN            int64   `json:"prompt_n"`
+	PromptMS           float64 `json:"prompt_ms"`
+	PredictedN         int64   `json:"predicted_n"`
+	PredictedMS        float64 `json:"predicted_ms"`
+	PredictedPerSecond float64 `json:"predicted_per_second"`
+}
+
+// timedUsage adds the llama-server timings of a response or stream chunk
+// to usage.
+func timedUsage(raw []byte, usage *core.Usage) *core.Usage {
+	var envelope struct {
+		Timings *timings `json:"timings"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil || envelope.Timings == nil {
+		return usage
+	}
+	if usage == nil {
+		usage = &core.Usage{
+			PromptTokens:     envelope.Timings.CacheN + envelope.Timings.PromptN,
+			CompletionTokens: envelope.Timings.PredictedN,
+		}
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	envelope.Timings.addDetails(usage)
+	return usage
+}
+
+// addDetails adds the timings to usage.Details: prompt_time_us and
+// completion_time_us in microseconds, completion_tokens_per_second, and
+// cached_tokens, the prompt tokens reused from the cache.
+func (t *timings) addDetails(usage *core.Usage) {
+	openaicompat.AddDetail(usage, "prompt_time_us", int64(t.PromptMS*1e3))
+	openaicompat.AddDetail(usage, "completion_time_us", int64(t.PredictedMS*1e3))
+	openaicompat.AddDetail(usage, "completion_tokens_per_second", int64(t.PredictedPerSecond))
+	openaicompat.AddDetail(usage, "cached_tokens", t.CacheN)
+}
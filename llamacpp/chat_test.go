@@ -0,0 +1,140 @@
+package llamacpp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatSendsSamplingAndReportsTimings(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer llama-key" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"chatcmpl-1","model":"gpt-3.5-turbo",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"yes"},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":12,"completion_tokens":2,"total_tokens":14},
+			"timings":{"cache_n":8,"prompt_n":4,"prompt_ms":12.5,"predicted_n":2,"predicted_ms":40.25,"predicted_per_second":49.7}}`)
+	}))
+	defer server.Close()
+
+	adapter := New("", WithBaseURL(server.URL+"/v1/"), WithAPIKey("llama-key"),
+		WithMirostat(MirostatV2, 4.5, 0), WithMinP(0.05), WithGrammar(`root ::= "yes" | "no"`))
+	topK := int64(40)
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages:     []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Is water wet?"}},
+		TopK:         &topK,
+		ModelOptions: map[string]any{"min_p": 0.1, "repeat_penalty": 1.1},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if body["model"] != defaultModel || body["mirostat"] != float64(2) || body["mirostat_tau"] != 4.5 || body["top_k"] != float64(40) || body["grammar"] != `root ::= "yes" | "no"` {
+		t.Fatalf("unexpected request body %v", body)
+	}
+	if _, ok := body["mirostat_eta"]; ok {
+		t.Fatalf("unexpected mirostat_eta in %v", body)
+	}
+	if body["min_p"] != 0.1 || body["repeat_penalty"] != 1.1 {
+		t.Fatalf("model options did not override sampling: %v", body)
+	}
+
+	if result.Text != "yes" || result.Usage.TotalTokens != 14 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	want := map[string]int64{"cached_tokens": 8, "prompt_time_us": 12500, "completion_time_us": 40250, "completion_tokens_per_second": 49}
+	for key, value := range want {
+		if result.Usage.Details[key] != value {
+			t.Fatalf("usage details = %v", result.Usage.Details)
+		}
+	}
+}
+
+func TestChatRejectsGrammarWithToolsOrOutput(t *testing.T) {
+	t.Parallel()
+
+	adapter := New("qwen3-8b", WithBaseURL("http://127.0.0.1:0"), WithGrammar(`root ::= "a"`))
+	messages := []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}}
+
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: messages,
+		Tools:    []core.ToolUnion{core.ClientTool{Name: "lookup"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "llamacpp: a grammar cannot be combined with tools") {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	_, err = adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: messages,
+		Output:   &core.Schema{Name: "answer", Schema: map[string]any{"type": "object"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "llamacpp: a grammar cannot be combined with structured output") {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
+
+func TestChatStreamReportsTimings(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if options, _ := body["stream_options"].(map[string]any); options["include_usage"] != true {
+			t.Errorf("stream_options = %v", body["stream_options"])
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"chatcmpl-2","choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"Short."}}]}
+
+data: {"id":"chatcmpl-2","choices":[{"index":0,"delta":{"content":"Hi!"}}]}
+
+data: {"id":"chatcmpl-2","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}
+
+data: {"id":"chatcmpl-2","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":4,"total_tokens":9},"timings":{"prompt_n":5,"prompt_ms":3,"predicted_n":4,"predicted_ms":20,"predicted_per_second":200}}
+
+data: [DONE]
+
+`)
+	}))
+	defer server.Close()
+
+	stream, err := New("qwen3-8b", WithBaseURL(server.URL)).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var content strings.Builder
+	var done core.StreamChunk
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkContent:
+			content.WriteString(chunk.Delta)
+		case core.StreamChunkDone:
+			done = chunk
+		case core.StreamChunkError:
+			t.Fatalf("stream error: %s", chunk.Error)
+		}
+	}
+	if content.String() != "Hi!" || done.Reasoning != "Short." || done.Usage == nil {
+		t.Fatalf("content = %q, done = %+v", content.String(), done)
+	}
+	if done.Usage.TotalTokens != 9 || done.Usage.Details["completion_tokens_per_second"] != 200 || done.Usage.Details["completion_time_us"] != 20000 {
+		t.Fatalf("unexpected usage %+v", done.Usage)
+	}
+}
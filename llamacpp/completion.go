@@ -0,0 +1,140 @@
+package llamacpp
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+// CompletionParams describes a raw prompt completion (POST /completion).
+type CompletionParams struct {
+	// Prompt is sent to the model as is, without a chat template.
+	Prompt string
+
+	// MaxTokens limits the generated tokens (n_predict).
+	MaxTokens   *int64
+	Temperature *float64
+	TopP        *float64
+	TopK        *int64
+	Seed        *int64
+	Stop        []string
+
+	// JSONSchema constrains the output to JSON matching the schema. It
+	// cannot be combined with the adapter grammar.
+	JSONSchema map[string]any
+
+	// ModelOptions are sent as top-level request fields, such as
+	// "cache_prompt" or "n_probs", and override the adapter Sampling.
+	ModelOptions map[string]any
+}
+
+// CompletionResult is the response of a Complete call.
+type CompletionResult struct {
+	Text         string
+	FinishReason core.FinishReason
+	// RawFinishReason is the stop_type reported by llama-server: "eos",
+	// "word", "limit", or "none".
+	RawFinishReason string
+	// StoppingWord is the stop sequence that ended the completion, if any.
+	StoppingWord string
+	// Truncated reports that the prompt was cut to fit the context.
+	Truncated bool
+	Usage     *core.Usage
+}
+
+// Complete sends a non-streaming completion request to /completion.
+//
+// Unlike Chat, the prompt is not formatted with the chat template of the
+// model, which suits base models and hand-formatted prompts.
+func (a *Adapter) Complete(ctx context.Context, params CompletionParams) (*CompletionResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params.Prompt == "" {
+		return nil, errors.New("llamacpp: completion prompt is required")
+	}
+	if err := a.Sampling.checkGrammar(false, params.JSONSchema != nil); err != nil {
+		return nil, err
+	}
+
+	request := a.Sampling.fields()
+	request["prompt"] = params.Prompt
+	request["stream"] = false
+	if model := strings.TrimSpace(a.Model); model != "" {
+		request["model"] = model
+	}
+	if params.MaxTokens != nil {
+		request["n_predict"] = *params.MaxTokens
+	}
+	if params.Temperature != nil {
+		request["temperature"] = *params.Temperature
+	}
+	if params.TopP != nil {
+		request["top_p"] = *params.TopP
+	}
+	if params.TopK != nil {
+		request["top_k"] = *params.TopK
+	}
+	if params.Seed != nil {
+		request["seed"] = *params.Seed
+	}
+	if len(params.Stop) > 0 {
+		request["stop"] = params.Stop
+	}
+	if params.JSONSchema != nil {
+		request["json_schema"] = params.JSONSchema
+	}
+	for key, value := range params.ModelOptions {
+		if key == "" || value == nil {
+			continue
+		}
+		request[key] = value
+	}
+
+	var response completionResponse
+	if _, err := a.transport().Send(ctx, httpclient.Request{Path: "/completion", Name: "completion", Body: request}, &response); err != nil {
+		return nil, err
+	}
+
+	usage := &core.Usage{
+		PromptTokens:     response.TokensEvaluated,
+		CompletionTokens: response.TokensPredicted,
+		TotalTokens:      response.TokensEvaluated + response.TokensPredicted,
+	}
+	if response.Timings != nil {
+		response.Timings.addDetails(usage)
+	}
+
+	return &CompletionResult{
+		Text:            response.Content,
+		FinishReason:    toCoreFinishReason(response.StopType),
+		RawFinishReason: response.StopType,
+		StoppingWord:    response.StoppingWord,
+		Truncated:       response.Truncated,
+		Usage:           usage,
+	}, nil
+}
+
+type completionResponse struct {
+	Content         string   `json:"content"`
+	StopType        string   `json:"stop_type"`
+	StoppingWord    string   `json:"stopping_word"`
+	Truncated       bool     `json:"truncated"`
+	TokensEvaluated int64    `json:"tokens_evaluated"`
+	TokensPredicted int64    `json:"tokens_predicted"`
+	Timings         *timings `json:"timings"`
+}
+
+func toCoreFinishReason(stopType string) core.FinishReason {
+	switch stopType {
+	case "", "eos", "word":
+		return core.FinishReasonStop
+	case "limit":
+		return core.FinishReasonLength
+	default:
+		return core.FinishReason(stopType)
+	}
+}
@@ -0,0 +1,93 @@
+package llamacpp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestCompleteSendsNativeFields(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/completion" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"content":" Paris.","stop":true,"stop_type":"word","stopping_word":"\n","truncated":false,
+			"tokens_evaluated":7,"tokens_predicted":3,
+			"timings":{"prompt_n":7,"prompt_ms":9.5,"predicted_n":3,"predicted_ms":30,"predicted_per_second":100}}`)
+	}))
+	defer server.Close()
+
+	maxTokens := int64(16)
+	result, err := New("", WithBaseURL(server.URL), WithMinP(0.05)).Complete(context.Background(), CompletionParams{
+		Prompt:       "The capital of France is",
+		MaxTokens:    &maxTokens,
+		Stop:         []string{"\n"},
+		ModelOptions: map[string]any{"cache_prompt": true},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	if body["prompt"] != "The capital of France is" || body["n_predict"] != float64(16) || body["min_p"] != 0.05 || body["stream"] != false || body["cache_prompt"] != true {
+		t.Fatalf("unexpected request body %v", body)
+	}
+	if _, ok := body["model"]; ok {
+		t.Fatalf("unexpected model in %v", body)
+	}
+	if result.Text != " Paris." || result.FinishReason != core.FinishReasonStop || result.RawFinishReason != "word" || result.StoppingWord != "\n" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Usage.TotalTokens != 10 || result.Usage.Details["prompt_time_us"] != 9500 || result.Usage.Details["completion_tokens_per_second"] != 100 {
+		t.Fatalf("unexpected usage %+v", result.Usage)
+	}
+}
+
+func TestCompleteReportsLengthLimit(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if schema, _ := body["json_schema"].(map[string]any); schema["type"] != "object" {
+			t.Errorf("json_schema = %v", body["json_schema"])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"content":"{\"a\":","stop":true,"stop_type":"limit","tokens_evaluated":4,"tokens_predicted":2}`)
+	}))
+	defer server.Close()
+
+	result, err := New("", WithBaseURL(server.URL)).Complete(context.Background(), CompletionParams{
+		Prompt:     "JSON:",
+		JSONSchema: map[string]any{"type": "object"},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if result.FinishReason != core.FinishReasonLength {
+		t.Fatalf("finish reason = %q", result.FinishReason)
+	}
+}
+
+func TestCompleteValidatesParams(t *testing.T) {
+	t.Parallel()
+
+	adapter := New("", WithBaseURL("http://127.0.0.1:0"), WithGrammar(`root ::= "a"`))
+	if _, err := adapter.Complete(context.Background(), CompletionParams{}); err == nil || !strings.Contains(err.Error(), "prompt is required") {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	_, err := adapter.Complete(context.Background(), CompletionParams{Prompt: "x", JSONSchema: map[string]any{"type": "object"}})
+	if err == nil || !strings.Contains(err.Error(), "structured output") {
+		t.Fatalf("Complete() error = %v", err)
+	}
+}
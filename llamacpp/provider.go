@@ -0,0 +1,67 @@
+package llamacpp
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func init() {
+	core.RegisterProvider(providerName, newFromConfig)
+}
+
+// newFromConfig builds an adapter for core.NewAdapter. It supports the
+// options timeout, mirostat, mirostat_tau, mirostat_eta, min_p, and
+// grammar_file, which is read at once.
+func newFromConfig(config core.ProviderConfig) (core.TextAdapter, error) {
+	opts := []Option{WithAPIKey(config.APIKey), WithBaseURL(config.BaseURL)}
+	mirostat := MirostatDisabled
+	var tau, eta float64
+	for name, value := range config.Options {
+		switch name {
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			opts = append(opts, WithTimeout(timeout))
+		case "mirostat":
+			version, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			if version != MirostatDisabled && version != MirostatV1 && version != MirostatV2 {
+				return nil, fmt.Errorf("option %s: unknown version %d", name, version)
+			}
+			mirostat = version
+		case "mirostat_tau", "mirostat_eta", "min_p":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			switch name {
+			case "mirostat_tau":
+				tau = number
+			case "mirostat_eta":
+				eta = number
+			default:
+				opts = append(opts, WithMinP(number))
+			}
+		case "grammar_file":
+			grammar, err := os.ReadFile(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			opts = append(opts, WithGrammar(string(grammar)))
+		default:
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	if mirostat != MirostatDisabled {
+		opts = append(opts, WithMirostat(mirostat, tau, eta))
+	}
+	return New(config.Model, opts...), nil
+}
@@ -0,0 +1,42 @@
+package llamacpp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestFromDSN(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "yes-no.gbnf")
+	if err := os.WriteFile(path, []byte(`root ::= "yes" | "no"`), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	adapter, err := core.FromDSN("llamacpp://qwen3-8b?base_url=http://gpu-box:8080&timeout=20s&mirostat=2&mirostat_tau=4&min_p=0.05&grammar_file=" + path)
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	llamacpp := adapter.(*Adapter)
+	if llamacpp.Model != "qwen3-8b" || llamacpp.BaseURL != "http://gpu-box:8080" || llamacpp.HTTPClient.Timeout != 20*time.Second {
+		t.Fatalf("adapter = %+v", llamacpp)
+	}
+	sampling := llamacpp.Sampling
+	if sampling.Mirostat != MirostatV2 || *sampling.MirostatTau != 4 || sampling.MirostatEta != nil || *sampling.MinP != 0.05 || sampling.Grammar != `root ::= "yes" | "no"` {
+		t.Fatalf("sampling = %+v", sampling)
+	}
+
+	for _, dsn := range []string{
+		"llamacpp://qwen3-8b?mirostat=3",
+		"llamacpp://qwen3-8b?min_p=low",
+		"llamacpp://qwen3-8b?gzip=1",
+	} {
+		if _, err := core.FromDSN(dsn); err == nil {
+			t.Fatalf("FromDSN(%q) expected error", dsn)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package llamacpp
+
+import (
+	"errors"
+	"strings"
+)
+
+// Mirostat versions for Sampling.Mirostat.
+const (
+	MirostatDisabled = 0
+	MirostatV1       = 1
+	MirostatV2       = 2
+)
+
+// Sampling holds the native llama.cpp sampling settings. Unset fields keep
+// the defaults of llama-server.
+type Sampling struct {
+	// Mirostat selects Mirostat sampling, MirostatV1 or MirostatV2, which
+	// replaces top-k, top-p, and min-p sampling.
+	Mirostat int
+	// MirostatTau is the target entropy, 5.0 on llama-server.
+	MirostatTau *float64
+	// MirostatEta is the learning rate, 0.1 on llama-server.
+	MirostatEta *float64
+
+	// MinP drops tokens whose probability is below MinP times that of the
+	// most likely token.
+	MinP *float64
+
+	// Grammar is a GBNF grammar the output must follow. It cannot be
+	// combined with tools or structured output, which llama-server turns
+	// into grammars itself.
+	Grammar string
+}
+
+// WithMirostat enables Mirostat sampling with version MirostatV1 or
+// MirostatV2. A tau or eta of zero keeps the llama-server default.
+func WithMirostat(version int, tau, eta float64) Option {
+	return func(adapter *Adapter) {
+		if version != MirostatV1 && version != MirostatV2 {
+			return
+		}
+		adapter.Sampling.Mirostat = version
+		if tau > 0 {
+			adapter.Sampling.MirostatTau = &tau
+		}
+		if eta > 0 {
+			adapter.Sampling.MirostatEta = &eta
+		}
+	}
+}
+
+// WithMinP sets min-p sampling, between 0 and 1.
+func WithMinP(minP float64) Option {
+	return func(adapter *Adapter) {
+		if minP < 0 || minP > 1 {
+			return
+		}
+		adapter.Sampling.MinP = &minP
+	}
+}
+
+// WithGrammar constrains the output to a GBNF grammar.
+func WithGrammar(grammar string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(grammar) == "" {
+			return
+		}
+		adapter.Sampling.Grammar = grammar
+	}
+}
+
+// fields returns the request fields of s.
+func (s Sampling) fields() map[string]any {
+	fields := make(map[string]any)
+	if s.Mirostat != MirostatDisabled {
+		fields["mirostat"] = s.Mirostat
+	}
+	if s.MirostatTau != nil {
+		fields["mirostat_tau"] = *s.MirostatTau
+	}
+	if s.MirostatEta != nil {
+		fields["mirostat_eta"] = *s.MirostatEta
+	}
+	if s.MinP != nil {
+		fields["min_p"] = *s.MinP
+	}
+	if s.Grammar != "" {
+		fields["grammar"] = s.Grammar
+	}
+	return fields
+}
+
+// checkGrammar rejects a grammar next to the constraints llama-server
+// builds grammars from.
+func (s Sampling) checkGrammar(tools, output bool) error {
+	if s.Grammar == "" {
+		return nil
+	}
+	if tools {
+		return errors.New("llamacpp: a grammar cannot be combined with tools")
+	}
+	if output {
+		return errors.New("llamacpp: a grammar cannot be combined with structured output")
+	}
+	return nil
+}
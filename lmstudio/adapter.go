@@ -0,0 +1,181 @@
+// Package lmstudio is an adapter for the local server of LM Studio, which
+// serves the OpenAI Chat Completions format under /v1 and lists its models,
+// with whether each is loaded, under /api/v0.
+//
+// When the adapter has no model, each call uses the model LM Studio has
+// loaded: the first loaded chat model for Chat and ChatStream, and the first
+// loaded embedding model for Embed and EmbedMany.
+package lmstudio
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+const (
+	providerName       = "lmstudio"
+	defaultBaseURL     = "http://localhost:1234"
+	defaultHTTPTimeout = 5 * time.Minute
+	envLMStudioHost    = "LMSTUDIO_HOST"
+)
+
+type Adapter struct {
+	// APIKey is only needed when the LM Studio server requires
+	// authentication.
+	APIKey string
+	// Model may be empty to use the model loaded in LM Studio.
+	Model string
+	// BaseURL is the root of the LM Studio server, without /v1.
+	BaseURL string
+
+	HTTPClient *http.Client
+}
+
+var _ core.TextAdapter = (*Adapter)(nil)
+var _ core.EmbeddingAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
+
+type Option func(*Adapter)
+
+// New creates an LM Studio adapter. An empty model selects the loaded model
+// on each call.
+//
+// Preferred usage is to use core and add this adapter there.
+//
+// If no base URL is provided via options, New reads LMSTUDIO_HOST and falls
+// back to http://localhost:1234.
+func New(model string, opts ...Option) *Adapter {
+	baseURL := strings.TrimSpace(os.Getenv(envLMStudioHost))
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	adapter := &Adapter{
+		Model:      strings.TrimSpace(model),
+		BaseURL:    baseURL,
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(adapter)
+	}
+
+	return adapter
+}
+
+// WithAPIKey sets the API token sent to servers that require
+// authentication.
+func WithAPIKey(apiKey string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(apiKey) == "" {
+			return
+		}
+		adapter.APIKey = strings.TrimSpace(apiKey)
+	}
+}
+
+// WithBaseURL sets the server URL used by the adapter. A trailing /v1 is
+// ignored.
+func WithBaseURL(baseURL string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(baseURL) == "" {
+			return
+		}
+		adapter.BaseURL = strings.TrimSpace(baseURL)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the adapter.
+func WithHTTPClient(client *http.Client) Option {
+	return func(adapter *Adapter) {
+		if client == nil {
+			return
+		}
+		adapter.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the timeout on the adapter HTTP client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(adapter *Adapter) {
+		if timeout <= 0 {
+			return
+		}
+		if adapter.HTTPClient == nil {
+			adapter.HTTPClient = &http.Client{}
+		}
+		adapter.HTTPClient.Timeout = timeout
+	}
+}
+
+// Capabilities reports the chat features of the LM Studio server. Tools,
+// vision, and reasoning depend on the model.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:              true,
+		Vision:             true,
+		StructuredOutput:   true,
+		StreamingWithTools: true,
+		Reasoning:          true,
+	}
+}
+
+func (a *Adapter) validate() error {
+	if a == nil {
+		return errors.New("lmstudio: adapter is nil")
+	}
+	return nil
+}
+
+// compat returns the Chat Completions client for model.
+func (a *Adapter) compat(model string) *openaicompat.Client {
+	return &openaicompat.Client{
+		Provider:    providerName,
+		Model:       model,
+		Transport:   a.transport("/v1"),
+		StreamUsage: true,
+	}
+}
+
+func (a *Adapter) client() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// transport returns the client that sends requests to the API under
+// prefix, "/v1" for the OpenAI-compatible API or "/api/v0" for the native
+// one.
+func (a *Adapter) transport(prefix string) *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL() + prefix,
+		Provider:   providerName,
+		Header: func(header http.Header) {
+			if a.APIKey != "" {
+				header.Set("Authorization", "Bearer "+a.APIKey)
+			}
+			header.Set("Accept", "application/json")
+		},
+		DecodeError: openaicompat.DecodeError(providerName),
+	}
+}
+
+func (a *Adapter) baseURL() string {
+	baseURL := strings.TrimRight(strings.TrimSpace(a.BaseURL), "/")
+	if baseURL == "" {
+		return defaultBaseURL
+	}
+	return strings.TrimSuffix(baseURL, "/v1")
+}
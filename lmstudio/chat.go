@@ -0,0 +1,97 @@
+package lmstudio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// Chat sends a non-streaming chat request to LM Studio.
+//
+// It supports tool calls, structured output, and reasoning. When the
+// adapter has no model, the loaded chat model answers.
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	model, err := a.model(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	return a.compat(model).Chat(ctx, params)
+}
+
+// ChatStream sends a streaming chat request to LM Studio.
+//
+// Server tools run between streamed turns. Structured output streams as
+// StreamChunkPartialJSON chunks. Usage is reported on the done chunk.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	model, err := a.model(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	return a.compat(model).ChatStream(ctx, params)
+}
+
+// Embed creates an embedding vector for one input. When the adapter has no
+// model, the loaded embedding model is used.
+func (a *Adapter) Embed(ctx context.Context, params *core.EmbedParams) (*core.EmbedResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("lmstudio: embed params are required")
+	}
+	input := strings.TrimSpace(params.Input)
+	if input == "" {
+		return nil, errors.New("lmstudio: embed input is required")
+	}
+
+	vectors, usage, err := a.embed(ctx, []string{input}, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &core.EmbedResult{Embedding: vectors[0], Usage: usage}, nil
+}
+
+// EmbedMany creates one embedding vector per input in a single request.
+func (a *Adapter) EmbedMany(ctx context.Context, params *core.EmbedManyParams) (*core.EmbedManyResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("lmstudio: embed many params are required")
+	}
+	if len(params.Inputs) == 0 {
+		return nil, errors.New("lmstudio: embed many inputs are required")
+	}
+
+	inputs := make([]string, 0, len(params.Inputs))
+	for i, input := range params.Inputs {
+		trimmed := strings.TrimSpace(input)
+		if trimmed == "" {
+			return nil, fmt.Errorf("lmstudio: embed many input at index %d is empty", i)
+		}
+		inputs = append(inputs, trimmed)
+	}
+
+	vectors, usage, err := a.embed(ctx, inputs, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &core.EmbedManyResult{Embeddings: vectors, Usage: usage}, nil
+}
+
+func (a *Adapter) embed(ctx context.Context, inputs []string, dimensions *int64, options map[string]any) ([][]float64, *core.Usage, error) {
+	model, err := a.model(ctx, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a.compat(model).Embed(ctx, inputs, dimensions, options)
+}
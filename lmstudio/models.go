@@ -0,0 +1,148 @@
+package lmstudio
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+// Model types reported by LM Studio.
+const (
+	ModelTypeLLM        = "llm"
+	ModelTypeVLM        = "vlm"
+	ModelTypeEmbeddings = "embeddings"
+)
+
+// Model states reported by LM Studio.
+const (
+	ModelStateLoaded    = "loaded"
+	ModelStateNotLoaded = "not-loaded"
+)
+
+var _ core.ModelAdapter = (*Adapter)(nil)
+
+// Model describes a model downloaded to LM Studio.
+type Model struct {
+	ID string
+	// Type is ModelTypeLLM, ModelTypeVLM for vision models, or
+	// ModelTypeEmbeddings.
+	Type      string
+	Publisher string
+	Arch      string
+	// CompatibilityType is the model format, such as "gguf" or "mlx".
+	CompatibilityType string
+	Quantization      string
+	// State is ModelStateLoaded or ModelStateNotLoaded.
+	State            string
+	MaxContextLength int64
+	// LoadedContextLength is the context length the model is loaded with,
+	// zero when it is not loaded.
+	LoadedContextLength int64
+}
+
+// Loaded reports whether the model is loaded into memory.
+func (m Model) Loaded() bool {
+	return m.State == ModelStateLoaded
+}
+
+// Models lists the downloaded models with their state (GET /api/v0/models).
+func (a *Adapter) Models(ctx context.Context) ([]Model, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	var response modelList
+	if _, err := a.transport("/api/v0").Send(ctx, httpclient.Request{Method: http.MethodGet, Path: "/models", Name: "models"}, &response); err != nil {
+		return nil, err
+	}
+
+	out := make([]Model, 0, len(response.Data))
+	for _, model := range response.Data {
+		out = append(out, Model{
+			ID:                  model.ID,
+			Type:                model.Type,
+			Publisher:           model.Publisher,
+			Arch:                model.Arch,
+			CompatibilityType:   model.CompatibilityType,
+			Quantization:        model.Quantization,
+			State:               model.State,
+			MaxContextLength:    model.MaxContextLength,
+			LoadedContextLength: model.LoadedContextLength,
+		})
+	}
+	return out, nil
+}
+
+// LoadedModels lists the models loaded into memory.
+func (a *Adapter) LoadedModels(ctx context.Context) ([]Model, error) {
+	models, err := a.Models(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded []Model
+	for _, model := range models {
+		if model.Loaded() {
+			loaded = append(loaded, model)
+		}
+	}
+	return loaded, nil
+}
+
+// ListModels returns the downloaded models, loaded or not.
+func (a *Adapter) ListModels(ctx context.Context) ([]core.ModelInfo, error) {
+	models, err := a.Models(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]core.ModelInfo, 0, len(models))
+	for _, model := range models {
+		out = append(out, core.ModelInfo{ID: model.ID})
+	}
+	return out, nil
+}
+
+// model returns the adapter model or, when it is empty, the first loaded
+// model of the given kind: an embedding model when embeddings is set and a
+// chat model otherwise.
+func (a *Adapter) model(ctx context.Context, embeddings bool) (string, error) {
+	if model := strings.TrimSpace(a.Model); model != "" {
+		return model, nil
+	}
+
+	loaded, err := a.LoadedModels(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, model := range loaded {
+		if (model.Type == ModelTypeEmbeddings) == embeddings {
+			return model.ID, nil
+		}
+	}
+
+	if embeddings {
+		return "", errors.New("lmstudio: no embedding model is loaded (load one in LM Studio or set a model)")
+	}
+	return "", errors.New("lmstudio: no chat model is loaded (load one in LM Studio or set a model)")
+}
+
+type modelList struct {
+	Data []modelEntry `json:"data"`
+}
+
+type modelEntry struct {
+	ID                  string `json:"id"`
+	Type                string `json:"type"`
+	Publisher           string `json:"publisher"`
+	Arch                string `json:"arch"`
+	CompatibilityType   string `json:"compatibility_type"`
+	Quantization        string `json:"quantization"`
+	State               string `json:"state"`
+	MaxContextLength    int64  `json:"max_context_length"`
+	LoadedContextLength int64  `json:"loaded_context_length"`
+}
@@ -0,0 +1,163 @@
+package lmstudio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+const testModels = `{"object":"list","data":[
+	{"id":"qwen3-8b","object":"model","type":"llm","publisher":"qwen","arch":"qwen3","compatibility_type":"gguf","quantization":"Q4_K_M","state":"not-loaded","max_context_length":40960},
+	{"id":"text-embedding-nomic-embed-text-v1.5","object":"model","type":"embeddings","publisher":"nomic-ai","arch":"nomic-bert","compatibility_type":"gguf","quantization":"Q4_K_M","state":"loaded","max_context_length":2048,"loaded_context_length":2048},
+	{"id":"gemma-3-4b-it","object":"model","type":"vlm","publisher":"google","arch":"gemma3","compatibility_type":"mlx","quantization":"4bit","state":"loaded","max_context_length":131072,"loaded_context_length":8192}]}`
+
+func TestModelsReportsState(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v0/models" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, testModels)
+	}))
+	defer server.Close()
+
+	adapter := New("", WithBaseURL(server.URL))
+	loaded, err := adapter.LoadedModels(context.Background())
+	if err != nil {
+		t.Fatalf("LoadedModels() error = %v", err)
+	}
+	if len(loaded) != 2 || loaded[1].ID != "gemma-3-4b-it" || loaded[1].Type != ModelTypeVLM || loaded[1].LoadedContextLength != 8192 || loaded[1].CompatibilityType != "mlx" {
+		t.Fatalf("loaded = %+v", loaded)
+	}
+
+	models, err := core.ListModels(context.Background(), adapter)
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(models) != 3 || models[0].ID != "qwen3-8b" {
+		t.Fatalf("models = %+v", models)
+	}
+}
+
+func TestChatSelectsLoadedModel(t *testing.T) {
+	t.Parallel()
+
+	var modelRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v0/models":
+			modelRequests.Add(1)
+			fmt.Fprint(w, testModels)
+		case "/v1/chat/completions":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["model"] != "gemma-3-4b-it" {
+				t.Errorf("model = %v", body["model"])
+			}
+			fmt.Fprint(w, `{"id":"chatcmpl-1","choices":[{"index":0,"message":{"role":"assistant","content":"Hi there."},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":3,"total_tokens":6}}`)
+		case "/v1/embeddings":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["model"] != "text-embedding-nomic-embed-text-v1.5" {
+				t.Errorf("model = %v", body["model"])
+			}
+			fmt.Fprint(w, `{"data":[{"index":0,"embedding":[0.1,0.2]}],"usage":{"prompt_tokens":2,"total_tokens":2}}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	adapter := New("", WithBaseURL(server.URL+"/v1"))
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Text != "Hi there." || result.Usage.TotalTokens != 6 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	embedding, err := adapter.Embed(context.Background(), &core.EmbedParams{Input: "hello"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(embedding.Embedding) != 2 || modelRequests.Load() != 2 {
+		t.Fatalf("embedding = %+v after %d model requests", embedding, modelRequests.Load())
+	}
+}
+
+func TestChatUsesConfiguredModel(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer lm-token" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"chatcmpl-2","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}
+
+data: {"id":"chatcmpl-2","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}
+
+data: {"id":"chatcmpl-2","choices":[],"usage":{"prompt_tokens":4,"completion_tokens":1,"total_tokens":5}}
+
+data: [DONE]
+
+`)
+	}))
+	defer server.Close()
+
+	stream, err := New("qwen3-8b", WithBaseURL(server.URL), WithAPIKey("lm-token")).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var content strings.Builder
+	var done core.StreamChunk
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkContent:
+			content.WriteString(chunk.Delta)
+		case core.StreamChunkDone:
+			done = chunk
+		case core.StreamChunkError:
+			t.Fatalf("stream error: %s", chunk.Error)
+		}
+	}
+	if content.String() != "Hello" || done.Usage == nil || done.Usage.TotalTokens != 5 {
+		t.Fatalf("content = %q, done = %+v", content.String(), done)
+	}
+}
+
+func TestChatFailsWithoutLoadedModel(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"qwen3-8b","type":"llm","state":"not-loaded"}]}`)
+	}))
+	defer server.Close()
+
+	_, err := New("", WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "lmstudio: no chat model is loaded") {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package lmstudio
+
+import (
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+func init() {
+	openaicompat.RegisterProvider(providerName, func(model, apiKey, baseURL string, timeout time.Duration) core.TextAdapter {
+		return New(model, WithAPIKey(apiKey), WithBaseURL(baseURL), WithTimeout(timeout))
+	})
+}
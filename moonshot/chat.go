@@ -0,0 +1,106 @@
+package moonshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+// Chat sends a non-streaming chat request to Moonshot.
+//
+// It supports tool calls, structured output in JSON mode, and file parts,
+// which are uploaded and sent as their extracted text. When the last message
+// is an assistant message, the model continues it, and Text holds only the
+// continuation.
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	params, err := a.resolveFileParts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.compat().Chat(ctx, params)
+}
+
+// ChatStream sends a streaming chat request to Moonshot.
+//
+// Server tools run between streamed turns, and structured output streams as
+// StreamChunkPartialJSON chunks. A trailing assistant message is continued
+// as in Chat. Usage is reported on the done chunk.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	params, err := a.resolveFileParts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.compat().ChatStream(ctx, params)
+}
+
+// prepareRequest sends a trailing assistant message in partial mode, which
+// makes the model continue it. The name of the message, if any, is kept for
+// role-play prefills. Partial mode cannot be combined with tools.
+func prepareRequest(_ *core.ChatParams, request *openaicompat.Request) error {
+	if len(request.Messages) == 0 {
+		return nil
+	}
+	last := &request.Messages[len(request.Messages)-1]
+	if last.Role != core.RoleAssistant || len(last.ToolCalls) > 0 {
+		return nil
+	}
+	if len(request.Tools) > 0 {
+		return errors.New("moonshot: a trailing assistant message (partial mode) cannot be combined with tools")
+	}
+
+	if last.Extra == nil {
+		last.Extra = make(map[string]any, 1)
+	}
+	last.Extra["partial"] = true
+	return nil
+}
+
+type moonshotUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+	CachedTokens     int64 `json:"cached_tokens"`
+}
+
+// choiceUsage reads the usage Moonshot reports on the last choice of a
+// stream rather than on the chunk, and adds the tokens served from the
+// context cache to usage as cached_tokens.
+func choiceUsage(raw []byte, usage *core.Usage) *core.Usage {
+	var envelope struct {
+		Usage   *moonshotUsage `json:"usage"`
+		Choices []struct {
+			Usage *moonshotUsage `json:"usage"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return usage
+	}
+
+	reported := envelope.Usage
+	if reported == nil && len(envelope.Choices) > 0 && envelope.Choices[0].Usage != nil {
+		reported = envelope.Choices[0].Usage
+		usage = &core.Usage{
+			PromptTokens:     reported.PromptTokens,
+			CompletionTokens: reported.CompletionTokens,
+			TotalTokens:      reported.TotalTokens,
+		}
+	}
+	if reported == nil || usage == nil || reported.CachedTokens <= 0 {
+		return usage
+	}
+
+	if usage.Details == nil {
+		usage.Details = make(map[string]int64)
+	}
+	usage.Details["cached_tokens"] = reported.CachedTokens
+	return usage
+}
@@ -0,0 +1,121 @@
+package moonshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatContinuesTrailingAssistantMessage(t *testing.T) {
+	t.Parallel()
+
+	var body struct {
+		Messages []map[string]any `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("unexpected request %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"id":"c1","choices":[{"index":0,"message":{"role":"assistant","content":"\"name\": \"Kimi\"}"},"finish_reason":"stop"}],"usage":{"prompt_tokens":20,"completion_tokens":6,"total_tokens":26,"cached_tokens":16}}`)
+	}))
+	defer server.Close()
+
+	result, err := New("kimi-k2-0905-preview", WithAPIKey("sk-test"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "Introduce yourself as JSON."},
+			core.TextMessagePart{Role: core.RoleAssistant, Name: "Kimi", Content: "{"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if len(body.Messages) != 2 {
+		t.Fatalf("unexpected messages %v", body.Messages)
+	}
+	prefill := body.Messages[1]
+	if prefill["role"] != "assistant" || prefill["content"] != "{" || prefill["name"] != "Kimi" || prefill["partial"] != true {
+		t.Fatalf("unexpected prefill %v", prefill)
+	}
+	if _, ok := body.Messages[0]["partial"]; ok {
+		t.Fatalf("unexpected partial on %v", body.Messages[0])
+	}
+	if result.Text != `"name": "Kimi"}` || result.Usage.Details["cached_tokens"] != 16 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestChatRejectsPartialModeWithTools(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("kimi-k2-0905-preview", WithAPIKey("sk-test"), WithBaseURL("http://127.0.0.1:0")).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "Weather in Paris?"},
+			core.TextMessagePart{Role: core.RoleAssistant, Content: "Let me"},
+		},
+		Tools: []core.ToolUnion{core.ClientTool{Name: "weather"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "moonshot: a trailing assistant message (partial mode) cannot be combined with tools") {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
+
+func TestChatStreamReadsUsageFromLastChoice(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []map[string]any `json:"messages"`
+			Stream   bool             `json:"stream"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if !body.Stream || body.Messages[len(body.Messages)-1]["partial"] != true {
+			t.Errorf("unexpected request body %v", body)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"c2","choices":[{"index":0,"delta":{"role":"assistant","content":" upon"}}]}
+
+data: {"id":"c2","choices":[{"index":0,"delta":{"content":" a time."},"finish_reason":"stop","usage":{"prompt_tokens":12,"completion_tokens":4,"total_tokens":16,"cached_tokens":8}}]}
+
+data: [DONE]
+
+`)
+	}))
+	defer server.Close()
+
+	stream, err := New("moonshot-v1-8k", WithAPIKey("sk-test"), WithBaseURL(server.URL)).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "Start a story."},
+			core.TextMessagePart{Role: core.RoleAssistant, Content: "Once"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var content strings.Builder
+	var done core.StreamChunk
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkContent:
+			content.WriteString(chunk.Delta)
+		case core.StreamChunkDone:
+			done = chunk
+		case core.StreamChunkError:
+			t.Fatalf("stream error: %s", chunk.Error)
+		}
+	}
+	if content.String() != " upon a time." {
+		t.Fatalf("content = %q", content.String())
+	}
+	if done.Usage == nil || done.Usage.TotalTokens != 16 || done.Usage.Details["cached_tokens"] != 8 {
+		t.Fatalf("done = %+v", done)
+	}
+}
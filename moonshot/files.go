@@ -0,0 +1,263 @@
+package moonshot
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+// fileUploadPurpose is the Files API purpose for files whose text is
+// extracted for the chat context.
+const fileUploadPurpose = "file-extract"
+
+// fileCache remembers the file ID returned for each uploaded file, so that
+// identical content is only uploaded once, and the extracted content of each
+// file ID, so that it is only downloaded once.
+type fileCache struct {
+	mu       sync.Mutex
+	ids      map[string]string
+	contents map[string]string
+}
+
+type fileUploadResponse struct {
+	ID string `json:"id"`
+}
+
+// resolveFileParts replaces the file parts of params with their extracted
+// content. Each file becomes a system message placed before the message that
+// held it, and a message left without parts is dropped. The original params
+// are not modified; params is returned as is when it has no file parts.
+func (a *Adapter) resolveFileParts(ctx context.Context, params *core.ChatParams) (*core.ChatParams, error) {
+	if params == nil || !hasFileParts(params.Messages) {
+		return params, nil
+	}
+
+	messages := make([]core.MessageUnion, 0, len(params.Messages)+1)
+	for i, union := range params.Messages {
+		resolved, err := a.resolveMessageFiles(ctx, union)
+		if err != nil {
+			return nil, fmt.Errorf("moonshot: invalid message at index %d: %w", i, err)
+		}
+		messages = append(messages, resolved...)
+	}
+
+	resolved := *params
+	resolved.Messages = messages
+	return &resolved, nil
+}
+
+func (a *Adapter) resolveMessageFiles(ctx context.Context, union core.MessageUnion) ([]core.MessageUnion, error) {
+	var msg core.ContentMessagePart
+	switch typed := union.(type) {
+	case core.ContentMessagePart:
+		msg = typed
+	case *core.ContentMessagePart:
+		if typed == nil {
+			return []core.MessageUnion{union}, nil
+		}
+		msg = *typed
+	default:
+		return []core.MessageUnion{union}, nil
+	}
+
+	out := make([]core.MessageUnion, 0, 2)
+	parts := make([]core.ContentPart, 0, len(msg.Parts))
+	for i, part := range msg.Parts {
+		file, ok := filePartValue(part)
+		if !ok {
+			parts = append(parts, part)
+			continue
+		}
+		content, err := a.fileContent(ctx, file)
+		if err != nil {
+			return nil, fmt.Errorf("content part at index %d: %w", i, err)
+		}
+		out = append(out, core.TextMessagePart{Role: core.RoleSystem, Content: content})
+	}
+	if len(parts) == 0 {
+		return out, nil
+	}
+
+	msg.Parts = parts
+	return append(out, msg), nil
+}
+
+// fileContent returns the extracted content of file, uploading its data
+// first when it has no FileID.
+func (a *Adapter) fileContent(ctx context.Context, file core.FilePart) (string, error) {
+	fileID := strings.TrimSpace(file.FileID)
+	if fileID == "" {
+		var err error
+		if fileID, err = a.uploadFile(ctx, file); err != nil {
+			return "", err
+		}
+	}
+
+	cache := a.files
+	if cache != nil {
+		cache.mu.Lock()
+		content, ok := cache.contents[fileID]
+		cache.mu.Unlock()
+		if ok {
+			return content, nil
+		}
+	}
+
+	request := httpclient.Request{Method: http.MethodGet, Path: "/files/" + url.PathEscape(fileID) + "/content", Name: "file content"}
+	httpResp, err := a.transport().Send(ctx, request, nil)
+	if err != nil {
+		return "", err
+	}
+	content := strings.TrimSpace(string(httpResp.Body))
+	if content == "" {
+		return "", fmt.Errorf("moonshot: file %s has no extracted content", fileID)
+	}
+
+	if cache != nil {
+		cache.mu.Lock()
+		if cache.contents == nil {
+			cache.contents = make(map[string]string)
+		}
+		cache.contents[fileID] = content
+		cache.mu.Unlock()
+	}
+
+	return content, nil
+}
+
+// uploadFile uploads file through the Files API, reusing the ID of an earlier
+// upload with the same content.
+func (a *Adapter) uploadFile(ctx context.Context, file core.FilePart) (string, error) {
+	if len(file.Data) == 0 {
+		return "", errors.New("file part requires a FileID or data")
+	}
+
+	key := fileCacheKey(file)
+	cache := a.files
+	if cache != nil {
+		cache.mu.Lock()
+		fileID, ok := cache.ids[key]
+		cache.mu.Unlock()
+		if ok {
+			return fileID, nil
+		}
+	}
+
+	body, contentType, err := buildFileUploadForm(file)
+	if err != nil {
+		return "", err
+	}
+
+	var response fileUploadResponse
+	upload := httpclient.Request{Path: "/files", Name: "file upload", Body: body.Bytes(), ContentType: contentType}
+	if _, err := a.transport().Send(ctx, upload, &response); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(response.ID) == "" {
+		return "", errors.New("moonshot: file upload response did not include an ID")
+	}
+
+	if cache != nil {
+		cache.mu.Lock()
+		if cache.ids == nil {
+			cache.ids = make(map[string]string)
+		}
+		cache.ids[key] = response.ID
+		cache.mu.Unlock()
+	}
+
+	return response.ID, nil
+}
+
+// buildFileUploadForm encodes file as a multipart form. Moonshot reads the
+// file type from the filename, so a missing filename gets the extension of
+// the MIME type.
+func buildFileUploadForm(file core.FilePart) (*bytes.Buffer, string, error) {
+	mimeType := strings.TrimSpace(file.MimeType)
+	filename := strings.TrimSpace(file.Filename)
+	if filename == "" {
+		filename = "file"
+		if extensions, _ := mime.ExtensionsByType(mimeType); len(extensions) > 0 {
+			filename += extensions[0]
+		}
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	if err := writer.WriteField("purpose", fileUploadPurpose); err != nil {
+		return nil, "", fmt.Errorf("moonshot: write purpose field: %w", err)
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	header.Set("Content-Type", mimeType)
+
+	filePart, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, "", fmt.Errorf("moonshot: create file form field: %w", err)
+	}
+	if _, err := filePart.Write(file.Data); err != nil {
+		return nil, "", fmt.Errorf("moonshot: write file data: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("moonshot: close multipart writer: %w", err)
+	}
+
+	return &buf, writer.FormDataContentType(), nil
+}
+
+func fileCacheKey(file core.FilePart) string {
+	sum := sha256.Sum256(file.Data)
+	return strings.TrimSpace(file.MimeType) + ":" + hex.EncodeToString(sum[:])
+}
+
+func hasFileParts(messages []core.MessageUnion) bool {
+	for _, union := range messages {
+		var parts []core.ContentPart
+		switch msg := union.(type) {
+		case core.ContentMessagePart:
+			parts = msg.Parts
+		case *core.ContentMessagePart:
+			if msg != nil {
+				parts = msg.Parts
+			}
+		}
+		for _, part := range parts {
+			if _, ok := filePartValue(part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func filePartValue(part core.ContentPart) (core.FilePart, bool) {
+	switch typed := part.(type) {
+	case core.FilePart:
+		return typed, true
+	case *core.FilePart:
+		if typed == nil {
+			return core.FilePart{}, false
+		}
+		return *typed, true
+	}
+	return core.FilePart{}, false
+}
@@ -0,0 +1,119 @@
+package moonshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatSendsFileContentAsSystemMessages(t *testing.T) {
+	t.Parallel()
+
+	var uploads, downloads atomic.Int32
+	var messages [][]map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			uploads.Add(1)
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Errorf("ParseMultipartForm() error = %v", err)
+			}
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("FormFile() error = %v", err)
+			} else {
+				file.Close()
+			}
+			if r.FormValue("purpose") != "file-extract" || header == nil || header.Filename != "file.pdf" {
+				t.Errorf("unexpected upload purpose %q, header %+v", r.FormValue("purpose"), header)
+			}
+			fmt.Fprint(w, `{"id":"file-uploaded","object":"file","purpose":"file-extract","status":"ok"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/files/file-uploaded/content":
+			downloads.Add(1)
+			fmt.Fprint(w, `{"content":"Q3 revenue grew 12%.","file_type":"application/pdf","filename":"file.pdf","title":"","type":"file"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/files/file-existing/content":
+			downloads.Add(1)
+			fmt.Fprint(w, `{"content":"Headcount is 40.","filename":"team.txt","type":"file"}`)
+		case r.URL.Path == "/chat/completions":
+			var body struct {
+				Messages []map[string]any `json:"messages"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			messages = append(messages, body.Messages)
+			fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"Revenue grew 12%."},"finish_reason":"stop"}]}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	adapter := New("moonshot-v1-32k", WithAPIKey("sk-test"), WithBaseURL(server.URL))
+	params := &core.ChatParams{Messages: []core.MessageUnion{
+		core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+			core.FilePart{Data: []byte("%PDF-1.7"), MimeType: "application/pdf"},
+			core.FilePart{FileID: "file-existing"},
+		}},
+		core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+			core.TextPart{Text: "How did revenue develop?"},
+		}},
+	}}
+	for range 2 {
+		if _, err := adapter.Chat(context.Background(), params); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+
+	if uploads.Load() != 1 || downloads.Load() != 2 {
+		t.Fatalf("uploads = %d, downloads = %d", uploads.Load(), downloads.Load())
+	}
+	sent := messages[1]
+	if len(sent) != 3 || sent[0]["role"] != "system" || sent[1]["role"] != "system" || sent[2]["role"] != "user" {
+		t.Fatalf("unexpected messages %v", sent)
+	}
+	if sent[0]["content"] != `{"content":"Q3 revenue grew 12%.","file_type":"application/pdf","filename":"file.pdf","title":"","type":"file"}` {
+		t.Fatalf("unexpected file content %v", sent[0])
+	}
+	if _, ok := params.Messages[0].(core.ContentMessagePart); !ok || len(params.Messages) != 2 {
+		t.Fatalf("params were modified: %v", params.Messages)
+	}
+}
+
+func TestChatKeepsPartsBesideFiles(t *testing.T) {
+	t.Parallel()
+
+	var messages []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/files/file-1/content" {
+			fmt.Fprint(w, `{"content":"Notes.","type":"file"}`)
+			return
+		}
+		var body struct {
+			Messages []map[string]any `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		messages = body.Messages
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"Done."},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	_, err := New("moonshot-v1-8k", WithAPIKey("sk-test"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{&core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+			core.TextPart{Text: "Summarize the notes."},
+			&core.FilePart{FileID: "file-1"},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if len(messages) != 2 || messages[0]["role"] != "system" || messages[0]["content"] != `{"content":"Notes.","type":"file"}` || messages[1]["role"] != "user" {
+		t.Fatalf("unexpected messages %v", messages)
+	}
+}
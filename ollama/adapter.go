@@ -1,16 +1,20 @@
 package ollama
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
 )
 
 const (
+	providerName           = "ollama"
 	defaultBaseURL         = "http://localhost:11434"
 	defaultMaxAgenticLoops = 8
 	defaultHTTPTimeout     = 5 * time.Minute
@@ -23,10 +27,42 @@ type Adapter struct {
 	Model      string
 	BaseURL    string
 	HTTPClient *http.Client
+
+	// KeepAlive controls how long Ollama keeps the model loaded after a
+	// request. Nil keeps the server default; zero unloads the model right
+	// away; a negative duration keeps it loaded indefinitely.
+	KeepAlive *time.Duration
+
+	// URLFetcher, when set, downloads images given as URLSource and sends
+	// them as base64 data.
+	URLFetcher *core.URLFetcher
+
+	// AutoContext sizes num_ctx for each chat request from the prompt and the
+	// model's context length, and rejects prompts that do not fit.
+	AutoContext bool
+
+	// CheckPromptSize rejects chat requests whose estimated prompt plus
+	// num_predict does not fit the context window, before sending them.
+	CheckPromptSize bool
+
+	// AutoPull pulls the model and retries once when a request fails with
+	// core.ModelNotFoundError.
+	AutoPull bool
+
+	// Runtime sets default runtime options, such as num_thread or num_gpu,
+	// for chat, generate, and embed requests.
+	Runtime *RuntimeOptions
+
+	// Gzip compresses request bodies of 1 KiB or more and asks for gzip
+	// responses, for servers behind a proxy that accepts gzip bodies.
+	Gzip bool
+
+	contextLengths *contextLengthCache
 }
 
 var _ core.TextAdapter = (*Adapter)(nil)
 var _ core.EmbeddingAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
 
 type Option func(*Adapter)
 
@@ -47,6 +83,8 @@ func New(model string, opts ...Option) *Adapter {
 		Model:      strings.TrimSpace(model),
 		BaseURL:    baseURL,
 		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+
+		contextLengths: &contextLengthCache{},
 	}
 
 	for _, opt := range opts {
@@ -109,6 +147,100 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithKeepAlive sets how long Ollama keeps the model loaded after chat and
+// embed requests. Individual requests can override it with
+// ModelOptions["keep_alive"].
+func WithKeepAlive(keepAlive time.Duration) Option {
+	return func(adapter *Adapter) {
+		adapter.KeepAlive = &keepAlive
+	}
+}
+
+// WithImageURLFetch lets the adapter download images given as URLSource and
+// send them as base64 data, since Ollama only accepts inline images. It uses
+// a core.URLFetcher of its own: images larger than maxBytes, or 20 MiB when
+// maxBytes is zero, are rejected, as are other types than images and hosts
+// on private networks.
+func WithImageURLFetch(maxBytes int64) Option {
+	return func(adapter *Adapter) {
+		if adapter.URLFetcher != nil {
+			return
+		}
+		adapter.URLFetcher = &core.URLFetcher{MaxBytes: maxBytes}
+	}
+}
+
+// WithURLFetcher lets the adapter download images given as URLSource through
+// fetcher, which adds a host allow-list and a cache shared by every adapter
+// using it. It takes precedence over WithImageURLFetch.
+func WithURLFetcher(fetcher *core.URLFetcher) Option {
+	return func(adapter *Adapter) {
+		if fetcher == nil {
+			return
+		}
+		adapter.URLFetcher = fetcher
+	}
+}
+
+// WithAutoContext sizes num_ctx for each chat request to fit the prompt and
+// the expected reply, up to the context length reported by /api/show. Without
+// it, Ollama silently truncates prompts longer than its default num_ctx.
+// Prompts larger than the model's context window are rejected before sending.
+func WithAutoContext() Option {
+	return func(adapter *Adapter) {
+		adapter.AutoContext = true
+	}
+}
+
+// WithGzip compresses request bodies of 1 KiB or more with gzip and asks for
+// gzip responses. The Ollama server itself does not decode gzip request
+// bodies, so use it only behind a proxy that does, for example to send large
+// images to a remote server.
+func WithGzip() Option {
+	return func(adapter *Adapter) {
+		adapter.Gzip = true
+	}
+}
+
+// WithPromptSizeCheck estimates the prompt tokens of each chat request and
+// returns a *core.ContextLengthExceededError when the prompt plus num_predict
+// cannot fit num_ctx, or the context length reported by /api/show when
+// num_ctx is not set.
+func WithPromptSizeCheck() Option {
+	return func(adapter *Adapter) {
+		adapter.CheckPromptSize = true
+	}
+}
+
+// WithAutoPull makes chat, generate, and embed requests pull a missing model
+// and retry once. The first request may then take as long as the download.
+func WithAutoPull() Option {
+	return func(adapter *Adapter) {
+		adapter.AutoPull = true
+	}
+}
+
+// WithRuntimeOptions sets default runtime options for every request.
+// Individual requests can override them through ModelOptions.
+func WithRuntimeOptions(options RuntimeOptions) Option {
+	return func(adapter *Adapter) {
+		adapter.Runtime = &options
+	}
+}
+
+// Capabilities reports the chat features of the Ollama API. Whether a model
+// supports tools, vision, or thinking depends on the model; Show reports the
+// capabilities of a specific model.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:              true,
+		Vision:             true,
+		StructuredOutput:   true,
+		StreamingWithTools: true,
+		Reasoning:          true,
+	}
+}
+
 func (a *Adapter) validate() error {
 	if a == nil {
 		return errors.New("ollama: adapter is nil")
@@ -125,6 +257,23 @@ func (a *Adapter) validate() error {
 	return nil
 }
 
+// withAutoPull runs call and, when AutoPull is enabled and the model is
+// missing, pulls the model and runs call once more.
+func withAutoPull[T any](ctx context.Context, a *Adapter, call func() (T, error)) (T, error) {
+	result, err := call()
+
+	var notFound *core.ModelNotFoundError
+	if err == nil || !a.AutoPull || !errors.As(err, &notFound) {
+		return result, err
+	}
+
+	model := nonEmpty(notFound.Model, a.Model)
+	if pullErr := a.Pull(ctx, PullParams{Model: model}); pullErr != nil {
+		return result, fmt.Errorf("ollama: auto-pull %s: %w", model, pullErr)
+	}
+	return call()
+}
+
 func (a *Adapter) client() *http.Client {
 	if a.HTTPClient != nil {
 		return a.HTTPClient
@@ -132,6 +281,44 @@ func (a *Adapter) client() *http.Client {
 	return &http.Client{Timeout: defaultHTTPTimeout}
 }
 
+// ndjson asks for a streamed response of newline-delimited JSON objects.
+var ndjson = http.Header{"Accept": {"application/x-ndjson"}}
+
+// transport returns the client that sends API requests with the adapter's
+// credentials.
+func (a *Adapter) transport() *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   "ollama",
+		Header: func(header http.Header) {
+			header.Set("Accept", "application/json")
+			if apiKey := strings.TrimSpace(a.APIKey); apiKey != "" {
+				header.Set("Authorization", "Bearer "+apiKey)
+			}
+		},
+		DecodeError: decodeAPIError,
+		Gzip:        a.Gzip,
+	}
+}
+
+// keepAlive returns the keep_alive value for a request: the per-request
+// ModelOptions override if set, else the adapter default.
+func (a *Adapter) keepAlive(modelOptions map[string]any) any {
+	for key, value := range modelOptions {
+		if isKeepAliveKey(key) && value != nil {
+			if duration, ok := value.(time.Duration); ok {
+				return duration.String()
+			}
+			return value
+		}
+	}
+	if a.KeepAlive != nil {
+		return a.KeepAlive.String()
+	}
+	return nil
+}
+
 func (a *Adapter) baseURL() string {
 	if strings.TrimSpace(a.BaseURL) == "" {
 		if host := strings.TrimSpace(os.Getenv(envOllamaHost)); host != "" {
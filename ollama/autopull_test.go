@@ -0,0 +1,74 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func newMissingModelServer(t *testing.T, pulls *int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/pull":
+			*pulls++
+			_, _ = fmt.Fprintln(w, `{"status":"success"}`)
+		case "/api/chat":
+			if *pulls == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"model \"llama3.2\" not found, try pulling it first"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"ok"},"done":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestChatReturnsModelNotFoundError(t *testing.T) {
+	t.Parallel()
+
+	pulls := 0
+	server := newMissingModelServer(t, &pulls)
+	defer server.Close()
+
+	_, err := New("llama3.2", WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	})
+
+	var notFound *core.ModelNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected ModelNotFoundError, got %T %v", err, err)
+	}
+	if notFound.Model != "llama3.2" {
+		t.Fatalf("unexpected model %q", notFound.Model)
+	}
+	if pulls != 0 {
+		t.Fatal("expected no pull without AutoPull")
+	}
+}
+
+func TestChatAutoPullsMissingModel(t *testing.T) {
+	t.Parallel()
+
+	pulls := 0
+	server := newMissingModelServer(t, &pulls)
+	defer server.Close()
+
+	result, err := New("llama3.2", WithBaseURL(server.URL), WithAutoPull()).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if result.Text != "ok" || pulls != 1 {
+		t.Fatalf("unexpected result %q after %d pulls", result.Text, pulls)
+	}
+}
@@ -0,0 +1,101 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// roundTripFunc serves benchmark responses without a network round trip.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// cannedClient returns a client that answers every request with body.
+func cannedClient(contentType string, body []byte) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			_, _ = io.Copy(io.Discard, req.Body)
+			_ = req.Body.Close()
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {contentType}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+			Request:    req,
+		}, nil
+	})}
+}
+
+func benchmarkChatStream(deltas int) []byte {
+	var buf bytes.Buffer
+	for i := range deltas / 2 {
+		fmt.Fprintf(&buf, "{\"model\":\"llama-test\",\"message\":{\"role\":\"assistant\",\"thinking\":\"step %d \"},\"done\":false}\n", i)
+	}
+	for i := range deltas / 2 {
+		fmt.Fprintf(&buf, "{\"model\":\"llama-test\",\"message\":{\"role\":\"assistant\",\"content\":\"token %d \"},\"done\":false}\n", i)
+	}
+	buf.WriteString("{\"model\":\"llama-test\",\"message\":{\"role\":\"assistant\",\"content\":\"\"},\"done\":true,\"done_reason\":\"stop\",\"prompt_eval_count\":10,\"eval_count\":1000}\n")
+	return buf.Bytes()
+}
+
+func benchmarkConversation(turns int) *core.ChatParams {
+	messages := []core.MessageUnion{core.TextMessagePart{Role: core.RoleSystem, Content: "You are a helpful assistant."}}
+	for i := range turns {
+		id := fmt.Sprintf("call_%d", i)
+		messages = append(messages,
+			core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+				core.TextPart{Text: fmt.Sprintf("What is the weather in city %d?", i)},
+				core.ImagePart{Source: core.DataSource{Data: "iVBORw0KGgo=", MimeType: "image/png"}},
+			}},
+			core.ToolCallMessagePart{Role: core.RoleToolCall, ToolCalls: []core.ToolCall{
+				{ID: id, Name: "get_weather", Arguments: map[string]any{"city": i, "unit": "celsius"}},
+			}},
+			core.ToolResultMessagePart{Role: core.RoleToolResult, ToolCallID: id, Name: "get_weather", Content: `{"temperature":21}`},
+			core.TextMessagePart{Role: core.RoleAssistant, Content: "It is 21 degrees and sunny."},
+		)
+	}
+	return &core.ChatParams{Messages: messages}
+}
+
+// BenchmarkChatStream measures reading a stream of 500 thinking and 500
+// content deltas and emitting their chunks.
+func BenchmarkChatStream(b *testing.B) {
+	body := benchmarkChatStream(1000)
+	adapter := New("llama-test", WithHTTPClient(cannedClient("application/x-ndjson", body)))
+	params := &core.ChatParams{Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hello"}}}
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		stream, err := adapter.ChatStream(context.Background(), params)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for chunk := range stream {
+			if chunk.Type == core.StreamChunkError {
+				b.Fatal(chunk.Error)
+			}
+		}
+	}
+}
+
+// BenchmarkToMessages measures converting a 20 turn conversation with tool
+// calls and images to chat messages.
+func BenchmarkToMessages(b *testing.B) {
+	params := benchmarkConversation(20)
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, err := toMessages(params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
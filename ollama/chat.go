@@ -1,15 +1,17 @@
 package ollama
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"strings"
+	"time"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/bufpool"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/partialjson"
+	"github.com/m43i/go-ai/internal/streamtext"
 )
 
 // Chat sends a non-streaming chat request to Ollama.
@@ -20,6 +22,11 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 		return nil, err
 	}
 
+	params, err := a.inlineImageURLs(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
 	requestTemplate, messages, serverTools, clientTools, maxLoopCount, err := a.buildRequestTemplate(params)
 	if err != nil {
 		return nil, err
@@ -33,8 +40,13 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 		request.Messages = messages
 		stream := false
 		request.Stream = &stream
+		if err := a.sizeContext(ctx, &request); err != nil {
+			return nil, err
+		}
 
-		response, err := a.postChat(ctx, &request)
+		response, err := withAutoPull(ctx, a, func() (*chatResponse, error) {
+			return a.postChat(ctx, &request)
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -45,12 +57,14 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 		if len(response.Message.ToolCalls) == 0 {
 			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: assistantText})
 			return &core.ChatResult{
-				Text:         assistantText,
-				Reasoning:    joinReasoningParts(reasoningParts),
-				Messages:     append([]core.MessageUnion(nil), conversation...),
-				ToolCalls:    nil,
-				FinishReason: nonEmpty(response.DoneReason, "stop"),
-				Usage:        toCoreChatUsage(response),
+				Text:            assistantText,
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				ToolCalls:       nil,
+				FinishReason:    toCoreFinishReason(response.DoneReason),
+				RawFinishReason: response.DoneReason,
+				Usage:           toCoreChatUsage(response),
+				RequestID:       response.RequestID,
 			}, nil
 		}
 
@@ -100,12 +114,14 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 
 		if len(pendingClientCalls) > 0 {
 			return &core.ChatResult{
-				Text:         "",
-				Reasoning:    joinReasoningParts(reasoningParts),
-				Messages:     append([]core.MessageUnion(nil), conversation...),
-				ToolCalls:    pendingClientCalls,
-				FinishReason: "tool_calls",
-				Usage:        toCoreChatUsage(response),
+				Text:            "",
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				ToolCalls:       pendingClientCalls,
+				FinishReason:    core.FinishReasonToolCalls,
+				RawFinishReason: response.DoneReason,
+				Usage:           toCoreChatUsage(response),
+				RequestID:       response.RequestID,
 			}, nil
 		}
 	}
@@ -115,145 +131,206 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 
 // ChatStream sends a streaming chat request to Ollama.
 //
-// When tools or structured output are configured, ChatStream emits chunks derived
-// from a non-streaming Chat call to preserve consistent behavior.
+// Structured output streams as StreamChunkPartialJSON chunks. Tool calls are
+// emitted as they arrive; server tools run between streamed turns, and the
+// stream ends with FinishReason "tool_calls" when client tools must be run.
 func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
 	if err := a.validate(); err != nil {
 		return nil, err
 	}
 
-	request, messages, serverTools, clientTools, _, err := a.buildRequestTemplate(params)
+	params, err := a.inlineImageURLs(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
+	requestTemplate, messages, serverTools, clientTools, maxLoopCount, err := a.buildRequestTemplate(params)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
 	out := make(chan core.StreamChunk, 64)
 
 	go func() {
 		defer close(out)
 
-		if len(serverTools) > 0 || len(clientTools) > 0 || (params != nil && params.Output != nil) {
-			result, err := a.Chat(ctx, params)
+		reasoningParts := make([]string, 0, 4)
+
+		for range maxLoopCount {
+			request := requestTemplate
+			request.Messages = messages
+			stream := true
+			request.Stream = &stream
+			if err := a.sizeContext(ctx, &request); err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: core.RequestIDOf(err)}
+				return
+			}
+
+			turn, err := withAutoPull(ctx, a, func() (*streamedTurn, error) {
+				return a.streamChatTurn(ctx, &request, out)
+			})
 			if err != nil {
-				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error()}
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: core.RequestIDOf(err)}
 				return
 			}
 
-			emitChunksFromResult(out, params, result)
-			out <- core.StreamChunk{
-				Type:         core.StreamChunkDone,
-				FinishReason: nonEmpty(result.FinishReason, defaultFinishReason(result)),
-				Reasoning:    result.Reasoning,
-				Usage:        result.Usage,
+			reasoningParts = appendReasoningPart(reasoningParts, turn.message.Thinking)
+
+			if len(turn.calls) == 0 {
+				out <- core.StreamChunk{
+					Type:            core.StreamChunkDone,
+					FinishReason:    toCoreFinishReason(turn.finishReason),
+					RawFinishReason: turn.finishReason,
+					Reasoning:       joinReasoningParts(reasoningParts),
+					Usage:           turn.usage,
+					RequestID:       turn.requestID,
+				}
+				return
 			}
-			return
-		}
 
-		request.Messages = messages
-		stream := true
-		request.Stream = &stream
+			messages = append(messages, turn.message)
+
+			pendingClientCalls := 0
+			for _, call := range turn.calls {
+				if serverTool, ok := serverTools[call.Name]; ok {
+					result, callErr := serverTool.Handler(call.Arguments)
+					if callErr != nil {
+						result = "tool_error: " + callErr.Error()
+					}
+
+					messages = append(messages, message{
+						Role:       "tool",
+						ToolCallID: call.ID,
+						ToolName:   call.Name,
+						Content:    result,
+					})
+					out <- core.StreamChunk{Type: core.StreamChunkToolResult, ToolCallID: call.ID, Content: result}
+					continue
+				}
 
-		url := strings.TrimRight(a.baseURL(), "/") + "/api/chat"
-		body, err := json.Marshal(request)
-		if err != nil {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("ollama: marshal stream request: %v", err)}
-			return
-		}
+				if _, ok := clientTools[call.Name]; ok {
+					pendingClientCalls++
+					continue
+				}
 
-		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-		if err != nil {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("ollama: build stream request: %v", err)}
-			return
-		}
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("ollama: tool %q was requested but not registered", call.Name)}
+				return
+			}
 
-		httpReq.Header.Set("Content-Type", "application/json")
-		httpReq.Header.Set("Accept", "application/x-ndjson")
-		if strings.TrimSpace(a.APIKey) != "" {
-			httpReq.Header.Set("Authorization", "Bearer "+strings.TrimSpace(a.APIKey))
+			if pendingClientCalls > 0 {
+				out <- core.StreamChunk{
+					Type:            core.StreamChunkDone,
+					FinishReason:    core.FinishReasonToolCalls,
+					RawFinishReason: turn.finishReason,
+					Reasoning:       joinReasoningParts(reasoningParts),
+					Usage:           turn.usage,
+					RequestID:       turn.requestID,
+				}
+				return
+			}
 		}
 
-		httpResp, err := a.client().Do(httpReq)
-		if err != nil {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("ollama: stream request failed: %v", err)}
-			return
-		}
-		defer httpResp.Body.Close()
+		out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("ollama: reached max tool loop count (%d)", maxLoopCount)}
+	}()
 
-		if httpResp.StatusCode >= http.StatusBadRequest {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: decodeAPIError(httpResp).Error()}
-			return
-		}
+	return core.TimeStream(ctx, out, start), nil
+}
 
-		scanner := bufio.NewScanner(httpResp.Body)
-		scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+// streamedTurn is one streamed assistant response, accumulated for the next
+// request of the tool loop.
+type streamedTurn struct {
+	message      message
+	calls        []core.ToolCall
+	finishReason string
+	usage        *core.Usage
+	requestID    string
+}
 
-		content := ""
-		reasoning := ""
-		finishReason := ""
-		var usage *core.Usage
+// streamChatTurn streams one chat request, forwarding reasoning, content, and
+// tool call chunks to out as they arrive.
+func (a *Adapter) streamChatTurn(ctx context.Context, request *chatRequest, out chan<- core.StreamChunk) (*streamedTurn, error) {
+	httpResp, err := a.transport().Do(ctx, httpclient.Request{Path: "/api/chat", Name: "stream", Body: request, Header: ndjson})
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
 
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line == "" {
-				continue
-			}
+	scanner, release := bufpool.NewScanner(httpResp.Body, 8*1024*1024)
+	defer release()
 
-			var event chatResponse
-			if err := json.Unmarshal([]byte(line), &event); err != nil {
-				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("ollama: decode stream event: %v", err)}
-				return
-			}
+	turn := &streamedTurn{message: message{Role: "assistant"}, requestID: httpclient.RequestID(httpResp.Header)}
+	var content, reasoning streamtext.Builder
+	var partial partialjson.Assembler
 
-			usage = toCoreChatUsage(&event)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
 
-			nextReasoning, reasoningDelta := appendStreamSegment(reasoning, event.Message.Thinking)
-			reasoning = nextReasoning
-			if reasoningDelta != "" {
-				out <- core.StreamChunk{
-					Type:      core.StreamChunkReasoning,
-					Role:      core.RoleAssistant,
-					Delta:     reasoningDelta,
-					Reasoning: reasoning,
-				}
-			}
+		var event chatResponse
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return nil, fmt.Errorf("ollama: decode stream event: %w", err)
+		}
 
-			nextContent, delta := appendStreamSegment(content, event.Message.Content)
-			content = nextContent
-			if delta != "" {
-				out <- core.StreamChunk{
-					Type:    core.StreamChunkContent,
-					Role:    core.RoleAssistant,
-					Delta:   delta,
-					Content: content,
-				}
+		turn.usage = toCoreChatUsage(&event)
+
+		if reasoningDelta := reasoning.Append(event.Message.Thinking); reasoningDelta != "" {
+			out <- core.StreamChunk{
+				Type:      core.StreamChunkReasoning,
+				Role:      core.RoleAssistant,
+				Delta:     reasoningDelta,
+				Reasoning: reasoning.String(),
 			}
+		}
 
-			if event.Done {
-				finishReason = nonEmpty(event.DoneReason, "stop")
-				out <- core.StreamChunk{
-					Type:         core.StreamChunkDone,
-					FinishReason: finishReason,
-					Reasoning:    reasoning,
-					Usage:        usage,
-				}
-				return
+		delta := content.Append(event.Message.Content)
+		if delta != "" && len(request.Format) > 0 {
+			partial.WriteString(delta)
+			out <- core.StreamChunk{
+				Type:    core.StreamChunkPartialJSON,
+				Role:    core.RoleAssistant,
+				Delta:   delta,
+				Content: partial.Partial(),
+			}
+		} else if delta != "" {
+			out <- core.StreamChunk{
+				Type:    core.StreamChunkContent,
+				Role:    core.RoleAssistant,
+				Delta:   delta,
+				Content: content.String(),
 			}
 		}
 
-		if err := scanner.Err(); err != nil {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("ollama: stream read failed: %v", err)}
-			return
+		for _, call := range event.Message.ToolCalls {
+			if strings.TrimSpace(call.ID) == "" {
+				call.ID = fmt.Sprintf("call_%d", len(turn.message.ToolCalls)+1)
+			}
+			coreCalls, err := toCoreToolCalls([]toolCall{call})
+			if err != nil {
+				return nil, err
+			}
+			turn.message.ToolCalls = append(turn.message.ToolCalls, call)
+			turn.calls = append(turn.calls, coreCalls[0])
+
+			emitted := coreCalls[0]
+			out <- core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &emitted}
 		}
 
-		out <- core.StreamChunk{
-			Type:         core.StreamChunkDone,
-			FinishReason: nonEmpty(finishReason, "stop"),
-			Reasoning:    reasoning,
-			Usage:        usage,
+		if event.Done {
+			turn.finishReason = event.DoneReason
+			break
 		}
-	}()
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ollama: stream read failed: %w", err)
+	}
 
-	return out, nil
+	turn.message.Content = content.String()
+	turn.message.Thinking = reasoning.String()
+	return turn, nil
 }
 
 func (a *Adapter) buildRequestTemplate(params *core.ChatParams) (chatRequest, []message, map[string]core.ServerTool, map[string]struct{}, int, error) {
@@ -275,48 +352,31 @@ func (a *Adapter) buildRequestTemplate(params *core.ChatParams) (chatRequest, []
 	request := chatRequest{
 		Model:   a.Model,
 		Tools:   tools,
-		Options: requestOptions(params),
+		Options: a.withRuntimeDefaults(requestOptions(params)),
 		Think:   thinkValue(params),
+
+		KeepAlive: a.keepAlive(paramsModelOptions(params)),
 	}
 	if len(format) > 0 {
 		request.Format = format
 	}
+	// Ollama has no tool_choice; "none" is honored by not offering tools,
+	// while required and named choices are left to the model.
+	if params != nil && params.ToolChoice != nil && strings.TrimSpace(params.ToolChoice.Name) == "" && strings.TrimSpace(params.ToolChoice.Mode) == core.ToolChoiceNone {
+		request.Tools = nil
+	}
 
 	return request, messages, serverTools, clientTools, maxLoops(params, len(serverTools) > 0), nil
 }
 
 func (a *Adapter) postChat(ctx context.Context, request *chatRequest) (*chatResponse, error) {
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ollama: marshal request: %w", err)
-	}
-
-	url := strings.TrimRight(a.baseURL(), "/") + "/api/chat"
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("ollama: build request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
-	if strings.TrimSpace(a.APIKey) != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+strings.TrimSpace(a.APIKey))
-	}
-
-	httpResp, err := a.client().Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("ollama: request failed: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode >= http.StatusBadRequest {
-		return nil, decodeAPIError(httpResp)
-	}
-
 	var response chatResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("ollama: decode response: %w", err)
+	httpResp, err := a.transport().Send(ctx, httpclient.Request{Path: "/api/chat", Body: request}, &response)
+	if err != nil {
+		return nil, err
 	}
+	response.Duration = httpResp.Duration
+	response.RequestID = httpclient.RequestID(httpResp.Header)
 
 	return &response, nil
 }
@@ -328,6 +388,13 @@ func paramsOutput(params *core.ChatParams) *core.Schema {
 	return params.Output
 }
 
+func paramsModelOptions(params *core.ChatParams) map[string]any {
+	if params == nil {
+		return nil
+	}
+	return params.ModelOptions
+}
+
 func cloneCoreMessages(params *core.ChatParams) []core.MessageUnion {
 	if params == nil || len(params.Messages) == 0 {
 		return nil
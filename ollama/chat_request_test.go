@@ -0,0 +1,136 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatRequestSendsKeepAlive(t *testing.T) {
+	t.Parallel()
+
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		requests = append(requests, request)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"llama3.2","message":{"role":"assistant","content":"ok"},"done":true}`))
+	}))
+	defer server.Close()
+
+	adapter := New("llama3.2", WithBaseURL(server.URL), WithKeepAlive(10*time.Minute))
+	messages := []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}}
+
+	if _, err := adapter.Chat(context.Background(), &core.ChatParams{Messages: messages}); err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if _, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages:     messages,
+		ModelOptions: map[string]any{"keep_alive": -1, "num_ctx": 8192},
+	}); err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	if requests[0]["keep_alive"] != "10m0s" {
+		t.Fatalf("expected adapter keep_alive, got %#v", requests[0]["keep_alive"])
+	}
+	if requests[1]["keep_alive"] != float64(-1) {
+		t.Fatalf("expected per-request keep_alive override, got %#v", requests[1]["keep_alive"])
+	}
+	options, _ := requests[1]["options"].(map[string]any)
+	if _, ok := options["keep_alive"]; ok {
+		t.Fatalf("keep_alive must not be sent as a model option: %#v", options)
+	}
+	if options["num_ctx"] != float64(8192) {
+		t.Fatalf("expected num_ctx option, got %#v", options)
+	}
+}
+
+func TestEmbedRequestSendsKeepAlive(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"nomic-embed-text","embeddings":[[0.1,0.2]]}`))
+	}))
+	defer server.Close()
+
+	adapter := New("nomic-embed-text", WithBaseURL(server.URL), WithKeepAlive(time.Minute))
+	_, err := adapter.Embed(context.Background(), &core.EmbedParams{
+		Input:        "hello",
+		ModelOptions: map[string]any{"keepAlive": 30 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("embed returned error: %v", err)
+	}
+	if request["keep_alive"] != "30s" {
+		t.Fatalf("expected per-request keep_alive override, got %#v", request["keep_alive"])
+	}
+	if _, ok := request["options"]; ok {
+		t.Fatalf("expected no options, got %#v", request["options"])
+	}
+}
+
+func TestChatReportsProxyRequestID(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "req_proxy")
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if request["model"] == "broken" {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`{"error":"upstream unavailable"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"hi"},"done":true,"done_reason":"stop"}` + "\n"))
+	}))
+	defer server.Close()
+
+	params := &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	}
+
+	result, err := New("llama3.2", WithBaseURL(server.URL)).Chat(context.Background(), params)
+	if err != nil || result.RequestID != "req_proxy" {
+		t.Fatalf("chat = %#v, %v", result, err)
+	}
+
+	stream, err := New("llama3.2", WithBaseURL(server.URL)).ChatStream(context.Background(), params)
+	if err != nil {
+		t.Fatalf("chat stream: %v", err)
+	}
+	var done core.StreamChunk
+	for done = range stream {
+	}
+	if done.Type != core.StreamChunkDone || done.RequestID != "req_proxy" {
+		t.Fatalf("unexpected done chunk: %#v", done)
+	}
+
+	_, err = New("broken", WithBaseURL(server.URL)).Chat(context.Background(), params)
+	var apiErr *core.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway || apiErr.RequestID != "req_proxy" || !apiErr.Retryable {
+		t.Fatalf("expected API error with request id, got %#v", err)
+	}
+	if apiErr.Error() != "ollama: API error: upstream unavailable" {
+		t.Fatalf("unexpected error message: %q", apiErr.Error())
+	}
+}
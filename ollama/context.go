@@ -0,0 +1,191 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/m43i/go-ai/core"
+)
+
+const (
+	// defaultContextReserve is the room left for the reply when num_predict
+	// is not set.
+	defaultContextReserve = 1024
+	// contextSizeStep rounds num_ctx so small prompt changes do not force the
+	// model to reload with a new context size.
+	contextSizeStep = 2048
+)
+
+// contextLengthCache remembers each model's context length reported by
+// /api/show.
+type contextLengthCache struct {
+	mu      sync.Mutex
+	lengths map[string]int64
+}
+
+// contextLength returns the model's maximum context length in tokens.
+func (a *Adapter) contextLength(ctx context.Context) (int64, error) {
+	cache := a.contextLengths
+	if cache != nil {
+		cache.mu.Lock()
+		length, ok := cache.lengths[a.Model]
+		cache.mu.Unlock()
+		if ok {
+			return length, nil
+		}
+	}
+
+	info, err := a.Show(ctx, a.Model)
+	if err != nil {
+		return 0, err
+	}
+	if info.ContextLength <= 0 {
+		return 0, fmt.Errorf("ollama: model %s did not report a context length", a.Model)
+	}
+
+	if cache != nil {
+		cache.mu.Lock()
+		if cache.lengths == nil {
+			cache.lengths = make(map[string]int64)
+		}
+		cache.lengths[a.Model] = info.ContextLength
+		cache.mu.Unlock()
+	}
+	return info.ContextLength, nil
+}
+
+// sizeContext checks and sizes the context window of request.
+//
+// With AutoContext it sets num_ctx to fit the prompt plus the reply, capped at
+// the model's context length, and fails when the prompt alone does not fit;
+// an explicit num_ctx is left unchanged. With CheckPromptSize it fails when
+// the prompt plus num_predict does not fit num_ctx or the model's context
+// length. Failures are *core.ContextLengthExceededError.
+func (a *Adapter) sizeContext(ctx context.Context, request *chatRequest) error {
+	if !a.AutoContext && !a.CheckPromptSize {
+		return nil
+	}
+	numCtx, explicit := optionInt(request.Options["num_ctx"])
+	if explicit && !a.CheckPromptSize {
+		return nil
+	}
+
+	limit := numCtx
+	if !explicit {
+		var err error
+		if limit, err = a.contextLength(ctx); err != nil {
+			return err
+		}
+	}
+
+	prompt := estimateTokens(request)
+	var output int64
+	if predict, ok := optionInt(request.Options["num_predict"]); ok && predict > 0 {
+		output = predict
+	}
+	if prompt >= limit || (a.CheckPromptSize && prompt+output > limit) {
+		return &core.ContextLengthExceededError{Model: a.Model, PromptTokens: prompt, OutputTokens: output, ContextWindow: limit}
+	}
+	if explicit || !a.AutoContext {
+		return nil
+	}
+
+	reserve := int64(defaultContextReserve)
+	if output > 0 {
+		reserve = output
+	}
+
+	size := (prompt + reserve + contextSizeStep - 1) / contextSizeStep * contextSizeStep
+	size = min(size, limit)
+
+	options := make(map[string]any, len(request.Options)+1)
+	for key, value := range request.Options {
+		options[key] = value
+	}
+	options["num_ctx"] = size
+	request.Options = options
+	return nil
+}
+
+var _ core.TokenCounterAdapter = (*Adapter)(nil)
+
+// CountTokens estimates the number of prompt tokens params would use. Ollama
+// has no token counting endpoint, so the estimate uses the same four
+// characters per token heuristic as WithAutoContext.
+func (a *Adapter) CountTokens(ctx context.Context, params *core.ChatParams) (int64, error) {
+	if err := a.validate(); err != nil {
+		return 0, err
+	}
+	params, err := a.inlineImageURLs(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+
+	request, messages, _, _, _, err := a.buildRequestTemplate(params)
+	if err != nil {
+		return 0, err
+	}
+	request.Messages = messages
+	return estimateTokens(&request), nil
+}
+
+// estimateTokens approximates the prompt size of request at four characters
+// per token, which errs on the high side for English text and code.
+func estimateTokens(request *chatRequest) int64 {
+	chars := 0
+	for _, message := range request.Messages {
+		chars += utf8.RuneCountInString(message.Content) + utf8.RuneCountInString(message.Thinking) + 16
+		for _, call := range message.ToolCalls {
+			arguments, _ := json.Marshal(call.Function.Arguments)
+			chars += len(call.Function.Name) + len(arguments)
+		}
+	}
+	if len(request.Tools) > 0 {
+		tools, _ := json.Marshal(request.Tools)
+		chars += len(tools)
+	}
+	if len(request.Format) > 0 {
+		chars += len(request.Format)
+	}
+	return int64((chars + 3) / 4)
+}
+
+func optionInt(value any) (int64, bool) {
+	switch typed := value.(type) {
+	case int:
+		return int64(typed), true
+	case int32:
+		return int64(typed), true
+	case int64:
+		return typed, true
+	case float64:
+		return int64(typed), true
+	case json.Number:
+		n, err := typed.Int64()
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// contextLengthFromModelInfo finds the "<architecture>.context_length" entry
+// of /api/show model_info.
+func contextLengthFromModelInfo(info map[string]any) int64 {
+	if architecture, ok := info["general.architecture"].(string); ok {
+		if length, ok := optionInt(info[architecture+".context_length"]); ok {
+			return length
+		}
+	}
+	for key, value := range info {
+		if strings.HasSuffix(key, ".context_length") {
+			if length, ok := optionInt(value); ok {
+				return length
+			}
+		}
+	}
+	return 0
+}
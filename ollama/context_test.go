@@ -0,0 +1,139 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func newContextServer(t *testing.T, showCalls *atomic.Int32, numCtx *atomic.Value) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/show":
+			showCalls.Add(1)
+			_, _ = w.Write([]byte(`{"details":{"family":"llama"},"model_info":{"general.architecture":"llama","llama.context_length":8192},"capabilities":["completion","tools"]}`))
+		case "/api/chat":
+			var request chatRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			numCtx.Store(request.Options["num_ctx"])
+			_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"ok"},"done":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestChatAutoContextSizesNumCtx(t *testing.T) {
+	t.Parallel()
+
+	var showCalls atomic.Int32
+	var numCtx atomic.Value
+	server := newContextServer(t, &showCalls, &numCtx)
+	defer server.Close()
+
+	adapter := New("llama3.2", WithBaseURL(server.URL), WithAutoContext())
+	chat := func(content string, modelOptions map[string]any) error {
+		_, err := adapter.Chat(context.Background(), &core.ChatParams{
+			Messages:     []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: content}},
+			ModelOptions: modelOptions,
+		})
+		return err
+	}
+
+	if err := chat("hi", nil); err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if numCtx.Load() != float64(2048) {
+		t.Fatalf("expected num_ctx 2048, got %#v", numCtx.Load())
+	}
+
+	if err := chat(strings.Repeat("word ", 5000), nil); err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if numCtx.Load() != float64(8192) {
+		t.Fatalf("expected num_ctx capped at 8192, got %#v", numCtx.Load())
+	}
+
+	if err := chat("hi", map[string]any{"num_ctx": 512}); err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if numCtx.Load() != float64(512) {
+		t.Fatalf("expected explicit num_ctx to be kept, got %#v", numCtx.Load())
+	}
+
+	if showCalls.Load() != 1 {
+		t.Fatalf("expected context length to be cached, got %d show calls", showCalls.Load())
+	}
+}
+
+func TestChatAutoContextRejectsOversizedPrompt(t *testing.T) {
+	t.Parallel()
+
+	var showCalls atomic.Int32
+	var numCtx atomic.Value
+	server := newContextServer(t, &showCalls, &numCtx)
+	defer server.Close()
+
+	adapter := New("llama3.2", WithBaseURL(server.URL), WithAutoContext())
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: strings.Repeat("x", 40000)}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "exceeds the 8192-token context window") {
+		t.Fatalf("expected context window error, got %v", err)
+	}
+	if numCtx.Load() != nil {
+		t.Fatal("expected the oversized prompt not to be sent")
+	}
+}
+
+func TestShowReportsContextLength(t *testing.T) {
+	t.Parallel()
+
+	var showCalls atomic.Int32
+	var numCtx atomic.Value
+	server := newContextServer(t, &showCalls, &numCtx)
+	defer server.Close()
+
+	info, err := New("llama3.2", WithBaseURL(server.URL)).Show(context.Background(), "llama3.2")
+	if err != nil {
+		t.Fatalf("show returned error: %v", err)
+	}
+	if info.ContextLength != 8192 || info.Family != "llama" || len(info.Capabilities) != 2 {
+		t.Fatalf("unexpected show result %#v", info)
+	}
+}
+
+func TestPromptSizeCheckUsesExplicitNumCtx(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request to %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	adapter := New("llama-test", WithBaseURL(server.URL), WithPromptSizeCheck())
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages:     []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: strings.Repeat("word ", 300)}},
+		ModelOptions: map[string]any{"num_ctx": 512, "num_predict": 256},
+	})
+
+	var exceeded *core.ContextLengthExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("expected ContextLengthExceededError, got %v", err)
+	}
+	if exceeded.ContextWindow != 512 || exceeded.OutputTokens != 256 {
+		t.Fatalf("unexpected error fields %#v", exceeded)
+	}
+}
@@ -135,6 +135,13 @@ func contentAndImages(parts []core.ContentPart) (string, []string, error) {
 			return "", nil, fmt.Errorf("content part at index %d: ollama: audio content is not supported", i)
 		case core.DocumentPart, *core.DocumentPart:
 			return "", nil, fmt.Errorf("content part at index %d: ollama: document content is not supported", i)
+		case core.FilePart, *core.FilePart:
+			return "", nil, fmt.Errorf("content part at index %d: ollama: file content is not supported", i)
+		case core.RawPart, *core.RawPart:
+			// Ollama messages have no content array to inject raw parts into.
+			if raw, ok := rawPartProvider(part); ok && strings.EqualFold(raw, providerName) {
+				return "", nil, fmt.Errorf("content part at index %d: ollama: raw content parts are not supported", i)
+			}
 		default:
 			return "", nil, fmt.Errorf("content part at index %d: unsupported content part type %T", i, part)
 		}
@@ -143,6 +150,19 @@ func contentAndImages(parts []core.ContentPart) (string, []string, error) {
 	return textBuilder.String(), images, nil
 }
 
+func rawPartProvider(part core.ContentPart) (string, bool) {
+	switch typed := part.(type) {
+	case core.RawPart:
+		return strings.TrimSpace(typed.Provider), true
+	case *core.RawPart:
+		if typed == nil {
+			return "", false
+		}
+		return strings.TrimSpace(typed.Provider), true
+	}
+	return "", false
+}
+
 func imageDataFromSource(source core.Source) (string, error) {
 	if source == nil {
 		return "", errors.New("image source is required")
@@ -158,7 +178,7 @@ func imageDataFromSource(source core.Source) (string, error) {
 		return dataImageSource(*typed)
 
 	case core.URLSource, *core.URLSource:
-		return "", errors.New("image URL source is not supported (use DataSource with base64 image data)")
+		return "", errImageURLNotSupported
 	}
 
 	return "", fmt.Errorf("unsupported image source type %T", source)
@@ -490,12 +510,16 @@ func requestOptions(params *core.ChatParams) map[string]any {
 	if params.TopP != nil {
 		options["top_p"] = *params.TopP
 	}
-	for key, value := range params.ModelOptions {
-		key = strings.TrimSpace(key)
-		if key != "" && value != nil {
-			options[key] = value
-		}
+	if params.TopK != nil {
+		options["top_k"] = *params.TopK
+	}
+	if params.Seed != nil {
+		options["seed"] = *params.Seed
 	}
+	if stop := stopSequences(params); len(stop) > 0 {
+		options["stop"] = stop
+	}
+	mergeModelOptions(options, params.ModelOptions)
 
 	if len(options) == 0 {
 		return nil
@@ -504,6 +528,78 @@ func requestOptions(params *core.ChatParams) map[string]any {
 	return options
 }
 
+// nativeOptions returns the ModelOptions that belong in the options map for
+// requests without typed sampling fields, such as embed and generate.
+func nativeOptions(modelOptions map[string]any) map[string]any {
+	options := map[string]any{}
+	mergeModelOptions(options, modelOptions)
+	if len(options) == 0 {
+		return nil
+	}
+	return options
+}
+
+// mergeModelOptions copies ModelOptions into the Ollama options map, such as
+// num_ctx, repeat_penalty, seed, or mirostat. Keys may be snake_case or
+// camelCase. A nested "options" map is merged first, then a RuntimeOptions
+// value under "runtime", so flat keys win.
+func mergeModelOptions(options, modelOptions map[string]any) {
+	if nested, ok := modelOptions["options"].(map[string]any); ok {
+		for key, value := range nested {
+			key = strings.TrimSpace(key)
+			if key != "" && value != nil {
+				options[optionKey(key)] = value
+			}
+		}
+	}
+
+	runtime := runtimeOptionsValue(modelOptions["runtime"])
+	runtime.apply(options, true)
+
+	for key, value := range modelOptions {
+		key = strings.TrimSpace(key)
+		if key == "" || key == "options" || value == nil || isKeepAliveKey(key) {
+			continue
+		}
+		if key == "runtime" && runtime != nil {
+			continue
+		}
+		options[optionKey(key)] = value
+	}
+}
+
+func optionKey(key string) string {
+	if strings.Contains(key, "_") {
+		return key
+	}
+	return camelToSnake(key)
+}
+
+func stopSequences(params *core.ChatParams) []string {
+	if params == nil {
+		return nil
+	}
+
+	var out []string
+	for _, stop := range params.StopSequences {
+		if stop != "" {
+			out = append(out, stop)
+		}
+	}
+	return out
+}
+
+// isKeepAliveKey reports whether a ModelOptions key sets the top-level
+// keep_alive field rather than a model option.
+func isKeepAliveKey(key string) bool {
+	switch strings.TrimSpace(key) {
+	case "keep_alive", "keepAlive":
+		return true
+	default:
+		return false
+	}
+}
+
 func thinkValue(params *core.ChatParams) any {
 	if params == nil {
 		return nil
@@ -1,6 +1,7 @@
 package ollama
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
@@ -169,3 +170,99 @@ func TestEmbeddingRequestFromManyRejectsEmptyInput(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestRequestOptionsPassesThroughNativeOptions(t *testing.T) {
+	t.Parallel()
+
+	topP := 0.9
+	options := requestOptions(&core.ChatParams{
+		TopP:          &topP,
+		StopSequences: []string{"<|end|>", ""},
+		ModelOptions: map[string]any{
+			"numCtx":         8192,
+			"repeat_penalty": 1.1,
+			"seed":           42,
+			"keep_alive":     "5m",
+			"options":        map[string]any{"mirostat": 2, "seed": 7},
+		},
+	})
+
+	expected := map[string]any{
+		"top_p":          0.9,
+		"stop":           []string{"<|end|>"},
+		"num_ctx":        8192,
+		"repeat_penalty": 1.1,
+		"seed":           42,
+		"mirostat":       2,
+	}
+	if !reflect.DeepEqual(options, expected) {
+		t.Fatalf("unexpected options:\n got %#v\nwant %#v", options, expected)
+	}
+}
+
+func TestRequestOptionsMapsSamplingFields(t *testing.T) {
+	t.Parallel()
+
+	topP := 0.8
+	topK := int64(40)
+	seed := int64(7)
+	options := requestOptions(&core.ChatParams{TopP: &topP, TopK: &topK, Seed: &seed})
+
+	expected := map[string]any{"top_p": 0.8, "top_k": int64(40), "seed": int64(7)}
+	if !reflect.DeepEqual(options, expected) {
+		t.Fatalf("unexpected options:\n got %#v\nwant %#v", options, expected)
+	}
+}
+
+func TestRuntimeOptionsMergeWithAdapterDefaults(t *testing.T) {
+	t.Parallel()
+
+	threads, gpuLayers, batch, mainGPU := 8, 0, 256, 1
+	adapter := New("llama3.2", WithRuntimeOptions(RuntimeOptions{NumThread: &threads, MainGPU: &mainGPU}))
+
+	options := adapter.withRuntimeDefaults(requestOptions(&core.ChatParams{
+		ModelOptions: map[string]any{
+			"runtime":   RuntimeOptions{NumGPU: &gpuLayers, NumBatch: &batch},
+			"num_batch": 512,
+			"options":   map[string]any{"num_gpu": 99},
+		},
+	}))
+
+	expected := map[string]any{
+		"num_thread": 8,
+		"num_gpu":    0,
+		"main_gpu":   1,
+		"num_batch":  512,
+	}
+	if !reflect.DeepEqual(options, expected) {
+		t.Fatalf("unexpected options:\n got %#v\nwant %#v", options, expected)
+	}
+}
+
+func TestToolChoiceNoneOmitsTools(t *testing.T) {
+	t.Parallel()
+
+	adapter := New("ollama-test")
+	params := &core.ChatParams{
+		Messages:   []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		Tools:      []core.ToolUnion{core.ClientTool{Name: "weather"}},
+		ToolChoice: &core.ToolChoice{Mode: core.ToolChoiceNone},
+	}
+
+	request, _, _, _, _, err := adapter.buildRequestTemplate(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(request.Tools) != 0 {
+		t.Fatalf("expected no tools, got %#v", request.Tools)
+	}
+
+	params.ToolChoice = &core.ToolChoice{Mode: core.ToolChoiceRequired}
+	request, _, _, _, _, err = adapter.buildRequestTemplate(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(request.Tools) != 1 {
+		t.Fatalf("expected tools to be offered, got %#v", request.Tools)
+	}
+}
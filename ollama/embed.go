@@ -1,15 +1,13 @@
 package ollama
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
 )
 
 // Embed creates one embedding vector for params.Input.
@@ -23,7 +21,12 @@ func (a *Adapter) Embed(ctx context.Context, params *core.EmbedParams) (*core.Em
 		return nil, err
 	}
 
-	response, err := a.postEmbed(ctx, &request)
+	request.KeepAlive = a.keepAlive(params.ModelOptions)
+	request.Options = a.withRuntimeDefaults(request.Options)
+
+	response, err := withAutoPull(ctx, a, func() (*embedResponse, error) {
+		return a.postEmbed(ctx, &request)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +35,9 @@ func (a *Adapter) Embed(ctx context.Context, params *core.EmbedParams) (*core.Em
 	if err != nil {
 		return nil, err
 	}
+	if err := checkDimensions(vectors, params.Dimensions); err != nil {
+		return nil, err
+	}
 
 	return &core.EmbedResult{
 		Embedding: vectors[0],
@@ -50,7 +56,12 @@ func (a *Adapter) EmbedMany(ctx context.Context, params *core.EmbedManyParams) (
 		return nil, err
 	}
 
-	response, err := a.postEmbed(ctx, &request)
+	request.KeepAlive = a.keepAlive(params.ModelOptions)
+	request.Options = a.withRuntimeDefaults(request.Options)
+
+	response, err := withAutoPull(ctx, a, func() (*embedResponse, error) {
+		return a.postEmbed(ctx, &request)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +70,9 @@ func (a *Adapter) EmbedMany(ctx context.Context, params *core.EmbedManyParams) (
 	if err != nil {
 		return nil, err
 	}
+	if err := checkDimensions(vectors, params.Dimensions); err != nil {
+		return nil, err
+	}
 
 	return &core.EmbedManyResult{
 		Embeddings: vectors,
@@ -84,6 +98,7 @@ func embeddingRequestFromSingle(model string, params *core.EmbedParams) (embedRe
 		Model:      model,
 		Input:      input,
 		Dimensions: params.Dimensions,
+		Options:    nativeOptions(params.ModelOptions),
 	}, 1, nil
 }
 
@@ -112,42 +127,15 @@ func embeddingRequestFromMany(model string, params *core.EmbedManyParams) (embed
 		Model:      model,
 		Input:      inputs,
 		Dimensions: params.Dimensions,
+		Options:    nativeOptions(params.ModelOptions),
 	}, len(inputs), nil
 }
 
 func (a *Adapter) postEmbed(ctx context.Context, request *embedRequest) (*embedResponse, error) {
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ollama: marshal embed request: %w", err)
-	}
-
-	url := strings.TrimRight(a.baseURL(), "/") + "/api/embed"
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("ollama: build embed request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
-	if strings.TrimSpace(a.APIKey) != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+strings.TrimSpace(a.APIKey))
-	}
-
-	httpResp, err := a.client().Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("ollama: embed request failed: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode >= http.StatusBadRequest {
-		return nil, decodeAPIError(httpResp)
-	}
-
 	var response embedResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("ollama: decode embed response: %w", err)
+	if _, err := a.transport().Send(ctx, httpclient.Request{Path: "/api/embed", Name: "embed", Body: request}, &response); err != nil {
+		return nil, err
 	}
-
 	return &response, nil
 }
 
@@ -169,3 +157,17 @@ func orderedEmbeddingVectors(data [][]float64, expectedCount int) ([][]float64,
 
 	return out, nil
 }
+
+// checkDimensions verifies that every vector has the requested length, since
+// models that ignore dimensions return their native size instead.
+func checkDimensions(vectors [][]float64, dimensions *int64) error {
+	if dimensions == nil {
+		return nil
+	}
+	for i, vector := range vectors {
+		if int64(len(vector)) != *dimensions {
+			return &core.EmbeddingDimensionError{Expected: *dimensions, Actual: int64(len(vector)), Index: i}
+		}
+	}
+	return nil
+}
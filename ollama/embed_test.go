@@ -0,0 +1,43 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestEmbedManyValidatesDimensions(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"embeddings":[[0.1,0.2],[0.1,0.2,0.3]]}`))
+	}))
+	defer server.Close()
+
+	dimensions := int64(2)
+	_, err := New("nomic-embed-text", WithBaseURL(server.URL)).EmbedMany(context.Background(), &core.EmbedManyParams{
+		Inputs:     []string{"a", "b"},
+		Dimensions: &dimensions,
+	})
+
+	if request["dimensions"] != float64(2) {
+		t.Fatalf("expected dimensions in request, got %#v", request)
+	}
+	var mismatch *core.EmbeddingDimensionError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected EmbeddingDimensionError, got %T %v", err, err)
+	}
+	if mismatch.Index != 1 || mismatch.Expected != 2 || mismatch.Actual != 3 {
+		t.Fatalf("unexpected mismatch %#v", mismatch)
+	}
+}
@@ -0,0 +1,97 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+// GenerateParams describes a single-prompt completion (POST /api/generate).
+type GenerateParams struct {
+	Prompt string
+	// System overrides the system message defined in the Modelfile.
+	System string
+	// Template overrides the prompt template defined in the Modelfile, using
+	// Go template syntax.
+	Template string
+	// Raw sends Prompt to the model unmodified, bypassing the template. Use it
+	// when the prompt is already fully formatted for the model.
+	Raw bool
+	// Suffix is the text after the insertion point for fill-in-the-middle
+	// completion with code models such as codellama or qwen2.5-coder. The
+	// model generates the text between Prompt and Suffix.
+	Suffix string
+
+	// ModelOptions are merged into the request options, like ChatParams.ModelOptions.
+	ModelOptions map[string]any
+}
+
+// GenerateResult is the response of a Generate call.
+type GenerateResult struct {
+	Text         string
+	Reasoning    string
+	FinishReason core.FinishReason
+	// RawFinishReason is the done_reason reported by Ollama.
+	RawFinishReason string
+	Usage           *core.Usage
+}
+
+// Generate sends a non-streaming completion request to /api/generate.
+//
+// Unlike Chat, it gives direct control over the prompt template, which is
+// needed for models without a chat template or for hand-formatted prompts.
+func (a *Adapter) Generate(ctx context.Context, params GenerateParams) (*GenerateResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params.Prompt == "" {
+		return nil, errors.New("ollama: generate prompt is required")
+	}
+
+	stream := false
+	request := generateRequest{
+		Model:     a.Model,
+		Prompt:    params.Prompt,
+		System:    params.System,
+		Template:  params.Template,
+		Raw:       params.Raw,
+		Suffix:    params.Suffix,
+		Stream:    &stream,
+		Options:   a.withRuntimeDefaults(nativeOptions(params.ModelOptions)),
+		KeepAlive: a.keepAlive(params.ModelOptions),
+	}
+
+	response, err := withAutoPull(ctx, a, func() (*generateResponse, error) {
+		return a.postGenerate(ctx, &request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GenerateResult{
+		Text:            response.Response,
+		Reasoning:       strings.TrimSpace(response.Thinking),
+		FinishReason:    toCoreFinishReason(response.DoneReason),
+		RawFinishReason: response.DoneReason,
+		Usage: toCoreUsageWithMetrics(
+			response.PromptEvalCount,
+			response.EvalCount,
+			response.TotalDuration,
+			response.LoadDuration,
+			response.PromptEvalDuration,
+			response.EvalDuration,
+		),
+	}, nil
+}
+
+func (a *Adapter) postGenerate(ctx context.Context, request *generateRequest) (*generateResponse, error) {
+	var response generateResponse
+	if _, err := a.transport().Send(ctx, httpclient.Request{Path: "/api/generate", Name: "generate", Body: request}, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,88 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenerateSendsRawAndTemplate(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"llama3.2","response":"Paris","done":true,"done_reason":"stop","prompt_eval_count":12,"eval_count":2}`))
+	}))
+	defer server.Close()
+
+	adapter := New("llama3.2", WithBaseURL(server.URL))
+	result, err := adapter.Generate(context.Background(), GenerateParams{
+		Prompt:       "[INST] Capital of France? [/INST]",
+		Template:     "{{ .Prompt }}",
+		Raw:          true,
+		ModelOptions: map[string]any{"numCtx": 4096},
+	})
+	if err != nil {
+		t.Fatalf("generate returned error: %v", err)
+	}
+
+	if request["raw"] != true || request["template"] != "{{ .Prompt }}" || request["stream"] != false {
+		t.Fatalf("unexpected request %#v", request)
+	}
+	options, _ := request["options"].(map[string]any)
+	if options["num_ctx"] != float64(4096) {
+		t.Fatalf("expected num_ctx option, got %#v", request["options"])
+	}
+	if result.Text != "Paris" || result.FinishReason != "stop" {
+		t.Fatalf("unexpected result %#v", result)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 14 {
+		t.Fatalf("unexpected usage %#v", result.Usage)
+	}
+}
+
+func TestGenerateSendsSuffix(t *testing.T) {
+	t.Parallel()
+
+	var request generateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"response":"a + b","done":true}`))
+	}))
+	defer server.Close()
+
+	adapter := New("qwen2.5-coder", WithBaseURL(server.URL))
+	result, err := adapter.Generate(context.Background(), GenerateParams{
+		Prompt: "func add(a, b int) int {\n\treturn ",
+		Suffix: "\n}",
+	})
+	if err != nil {
+		t.Fatalf("generate returned error: %v", err)
+	}
+	if request.Suffix != "\n}" || request.Prompt != "func add(a, b int) int {\n\treturn " {
+		t.Fatalf("unexpected request %#v", request)
+	}
+	if result.Text != "a + b" {
+		t.Fatalf("unexpected completion %q", result.Text)
+	}
+}
+
+func TestGenerateRequiresPrompt(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New("llama3.2").Generate(context.Background(), GenerateParams{}); err == nil {
+		t.Fatal("expected error for empty prompt")
+	}
+}
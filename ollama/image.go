@@ -0,0 +1,20 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+
+	"github.com/m43i/go-ai/core"
+)
+
+var errImageURLNotSupported = errors.New("image URL source is not supported (use DataSource with base64 image data or enable ollama.WithImageURLFetch)")
+
+// inlineImageURLs returns params with every image URLSource replaced by a
+// DataSource holding the downloaded image, so Ollama receives base64 data.
+// It returns params unchanged unless image URL fetching is enabled.
+func (a *Adapter) inlineImageURLs(ctx context.Context, params *core.ChatParams) (*core.ChatParams, error) {
+	if a.URLFetcher == nil {
+		return params, nil
+	}
+	return a.URLFetcher.InlineURLs(ctx, params, core.CapabilityVision)
+}
@@ -0,0 +1,171 @@
+package ollama
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestChatFetchesImageURLs(t *testing.T) {
+	t.Parallel()
+
+	var request chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cat.png":
+			_, _ = w.Write(pngHeader)
+		case "/api/chat":
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"a cat"},"done":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := New("llava", WithBaseURL(server.URL), WithImageURLFetch(0))
+	adapter.URLFetcher.AllowPrivateNetworks = true
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.ContentMessagePart{
+			Role: core.RoleUser,
+			Parts: []core.ContentPart{
+				core.TextPart{Text: "What is this?"},
+				core.ImagePart{Source: core.URLSource{URL: server.URL + "/cat.png"}},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if result.Text != "a cat" {
+		t.Fatalf("unexpected result %q", result.Text)
+	}
+	if len(request.Messages) != 1 || len(request.Messages[0].Images) != 1 {
+		t.Fatalf("expected one inlined image, got %#v", request.Messages)
+	}
+	if request.Messages[0].Images[0] != base64.StdEncoding.EncodeToString(pngHeader) {
+		t.Fatalf("unexpected image data %q", request.Messages[0].Images[0])
+	}
+}
+
+func TestInlineImageURLsKeepsMessageFields(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(pngHeader)
+	}))
+	defer server.Close()
+
+	message := core.ContentMessagePart{
+		Role:         core.RoleUser,
+		Parts:        []core.ContentPart{core.ImagePart{Source: core.URLSource{URL: server.URL + "/cat.png"}}},
+		Name:         "jane",
+		ID:           "msg_1",
+		Metadata:     map[string]any{"source": "upload"},
+		CacheControl: &core.CacheControl{},
+	}
+	adapter := New("llava", WithURLFetcher(&core.URLFetcher{AllowPrivateNetworks: true}))
+	params, err := adapter.inlineImageURLs(context.Background(), &core.ChatParams{Messages: []core.MessageUnion{&message}})
+	if err != nil {
+		t.Fatalf("inlineImageURLs returned error: %v", err)
+	}
+	inlined := params.Messages[0].(core.ContentMessagePart)
+	if inlined.Name != "jane" || inlined.ID != "msg_1" || inlined.Metadata["source"] != "upload" || inlined.CacheControl == nil {
+		t.Fatalf("message fields were dropped: %#v", inlined)
+	}
+	if _, ok := inlined.Parts[0].(core.ImagePart).Source.(core.DataSource); !ok {
+		t.Fatalf("image was not inlined: %#v", inlined.Parts[0])
+	}
+	if _, ok := message.Parts[0].(core.ImagePart).Source.(core.URLSource); !ok {
+		t.Fatal("caller's message was modified")
+	}
+}
+
+func TestImageURLFetchEnforcesLimits(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large.png":
+			_, _ = w.Write(append(pngHeader, make([]byte, 64)...))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		}
+	}))
+	defer server.Close()
+
+	inline := func(adapter *Adapter, url string) error {
+		_, err := adapter.inlineImageURLs(context.Background(), &core.ChatParams{Messages: []core.MessageUnion{core.ContentMessagePart{
+			Role:  core.RoleUser,
+			Parts: []core.ContentPart{core.ImagePart{Source: core.URLSource{URL: url}}},
+		}}})
+		return err
+	}
+
+	adapter := New("llava", WithImageURLFetch(32))
+	if err := inline(adapter, server.URL+"/large.png"); err == nil || !strings.Contains(err.Error(), "private address") {
+		t.Fatalf("expected a private address error, got %v", err)
+	}
+
+	adapter.URLFetcher.AllowPrivateNetworks = true
+	tests := map[string]string{
+		server.URL + "/large.png": "exceeds 32 bytes",
+		server.URL + "/page.html": `has type "text/html", not image`,
+		"file:///etc/passwd":      "http or https",
+	}
+	for url, want := range tests {
+		if err := inline(adapter, url); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("inlineImageURLs(%q) error = %v, want %q", url, err, want)
+		}
+	}
+}
+
+func TestChatFetchesImageURLsWithURLFetcher(t *testing.T) {
+	t.Parallel()
+
+	var request chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cat.png":
+			_, _ = w.Write(pngHeader)
+		case "/api/chat":
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"a cat"},"done":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := New("llava", WithBaseURL(server.URL), WithURLFetcher(&core.URLFetcher{AllowedHosts: []string{"127.0.0.1"}}))
+	message := core.ContentMessagePart{
+		Role:  core.RoleUser,
+		Parts: []core.ContentPart{core.ImagePart{Source: core.URLSource{URL: server.URL + "/cat.png"}}},
+	}
+	if _, err := adapter.Chat(context.Background(), &core.ChatParams{Messages: []core.MessageUnion{message}}); err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if len(request.Messages) != 1 || len(request.Messages[0].Images) != 1 || request.Messages[0].Images[0] != base64.StdEncoding.EncodeToString(pngHeader) {
+		t.Fatalf("expected one inlined image, got %#v", request.Messages)
+	}
+
+	message.Parts = []core.ContentPart{core.ImagePart{Source: core.URLSource{URL: "http://example.com/cat.png"}}}
+	if _, err := adapter.Chat(context.Background(), &core.ChatParams{Messages: []core.MessageUnion{message}}); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("expected a host error, got %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/bufpool"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+var _ core.HealthAdapter = (*Adapter)(nil)
+
+// RunningModel describes a model currently loaded by the Ollama server.
+type RunningModel struct {
+	Name   string
+	Model  string
+	Digest string
+	// Size is the total memory used by the model in bytes.
+	Size int64
+	// SizeVRAM is the part of Size held in GPU memory.
+	SizeVRAM      int64
+	ContextLength int64
+	// ExpiresAt is when the model will be unloaded unless it is used again.
+	ExpiresAt time.Time
+
+	Family            string
+	ParameterSize     string
+	QuantizationLevel string
+}
+
+// Ps lists the models currently loaded into memory (GET /api/ps).
+func (a *Adapter) Ps(ctx context.Context) ([]RunningModel, error) {
+	if a == nil {
+		return nil, errors.New("ollama: adapter is nil")
+	}
+
+	var response psResponse
+	if err := a.getJSON(ctx, "/api/ps", &response); err != nil {
+		return nil, err
+	}
+
+	out := make([]RunningModel, 0, len(response.Models))
+	for _, model := range response.Models {
+		out = append(out, RunningModel{
+			Name:              model.Name,
+			Model:             model.Model,
+			Digest:            model.Digest,
+			Size:              model.Size,
+			SizeVRAM:          model.SizeVRAM,
+			ContextLength:     model.ContextLength,
+			ExpiresAt:         model.ExpiresAt,
+			Family:            model.Details.Family,
+			ParameterSize:     model.Details.ParameterSize,
+			QuantizationLevel: model.Details.QuantizationLevel,
+		})
+	}
+	return out, nil
+}
+
+// ShowResult describes a model as reported by /api/show.
+type ShowResult struct {
+	Family            string
+	ParameterSize     string
+	QuantizationLevel string
+	// ContextLength is the maximum context window of the model in tokens.
+	ContextLength int64
+	// Capabilities lists features such as "completion", "tools", "vision",
+	// or "thinking".
+	Capabilities []string
+	Template     string
+	// Parameters holds the Modelfile PARAMETER lines.
+	Parameters string
+}
+
+// Show returns details about a model (POST /api/show).
+func (a *Adapter) Show(ctx context.Context, model string) (*ShowResult, error) {
+	if a == nil {
+		return nil, errors.New("ollama: adapter is nil")
+	}
+	if strings.TrimSpace(model) == "" {
+		return nil, errors.New("ollama: show model name is required")
+	}
+
+	var response showResponse
+	if err := a.postJSON(ctx, "/api/show", showRequest{Model: strings.TrimSpace(model)}, &response); err != nil {
+		return nil, err
+	}
+
+	return &ShowResult{
+		Family:            response.Details.Family,
+		ParameterSize:     response.Details.ParameterSize,
+		QuantizationLevel: response.Details.QuantizationLevel,
+		ContextLength:     contextLengthFromModelInfo(response.ModelInfo),
+		Capabilities:      response.Capabilities,
+		Template:          response.Template,
+		Parameters:        response.Parameters,
+	}, nil
+}
+
+// Warmup loads model into memory ahead of traffic by sending an empty
+// generate request. An empty model warms up the adapter's model. The model
+// stays loaded for the adapter's KeepAlive; use WithKeepAlive(-1) to keep it
+// resident until the server unloads it explicitly.
+func (a *Adapter) Warmup(ctx context.Context, model string) error {
+	if a == nil {
+		return errors.New("ollama: adapter is nil")
+	}
+
+	model = nonEmpty(model, a.Model)
+	if model == "" {
+		return errors.New("ollama: warmup model name is required")
+	}
+
+	stream := false
+	request := generateRequest{
+		Model:     model,
+		Stream:    &stream,
+		KeepAlive: a.keepAlive(nil),
+	}
+	_, err := withAutoPull(ctx, a, func() (*generateResponse, error) {
+		return a.postGenerate(ctx, &request)
+	})
+	return err
+}
+
+// Ping checks that the Ollama server is reachable (GET /).
+func (a *Adapter) Ping(ctx context.Context) error {
+	if a == nil {
+		return errors.New("ollama: adapter is nil")
+	}
+
+	httpResp, err := a.get(ctx, "/")
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	_, _ = io.Copy(io.Discard, httpResp.Body)
+	return nil
+}
+
+// Version returns the Ollama server version (GET /api/version).
+func (a *Adapter) Version(ctx context.Context) (string, error) {
+	if a == nil {
+		return "", errors.New("ollama: adapter is nil")
+	}
+
+	var response versionResponse
+	if err := a.getJSON(ctx, "/api/version", &response); err != nil {
+		return "", err
+	}
+	return response.Version, nil
+}
+
+// RequireVersion returns an error unless the server version is at least
+// minimum, such as "0.5.5".
+func (a *Adapter) RequireVersion(ctx context.Context, minimum string) error {
+	version, err := a.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if compareVersions(version, minimum) < 0 {
+		return fmt.Errorf("ollama: server version %s is older than required %s", version, minimum)
+	}
+	return nil
+}
+
+// compareVersions compares dotted numeric versions such as "0.6.2". A leading
+// "v" and any pre-release or build suffix are ignored.
+func compareVersions(a, b string) int {
+	left, right := versionParts(a), versionParts(b)
+	for i := range max(len(left), len(right)) {
+		var l, r int
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		if l != r {
+			if l < r {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if idx := strings.IndexAny(version, "-+ "); idx >= 0 {
+		version = version[:idx]
+	}
+
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts = append(parts, value)
+	}
+	return parts
+}
+
+// Progress is a status update streamed by long-running model operations.
+// Total and Completed are byte counts while a layer is transferred.
+type Progress struct {
+	Status    string
+	Digest    string
+	Total     int64
+	Completed int64
+}
+
+// CreateParams describes a model to create from an existing model.
+type CreateParams struct {
+	// Model is the name of the model to create.
+	Model string
+	// From is the base model, such as "llama3.2".
+	From     string
+	System   string
+	Template string
+	License  string
+	// Parameters sets default model options, such as temperature or num_ctx.
+	Parameters map[string]any
+	Quantize   string
+
+	// Modelfile is the Modelfile contents for servers older than Ollama 0.5.5,
+	// which do not accept From and the other fields.
+	Modelfile string
+
+	// Progress, if set, receives each status update as it is streamed.
+	Progress func(Progress)
+}
+
+// Create creates a model variant, such as a base model with a custom system
+// prompt (POST /api/create). It returns once the server reports success.
+func (a *Adapter) Create(ctx context.Context, params CreateParams) error {
+	if a == nil {
+		return errors.New("ollama: adapter is nil")
+	}
+	if strings.TrimSpace(params.Model) == "" {
+		return errors.New("ollama: create model name is required")
+	}
+	if strings.TrimSpace(params.From) == "" && strings.TrimSpace(params.Modelfile) == "" {
+		return errors.New("ollama: create requires From or Modelfile")
+	}
+
+	stream := true
+	request := createRequest{
+		Model:      strings.TrimSpace(params.Model),
+		From:       strings.TrimSpace(params.From),
+		System:     params.System,
+		Template:   params.Template,
+		License:    params.License,
+		Parameters: params.Parameters,
+		Quantize:   strings.TrimSpace(params.Quantize),
+		Modelfile:  params.Modelfile,
+		Stream:     &stream,
+	}
+
+	return a.postProgress(ctx, "/api/create", request, params.Progress)
+}
+
+// PullParams describes a model to download from a registry.
+type PullParams struct {
+	// Model is the model to pull, such as "llama3.2" or "qwen2.5:7b".
+	Model string
+	// Insecure allows pulling from a registry without TLS verification.
+	Insecure bool
+
+	// Progress, if set, receives each status update as it is streamed, with
+	// Completed and Total bytes while layers download.
+	Progress func(Progress)
+}
+
+// Pull downloads a model (POST /api/pull). It returns once the server reports
+// success.
+func (a *Adapter) Pull(ctx context.Context, params PullParams) error {
+	if a == nil {
+		return errors.New("ollama: adapter is nil")
+	}
+	if strings.TrimSpace(params.Model) == "" {
+		return errors.New("ollama: pull model name is required")
+	}
+
+	stream := true
+	request := pullRequest{
+		Model:    strings.TrimSpace(params.Model),
+		Insecure: params.Insecure,
+		Stream:   &stream,
+	}
+
+	return a.postProgress(ctx, "/api/pull", request, params.Progress)
+}
+
+// postProgress posts a streaming request and reports each NDJSON status line
+// to progress until the stream ends.
+func (a *Adapter) postProgress(ctx context.Context, path string, request any, progress func(Progress)) error {
+	httpResp, err := a.transport().Do(ctx, httpclient.Request{Path: path, Body: request, Header: ndjson})
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	scanner, release := bufpool.NewScanner(httpResp.Body, 1024*1024)
+	defer release()
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var event progressResponse
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return fmt.Errorf("ollama: decode progress: %w", err)
+		}
+		if strings.TrimSpace(event.Error) != "" {
+			return fmt.Errorf("ollama: API error: %s", strings.TrimSpace(event.Error))
+		}
+		if progress != nil {
+			progress(Progress{
+				Status:    event.Status,
+				Digest:    event.Digest,
+				Total:     event.Total,
+				Completed: event.Completed,
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("ollama: progress read failed: %w", err)
+	}
+	return nil
+}
+
+func (a *Adapter) postJSON(ctx context.Context, path string, request, out any) error {
+	_, err := a.transport().Send(ctx, httpclient.Request{Path: path, Body: request}, out)
+	return err
+}
+
+func (a *Adapter) getJSON(ctx context.Context, path string, out any) error {
+	_, err := a.transport().Send(ctx, httpclient.Request{Path: path}, out)
+	return err
+}
+
+// get sends a GET request and returns the response of a successful call; the
+// caller closes its body.
+func (a *Adapter) get(ctx context.Context, path string) (*http.Response, error) {
+	return a.transport().Do(ctx, httpclient.Request{Path: path})
+}
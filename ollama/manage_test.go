@@ -0,0 +1,225 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPsListsRunningModels(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/ps" {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"models":[{"name":"llama3.2:latest","model":"llama3.2:latest","size":5137025024,"digest":"abc","details":{"family":"llama","parameter_size":"3.2B","quantization_level":"Q4_K_M"},"expires_at":"2025-01-01T10:00:00Z","size_vram":4000000000,"context_length":4096}]}`))
+	}))
+	defer server.Close()
+
+	models, err := New("", WithBaseURL(server.URL)).Ps(context.Background())
+	if err != nil {
+		t.Fatalf("ps returned error: %v", err)
+	}
+	if len(models) != 1 {
+		t.Fatalf("expected one model, got %#v", models)
+	}
+	model := models[0]
+	if model.Name != "llama3.2:latest" || model.Size != 5137025024 || model.SizeVRAM != 4000000000 || model.ContextLength != 4096 {
+		t.Fatalf("unexpected model %#v", model)
+	}
+	if model.Family != "llama" || model.ParameterSize != "3.2B" || model.QuantizationLevel != "Q4_K_M" {
+		t.Fatalf("unexpected model details %#v", model)
+	}
+	if !model.ExpiresAt.Equal(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected expiry %v", model.ExpiresAt)
+	}
+}
+
+func TestCreateStreamsProgress(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/create" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = fmt.Fprintln(w, `{"status":"using existing layer sha256:abc"}`)
+		_, _ = fmt.Fprintln(w, `{"status":"writing manifest"}`)
+		_, _ = fmt.Fprintln(w, `{"status":"success"}`)
+	}))
+	defer server.Close()
+
+	var statuses []string
+	err := New("", WithBaseURL(server.URL)).Create(context.Background(), CreateParams{
+		Model:      "mario",
+		From:       "llama3.2",
+		System:     "You are Mario.",
+		Parameters: map[string]any{"temperature": 0.7},
+		Progress:   func(p Progress) { statuses = append(statuses, p.Status) },
+	})
+	if err != nil {
+		t.Fatalf("create returned error: %v", err)
+	}
+
+	if request["model"] != "mario" || request["from"] != "llama3.2" || request["system"] != "You are Mario." || request["stream"] != true {
+		t.Fatalf("unexpected create request %#v", request)
+	}
+	if len(statuses) != 3 || statuses[2] != "success" {
+		t.Fatalf("unexpected progress statuses %#v", statuses)
+	}
+}
+
+func TestCreateReturnsStreamedError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(w, `{"status":"parsing modelfile"}`)
+		_, _ = fmt.Fprintln(w, `{"error":"base model not found"}`)
+	}))
+	defer server.Close()
+
+	err := New("", WithBaseURL(server.URL)).Create(context.Background(), CreateParams{Model: "x", From: "missing"})
+	if err == nil || !strings.Contains(err.Error(), "base model not found") {
+		t.Fatalf("expected streamed error, got %v", err)
+	}
+}
+
+func TestPullStreamsProgress(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/pull" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = fmt.Fprintln(w, `{"status":"pulling manifest"}`)
+		_, _ = fmt.Fprintln(w, `{"status":"pulling 8eeb52dfb3bb","digest":"sha256:8eeb52dfb3bb","total":2000,"completed":500}`)
+		_, _ = fmt.Fprintln(w, `{"status":"pulling 8eeb52dfb3bb","digest":"sha256:8eeb52dfb3bb","total":2000,"completed":2000}`)
+		_, _ = fmt.Fprintln(w, `{"status":"success"}`)
+	}))
+	defer server.Close()
+
+	var updates []Progress
+	err := New("", WithBaseURL(server.URL)).Pull(context.Background(), PullParams{
+		Model:    "llama3.2",
+		Progress: func(p Progress) { updates = append(updates, p) },
+	})
+	if err != nil {
+		t.Fatalf("pull returned error: %v", err)
+	}
+
+	if request["model"] != "llama3.2" || request["stream"] != true {
+		t.Fatalf("unexpected pull request %#v", request)
+	}
+	if len(updates) != 4 {
+		t.Fatalf("expected 4 progress updates, got %#v", updates)
+	}
+	if updates[1].Digest != "sha256:8eeb52dfb3bb" || updates[1].Total != 2000 || updates[1].Completed != 500 {
+		t.Fatalf("unexpected download progress %#v", updates[1])
+	}
+}
+
+func TestWarmupSendsEmptyGenerate(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"llama3.2","response":"","done":true,"done_reason":"load"}`))
+	}))
+	defer server.Close()
+
+	adapter := New("llama3.2", WithBaseURL(server.URL), WithKeepAlive(-1))
+	if err := adapter.Warmup(context.Background(), ""); err != nil {
+		t.Fatalf("warmup returned error: %v", err)
+	}
+
+	if request["model"] != "llama3.2" || request["prompt"] != "" || request["keep_alive"] != "-1ns" {
+		t.Fatalf("unexpected warmup request %#v", request)
+	}
+}
+
+func TestPingAndVersion(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte("Ollama is running"))
+		case "/api/version":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"version":"0.6.2"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := New("", WithBaseURL(server.URL))
+	if err := adapter.Ping(context.Background()); err != nil {
+		t.Fatalf("ping returned error: %v", err)
+	}
+
+	version, err := adapter.Version(context.Background())
+	if err != nil || version != "0.6.2" {
+		t.Fatalf("unexpected version %q (%v)", version, err)
+	}
+	if err := adapter.RequireVersion(context.Background(), "0.5.5"); err != nil {
+		t.Fatalf("expected 0.6.2 to satisfy 0.5.5, got %v", err)
+	}
+	if err := adapter.RequireVersion(context.Background(), "0.10.0"); err == nil {
+		t.Fatal("expected 0.6.2 to fail 0.10.0")
+	}
+}
+
+func TestPingReportsUnreachableServer(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	if err := New("", WithBaseURL(server.URL)).Ping(context.Background()); err == nil {
+		t.Fatal("expected error for closed server")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.6.2", "0.6.2", 0},
+		{"v0.6.2", "0.6", 1},
+		{"0.5.13", "0.6.0", -1},
+		{"0.6.0-rc1", "0.6.0", 0},
+		{"1.0", "0.99.99", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Fatalf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ollama
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func init() {
+	core.RegisterProvider(providerName, newFromConfig)
+}
+
+// newFromConfig builds an adapter for core.NewAdapter. It supports the
+// options timeout, keep_alive, auto_context, auto_pull, gzip, and
+// prompt_size_check.
+func newFromConfig(config core.ProviderConfig) (core.TextAdapter, error) {
+	opts := []Option{WithAPIKey(config.APIKey), WithBaseURL(config.BaseURL)}
+	for name, value := range config.Options {
+		switch name {
+		case "timeout", "keep_alive":
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			if name == "timeout" {
+				opts = append(opts, WithTimeout(duration))
+			} else {
+				opts = append(opts, WithKeepAlive(duration))
+			}
+		case "auto_context", "auto_pull", "gzip", "prompt_size_check":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			if !enabled {
+				continue
+			}
+			switch name {
+			case "auto_context":
+				opts = append(opts, WithAutoContext())
+			case "auto_pull":
+				opts = append(opts, WithAutoPull())
+			case "gzip":
+				opts = append(opts, WithGzip())
+			default:
+				opts = append(opts, WithPromptSizeCheck())
+			}
+		default:
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	return New(config.Model, opts...), nil
+}
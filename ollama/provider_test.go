@@ -0,0 +1,24 @@
+package ollama
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestFromDSN(t *testing.T) {
+	t.Parallel()
+
+	adapter, err := core.FromDSN("ollama://llama3.2:3b?base_url=http://gpu-box:11434&keep_alive=10m&auto_pull=1")
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	ollama := adapter.(*Adapter)
+	if ollama.Model != "llama3.2:3b" || ollama.BaseURL != "http://gpu-box:11434" || ollama.KeepAlive == nil || *ollama.KeepAlive != 10*time.Minute || !ollama.AutoPull {
+		t.Fatalf("adapter = %+v", ollama)
+	}
+	if _, err := core.FromDSN("ollama://llama3.2?keep_alive=soon"); err == nil {
+		t.Fatal("FromDSN() expected error for an invalid duration")
+	}
+}
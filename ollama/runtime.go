@@ -0,0 +1,68 @@
+package ollama
+
+// RuntimeOptions tunes how Ollama runs a model for a request. Nil fields keep
+// the server default, which is otherwise set through environment variables on
+// the server.
+//
+// Pass it per request as ModelOptions["runtime"], or set adapter-wide
+// defaults with WithRuntimeOptions. Flat ModelOptions keys such as
+// "num_thread" take precedence.
+type RuntimeOptions struct {
+	// NumThread is the number of CPU threads used for generation.
+	NumThread *int
+	// NumGPU is the number of layers offloaded to the GPU; 0 runs on the CPU.
+	NumGPU *int
+	// MainGPU selects the GPU used for small tensors when splitting a model
+	// across several GPUs.
+	MainGPU *int
+	// NumBatch is the prompt processing batch size.
+	NumBatch *int
+}
+
+// apply sets each configured field in options. Existing keys are replaced
+// only when overwrite is true.
+func (r *RuntimeOptions) apply(options map[string]any, overwrite bool) {
+	if r == nil {
+		return
+	}
+	for key, value := range map[string]*int{
+		"num_thread": r.NumThread,
+		"num_gpu":    r.NumGPU,
+		"main_gpu":   r.MainGPU,
+		"num_batch":  r.NumBatch,
+	} {
+		if value == nil {
+			continue
+		}
+		if _, ok := options[key]; overwrite || !ok {
+			options[key] = *value
+		}
+	}
+}
+
+// runtimeOptionsValue returns the RuntimeOptions given as a ModelOptions value.
+func runtimeOptionsValue(value any) *RuntimeOptions {
+	switch typed := value.(type) {
+	case RuntimeOptions:
+		return &typed
+	case *RuntimeOptions:
+		return typed
+	default:
+		return nil
+	}
+}
+
+// withRuntimeDefaults fills options with the adapter's RuntimeOptions.
+func (a *Adapter) withRuntimeDefaults(options map[string]any) map[string]any {
+	if a.Runtime == nil {
+		return options
+	}
+	if options == nil {
+		options = map[string]any{}
+	}
+	a.Runtime.apply(options, false)
+	if len(options) == 0 {
+		return nil
+	}
+	return options
+}
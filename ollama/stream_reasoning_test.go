@@ -2,6 +2,7 @@ package ollama
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -61,3 +62,128 @@ func TestChatStreamReasoningPreservesSpacesInDelta(t *testing.T) {
 		t.Fatalf("unexpected final reasoning: %q", doneReasoning)
 	}
 }
+
+func TestChatStreamStreamsStructuredOutput(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if request["stream"] != true || request["format"] == nil {
+			t.Fatalf("expected streaming request with format, got %#v", request)
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = fmt.Fprintln(w, `{"message":{"content":"{\"city\": \"Par"},"done":false}`)
+		_, _ = fmt.Fprintln(w, `{"message":{"content":"is\", \"pop"},"done":false}`)
+		_, _ = fmt.Fprintln(w, `{"message":{"content":"\": 2}"},"done":true,"done_reason":"stop"}`)
+	}))
+	defer server.Close()
+
+	adapter := New("ollama-test", WithBaseURL(server.URL))
+	stream, err := adapter.ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Capital of France?"}},
+		Output: &core.Schema{Name: "city", Schema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"city": map[string]any{"type": "string"}, "pop": map[string]any{"type": "integer"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	var snapshots []string
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkPartialJSON:
+			snapshots = append(snapshots, chunk.Content)
+		case core.StreamChunkContent:
+			t.Fatalf("expected partial JSON chunks, got content chunk %#v", chunk)
+		case core.StreamChunkError:
+			t.Fatalf("unexpected chunk error: %s", chunk.Error)
+		}
+	}
+
+	expected := []string{`{"city": "Par"}`, `{"city": "Paris"}`, `{"city": "Paris", "pop": 2}`}
+	if !reflect.DeepEqual(snapshots, expected) {
+		t.Fatalf("unexpected partial JSON snapshots: %#v", snapshots)
+	}
+}
+
+func TestChatStreamRunsServerToolsBetweenTurns(t *testing.T) {
+	t.Parallel()
+
+	var requests []chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		requests = append(requests, request)
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		if len(requests) == 1 {
+			_, _ = fmt.Fprintln(w, `{"message":{"content":"Checking."},"done":false}`)
+			_, _ = fmt.Fprintln(w, `{"message":{"tool_calls":[{"function":{"name":"weather","arguments":{"city":"Berlin"}}}]},"done":false}`)
+			_, _ = fmt.Fprintln(w, `{"message":{"content":""},"done":true,"done_reason":"stop"}`)
+			return
+		}
+		_, _ = fmt.Fprintln(w, `{"message":{"content":"It is sunny."},"done":true,"done_reason":"stop"}`)
+	}))
+	defer server.Close()
+
+	adapter := New("ollama-test", WithBaseURL(server.URL))
+	stream, err := adapter.ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather in Berlin?"}},
+		Tools: []core.ToolUnion{core.ServerTool{
+			Name: "weather",
+			Handler: func(arguments any) (string, error) {
+				return "sunny", nil
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	var types []string
+	var call *core.ToolCall
+	var finishReason core.FinishReason
+	for chunk := range stream {
+		types = append(types, chunk.Type)
+		switch chunk.Type {
+		case core.StreamChunkToolCall:
+			call = chunk.ToolCall
+		case core.StreamChunkError:
+			t.Fatalf("unexpected chunk error: %s", chunk.Error)
+		case core.StreamChunkDone:
+			finishReason = chunk.FinishReason
+		}
+	}
+
+	expected := []string{
+		core.StreamChunkContent,
+		core.StreamChunkToolCall,
+		core.StreamChunkToolResult,
+		core.StreamChunkContent,
+		core.StreamChunkDone,
+	}
+	if !reflect.DeepEqual(types, expected) {
+		t.Fatalf("unexpected chunk types: %#v", types)
+	}
+	if call == nil || call.ID != "call_1" || call.Name != "weather" {
+		t.Fatalf("unexpected tool call %#v", call)
+	}
+	if finishReason != core.FinishReasonStop {
+		t.Fatalf("unexpected finish reason %q", finishReason)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	second := requests[1].Messages
+	if len(second) != 3 || second[1].Content != "Checking." || len(second[1].ToolCalls) != 1 || second[2].Role != "tool" || second[2].Content != "sunny" {
+		t.Fatalf("unexpected follow-up messages %#v", second)
+	}
+}
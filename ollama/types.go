@@ -1,6 +1,9 @@
 package ollama
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type chatRequest struct {
 	Model    string          `json:"model"`
@@ -10,6 +13,8 @@ type chatRequest struct {
 	Stream   *bool           `json:"stream,omitempty"`
 	Think    any             `json:"think,omitempty"`
 	Options  map[string]any  `json:"options,omitempty"`
+
+	KeepAlive any `json:"keep_alive,omitempty"`
 }
 
 type message struct {
@@ -56,12 +61,47 @@ type chatResponse struct {
 	PromptEvalDuration int64   `json:"prompt_eval_duration,omitempty"`
 	EvalCount          int64   `json:"eval_count,omitempty"`
 	EvalDuration       int64   `json:"eval_duration,omitempty"`
+
+	// Duration is the client-side time of the request.
+	Duration time.Duration `json:"-"`
+	// RequestID is set when a proxy in front of Ollama reports one.
+	RequestID string `json:"-"`
+}
+
+type generateRequest struct {
+	Model    string `json:"model"`
+	Prompt   string `json:"prompt"`
+	System   string `json:"system,omitempty"`
+	Template string `json:"template,omitempty"`
+	Raw      bool   `json:"raw,omitempty"`
+	Suffix   string `json:"suffix,omitempty"`
+	Stream   *bool  `json:"stream,omitempty"`
+
+	Options   map[string]any `json:"options,omitempty"`
+	KeepAlive any            `json:"keep_alive,omitempty"`
+}
+
+type generateResponse struct {
+	Model              string `json:"model"`
+	Response           string `json:"response"`
+	Thinking           string `json:"thinking,omitempty"`
+	Done               bool   `json:"done"`
+	DoneReason         string `json:"done_reason,omitempty"`
+	TotalDuration      int64  `json:"total_duration,omitempty"`
+	LoadDuration       int64  `json:"load_duration,omitempty"`
+	PromptEvalCount    int64  `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64  `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int64  `json:"eval_count,omitempty"`
+	EvalDuration       int64  `json:"eval_duration,omitempty"`
 }
 
 type embedRequest struct {
 	Model      string `json:"model"`
 	Input      any    `json:"input"`
 	Dimensions *int64 `json:"dimensions,omitempty"`
+
+	Options   map[string]any `json:"options,omitempty"`
+	KeepAlive any            `json:"keep_alive,omitempty"`
 }
 
 type embedResponse struct {
@@ -71,3 +111,67 @@ type embedResponse struct {
 	LoadDuration    int64       `json:"load_duration,omitempty"`
 	PromptEvalCount int64       `json:"prompt_eval_count,omitempty"`
 }
+
+type psResponse struct {
+	Models []runningModel `json:"models"`
+}
+
+type runningModel struct {
+	Name          string       `json:"name"`
+	Model         string       `json:"model"`
+	Size          int64        `json:"size"`
+	Digest        string       `json:"digest"`
+	Details       modelDetails `json:"details"`
+	ExpiresAt     time.Time    `json:"expires_at"`
+	SizeVRAM      int64        `json:"size_vram"`
+	ContextLength int64        `json:"context_length,omitempty"`
+}
+
+type modelDetails struct {
+	Format            string `json:"format,omitempty"`
+	Family            string `json:"family,omitempty"`
+	ParameterSize     string `json:"parameter_size,omitempty"`
+	QuantizationLevel string `json:"quantization_level,omitempty"`
+}
+
+type pullRequest struct {
+	Model    string `json:"model"`
+	Insecure bool   `json:"insecure,omitempty"`
+	Stream   *bool  `json:"stream,omitempty"`
+}
+
+type showRequest struct {
+	Model string `json:"model"`
+}
+
+type showResponse struct {
+	Parameters   string         `json:"parameters,omitempty"`
+	Template     string         `json:"template,omitempty"`
+	Details      modelDetails   `json:"details"`
+	ModelInfo    map[string]any `json:"model_info,omitempty"`
+	Capabilities []string       `json:"capabilities,omitempty"`
+}
+
+type createRequest struct {
+	Model      string         `json:"model"`
+	From       string         `json:"from,omitempty"`
+	System     string         `json:"system,omitempty"`
+	Template   string         `json:"template,omitempty"`
+	License    string         `json:"license,omitempty"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+	Quantize   string         `json:"quantize,omitempty"`
+	Modelfile  string         `json:"modelfile,omitempty"`
+	Stream     *bool          `json:"stream,omitempty"`
+}
+
+type progressResponse struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type versionResponse struct {
+	Version string `json:"version"`
+}
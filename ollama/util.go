@@ -6,30 +6,67 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"unicode"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
 )
 
 func decodeAPIError(resp *http.Response) error {
+	requestID := httpclient.RequestID(resp.Header)
 	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
 	if readErr != nil {
-		return fmt.Errorf("ollama: API status %d and failed to read error body: %w", resp.StatusCode, readErr)
+		return &core.APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("ollama: API status %d and failed to read error body: %v", resp.StatusCode, readErr),
+			RequestID:  requestID,
+			Retryable:  resp.StatusCode >= http.StatusInternalServerError,
+		}
 	}
 
 	var envelope struct {
 		Error string `json:"error"`
 	}
 
+	message := ""
 	if err := json.Unmarshal(body, &envelope); err == nil && strings.TrimSpace(envelope.Error) != "" {
-		return fmt.Errorf("ollama: API error: %s", strings.TrimSpace(envelope.Error))
+		errorText := strings.TrimSpace(envelope.Error)
+		if resp.StatusCode == http.StatusNotFound && strings.Contains(errorText, "not found") {
+			return &core.ModelNotFoundError{
+				Model:     missingModelName(errorText),
+				Message:   "ollama: API error: " + errorText,
+				RequestID: requestID,
+			}
+		}
+		message = "ollama: API error: " + errorText
+	} else {
+		text := strings.TrimSpace(string(body))
+		if text == "" {
+			text = http.StatusText(resp.StatusCode)
+		}
+		message = fmt.Sprintf("ollama: API status %d: %s", resp.StatusCode, text)
 	}
 
-	text := strings.TrimSpace(string(body))
-	if text == "" {
-		text = http.StatusText(resp.StatusCode)
+	return &core.APIError{
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		RequestID:  requestID,
+		Retryable:  resp.StatusCode >= http.StatusInternalServerError,
 	}
+}
 
-	return fmt.Errorf("ollama: API status %d: %s", resp.StatusCode, text)
+// missingModelName extracts the model from errors such as
+// `model "llama3" not found, try pulling it first`.
+func missingModelName(message string) string {
+	start := strings.IndexAny(message, "\"'")
+	if start < 0 {
+		return ""
+	}
+	end := strings.IndexByte(message[start+1:], message[start])
+	if end < 0 {
+		return ""
+	}
+	return message[start+1 : start+1+end]
 }
 
 func toCoreChatUsage(in *chatResponse) *core.Usage {
@@ -37,7 +74,7 @@ func toCoreChatUsage(in *chatResponse) *core.Usage {
 		return nil
 	}
 
-	return toCoreUsageWithMetrics(
+	usage := toCoreUsageWithMetrics(
 		in.PromptEvalCount,
 		in.EvalCount,
 		in.TotalDuration,
@@ -45,6 +82,10 @@ func toCoreChatUsage(in *chatResponse) *core.Usage {
 		in.PromptEvalDuration,
 		in.EvalDuration,
 	)
+	if usage != nil {
+		usage.Duration = in.Duration
+	}
+	return usage
 }
 
 func toCoreEmbedUsage(in *embedResponse) *core.Usage {
@@ -114,11 +155,16 @@ func joinReasoningParts(parts []string) string {
 	return strings.TrimSpace(strings.Join(parts, "\n"))
 }
 
-func defaultFinishReason(result *core.ChatResult) string {
-	if result != nil && len(result.ToolCalls) > 0 {
-		return "tool_calls"
+// toCoreFinishReason maps an Ollama done_reason onto core.FinishReason.
+func toCoreFinishReason(doneReason string) core.FinishReason {
+	switch strings.TrimSpace(doneReason) {
+	case "", "stop":
+		return core.FinishReasonStop
+	case "length":
+		return core.FinishReasonLength
+	default:
+		return core.FinishReason(doneReason)
 	}
-	return "stop"
 }
 
 func nonEmpty(value, fallback string) string {
@@ -129,74 +175,17 @@ func nonEmpty(value, fallback string) string {
 	return value
 }
 
-func appendStreamSegment(current, incoming string) (next string, delta string) {
-	if incoming == "" {
-		return current, ""
-	}
-
-	if strings.HasPrefix(incoming, current) {
-		return incoming, incoming[len(current):]
-	}
-	if strings.HasPrefix(current, incoming) {
-		return current, ""
-	}
-
-	return current + incoming, incoming
-}
-
-func emitChunksFromResult(out chan<- core.StreamChunk, params *core.ChatParams, result *core.ChatResult) {
-	if result == nil {
-		return
-	}
-
-	if strings.TrimSpace(result.Reasoning) != "" {
-		reasoning := strings.TrimSpace(result.Reasoning)
-		out <- core.StreamChunk{
-			Type:      core.StreamChunkReasoning,
-			Role:      core.RoleAssistant,
-			Delta:     reasoning,
-			Reasoning: reasoning,
-		}
-	}
-
-	start := 0
-	if params != nil {
-		start = len(params.Messages)
-	}
-	if start < 0 || start > len(result.Messages) {
-		start = 0
-	}
-
-	for _, message := range result.Messages[start:] {
-		switch m := message.(type) {
-		case core.TextMessagePart:
-			if m.Role == core.RoleAssistant {
-				out <- core.StreamChunk{Type: core.StreamChunkContent, Role: core.RoleAssistant, Delta: m.Content, Content: m.Content}
-			}
-		case *core.TextMessagePart:
-			if m != nil && m.Role == core.RoleAssistant {
-				out <- core.StreamChunk{Type: core.StreamChunkContent, Role: core.RoleAssistant, Delta: m.Content, Content: m.Content}
-			}
-
-		case core.ToolCallMessagePart:
-			for _, call := range m.ToolCalls {
-				c := call
-				out <- core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &c}
-			}
-		case *core.ToolCallMessagePart:
-			if m != nil {
-				for _, call := range m.ToolCalls {
-					c := call
-					out <- core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &c}
-				}
-			}
-
-		case core.ToolResultMessagePart:
-			out <- core.StreamChunk{Type: core.StreamChunkToolResult, ToolCallID: m.ToolCallID, Content: m.Content}
-		case *core.ToolResultMessagePart:
-			if m != nil {
-				out <- core.StreamChunk{Type: core.StreamChunkToolResult, ToolCallID: m.ToolCallID, Content: m.Content}
+func camelToSnake(value string) string {
+	var builder strings.Builder
+	for i, r := range value {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				builder.WriteByte('_')
 			}
+			builder.WriteRune(unicode.ToLower(r))
+			continue
 		}
+		builder.WriteRune(r)
 	}
+	return builder.String()
 }
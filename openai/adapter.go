@@ -8,9 +8,11 @@ import (
 	"time"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
 )
 
 const (
+	providerName           = "openai"
 	defaultBaseURL         = "https://api.openai.com/v1"
 	defaultMaxAgenticLoops = 8
 	defaultHTTPTimeout     = 5 * time.Minute
@@ -25,12 +27,28 @@ type Adapter struct {
 	BaseURL    string
 	Endpoint   string
 	HTTPClient *http.Client
+
+	// CheckPromptSize estimates prompt tokens before each request and fails
+	// locally when the prompt cannot fit the model's context window.
+	CheckPromptSize bool
+
+	// URLFetcher, when set, downloads audio given as URLSource, and documents
+	// on the chat completions endpoint, and sends them as inline data.
+	URLFetcher *core.URLFetcher
+
+	// Gzip compresses request bodies of 1 KiB or more, such as prompts with
+	// inline images or audio, and asks for gzip responses.
+	Gzip bool
+
+	fileIDs *fileIDCache
 }
 
 var _ core.TextAdapter = (*Adapter)(nil)
 var _ core.EmbeddingAdapter = (*Adapter)(nil)
 var _ core.ImageAdapter = (*Adapter)(nil)
 var _ core.TranscriptionAdapter = (*Adapter)(nil)
+var _ core.ModerationAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
 
 type Option func(*Adapter)
 
@@ -46,6 +64,7 @@ func New(model string, opts ...Option) *Adapter {
 		BaseURL:    defaultBaseURL,
 		Endpoint:   EndpointChatCompletions,
 		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+		fileIDs:    &fileIDCache{},
 	}
 
 	for _, opt := range opts {
@@ -117,6 +136,35 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// WithGzip compresses request bodies of 1 KiB or more with gzip and asks for
+// gzip responses. It cuts upload time for prompts with large inline images
+// or audio; use it only with endpoints, such as a gateway, that accept
+// gzip-encoded request bodies.
+func WithGzip() Option {
+	return func(adapter *Adapter) {
+		adapter.Gzip = true
+	}
+}
+
+// WithPromptSizeCheck estimates the prompt tokens of each request before
+// sending it and returns a *core.ContextLengthExceededError when the prompt
+// plus the requested output cannot fit the model's context window from the
+// model catalog.
+func WithPromptSizeCheck() Option {
+	return func(adapter *Adapter) {
+		adapter.CheckPromptSize = true
+	}
+}
+
+// WithURLFetcher lets the adapter download media given as URLSource that
+// OpenAI only accepts inline: audio, and documents on the chat completions
+// endpoint. Image URLs are sent to OpenAI as is.
+func WithURLFetcher(fetcher *core.URLFetcher) Option {
+	return func(adapter *Adapter) {
+		adapter.URLFetcher = fetcher
+	}
+}
+
 // WithTimeout sets the timeout on the adapter HTTP client.
 func WithTimeout(timeout time.Duration) Option {
 	return func(adapter *Adapter) {
@@ -130,6 +178,20 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// Capabilities reports the chat features of the configured endpoint. Audio
+// input is only accepted by chat completions, and ChatStream replays a
+// non-streaming call when tools or structured output are used.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:            true,
+		Vision:           true,
+		AudioInput:       a.textEndpoint() == EndpointChatCompletions,
+		Documents:        true,
+		StructuredOutput: true,
+		Reasoning:        true,
+	}
+}
+
 func (a *Adapter) validate() error {
 	if a == nil {
 		return errors.New("openai: adapter is nil")
@@ -156,6 +218,21 @@ func (a *Adapter) client() *http.Client {
 	return &http.Client{Timeout: defaultHTTPTimeout}
 }
 
+// transport returns the client that sends API requests with the adapter's
+// credentials.
+func (a *Adapter) transport() *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   "openai",
+		Header: func(header http.Header) {
+			header.Set("Authorization", "Bearer "+a.APIKey)
+		},
+		DecodeError: decodeAPIError,
+		Gzip:        a.Gzip,
+	}
+}
+
 func (a *Adapter) baseURL() string {
 	if strings.TrimSpace(a.BaseURL) == "" {
 		return defaultBaseURL
@@ -0,0 +1,100 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// roundTripFunc serves benchmark responses without a network round trip.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// cannedClient returns a client that answers every request with body.
+func cannedClient(contentType string, body []byte) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			_, _ = io.Copy(io.Discard, req.Body)
+			_ = req.Body.Close()
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {contentType}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+			Request:    req,
+		}, nil
+	})}
+}
+
+func benchmarkChatCompletionStream(chunks int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"\"},\"finish_reason\":null}]}\n\n")
+	for i := range chunks {
+		fmt.Fprintf(&buf, "data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"token %d \"},\"finish_reason\":null}]}\n\n", i)
+	}
+	buf.WriteString("data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}],\"usage\":{\"prompt_tokens\":10,\"completion_tokens\":1000,\"total_tokens\":1010}}\n\n")
+	buf.WriteString("data: [DONE]\n\n")
+	return buf.Bytes()
+}
+
+func benchmarkConversation(turns int) *core.ChatParams {
+	messages := []core.MessageUnion{core.TextMessagePart{Role: core.RoleSystem, Content: "You are a helpful assistant."}}
+	for i := range turns {
+		id := fmt.Sprintf("call_%d", i)
+		messages = append(messages,
+			core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+				core.TextPart{Text: fmt.Sprintf("What is the weather in city %d?", i)},
+				core.ImagePart{Source: core.URLSource{URL: "https://example.com/map.png"}},
+			}},
+			core.ToolCallMessagePart{Role: core.RoleToolCall, ToolCalls: []core.ToolCall{
+				{ID: id, Name: "get_weather", Arguments: map[string]any{"city": i, "unit": "celsius"}},
+			}},
+			core.ToolResultMessagePart{Role: core.RoleToolResult, ToolCallID: id, Name: "get_weather", Content: `{"temperature":21}`},
+			core.TextMessagePart{Role: core.RoleAssistant, Content: "It is 21 degrees and sunny."},
+		)
+	}
+	return &core.ChatParams{Messages: messages}
+}
+
+// BenchmarkChatStream measures reading a 1000 chunk chat completion stream
+// and emitting its chunks.
+func BenchmarkChatStream(b *testing.B) {
+	body := benchmarkChatCompletionStream(1000)
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithHTTPClient(cannedClient("text/event-stream", body)))
+	params := &core.ChatParams{Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hello"}}}
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		stream, err := adapter.ChatStream(context.Background(), params)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for chunk := range stream {
+			if chunk.Type == core.StreamChunkError {
+				b.Fatal(chunk.Error)
+			}
+		}
+	}
+}
+
+// BenchmarkToChatMessages measures converting a 20 turn conversation with
+// tool calls and images to chat completion messages.
+func BenchmarkToChatMessages(b *testing.B) {
+	params := benchmarkConversation(20)
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, err := toChatMessages(params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,17 +1,20 @@
 package openai
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"strings"
+	"time"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/openaicompat"
+	"github.com/m43i/go-ai/internal/sse"
+	"github.com/m43i/go-ai/internal/streamtext"
 )
 
 // Chat sends a non-streaming chat completion request to OpenAI.
@@ -21,6 +24,17 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 	if err := a.validate(); err != nil {
 		return nil, err
 	}
+	params, err := a.inlineURLSources(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	params, err = a.resolveFileParts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.checkPromptSize(params); err != nil {
+		return nil, err
+	}
 	if a.textEndpoint() == EndpointResponses {
 		return a.chatResponses(ctx, params)
 	}
@@ -72,14 +86,24 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 				text = rawText
 			}
 
+			candidates, err := toCoreCandidates(response)
+			if err != nil {
+				return nil, err
+			}
+
 			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: text})
 			return &core.ChatResult{
-				Text:         text,
-				Reasoning:    joinReasoningParts(reasoningParts),
-				Messages:     append([]core.MessageUnion(nil), conversation...),
-				ToolCalls:    nil,
-				FinishReason: nonEmpty(choice.FinishReason, "stop"),
-				Usage:        toCoreUsage(response.Usage),
+				Text:            text,
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				ToolCalls:       nil,
+				Citations:       toCoreCitations(assistant.Annotations),
+				FinishReason:    toCoreFinishReason(choice.FinishReason),
+				RawFinishReason: choice.FinishReason,
+				Usage:           timedUsage(toCoreUsage(response.Usage), response.Duration, response.RequestID),
+				RateLimit:       response.RateLimit,
+				RequestID:       response.RequestID,
+				Candidates:      candidates,
 			}, nil
 		}
 
@@ -124,12 +148,15 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 
 		if len(pendingClientCalls) > 0 {
 			return &core.ChatResult{
-				Text:         "",
-				Reasoning:    joinReasoningParts(reasoningParts),
-				Messages:     append([]core.MessageUnion(nil), conversation...),
-				ToolCalls:    pendingClientCalls,
-				FinishReason: "tool_calls",
-				Usage:        toCoreUsage(response.Usage),
+				Text:            "",
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				ToolCalls:       pendingClientCalls,
+				FinishReason:    core.FinishReasonToolCalls,
+				RawFinishReason: choice.FinishReason,
+				Usage:           timedUsage(toCoreUsage(response.Usage), response.Duration, response.RequestID),
+				RateLimit:       response.RateLimit,
+				RequestID:       response.RequestID,
 			}, nil
 		}
 	}
@@ -139,12 +166,24 @@ func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.Chat
 
 // ChatStream sends a streaming chat completion request to OpenAI.
 //
-// When tools or structured output are configured, ChatStream emits chunks derived
-// from a non-streaming Chat call to preserve consistent behavior.
+// When tools, structured output, or several candidates are configured,
+// ChatStream emits chunks derived from a non-streaming Chat call to preserve
+// consistent behavior.
 func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
 	if err := a.validate(); err != nil {
 		return nil, err
 	}
+	params, err := a.inlineURLSources(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	params, err = a.resolveFileParts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.checkPromptSize(params); err != nil {
+		return nil, err
+	}
 	if a.textEndpoint() == EndpointResponses {
 		return a.chatResponsesStream(ctx, params)
 	}
@@ -154,24 +193,27 @@ func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-ch
 		return nil, err
 	}
 
+	start := time.Now()
 	out := make(chan core.StreamChunk, 64)
 
 	go func() {
 		defer close(out)
 
-		if len(serverTools) > 0 || len(clientTools) > 0 || (params != nil && params.Output != nil) {
+		if len(serverTools) > 0 || len(clientTools) > 0 || (params != nil && params.Output != nil) || request.N != nil {
 			result, err := a.Chat(ctx, params)
 			if err != nil {
-				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error()}
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: core.RequestIDOf(err)}
 				return
 			}
 
 			emitChunksFromResult(out, params, result)
 			out <- core.StreamChunk{
-				Type:         core.StreamChunkDone,
-				FinishReason: nonEmpty(result.FinishReason, defaultFinishReason(result)),
-				Reasoning:    result.Reasoning,
-				Usage:        result.Usage,
+				Type:            core.StreamChunkDone,
+				FinishReason:    result.FinishReason,
+				RawFinishReason: result.RawFinishReason,
+				Reasoning:       result.Reasoning,
+				Usage:           result.Usage,
+				RequestID:       result.RequestID,
 			}
 			return
 		}
@@ -179,69 +221,63 @@ func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-ch
 		request.Messages = messages
 		request.Stream = true
 
-		url := strings.TrimRight(a.baseURL(), "/") + "/chat/completions"
 		body, err := json.Marshal(request)
 		if err != nil {
 			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: marshal stream request: %v", err)}
 			return
 		}
 
-		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		httpResp, err := a.transport().Do(ctx, httpclient.Request{Path: "/chat/completions", Name: "stream", Body: body})
 		if err != nil {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: build stream request: %v", err)}
-			return
-		}
-
-		httpReq.Header.Set("Authorization", "Bearer "+a.APIKey)
-		httpReq.Header.Set("Content-Type", "application/json")
-
-		httpResp, err := a.client().Do(httpReq)
-		if err != nil {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: stream request failed: %v", err)}
+			out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: core.RequestIDOf(err)}
 			return
 		}
 		defer httpResp.Body.Close()
+		requestID := httpclient.RequestID(httpResp.Header)
 
-		if httpResp.StatusCode >= http.StatusBadRequest {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: decodeAPIError(httpResp).Error()}
-			return
-		}
-
-		scanner := bufio.NewScanner(httpResp.Body)
-		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		reader := sse.NewReader(httpResp.Body)
+		defer reader.Release()
 
 		var content strings.Builder
-		reasoning := ""
+		var reasoning streamtext.Builder
 		finishReason := ""
 		var usage *core.Usage
 
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line == "" || strings.HasPrefix(line, ":") || !strings.HasPrefix(line, "data:") {
-				continue
+		for {
+			sseEvent, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: stream read failed: %v", err), RequestID: requestID}
+				return
 			}
 
-			payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-			if payload == "[DONE]" {
+			payload := bytes.TrimSpace(sseEvent.Data)
+			if len(payload) == 0 {
+				continue
+			}
+			if bytes.Equal(payload, doneMarker) {
 				out <- core.StreamChunk{
-					Type:         core.StreamChunkDone,
-					FinishReason: nonEmpty(finishReason, "stop"),
-					Reasoning:    reasoning,
-					Usage:        usage,
+					Type:            core.StreamChunkDone,
+					FinishReason:    toCoreFinishReason(finishReason),
+					RawFinishReason: finishReason,
+					Reasoning:       reasoning.String(),
+					Usage:           usage,
+					RequestID:       requestID,
 				}
 				return
 			}
 
 			var event streamEvent
-			if err := json.Unmarshal([]byte(payload), &event); err != nil {
-				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: decode stream event: %v", err)}
+			if err := json.Unmarshal(payload, &event); err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: decode stream event: %v", err), RequestID: requestID}
 				return
 			}
 
-			var rawEvent struct {
-				Choices []json.RawMessage `json:"choices"`
-			}
-			_ = json.Unmarshal([]byte(payload), &rawEvent)
+			// The raw choices are only decoded when a choice has no text or
+			// reasoning in the typed fields, which most events do.
+			raw := rawStreamChoices{payload: payload}
 
 			if event.Usage != nil {
 				usage = toCoreUsage(event.Usage)
@@ -253,35 +289,33 @@ func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-ch
 				}
 
 				incomingReasoning := parseStreamChoiceReasoning(choice)
-				if incomingReasoning == "" && idx < len(rawEvent.Choices) {
-					rawReasoning, rawErr := parseStreamChoiceRawReasoning(rawEvent.Choices[idx])
+				if incomingReasoning == "" && mayContainReasoning(payload) {
+					rawReasoning, rawErr := parseStreamChoiceRawReasoning(raw.choice(idx))
 					if rawErr != nil {
-						out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: decode raw stream choice reasoning: %v", rawErr)}
+						out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: decode raw stream choice reasoning: %v", rawErr), RequestID: requestID}
 						return
 					}
 					incomingReasoning = rawReasoning
 				}
 
-				nextReasoning, reasoningDelta := appendStreamSegment(reasoning, incomingReasoning)
-				reasoning = nextReasoning
-				if reasoningDelta != "" {
+				if reasoningDelta := reasoning.Append(incomingReasoning); reasoningDelta != "" {
 					out <- core.StreamChunk{
 						Type:      core.StreamChunkReasoning,
 						Role:      core.RoleAssistant,
 						Delta:     reasoningDelta,
-						Reasoning: reasoning,
+						Reasoning: reasoning.String(),
 					}
 				}
 
 				deltaText, err := parseStreamChoiceText(choice)
 				if err != nil {
-					out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: decode stream delta: %v", err)}
+					out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: decode stream delta: %v", err), RequestID: requestID}
 					return
 				}
-				if deltaText == "" && idx < len(rawEvent.Choices) {
-					rawText, rawErr := parseStreamChoiceRaw(rawEvent.Choices[idx])
+				if deltaText == "" {
+					rawText, rawErr := parseStreamChoiceRaw(raw.choice(idx))
 					if rawErr != nil {
-						out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: decode raw stream choice: %v", rawErr)}
+						out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: decode raw stream choice: %v", rawErr), RequestID: requestID}
 						return
 					}
 					deltaText = rawText
@@ -301,20 +335,17 @@ func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-ch
 			}
 		}
 
-		if err := scanner.Err(); err != nil {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("openai: stream read failed: %v", err)}
-			return
-		}
-
 		out <- core.StreamChunk{
-			Type:         core.StreamChunkDone,
-			FinishReason: nonEmpty(finishReason, "stop"),
-			Reasoning:    reasoning,
-			Usage:        usage,
+			Type:            core.StreamChunkDone,
+			FinishReason:    toCoreFinishReason(finishReason),
+			RawFinishReason: finishReason,
+			Reasoning:       reasoning.String(),
+			Usage:           usage,
+			RequestID:       requestID,
 		}
 	}()
 
-	return out, nil
+	return core.TimeStream(ctx, out, start), nil
 }
 
 func (a *Adapter) buildRequestTemplate(params *core.ChatParams) (chatCompletionRequest, []chatMessage, map[string]core.ServerTool, map[string]struct{}, int, error) {
@@ -328,19 +359,37 @@ func (a *Adapter) buildRequestTemplate(params *core.ChatParams) (chatCompletionR
 		return chatCompletionRequest{}, nil, nil, nil, 0, err
 	}
 
+	providerTools, err := toProviderTools(params)
+	if err != nil {
+		return chatCompletionRequest{}, nil, nil, nil, 0, err
+	}
+	webSearch, err := webSearchOptions(providerTools)
+	if err != nil {
+		return chatCompletionRequest{}, nil, nil, nil, 0, err
+	}
+
 	request := chatCompletionRequest{
 		Model:               a.Model,
 		Tools:               tools,
-		MaxCompletionTokens: maxTokens(params),
+		MaxCompletionTokens: maxTokens(a.Model, params),
 		Temperature:         temperature(params),
 		TopP:                topP(params),
+		Stop:                stopSequences(params),
+		Seed:                seed(params),
+		LogitBias:           openaicompat.LogitBias(params),
+		N:                   openaicompat.CandidateCount(params),
 		Metadata:            metadata(params),
 		ReasoningEffort:     reasoningEffort(params),
+		WebSearchOptions:    webSearch,
 		ModelOptions:        modelOptions(params),
 	}
 
 	if len(tools) > 0 {
-		request.ToolChoice = "auto"
+		request.ToolChoice, err = toolChoice(params, true)
+		if err != nil {
+			return chatCompletionRequest{}, nil, nil, nil, 0, err
+		}
+		request.ParallelToolCalls = parallelToolCalls(params)
 	}
 
 	if params != nil && params.Output != nil {
@@ -356,41 +405,21 @@ func (a *Adapter) postChatCompletions(ctx context.Context, request *chatCompleti
 		return nil, fmt.Errorf("openai: marshal request: %w", err)
 	}
 
-	url := strings.TrimRight(a.baseURL(), "/") + "/chat/completions"
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("openai: build request: %w", err)
-	}
-
-	httpReq.Header.Set("Authorization", "Bearer "+a.APIKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	httpResp, err := a.client().Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("openai: request failed: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode >= http.StatusBadRequest {
-		return nil, decodeAPIError(httpResp)
-	}
-
-	bodyBytes, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("openai: read response body: %w", err)
-	}
-
 	var response chatCompletionResponse
-	if err := json.Unmarshal(bodyBytes, &response); err != nil {
-		return nil, fmt.Errorf("openai: decode response: %w", err)
+	httpResp, err := a.transport().Send(ctx, httpclient.Request{Path: "/chat/completions", Body: body}, &response)
+	if err != nil {
+		return nil, err
 	}
 
 	var rawEnvelope struct {
 		Choices []json.RawMessage `json:"choices"`
 	}
-	if err := json.Unmarshal(bodyBytes, &rawEnvelope); err == nil {
+	if err := json.Unmarshal(httpResp.Body, &rawEnvelope); err == nil {
 		response.RawChoices = rawEnvelope.Choices
 	}
+	response.RateLimit = parseRateLimit(httpResp.Header)
+	response.RequestID = httpclient.RequestID(httpResp.Header)
+	response.Duration = httpResp.Duration
 
 	return &response, nil
 }
@@ -462,6 +491,50 @@ func emitChunksFromResult(out chan<- core.StreamChunk, params *core.ChatParams,
 	}
 }
 
+// toCoreCandidates returns every choice of a response that has several.
+func toCoreCandidates(response *chatCompletionResponse) ([]core.Candidate, error) {
+	if len(response.Choices) < 2 {
+		return nil, nil
+	}
+
+	out := make([]core.Candidate, 0, len(response.Choices))
+	for i, choice := range response.Choices {
+		text, err := parseAssistantChoice(choice)
+		if err != nil {
+			return nil, err
+		}
+		reasoning := parseAssistantChoiceReasoning(choice)
+		if i < len(response.RawChoices) {
+			if strings.TrimSpace(text) == "" {
+				if text, err = parseAssistantChoiceRaw(response.RawChoices[i]); err != nil {
+					return nil, fmt.Errorf("openai: decode raw choice: %w", err)
+				}
+			}
+			if reasoning == "" {
+				if reasoning, err = parseAssistantChoiceRawReasoning(response.RawChoices[i]); err != nil {
+					return nil, fmt.Errorf("openai: decode raw choice reasoning: %w", err)
+				}
+			}
+		}
+
+		var calls []core.ToolCall
+		if len(choice.Message.ToolCalls) > 0 {
+			if calls, err = toCoreToolCalls(choice.Message.ToolCalls); err != nil {
+				return nil, err
+			}
+		}
+
+		out = append(out, core.Candidate{
+			Text:            text,
+			Reasoning:       reasoning,
+			ToolCalls:       calls,
+			FinishReason:    toCoreFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+		})
+	}
+	return out, nil
+}
+
 func toCoreUsage(in *usage) *core.Usage {
 	if in == nil {
 		return nil
@@ -518,11 +591,23 @@ func joinReasoningParts(parts []string) string {
 	return strings.TrimSpace(strings.Join(parts, "\n"))
 }
 
-func defaultFinishReason(result *core.ChatResult) string {
-	if result != nil && len(result.ToolCalls) > 0 {
-		return "tool_calls"
+// toCoreFinishReason maps a chat completions finish_reason, or a Responses
+// API status or incomplete reason, onto core.FinishReason.
+func toCoreFinishReason(reason string) core.FinishReason {
+	switch strings.TrimSpace(reason) {
+	case "", "stop", "completed":
+		return core.FinishReasonStop
+	case "length", "max_output_tokens":
+		return core.FinishReasonLength
+	case "tool_calls", "function_call":
+		return core.FinishReasonToolCalls
+	case "content_filter":
+		return core.FinishReasonContentFilter
+	case "failed":
+		return core.FinishReasonError
+	default:
+		return core.FinishReason(reason)
 	}
-	return "stop"
 }
 
 func nonEmpty(value, fallback string) string {
@@ -532,18 +617,3 @@ func nonEmpty(value, fallback string) string {
 	}
 	return value
 }
-
-func appendStreamSegment(current, incoming string) (next string, delta string) {
-	if incoming == "" {
-		return current, ""
-	}
-
-	if strings.HasPrefix(incoming, current) {
-		return incoming, incoming[len(current):]
-	}
-	if strings.HasPrefix(current, incoming) {
-		return current, ""
-	}
-
-	return current + incoming, incoming
-}
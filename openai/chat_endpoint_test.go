@@ -1,11 +1,18 @@
 package openai
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/m43i/go-ai/core"
 )
@@ -110,3 +117,549 @@ func TestResponsesEndpointReceivesCommonAndModelOptions(t *testing.T) {
 		t.Fatalf("modelOptions reasoning was not forwarded: %#v", request)
 	}
 }
+
+func TestChatCompletionsWebSearchOptionsAndCitations(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"Go 1.25 is out.","annotations":[{"type":"url_citation","url_citation":{"start_index":0,"end_index":15,"url":"https://go.dev/blog","title":"Go Blog"}}]},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-test-search", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	result, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "latest go release?"},
+		},
+		Tools: []core.ToolUnion{
+			core.ProviderTool{Type: "web_search", Options: map[string]any{"search_context_size": "low"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	options, ok := request["web_search_options"].(map[string]any)
+	if !ok || options["search_context_size"] != "low" {
+		t.Fatalf("web_search_options not forwarded: %#v", request)
+	}
+	if _, ok := request["tools"]; ok {
+		t.Fatalf("web search must not be sent as a function tool: %#v", request["tools"])
+	}
+	if len(result.Citations) != 1 {
+		t.Fatalf("expected one citation, got %#v", result.Citations)
+	}
+	citation := result.Citations[0]
+	if citation.URL != "https://go.dev/blog" || citation.Title != "Go Blog" || citation.EndIndex != 15 {
+		t.Fatalf("unexpected citation: %#v", citation)
+	}
+}
+
+func TestResponsesWebSearchToolAndCitations(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"completed","output":[{"type":"web_search_call","status":"completed"},{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Go 1.25 is out.","annotations":[{"type":"url_citation","start_index":0,"end_index":15,"url":"https://go.dev/blog","title":"Go Blog"}]}]}]}`))
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL), WithResponsesAPI())
+	result, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "latest go release?"},
+		},
+		Tools: []core.ToolUnion{
+			core.ProviderTool{Type: "web_search", Options: map[string]any{"searchContextSize": "high"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	tools, ok := request["tools"].([]any)
+	if !ok || len(tools) != 1 {
+		t.Fatalf("expected one tool, got %#v", request["tools"])
+	}
+	tool := tools[0].(map[string]any)
+	if tool["type"] != "web_search" || tool["search_context_size"] != "high" {
+		t.Fatalf("unexpected web search tool: %#v", tool)
+	}
+	if result.Text != "Go 1.25 is out." {
+		t.Fatalf("unexpected text: %q", result.Text)
+	}
+	if len(result.Citations) != 1 || result.Citations[0].URL != "https://go.dev/blog" {
+		t.Fatalf("unexpected citations: %#v", result.Citations)
+	}
+}
+
+func TestChatCompletionsRejectsUnsupportedProviderTool(t *testing.T) {
+	t.Parallel()
+
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL("http://127.0.0.1:0"))
+	_, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "hi"},
+		},
+		Tools: []core.ToolUnion{core.ProviderTool{Type: "code_interpreter"}},
+	})
+	if err == nil {
+		t.Fatal("expected error for unsupported provider tool")
+	}
+}
+
+func TestChatCompletionsSurfacesRateLimitHeaders(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-ratelimit-limit-requests", "500")
+		w.Header().Set("x-ratelimit-remaining-requests", "499")
+		w.Header().Set("x-ratelimit-remaining-tokens", "29000")
+		w.Header().Set("x-ratelimit-reset-requests", "120ms")
+		w.Header().Set("x-ratelimit-reset-tokens", "6m0s")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"hello"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	rateLimit := result.RateLimit
+	if rateLimit == nil {
+		t.Fatal("expected rate limit on result")
+	}
+	if rateLimit.LimitRequests != 500 || rateLimit.RemainingRequests != 499 || rateLimit.RemainingTokens != 29000 {
+		t.Fatalf("unexpected rate limit counts: %#v", rateLimit)
+	}
+	if rateLimit.ResetRequests != 120*time.Millisecond || rateLimit.ResetTokens != 6*time.Minute {
+		t.Fatalf("unexpected rate limit resets: %#v", rateLimit)
+	}
+}
+
+func TestChatCompletionsReturnsRateLimitError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("retry-after", "2")
+		w.Header().Set("x-ratelimit-remaining-requests", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	})
+
+	var rateLimitErr *core.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if rateLimitErr.RetryAfter != 2*time.Second {
+		t.Fatalf("unexpected retry after: %v", rateLimitErr.RetryAfter)
+	}
+	if !strings.Contains(rateLimitErr.Error(), "Rate limit reached") {
+		t.Fatalf("unexpected error message: %q", rateLimitErr.Error())
+	}
+}
+
+func TestChatCompletionsForwardsLogitBias(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"yes"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	_, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "yes or no?"},
+		},
+		LogitBias: map[int64]float64{9891: 150, 2201: -100},
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	bias, ok := request["logit_bias"].(map[string]any)
+	if !ok {
+		t.Fatalf("logit_bias not forwarded: %#v", request)
+	}
+	if bias["9891"].(float64) != 100 || bias["2201"].(float64) != -100 {
+		t.Fatalf("unexpected logit_bias: %#v", bias)
+	}
+}
+
+func TestChatCompletionsForwardsParallelToolCalls(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	parallel := false
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	_, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter: adapter,
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "hi"},
+		},
+		Tools:             []core.ToolUnion{core.ClientTool{Name: "lookup", Description: "Look something up"}},
+		ParallelToolCalls: &parallel,
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+	if request["parallel_tool_calls"] != false {
+		t.Fatalf("expected parallel_tool_calls false, got %#v", request["parallel_tool_calls"])
+	}
+}
+
+func TestChatCompletionsReturnsCandidates(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[
+			{"index":0,"message":{"content":"Tagline A"},"finish_reason":"stop"},
+			{"index":1,"message":{"content":"Tagline B is longer"},"finish_reason":"length"}
+		]}`))
+	}))
+	defer server.Close()
+
+	count := int64(2)
+	result, err := core.Chat(context.Background(), core.TextOptions{
+		Adapter:        New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL)),
+		Messages:       []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Write a tagline"}},
+		CandidateCount: &count,
+	})
+	if err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	if request["n"] != float64(2) {
+		t.Fatalf("expected n in request, got %#v", request)
+	}
+	if result.Text != "Tagline A" || len(result.Candidates) != 2 {
+		t.Fatalf("unexpected result %#v", result)
+	}
+	second := result.Candidates[1]
+	if second.Text != "Tagline B is longer" || second.FinishReason != core.FinishReasonLength {
+		t.Fatalf("unexpected second candidate %#v", second)
+	}
+}
+
+func TestStopSequencesAreSentToChatCompletionsOnly(t *testing.T) {
+	t.Parallel()
+
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		requests = append(requests, request)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/responses" {
+			_, _ = w.Write([]byte(`{"status":"completed","output":[{"type":"message","content":[{"type":"output_text","text":"ok"}]}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	params := &core.ChatParams{
+		Messages:      []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Count"}},
+		StopSequences: []string{"END", "", "STOP"},
+	}
+	for _, adapter := range []*Adapter{
+		New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL)),
+		New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL), WithResponsesAPI()),
+	} {
+		if _, err := adapter.Chat(context.Background(), params); err != nil {
+			t.Fatalf("chat returned error: %v", err)
+		}
+	}
+
+	stop, ok := requests[0]["stop"].([]any)
+	if !ok || len(stop) != 2 || stop[0] != "END" || stop[1] != "STOP" {
+		t.Fatalf("unexpected chat completions stop %#v", requests[0]["stop"])
+	}
+	if _, ok := requests[1]["stop"]; ok {
+		t.Fatalf("responses request must not include stop: %#v", requests[1])
+	}
+}
+
+func TestSeedIsSentToChatCompletionsAndTopKIsOmitted(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	seed := int64(1234)
+	topK := int64(20)
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	if _, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Roll"}},
+		Seed:     &seed,
+		TopK:     &topK,
+	}); err != nil {
+		t.Fatalf("chat returned error: %v", err)
+	}
+
+	if request["seed"] != float64(1234) {
+		t.Fatalf("seed not forwarded: %#v", request)
+	}
+	if _, ok := request["top_k"]; ok {
+		t.Fatalf("top_k must be omitted for OpenAI: %#v", request)
+	}
+}
+
+func TestCapabilitiesDependOnEndpoint(t *testing.T) {
+	t.Parallel()
+
+	if !New("gpt-test").Capabilities().AudioInput {
+		t.Fatal("chat completions should accept audio input")
+	}
+	if New("gpt-test", WithResponsesAPI()).Capabilities().AudioInput {
+		t.Fatal("responses endpoint should not report audio input")
+	}
+}
+
+func TestChatReportsRequestIDAndDuration(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-request-id", "req_123")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`))
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("chat: %v", err)
+	}
+	if result.RequestID != "req_123" || result.Usage == nil || result.Usage.RequestID != "req_123" {
+		t.Fatalf("expected request ID on the result and usage, got %q and %#v", result.RequestID, result.Usage)
+	}
+	if result.Usage.TotalTokens != 4 || result.Usage.Duration <= 0 {
+		t.Fatalf("unexpected usage %#v", result.Usage)
+	}
+}
+
+func TestChatStreamReadsServerSentEvents(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, ": keep-alive\n\n")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\r\n\r\n")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"},\n")
+		_, _ = io.WriteString(w, "data: \"finish_reason\":\"stop\"}],\"usage\":{\"prompt_tokens\":2,\"completion_tokens\":2,\"total_tokens\":4}}\n\n")
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	stream, err := adapter.ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("chat stream: %v", err)
+	}
+
+	var content string
+	var done *core.StreamChunk
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkContent:
+			content = chunk.Content
+		case core.StreamChunkDone:
+			done = &chunk
+		case core.StreamChunkError:
+			t.Fatalf("stream error: %s", chunk.Error)
+		}
+	}
+
+	if content != "Hello" {
+		t.Fatalf("unexpected content %q", content)
+	}
+	if done == nil || done.FinishReason != core.FinishReasonStop || done.Usage == nil || done.Usage.TotalTokens != 4 {
+		t.Fatalf("unexpected done chunk %#v", done)
+	}
+	if done.Usage.TimeToFirstToken <= 0 || done.Usage.Duration < done.Usage.TimeToFirstToken {
+		t.Fatalf("unexpected stream timing %#v", done.Usage)
+	}
+}
+
+func TestChatWithGzipCompressesLargeRequests(t *testing.T) {
+	t.Parallel()
+
+	image := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 4096))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("expected a gzip request body, got Content-Encoding %q", r.Header.Get("Content-Encoding"))
+			return
+		}
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("gzip reader: %v", err)
+			return
+		}
+		body, _ := io.ReadAll(reader)
+		if !strings.Contains(string(body), image) {
+			t.Errorf("expected the image in the decompressed body")
+		}
+		if int64(len(body)) <= r.ContentLength {
+			t.Errorf("expected compression, sent %d bytes for %d", r.ContentLength, len(body))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		_, _ = io.WriteString(writer, `{"choices":[{"message":{"content":"a png"},"finish_reason":"stop"}]}`)
+		_ = writer.Close()
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL), WithGzip())
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+			core.ImagePart{Source: core.DataSource{Data: image, MimeType: "image/png"}},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("chat: %v", err)
+	}
+	if result.Text != "a png" {
+		t.Fatalf("unexpected text %q", result.Text)
+	}
+}
+
+func TestChatReturnsTypedAPIErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-request-id", "req_err")
+		if r.Header.Get("Authorization") == "Bearer limited" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"requests"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"bad input","type":"invalid_request_error","code":null}}`))
+	}))
+	defer server.Close()
+
+	params := &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hi"}},
+	}
+
+	_, err := New("gpt-test", WithAPIKey("limited"), WithBaseURL(server.URL)).Chat(context.Background(), params)
+	var rateLimitErr *core.RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.RequestID != "req_err" {
+		t.Fatalf("expected rate limit error with request id, got %#v", err)
+	}
+
+	_, err = New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL)).Chat(context.Background(), params)
+	var apiErr *core.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected API error, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Type != "invalid_request_error" || apiErr.RequestID != "req_err" || apiErr.Retryable {
+		t.Fatalf("unexpected API error: %#v", apiErr)
+	}
+	if apiErr.Error() != "openai: API error (invalid_request_error, <nil>): bad input" {
+		t.Fatalf("unexpected error message: %q", apiErr.Error())
+	}
+
+	stream, err := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL)).ChatStream(context.Background(), params)
+	if err != nil {
+		t.Fatalf("chat stream: %v", err)
+	}
+	for chunk := range stream {
+		if chunk.Type != core.StreamChunkError || chunk.RequestID != "req_err" {
+			t.Fatalf("unexpected chunk: %#v", chunk)
+		}
+	}
+}
+
+func TestChatStreamReportsRequestID(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("x-request-id", "req_stream")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"},\"finish_reason\":\"stop\"}]}\n\n")
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	for _, endpoint := range []Option{WithChatCompletionsAPI(), WithResponsesAPI()} {
+		adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL), endpoint)
+		stream, err := adapter.ChatStream(context.Background(), &core.ChatParams{
+			Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hello"}},
+		})
+		if err != nil {
+			t.Fatalf("chat stream: %v", err)
+		}
+		var done *core.StreamChunk
+		for chunk := range stream {
+			if chunk.Type == core.StreamChunkDone {
+				done = &chunk
+			}
+		}
+		if done == nil || done.RequestID != "req_stream" {
+			t.Fatalf("%s: unexpected done chunk %#v", adapter.Endpoint, done)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -121,6 +122,18 @@ func toResponseContentParts(parts []core.ContentPart) ([]responseContentPart, er
 
 	out := make([]responseContentPart, 0, len(parts))
 	for i, part := range parts {
+		if raw, ok := rawPartValue(part); ok {
+			if !isOwnRawPart(raw) {
+				continue
+			}
+			payload, err := rawPartPayload(raw)
+			if err != nil {
+				return nil, fmt.Errorf("content part at index %d: %w", i, err)
+			}
+			out = append(out, responseContentPart{Raw: payload})
+			continue
+		}
+
 		switch typed := part.(type) {
 		case core.TextPart:
 			out = append(out, responseContentPart{Type: "input_text", Text: typed.Text})
@@ -144,6 +157,36 @@ func toResponseContentParts(parts []core.ContentPart) ([]responseContentPart, er
 				return nil, fmt.Errorf("content part at index %d: %w", i, err)
 			}
 			out = append(out, item)
+		case core.DocumentPart:
+			item, err := responseDocumentContentPart(typed.Source, typed.Metadata)
+			if err != nil {
+				return nil, fmt.Errorf("content part at index %d: %w", i, err)
+			}
+			out = append(out, item)
+		case *core.DocumentPart:
+			if typed == nil {
+				return nil, fmt.Errorf("content part at index %d: document part is nil", i)
+			}
+			item, err := responseDocumentContentPart(typed.Source, typed.Metadata)
+			if err != nil {
+				return nil, fmt.Errorf("content part at index %d: %w", i, err)
+			}
+			out = append(out, item)
+		case core.FilePart:
+			item, err := responseFileContentPart(typed)
+			if err != nil {
+				return nil, fmt.Errorf("content part at index %d: %w", i, err)
+			}
+			out = append(out, item)
+		case *core.FilePart:
+			if typed == nil {
+				return nil, fmt.Errorf("content part at index %d: file part is nil", i)
+			}
+			item, err := responseFileContentPart(*typed)
+			if err != nil {
+				return nil, fmt.Errorf("content part at index %d: %w", i, err)
+			}
+			out = append(out, item)
 		default:
 			return nil, fmt.Errorf("content part at index %d: unsupported content part type %T", i, part)
 		}
@@ -160,6 +203,48 @@ func responseImageContentPart(source core.Source) (responseContentPart, error) {
 	return responseContentPart{Type: "input_image", ImageURL: url}, nil
 }
 
+func responseDocumentContentPart(source core.Source, metadata map[string]any) (responseContentPart, error) {
+	if fileID := metadataString(metadata, "file_id"); fileID != "" {
+		return responseContentPart{Type: "input_file", FileID: fileID}, nil
+	}
+	if source == nil {
+		return responseContentPart{}, errors.New("document source is required")
+	}
+
+	switch typed := source.(type) {
+	case core.URLSource:
+		url := strings.TrimSpace(typed.URL)
+		if url == "" {
+			return responseContentPart{}, errors.New("document URL is required")
+		}
+		return responseContentPart{Type: "input_file", FileURL: url}, nil
+	case *core.URLSource:
+		if typed == nil {
+			return responseContentPart{}, errors.New("document URL source is nil")
+		}
+		return responseDocumentContentPart(*typed, metadata)
+	}
+
+	file, err := documentFileFromSource(source, metadata)
+	if err != nil {
+		return responseContentPart{}, err
+	}
+	return responseContentPart{Type: "input_file", Filename: file.Filename, FileData: file.FileData}, nil
+}
+
+// responseFileContentPart references an uploaded file, as an input image when
+// its MIME type is an image type.
+func responseFileContentPart(part core.FilePart) (responseContentPart, error) {
+	fileID := strings.TrimSpace(part.FileID)
+	if fileID == "" {
+		return responseContentPart{}, errors.New("file part requires a FileID or data")
+	}
+	if isImageMimeType(part.MimeType) {
+		return responseContentPart{Type: "input_image", FileID: fileID}, nil
+	}
+	return responseContentPart{Type: "input_file", FileID: fileID}, nil
+}
+
 func newToolCallResponseInput(calls []core.ToolCall) ([]responseInputItem, error) {
 	if len(calls) == 0 {
 		return nil, errors.New("assistant tool call message must include at least one tool call")
@@ -196,20 +281,20 @@ func newToolResultResponseInput(toolCallID, content string) ([]responseInputItem
 func toChatMessage(union core.MessageUnion) (chatMessage, error) {
 	switch msg := union.(type) {
 	case core.TextMessagePart:
-		return newTextChatMessage(msg.Role, msg.Content)
+		return newTextChatMessage(msg.Role, msg.Name, msg.Content)
 	case *core.TextMessagePart:
 		if msg == nil {
 			return chatMessage{}, errors.New("text message is nil")
 		}
-		return newTextChatMessage(msg.Role, msg.Content)
+		return newTextChatMessage(msg.Role, msg.Name, msg.Content)
 
 	case core.ContentMessagePart:
-		return newContentChatMessage(msg.Role, msg.Parts)
+		return newContentChatMessage(msg.Role, msg.Name, msg.Parts)
 	case *core.ContentMessagePart:
 		if msg == nil {
 			return chatMessage{}, errors.New("content message is nil")
 		}
-		return newContentChatMessage(msg.Role, msg.Parts)
+		return newContentChatMessage(msg.Role, msg.Name, msg.Parts)
 
 	case core.AssistantToolCallMessagePart:
 		return newAssistantToolCallChatMessage(msg.Role, msg.ToolCalls)
@@ -231,16 +316,16 @@ func toChatMessage(union core.MessageUnion) (chatMessage, error) {
 	return chatMessage{}, fmt.Errorf("unsupported message type %T", union)
 }
 
-func newTextChatMessage(role, content string) (chatMessage, error) {
+func newTextChatMessage(role, name, content string) (chatMessage, error) {
 	role = strings.TrimSpace(role)
 	if role == "" {
 		return chatMessage{}, errors.New("text message role is required")
 	}
 
-	return chatMessage{Role: role, Content: content}, nil
+	return chatMessage{Role: role, Name: strings.TrimSpace(name), Content: content}, nil
 }
 
-func newContentChatMessage(role string, parts []core.ContentPart) (chatMessage, error) {
+func newContentChatMessage(role, name string, parts []core.ContentPart) (chatMessage, error) {
 	role = strings.TrimSpace(role)
 	if role == "" {
 		return chatMessage{}, errors.New("content message role is required")
@@ -251,7 +336,7 @@ func newContentChatMessage(role string, parts []core.ContentPart) (chatMessage,
 		return chatMessage{}, err
 	}
 
-	return chatMessage{Role: role, Content: contentParts}, nil
+	return chatMessage{Role: role, Name: strings.TrimSpace(name), Content: contentParts}, nil
 }
 
 func toChatContentParts(parts []core.ContentPart) ([]chatContentPart, error) {
@@ -261,6 +346,9 @@ func toChatContentParts(parts []core.ContentPart) ([]chatContentPart, error) {
 
 	out := make([]chatContentPart, 0, len(parts))
 	for i, part := range parts {
+		if raw, ok := rawPartValue(part); ok && !isOwnRawPart(raw) {
+			continue
+		}
 		contentPart, err := toChatContentPart(part)
 		if err != nil {
 			return nil, fmt.Errorf("content part at index %d: %w", i, err)
@@ -298,17 +386,72 @@ func toChatContentPart(part core.ContentPart) (chatContentPart, error) {
 		return audioContentPart(typed.Source)
 
 	case core.DocumentPart:
-		return documentContentPart(typed.Source)
+		return documentContentPart(typed.Source, typed.Metadata)
 	case *core.DocumentPart:
 		if typed == nil {
 			return chatContentPart{}, errors.New("document part is nil")
 		}
-		return documentContentPart(typed.Source)
+		return documentContentPart(typed.Source, typed.Metadata)
+
+	case core.FilePart:
+		return fileContentPart(typed)
+	case *core.FilePart:
+		if typed == nil {
+			return chatContentPart{}, errors.New("file part is nil")
+		}
+		return fileContentPart(*typed)
+
+	case core.RawPart:
+		payload, err := rawPartPayload(typed)
+		return chatContentPart{Raw: payload}, err
+	case *core.RawPart:
+		if typed == nil {
+			return chatContentPart{}, errors.New("raw part is nil")
+		}
+		payload, err := rawPartPayload(*typed)
+		return chatContentPart{Raw: payload}, err
 	}
 
 	return chatContentPart{}, fmt.Errorf("unsupported content part type %T", part)
 }
 
+// fileContentPart references an uploaded file. Chat and ChatStream upload
+// FileParts that carry data before converting messages.
+func fileContentPart(part core.FilePart) (chatContentPart, error) {
+	fileID := strings.TrimSpace(part.FileID)
+	if fileID == "" {
+		return chatContentPart{}, errors.New("file part requires a FileID or data")
+	}
+	return chatContentPart{Type: "file", File: &chatFile{FileID: fileID}}, nil
+}
+
+func rawPartValue(part core.ContentPart) (core.RawPart, bool) {
+	switch typed := part.(type) {
+	case core.RawPart:
+		return typed, true
+	case *core.RawPart:
+		if typed == nil {
+			return core.RawPart{}, false
+		}
+		return *typed, true
+	}
+	return core.RawPart{}, false
+}
+
+// isOwnRawPart reports whether a raw part targets this adapter; raw parts for
+// other providers are skipped.
+func isOwnRawPart(part core.RawPart) bool {
+	return strings.EqualFold(strings.TrimSpace(part.Provider), providerName)
+}
+
+func rawPartPayload(part core.RawPart) (json.RawMessage, error) {
+	payload := bytes.TrimSpace(part.Payload)
+	if len(payload) == 0 || payload[0] != '{' || !json.Valid(payload) {
+		return nil, errors.New("raw part payload must be a JSON object")
+	}
+	return json.RawMessage(payload), nil
+}
+
 func imageContentPart(source core.Source, metadata map[string]any) (chatContentPart, error) {
 	if source == nil {
 		return chatContentPart{}, errors.New("image source is required")
@@ -343,26 +486,102 @@ func audioContentPart(source core.Source) (chatContentPart, error) {
 	}, nil
 }
 
-func documentContentPart(source core.Source) (chatContentPart, error) {
+// documentContentPart converts a document into a chat completions file part.
+//
+// A "file_id" metadata entry references a file previously uploaded through the
+// Files API; otherwise the document must be inline base64 data and is sent as
+// file_data. An optional "filename" metadata entry overrides the default name.
+func documentContentPart(source core.Source, metadata map[string]any) (chatContentPart, error) {
+	if fileID := metadataString(metadata, "file_id"); fileID != "" {
+		return chatContentPart{Type: "file", File: &chatFile{FileID: fileID}}, nil
+	}
 	if source == nil {
 		return chatContentPart{}, errors.New("document source is required")
 	}
 
-	return chatContentPart{}, errors.New("openai: document content is not supported")
+	file, err := documentFileFromSource(source, metadata)
+	if err != nil {
+		return chatContentPart{}, err
+	}
+
+	return chatContentPart{Type: "file", File: file}, nil
+}
+
+func documentFileFromSource(source core.Source, metadata map[string]any) (*chatFile, error) {
+	switch typed := source.(type) {
+	case core.DataSource:
+		return documentFileFromDataSource(typed, metadata)
+	case *core.DataSource:
+		if typed == nil {
+			return nil, errors.New("document data source is nil")
+		}
+		return documentFileFromDataSource(*typed, metadata)
+
+	case core.URLSource, *core.URLSource:
+		return nil, errors.New("document URL source is not supported (use DataSource with base64 data or a file_id)")
+	}
+
+	return nil, fmt.Errorf("unsupported document source type %T", source)
+}
+
+func documentFileFromDataSource(source core.DataSource, metadata map[string]any) (*chatFile, error) {
+	data := strings.TrimSpace(source.Data)
+	if data == "" {
+		return nil, errors.New("document data is required")
+	}
+	if strings.HasPrefix(data, "data:") {
+		return nil, errors.New("document data must be raw base64")
+	}
+
+	mimeType := strings.TrimSpace(source.MimeType)
+	if mimeType == "" {
+		return nil, errors.New("document mime type is required")
+	}
+
+	filename := metadataString(metadata, "filename")
+	if filename == "" {
+		filename = "document" + documentExtensionFromMime(mimeType)
+	}
+
+	return &chatFile{
+		Filename: filename,
+		FileData: fmt.Sprintf("data:%s;base64,%s", mimeType, data),
+	}, nil
+}
+
+func documentExtensionFromMime(mimeType string) string {
+	switch strings.ToLower(strings.TrimSpace(mimeType)) {
+	case "application/pdf":
+		return ".pdf"
+	case "text/plain":
+		return ".txt"
+	case "text/markdown":
+		return ".md"
+	case "text/csv":
+		return ".csv"
+	case "application/json":
+		return ".json"
+	default:
+		return ""
+	}
 }
 
 func imageDetail(metadata map[string]any) string {
+	return metadataString(metadata, "detail")
+}
+
+func metadataString(metadata map[string]any, key string) string {
 	if metadata == nil {
 		return ""
 	}
 
-	value, ok := metadata["detail"]
+	value, ok := metadata[key]
 	if !ok {
 		return ""
 	}
 
-	if detail, ok := value.(string); ok {
-		return strings.TrimSpace(detail)
+	if text, ok := value.(string); ok {
+		return strings.TrimSpace(text)
 	}
 
 	return ""
@@ -654,6 +873,10 @@ func toChatTools(params *core.ChatParams) ([]chatTool, map[string]core.ServerToo
 			tools = append(tools, def)
 			clientTools[def.Function.Name] = struct{}{}
 
+		case core.ProviderTool, *core.ProviderTool:
+			// Provider tools are collected separately by toProviderTools.
+			continue
+
 		default:
 			return nil, nil, nil, fmt.Errorf("openai: unsupported tool type %T", union)
 		}
@@ -662,6 +885,91 @@ func toChatTools(params *core.ChatParams) ([]chatTool, map[string]core.ServerToo
 	return tools, serverTools, clientTools, nil
 }
 
+func toProviderTools(params *core.ChatParams) ([]core.ProviderTool, error) {
+	if params == nil || len(params.Tools) == 0 {
+		return nil, nil
+	}
+
+	out := make([]core.ProviderTool, 0)
+	for i, union := range params.Tools {
+		switch tool := union.(type) {
+		case core.ProviderTool:
+			if strings.TrimSpace(tool.Type) == "" {
+				return nil, fmt.Errorf("openai: provider tool at index %d is missing a type", i)
+			}
+			out = append(out, tool)
+		case *core.ProviderTool:
+			if tool == nil {
+				return nil, fmt.Errorf("openai: provider tool at index %d is nil", i)
+			}
+			if strings.TrimSpace(tool.Type) == "" {
+				return nil, fmt.Errorf("openai: provider tool at index %d is missing a type", i)
+			}
+			out = append(out, *tool)
+		}
+	}
+
+	return out, nil
+}
+
+// webSearchOptions maps provider web search tools onto the chat completions
+// web_search_options field. Chat completions has no other provider tools.
+func webSearchOptions(tools []core.ProviderTool) (any, error) {
+	var options any
+	for _, tool := range tools {
+		if !isWebSearchToolType(tool.Type) {
+			return nil, fmt.Errorf("openai: provider tool %q is not supported by chat completions", tool.Type)
+		}
+		if options != nil {
+			return nil, errors.New("openai: only one web search tool is supported")
+		}
+		if tool.Options == nil {
+			options = map[string]any{}
+			continue
+		}
+		options = tool.Options
+	}
+	return options, nil
+}
+
+func responsesToolDefinition(tool core.ProviderTool) map[string]any {
+	definition := make(map[string]any, len(tool.Options)+1)
+	for key, value := range tool.Options {
+		key = strings.TrimSpace(key)
+		if key != "" && value != nil {
+			definition[jsonKey(key)] = value
+		}
+	}
+	definition["type"] = strings.TrimSpace(tool.Type)
+	return definition
+}
+
+func isWebSearchToolType(toolType string) bool {
+	switch strings.TrimSpace(toolType) {
+	case "web_search", "web_search_preview":
+		return true
+	default:
+		return false
+	}
+}
+
+func toCoreCitations(annotations []annotation) []core.Citation {
+	var out []core.Citation
+	for _, item := range annotations {
+		if item.URLCitation == nil {
+			continue
+		}
+		out = append(out, core.Citation{
+			Type:       nonEmpty(item.Type, "url_citation"),
+			URL:        item.URLCitation.URL,
+			Title:      item.URLCitation.Title,
+			StartIndex: item.URLCitation.StartIndex,
+			EndIndex:   item.URLCitation.EndIndex,
+		})
+	}
+	return out
+}
+
 func newServerChatTool(tool core.ServerTool) (chatTool, core.ServerTool, error) {
 	name := strings.TrimSpace(tool.Name)
 	if name == "" {
@@ -703,21 +1011,27 @@ func chatToolFromDefinition(name, description string, parameters map[string]any)
 	}
 }
 
-func maxTokens(params *core.ChatParams) *int64 {
+// maxTokens returns the requested output limit, clamped to the model's
+// maximum output when the model catalog knows it.
+func maxTokens(model string, params *core.ChatParams) *int64 {
 	if params == nil {
 		return nil
 	}
-	if params.MaxTokens != nil {
-		return params.MaxTokens
-	}
-	if params.MaxOutputTokens != nil {
-		return params.MaxOutputTokens
-	}
-	if params.MaxLength > 0 {
-		value := params.MaxLength
-		return &value
+
+	var value int64
+	switch {
+	case params.MaxTokens != nil:
+		value = *params.MaxTokens
+	case params.MaxOutputTokens != nil:
+		value = *params.MaxOutputTokens
+	case params.MaxLength > 0:
+		value = params.MaxLength
+	default:
+		return nil
 	}
-	return nil
+
+	value = core.ClampOutputTokens(model, value)
+	return &value
 }
 
 func temperature(params *core.ChatParams) *float64 {
@@ -734,6 +1048,31 @@ func topP(params *core.ChatParams) *float64 {
 	return params.TopP
 }
 
+// stopSequences returns the chat completions stop list. The Responses API has
+// no stop parameter, so stop sequences are not sent there.
+func stopSequences(params *core.ChatParams) []string {
+	if params == nil {
+		return nil
+	}
+
+	var out []string
+	for _, sequence := range params.StopSequences {
+		if sequence != "" {
+			out = append(out, sequence)
+		}
+	}
+	return out
+}
+
+// seed returns the chat completions seed. The Responses API has no seed
+// parameter, so it is not sent there.
+func seed(params *core.ChatParams) *int64 {
+	if params == nil {
+		return nil
+	}
+	return params.Seed
+}
+
 func metadata(params *core.ChatParams) map[string]any {
 	if params == nil || len(params.Metadata) == 0 {
 		return nil
@@ -764,3 +1103,35 @@ func maxLoops(params *core.ChatParams, hasServerTools bool) int {
 	}
 	return defaultMaxAgenticLoops
 }
+
+func parallelToolCalls(params *core.ChatParams) *bool {
+	if params == nil {
+		return nil
+	}
+	return params.ParallelToolCalls
+}
+
+// toolChoice converts the tool choice into a tool_choice value, defaulting
+// to "auto". Chat completions nest a forced tool name under "function" while
+// the Responses API puts it at the top level.
+func toolChoice(params *core.ChatParams, nested bool) (any, error) {
+	if params == nil || params.ToolChoice == nil {
+		return core.ToolChoiceAuto, nil
+	}
+
+	if name := strings.TrimSpace(params.ToolChoice.Name); name != "" {
+		if nested {
+			return map[string]any{"type": "function", "function": map[string]any{"name": name}}, nil
+		}
+		return map[string]any{"type": "function", "name": name}, nil
+	}
+
+	switch mode := strings.TrimSpace(params.ToolChoice.Mode); mode {
+	case "", core.ToolChoiceAuto:
+		return core.ToolChoiceAuto, nil
+	case core.ToolChoiceNone, core.ToolChoiceRequired:
+		return mode, nil
+	default:
+		return nil, fmt.Errorf("openai: unsupported tool choice mode %q", mode)
+	}
+}
@@ -1,8 +1,10 @@
 package openai
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/m43i/go-ai/core"
 )
@@ -120,6 +122,41 @@ func TestToChatMessageContentMessagePartNoParts(t *testing.T) {
 	}
 }
 
+func TestToChatMessagePassesName(t *testing.T) {
+	t.Parallel()
+
+	result, err := toChatMessage(core.TextMessagePart{
+		Role:      "user",
+		Content:   "Hi",
+		Name:      " alice ",
+		ID:        "msg_1",
+		CreatedAt: time.Unix(1700000000, 0),
+		Metadata:  map[string]any{"tenant": "acme"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Name != "alice" {
+		t.Fatalf("expected name alice, got %q", result.Name)
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(body) != `{"role":"user","name":"alice","content":"Hi"}` {
+		t.Fatalf("unexpected message JSON %s", body)
+	}
+
+	result, err = toChatMessage(&core.ContentMessagePart{Role: "user", Name: "bob", Parts: []core.ContentPart{core.TextPart{Text: "Hi"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Name != "bob" {
+		t.Fatalf("expected name bob, got %q", result.Name)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // toChatContentPart — TextPart
 // ---------------------------------------------------------------------------
@@ -493,7 +530,63 @@ func TestAudioContentPartEmptyMimeType(t *testing.T) {
 // toChatContentPart — DocumentPart
 // ---------------------------------------------------------------------------
 
-func TestDocumentContentPartNotSupported(t *testing.T) {
+func TestDocumentContentPartDataSource(t *testing.T) {
+	t.Parallel()
+
+	part := core.DocumentPart{
+		Source: core.DataSource{Data: "JVBERi0=", MimeType: "application/pdf"},
+	}
+	result, err := toChatContentPart(part)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Type != "file" {
+		t.Fatalf("unexpected type: %q", result.Type)
+	}
+	if result.File == nil {
+		t.Fatal("expected file payload")
+	}
+	if result.File.FileData != "data:application/pdf;base64,JVBERi0=" {
+		t.Fatalf("unexpected file data: %q", result.File.FileData)
+	}
+	if result.File.Filename != "document.pdf" {
+		t.Fatalf("unexpected filename: %q", result.File.Filename)
+	}
+}
+
+func TestDocumentContentPartFilenameMetadata(t *testing.T) {
+	t.Parallel()
+
+	part := core.DocumentPart{
+		Source:   core.DataSource{Data: "JVBERi0=", MimeType: "application/pdf"},
+		Metadata: map[string]any{"filename": "report.pdf"},
+	}
+	result, err := toChatContentPart(part)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.File.Filename != "report.pdf" {
+		t.Fatalf("unexpected filename: %q", result.File.Filename)
+	}
+}
+
+func TestDocumentContentPartFileID(t *testing.T) {
+	t.Parallel()
+
+	part := core.DocumentPart{Metadata: map[string]any{"file_id": "file-abc"}}
+	result, err := toChatContentPart(part)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.File == nil || result.File.FileID != "file-abc" {
+		t.Fatalf("unexpected file payload: %#v", result.File)
+	}
+	if result.File.FileData != "" {
+		t.Fatalf("expected no inline data, got %q", result.File.FileData)
+	}
+}
+
+func TestDocumentContentPartURLNotSupported(t *testing.T) {
 	t.Parallel()
 
 	part := core.DocumentPart{
@@ -501,13 +594,43 @@ func TestDocumentContentPartNotSupported(t *testing.T) {
 	}
 	_, err := toChatContentPart(part)
 	if err == nil {
-		t.Fatal("expected error for document content")
+		t.Fatal("expected error for document URL source")
 	}
 	if !strings.Contains(err.Error(), "not supported") {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
 
+func TestDocumentContentPartRejectsDataPrefix(t *testing.T) {
+	t.Parallel()
+
+	part := core.DocumentPart{
+		Source: core.DataSource{Data: "data:application/pdf;base64,JVBERi0=", MimeType: "application/pdf"},
+	}
+	_, err := toChatContentPart(part)
+	if err == nil {
+		t.Fatal("expected error for data URL prefix")
+	}
+}
+
+func TestResponseDocumentContentPart(t *testing.T) {
+	t.Parallel()
+
+	parts, err := toResponseContentParts([]core.ContentPart{
+		core.DocumentPart{Source: core.DataSource{Data: "JVBERi0=", MimeType: "application/pdf"}},
+		core.DocumentPart{Source: core.URLSource{URL: "https://example.com/doc.pdf"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parts[0].Type != "input_file" || parts[0].FileData != "data:application/pdf;base64,JVBERi0=" || parts[0].Filename != "document.pdf" {
+		t.Fatalf("unexpected inline file part: %#v", parts[0])
+	}
+	if parts[1].Type != "input_file" || parts[1].FileURL != "https://example.com/doc.pdf" {
+		t.Fatalf("unexpected URL file part: %#v", parts[1])
+	}
+}
+
 func TestDocumentContentPartNilSource(t *testing.T) {
 	t.Parallel()
 
@@ -684,3 +807,112 @@ func TestToChatMessagesNilParams(t *testing.T) {
 		t.Fatal("expected error for nil params")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// toCoreFinishReason
+// ---------------------------------------------------------------------------
+
+func TestToCoreFinishReason(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]core.FinishReason{
+		"stop":              core.FinishReasonStop,
+		"completed":         core.FinishReasonStop,
+		"length":            core.FinishReasonLength,
+		"max_output_tokens": core.FinishReasonLength,
+		"tool_calls":        core.FinishReasonToolCalls,
+		"function_call":     core.FinishReasonToolCalls,
+		"content_filter":    core.FinishReasonContentFilter,
+		"failed":            core.FinishReasonError,
+	}
+	for raw, want := range tests {
+		if got := toCoreFinishReason(raw); got != want {
+			t.Fatalf("toCoreFinishReason(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestToChatContentPartsInjectsOwnRawParts(t *testing.T) {
+	t.Parallel()
+
+	parts, err := toChatContentParts([]core.ContentPart{
+		core.TextPart{Text: "Hi"},
+		core.RawPart{Provider: "openai", Payload: json.RawMessage(`{"type":"input_video","video_url":"https://example.com/v.mp4"}`)},
+		&core.RawPart{Provider: "claude", Payload: json.RawMessage(`{"type":"container_upload"}`)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, err := json.Marshal(parts)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	expected := `[{"type":"text","text":"Hi"},{"type":"input_video","video_url":"https://example.com/v.mp4"}]`
+	if string(body) != expected {
+		t.Fatalf("unexpected parts JSON %s", body)
+	}
+
+	responseParts, err := toResponseContentParts([]core.ContentPart{core.RawPart{Provider: "OpenAI", Payload: json.RawMessage(`{"type":"input_new"}`)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ = json.Marshal(responseParts)
+	if string(body) != `[{"type":"input_new"}]` {
+		t.Fatalf("unexpected response parts JSON %s", body)
+	}
+
+	if _, err := toChatContentParts([]core.ContentPart{core.RawPart{Provider: "openai", Payload: json.RawMessage(`"text"`)}}); err == nil {
+		t.Fatal("expected error for non-object raw payload")
+	}
+}
+
+func TestToolChoice(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		choice   *core.ToolChoice
+		nested   bool
+		expected string
+	}{
+		{nil, true, `"auto"`},
+		{&core.ToolChoice{Mode: core.ToolChoiceNone}, true, `"none"`},
+		{&core.ToolChoice{Mode: core.ToolChoiceRequired}, false, `"required"`},
+		{&core.ToolChoice{Name: "weather"}, true, `{"function":{"name":"weather"},"type":"function"}`},
+		{&core.ToolChoice{Name: "weather"}, false, `{"name":"weather","type":"function"}`},
+	}
+	for _, test := range tests {
+		choice, err := toolChoice(&core.ChatParams{ToolChoice: test.choice}, test.nested)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		body, _ := json.Marshal(choice)
+		if string(body) != test.expected {
+			t.Fatalf("tool choice %#v: got %s, want %s", test.choice, body, test.expected)
+		}
+	}
+
+	if _, err := toolChoice(&core.ChatParams{ToolChoice: &core.ToolChoice{Mode: "sometimes"}}, true); err == nil {
+		t.Fatal("expected error for unknown tool choice mode")
+	}
+}
+
+func TestMaxTokensClampsToModelCatalog(t *testing.T) {
+	t.Parallel()
+
+	requested := int64(50000)
+	params := &core.ChatParams{MaxOutputTokens: &requested}
+
+	if got := maxTokens("gpt-4o-2024-08-06", params); got == nil || *got != 16384 {
+		t.Fatalf("expected clamp to 16384, got %v", got)
+	}
+	if got := maxTokens("ft:custom-model", params); got == nil || *got != requested {
+		t.Fatalf("unknown models must not be clamped, got %v", got)
+	}
+	if requested != 50000 {
+		t.Fatal("caller value was modified")
+	}
+	if got := maxTokens("gpt-4o", &core.ChatParams{}); got != nil {
+		t.Fatalf("expected no limit when none is requested, got %v", *got)
+	}
+}
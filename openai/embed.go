@@ -1,15 +1,13 @@
 package openai
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
 )
 
 // Embed creates one embedding vector for params.Input.
@@ -81,9 +79,10 @@ func embeddingRequestFromSingle(model string, params *core.EmbedParams) (embeddi
 	}
 
 	return embeddingRequest{
-		Model:      model,
-		Input:      input,
-		Dimensions: params.Dimensions,
+		Model:        model,
+		Input:        input,
+		Dimensions:   params.Dimensions,
+		ModelOptions: params.ModelOptions,
 	}, 1, nil
 }
 
@@ -109,42 +108,23 @@ func embeddingRequestFromMany(model string, params *core.EmbedManyParams) (embed
 	}
 
 	return embeddingRequest{
-		Model:      model,
-		Input:      inputs,
-		Dimensions: params.Dimensions,
+		Model:        model,
+		Input:        inputs,
+		Dimensions:   params.Dimensions,
+		ModelOptions: params.ModelOptions,
 	}, len(inputs), nil
 }
 
 func (a *Adapter) postEmbeddings(ctx context.Context, request *embeddingRequest) (*embeddingResponse, error) {
-	body, err := json.Marshal(request)
+	body, err := marshalWithModelOptions(request, request.ModelOptions)
 	if err != nil {
 		return nil, fmt.Errorf("openai: marshal embeddings request: %w", err)
 	}
 
-	url := strings.TrimRight(a.baseURL(), "/") + "/embeddings"
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("openai: build embeddings request: %w", err)
-	}
-
-	httpReq.Header.Set("Authorization", "Bearer "+a.APIKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	httpResp, err := a.client().Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("openai: embeddings request failed: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode >= http.StatusBadRequest {
-		return nil, decodeAPIError(httpResp)
-	}
-
 	var response embeddingResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("openai: decode embeddings response: %w", err)
+	if _, err := a.transport().Send(ctx, httpclient.Request{Path: "/embeddings", Name: "embeddings", Body: body}, &response); err != nil {
+		return nil, err
 	}
-
 	return &response, nil
 }
 
@@ -4,6 +4,8 @@ type embeddingRequest struct {
 	Model      string `json:"model"`
 	Input      any    `json:"input"`
 	Dimensions *int64 `json:"dimensions,omitempty"`
+
+	ModelOptions map[string]any `json:"-"`
 }
 
 type embeddingResponse struct {
@@ -0,0 +1,235 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+// fileUploadPurpose is the Files API purpose for files used as model input.
+const fileUploadPurpose = "user_data"
+
+// fileIDCache remembers the file ID returned for each uploaded file so that
+// identical content is only uploaded once.
+type fileIDCache struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+type fileUploadResponse struct {
+	ID string `json:"id"`
+}
+
+// inlineURLSources downloads the URLSource media OpenAI does not accept by
+// URL when a URLFetcher is configured.
+func (a *Adapter) inlineURLSources(ctx context.Context, params *core.ChatParams) (*core.ChatParams, error) {
+	if a.URLFetcher == nil {
+		return params, nil
+	}
+	capabilities := []string{core.CapabilityAudioInput}
+	if a.textEndpoint() != EndpointResponses {
+		capabilities = append(capabilities, core.CapabilityDocuments)
+	}
+
+	return a.URLFetcher.InlineURLs(ctx, params, capabilities...)
+}
+
+// resolveFileParts uploads every FilePart that carries data but no FileID and
+// returns params with those parts replaced by their uploaded references. The
+// original params are not modified; params is returned as is when nothing
+// needs uploading.
+func (a *Adapter) resolveFileParts(ctx context.Context, params *core.ChatParams) (*core.ChatParams, error) {
+	if params == nil || !hasPendingFileParts(params.Messages) {
+		return params, nil
+	}
+
+	messages := make([]core.MessageUnion, len(params.Messages))
+	for i, union := range params.Messages {
+		resolved, err := a.resolveMessageFiles(ctx, union)
+		if err != nil {
+			return nil, fmt.Errorf("openai: invalid message at index %d: %w", i, err)
+		}
+		messages[i] = resolved
+	}
+
+	resolved := *params
+	resolved.Messages = messages
+	return &resolved, nil
+}
+
+func (a *Adapter) resolveMessageFiles(ctx context.Context, union core.MessageUnion) (core.MessageUnion, error) {
+	switch msg := union.(type) {
+	case core.ContentMessagePart:
+		parts, err := a.resolvePartFiles(ctx, msg.Parts)
+		if err != nil {
+			return nil, err
+		}
+		msg.Parts = parts
+		return msg, nil
+	case *core.ContentMessagePart:
+		if msg == nil {
+			return union, nil
+		}
+		copied := *msg
+		parts, err := a.resolvePartFiles(ctx, copied.Parts)
+		if err != nil {
+			return nil, err
+		}
+		copied.Parts = parts
+		return &copied, nil
+	}
+
+	return union, nil
+}
+
+func (a *Adapter) resolvePartFiles(ctx context.Context, parts []core.ContentPart) ([]core.ContentPart, error) {
+	out := make([]core.ContentPart, len(parts))
+	for i, part := range parts {
+		file, ok := filePartValue(part)
+		if !ok || strings.TrimSpace(file.FileID) != "" {
+			out[i] = part
+			continue
+		}
+
+		fileID, err := a.uploadFile(ctx, file)
+		if err != nil {
+			return nil, fmt.Errorf("content part at index %d: %w", i, err)
+		}
+		file.FileID = fileID
+		file.Data = nil
+		out[i] = file
+	}
+	return out, nil
+}
+
+// uploadFile uploads file through the Files API, reusing the ID of an earlier
+// upload with the same content.
+func (a *Adapter) uploadFile(ctx context.Context, file core.FilePart) (string, error) {
+	if len(file.Data) == 0 {
+		return "", errors.New("file part requires a FileID or data")
+	}
+
+	key := fileCacheKey(file)
+	cache := a.fileIDs
+	if cache != nil {
+		cache.mu.Lock()
+		fileID, ok := cache.ids[key]
+		cache.mu.Unlock()
+		if ok {
+			return fileID, nil
+		}
+	}
+
+	body, contentType, err := buildFileUploadForm(file)
+	if err != nil {
+		return "", err
+	}
+
+	var response fileUploadResponse
+	upload := httpclient.Request{Path: "/files", Name: "file upload", Body: body.Bytes(), ContentType: contentType}
+	if _, err := a.transport().Send(ctx, upload, &response); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(response.ID) == "" {
+		return "", errors.New("openai: file upload response did not include an ID")
+	}
+
+	if cache != nil {
+		cache.mu.Lock()
+		if cache.ids == nil {
+			cache.ids = make(map[string]string)
+		}
+		cache.ids[key] = response.ID
+		cache.mu.Unlock()
+	}
+
+	return response.ID, nil
+}
+
+func buildFileUploadForm(file core.FilePart) (*bytes.Buffer, string, error) {
+	filename := strings.TrimSpace(file.Filename)
+	if filename == "" {
+		filename = "file" + documentExtensionFromMime(file.MimeType)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	if err := writer.WriteField("purpose", fileUploadPurpose); err != nil {
+		return nil, "", fmt.Errorf("openai: write purpose field: %w", err)
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	mimeType := strings.TrimSpace(file.MimeType)
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	header.Set("Content-Type", mimeType)
+
+	filePart, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, "", fmt.Errorf("openai: create file form field: %w", err)
+	}
+	if _, err := filePart.Write(file.Data); err != nil {
+		return nil, "", fmt.Errorf("openai: write file data: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("openai: close multipart writer: %w", err)
+	}
+
+	return &buf, writer.FormDataContentType(), nil
+}
+
+func fileCacheKey(file core.FilePart) string {
+	sum := sha256.Sum256(file.Data)
+	return strings.TrimSpace(file.MimeType) + ":" + hex.EncodeToString(sum[:])
+}
+
+func hasPendingFileParts(messages []core.MessageUnion) bool {
+	for _, union := range messages {
+		var parts []core.ContentPart
+		switch msg := union.(type) {
+		case core.ContentMessagePart:
+			parts = msg.Parts
+		case *core.ContentMessagePart:
+			if msg != nil {
+				parts = msg.Parts
+			}
+		}
+		for _, part := range parts {
+			if file, ok := filePartValue(part); ok && strings.TrimSpace(file.FileID) == "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func filePartValue(part core.ContentPart) (core.FilePart, bool) {
+	switch typed := part.(type) {
+	case core.FilePart:
+		return typed, true
+	case *core.FilePart:
+		if typed == nil {
+			return core.FilePart{}, false
+		}
+		return *typed, true
+	}
+	return core.FilePart{}, false
+}
+
+func isImageMimeType(mimeType string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(mimeType)), "image/")
+}
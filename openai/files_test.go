@@ -0,0 +1,153 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatUploadsFilePartOnce(t *testing.T) {
+	t.Parallel()
+
+	var uploads atomic.Int32
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/files":
+			uploads.Add(1)
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Fatalf("parse upload form: %v", err)
+			}
+			if r.FormValue("purpose") != "user_data" {
+				t.Fatalf("unexpected purpose %q", r.FormValue("purpose"))
+			}
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("read uploaded file: %v", err)
+			}
+			data, _ := io.ReadAll(file)
+			if string(data) != "%PDF-1.7" || header.Filename != "report.pdf" {
+				t.Fatalf("unexpected upload %q named %q", data, header.Filename)
+			}
+			_, _ = w.Write([]byte(`{"id":"file-abc","object":"file"}`))
+		case "/chat/completions":
+			var request map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			requests = append(requests, request)
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"done"},"finish_reason":"stop"}]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	message := core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+		core.TextPart{Text: "Summarize"},
+		core.FilePart{Data: []byte("%PDF-1.7"), Filename: "report.pdf", MimeType: "application/pdf"},
+	}}
+
+	for range 2 {
+		result, err := adapter.Chat(context.Background(), &core.ChatParams{Messages: []core.MessageUnion{message}})
+		if err != nil {
+			t.Fatalf("chat returned error: %v", err)
+		}
+		sent, ok := result.Messages[0].(core.ContentMessagePart)
+		if !ok {
+			t.Fatalf("unexpected conversation message %#v", result.Messages[0])
+		}
+		if file := sent.Parts[1].(core.FilePart); file.FileID != "file-abc" || file.Data != nil {
+			t.Fatalf("expected uploaded file reference in conversation, got %#v", file)
+		}
+	}
+
+	if uploads.Load() != 1 {
+		t.Fatalf("expected one upload, got %d", uploads.Load())
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 chat requests, got %d", len(requests))
+	}
+	content := requests[0]["messages"].([]any)[0].(map[string]any)["content"].([]any)
+	file := content[1].(map[string]any)
+	if file["type"] != "file" || file["file"].(map[string]any)["file_id"] != "file-abc" {
+		t.Fatalf("unexpected file content part %#v", file)
+	}
+	if message.Parts[1].(core.FilePart).FileID != "" {
+		t.Fatal("caller message was modified")
+	}
+}
+
+func TestResponseFileContentPartUsesInputImageForImages(t *testing.T) {
+	t.Parallel()
+
+	parts, err := toResponseContentParts([]core.ContentPart{
+		core.FilePart{FileID: "file-img", MimeType: "image/png"},
+		&core.FilePart{FileID: "file-doc", MimeType: "application/pdf"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parts[0].Type != "input_image" || parts[0].FileID != "file-img" {
+		t.Fatalf("unexpected image part %#v", parts[0])
+	}
+	if parts[1].Type != "input_file" || parts[1].FileID != "file-doc" {
+		t.Fatalf("unexpected file part %#v", parts[1])
+	}
+
+	if _, err := toChatContentPart(core.FilePart{}); err == nil {
+		t.Fatal("expected error for file part without ID or data")
+	}
+}
+
+func TestChatInlinesAudioURLsWithURLFetcher(t *testing.T) {
+	t.Parallel()
+
+	wav := []byte("RIFF\x24\x00\x00\x00WAVEfmt ")
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/clip.wav":
+			_, _ = w.Write(wav)
+		case "/chat/completions":
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"heard"},"finish_reason":"stop"}]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-4o-audio-preview", WithAPIKey("test-key"), WithBaseURL(server.URL), WithURLFetcher(&core.URLFetcher{AllowPrivateNetworks: true}))
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{Messages: []core.MessageUnion{
+		core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+			core.AudioPart{Source: core.URLSource{URL: server.URL + "/clip.wav"}},
+			core.ImagePart{Source: core.URLSource{URL: "https://example.com/cat.png"}},
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("chat: %v", err)
+	}
+
+	parts := request["messages"].([]any)[0].(map[string]any)["content"].([]any)
+	audio := parts[0].(map[string]any)["input_audio"].(map[string]any)
+	if audio["format"] != "wav" || audio["data"] != base64.StdEncoding.EncodeToString(wav) {
+		t.Fatalf("unexpected audio part %#v", audio)
+	}
+	image := parts[1].(map[string]any)["image_url"].(map[string]any)
+	if image["url"] != "https://example.com/cat.png" {
+		t.Fatalf("expected the image URL to be sent as is, got %#v", image)
+	}
+}
@@ -1,17 +1,15 @@
 package openai
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
 )
 
 var imageGenerationCounter uint64
@@ -153,35 +151,10 @@ func normalizeImageModelOptionKey(key string) string {
 }
 
 func (a *Adapter) postImageGeneration(ctx context.Context, request map[string]any) (*imageGenerationResponse, error) {
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("openai: marshal image generation request: %w", err)
-	}
-
-	url := strings.TrimRight(a.baseURL(), "/") + "/images/generations"
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("openai: build image generation request: %w", err)
-	}
-
-	httpReq.Header.Set("Authorization", "Bearer "+a.APIKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	httpResp, err := a.client().Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("openai: image generation request failed: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode >= http.StatusBadRequest {
-		return nil, decodeAPIError(httpResp)
-	}
-
 	var response imageGenerationResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("openai: decode image generation response: %w", err)
+	if _, err := a.transport().Send(ctx, httpclient.Request{Path: "/images/generations", Name: "image generation", Body: request}, &response); err != nil {
+		return nil, err
 	}
-
 	return &response, nil
 }
 
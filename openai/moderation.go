@@ -0,0 +1,53 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+// defaultModerationModel is used when the adapter's model is not a
+// moderation model, so a chat adapter can moderate its own traffic.
+const defaultModerationModel = "omni-moderation-latest"
+
+// Moderate classifies params.Inputs with OpenAI's moderation endpoint. It
+// uses the adapter's model when it is a moderation model, such as
+// "omni-moderation-latest", and omni-moderation-latest otherwise.
+func (a *Adapter) Moderate(ctx context.Context, params *core.ModerationParams) (*core.ModerationResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("openai: moderation params are required")
+	}
+	if len(params.Inputs) == 0 {
+		return nil, errors.New("openai: moderation inputs are required")
+	}
+
+	model := strings.TrimSpace(a.Model)
+	if !strings.Contains(model, "moderation") {
+		model = defaultModerationModel
+	}
+	body, err := marshalWithModelOptions(moderationRequest{Model: model, Input: params.Inputs}, params.ModelOptions)
+	if err != nil {
+		return nil, fmt.Errorf("openai: marshal moderation request: %w", err)
+	}
+
+	var response moderationResponse
+	if _, err := a.transport().Send(ctx, httpclient.Request{Path: "/moderations", Name: "moderations", Body: body}, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Results) != len(params.Inputs) {
+		return nil, fmt.Errorf("openai: moderation response has %d results for %d inputs", len(response.Results), len(params.Inputs))
+	}
+
+	results := make([]core.Moderation, len(response.Results))
+	for i, result := range response.Results {
+		results[i] = core.Moderation{Flagged: result.Flagged, Categories: result.Categories, Scores: result.CategoryScores}
+	}
+	return &core.ModerationResult{Model: response.Model, Results: results}, nil
+}
@@ -0,0 +1,54 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestModerate(t *testing.T) {
+	t.Parallel()
+
+	var request moderationRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/moderations" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"modr-1","model":"omni-moderation-2024-09-26","results":[
+			{"flagged":false,"categories":{"violence":false},"category_scores":{"violence":0.01}},
+			{"flagged":true,"categories":{"violence":true},"category_scores":{"violence":0.93}}
+		]}`))
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-4o-mini", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	result, err := core.Moderate(context.Background(), adapter, &core.ModerationParams{Inputs: []string{"hello", "threat"}})
+	if err != nil {
+		t.Fatalf("Moderate() error = %v", err)
+	}
+	if request.Model != defaultModerationModel || len(request.Input) != 2 {
+		t.Fatalf("request = %+v", request)
+	}
+	if result.Model != "omni-moderation-2024-09-26" || len(result.Results) != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	if flagged := result.Results[1]; !flagged.Flagged || !flagged.Categories["violence"] || flagged.Scores["violence"] != 0.93 {
+		t.Fatalf("flagged result = %+v", flagged)
+	}
+
+	moderation := New("text-moderation-stable", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	if _, err := moderation.Moderate(context.Background(), &core.ModerationParams{Inputs: []string{"a", "b"}}); err != nil || request.Model != "text-moderation-stable" {
+		t.Fatalf("Moderate() model = %q, error = %v", request.Model, err)
+	}
+	if _, err := adapter.Moderate(context.Background(), &core.ModerationParams{}); err == nil {
+		t.Fatal("Moderate() expected error without inputs")
+	}
+}
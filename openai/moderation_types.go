@@ -0,0 +1,18 @@
+package openai
+
+type moderationRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type moderationResponse struct {
+	ID      string             `json:"id"`
+	Model   string             `json:"model"`
+	Results []moderationResult `json:"results"`
+}
+
+type moderationResult struct {
+	Flagged        bool               `json:"flagged"`
+	Categories     map[string]bool    `json:"categories"`
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
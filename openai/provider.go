@@ -0,0 +1,51 @@
+package openai
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func init() {
+	core.RegisterProvider(providerName, newFromConfig)
+}
+
+// newFromConfig builds an adapter for core.NewAdapter. It supports the
+// options timeout, endpoint ("chat_completions" or "responses"), gzip, and
+// prompt_size_check.
+func newFromConfig(config core.ProviderConfig) (core.TextAdapter, error) {
+	opts := []Option{WithAPIKey(config.APIKey), WithBaseURL(config.BaseURL)}
+	for name, value := range config.Options {
+		switch name {
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("option timeout: %w", err)
+			}
+			opts = append(opts, WithTimeout(timeout))
+		case "endpoint":
+			if value != EndpointChatCompletions && value != EndpointResponses {
+				return nil, fmt.Errorf("option endpoint: unknown endpoint %q", value)
+			}
+			opts = append(opts, WithEndpoint(value))
+		case "gzip", "prompt_size_check":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			if !enabled {
+				continue
+			}
+			if name == "gzip" {
+				opts = append(opts, WithGzip())
+			} else {
+				opts = append(opts, WithPromptSizeCheck())
+			}
+		default:
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	return New(config.Model, opts...), nil
+}
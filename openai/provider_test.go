@@ -0,0 +1,26 @@
+package openai
+
+import (
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestFromDSN(t *testing.T) {
+	t.Parallel()
+
+	adapter, err := core.FromDSN("openai://text-embedding-3-small?api_key=sk-test&endpoint=responses&base_url=https://gateway.example.com/v1")
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	openai := adapter.(*Adapter)
+	if openai.Model != "text-embedding-3-small" || openai.APIKey != "sk-test" || openai.Endpoint != EndpointResponses || openai.BaseURL != "https://gateway.example.com/v1" {
+		t.Fatalf("adapter = %+v", openai)
+	}
+	if _, ok := adapter.(core.EmbeddingAdapter); !ok {
+		t.Fatal("adapter does not implement core.EmbeddingAdapter")
+	}
+	if _, err := core.FromDSN("openai://gpt-4o?endpoint=completions"); err == nil {
+		t.Fatal("FromDSN() expected error for an unknown endpoint")
+	}
+}
@@ -1,17 +1,18 @@
 package openai
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"strings"
+	"time"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/sse"
 )
 
 func (a *Adapter) chatResponses(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
@@ -42,11 +43,15 @@ func (a *Adapter) chatResponses(ctx context.Context, params *core.ChatParams) (*
 		if len(toolCalls) == 0 {
 			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: text})
 			return &core.ChatResult{
-				Text:         text,
-				Reasoning:    joinReasoningParts(reasoningParts),
-				Messages:     append([]core.MessageUnion(nil), conversation...),
-				FinishReason: responseFinishReason(response),
-				Usage:        toCoreResponsesUsage(response.Usage),
+				Text:            text,
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				Citations:       responseCitations(response),
+				FinishReason:    toCoreFinishReason(responseFinishReason(response)),
+				RawFinishReason: responseFinishReason(response),
+				Usage:           timedUsage(toCoreResponsesUsage(response.Usage), response.Duration, response.RequestID),
+				RateLimit:       response.RateLimit,
+				RequestID:       response.RequestID,
 			}, nil
 		}
 
@@ -76,11 +81,14 @@ func (a *Adapter) chatResponses(ctx context.Context, params *core.ChatParams) (*
 
 		if len(pendingClientCalls) > 0 {
 			return &core.ChatResult{
-				Reasoning:    joinReasoningParts(reasoningParts),
-				Messages:     append([]core.MessageUnion(nil), conversation...),
-				ToolCalls:    pendingClientCalls,
-				FinishReason: "tool_calls",
-				Usage:        toCoreResponsesUsage(response.Usage),
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				ToolCalls:       pendingClientCalls,
+				FinishReason:    core.FinishReasonToolCalls,
+				RawFinishReason: responseFinishReason(response),
+				Usage:           timedUsage(toCoreResponsesUsage(response.Usage), response.Duration, response.RequestID),
+				RateLimit:       response.RateLimit,
+				RequestID:       response.RequestID,
 			}, nil
 		}
 	}
@@ -94,6 +102,7 @@ func (a *Adapter) chatResponsesStream(ctx context.Context, params *core.ChatPara
 		return nil, err
 	}
 
+	start := time.Now()
 	out := make(chan core.StreamChunk, 64)
 	go func() {
 		defer close(out)
@@ -101,22 +110,25 @@ func (a *Adapter) chatResponsesStream(ctx context.Context, params *core.ChatPara
 		if len(serverTools) > 0 || len(clientTools) > 0 || (params != nil && params.Output != nil) {
 			result, err := a.chatResponses(ctx, params)
 			if err != nil {
-				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error()}
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: core.RequestIDOf(err)}
 				return
 			}
 			emitChunksFromResult(out, params, result)
-			out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: nonEmpty(result.FinishReason, defaultFinishReason(result)), Reasoning: result.Reasoning, Usage: result.Usage}
+			out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: result.FinishReason, RawFinishReason: result.RawFinishReason, Reasoning: result.Reasoning, Usage: result.Usage, RequestID: result.RequestID}
 			return
 		}
 
 		request.Input = input
 		request.Stream = true
-		if err := a.streamResponses(ctx, &request, out); err != nil {
-			out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error()}
+		if requestID, err := a.streamResponses(ctx, &request, out); err != nil {
+			if requestID == "" {
+				requestID = core.RequestIDOf(err)
+			}
+			out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: requestID}
 		}
 	}()
 
-	return out, nil
+	return core.TimeStream(ctx, out, start), nil
 }
 
 func (a *Adapter) buildResponsesRequestTemplate(params *core.ChatParams) (responsesRequest, []responseInputItem, map[string]core.ServerTool, map[string]struct{}, int, error) {
@@ -125,23 +137,40 @@ func (a *Adapter) buildResponsesRequestTemplate(params *core.ChatParams) (respon
 		return responsesRequest{}, nil, nil, nil, 0, err
 	}
 
-	tools, serverTools, clientTools, err := toChatTools(params)
+	functionTools, serverTools, clientTools, err := toChatTools(params)
 	if err != nil {
 		return responsesRequest{}, nil, nil, nil, 0, err
 	}
 
+	providerTools, err := toProviderTools(params)
+	if err != nil {
+		return responsesRequest{}, nil, nil, nil, 0, err
+	}
+
+	var tools []any
+	for _, tool := range functionTools {
+		tools = append(tools, tool)
+	}
+	for _, tool := range providerTools {
+		tools = append(tools, responsesToolDefinition(tool))
+	}
+
 	request := responsesRequest{
 		Model:           a.Model,
 		Instructions:    instructions,
 		Tools:           tools,
-		MaxOutputTokens: maxTokens(params),
+		MaxOutputTokens: maxTokens(a.Model, params),
 		Temperature:     temperature(params),
 		TopP:            topP(params),
 		Metadata:        metadata(params),
 		ModelOptions:    modelOptions(params),
 	}
 	if len(tools) > 0 {
-		request.ToolChoice = "auto"
+		request.ToolChoice, err = toolChoice(params, false)
+		if err != nil {
+			return responsesRequest{}, nil, nil, nil, 0, err
+		}
+		request.ParallelToolCalls = parallelToolCalls(params)
 	}
 	if params != nil && params.Output != nil {
 		request.Text = responseTextFormat(params.Output)
@@ -173,88 +202,67 @@ func (a *Adapter) postResponses(ctx context.Context, request *responsesRequest)
 		return nil, fmt.Errorf("openai: marshal responses request: %w", err)
 	}
 
-	url := strings.TrimRight(a.baseURL(), "/") + "/responses"
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("openai: build responses request: %w", err)
-	}
-	httpReq.Header.Set("Authorization", "Bearer "+a.APIKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	httpResp, err := a.client().Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("openai: responses request failed: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode >= http.StatusBadRequest {
-		return nil, decodeAPIError(httpResp)
-	}
-
-	bodyBytes, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("openai: read responses body: %w", err)
-	}
-
 	var response responsesResponse
-	if err := json.Unmarshal(bodyBytes, &response); err != nil {
-		return nil, fmt.Errorf("openai: decode responses response: %w", err)
+	httpResp, err := a.transport().Send(ctx, httpclient.Request{Path: "/responses", Name: "responses", Body: body}, &response)
+	if err != nil {
+		return nil, err
 	}
 	var rawEnvelope struct {
 		Output []json.RawMessage `json:"output"`
 	}
-	if err := json.Unmarshal(bodyBytes, &rawEnvelope); err == nil {
+	if err := json.Unmarshal(httpResp.Body, &rawEnvelope); err == nil {
 		response.RawOutput = rawEnvelope.Output
 	}
+	response.RateLimit = parseRateLimit(httpResp.Header)
+	response.RequestID = httpclient.RequestID(httpResp.Header)
+	response.Duration = httpResp.Duration
 
 	return &response, nil
 }
 
-func (a *Adapter) streamResponses(ctx context.Context, request *responsesRequest, out chan<- core.StreamChunk) error {
+// streamResponses sends a responses stream request and forwards its events
+// to out. It returns the provider request ID, also when the stream fails.
+func (a *Adapter) streamResponses(ctx context.Context, request *responsesRequest, out chan<- core.StreamChunk) (string, error) {
 	body, err := marshalWithModelOptions(request, request.ModelOptions)
 	if err != nil {
-		return fmt.Errorf("openai: marshal responses stream request: %w", err)
+		return "", fmt.Errorf("openai: marshal responses stream request: %w", err)
 	}
 
-	url := strings.TrimRight(a.baseURL(), "/") + "/responses"
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	httpResp, err := a.transport().Do(ctx, httpclient.Request{Path: "/responses", Name: "responses stream", Body: body})
 	if err != nil {
-		return fmt.Errorf("openai: build responses stream request: %w", err)
-	}
-	httpReq.Header.Set("Authorization", "Bearer "+a.APIKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	httpResp, err := a.client().Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("openai: responses stream request failed: %w", err)
+		return "", err
 	}
 	defer httpResp.Body.Close()
+	requestID := httpclient.RequestID(httpResp.Header)
 
-	if httpResp.StatusCode >= http.StatusBadRequest {
-		return decodeAPIError(httpResp)
-	}
-
-	scanner := bufio.NewScanner(httpResp.Body)
-	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	reader := sse.NewReader(httpResp.Body)
+	defer reader.Release()
 	var content strings.Builder
 	var reasoning strings.Builder
 	var finalUsage *core.Usage
-	finishReason := "stop"
+	finishReason := ""
+
+	for {
+		sseEvent, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return requestID, fmt.Errorf("openai: responses stream read failed: %w", err)
+		}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, ":") || !strings.HasPrefix(line, "data:") {
+		payload := bytes.TrimSpace(sseEvent.Data)
+		if len(payload) == 0 {
 			continue
 		}
-		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-		if payload == "[DONE]" {
-			out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: finishReason, Reasoning: reasoning.String(), Usage: finalUsage}
-			return nil
+		if bytes.Equal(payload, doneMarker) {
+			out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: toCoreFinishReason(finishReason), RawFinishReason: finishReason, Reasoning: reasoning.String(), Usage: finalUsage, RequestID: requestID}
+			return requestID, nil
 		}
 
 		var event responsesStreamEvent
-		if err := json.Unmarshal([]byte(payload), &event); err != nil {
-			return fmt.Errorf("openai: decode responses stream event: %w", err)
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return requestID, fmt.Errorf("openai: decode responses stream event: %w", err)
 		}
 		switch event.Type {
 		case "response.output_text.delta":
@@ -274,21 +282,18 @@ func (a *Adapter) streamResponses(ctx context.Context, request *responsesRequest
 				finalUsage = toCoreResponsesUsage(event.Response.Usage)
 				finishReason = responseFinishReason(event.Response)
 			}
-			out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: finishReason, Reasoning: reasoning.String(), Usage: finalUsage}
-			return nil
+			out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: toCoreFinishReason(finishReason), RawFinishReason: finishReason, Reasoning: reasoning.String(), Usage: finalUsage, RequestID: requestID}
+			return requestID, nil
 		case "response.failed", "response.incomplete":
 			if event.Response != nil {
-				return errors.New("openai: responses stream ended with status " + event.Response.Status)
+				return requestID, errors.New("openai: responses stream ended with status " + event.Response.Status)
 			}
-			return errors.New("openai: responses stream ended with " + event.Type)
+			return requestID, errors.New("openai: responses stream ended with " + event.Type)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("openai: responses stream read failed: %w", err)
-	}
-	out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: finishReason, Reasoning: reasoning.String(), Usage: finalUsage}
-	return nil
+	out <- core.StreamChunk{Type: core.StreamChunkDone, FinishReason: toCoreFinishReason(finishReason), RawFinishReason: finishReason, Reasoning: reasoning.String(), Usage: finalUsage, RequestID: requestID}
+	return requestID, nil
 }
 
 func responseText(response *responsesResponse) string {
@@ -327,6 +332,32 @@ func responseReasoning(response *responsesResponse) string {
 	return strings.TrimSpace(strings.Join(parts, "\n"))
 }
 
+func responseCitations(response *responsesResponse) []core.Citation {
+	if response == nil {
+		return nil
+	}
+	var out []core.Citation
+	for _, item := range response.Output {
+		for _, part := range item.Content {
+			annotations, _ := part["annotations"].([]any)
+			for _, raw := range annotations {
+				entry, ok := raw.(map[string]any)
+				if !ok || stringValue(entry["type"]) != "url_citation" {
+					continue
+				}
+				out = append(out, core.Citation{
+					Type:       "url_citation",
+					URL:        stringValue(entry["url"]),
+					Title:      stringValue(entry["title"]),
+					StartIndex: intValue(entry["start_index"]),
+					EndIndex:   intValue(entry["end_index"]),
+				})
+			}
+		}
+	}
+	return out
+}
+
 func responseToolCalls(response *responsesResponse) ([]core.ToolCall, error) {
 	if response == nil {
 		return nil, nil
@@ -357,17 +388,16 @@ func responseFunctionCallInput(calls []core.ToolCall) []responseInputItem {
 	return out
 }
 
+// responseFinishReason returns the incomplete reason of response, such as
+// "max_output_tokens", or else its status.
 func responseFinishReason(response *responsesResponse) string {
 	if response == nil {
-		return "stop"
+		return ""
 	}
 	if response.IncompleteDetails != nil && strings.TrimSpace(response.IncompleteDetails.Reason) != "" {
 		return strings.TrimSpace(response.IncompleteDetails.Reason)
 	}
-	if strings.TrimSpace(response.Status) != "" && response.Status != "completed" {
-		return response.Status
-	}
-	return "stop"
+	return strings.TrimSpace(response.Status)
 }
 
 func toCoreResponsesUsage(in *responsesUsage) *core.Usage {
@@ -1,42 +1,58 @@
 package openai
 
-import "testing"
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
 
-func TestAppendStreamSegmentHandlesCumulativeSnapshots(t *testing.T) {
-	t.Parallel()
-
-	current := ""
-	next, delta := appendStreamSegment(current, "The")
-	if next != "The" || delta != "The" {
-		t.Fatalf("unexpected first segment: next=%q delta=%q", next, delta)
-	}
+	"github.com/m43i/go-ai/core"
+)
 
-	next, delta = appendStreamSegment(next, "The user")
-	if next != "The user" || delta != " user" {
-		t.Fatalf("unexpected second segment: next=%q delta=%q", next, delta)
-	}
+func TestParseStreamDeltaReasoningPreservesWhitespace(t *testing.T) {
+	t.Parallel()
 
-	next, delta = appendStreamSegment(next, "The user asks")
-	if next != "The user asks" || delta != " asks" {
-		t.Fatalf("unexpected third segment: next=%q delta=%q", next, delta)
+	got := parseStreamDeltaReasoning(streamDelta{ReasoningContent: " asks"})
+	if got != " asks" {
+		t.Fatalf("expected leading space to be preserved, got %q", got)
 	}
 }
 
-func TestAppendStreamSegmentHandlesDeltaUpdates(t *testing.T) {
+func TestChatStreamReadsReasoningFromRawChoices(t *testing.T) {
 	t.Parallel()
 
-	current := "The user"
-	next, delta := appendStreamSegment(current, " asks")
-	if next != "The user asks" || delta != " asks" {
-		t.Fatalf("unexpected segment append: next=%q delta=%q", next, delta)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"reasoning\":\"The\"}}]}\n\n")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"reasoning\":\"The user\"}}]}\n\n")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":[{\"type\":\"text\",\"text\":\"hi\"}]}}]}\n\n")
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	adapter := New("gpt-test", WithAPIKey("test-key"), WithBaseURL(server.URL))
+	stream, err := adapter.ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
 	}
-}
 
-func TestParseStreamDeltaReasoningPreservesWhitespace(t *testing.T) {
-	t.Parallel()
+	var reasoning, content []string
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkReasoning:
+			reasoning = append(reasoning, chunk.Delta)
+		case core.StreamChunkContent:
+			content = append(content, chunk.Delta)
+		case core.StreamChunkError:
+			t.Fatalf("unexpected chunk error: %s", chunk.Error)
+		}
+	}
 
-	got := parseStreamDeltaReasoning(streamDelta{ReasoningContent: " asks"})
-	if got != " asks" {
-		t.Fatalf("expected leading space to be preserved, got %q", got)
+	if !reflect.DeepEqual(reasoning, []string{"The", " user"}) || !reflect.DeepEqual(content, []string{"hi"}) {
+		t.Fatalf("reasoning deltas %q, content deltas %q", reasoning, content)
 	}
 }
@@ -0,0 +1,117 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/m43i/go-ai/core"
+)
+
+const (
+	// charsPerToken approximates English text and code, erring on the high side.
+	charsPerToken = 4
+	// mediaPartTokens is a rough allowance for an image, audio, or file part.
+	mediaPartTokens = 1024
+	// messageOverheadTokens covers the role and separators of each message.
+	messageOverheadTokens = 4
+)
+
+var _ core.TokenCounterAdapter = (*Adapter)(nil)
+
+// CountTokens estimates the number of prompt tokens params would use. OpenAI
+// has no token counting endpoint, so text is estimated at four characters per
+// token and each image, audio, or file part at a fixed allowance.
+func (a *Adapter) CountTokens(ctx context.Context, params *core.ChatParams) (int64, error) {
+	if err := a.validate(); err != nil {
+		return 0, err
+	}
+	params, err := a.inlineURLSources(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	params, err = a.resolveFileParts(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	return estimatePromptTokens(params)
+}
+
+func estimatePromptTokens(params *core.ChatParams) (int64, error) {
+	messages, err := toChatMessages(params)
+	if err != nil {
+		return 0, err
+	}
+	tools, _, _, err := toChatTools(params)
+	if err != nil {
+		return 0, err
+	}
+
+	var tokens int64
+	for _, message := range messages {
+		tokens += messageOverheadTokens + estimateContentTokens(message.Content)
+		for _, call := range message.ToolCalls {
+			tokens += estimateTextTokens(call.Function.Name) + estimateTextTokens(call.Function.Arguments)
+		}
+	}
+	if len(tools) > 0 {
+		encoded, err := json.Marshal(tools)
+		if err != nil {
+			return 0, err
+		}
+		tokens += estimateTextTokens(string(encoded))
+	}
+	if params != nil && params.Output != nil {
+		encoded, err := json.Marshal(params.Output.Schema)
+		if err != nil {
+			return 0, err
+		}
+		tokens += estimateTextTokens(string(encoded))
+	}
+
+	return tokens, nil
+}
+
+func estimateContentTokens(content any) int64 {
+	switch typed := content.(type) {
+	case string:
+		return estimateTextTokens(typed)
+	case []chatContentPart:
+		var tokens int64
+		for _, part := range typed {
+			if part.Type == "text" {
+				tokens += estimateTextTokens(part.Text)
+			} else {
+				tokens += mediaPartTokens
+			}
+		}
+		return tokens
+	}
+	return 0
+}
+
+func estimateTextTokens(text string) int64 {
+	return int64((utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken)
+}
+
+// checkPromptSize estimates the prompt tokens of params when CheckPromptSize
+// is set and fails when the prompt plus the requested output cannot fit the
+// model's context window from the model catalog.
+func (a *Adapter) checkPromptSize(params *core.ChatParams) error {
+	if !a.CheckPromptSize {
+		return nil
+	}
+	if spec, ok := core.LookupModel(a.Model); !ok || spec.ContextWindow <= 0 {
+		return nil
+	}
+
+	tokens, err := estimatePromptTokens(params)
+	if err != nil {
+		return err
+	}
+	var output int64
+	if limit := maxTokens(a.Model, params); limit != nil {
+		output = *limit
+	}
+	return core.CheckContextWindow(a.Model, tokens, output)
+}
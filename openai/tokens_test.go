@@ -0,0 +1,54 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestPromptSizeCheckFailsBeforeSending(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request to %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	params := &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: strings.Repeat("a", 520000)}},
+	}
+
+	adapter := New("gpt-4o", WithAPIKey("test-key"), WithBaseURL(server.URL), WithPromptSizeCheck())
+	_, err := adapter.Chat(context.Background(), params)
+
+	var exceeded *core.ContextLengthExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("expected ContextLengthExceededError, got %v", err)
+	}
+	if exceeded.PromptTokens != 130004 || exceeded.ContextWindow != 128000 {
+		t.Fatalf("unexpected error fields %#v", exceeded)
+	}
+}
+
+func TestCountTokensEstimatesTextAndMedia(t *testing.T) {
+	t.Parallel()
+
+	adapter := New("gpt-4o", WithAPIKey("test-key"))
+	tokens, err := adapter.CountTokens(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+			core.TextPart{Text: "What is this?"},
+			core.ImagePart{Source: core.URLSource{URL: "https://example.com/cat.png"}},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tokens != messageOverheadTokens+4+mediaPartTokens {
+		t.Fatalf("unexpected estimate %d", tokens)
+	}
+}
@@ -7,10 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"mime/multipart"
-	"net/http"
 	"strings"
 
 	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
 )
 
 var transcriptionReservedKeys = map[string]struct{}{
@@ -172,30 +172,11 @@ func modelOptionToString(value any) (string, error) {
 }
 
 func (a *Adapter) postTranscription(ctx context.Context, body *bytes.Buffer, contentType string) (*transcriptionResponse, error) {
-	url := strings.TrimRight(a.baseURL(), "/") + "/audio/transcriptions"
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
-	if err != nil {
-		return nil, fmt.Errorf("openai: build transcription request: %w", err)
-	}
-
-	httpReq.Header.Set("Authorization", "Bearer "+a.APIKey)
-	httpReq.Header.Set("Content-Type", contentType)
-
-	httpResp, err := a.client().Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("openai: transcription request failed: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode >= http.StatusBadRequest {
-		return nil, decodeAPIError(httpResp)
-	}
-
 	var response transcriptionResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("openai: decode transcription response: %w", err)
+	upload := httpclient.Request{Path: "/audio/transcriptions", Name: "transcription", Body: body.Bytes(), ContentType: contentType}
+	if _, err := a.transport().Send(ctx, upload, &response); err != nil {
+		return nil, err
 	}
-
 	return &response, nil
 }
 
@@ -1,36 +1,48 @@
 package openai
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
 
 type chatCompletionRequest struct {
-	Model               string         `json:"model"`
-	Messages            []chatMessage  `json:"messages"`
-	Tools               []chatTool     `json:"tools,omitempty"`
-	ToolChoice          string         `json:"tool_choice,omitempty"`
-	ResponseFormat      any            `json:"response_format,omitempty"`
-	MaxCompletionTokens *int64         `json:"max_completion_tokens,omitempty"`
-	Temperature         *float64       `json:"temperature,omitempty"`
-	TopP                *float64       `json:"top_p,omitempty"`
-	Metadata            map[string]any `json:"metadata,omitempty"`
-	ReasoningEffort     string         `json:"reasoning_effort,omitempty"`
-	Stream              bool           `json:"stream,omitempty"`
-	ModelOptions        map[string]any `json:"-"`
+	Model               string           `json:"model"`
+	Messages            []chatMessage    `json:"messages"`
+	Tools               []chatTool       `json:"tools,omitempty"`
+	ToolChoice          any              `json:"tool_choice,omitempty"`
+	ParallelToolCalls   *bool            `json:"parallel_tool_calls,omitempty"`
+	ResponseFormat      any              `json:"response_format,omitempty"`
+	MaxCompletionTokens *int64           `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64         `json:"temperature,omitempty"`
+	TopP                *float64         `json:"top_p,omitempty"`
+	Stop                []string         `json:"stop,omitempty"`
+	Seed                *int64           `json:"seed,omitempty"`
+	LogitBias           map[string]int64 `json:"logit_bias,omitempty"`
+	N                   *int64           `json:"n,omitempty"`
+	Metadata            map[string]any   `json:"metadata,omitempty"`
+	ReasoningEffort     string           `json:"reasoning_effort,omitempty"`
+	WebSearchOptions    any              `json:"web_search_options,omitempty"`
+	Stream              bool             `json:"stream,omitempty"`
+	ModelOptions        map[string]any   `json:"-"`
 }
 
 type responsesRequest struct {
-	Model           string              `json:"model"`
-	Input           []responseInputItem `json:"input"`
-	Instructions    string              `json:"instructions,omitempty"`
-	Tools           []chatTool          `json:"tools,omitempty"`
-	ToolChoice      string              `json:"tool_choice,omitempty"`
-	Text            any                 `json:"text,omitempty"`
-	MaxOutputTokens *int64              `json:"max_output_tokens,omitempty"`
-	Temperature     *float64            `json:"temperature,omitempty"`
-	TopP            *float64            `json:"top_p,omitempty"`
-	Metadata        map[string]any      `json:"metadata,omitempty"`
-	Reasoning       map[string]any      `json:"reasoning,omitempty"`
-	Stream          bool                `json:"stream,omitempty"`
-	ModelOptions    map[string]any      `json:"-"`
+	Model             string              `json:"model"`
+	Input             []responseInputItem `json:"input"`
+	Instructions      string              `json:"instructions,omitempty"`
+	Tools             []any               `json:"tools,omitempty"`
+	ToolChoice        any                 `json:"tool_choice,omitempty"`
+	ParallelToolCalls *bool               `json:"parallel_tool_calls,omitempty"`
+	Text              any                 `json:"text,omitempty"`
+	MaxOutputTokens   *int64              `json:"max_output_tokens,omitempty"`
+	Temperature       *float64            `json:"temperature,omitempty"`
+	TopP              *float64            `json:"top_p,omitempty"`
+	Metadata          map[string]any      `json:"metadata,omitempty"`
+	Reasoning         map[string]any      `json:"reasoning,omitempty"`
+	Stream            bool                `json:"stream,omitempty"`
+	ModelOptions      map[string]any      `json:"-"`
 }
 
 type responseInputItem struct {
@@ -47,6 +59,13 @@ type responseContentPart struct {
 	Type     string `json:"type"`
 	Text     string `json:"text,omitempty"`
 	ImageURL string `json:"image_url,omitempty"`
+	FileID   string `json:"file_id,omitempty"`
+	FileURL  string `json:"file_url,omitempty"`
+	FileData string `json:"file_data,omitempty"`
+	Filename string `json:"filename,omitempty"`
+
+	// Raw replaces the whole part when set, for core.RawPart.
+	Raw json.RawMessage `json:"-"`
 }
 
 type responsesResponse struct {
@@ -56,6 +75,9 @@ type responsesResponse struct {
 	Status            string               `json:"status,omitempty"`
 	IncompleteDetails *incompleteDetails   `json:"incomplete_details,omitempty"`
 	RawOutput         []json.RawMessage    `json:"-"`
+	RateLimit         *core.RateLimit      `json:"-"`
+	RequestID         string               `json:"-"`
+	Duration          time.Duration        `json:"-"`
 }
 
 type responseOutputItem struct {
@@ -97,6 +119,7 @@ type responsesStreamEvent struct {
 
 type chatMessage struct {
 	Role       string         `json:"role"`
+	Name       string         `json:"name,omitempty"`
 	Content    any            `json:"content,omitempty"`
 	ToolCallID string         `json:"tool_call_id,omitempty"`
 	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
@@ -107,6 +130,10 @@ type chatContentPart struct {
 	Text       string          `json:"text,omitempty"`
 	ImageURL   *chatImageURL   `json:"image_url,omitempty"`
 	InputAudio *chatInputAudio `json:"input_audio,omitempty"`
+	File       *chatFile       `json:"file,omitempty"`
+
+	// Raw replaces the whole part when set, for core.RawPart.
+	Raw json.RawMessage `json:"-"`
 }
 
 type chatImageURL struct {
@@ -119,6 +146,12 @@ type chatInputAudio struct {
 	Format string `json:"format"`
 }
 
+type chatFile struct {
+	FileID   string `json:"file_id,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	FileData string `json:"file_data,omitempty"`
+}
+
 type chatTool struct {
 	Type     string           `json:"type"`
 	Function chatToolFunction `json:"function"`
@@ -145,6 +178,9 @@ type chatCompletionResponse struct {
 	Choices    []chatChoice      `json:"choices"`
 	Usage      *usage            `json:"usage,omitempty"`
 	RawChoices []json.RawMessage `json:"-"`
+	RateLimit  *core.RateLimit   `json:"-"`
+	RequestID  string            `json:"-"`
+	Duration   time.Duration     `json:"-"`
 }
 
 type chatChoice struct {
@@ -160,6 +196,19 @@ type chatResponseMessage struct {
 	ToolCalls        []chatToolCall  `json:"tool_calls"`
 	ReasoningContent string          `json:"reasoning_content,omitempty"`
 	Refusal          string          `json:"refusal,omitempty"`
+	Annotations      []annotation    `json:"annotations,omitempty"`
+}
+
+type annotation struct {
+	Type        string       `json:"type"`
+	URLCitation *urlCitation `json:"url_citation,omitempty"`
+}
+
+type urlCitation struct {
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
+	URL        string `json:"url"`
+	Title      string `json:"title,omitempty"`
 }
 
 type streamEvent struct {
@@ -6,10 +6,31 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
 )
 
+func (p chatContentPart) MarshalJSON() ([]byte, error) {
+	if len(p.Raw) > 0 {
+		return p.Raw, nil
+	}
+	type plain chatContentPart
+	return json.Marshal(plain(p))
+}
+
+func (p responseContentPart) MarshalJSON() ([]byte, error) {
+	if len(p.Raw) > 0 {
+		return p.Raw, nil
+	}
+	type plain responseContentPart
+	return json.Marshal(plain(p))
+}
+
 func marshalWithModelOptions(request any, options map[string]any) ([]byte, error) {
 	body, err := json.Marshal(request)
 	if err != nil {
@@ -246,7 +267,9 @@ func parseStreamDeltaReasoning(delta streamDelta) string {
 	if delta.ReasoningContent != "" {
 		return delta.ReasoningContent
 	}
-	if len(delta.Content) == 0 {
+	// Only objects and arrays can hold reasoning; plain text content, the
+	// common case, is skipped without decoding it again.
+	if content := bytes.TrimSpace(delta.Content); len(content) == 0 || (content[0] != '{' && content[0] != '[') {
 		return ""
 	}
 
@@ -268,6 +291,37 @@ func parseStreamChoiceReasoning(choice streamChoice) string {
 	return ""
 }
 
+// rawStreamChoices decodes the choices of a stream event payload as raw JSON
+// the first time one is needed.
+type rawStreamChoices struct {
+	payload []byte
+	decoded bool
+	choices []json.RawMessage
+}
+
+// choice returns the raw JSON of choice idx, or nil when the payload has no
+// such choice.
+func (r *rawStreamChoices) choice(idx int) json.RawMessage {
+	if !r.decoded {
+		r.decoded = true
+		var event struct {
+			Choices []json.RawMessage `json:"choices"`
+		}
+		_ = json.Unmarshal(r.payload, &event)
+		r.choices = event.Choices
+	}
+	if idx < len(r.choices) {
+		return r.choices[idx]
+	}
+	return nil
+}
+
+// mayContainReasoning reports whether payload has a key that
+// extractReasoningFromAny looks for, so events without one skip decoding it.
+func mayContainReasoning(payload []byte) bool {
+	return bytes.Contains(payload, []byte(`"reasoning`)) || bytes.Contains(payload, []byte(`"thinking`))
+}
+
 func parseStreamChoiceRawReasoning(raw json.RawMessage) (string, error) {
 	if len(raw) == 0 {
 		return "", nil
@@ -352,6 +406,18 @@ func stringValue(value any) string {
 	return ""
 }
 
+func intValue(value any) int {
+	switch typed := value.(type) {
+	case float64:
+		return int(typed)
+	case int:
+		return typed
+	case int64:
+		return int(typed)
+	}
+	return 0
+}
+
 func extractTextFromAny(value any) string {
 	switch typed := value.(type) {
 	case nil:
@@ -460,9 +526,30 @@ func extractReasoningFromAny(value any) string {
 }
 
 func decodeAPIError(resp *http.Response) error {
+	message, errorType := decodeAPIErrorBody(resp)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &core.RateLimitError{
+			Message:    message,
+			RetryAfter: retryAfter(resp.Header),
+			RateLimit:  parseRateLimit(resp.Header),
+			RequestID:  httpclient.RequestID(resp.Header),
+		}
+	}
+
+	return &core.APIError{
+		StatusCode: resp.StatusCode,
+		Type:       errorType,
+		Message:    message,
+		RequestID:  httpclient.RequestID(resp.Header),
+		Retryable:  resp.StatusCode >= http.StatusInternalServerError,
+		RetryAfter: retryAfter(resp.Header),
+	}
+}
+
+func decodeAPIErrorBody(resp *http.Response) (string, string) {
 	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
 	if readErr != nil {
-		return fmt.Errorf("openai: API status %d and failed to read error body: %w", resp.StatusCode, readErr)
+		return fmt.Sprintf("openai: API status %d and failed to read error body: %v", resp.StatusCode, readErr), ""
 	}
 
 	var envelope struct {
@@ -475,9 +562,9 @@ func decodeAPIError(resp *http.Response) error {
 
 	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error.Message != "" {
 		if envelope.Error.Type != "" || envelope.Error.Code != nil {
-			return fmt.Errorf("openai: API error (%s, %v): %s", envelope.Error.Type, envelope.Error.Code, envelope.Error.Message)
+			return fmt.Sprintf("openai: API error (%s, %v): %s", envelope.Error.Type, envelope.Error.Code, envelope.Error.Message), envelope.Error.Type
 		}
-		return fmt.Errorf("openai: API error: %s", envelope.Error.Message)
+		return fmt.Sprintf("openai: API error: %s", envelope.Error.Message), ""
 	}
 
 	text := strings.TrimSpace(string(body))
@@ -485,5 +572,85 @@ func decodeAPIError(resp *http.Response) error {
 		text = http.StatusText(resp.StatusCode)
 	}
 
-	return fmt.Errorf("openai: API status %d: %s", resp.StatusCode, text)
+	return fmt.Sprintf("openai: API status %d: %s", resp.StatusCode, text), ""
+}
+
+// doneMarker is the data of the event that ends an OpenAI stream.
+var doneMarker = []byte("[DONE]")
+
+// timedUsage sets the request duration and ID on usage, adding a Usage when
+// the response reported none.
+func timedUsage(usage *core.Usage, duration time.Duration, requestID string) *core.Usage {
+	if usage == nil {
+		usage = &core.Usage{}
+	}
+	usage.Duration = duration
+	usage.RequestID = requestID
+	return usage
+}
+
+func parseRateLimit(header http.Header) *core.RateLimit {
+	if header == nil {
+		return nil
+	}
+
+	rateLimit := core.RateLimit{
+		LimitRequests:     headerInt(header, "x-ratelimit-limit-requests"),
+		LimitTokens:       headerInt(header, "x-ratelimit-limit-tokens"),
+		RemainingRequests: headerInt(header, "x-ratelimit-remaining-requests"),
+		RemainingTokens:   headerInt(header, "x-ratelimit-remaining-tokens"),
+		ResetRequests:     headerDuration(header, "x-ratelimit-reset-requests"),
+		ResetTokens:       headerDuration(header, "x-ratelimit-reset-tokens"),
+	}
+	if rateLimit == (core.RateLimit{}) {
+		return nil
+	}
+	return &rateLimit
+}
+
+func retryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+	if ms := strings.TrimSpace(header.Get("retry-after-ms")); ms != "" {
+		if value, err := strconv.ParseFloat(ms, 64); err == nil && value > 0 {
+			return time.Duration(value * float64(time.Millisecond))
+		}
+	}
+	value := strings.TrimSpace(header.Get("retry-after"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+func headerInt(header http.Header, key string) int64 {
+	value, err := strconv.ParseInt(strings.TrimSpace(header.Get(key)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// headerDuration parses reset headers such as "1s", "6m0s", or "20ms".
+func headerDuration(header http.Header, key string) time.Duration {
+	value := strings.TrimSpace(header.Get(key))
+	if value == "" {
+		return 0
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return duration
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return 0
 }
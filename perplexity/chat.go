@@ -0,0 +1,142 @@
+package perplexity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+// Chat sends a non-streaming chat request to Perplexity.
+//
+// The sources the model searched are reported on Citations, and the search
+// counts on Usage.Details.
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().Chat(ctx, params)
+}
+
+// ChatStream sends a streaming chat request to Perplexity.
+//
+// Structured output streams as StreamChunkPartialJSON chunks. Usage, with
+// the search counts, is reported on the done chunk; citations are only
+// reported by Chat.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.compat().ChatStream(ctx, params)
+}
+
+// prepareRequest rejects function tools, which the Sonar API does not
+// accept. Search settings such as search_domain_filter are sent through
+// ChatParams.ModelOptions.
+func prepareRequest(params *core.ChatParams, request *openaicompat.Request) error {
+	if len(params.Tools) > 0 || len(request.Tools) > 0 {
+		return errors.New("perplexity: tools are not supported")
+	}
+	return nil
+}
+
+// searchResult is one entry of the search_results of a response.
+type searchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
+}
+
+// citations returns the sources of a response. The model cites them in the
+// text as [1], [2], and so on, which are the entries of citations, so
+// DocumentIndex is the 0-based position in that list. Each source takes the
+// title and snippet of its search result. Search results that are not
+// cited follow them.
+func citations(raw []byte) []core.Citation {
+	var envelope struct {
+		Citations     []string       `json:"citations"`
+		SearchResults []searchResult `json:"search_results"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil
+	}
+
+	urls := envelope.Citations
+	if len(urls) == 0 {
+		for _, result := range envelope.SearchResults {
+			urls = append(urls, result.URL)
+		}
+	}
+	results := make(map[string]searchResult, len(envelope.SearchResults))
+	for _, result := range envelope.SearchResults {
+		if _, ok := results[result.URL]; !ok {
+			results[result.URL] = result
+		}
+	}
+
+	var out []core.Citation
+	cited := make(map[string]struct{}, len(urls))
+	for _, url := range urls {
+		result := results[url]
+		out = append(out, core.Citation{
+			Type:          "search_result",
+			URL:           url,
+			Title:         result.Title,
+			CitedText:     result.Snippet,
+			DocumentIndex: len(out),
+		})
+		cited[url] = struct{}{}
+	}
+	for _, result := range envelope.SearchResults {
+		if _, ok := cited[result.URL]; ok {
+			continue
+		}
+		out = append(out, core.Citation{
+			Type:          "search_result",
+			URL:           result.URL,
+			Title:         result.Title,
+			CitedText:     result.Snippet,
+			DocumentIndex: len(out),
+		})
+		cited[result.URL] = struct{}{}
+	}
+	return out
+}
+
+// searchUsage adds the search counts of a response or stream chunk to usage
+// as citation_tokens and num_search_queries. Perplexity reports reasoning
+// tokens next to the other counts rather than in completion_tokens_details.
+func searchUsage(raw []byte, usage *core.Usage) *core.Usage {
+	if usage == nil {
+		return nil
+	}
+
+	var envelope struct {
+		Usage *struct {
+			CitationTokens   int64 `json:"citation_tokens"`
+			NumSearchQueries int64 `json:"num_search_queries"`
+			ReasoningTokens  int64 `json:"reasoning_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil || envelope.Usage == nil {
+		return usage
+	}
+
+	if envelope.Usage.ReasoningTokens > 0 {
+		usage.ReasoningTokens = envelope.Usage.ReasoningTokens
+	}
+	addDetail := func(key string, value int64) {
+		if value <= 0 {
+			return
+		}
+		if usage.Details == nil {
+			usage.Details = make(map[string]int64)
+		}
+		usage.Details[key] = value
+	}
+	addDetail("citation_tokens", envelope.Usage.CitationTokens)
+	addDetail("num_search_queries", envelope.Usage.NumSearchQueries)
+	return usage
+}
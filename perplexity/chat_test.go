@@ -0,0 +1,133 @@
+package perplexity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatReportsCitationsAndSearchUsage(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" || r.Header.Get("Authorization") != "Bearer pplx-test" {
+			t.Errorf("unexpected request %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"id":"resp-1","model":"sonar",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"Emperor penguins are the tallest [1][2]."},"finish_reason":"stop"}],
+			"citations":["https://example.com/emperor","https://example.org/penguins"],
+			"search_results":[
+				{"title":"Penguin facts","url":"https://example.org/penguins","date":"2025-01-02","snippet":"The emperor penguin stands up to 1.2 m."},
+				{"title":"Emperor penguin","url":"https://example.com/emperor","snippet":"Tallest and heaviest of all penguins."},
+				{"title":"Uncited","url":"https://example.net/other","snippet":"Not cited."}],
+			"usage":{"prompt_tokens":8,"completion_tokens":20,"total_tokens":28,"citation_tokens":412,"num_search_queries":1,"search_context_size":"low"}}`)
+	}))
+	defer server.Close()
+
+	result, err := New("sonar", WithAPIKey("pplx-test"), WithBaseURL(server.URL)).Chat(context.Background(), &core.ChatParams{
+		Messages:     []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Which penguins are the tallest?"}},
+		ModelOptions: map[string]any{"search_domain_filter": []string{"example.com"}, "search_recency_filter": "month"},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if body["model"] != "sonar" || body["search_recency_filter"] != "month" || !reflect.DeepEqual(body["search_domain_filter"], []any{"example.com"}) {
+		t.Fatalf("unexpected request body %v", body)
+	}
+
+	want := []core.Citation{
+		{Type: "search_result", URL: "https://example.com/emperor", Title: "Emperor penguin", CitedText: "Tallest and heaviest of all penguins.", DocumentIndex: 0},
+		{Type: "search_result", URL: "https://example.org/penguins", Title: "Penguin facts", CitedText: "The emperor penguin stands up to 1.2 m.", DocumentIndex: 1},
+		{Type: "search_result", URL: "https://example.net/other", Title: "Uncited", CitedText: "Not cited.", DocumentIndex: 2},
+	}
+	if !reflect.DeepEqual(result.Citations, want) {
+		t.Fatalf("citations = %+v", result.Citations)
+	}
+	if result.Usage.TotalTokens != 28 || result.Usage.Details["citation_tokens"] != 412 || result.Usage.Details["num_search_queries"] != 1 {
+		t.Fatalf("unexpected usage %+v", result.Usage)
+	}
+}
+
+func TestCitationsFallBackToEitherField(t *testing.T) {
+	t.Parallel()
+
+	urlsOnly := citations([]byte(`{"citations":["https://a.example","https://b.example"]}`))
+	if len(urlsOnly) != 2 || urlsOnly[1].URL != "https://b.example" || urlsOnly[1].DocumentIndex != 1 || urlsOnly[1].Title != "" {
+		t.Fatalf("citations = %+v", urlsOnly)
+	}
+
+	resultsOnly := citations([]byte(`{"search_results":[{"title":"A","url":"https://a.example","snippet":"a"}]}`))
+	if len(resultsOnly) != 1 || resultsOnly[0].Title != "A" || resultsOnly[0].CitedText != "a" {
+		t.Fatalf("citations = %+v", resultsOnly)
+	}
+
+	if none := citations([]byte(`{"choices":[]}`)); none != nil {
+		t.Fatalf("citations = %+v", none)
+	}
+}
+
+func TestChatRejectsTools(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("sonar-pro", WithAPIKey("pplx-test"), WithBaseURL("http://127.0.0.1:0")).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		Tools:    []core.ToolUnion{core.ClientTool{Name: "lookup"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "perplexity: tools are not supported") {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
+
+func TestChatStreamReportsReasoningAndSearchUsage(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"resp-2","choices":[{"index":0,"delta":{"role":"assistant","content":"Sunny"}}],"citations":["https://weather.example"]}
+
+data: {"id":"resp-2","choices":[{"index":0,"delta":{"content":"."},"finish_reason":"stop"}],"citations":["https://weather.example"],"usage":{"prompt_tokens":5,"completion_tokens":30,"total_tokens":35,"reasoning_tokens":24,"citation_tokens":90,"num_search_queries":2}}
+
+data: [DONE]
+
+`)
+	}))
+	defer server.Close()
+
+	stream, err := New("sonar-reasoning-pro", WithAPIKey("pplx-test"), WithBaseURL(server.URL)).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather?"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var content strings.Builder
+	var done core.StreamChunk
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkContent:
+			content.WriteString(chunk.Delta)
+		case core.StreamChunkDone:
+			done = chunk
+		case core.StreamChunkError:
+			t.Fatalf("stream error: %s", chunk.Error)
+		}
+	}
+	if content.String() != "Sunny." || done.Usage == nil {
+		t.Fatalf("content = %q, done = %+v", content.String(), done)
+	}
+	if done.Usage.ReasoningTokens != 24 || done.Usage.Details["num_search_queries"] != 2 || done.Usage.Details["citation_tokens"] != 90 {
+		t.Fatalf("unexpected usage %+v", done.Usage)
+	}
+}
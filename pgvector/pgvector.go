@@ -0,0 +1,547 @@
+// Package pgvector implements core.VectorStore on PostgreSQL with the
+// pgvector extension.
+//
+// The store works on a *sql.DB, so it needs no driver of its own; open the
+// database with any PostgreSQL driver, such as pgx's stdlib package:
+//
+//	db, err := sql.Open("pgx", os.Getenv("DATABASE_URL"))
+//	store, err := pgvector.New(db,
+//		pgvector.WithDimensions(1536),
+//		pgvector.WithEmbeddingAdapter(openai.New("text-embedding-3-small")),
+//	)
+//	err = store.Migrate(ctx)
+//
+// Documents live in one table with text, a vector column, and JSONB
+// metadata. Queries rank by cosine distance and translate core filters to
+// JSONB conditions.
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// DefaultTable is the table documents are stored in unless WithTable is used.
+const DefaultTable = "goai_documents"
+
+// upsertBatchSize is the number of documents written per INSERT statement,
+// well below PostgreSQL's limit of 65535 parameters.
+const upsertBatchSize = 500
+
+// IndexType selects the approximate nearest neighbor index Migrate creates.
+type IndexType string
+
+const (
+	// IndexHNSW builds a hierarchical navigable small world graph, which
+	// gives the best speed and recall trade-off and can be built on an empty
+	// table. It is the default.
+	IndexHNSW IndexType = "hnsw"
+
+	// IndexIVFFlat partitions vectors into lists. It builds faster and uses
+	// less memory than HNSW, but should be created after the table holds
+	// representative data.
+	IndexIVFFlat IndexType = "ivfflat"
+
+	// IndexNone creates no vector index, so queries scan the whole table
+	// with exact results.
+	IndexNone IndexType = "none"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Store is a core.VectorStore backed by a PostgreSQL table.
+type Store struct {
+	db         *sql.DB
+	adapter    core.EmbeddingAdapter
+	table      string
+	dimensions int
+
+	index          IndexType
+	hnswM          int
+	efConstruction int
+	lists          int
+	efSearch       int
+	probes         int
+}
+
+var _ core.VectorStore = (*Store)(nil)
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithTable sets the table name, optionally qualified with a schema such as
+// "rag.documents".
+func WithTable(table string) Option {
+	return func(s *Store) {
+		if table = strings.TrimSpace(table); table != "" {
+			s.table = table
+		}
+	}
+}
+
+// WithDimensions sets the length of the stored vectors. It is required by
+// Migrate to create the vector column.
+func WithDimensions(dimensions int) Option {
+	return func(s *Store) {
+		s.dimensions = dimensions
+	}
+}
+
+// WithEmbeddingAdapter sets the adapter that embeds documents and queries
+// given only as text.
+func WithEmbeddingAdapter(adapter core.EmbeddingAdapter) Option {
+	return func(s *Store) {
+		s.adapter = adapter
+	}
+}
+
+// WithHNSWIndex makes Migrate create an HNSW index with at most m
+// connections per node and a candidate list of efConstruction while
+// building. Zero keeps the pgvector defaults of 16 and 64.
+func WithHNSWIndex(m, efConstruction int) Option {
+	return func(s *Store) {
+		s.index = IndexHNSW
+		s.hnswM = m
+		s.efConstruction = efConstruction
+	}
+}
+
+// WithIVFFlatIndex makes Migrate create an IVFFlat index with lists
+// partitions. A good start is rows/1000 for up to a million rows. Zero keeps
+// the pgvector default of 100.
+func WithIVFFlatIndex(lists int) Option {
+	return func(s *Store) {
+		s.index = IndexIVFFlat
+		s.lists = lists
+	}
+}
+
+// WithoutIndex makes Migrate create no vector index, for exact search.
+func WithoutIndex() Option {
+	return func(s *Store) {
+		s.index = IndexNone
+	}
+}
+
+// WithEFSearch sets hnsw.ef_search for queries, the size of the candidate
+// list searched in an HNSW index. Higher values improve recall at the cost
+// of speed.
+func WithEFSearch(efSearch int) Option {
+	return func(s *Store) {
+		s.efSearch = efSearch
+	}
+}
+
+// WithProbes sets ivfflat.probes for queries, the number of IVFFlat lists
+// searched. Higher values improve recall at the cost of speed.
+func WithProbes(probes int) Option {
+	return func(s *Store) {
+		s.probes = probes
+	}
+}
+
+// New returns a Store on db. It does not touch the database; call Migrate to
+// create the table and indexes.
+func New(db *sql.DB, opts ...Option) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("pgvector: db is required")
+	}
+	s := &Store{db: db, table: DefaultTable, index: IndexHNSW}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+
+	if !identifierPattern.MatchString(s.table) {
+		return nil, fmt.Errorf("pgvector: invalid table name %q", s.table)
+	}
+	if s.dimensions < 0 {
+		return nil, fmt.Errorf("pgvector: invalid dimensions %d", s.dimensions)
+	}
+	switch s.index {
+	case IndexHNSW, IndexIVFFlat, IndexNone:
+	default:
+		return nil, fmt.Errorf("pgvector: unsupported index type %q", s.index)
+	}
+	return s, nil
+}
+
+// Schema returns the statements Migrate runs, for use with a migration tool.
+func (s *Store) Schema() ([]string, error) {
+	if s.dimensions <= 0 {
+		return nil, errors.New("pgvector: WithDimensions is required to create the table")
+	}
+
+	table := quoteIdentifier(s.table)
+	name := s.table[strings.LastIndex(s.table, ".")+1:]
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS vector",
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id text PRIMARY KEY,
+	text text NOT NULL DEFAULT '',
+	embedding vector(%d) NOT NULL,
+	metadata jsonb NOT NULL DEFAULT '{}'
+)`, table, s.dimensions),
+	}
+
+	switch s.index {
+	case IndexHNSW:
+		var with []string
+		if s.hnswM > 0 {
+			with = append(with, "m = "+strconv.Itoa(s.hnswM))
+		}
+		if s.efConstruction > 0 {
+			with = append(with, "ef_construction = "+strconv.Itoa(s.efConstruction))
+		}
+		statements = append(statements, createVectorIndex(name, table, "hnsw", with))
+	case IndexIVFFlat:
+		var with []string
+		if s.lists > 0 {
+			with = append(with, "lists = "+strconv.Itoa(s.lists))
+		}
+		statements = append(statements, createVectorIndex(name, table, "ivfflat", with))
+	}
+
+	statements = append(statements, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING gin (metadata jsonb_path_ops)", quoteIdentifier(name+"_metadata_idx"), table))
+	return statements, nil
+}
+
+func createVectorIndex(name, table, method string, with []string) string {
+	statement := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING %s (embedding vector_cosine_ops)", quoteIdentifier(name+"_embedding_idx"), table, method)
+	if len(with) > 0 {
+		statement += " WITH (" + strings.Join(with, ", ") + ")"
+	}
+	return statement
+}
+
+// Migrate creates the pgvector extension, the table, and its indexes when
+// they do not exist.
+func (s *Store) Migrate(ctx context.Context) error {
+	statements, err := s.Schema()
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("pgvector: migrate: %w", err)
+		}
+	}
+	return nil
+}
+
+// Upsert implements core.VectorStore. Documents are written in one
+// transaction.
+func (s *Store) Upsert(ctx context.Context, documents ...core.VectorDocument) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	documents = append([]core.VectorDocument(nil), documents...)
+	if err := core.EmbedDocuments(ctx, s.adapter, documents); err != nil {
+		return err
+	}
+
+	// A statement cannot update the same row twice, so only the last
+	// document with an ID is written.
+	last := make(map[string]int, len(documents))
+	for idx, document := range documents {
+		if s.dimensions > 0 && len(document.Embedding) != s.dimensions {
+			return fmt.Errorf("pgvector: document %q has %d dimensions, the store has %d", document.ID, len(document.Embedding), s.dimensions)
+		}
+		last[document.ID] = idx
+	}
+	unique := documents[:0]
+	for idx, document := range documents {
+		if last[document.ID] == idx {
+			unique = append(unique, document)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pgvector: begin upsert: %w", err)
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(unique); start += upsertBatchSize {
+		batch := unique[start:min(start+upsertBatchSize, len(unique))]
+		statement, args, err := s.upsertStatement(batch)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, statement, args...); err != nil {
+			return fmt.Errorf("pgvector: upsert: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pgvector: commit upsert: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) upsertStatement(documents []core.VectorDocument) (string, []any, error) {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "INSERT INTO %s (id, text, embedding, metadata) VALUES ", quoteIdentifier(s.table))
+
+	args := make([]any, 0, 4*len(documents))
+	for idx, document := range documents {
+		metadata := []byte("{}")
+		if len(document.Metadata) > 0 {
+			var err error
+			if metadata, err = json.Marshal(document.Metadata); err != nil {
+				return "", nil, fmt.Errorf("pgvector: encode metadata of document %q: %w", document.ID, err)
+			}
+		}
+		if idx > 0 {
+			builder.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&builder, "($%d, $%d, $%d::vector, $%d::jsonb)", n+1, n+2, n+3, n+4)
+		args = append(args, document.ID, document.Text, encodeVector(document.Embedding), string(metadata))
+	}
+
+	builder.WriteString(" ON CONFLICT (id) DO UPDATE SET text = EXCLUDED.text, embedding = EXCLUDED.embedding, metadata = EXCLUDED.metadata")
+	return builder.String(), args, nil
+}
+
+// Query implements core.VectorStore. Scores are cosine similarities.
+func (s *Store) Query(ctx context.Context, query *core.VectorQuery) ([]core.VectorMatch, error) {
+	embedding, err := core.QueryEmbedding(ctx, s.adapter, query)
+	if err != nil {
+		return nil, err
+	}
+	if s.dimensions > 0 && len(embedding) != s.dimensions {
+		return nil, fmt.Errorf("pgvector: query has %d dimensions, the store has %d", len(embedding), s.dimensions)
+	}
+	if err := query.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	topK := query.TopK
+	if topK <= 0 {
+		topK = core.DefaultVectorQueryTopK
+	}
+
+	args := []any{encodeVector(embedding)}
+	where := "TRUE"
+	if query.Filter != nil {
+		if where, err = filterSQL(query.Filter, &args); err != nil {
+			return nil, err
+		}
+	}
+	args = append(args, topK)
+	statement := fmt.Sprintf(
+		"SELECT id, text, embedding::text, metadata::text, 1 - (embedding <=> $1::vector) AS score FROM %s WHERE %s ORDER BY embedding <=> $1::vector LIMIT $%d",
+		quoteIdentifier(s.table), where, len(args),
+	)
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("pgvector: begin query: %w", err)
+	}
+	defer tx.Rollback()
+
+	// SET LOCAL applies the search settings to this transaction only.
+	if s.efSearch > 0 {
+		if _, err := tx.ExecContext(ctx, "SET LOCAL hnsw.ef_search = "+strconv.Itoa(s.efSearch)); err != nil {
+			return nil, fmt.Errorf("pgvector: set hnsw.ef_search: %w", err)
+		}
+	}
+	if s.probes > 0 {
+		if _, err := tx.ExecContext(ctx, "SET LOCAL ivfflat.probes = "+strconv.Itoa(s.probes)); err != nil {
+			return nil, fmt.Errorf("pgvector: set ivfflat.probes: %w", err)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgvector: query: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []core.VectorMatch
+	for rows.Next() {
+		var match core.VectorMatch
+		var vector, metadata string
+		if err := rows.Scan(&match.ID, &match.Text, &vector, &metadata, &match.Score); err != nil {
+			return nil, fmt.Errorf("pgvector: scan match: %w", err)
+		}
+		if query.MinScore != nil && match.Score < *query.MinScore {
+			break
+		}
+		if match.Embedding, err = decodeVector(vector); err != nil {
+			return nil, fmt.Errorf("pgvector: decode embedding of %q: %w", match.ID, err)
+		}
+		if err := json.Unmarshal([]byte(metadata), &match.Metadata); err != nil {
+			return nil, fmt.Errorf("pgvector: decode metadata of %q: %w", match.ID, err)
+		}
+		if len(match.Metadata) == 0 {
+			match.Metadata = nil
+		}
+		matches = append(matches, match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgvector: query: %w", err)
+	}
+	return matches, nil
+}
+
+// Delete implements core.VectorStore.
+func (s *Store) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "DELETE FROM %s WHERE id IN (", quoteIdentifier(s.table))
+	args := make([]any, len(ids))
+	for idx, id := range ids {
+		if idx > 0 {
+			builder.WriteString(", ")
+		}
+		fmt.Fprintf(&builder, "$%d", idx+1)
+		args[idx] = id
+	}
+	builder.WriteString(")")
+
+	if _, err := s.db.ExecContext(ctx, builder.String(), args...); err != nil {
+		return fmt.Errorf("pgvector: delete: %w", err)
+	}
+	return nil
+}
+
+// filterSQL translates filter to a condition on the metadata column,
+// appending its values to args as parameters.
+func filterSQL(filter *core.Filter, args *[]any) (string, error) {
+	param := func(value any) string {
+		*args = append(*args, value)
+		return "$" + strconv.Itoa(len(*args))
+	}
+
+	switch filter.Op {
+	case core.FilterAnd, core.FilterOr:
+		parts := make([]string, len(filter.Filters))
+		for idx, operand := range filter.Filters {
+			part, err := filterSQL(operand, args)
+			if err != nil {
+				return "", err
+			}
+			parts[idx] = part
+		}
+		separator := " AND "
+		if filter.Op == core.FilterOr {
+			separator = " OR "
+		}
+		return "(" + strings.Join(parts, separator) + ")", nil
+
+	case core.FilterNot:
+		part, err := filterSQL(filter.Filters[0], args)
+		if err != nil {
+			return "", err
+		}
+		return "(NOT " + part + ")", nil
+
+	case core.FilterEq, core.FilterNe:
+		// Containment uses the GIN index and compares numbers by value.
+		contains, err := containment(filter.Key, filter.Value)
+		if err != nil {
+			return "", err
+		}
+		condition := "metadata @> " + param(contains) + "::jsonb"
+		if filter.Op == core.FilterNe {
+			return "(NOT " + condition + ")", nil
+		}
+		return condition, nil
+
+	case core.FilterIn:
+		parts := make([]string, len(filter.Values))
+		for idx, value := range filter.Values {
+			contains, err := containment(filter.Key, value)
+			if err != nil {
+				return "", err
+			}
+			parts[idx] = "metadata @> " + param(contains) + "::jsonb"
+		}
+		return "(" + strings.Join(parts, " OR ") + ")", nil
+
+	case core.FilterGt, core.FilterGte, core.FilterLt, core.FilterLte:
+		operator := map[core.FilterOp]string{core.FilterGt: ">", core.FilterGte: ">=", core.FilterLt: "<", core.FilterLte: "<="}[filter.Op]
+		key := param(filter.Key)
+
+		// CASE evaluates the cast only for values of the matching JSON type,
+		// and COALESCE keeps other values from matching under NOT.
+		switch value := filter.Value.(type) {
+		case string:
+			return fmt.Sprintf(`COALESCE(CASE WHEN jsonb_typeof(metadata -> %s::text) = 'string' THEN (metadata ->> %s::text) COLLATE "C" %s %s::text END, FALSE)`, key, key, operator, param(value)), nil
+		default:
+			number, ok := core.FilterNumber(value)
+			if !ok {
+				return "", fmt.Errorf("pgvector: %s filter on %q needs a number or string, got %T", filter.Op, filter.Key, filter.Value)
+			}
+			return fmt.Sprintf(`COALESCE(CASE WHEN jsonb_typeof(metadata -> %s::text) = 'number' THEN (metadata ->> %s::text)::numeric %s %s::numeric END, FALSE)`, key, key, operator, param(strconv.FormatFloat(number, 'f', -1, 64))), nil
+		}
+	}
+	return "", fmt.Errorf("pgvector: unsupported filter op %q", filter.Op)
+}
+
+func containment(key string, value any) (string, error) {
+	data, err := json.Marshal(map[string]any{key: value})
+	if err != nil {
+		return "", fmt.Errorf("pgvector: encode filter value for %q: %w", key, err)
+	}
+	return string(data), nil
+}
+
+// encodeVector formats v as a pgvector literal such as "[1,0.5,-2]".
+func encodeVector(v []float64) string {
+	buf := make([]byte, 0, 2+len(v)*10)
+	buf = append(buf, '[')
+	for idx, x := range v {
+		if idx > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendFloat(buf, x, 'g', -1, 32)
+	}
+	return string(append(buf, ']'))
+}
+
+// decodeVector parses a pgvector literal.
+func decodeVector(text string) ([]float64, error) {
+	text = strings.TrimSpace(text)
+	if len(text) < 2 || text[0] != '[' || text[len(text)-1] != ']' {
+		return nil, fmt.Errorf("invalid vector %q", text)
+	}
+	text = text[1 : len(text)-1]
+	if strings.TrimSpace(text) == "" {
+		return []float64{}, nil
+	}
+
+	fields := strings.Split(text, ",")
+	out := make([]float64, len(fields))
+	for idx, field := range fields {
+		x, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector element %q", field)
+		}
+		out[idx] = x
+	}
+	return out, nil
+}
+
+// quoteIdentifier quotes each part of a validated, optionally schema
+// qualified identifier.
+func quoteIdentifier(identifier string) string {
+	parts := strings.Split(identifier, ".")
+	for idx, part := range parts {
+		parts[idx] = `"` + part + `"`
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,292 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// fakeDB is a database/sql driver that records statements and answers
+// queries with scripted rows, standing in for PostgreSQL.
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []fakeStatement
+	rows       [][]driver.Value
+}
+
+type fakeStatement struct {
+	query string
+	args  []any
+}
+
+func newFakeDB(t *testing.T) (*fakeDB, *sql.DB) {
+	t.Helper()
+	fake := &fakeDB{}
+	db := sql.OpenDB(fakeConnector{fake})
+	t.Cleanup(func() { db.Close() })
+	return fake, db
+}
+
+func (f *fakeDB) record(query string, args []driver.NamedValue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	f.statements = append(f.statements, fakeStatement{query: query, args: values})
+}
+
+func (f *fakeDB) queries() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]string, len(f.statements))
+	for i, statement := range f.statements {
+		out[i] = statement.query
+	}
+	return out
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: c.db}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use the connector") }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.db.record("BEGIN", nil)
+	return fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query, args)
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	return &fakeRows{rows: c.db.rows}, nil
+}
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx fakeTx) Commit() error   { tx.db.record("COMMIT", nil); return nil }
+func (tx fakeTx) Rollback() error { tx.db.record("ROLLBACK", nil); return nil }
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "text", "embedding", "metadata", "score"}
+}
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	fake, db := newFakeDB(t)
+	store, err := New(db, WithTable("rag.documents"), WithDimensions(3), WithHNSWIndex(24, 100))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	queries := fake.queries()
+	if len(queries) != 4 || queries[0] != "CREATE EXTENSION IF NOT EXISTS vector" {
+		t.Fatalf("queries = %q", queries)
+	}
+	if !strings.Contains(queries[1], `CREATE TABLE IF NOT EXISTS "rag"."documents"`) || !strings.Contains(queries[1], "embedding vector(3) NOT NULL") {
+		t.Fatalf("create table = %q", queries[1])
+	}
+	if want := `CREATE INDEX IF NOT EXISTS "documents_embedding_idx" ON "rag"."documents" USING hnsw (embedding vector_cosine_ops) WITH (m = 24, ef_construction = 100)`; queries[2] != want {
+		t.Fatalf("vector index = %q", queries[2])
+	}
+	if !strings.Contains(queries[3], "USING gin (metadata jsonb_path_ops)") {
+		t.Fatalf("metadata index = %q", queries[3])
+	}
+
+	ivfflat, _ := New(db, WithDimensions(3), WithIVFFlatIndex(50))
+	schema, err := ivfflat.Schema()
+	if err != nil || !strings.HasSuffix(schema[2], "USING ivfflat (embedding vector_cosine_ops) WITH (lists = 50)") {
+		t.Fatalf("ivfflat schema = %q, %v", schema, err)
+	}
+	exact, _ := New(db, WithDimensions(3), WithoutIndex())
+	if schema, _ := exact.Schema(); len(schema) != 3 {
+		t.Fatalf("schema without index = %q", schema)
+	}
+	noDimensions, _ := New(db)
+	if err := noDimensions.Migrate(context.Background()); err == nil || !strings.Contains(err.Error(), "WithDimensions") {
+		t.Fatalf("Migrate() without dimensions error = %v", err)
+	}
+}
+
+func TestNewValidatesTable(t *testing.T) {
+	t.Parallel()
+
+	_, db := newFakeDB(t)
+	for _, table := range []string{`docs"; DROP TABLE users; --`, "a.b.c", "1docs"} {
+		if _, err := New(db, WithTable(table)); err == nil {
+			t.Fatalf("New(WithTable(%q)) expected error", table)
+		}
+	}
+	if _, err := New(nil); err == nil {
+		t.Fatal("New(nil) expected error")
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	t.Parallel()
+
+	fake, db := newFakeDB(t)
+	adapter := core.NewMockAdapter().ReplyEmbeddings([]float64{0, 0.5})
+	store, err := New(db, WithDimensions(2), WithEmbeddingAdapter(adapter))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = store.Upsert(context.Background(),
+		core.VectorDocument{ID: "a", Text: "first", Embedding: []float64{1, 0}, Metadata: map[string]any{"lang": "en"}},
+		core.VectorDocument{ID: "b", Text: "embedded"},
+		core.VectorDocument{ID: "a", Text: "replaced", Embedding: []float64{0.25, -1}},
+	)
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+
+	statements := fake.statements
+	if len(statements) != 3 || statements[0].query != "BEGIN" || statements[2].query != "COMMIT" {
+		t.Fatalf("statements = %q", fake.queries())
+	}
+	insert := statements[1]
+	want := `INSERT INTO "goai_documents" (id, text, embedding, metadata) VALUES ($1, $2, $3::vector, $4::jsonb), ($5, $6, $7::vector, $8::jsonb) ON CONFLICT (id) DO UPDATE SET text = EXCLUDED.text, embedding = EXCLUDED.embedding, metadata = EXCLUDED.metadata`
+	if insert.query != want {
+		t.Fatalf("insert = %q", insert.query)
+	}
+	if wantArgs := []any{"b", "embedded", "[0,0.5]", "{}", "a", "replaced", "[0.25,-1]", "{}"}; !reflect.DeepEqual(insert.args, wantArgs) {
+		t.Fatalf("args = %#v", insert.args)
+	}
+
+	if err := store.Upsert(context.Background(), core.VectorDocument{ID: "c", Embedding: []float64{1}}); err == nil || !strings.Contains(err.Error(), "1 dimensions") {
+		t.Fatalf("Upsert() dimension error = %v", err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	fake, db := newFakeDB(t)
+	fake.rows = [][]driver.Value{
+		{"a", "east", "[1,0]", `{"lang": "en", "year": 2024}`, 0.99},
+		{"b", "north", "[0,1]", `{}`, 0.4},
+	}
+	store, err := New(db, WithDimensions(2), WithEFSearch(80))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	minScore := 0.5
+	matches, err := store.Query(context.Background(), &core.VectorQuery{
+		Embedding: []float64{1, 0.1},
+		TopK:      5,
+		MinScore:  &minScore,
+		Filter:    core.And(core.Eq("lang", "en"), core.Gte("year", 2024), core.Not(core.In("tag", "draft", "old"))),
+	})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	want := []core.VectorMatch{{
+		VectorDocument: core.VectorDocument{ID: "a", Text: "east", Embedding: []float64{1, 0}, Metadata: map[string]any{"lang": "en", "year": float64(2024)}},
+		Score:          0.99,
+	}}
+	if !reflect.DeepEqual(matches, want) {
+		t.Fatalf("matches = %+v", matches)
+	}
+
+	queries := fake.queries()
+	if len(queries) != 4 || queries[1] != "SET LOCAL hnsw.ef_search = 80" {
+		t.Fatalf("queries = %q", queries)
+	}
+	query := fake.statements[2]
+	wantQuery := `SELECT id, text, embedding::text, metadata::text, 1 - (embedding <=> $1::vector) AS score FROM "goai_documents" ` +
+		`WHERE (metadata @> $2::jsonb AND COALESCE(CASE WHEN jsonb_typeof(metadata -> $3::text) = 'number' THEN (metadata ->> $3::text)::numeric >= $4::numeric END, FALSE) ` +
+		`AND (NOT (metadata @> $5::jsonb OR metadata @> $6::jsonb))) ORDER BY embedding <=> $1::vector LIMIT $7`
+	if query.query != wantQuery {
+		t.Fatalf("query = %q", query.query)
+	}
+	if wantArgs := []any{"[1,0.1]", `{"lang":"en"}`, "year", "2024", `{"tag":"draft"}`, `{"tag":"old"}`, int64(5)}; !reflect.DeepEqual(query.args, wantArgs) {
+		t.Fatalf("args = %#v", query.args)
+	}
+
+	if _, err := store.Query(context.Background(), &core.VectorQuery{Embedding: []float64{1, 0}, Filter: core.Gt("tags", []string{"a"})}); err == nil {
+		t.Fatal("Query() expected error for a non-scalar range filter")
+	}
+	if _, err := store.Query(context.Background(), &core.VectorQuery{Text: "no adapter"}); err == nil {
+		t.Fatal("Query() expected error for a text query without adapter")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+
+	fake, db := newFakeDB(t)
+	store, _ := New(db, WithTable("docs"))
+	if err := store.Delete(context.Background(), "a", "b"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	statement := fake.statements[0]
+	if statement.query != `DELETE FROM "docs" WHERE id IN ($1, $2)` || !reflect.DeepEqual(statement.args, []any{"a", "b"}) {
+		t.Fatalf("statement = %+v", statement)
+	}
+}
+
+func TestVectorEncoding(t *testing.T) {
+	t.Parallel()
+
+	if got := encodeVector([]float64{1, -0.5, 1e-7}); got != "[1,-0.5,1e-07]" {
+		t.Fatalf("encodeVector() = %q", got)
+	}
+	got, err := decodeVector("[1,-0.5, 1e-07]")
+	if err != nil || !reflect.DeepEqual(got, []float64{1, -0.5, 1e-7}) {
+		t.Fatalf("decodeVector() = %v, %v", got, err)
+	}
+	if _, err := decodeVector("1,2"); err == nil {
+		t.Fatal("decodeVector() expected error")
+	}
+}
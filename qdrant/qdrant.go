@@ -0,0 +1,509 @@
+// Package qdrant implements core.VectorStore on a Qdrant collection through
+// Qdrant's HTTP API.
+//
+//	store := qdrant.New("documents",
+//		qdrant.WithDimensions(1536),
+//		qdrant.WithEmbeddingAdapter(openai.New("text-embedding-3-small")),
+//	)
+//	err := store.EnsureCollection(ctx)
+//
+// Qdrant only accepts unsigned integers and UUIDs as point IDs, so each
+// document ID is mapped to a name-based UUID and kept in the payload next to
+// the text and metadata. Filters apply to the metadata.
+package qdrant
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+const (
+	providerName       = "qdrant"
+	defaultBaseURL     = "http://localhost:6333"
+	defaultBatchSize   = 256
+	defaultHTTPTimeout = time.Minute
+
+	// Payload fields holding the document besides its vector.
+	payloadID       = "document_id"
+	payloadText     = "text"
+	payloadMetadata = "metadata"
+)
+
+// pointNamespace is the UUID namespace of point IDs derived from document
+// IDs, the RFC 4122 URL namespace.
+var pointNamespace = [16]byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+// Store is a core.VectorStore backed by a Qdrant collection.
+type Store struct {
+	APIKey     string
+	BaseURL    string
+	Collection string
+	HTTPClient *http.Client
+
+	// Dimensions is the vector size EnsureCollection creates the collection
+	// with.
+	Dimensions int
+
+	// Adapter embeds documents and queries given only as text.
+	Adapter core.EmbeddingAdapter
+
+	// BatchSize is the number of points sent per upsert request.
+	BatchSize int
+
+	// PayloadIndexes maps metadata keys to the payload index schema
+	// EnsureCollection creates for them, such as "keyword" or "integer".
+	PayloadIndexes map[string]string
+}
+
+var _ core.VectorStore = (*Store)(nil)
+
+// Option configures a Store.
+type Option func(*Store)
+
+// New creates a store for the named collection.
+//
+// If no URL or API key is provided via options, New reads QDRANT_URL and
+// QDRANT_API_KEY from the environment. The URL defaults to a local Qdrant.
+func New(collection string, opts ...Option) *Store {
+	store := &Store{
+		APIKey:     strings.TrimSpace(os.Getenv("QDRANT_API_KEY")),
+		BaseURL:    strings.TrimSpace(os.Getenv("QDRANT_URL")),
+		Collection: strings.TrimSpace(collection),
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+		BatchSize:  defaultBatchSize,
+	}
+	if store.BaseURL == "" {
+		store.BaseURL = defaultBaseURL
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(store)
+	}
+
+	return store
+}
+
+// WithAPIKey sets the API key sent in the api-key header.
+func WithAPIKey(apiKey string) Option {
+	return func(store *Store) {
+		store.APIKey = strings.TrimSpace(apiKey)
+	}
+}
+
+// WithBaseURL sets the Qdrant URL, such as "https://xyz.cloud.qdrant.io:6333".
+func WithBaseURL(baseURL string) Option {
+	return func(store *Store) {
+		if strings.TrimSpace(baseURL) == "" {
+			return
+		}
+		store.BaseURL = strings.TrimSpace(baseURL)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(store *Store) {
+		if client != nil {
+			store.HTTPClient = client
+		}
+	}
+}
+
+// WithDimensions sets the vector size EnsureCollection creates the
+// collection with.
+func WithDimensions(dimensions int) Option {
+	return func(store *Store) {
+		store.Dimensions = dimensions
+	}
+}
+
+// WithEmbeddingAdapter sets the adapter that embeds documents and queries
+// given only as text.
+func WithEmbeddingAdapter(adapter core.EmbeddingAdapter) Option {
+	return func(store *Store) {
+		store.Adapter = adapter
+	}
+}
+
+// WithBatchSize sets the number of points sent per upsert request. The
+// default is 256.
+func WithBatchSize(size int) Option {
+	return func(store *Store) {
+		if size > 0 {
+			store.BatchSize = size
+		}
+	}
+}
+
+// WithPayloadIndex makes EnsureCollection index the metadata key with
+// schema, such as "keyword", "integer", "float", or "bool", which speeds up
+// filters on it.
+func WithPayloadIndex(key, schema string) Option {
+	return func(store *Store) {
+		if store.PayloadIndexes == nil {
+			store.PayloadIndexes = make(map[string]string)
+		}
+		store.PayloadIndexes[key] = schema
+	}
+}
+
+// EnsureCollection creates the collection for cosine similarity when it
+// does not exist, and the payload indexes set with WithPayloadIndex.
+func (s *Store) EnsureCollection(ctx context.Context) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	var exists struct {
+		Result struct {
+			Exists bool `json:"exists"`
+		} `json:"result"`
+	}
+	if _, err := s.transport().Send(ctx, httpclient.Request{Method: http.MethodGet, Path: s.collectionPath("/exists"), Name: "collection exists"}, &exists); err != nil {
+		return err
+	}
+
+	if !exists.Result.Exists {
+		if s.Dimensions <= 0 {
+			return errors.New("qdrant: dimensions are required to create the collection (use qdrant.WithDimensions)")
+		}
+		body := map[string]any{"vectors": map[string]any{"size": s.Dimensions, "distance": "Cosine"}}
+		if _, err := s.transport().Send(ctx, httpclient.Request{Method: http.MethodPut, Path: s.collectionPath(""), Name: "create collection", Body: body}, nil); err != nil {
+			return err
+		}
+	}
+
+	for key, schema := range s.PayloadIndexes {
+		body := map[string]any{"field_name": payloadMetadata + "." + key, "field_schema": schema}
+		if _, err := s.transport().Send(ctx, httpclient.Request{Method: http.MethodPut, Path: s.collectionPath("/index?wait=true"), Name: "create payload index", Body: body}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteCollection deletes the collection and every point in it.
+func (s *Store) DeleteCollection(ctx context.Context) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	_, err := s.transport().Send(ctx, httpclient.Request{Method: http.MethodDelete, Path: s.collectionPath(""), Name: "delete collection"}, nil)
+	return err
+}
+
+// Upsert implements core.VectorStore. Points are sent in batches of
+// BatchSize and each request waits until Qdrant has applied it.
+func (s *Store) Upsert(ctx context.Context, documents ...core.VectorDocument) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	if len(documents) == 0 {
+		return nil
+	}
+	documents = append([]core.VectorDocument(nil), documents...)
+	if err := core.EmbedDocuments(ctx, s.Adapter, documents); err != nil {
+		return err
+	}
+
+	points := make([]point, len(documents))
+	for idx, document := range documents {
+		if s.Dimensions > 0 && len(document.Embedding) != s.Dimensions {
+			return fmt.Errorf("qdrant: document %q has %d dimensions, the store has %d", document.ID, len(document.Embedding), s.Dimensions)
+		}
+		payload := map[string]any{payloadID: document.ID, payloadText: document.Text}
+		if len(document.Metadata) > 0 {
+			payload[payloadMetadata] = document.Metadata
+		}
+		points[idx] = point{ID: pointID(document.ID), Vector: document.Embedding, Payload: payload}
+	}
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	for start := 0; start < len(points); start += batchSize {
+		body := map[string]any{"points": points[start:min(start+batchSize, len(points))]}
+		if _, err := s.transport().Send(ctx, httpclient.Request{Method: http.MethodPut, Path: s.collectionPath("/points?wait=true"), Name: "upsert", Body: body}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Query implements core.VectorStore. Scores are cosine similarities.
+func (s *Store) Query(ctx context.Context, query *core.VectorQuery) ([]core.VectorMatch, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	embedding, err := core.QueryEmbedding(ctx, s.Adapter, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := query.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	topK := query.TopK
+	if topK <= 0 {
+		topK = core.DefaultVectorQueryTopK
+	}
+
+	request := searchRequest{
+		Vector:         embedding,
+		Limit:          topK,
+		WithPayload:    true,
+		WithVector:     true,
+		ScoreThreshold: query.MinScore,
+	}
+	if query.Filter != nil {
+		condition, err := filterCondition(query.Filter)
+		if err != nil {
+			return nil, err
+		}
+		request.Filter = asFilter(condition)
+	}
+
+	var response struct {
+		Result []scoredPoint `json:"result"`
+	}
+	if _, err := s.transport().Send(ctx, httpclient.Request{Path: s.collectionPath("/points/search"), Name: "search", Body: request}, &response); err != nil {
+		return nil, err
+	}
+
+	matches := make([]core.VectorMatch, 0, len(response.Result))
+	for _, scored := range response.Result {
+		match := core.VectorMatch{Score: scored.Score}
+		match.ID, _ = scored.Payload[payloadID].(string)
+		match.Text, _ = scored.Payload[payloadText].(string)
+		match.Metadata, _ = scored.Payload[payloadMetadata].(map[string]any)
+		match.Embedding = scored.Vector
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// Delete implements core.VectorStore.
+func (s *Store) Delete(ctx context.Context, ids ...string) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	points := make([]string, len(ids))
+	for idx, id := range ids {
+		points[idx] = pointID(id)
+	}
+	_, err := s.transport().Send(ctx, httpclient.Request{Path: s.collectionPath("/points/delete?wait=true"), Name: "delete", Body: map[string]any{"points": points}}, nil)
+	return err
+}
+
+type point struct {
+	ID      string         `json:"id"`
+	Vector  []float64      `json:"vector"`
+	Payload map[string]any `json:"payload"`
+}
+
+type searchRequest struct {
+	Vector         []float64 `json:"vector"`
+	Limit          int       `json:"limit"`
+	Filter         any       `json:"filter,omitempty"`
+	WithPayload    bool      `json:"with_payload"`
+	WithVector     bool      `json:"with_vector"`
+	ScoreThreshold *float64  `json:"score_threshold,omitempty"`
+}
+
+type scoredPoint struct {
+	ID      any            `json:"id"`
+	Score   float64        `json:"score"`
+	Payload map[string]any `json:"payload"`
+	Vector  []float64      `json:"vector"`
+}
+
+// filterCondition translates filter to a Qdrant condition on the metadata
+// payload. Boolean operators become nested filters.
+func filterCondition(filter *core.Filter) (map[string]any, error) {
+	switch filter.Op {
+	case core.FilterAnd, core.FilterOr, core.FilterNot:
+		conditions := make([]map[string]any, len(filter.Filters))
+		for idx, operand := range filter.Filters {
+			condition, err := filterCondition(operand)
+			if err != nil {
+				return nil, err
+			}
+			conditions[idx] = condition
+		}
+		clause := map[core.FilterOp]string{core.FilterAnd: "must", core.FilterOr: "should", core.FilterNot: "must_not"}[filter.Op]
+		return map[string]any{clause: conditions}, nil
+
+	case core.FilterEq:
+		return matchCondition(filter.Key, filter.Value)
+
+	case core.FilterNe:
+		condition, err := matchCondition(filter.Key, filter.Value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"must_not": []map[string]any{condition}}, nil
+
+	case core.FilterIn:
+		conditions := make([]map[string]any, len(filter.Values))
+		for idx, value := range filter.Values {
+			condition, err := matchCondition(filter.Key, value)
+			if err != nil {
+				return nil, err
+			}
+			conditions[idx] = condition
+		}
+		return map[string]any{"should": conditions}, nil
+
+	case core.FilterGt, core.FilterGte, core.FilterLt, core.FilterLte:
+		key := payloadMetadata + "." + filter.Key
+		if number, ok := core.FilterNumber(filter.Value); ok {
+			return map[string]any{"key": key, "range": map[string]any{string(filter.Op): number}}, nil
+		}
+		// Qdrant orders strings only as datetimes.
+		if value, ok := filter.Value.(string); ok {
+			if _, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				return map[string]any{"key": key, "datetime_range": map[string]any{string(filter.Op): value}}, nil
+			}
+			return nil, fmt.Errorf("qdrant: %s filter on %q needs a number or an RFC 3339 timestamp, got %q", filter.Op, filter.Key, value)
+		}
+		return nil, fmt.Errorf("qdrant: %s filter on %q needs a number or an RFC 3339 timestamp, got %T", filter.Op, filter.Key, filter.Value)
+	}
+	return nil, fmt.Errorf("qdrant: unsupported filter op %q", filter.Op)
+}
+
+// matchCondition matches key to value exactly. Qdrant matches integers,
+// strings, and booleans, so fractional numbers use a closed range.
+func matchCondition(key string, value any) (map[string]any, error) {
+	key = payloadMetadata + "." + key
+	switch typed := value.(type) {
+	case string, bool:
+		return map[string]any{"key": key, "match": map[string]any{"value": typed}}, nil
+	}
+
+	number, ok := core.FilterNumber(value)
+	if !ok {
+		return nil, fmt.Errorf("qdrant: filter on %q needs a string, number, or bool, got %T", key, value)
+	}
+	if number == math.Trunc(number) && math.Abs(number) < 1<<53 {
+		return map[string]any{"key": key, "match": map[string]any{"value": int64(number)}}, nil
+	}
+	return map[string]any{"key": key, "range": map[string]any{"gte": number, "lte": number}}, nil
+}
+
+// asFilter wraps a condition as a filter unless it already is one.
+func asFilter(condition map[string]any) map[string]any {
+	for _, clause := range []string{"must", "should", "must_not"} {
+		if _, ok := condition[clause]; ok {
+			return condition
+		}
+	}
+	return map[string]any{"must": []map[string]any{condition}}
+}
+
+// pointID returns the name-based (version 5) UUID of a document ID.
+func pointID(id string) string {
+	hash := sha1.New()
+	hash.Write(pointNamespace[:])
+	hash.Write([]byte(id))
+	sum := hash.Sum(nil)
+
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	encoded := hex.EncodeToString(sum[:16])
+	return encoded[0:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" + encoded[16:20] + "-" + encoded[20:32]
+}
+
+func (s *Store) validate() error {
+	if s == nil {
+		return errors.New("qdrant: store is nil")
+	}
+	if strings.TrimSpace(s.Collection) == "" {
+		return errors.New("qdrant: collection is required")
+	}
+	return nil
+}
+
+func (s *Store) collectionPath(suffix string) string {
+	return "/collections/" + url.PathEscape(s.Collection) + suffix
+}
+
+func (s *Store) client() *http.Client {
+	if s.HTTPClient != nil {
+		return s.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// transport returns the client that sends API requests with the store's
+// credentials.
+func (s *Store) transport() *httpclient.Client {
+	baseURL := strings.TrimRight(strings.TrimSpace(s.BaseURL), "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &httpclient.Client{
+		HTTPClient: s.client(),
+		BaseURL:    baseURL,
+		Provider:   providerName,
+		Header: func(header http.Header) {
+			if s.APIKey != "" {
+				header.Set("api-key", s.APIKey)
+			}
+		},
+		DecodeError: decodeAPIError,
+	}
+}
+
+func decodeAPIError(resp *http.Response) error {
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
+	if readErr != nil {
+		return fmt.Errorf("qdrant: API status %d and failed to read error body: %w", resp.StatusCode, readErr)
+	}
+
+	var envelope struct {
+		Status struct {
+			Error string `json:"error"`
+		} `json:"status"`
+	}
+	message := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Status.Error != "" {
+		message = envelope.Status.Error
+	}
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		var wait time.Duration
+		if seconds, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Retry-After"))); err == nil && seconds > 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+		return &core.RateLimitError{Message: "qdrant: API error: " + message, RetryAfter: wait}
+	}
+	return &core.APIError{
+		StatusCode: resp.StatusCode,
+		Message:    fmt.Sprintf("qdrant: API status %d: %s", resp.StatusCode, message),
+		Retryable:  resp.StatusCode == http.StatusServiceUnavailable,
+	}
+}
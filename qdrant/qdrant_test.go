@@ -0,0 +1,293 @@
+package qdrant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	APIKey string
+	Body   map[string]any
+}
+
+// fakeQdrant records requests and answers each path with a handler.
+type fakeQdrant struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+	handlers map[string]func(body map[string]any) (int, any)
+}
+
+func newFakeQdrant(t *testing.T, handlers map[string]func(map[string]any) (int, any)) (*fakeQdrant, *httptest.Server) {
+	t.Helper()
+	fake := &fakeQdrant{handlers: handlers}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &body); err != nil {
+				t.Errorf("decode request body: %v", err)
+			}
+		}
+		key := r.Method + " " + r.URL.RequestURI()
+		fake.mu.Lock()
+		fake.requests = append(fake.requests, recordedRequest{Method: r.Method, Path: r.URL.RequestURI(), APIKey: r.Header.Get("api-key"), Body: body})
+		handler := fake.handlers[key]
+		fake.mu.Unlock()
+
+		status, response := http.StatusOK, any(map[string]any{"result": true, "status": "ok"})
+		if handler != nil {
+			status, response = handler(body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeQdrant) recorded() []recordedRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]recordedRequest(nil), f.requests...)
+}
+
+func roundTrip(v any) any {
+	data, _ := json.Marshal(v)
+	var out any
+	_ = json.Unmarshal(data, &out)
+	return out
+}
+
+func TestPointID(t *testing.T) {
+	t.Parallel()
+
+	// uuid.uuid5(uuid.NAMESPACE_URL, "doc-1") in Python.
+	if got := pointID("doc-1"); got != "3f622591-baa6-5888-8a4f-6b3813e16a44" {
+		t.Fatalf("pointID() = %q", got)
+	}
+}
+
+func TestEnsureCollection(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakeQdrant(t, map[string]func(map[string]any) (int, any){
+		"GET /collections/docs/exists": func(map[string]any) (int, any) {
+			return http.StatusOK, map[string]any{"result": map[string]any{"exists": false}}
+		},
+	})
+	store := New("docs", WithBaseURL(server.URL), WithAPIKey("secret"), WithDimensions(3), WithPayloadIndex("lang", "keyword"))
+	if err := store.EnsureCollection(context.Background()); err != nil {
+		t.Fatalf("EnsureCollection() error = %v", err)
+	}
+
+	requests := fake.recorded()
+	if len(requests) != 3 || requests[0].APIKey != "secret" {
+		t.Fatalf("requests = %+v", requests)
+	}
+	create := requests[1]
+	if create.Method != http.MethodPut || create.Path != "/collections/docs" || !reflect.DeepEqual(create.Body, roundTrip(map[string]any{"vectors": map[string]any{"size": 3, "distance": "Cosine"}})) {
+		t.Fatalf("create = %+v", create)
+	}
+	index := requests[2]
+	if index.Path != "/collections/docs/index?wait=true" || index.Body["field_name"] != "metadata.lang" || index.Body["field_schema"] != "keyword" {
+		t.Fatalf("index = %+v", index)
+	}
+
+	if err := New("docs", WithBaseURL(server.URL)).EnsureCollection(context.Background()); err == nil || !strings.Contains(err.Error(), "dimensions") {
+		t.Fatalf("EnsureCollection() without dimensions error = %v", err)
+	}
+}
+
+func TestUpsertBatches(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakeQdrant(t, nil)
+	adapter := core.NewMockAdapter().ReplyEmbeddings([]float64{0, 1})
+	store := New("docs", WithBaseURL(server.URL), WithBatchSize(2), WithEmbeddingAdapter(adapter))
+
+	err := store.Upsert(context.Background(),
+		core.VectorDocument{ID: "doc-1", Text: "one", Embedding: []float64{1, 0}, Metadata: map[string]any{"lang": "en"}},
+		core.VectorDocument{ID: "doc-2", Text: "two"},
+		core.VectorDocument{ID: "doc-3", Embedding: []float64{0.5, 0.5}},
+	)
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+
+	requests := fake.recorded()
+	if len(requests) != 2 || requests[0].Method != http.MethodPut || requests[0].Path != "/collections/docs/points?wait=true" {
+		t.Fatalf("requests = %+v", requests)
+	}
+	first := requests[0].Body["points"].([]any)
+	second := requests[1].Body["points"].([]any)
+	if len(first) != 2 || len(second) != 1 {
+		t.Fatalf("batches = %d and %d points", len(first), len(second))
+	}
+	want := roundTrip(map[string]any{
+		"id":      "3f622591-baa6-5888-8a4f-6b3813e16a44",
+		"vector":  []float64{1, 0},
+		"payload": map[string]any{"document_id": "doc-1", "text": "one", "metadata": map[string]any{"lang": "en"}},
+	})
+	if !reflect.DeepEqual(first[0], want) {
+		t.Fatalf("point = %#v", first[0])
+	}
+	if embedded := first[1].(map[string]any)["vector"]; !reflect.DeepEqual(embedded, []any{0.0, 1.0}) {
+		t.Fatalf("embedded vector = %#v", embedded)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakeQdrant(t, map[string]func(map[string]any) (int, any){
+		"POST /collections/docs/points/search": func(map[string]any) (int, any) {
+			return http.StatusOK, map[string]any{"result": []any{map[string]any{
+				"id":      "3f622591-baa6-5888-8a4f-6b3813e16a44",
+				"score":   0.91,
+				"payload": map[string]any{"document_id": "doc-1", "text": "one", "metadata": map[string]any{"lang": "en"}},
+				"vector":  []float64{1, 0},
+			}}}
+		},
+	})
+	store := New("docs", WithBaseURL(server.URL))
+
+	minScore := 0.5
+	matches, err := store.Query(context.Background(), &core.VectorQuery{
+		Embedding: []float64{1, 0},
+		TopK:      3,
+		MinScore:  &minScore,
+		Filter:    core.And(core.Eq("lang", "en"), core.Gte("year", 2020), core.Ne("draft", true), core.In("score", 1.5, 2)),
+	})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	want := []core.VectorMatch{{
+		VectorDocument: core.VectorDocument{ID: "doc-1", Text: "one", Embedding: []float64{1, 0}, Metadata: map[string]any{"lang": "en"}},
+		Score:          0.91,
+	}}
+	if !reflect.DeepEqual(matches, want) {
+		t.Fatalf("matches = %+v", matches)
+	}
+
+	body := fake.recorded()[0].Body
+	wantBody := roundTrip(map[string]any{
+		"vector":          []float64{1, 0},
+		"limit":           3,
+		"with_payload":    true,
+		"with_vector":     true,
+		"score_threshold": 0.5,
+		"filter": map[string]any{"must": []any{
+			map[string]any{"key": "metadata.lang", "match": map[string]any{"value": "en"}},
+			map[string]any{"key": "metadata.year", "range": map[string]any{"gte": 2020}},
+			map[string]any{"must_not": []any{map[string]any{"key": "metadata.draft", "match": map[string]any{"value": true}}}},
+			map[string]any{"should": []any{
+				map[string]any{"key": "metadata.score", "range": map[string]any{"gte": 1.5, "lte": 1.5}},
+				map[string]any{"key": "metadata.score", "match": map[string]any{"value": 2}},
+			}},
+		}},
+	})
+	if !reflect.DeepEqual(body, wantBody) {
+		got, _ := json.Marshal(body)
+		t.Fatalf("search body = %s", got)
+	}
+
+	if _, err := store.Query(context.Background(), &core.VectorQuery{Embedding: []float64{1, 0}, Filter: core.Lt("lang", "m")}); err == nil || !strings.Contains(err.Error(), "RFC 3339") {
+		t.Fatalf("Query() error = %v, want an error for a string range filter", err)
+	}
+}
+
+func TestQueryDatetimeRange(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakeQdrant(t, map[string]func(map[string]any) (int, any){
+		"POST /collections/docs/points/search": func(map[string]any) (int, any) {
+			return http.StatusOK, map[string]any{"result": []any{}}
+		},
+	})
+	store := New("docs", WithBaseURL(server.URL))
+	filter := core.And(core.Gte("published", "2024-01-01T00:00:00Z"), core.Lt("published", "2025-01-01T00:00:00Z"))
+	if _, err := store.Query(context.Background(), &core.VectorQuery{Embedding: []float64{1}, Filter: filter}); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	want := roundTrip(map[string]any{"must": []any{
+		map[string]any{"key": "metadata.published", "datetime_range": map[string]any{"gte": "2024-01-01T00:00:00Z"}},
+		map[string]any{"key": "metadata.published", "datetime_range": map[string]any{"lt": "2025-01-01T00:00:00Z"}},
+	}})
+	if got := fake.recorded()[0].Body["filter"]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("filter = %#v", got)
+	}
+}
+
+func TestQueryWrapsSingleCondition(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakeQdrant(t, map[string]func(map[string]any) (int, any){
+		"POST /collections/docs/points/search": func(map[string]any) (int, any) {
+			return http.StatusOK, map[string]any{"result": []any{}}
+		},
+	})
+	store := New("docs", WithBaseURL(server.URL))
+	if _, err := store.Query(context.Background(), &core.VectorQuery{Embedding: []float64{1}, Filter: core.Eq("lang", "en")}); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	want := roundTrip(map[string]any{"must": []any{map[string]any{"key": "metadata.lang", "match": map[string]any{"value": "en"}}}})
+	if filter := fake.recorded()[0].Body["filter"]; !reflect.DeepEqual(filter, want) {
+		t.Fatalf("filter = %#v", filter)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakeQdrant(t, nil)
+	store := New("docs", WithBaseURL(server.URL))
+	if err := store.Delete(context.Background(), "doc-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	request := fake.recorded()[0]
+	if request.Method != http.MethodPost || request.Path != "/collections/docs/points/delete?wait=true" || !reflect.DeepEqual(request.Body["points"], []any{"3f622591-baa6-5888-8a4f-6b3813e16a44"}) {
+		t.Fatalf("request = %+v", request)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	_, server := newFakeQdrant(t, map[string]func(map[string]any) (int, any){
+		"POST /collections/missing/points/delete?wait=true": func(map[string]any) (int, any) {
+			return http.StatusNotFound, map[string]any{"status": map[string]any{"error": "Not found: Collection `missing` doesn't exist!"}}
+		},
+		"POST /collections/busy/points/delete?wait=true": func(map[string]any) (int, any) {
+			return http.StatusTooManyRequests, map[string]any{"status": map[string]any{"error": "too many requests"}}
+		},
+	})
+
+	err := New("missing", WithBaseURL(server.URL)).Delete(context.Background(), "a")
+	var apiErr *core.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || !strings.Contains(err.Error(), "doesn't exist") {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	err = New("busy", WithBaseURL(server.URL)).Delete(context.Background(), "a")
+	var rateLimit *core.RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if err := New("", WithBaseURL(server.URL)).Delete(context.Background(), "a"); err == nil || !strings.Contains(err.Error(), "collection is required") {
+		t.Fatalf("Delete() without collection error = %v", err)
+	}
+}
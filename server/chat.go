@@ -0,0 +1,218 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	var request chatCompletionRequest
+	if !s.decodeBody(w, r, &request) {
+		return
+	}
+
+	adapter := s.textAdapter(request.Model)
+	if adapter == nil {
+		writeError(w, http.StatusNotFound, "invalid_request_error", "model_not_found", fmt.Sprintf("The model %q does not exist.", request.Model))
+		return
+	}
+
+	params, err := toChatParams(&request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", err.Error())
+		return
+	}
+
+	if request.Stream {
+		s.streamChatCompletion(w, r, adapter, &request, params)
+		return
+	}
+
+	result, err := adapter.Chat(r.Context(), params)
+	if err != nil {
+		s.writeAdapterError(w, err)
+		return
+	}
+
+	response := chatCompletionResponse{
+		ID:      newID("chatcmpl-"),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   request.Model,
+		Usage:   fromCoreUsage(result.Usage),
+	}
+	if result.RequestID != "" {
+		w.Header().Set("X-Request-Id", result.RequestID)
+	}
+	for idx, candidate := range result.AllCandidates() {
+		message := responseMessage{Role: core.RoleAssistant, ReasoningContent: candidate.Reasoning}
+		if candidate.Text != "" || len(candidate.ToolCalls) == 0 {
+			text := candidate.Text
+			message.Content = &text
+		}
+		if len(candidate.ToolCalls) > 0 {
+			message.ToolCalls = fromCoreToolCalls(candidate.ToolCalls, false)
+		}
+		response.Choices = append(response.Choices, chatChoice{
+			Index:        idx,
+			Message:      message,
+			FinishReason: fromCoreFinishReason(candidate.FinishReason),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// streamChatCompletion answers with server-sent chat completion chunks. Errors
+// before the first chunk get a regular error response; later errors are sent
+// as an error event.
+func (s *Server) streamChatCompletion(w http.ResponseWriter, r *http.Request, adapter core.TextAdapter, request *chatCompletionRequest, params *core.ChatParams) {
+	stream, err := adapter.ChatStream(r.Context(), params)
+	if err != nil {
+		s.writeAdapterError(w, err)
+		return
+	}
+
+	// Drain the stream when the client goes away, so the adapter can finish.
+	defer func() {
+		for range stream {
+		}
+	}()
+
+	writer := &chunkWriter{
+		w:       w,
+		id:      newID("chatcmpl-"),
+		created: time.Now().Unix(),
+		model:   request.Model,
+	}
+	writer.flusher, _ = w.(http.Flusher)
+
+	toolCalls := 0
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkContent:
+			if chunk.Delta != "" {
+				writer.delta(chunkDelta{Content: chunk.Delta})
+			}
+
+		case core.StreamChunkReasoning:
+			if chunk.Delta != "" {
+				writer.delta(chunkDelta{ReasoningContent: chunk.Delta})
+			}
+
+		case core.StreamChunkToolCall:
+			if chunk.ToolCall == nil {
+				continue
+			}
+			call := fromCoreToolCalls([]core.ToolCall{*chunk.ToolCall}, true)[0]
+			*call.Index = toolCalls
+			toolCalls++
+			writer.delta(chunkDelta{ToolCalls: []toolCall{call}})
+
+		case core.StreamChunkError:
+			message := s.errorMessage(chunk.Error, "The upstream provider request failed.")
+			if !writer.started {
+				writeError(w, http.StatusBadGateway, "server_error", "", message)
+				return
+			}
+			writer.event(errorResponse{Error: errorBody{Message: message, Type: "server_error"}})
+			return
+
+		case core.StreamChunkDone:
+			finishReason := fromCoreFinishReason(chunk.FinishReason)
+			if toolCalls > 0 && chunk.FinishReason == "" {
+				finishReason = "tool_calls"
+			}
+			writer.finish(finishReason)
+			if request.StreamOptions != nil && request.StreamOptions.IncludeUsage {
+				reported := fromCoreUsage(chunk.Usage)
+				if reported == nil {
+					reported = &usage{}
+				}
+				writer.usage(reported)
+			}
+			writer.done()
+			return
+		}
+	}
+
+	// The stream closed without a done chunk, such as when the request was
+	// canceled.
+	if r.Context().Err() == nil {
+		writer.finish("stop")
+		writer.done()
+	}
+}
+
+// chunkWriter writes chat completion chunks as server-sent events. The first
+// chunk announces the assistant role, as the OpenAI API does.
+type chunkWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	id      string
+	created int64
+	model   string
+	started bool
+}
+
+func (c *chunkWriter) start() {
+	if c.started {
+		return
+	}
+	c.started = true
+	header := c.w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	c.w.WriteHeader(http.StatusOK)
+	c.event(c.chunk([]chunkChoice{{Delta: chunkDelta{Role: core.RoleAssistant}}}))
+}
+
+func (c *chunkWriter) chunk(choices []chunkChoice) chatCompletionChunk {
+	return chatCompletionChunk{
+		ID:      c.id,
+		Object:  "chat.completion.chunk",
+		Created: c.created,
+		Model:   c.model,
+		Choices: choices,
+	}
+}
+
+func (c *chunkWriter) delta(delta chunkDelta) {
+	c.start()
+	c.event(c.chunk([]chunkChoice{{Delta: delta}}))
+}
+
+func (c *chunkWriter) finish(finishReason string) {
+	c.start()
+	c.event(c.chunk([]chunkChoice{{FinishReason: &finishReason}}))
+}
+
+func (c *chunkWriter) usage(reported *usage) {
+	chunk := c.chunk([]chunkChoice{})
+	chunk.Usage = reported
+	c.event(chunk)
+}
+
+func (c *chunkWriter) done() {
+	c.write("[DONE]")
+}
+
+func (c *chunkWriter) event(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	c.write(string(data))
+}
+
+func (c *chunkWriter) write(data string) {
+	_, _ = fmt.Fprintf(c.w, "data: %s\n\n", data)
+	if c.flusher != nil {
+		c.flusher.Flush()
+	}
+}
@@ -0,0 +1,361 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// toChatParams converts an OpenAI chat completion request to core params.
+// Tools are passed on as client tools, so tool calls go back to the caller.
+func toChatParams(request *chatCompletionRequest) (*core.ChatParams, error) {
+	if len(request.Messages) == 0 {
+		return nil, errors.New("messages must not be empty")
+	}
+
+	params := &core.ChatParams{
+		ParallelToolCalls: request.ParallelToolCalls,
+		Temperature:       request.Temperature,
+		TopP:              request.TopP,
+		MaxTokens:         request.MaxTokens,
+		Seed:              request.Seed,
+		CandidateCount:    request.N,
+		ReasoningEffort:   request.ReasoningEffort,
+		Metadata:          request.Metadata,
+	}
+	if request.MaxCompletionTokens != nil {
+		params.MaxTokens = request.MaxCompletionTokens
+	}
+
+	// Tool results name the tool the call they answer was made to.
+	toolNames := make(map[string]string)
+	for idx, message := range request.Messages {
+		switch message.Role {
+		case "system", "developer":
+			text, err := contentText(message.Content)
+			if err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", idx, err)
+			}
+			params.SystemPrompts = append(params.SystemPrompts, text)
+
+		case "user":
+			converted, err := userMessage(message)
+			if err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", idx, err)
+			}
+			params.Messages = append(params.Messages, converted)
+
+		case "assistant":
+			text, err := contentText(message.Content)
+			if err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", idx, err)
+			}
+			if text != "" {
+				params.Messages = append(params.Messages, core.TextMessagePart{Role: core.RoleAssistant, Content: text, Name: message.Name})
+			}
+			if len(message.ToolCalls) > 0 {
+				calls, err := toCoreToolCalls(message.ToolCalls)
+				if err != nil {
+					return nil, fmt.Errorf("messages[%d]: %w", idx, err)
+				}
+				for _, call := range calls {
+					toolNames[call.ID] = call.Name
+				}
+				params.Messages = append(params.Messages, core.ToolCallMessagePart{Role: core.RoleToolCall, ToolCalls: calls})
+			}
+
+		case "tool":
+			text, err := contentText(message.Content)
+			if err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", idx, err)
+			}
+			params.Messages = append(params.Messages, core.ToolResultMessagePart{
+				Role:       core.RoleToolResult,
+				ToolCallID: message.ToolCallID,
+				Name:       toolNames[message.ToolCallID],
+				Content:    text,
+			})
+
+		default:
+			return nil, fmt.Errorf("messages[%d]: unsupported role %q", idx, message.Role)
+		}
+	}
+
+	for idx, definition := range request.Tools {
+		if definition.Type != "function" || strings.TrimSpace(definition.Function.Name) == "" {
+			return nil, fmt.Errorf("tools[%d]: only named function tools are supported", idx)
+		}
+		params.Tools = append(params.Tools, core.ClientTool{
+			Name:        definition.Function.Name,
+			Description: definition.Function.Description,
+			Parameters:  definition.Function.Parameters,
+		})
+	}
+
+	choice, err := toToolChoice(request.ToolChoice)
+	if err != nil {
+		return nil, err
+	}
+	params.ToolChoice = choice
+
+	if params.StopSequences, err = stopSequences(request.Stop); err != nil {
+		return nil, err
+	}
+
+	if len(request.LogitBias) > 0 {
+		params.LogitBias = make(map[int64]float64, len(request.LogitBias))
+		for token, bias := range request.LogitBias {
+			id, err := strconv.ParseInt(token, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("logit_bias: invalid token %q", token)
+			}
+			params.LogitBias[id] = bias
+		}
+	}
+
+	if format := request.ResponseFormat; format != nil && format.Type == "json_schema" {
+		if format.JSONSchema == nil {
+			return nil, errors.New("response_format: json_schema is required")
+		}
+		schema, err := core.NewSchemaFromMap(format.JSONSchema.Name, format.JSONSchema.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("response_format: %w", err)
+		}
+		schema.Strict = format.JSONSchema.Strict
+		params.Output = &schema
+	}
+
+	return params, nil
+}
+
+func userMessage(message chatMessage) (core.MessageUnion, error) {
+	content := bytes.TrimSpace(message.Content)
+	if len(content) == 0 || content[0] != '[' {
+		text, err := contentText(content)
+		if err != nil {
+			return nil, err
+		}
+		return core.TextMessagePart{Role: core.RoleUser, Content: text, Name: message.Name}, nil
+	}
+
+	var parts []contentPart
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return nil, fmt.Errorf("decode content: %w", err)
+	}
+	out := core.ContentMessagePart{Role: core.RoleUser, Name: message.Name}
+	for idx, part := range parts {
+		converted, err := toContentPart(part)
+		if err != nil {
+			return nil, fmt.Errorf("content[%d]: %w", idx, err)
+		}
+		out.Parts = append(out.Parts, converted)
+	}
+	return out, nil
+}
+
+func toContentPart(part contentPart) (core.ContentPart, error) {
+	switch part.Type {
+	case "text":
+		return core.TextPart{Text: part.Text}, nil
+
+	case "image_url":
+		if part.ImageURL == nil || strings.TrimSpace(part.ImageURL.URL) == "" {
+			return nil, errors.New("image_url.url is required")
+		}
+		var metadata map[string]any
+		if part.ImageURL.Detail != "" {
+			metadata = map[string]any{"detail": part.ImageURL.Detail}
+		}
+		return core.ImagePart{Source: toSource(part.ImageURL.URL), Metadata: metadata}, nil
+
+	case "input_audio":
+		if part.InputAudio == nil || part.InputAudio.Data == "" {
+			return nil, errors.New("input_audio.data is required")
+		}
+		return core.AudioPart{Source: core.DataSource{Data: part.InputAudio.Data, MimeType: "audio/" + part.InputAudio.Format}}, nil
+
+	case "file":
+		if part.File == nil {
+			return nil, errors.New("file is required")
+		}
+		if part.File.FileID != "" {
+			return core.FilePart{FileID: part.File.FileID, Filename: part.File.Filename}, nil
+		}
+		if part.File.FileData == "" {
+			return nil, errors.New("file.file_id or file.file_data is required")
+		}
+		return core.DocumentPart{Source: toSource(part.File.FileData)}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported content part type %q", part.Type)
+}
+
+// toSource returns a data URL as a DataSource and any other URL as a
+// URLSource.
+func toSource(rawURL string) core.Source {
+	rawURL = strings.TrimSpace(rawURL)
+	if header, data, ok := strings.Cut(strings.TrimPrefix(rawURL, "data:"), ","); ok && strings.HasPrefix(rawURL, "data:") {
+		mimeType, _, _ := strings.Cut(header, ";")
+		return core.DataSource{Data: data, MimeType: mimeType}
+	}
+	return core.URLSource{URL: rawURL}
+}
+
+// contentText returns the text of a message content, which is a string, an
+// array of text parts, or null.
+func contentText(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil
+	}
+
+	var parts []contentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", errors.New("content must be a string or an array of parts")
+	}
+	var builder strings.Builder
+	for _, part := range parts {
+		if part.Type != "text" {
+			return "", fmt.Errorf("unsupported content part type %q for this role", part.Type)
+		}
+		builder.WriteString(part.Text)
+	}
+	return builder.String(), nil
+}
+
+func toCoreToolCalls(calls []toolCall) ([]core.ToolCall, error) {
+	out := make([]core.ToolCall, 0, len(calls))
+	for _, call := range calls {
+		var arguments any = map[string]any{}
+		if text := strings.TrimSpace(call.Function.Arguments); text != "" {
+			if err := json.Unmarshal([]byte(text), &arguments); err != nil {
+				return nil, fmt.Errorf("invalid arguments for tool %q: %w", call.Function.Name, err)
+			}
+		}
+		out = append(out, core.ToolCall{ID: call.ID, Name: call.Function.Name, Arguments: arguments})
+	}
+	return out, nil
+}
+
+func toToolChoice(raw json.RawMessage) (*core.ToolChoice, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	var mode string
+	if err := json.Unmarshal(raw, &mode); err == nil {
+		switch mode {
+		case core.ToolChoiceAuto, core.ToolChoiceNone, core.ToolChoiceRequired:
+			return &core.ToolChoice{Mode: mode}, nil
+		}
+		return nil, fmt.Errorf("tool_choice: unsupported mode %q", mode)
+	}
+
+	var named struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(raw, &named); err != nil || named.Function.Name == "" {
+		return nil, errors.New("tool_choice: expected a mode or a function name")
+	}
+	return &core.ToolChoice{Name: named.Function.Name}, nil
+}
+
+func stopSequences(raw json.RawMessage) ([]string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, errors.New("stop: expected a string or an array of strings")
+	}
+	return list, nil
+}
+
+// fromCoreToolCalls converts tool calls to the response format, encoding the
+// arguments as a JSON string.
+func fromCoreToolCalls(calls []core.ToolCall, indexed bool) []toolCall {
+	out := make([]toolCall, 0, len(calls))
+	for idx, call := range calls {
+		converted := toolCall{
+			ID:       call.ID,
+			Type:     "function",
+			Function: toolCallFunction{Name: call.Name, Arguments: argumentsJSON(call.Arguments)},
+		}
+		if indexed {
+			index := idx
+			converted.Index = &index
+		}
+		out = append(out, converted)
+	}
+	return out
+}
+
+func argumentsJSON(arguments any) string {
+	switch typed := arguments.(type) {
+	case nil:
+		return "{}"
+	case string:
+		if strings.TrimSpace(typed) == "" {
+			return "{}"
+		}
+		return typed
+	case json.RawMessage:
+		return string(typed)
+	}
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+func fromCoreFinishReason(reason core.FinishReason) string {
+	switch reason {
+	case "", core.FinishReasonStop, core.FinishReasonStopSequence:
+		return "stop"
+	case core.FinishReasonLength:
+		return "length"
+	case core.FinishReasonToolCalls:
+		return "tool_calls"
+	case core.FinishReasonContentFilter:
+		return "content_filter"
+	}
+	return string(reason)
+}
+
+func fromCoreUsage(in *core.Usage) *usage {
+	if in == nil {
+		return nil
+	}
+	out := &usage{
+		PromptTokens:     in.PromptTokens,
+		CompletionTokens: in.CompletionTokens,
+		TotalTokens:      in.TotalTokens,
+	}
+	if out.TotalTokens == 0 {
+		out.TotalTokens = out.PromptTokens + out.CompletionTokens
+	}
+	if in.ReasoningTokens > 0 {
+		out.CompletionTokensDetails = &completionTokensDetails{ReasoningTokens: in.ReasoningTokens}
+	}
+	return out
+}
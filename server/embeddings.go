@@ -0,0 +1,97 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	var request embeddingsRequest
+	if !s.decodeBody(w, r, &request) {
+		return
+	}
+
+	adapter := s.embeddingAdapter(request.Model)
+	if adapter == nil {
+		writeError(w, http.StatusNotFound, "invalid_request_error", "model_not_found", fmt.Sprintf("The model %q does not support embeddings.", request.Model))
+		return
+	}
+
+	inputs, err := embeddingInputs(request.Input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", err.Error())
+		return
+	}
+	if request.EncodingFormat != "" && request.EncodingFormat != "float" && request.EncodingFormat != "base64" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", fmt.Sprintf("encoding_format: unsupported value %q", request.EncodingFormat))
+		return
+	}
+
+	result, err := adapter.EmbedMany(r.Context(), &core.EmbedManyParams{Inputs: inputs, Dimensions: request.Dimensions})
+	if err != nil {
+		s.writeAdapterError(w, err)
+		return
+	}
+
+	response := embeddingsResponse{
+		Object: "list",
+		Data:   make([]embeddingData, 0, len(result.Embeddings)),
+		Model:  request.Model,
+	}
+	if result.Usage != nil {
+		response.Usage = embeddingsUsage{PromptTokens: result.Usage.PromptTokens, TotalTokens: result.Usage.TotalTokens}
+		if response.Usage.TotalTokens == 0 {
+			response.Usage.TotalTokens = response.Usage.PromptTokens
+		}
+	}
+	for idx, embedding := range result.Embeddings {
+		data := embeddingData{Object: "embedding", Index: idx, Embedding: embedding}
+		if request.EncodingFormat == "base64" {
+			data.Embedding = encodeBase64Embedding(embedding)
+		}
+		response.Data = append(response.Data, data)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// embeddingInputs returns the texts of an embeddings input, which is a string
+// or an array of strings. Token arrays are not supported because adapters
+// embed text.
+func embeddingInputs(raw json.RawMessage) ([]string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, errors.New("input is required")
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, errors.New("input: expected a string or an array of strings")
+	}
+	if len(list) == 0 {
+		return nil, errors.New("input must not be empty")
+	}
+	return list, nil
+}
+
+// encodeBase64Embedding encodes an embedding as little-endian float32 values,
+// the format OpenAI SDKs request by default.
+func encodeBase64Embedding(embedding []float64) string {
+	buf := make([]byte, 4*len(embedding))
+	for i, value := range embedding {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(value)))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,258 @@
+// Package server exposes any core adapter behind the OpenAI HTTP API, so
+// existing OpenAI SDK clients can talk to Claude, Ollama, or any other
+// adapter by changing their base URL.
+//
+// A Server is an http.Handler serving POST /v1/chat/completions, POST
+// /v1/embeddings, and GET /v1/models:
+//
+//	gateway := server.New(claude.New("claude-sonnet-4-5"))
+//	http.ListenAndServe(":8080", gateway)
+//
+// Clients then use http://localhost:8080/v1 as their base URL. Tools in a
+// request are passed to the adapter as client tools, so tool calls are
+// returned to the client, as with the OpenAI API.
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// defaultMaxBodyBytes limits request bodies unless WithMaxBodyBytes is used.
+const defaultMaxBodyBytes = 32 << 20
+
+// Server serves the OpenAI chat completions and embeddings APIs from core
+// adapters.
+type Server struct {
+	adapter      core.TextAdapter
+	embedder     core.EmbeddingAdapter
+	models       map[string]any
+	modelOrder   []string
+	apiKeys      [][]byte
+	maxBodyBytes int64
+	exposeErrors bool
+	mux          *http.ServeMux
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithEmbeddingAdapter sets the adapter that serves /v1/embeddings for
+// requests whose model was not registered with WithModel. By default the
+// text adapter is used when it also implements core.EmbeddingAdapter.
+func WithEmbeddingAdapter(adapter core.EmbeddingAdapter) Option {
+	return func(s *Server) {
+		s.embedder = adapter
+	}
+}
+
+// WithModel routes requests for the named model to adapter, which is a
+// core.TextAdapter, a core.EmbeddingAdapter, or both. Registered models are
+// listed by /v1/models. Requests for other models use the default adapters.
+func WithModel(name string, adapter any) Option {
+	return func(s *Server) {
+		name = strings.TrimSpace(name)
+		if name == "" || adapter == nil {
+			return
+		}
+		if _, ok := s.models[name]; !ok {
+			s.modelOrder = append(s.modelOrder, name)
+		}
+		s.models[name] = adapter
+	}
+}
+
+// WithAPIKeys requires requests to send one of keys as a bearer token.
+func WithAPIKeys(keys ...string) Option {
+	return func(s *Server) {
+		for _, key := range keys {
+			if key = strings.TrimSpace(key); key != "" {
+				s.apiKeys = append(s.apiKeys, []byte(key))
+			}
+		}
+	}
+}
+
+// WithMaxBodyBytes limits the size of request bodies. The default is 32 MiB.
+func WithMaxBodyBytes(n int64) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.maxBodyBytes = n
+		}
+	}
+}
+
+// WithUpstreamErrors passes the error messages of the providers behind the
+// adapters through to clients. By default clients get generic messages,
+// since provider errors can reveal the gateway's accounts, keys, or
+// configuration.
+func WithUpstreamErrors() Option {
+	return func(s *Server) {
+		s.exposeErrors = true
+	}
+}
+
+// New returns a Server that answers requests with adapter. adapter may be nil
+// when every model is registered with WithModel.
+func New(adapter core.TextAdapter, opts ...Option) *Server {
+	s := &Server{
+		adapter:      adapter,
+		models:       make(map[string]any),
+		maxBodyBytes: defaultMaxBodyBytes,
+	}
+	if embedder, ok := adapter.(core.EmbeddingAdapter); ok {
+		s.embedder = embedder
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("POST /v1/chat/completions", s.handleChatCompletions)
+	s.mux.HandleFunc("POST /v1/embeddings", s.handleEmbeddings)
+	s.mux.HandleFunc("GET /v1/models", s.handleModels)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid_request_error", "invalid_api_key", "Incorrect API key provided.")
+		return
+	}
+	if _, pattern := s.mux.Handler(r); pattern == "" {
+		writeError(w, http.StatusNotFound, "invalid_request_error", "unknown_url", fmt.Sprintf("Unknown request URL: %s %s.", r.Method, r.URL.Path))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if len(s.apiKeys) == 0 {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	for _, key := range s.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), key) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) textAdapter(model string) core.TextAdapter {
+	if adapter, ok := s.models[model].(core.TextAdapter); ok {
+		return adapter
+	}
+	return s.adapter
+}
+
+func (s *Server) embeddingAdapter(model string) core.EmbeddingAdapter {
+	if adapter, ok := s.models[model].(core.EmbeddingAdapter); ok {
+		return adapter
+	}
+	return s.embedder
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, _ *http.Request) {
+	list := modelList{Object: "list", Data: make([]modelInfo, 0, len(s.modelOrder))}
+	for _, name := range s.modelOrder {
+		list.Data = append(list.Data, modelInfo{ID: name, Object: "model", OwnedBy: "go-ai"})
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// decodeBody decodes the JSON request body into v and writes an error
+// response when it cannot.
+func (s *Server) decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	body := http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "invalid_request_error", "", fmt.Sprintf("Request body exceeds %d bytes.", tooLarge.Limit))
+			return false
+		}
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", fmt.Sprintf("Invalid JSON body: %v", err))
+		return false
+	}
+	return true
+}
+
+// writeAdapterError maps an adapter error to the status and error type the
+// OpenAI API uses for it. Upstream authentication failures are the
+// gateway's own, so they become a 502 rather than telling the client that
+// its key was rejected.
+func (s *Server) writeAdapterError(w http.ResponseWriter, err error) {
+	var rateLimit *core.RateLimitError
+	var apiErr *core.APIError
+	var notFound *core.ModelNotFoundError
+	var capability *core.CapabilityError
+	var contextLength *core.ContextLengthExceededError
+
+	if requestID := core.RequestIDOf(err); requestID != "" {
+		w.Header().Set("X-Request-Id", requestID)
+	}
+	switch {
+	case errors.As(err, &rateLimit):
+		if rateLimit.RetryAfter > 0 {
+			seconds := int64((rateLimit.RetryAfter + time.Second - 1) / time.Second)
+			w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+		}
+		writeError(w, http.StatusTooManyRequests, "rate_limit_error", "rate_limit_exceeded", s.errorMessage(err.Error(), "The upstream provider rate limited the request."))
+	case errors.As(err, &notFound):
+		writeError(w, http.StatusNotFound, "invalid_request_error", "model_not_found", s.errorMessage(err.Error(), "The model does not exist."))
+	case errors.As(err, &capability):
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "unsupported_parameter", err.Error())
+	case errors.As(err, &contextLength):
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "context_length_exceeded", s.errorMessage(err.Error(), "The request exceeds the context length of the model."))
+	case errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 &&
+		apiErr.StatusCode != http.StatusUnauthorized && apiErr.StatusCode != http.StatusForbidden:
+		writeError(w, apiErr.StatusCode, "invalid_request_error", "", s.errorMessage(err.Error(), "The upstream provider rejected the request."))
+	default:
+		writeError(w, http.StatusBadGateway, "server_error", "", s.errorMessage(err.Error(), "The upstream provider request failed."))
+	}
+}
+
+// errorMessage returns the upstream message when upstream errors are
+// exposed, and generic otherwise.
+func (s *Server) errorMessage(message, generic string) string {
+	if s.exposeErrors {
+		return message
+	}
+	return generic
+}
+
+func writeError(w http.ResponseWriter, status int, errorType, code, message string) {
+	body := errorBody{Message: message, Type: errorType}
+	if code != "" {
+		body.Code = &code
+	}
+	writeJSON(w, status, errorResponse{Error: body})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newID(prefix string) string {
+	var buf [12]byte
+	_, _ = rand.Read(buf[:])
+	return prefix + hex.EncodeToString(buf[:])
+}
@@ -0,0 +1,434 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/openai"
+)
+
+func newGateway(t *testing.T, adapter core.TextAdapter, opts ...Option) *httptest.Server {
+	t.Helper()
+	gateway := httptest.NewServer(New(adapter, opts...))
+	t.Cleanup(gateway.Close)
+	return gateway
+}
+
+func newClient(gateway *httptest.Server) *openai.Adapter {
+	return openai.New("proxied-model", openai.WithAPIKey("test-key"), openai.WithBaseURL(gateway.URL+"/v1"))
+}
+
+func post(t *testing.T, url, body string, header ...string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decodeError(t *testing.T, resp *http.Response) errorBody {
+	t.Helper()
+	var body errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	return body.Error
+}
+
+func TestChatCompletionThroughOpenAIAdapter(t *testing.T) {
+	t.Parallel()
+
+	backend := core.NewMockAdapter(core.MockResponse{Result: &core.ChatResult{
+		Text:         "Bonjour!",
+		Reasoning:    "Translate it.",
+		FinishReason: core.FinishReasonStop,
+		Usage:        &core.Usage{PromptTokens: 7, CompletionTokens: 2},
+	}})
+	client := newClient(newGateway(t, backend))
+
+	temperature := 0.2
+	result, err := client.Chat(context.Background(), &core.ChatParams{
+		SystemPrompts: []string{"You translate to French."},
+		Messages:      []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hello!"}},
+		Temperature:   &temperature,
+		StopSequences: []string{"END"},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Text != "Bonjour!" || result.Reasoning != "Translate it." || result.FinishReason != core.FinishReasonStop {
+		t.Fatalf("result = %#v", result)
+	}
+	if result.Usage == nil || result.Usage.PromptTokens != 7 || result.Usage.CompletionTokens != 2 || result.Usage.TotalTokens != 9 {
+		t.Fatalf("usage = %#v", result.Usage)
+	}
+
+	requests := backend.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("requests = %d", len(requests))
+	}
+	params := requests[0]
+	if !reflect.DeepEqual(params.SystemPrompts, []string{"You translate to French."}) {
+		t.Fatalf("system prompts = %q", params.SystemPrompts)
+	}
+	if !reflect.DeepEqual(params.Messages, []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hello!"}}) {
+		t.Fatalf("messages = %#v", params.Messages)
+	}
+	if params.Temperature == nil || *params.Temperature != 0.2 || !reflect.DeepEqual(params.StopSequences, []string{"END"}) {
+		t.Fatalf("params = %#v", params)
+	}
+}
+
+func TestChatCompletionReturnsClientToolCalls(t *testing.T) {
+	t.Parallel()
+
+	backend := core.NewMockAdapter().
+		ReplyToolCalls(core.ToolCall{Name: "get_weather", Arguments: map[string]any{"city": "Paris"}}).
+		Reply("It is sunny in Paris.")
+	client := newClient(newGateway(t, backend))
+
+	tool := core.ClientTool{Name: "get_weather", Description: "Weather by city.", Parameters: map[string]any{"type": "object"}}
+	messages := []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather in Paris?"}}
+	result, err := client.Chat(context.Background(), &core.ChatParams{Tools: []core.ToolUnion{tool}, Messages: messages})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.FinishReason != core.FinishReasonToolCalls || len(result.ToolCalls) != 1 {
+		t.Fatalf("result = %#v", result)
+	}
+	call := result.ToolCalls[0]
+	if call.ID != "call_1" || call.Name != "get_weather" || !reflect.DeepEqual(call.Arguments, map[string]any{"city": "Paris"}) {
+		t.Fatalf("tool call = %#v", call)
+	}
+
+	messages = append(messages,
+		core.ToolCallMessagePart{Role: core.RoleToolCall, ToolCalls: result.ToolCalls},
+		core.ToolResultMessagePart{Role: core.RoleToolResult, ToolCallID: call.ID, Name: call.Name, Content: "sunny"},
+	)
+	result, err = client.Chat(context.Background(), &core.ChatParams{Tools: []core.ToolUnion{tool}, Messages: messages})
+	if err != nil {
+		t.Fatalf("second Chat() error = %v", err)
+	}
+	if result.Text != "It is sunny in Paris." {
+		t.Fatalf("text = %q", result.Text)
+	}
+
+	params := backend.Requests()[1]
+	if len(params.Tools) != 1 || !reflect.DeepEqual(params.Tools[0], tool) {
+		t.Fatalf("tools = %#v", params.Tools)
+	}
+	if !reflect.DeepEqual(params.Messages, messages) {
+		t.Fatalf("messages = %#v", params.Messages)
+	}
+}
+
+func TestChatCompletionStreamsThroughOpenAIAdapter(t *testing.T) {
+	t.Parallel()
+
+	backend := core.NewMockAdapter().ReplyStream(
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkReasoning, Delta: "Greet them."}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "Hello "}},
+		core.MockChunk{StreamChunk: core.StreamChunk{Type: core.StreamChunkContent, Delta: "there"}},
+		core.MockChunk{StreamChunk: core.StreamChunk{
+			Type:         core.StreamChunkDone,
+			FinishReason: core.FinishReasonStop,
+			Usage:        &core.Usage{PromptTokens: 3, CompletionTokens: 2},
+		}},
+	)
+	client := newClient(newGateway(t, backend))
+
+	stream, err := client.ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var deltas []string
+	var reasoning string
+	var done core.StreamChunk
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkContent:
+			deltas = append(deltas, chunk.Delta)
+		case core.StreamChunkReasoning:
+			reasoning = chunk.Reasoning
+		case core.StreamChunkDone:
+			done = chunk
+		case core.StreamChunkError:
+			t.Fatalf("stream error: %s", chunk.Error)
+		}
+	}
+
+	if !reflect.DeepEqual(deltas, []string{"Hello ", "there"}) || reasoning != "Greet them." {
+		t.Fatalf("deltas = %q, reasoning = %q", deltas, reasoning)
+	}
+	if done.FinishReason != core.FinishReasonStop {
+		t.Fatalf("done = %#v", done)
+	}
+}
+
+func TestChatCompletionStreamEvents(t *testing.T) {
+	t.Parallel()
+
+	backend := core.NewMockAdapter().ReplyToolCalls(core.ToolCall{Name: "lookup", Arguments: map[string]any{"q": "go"}})
+	gateway := newGateway(t, backend)
+
+	resp := post(t, gateway.URL+"/v1/chat/completions", `{
+		"model": "proxied-model",
+		"stream": true,
+		"stream_options": {"include_usage": true},
+		"tools": [{"type": "function", "function": {"name": "lookup"}}],
+		"messages": [{"role": "user", "content": "Look it up."}]
+	}`)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, content type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			events = append(events, data)
+		}
+	}
+
+	if len(events) != 5 || events[4] != "[DONE]" {
+		t.Fatalf("events = %q", events)
+	}
+	var chunks [4]chatCompletionChunk
+	for i := range chunks {
+		if err := json.Unmarshal([]byte(events[i]), &chunks[i]); err != nil {
+			t.Fatalf("decode event %d: %v", i, err)
+		}
+		if chunks[i].Object != "chat.completion.chunk" || chunks[i].Model != "proxied-model" || chunks[i].ID != chunks[0].ID {
+			t.Fatalf("chunk %d = %#v", i, chunks[i])
+		}
+	}
+	if chunks[0].Choices[0].Delta.Role != core.RoleAssistant {
+		t.Fatalf("first chunk = %#v", chunks[0])
+	}
+	calls := chunks[1].Choices[0].Delta.ToolCalls
+	if len(calls) != 1 || *calls[0].Index != 0 || calls[0].ID != "call_1" || calls[0].Function.Name != "lookup" || calls[0].Function.Arguments != `{"q":"go"}` {
+		t.Fatalf("tool call delta = %#v", calls)
+	}
+	if reason := chunks[2].Choices[0].FinishReason; reason == nil || *reason != "tool_calls" {
+		t.Fatalf("finish chunk = %#v", chunks[2])
+	}
+	if len(chunks[3].Choices) != 0 || chunks[3].Usage == nil {
+		t.Fatalf("usage chunk = %#v", chunks[3])
+	}
+}
+
+func TestChatCompletionConvertsContentParts(t *testing.T) {
+	t.Parallel()
+
+	backend := core.NewMockAdapter().Reply("A cat.")
+	gateway := newGateway(t, backend)
+
+	resp := post(t, gateway.URL+"/v1/chat/completions", `{
+		"model": "proxied-model",
+		"n": 2,
+		"max_completion_tokens": 64,
+		"tool_choice": {"type": "function", "function": {"name": "describe"}},
+		"logit_bias": {"42": -100},
+		"response_format": {"type": "json_schema", "json_schema": {"name": "answer", "strict": true, "schema": {"type": "object"}}},
+		"messages": [
+			{"role": "developer", "content": [{"type": "text", "text": "Be brief."}]},
+			{"role": "user", "content": [
+				{"type": "text", "text": "What is this?"},
+				{"type": "image_url", "image_url": {"url": "data:image/png;base64,iVBORw0KGgo=", "detail": "low"}},
+				{"type": "image_url", "image_url": {"url": "https://example.com/cat.png"}},
+				{"type": "file", "file": {"file_id": "file-123"}}
+			]}
+		]
+	}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, error = %#v", resp.StatusCode, decodeError(t, resp))
+	}
+
+	var body chatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Choices) != 1 || body.Choices[0].Message.Content == nil || *body.Choices[0].Message.Content != "A cat." || body.Choices[0].FinishReason != "stop" {
+		t.Fatalf("response = %#v", body)
+	}
+
+	params := backend.Requests()[0]
+	want := core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+		core.TextPart{Text: "What is this?"},
+		core.ImagePart{Source: core.DataSource{Data: "iVBORw0KGgo=", MimeType: "image/png"}, Metadata: map[string]any{"detail": "low"}},
+		core.ImagePart{Source: core.URLSource{URL: "https://example.com/cat.png"}},
+		core.FilePart{FileID: "file-123"},
+	}}
+	if !reflect.DeepEqual(params.Messages, []core.MessageUnion{want}) {
+		t.Fatalf("messages = %#v", params.Messages)
+	}
+	if !reflect.DeepEqual(params.SystemPrompts, []string{"Be brief."}) || *params.CandidateCount != 2 || *params.MaxTokens != 64 {
+		t.Fatalf("params = %#v", params)
+	}
+	if params.ToolChoice == nil || params.ToolChoice.Name != "describe" || params.LogitBias[42] != -100 {
+		t.Fatalf("tool choice = %#v, logit bias = %v", params.ToolChoice, params.LogitBias)
+	}
+	if params.Output == nil || params.Output.Name != "answer" || !params.Output.Strict {
+		t.Fatalf("output = %#v", params.Output)
+	}
+}
+
+func TestEmbeddingsThroughOpenAIAdapter(t *testing.T) {
+	t.Parallel()
+
+	backend := core.NewMockAdapter().ReplyEmbeddings([]float64{0.5, -1}, []float64{0.25, 2})
+	client := newClient(newGateway(t, backend))
+
+	result, err := client.EmbedMany(context.Background(), &core.EmbedManyParams{Inputs: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("EmbedMany() error = %v", err)
+	}
+	if !reflect.DeepEqual(result.Embeddings, [][]float64{{0.5, -1}, {0.25, 2}}) {
+		t.Fatalf("embeddings = %v", result.Embeddings)
+	}
+	if !reflect.DeepEqual(backend.EmbedInputs(), []string{"a", "b"}) {
+		t.Fatalf("inputs = %q", backend.EmbedInputs())
+	}
+}
+
+func TestEmbeddingsEncodesBase64(t *testing.T) {
+	t.Parallel()
+
+	backend := core.NewMockAdapter().ReplyEmbeddings([]float64{0.5, -1})
+	gateway := newGateway(t, backend)
+
+	resp := post(t, gateway.URL+"/v1/embeddings", `{"model": "embed", "input": "a", "encoding_format": "base64"}`)
+	var body struct {
+		Data []struct {
+			Embedding string `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || len(body.Data) != 1 {
+		t.Fatalf("decode response: %v, %#v", err, body)
+	}
+	raw, err := base64.StdEncoding.DecodeString(body.Data[0].Embedding)
+	if err != nil || len(raw) != 8 {
+		t.Fatalf("embedding = %q, err = %v", body.Data[0].Embedding, err)
+	}
+	first := math.Float32frombits(binary.LittleEndian.Uint32(raw))
+	second := math.Float32frombits(binary.LittleEndian.Uint32(raw[4:]))
+	if first != 0.5 || second != -1 {
+		t.Fatalf("decoded = %v, %v", first, second)
+	}
+}
+
+func TestModelRouting(t *testing.T) {
+	t.Parallel()
+
+	fallback := core.NewMockAdapter().Reply("fallback")
+	routed := core.NewMockAdapter().Reply("routed")
+	gateway := newGateway(t, fallback, WithModel("claude-test", routed))
+
+	for model, want := range map[string]string{"claude-test": "routed", "other": "fallback"} {
+		client := openai.New(model, openai.WithAPIKey("test-key"), openai.WithBaseURL(gateway.URL+"/v1"))
+		result, err := client.Chat(context.Background(), &core.ChatParams{
+			Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		})
+		if err != nil || result.Text != want {
+			t.Fatalf("%s: result = %#v, err = %v", model, result, err)
+		}
+	}
+
+	resp, err := http.Get(gateway.URL + "/v1/models")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	var list modelList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil || len(list.Data) != 1 || list.Data[0].ID != "claude-test" {
+		t.Fatalf("models = %#v, err = %v", list, err)
+	}
+}
+
+func TestErrorsUseOpenAIFormat(t *testing.T) {
+	t.Parallel()
+
+	backend := core.NewMockAdapter().ReplyError(&core.RateLimitError{Message: "slow down", RetryAfter: 1500 * time.Millisecond, RequestID: "req_1"})
+	gateway := newGateway(t, backend, WithAPIKeys("secret"))
+	auth := []string{"Authorization", "Bearer secret"}
+	request := `{"model": "m", "messages": [{"role": "user", "content": "Hi"}]}`
+
+	resp := post(t, gateway.URL+"/v1/chat/completions", request)
+	if got := decodeError(t, resp); resp.StatusCode != http.StatusUnauthorized || got.Code == nil || *got.Code != "invalid_api_key" {
+		t.Fatalf("unauthenticated: status = %d, error = %#v", resp.StatusCode, got)
+	}
+
+	resp = post(t, gateway.URL+"/v1/chat/completions", request, auth...)
+	if got := decodeError(t, resp); resp.StatusCode != http.StatusTooManyRequests || got.Type != "rate_limit_error" || got.Message != "The upstream provider rate limited the request." {
+		t.Fatalf("rate limited: status = %d, error = %#v", resp.StatusCode, got)
+	}
+	if resp.Header.Get("Retry-After") != "2" {
+		t.Fatalf("Retry-After = %q", resp.Header.Get("Retry-After"))
+	}
+	if resp.Header.Get("X-Request-Id") != "req_1" {
+		t.Fatalf("X-Request-Id = %q", resp.Header.Get("X-Request-Id"))
+	}
+
+	resp = post(t, gateway.URL+"/v1/chat/completions", `{"messages": [{"role": "wizard", "content": "Hi"}]}`, auth...)
+	if got := decodeError(t, resp); resp.StatusCode != http.StatusBadRequest || !strings.Contains(got.Message, `unsupported role "wizard"`) {
+		t.Fatalf("bad role: status = %d, error = %#v", resp.StatusCode, got)
+	}
+
+	resp = post(t, gateway.URL+"/v1/embeddings", `{"input": [1, 2, 3]}`, auth...)
+	if got := decodeError(t, resp); resp.StatusCode != http.StatusBadRequest || got.Type != "invalid_request_error" {
+		t.Fatalf("token input: status = %d, error = %#v", resp.StatusCode, got)
+	}
+
+	resp = post(t, gateway.URL+"/v1/completions", `{}`, auth...)
+	if got := decodeError(t, resp); resp.StatusCode != http.StatusNotFound || got.Code == nil || *got.Code != "unknown_url" {
+		t.Fatalf("unknown url: status = %d, error = %#v", resp.StatusCode, got)
+	}
+}
+
+func TestUpstreamErrorsAreHidden(t *testing.T) {
+	t.Parallel()
+
+	unauthorized := &core.APIError{StatusCode: http.StatusUnauthorized, Message: "openai: API error: Incorrect API key provided: sk-proj-1234"}
+	badRequest := &core.APIError{StatusCode: http.StatusBadRequest, Message: "openai: API error: Invalid value for temperature"}
+	request := `{"model": "m", "messages": [{"role": "user", "content": "Hi"}]}`
+
+	gateway := newGateway(t, core.NewMockAdapter().ReplyError(unauthorized).ReplyError(badRequest))
+	resp := post(t, gateway.URL+"/v1/chat/completions", request)
+	if got := decodeError(t, resp); resp.StatusCode != http.StatusBadGateway || got.Type != "server_error" || strings.Contains(got.Message, "sk-proj") {
+		t.Fatalf("upstream 401: status = %d, error = %#v", resp.StatusCode, got)
+	}
+	resp = post(t, gateway.URL+"/v1/chat/completions", request)
+	if got := decodeError(t, resp); resp.StatusCode != http.StatusBadRequest || got.Message != "The upstream provider rejected the request." {
+		t.Fatalf("upstream 400: status = %d, error = %#v", resp.StatusCode, got)
+	}
+
+	exposed := newGateway(t, core.NewMockAdapter().ReplyError(badRequest), WithUpstreamErrors())
+	resp = post(t, exposed.URL+"/v1/chat/completions", request)
+	if got := decodeError(t, resp); resp.StatusCode != http.StatusBadRequest || got.Message != badRequest.Error() {
+		t.Fatalf("exposed upstream 400: status = %d, error = %#v", resp.StatusCode, got)
+	}
+}
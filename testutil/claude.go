@@ -0,0 +1,139 @@
+package testutil
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// NewClaudeServer starts a server for the Anthropic Messages API that answers
+// with replies in order. It is closed when the test ends. Use its URL as the
+// base URL of the claude adapter.
+func NewClaudeServer(tb testing.TB, replies ...Reply) *Server {
+	tb.Helper()
+	return newServer(tb, claudeProvider{}, replies)
+}
+
+type claudeProvider struct{}
+
+func (claudeProvider) name() string {
+	return "claude"
+}
+
+func (claudeProvider) route(path string) (endpoint, bool) {
+	if strings.TrimPrefix(path, "/v1") == "/messages" {
+		return claudeMessages, true
+	}
+	return nil, false
+}
+
+func (claudeProvider) writeError(w http.ResponseWriter, status int, message string) {
+	errorType := "invalid_request_error"
+	switch {
+	case status == http.StatusUnauthorized:
+		errorType = "authentication_error"
+	case status == http.StatusNotFound:
+		errorType = "not_found_error"
+	case status == http.StatusTooManyRequests:
+		errorType = "rate_limit_error"
+	case status == 529:
+		errorType = "overloaded_error"
+	case status >= http.StatusInternalServerError:
+		errorType = "api_error"
+	}
+	writeJSON(w, status, map[string]any{
+		"type":  "error",
+		"error": map[string]any{"type": errorType, "message": message},
+	})
+}
+
+func claudeMessages(w http.ResponseWriter, body map[string]any, reply Reply, n int) {
+	id := fmt.Sprintf("msg_test_%d", n)
+	stopReason := claudeStopReason(reply.finishReason())
+	calls := reply.toolCalls(n)
+
+	if stream, _ := body["stream"].(bool); !stream {
+		content := make([]map[string]any, 0, 2+len(calls))
+		if reply.Reasoning != "" {
+			content = append(content, map[string]any{"type": "thinking", "thinking": reply.Reasoning, "signature": "test-signature"})
+		}
+		if reply.Text != "" {
+			content = append(content, map[string]any{"type": "text", "text": reply.Text})
+		}
+		for _, call := range calls {
+			content = append(content, map[string]any{"type": "tool_use", "id": call.ID, "name": call.Name, "input": arguments(call.Arguments)})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id":            id,
+			"type":          "message",
+			"role":          "assistant",
+			"model":         model(body),
+			"content":       content,
+			"stop_reason":   stopReason,
+			"stop_sequence": nil,
+			"usage":         map[string]any{"input_tokens": reply.PromptTokens, "output_tokens": reply.CompletionTokens},
+		})
+		return
+	}
+
+	stream := newStreamWriter(w, "text/event-stream", reply.Delay)
+	stream.event("message_start", map[string]any{
+		"type": "message_start",
+		"message": map[string]any{
+			"id": id, "type": "message", "role": "assistant", "model": model(body), "content": []any{},
+			"stop_reason": nil, "stop_sequence": nil,
+			"usage": map[string]any{"input_tokens": reply.PromptTokens, "output_tokens": 1},
+		},
+	})
+
+	index := 0
+	block := func(start map[string]any, deltas ...map[string]any) {
+		stream.event("content_block_start", map[string]any{"type": "content_block_start", "index": index, "content_block": start})
+		for _, delta := range deltas {
+			stream.event("content_block_delta", map[string]any{"type": "content_block_delta", "index": index, "delta": delta})
+		}
+		stream.event("content_block_stop", map[string]any{"type": "content_block_stop", "index": index})
+		index++
+	}
+
+	if reply.Reasoning != "" {
+		block(map[string]any{"type": "thinking", "thinking": ""},
+			map[string]any{"type": "thinking_delta", "thinking": reply.Reasoning},
+			map[string]any{"type": "signature_delta", "signature": "test-signature"})
+	}
+	if deltas := reply.deltas(); len(deltas) > 0 {
+		textDeltas := make([]map[string]any, len(deltas))
+		for i, delta := range deltas {
+			textDeltas[i] = map[string]any{"type": "text_delta", "text": delta}
+		}
+		block(map[string]any{"type": "text", "text": ""}, textDeltas...)
+	}
+	for _, call := range calls {
+		block(map[string]any{"type": "tool_use", "id": call.ID, "name": call.Name, "input": map[string]any{}},
+			map[string]any{"type": "input_json_delta", "partial_json": argumentsJSON(call.Arguments)})
+	}
+
+	stream.event("message_delta", map[string]any{
+		"type":  "message_delta",
+		"delta": map[string]any{"stop_reason": stopReason, "stop_sequence": nil},
+		"usage": map[string]any{"output_tokens": reply.CompletionTokens},
+	})
+	stream.event("message_stop", map[string]any{"type": "message_stop"})
+}
+
+func claudeStopReason(reason core.FinishReason) string {
+	switch reason {
+	case core.FinishReasonStop:
+		return "end_turn"
+	case core.FinishReasonLength:
+		return "max_tokens"
+	case core.FinishReasonToolCalls:
+		return "tool_use"
+	case core.FinishReasonContentFilter:
+		return "refusal"
+	}
+	return string(reason)
+}
@@ -0,0 +1,112 @@
+package testutil
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// NewOllamaServer starts a server for the Ollama chat and embed APIs that
+// answers with replies in order. It is closed when the test ends. Use its URL
+// as the base URL of the ollama adapter.
+func NewOllamaServer(tb testing.TB, replies ...Reply) *Server {
+	tb.Helper()
+	return newServer(tb, ollamaProvider{}, replies)
+}
+
+type ollamaProvider struct{}
+
+func (ollamaProvider) name() string {
+	return "ollama"
+}
+
+func (p ollamaProvider) route(path string) (endpoint, bool) {
+	switch path {
+	case "/api/chat":
+		return ollamaChat, true
+	case "/api/embed":
+		return p.embed, true
+	}
+	return nil, false
+}
+
+func (ollamaProvider) writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"error": message})
+}
+
+func (p ollamaProvider) embed(w http.ResponseWriter, body map[string]any, reply Reply, n int) {
+	if len(reply.Embeddings) == 0 {
+		p.writeError(w, http.StatusInternalServerError, fmt.Sprintf("testutil: no embeddings scripted for request %d", n))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"model":             model(body),
+		"embeddings":        reply.Embeddings,
+		"prompt_eval_count": reply.PromptTokens,
+	})
+}
+
+func ollamaChat(w http.ResponseWriter, body map[string]any, reply Reply, n int) {
+	createdAt := time.Now().UTC().Format(time.RFC3339Nano)
+	calls := reply.toolCalls(n)
+	toolCalls := make([]map[string]any, len(calls))
+	for i, call := range calls {
+		toolCalls[i] = map[string]any{
+			"id":       call.ID,
+			"function": map[string]any{"index": i, "name": call.Name, "arguments": arguments(call.Arguments)},
+		}
+	}
+	done := func(message map[string]any) map[string]any {
+		return map[string]any{
+			"model":             model(body),
+			"created_at":        createdAt,
+			"message":           message,
+			"done":              true,
+			"done_reason":       ollamaDoneReason(reply.finishReason()),
+			"total_duration":    int64(time.Millisecond),
+			"prompt_eval_count": reply.PromptTokens,
+			"eval_count":        reply.CompletionTokens,
+		}
+	}
+
+	// Ollama streams unless the request turns it off.
+	if stream, ok := body["stream"].(bool); ok && !stream {
+		message := map[string]any{"role": "assistant", "content": reply.Text}
+		if reply.Reasoning != "" {
+			message["thinking"] = reply.Reasoning
+		}
+		if len(toolCalls) > 0 {
+			message["tool_calls"] = toolCalls
+		}
+		writeJSON(w, http.StatusOK, done(message))
+		return
+	}
+
+	partial := func(message map[string]any) map[string]any {
+		message["role"] = "assistant"
+		return map[string]any{"model": model(body), "created_at": createdAt, "message": message, "done": false}
+	}
+
+	stream := newStreamWriter(w, "application/x-ndjson", reply.Delay)
+	if reply.Reasoning != "" {
+		stream.line(partial(map[string]any{"content": "", "thinking": reply.Reasoning}))
+	}
+	for _, delta := range reply.deltas() {
+		stream.line(partial(map[string]any{"content": delta}))
+	}
+	if len(toolCalls) > 0 {
+		stream.line(partial(map[string]any{"content": "", "tool_calls": toolCalls}))
+	}
+	stream.line(done(map[string]any{"role": "assistant", "content": ""}))
+}
+
+func ollamaDoneReason(reason core.FinishReason) string {
+	switch reason {
+	case core.FinishReasonToolCalls, core.FinishReasonStopSequence:
+		return "stop"
+	}
+	return string(reason)
+}
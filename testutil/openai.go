@@ -0,0 +1,160 @@
+package testutil
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// NewOpenAIServer starts a server for the OpenAI Chat Completions and
+// Embeddings APIs that answers with replies in order. It is closed when the
+// test ends. Use its URL as the base URL of the openai adapter.
+func NewOpenAIServer(tb testing.TB, replies ...Reply) *Server {
+	tb.Helper()
+	return newServer(tb, openAIProvider{}, replies)
+}
+
+type openAIProvider struct{}
+
+func (openAIProvider) name() string {
+	return "openai"
+}
+
+func (p openAIProvider) route(path string) (endpoint, bool) {
+	switch strings.TrimPrefix(path, "/v1") {
+	case "/chat/completions":
+		return openAIChat, true
+	case "/embeddings":
+		return p.embeddings, true
+	}
+	return nil, false
+}
+
+func (openAIProvider) writeError(w http.ResponseWriter, status int, message string) {
+	errorType := "invalid_request_error"
+	switch {
+	case status == http.StatusUnauthorized:
+		errorType = "authentication_error"
+	case status == http.StatusTooManyRequests:
+		errorType = "rate_limit_exceeded"
+	case status >= http.StatusInternalServerError:
+		errorType = "server_error"
+	}
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{"message": message, "type": errorType, "param": nil, "code": nil},
+	})
+}
+
+func (p openAIProvider) embeddings(w http.ResponseWriter, body map[string]any, reply Reply, n int) {
+	if len(reply.Embeddings) == 0 {
+		p.writeError(w, http.StatusInternalServerError, fmt.Sprintf("testutil: no embeddings scripted for request %d", n))
+		return
+	}
+
+	data := make([]map[string]any, len(reply.Embeddings))
+	for i, embedding := range reply.Embeddings {
+		data[i] = map[string]any{"object": "embedding", "index": i, "embedding": embedding}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"object": "list",
+		"data":   data,
+		"model":  model(body),
+		"usage":  map[string]any{"prompt_tokens": reply.PromptTokens, "total_tokens": reply.PromptTokens},
+	})
+}
+
+func openAIChat(w http.ResponseWriter, body map[string]any, reply Reply, n int) {
+	id := fmt.Sprintf("chatcmpl-test-%d", n)
+	created := time.Now().Unix()
+	finishReason := openAIFinishReason(reply.finishReason())
+	calls := reply.toolCalls(n)
+
+	if stream, _ := body["stream"].(bool); !stream {
+		message := map[string]any{"role": "assistant", "content": nil}
+		if reply.Text != "" {
+			message["content"] = reply.Text
+		}
+		if reply.Reasoning != "" {
+			message["reasoning_content"] = reply.Reasoning
+		}
+		if len(calls) > 0 {
+			message["tool_calls"] = openAIToolCalls(calls, false)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id":      id,
+			"object":  "chat.completion",
+			"created": created,
+			"model":   model(body),
+			"choices": []map[string]any{{"index": 0, "message": message, "finish_reason": finishReason}},
+			"usage":   openAIUsage(reply),
+		})
+		return
+	}
+
+	chunk := func(delta map[string]any, finishReason any) map[string]any {
+		return map[string]any{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   model(body),
+			"choices": []map[string]any{{"index": 0, "delta": delta, "finish_reason": finishReason}},
+		}
+	}
+
+	stream := newStreamWriter(w, "text/event-stream", reply.Delay)
+	stream.event("", chunk(map[string]any{"role": "assistant", "content": ""}, nil))
+	if reply.Reasoning != "" {
+		stream.event("", chunk(map[string]any{"reasoning_content": reply.Reasoning}, nil))
+	}
+	for _, delta := range reply.deltas() {
+		stream.event("", chunk(map[string]any{"content": delta}, nil))
+	}
+	if len(calls) > 0 {
+		stream.event("", chunk(map[string]any{"tool_calls": openAIToolCalls(calls, true)}, nil))
+	}
+	stream.event("", chunk(map[string]any{}, finishReason))
+	stream.event("", map[string]any{
+		"id":      id,
+		"object":  "chat.completion.chunk",
+		"created": created,
+		"model":   model(body),
+		"choices": []any{},
+		"usage":   openAIUsage(reply),
+	})
+	stream.raw("data: [DONE]\n\n")
+}
+
+func openAIToolCalls(calls []core.ToolCall, indexed bool) []map[string]any {
+	out := make([]map[string]any, len(calls))
+	for i, call := range calls {
+		out[i] = map[string]any{
+			"id":       call.ID,
+			"type":     "function",
+			"function": map[string]any{"name": call.Name, "arguments": argumentsJSON(call.Arguments)},
+		}
+		if indexed {
+			out[i]["index"] = i
+		}
+	}
+	return out
+}
+
+func openAIUsage(reply Reply) map[string]any {
+	return map[string]any{
+		"prompt_tokens":     reply.PromptTokens,
+		"completion_tokens": reply.CompletionTokens,
+		"total_tokens":      reply.PromptTokens + reply.CompletionTokens,
+	}
+}
+
+func openAIFinishReason(reason core.FinishReason) string {
+	switch reason {
+	case core.FinishReasonStopSequence:
+		return "stop"
+	}
+	return string(reason)
+}
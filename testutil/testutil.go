@@ -0,0 +1,296 @@
+// Package testutil runs HTTP test servers that speak the OpenAI, Claude, and
+// Ollama wire formats, so applications can test the code that drives the
+// adapters without a provider or an API key.
+//
+// Each server answers requests with scripted replies in order, streaming them
+// as server-sent events or newline-delimited JSON when the request asks for a
+// stream, and records the requests it received:
+//
+//	server := testutil.NewOpenAIServer(t, testutil.Reply{Text: "Hello!"})
+//	adapter := openai.New("gpt-4o-mini", openai.WithBaseURL(server.URL), openai.WithAPIKey("test"))
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// Reply is one scripted response of a Server.
+type Reply struct {
+	// Text is the assistant message.
+	Text string
+
+	// Reasoning is the model's reasoning, sent before Text.
+	Reasoning string
+
+	// ToolCalls are the tools the model calls. Calls without an ID get one.
+	ToolCalls []core.ToolCall
+
+	// Deltas is Text split into the pieces a stream sends. Empty streams
+	// Text word by word.
+	Deltas []string
+
+	// FinishReason is mapped to the provider's value. Empty means
+	// FinishReasonToolCalls with tool calls and FinishReasonStop otherwise.
+	FinishReason core.FinishReason
+
+	// PromptTokens and CompletionTokens are reported as usage.
+	PromptTokens     int64
+	CompletionTokens int64
+
+	// Embeddings answers an embeddings request, one vector per input. An
+	// embeddings request fails when it is empty.
+	Embeddings [][]float64
+
+	// Status other than zero sends an error response with Error as its
+	// message, in the provider's error format.
+	Status int
+	Error  string
+
+	// Header is added to the response headers.
+	Header http.Header
+
+	// Delay is waited before responding and after each event of a stream.
+	Delay time.Duration
+}
+
+// Request is a request a Server received.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// Decode decodes the JSON body of r into v.
+func (r Request) Decode(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Server is a test server for one provider's API. Point an adapter at it with
+// the adapter's WithBaseURL option and Server.URL.
+type Server struct {
+	*httptest.Server
+
+	provider provider
+
+	mu       sync.Mutex
+	replies  []Reply
+	requests []Request
+}
+
+// provider writes the responses of one provider's API.
+type provider interface {
+	name() string
+
+	// route returns how the request at path is answered, or false when the
+	// API has no such endpoint.
+	route(path string) (endpoint, bool)
+
+	// writeError sends an error response in the provider's format.
+	writeError(w http.ResponseWriter, status int, message string)
+}
+
+// endpoint answers one request with reply. n counts the requests the server
+// received, starting at 1, for response IDs.
+type endpoint func(w http.ResponseWriter, body map[string]any, reply Reply, n int)
+
+func newServer(tb testing.TB, p provider, replies []Reply) *Server {
+	tb.Helper()
+
+	s := &Server{provider: p, replies: append([]Reply(nil), replies...)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Reply appends replies to the script.
+func (s *Server) Reply(replies ...Reply) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies = append(s.replies, replies...)
+	return s
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Remaining returns how many scripted replies have not been used.
+func (s *Server) Remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.replies)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.provider.writeError(w, http.StatusBadRequest, fmt.Sprintf("testutil: read request: %v", err))
+		return
+	}
+
+	handle, ok := s.provider.route(r.URL.Path)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: data})
+	n := len(s.requests)
+	reply, scripted := Reply{}, ok && len(s.replies) > 0
+	if scripted {
+		reply = s.replies[0]
+		s.replies = s.replies[1:]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		s.provider.writeError(w, http.StatusNotFound, fmt.Sprintf("testutil: %s has no endpoint %s", s.provider.name(), r.URL.Path))
+		return
+	}
+	if !scripted {
+		s.provider.writeError(w, http.StatusInternalServerError, fmt.Sprintf("testutil: no reply scripted for request %d", n))
+		return
+	}
+
+	var body map[string]any
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &body); err != nil {
+			s.provider.writeError(w, http.StatusBadRequest, fmt.Sprintf("testutil: decode request: %v", err))
+			return
+		}
+	}
+
+	for key, values := range reply.Header {
+		w.Header()[http.CanonicalHeaderKey(key)] = values
+	}
+	if reply.Delay > 0 {
+		time.Sleep(reply.Delay)
+	}
+	if reply.Status != 0 {
+		s.provider.writeError(w, reply.Status, reply.Error)
+		return
+	}
+	handle(w, body, reply, n)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// streamWriter sends the events of a streamed response, flushing each one.
+type streamWriter struct {
+	w     http.ResponseWriter
+	delay time.Duration
+}
+
+func newStreamWriter(w http.ResponseWriter, contentType string, delay time.Duration) *streamWriter {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	return &streamWriter{w: w, delay: delay}
+}
+
+// event sends a server-sent event with v as its JSON data. An empty name
+// sends a data-only event.
+func (s *streamWriter) event(name string, v any) {
+	data, _ := json.Marshal(v)
+	if name != "" {
+		fmt.Fprintf(s.w, "event: %s\n", name)
+	}
+	fmt.Fprintf(s.w, "data: %s\n\n", data)
+	s.flush()
+}
+
+// raw sends text as is.
+func (s *streamWriter) raw(text string) {
+	io.WriteString(s.w, text)
+	s.flush()
+}
+
+// line sends v as one line of newline-delimited JSON.
+func (s *streamWriter) line(v any) {
+	_ = json.NewEncoder(s.w).Encode(v)
+	s.flush()
+}
+
+func (s *streamWriter) flush() {
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	if s.delay > 0 {
+		time.Sleep(s.delay)
+	}
+}
+
+// deltas returns the pieces reply.Text is streamed in.
+func (r Reply) deltas() []string {
+	if len(r.Deltas) > 0 {
+		return r.Deltas
+	}
+	if r.Text == "" {
+		return nil
+	}
+	return strings.SplitAfter(r.Text, " ")
+}
+
+// toolCalls returns the tool calls with an ID each.
+func (r Reply) toolCalls(n int) []core.ToolCall {
+	calls := make([]core.ToolCall, len(r.ToolCalls))
+	for i, call := range r.ToolCalls {
+		if strings.TrimSpace(call.ID) == "" {
+			call.ID = fmt.Sprintf("call_%d_%d", n, i+1)
+		}
+		if call.Arguments == nil {
+			call.Arguments = map[string]any{}
+		}
+		calls[i] = call
+	}
+	return calls
+}
+
+func (r Reply) finishReason() core.FinishReason {
+	if r.FinishReason != "" {
+		return r.FinishReason
+	}
+	if len(r.ToolCalls) > 0 {
+		return core.FinishReasonToolCalls
+	}
+	return core.FinishReasonStop
+}
+
+// arguments returns the tool call arguments as a JSON object, decoding them
+// when they are given as a JSON string.
+func arguments(value any) any {
+	if text, ok := value.(string); ok {
+		var decoded any
+		if json.Unmarshal([]byte(text), &decoded) == nil {
+			return decoded
+		}
+	}
+	return value
+}
+
+// argumentsJSON returns the tool call arguments encoded as a JSON string.
+func argumentsJSON(value any) string {
+	data, _ := json.Marshal(arguments(value))
+	return string(data)
+}
+
+// model returns the model named in the request body.
+func model(body map[string]any) string {
+	name, _ := body["model"].(string)
+	return name
+}
@@ -0,0 +1,187 @@
+package testutil
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/claude"
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/ollama"
+	"github.com/m43i/go-ai/openai"
+)
+
+type providerCase struct {
+	name      string
+	newServer func(testing.TB, ...Reply) *Server
+	adapter   func(url string) core.TextAdapter
+}
+
+var providerCases = []providerCase{
+	{"openai", NewOpenAIServer, func(url string) core.TextAdapter {
+		return openai.New("gpt-test", openai.WithAPIKey("test-key"), openai.WithBaseURL(url))
+	}},
+	{"claude", NewClaudeServer, func(url string) core.TextAdapter {
+		return claude.New("claude-test", claude.WithAPIKey("test-key"), claude.WithBaseURL(url))
+	}},
+	{"ollama", NewOllamaServer, func(url string) core.TextAdapter {
+		return ollama.New("llama-test", ollama.WithBaseURL(url))
+	}},
+}
+
+func chatParams(tools ...core.ToolUnion) *core.ChatParams {
+	return &core.ChatParams{
+		Tools:    tools,
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "hello"}},
+	}
+}
+
+func TestChatReturnsScriptedReply(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range providerCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := tc.newServer(t, Reply{Text: "Hello there!", PromptTokens: 5, CompletionTokens: 3})
+			result, err := tc.adapter(server.URL).Chat(context.Background(), chatParams())
+			if err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+			if result.Text != "Hello there!" || result.FinishReason != core.FinishReasonStop {
+				t.Fatalf("result = %q, %q", result.Text, result.FinishReason)
+			}
+			if result.Usage == nil || result.Usage.PromptTokens != 5 || result.Usage.CompletionTokens != 3 {
+				t.Fatalf("usage = %#v", result.Usage)
+			}
+
+			requests := server.Requests()
+			var body struct {
+				Model string `json:"model"`
+			}
+			if len(requests) != 1 || requests[0].Method != http.MethodPost || requests[0].Decode(&body) != nil || !strings.HasSuffix(body.Model, "-test") {
+				t.Fatalf("requests = %+v", requests)
+			}
+		})
+	}
+}
+
+func TestChatStreamSendsDeltas(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range providerCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := tc.newServer(t, Reply{Text: "Hello there, friend", Reasoning: "Greet them.", CompletionTokens: 4})
+			stream, err := tc.adapter(server.URL).ChatStream(context.Background(), chatParams())
+			if err != nil {
+				t.Fatalf("ChatStream() error = %v", err)
+			}
+
+			var deltas []string
+			var reasoning string
+			var done core.StreamChunk
+			for chunk := range stream {
+				switch chunk.Type {
+				case core.StreamChunkContent:
+					deltas = append(deltas, chunk.Delta)
+				case core.StreamChunkReasoning:
+					reasoning = chunk.Reasoning
+				case core.StreamChunkDone:
+					done = chunk
+				case core.StreamChunkError:
+					t.Fatalf("stream error: %s", chunk.Error)
+				}
+			}
+
+			if !reflect.DeepEqual(deltas, []string{"Hello ", "there, ", "friend"}) || reasoning != "Greet them." {
+				t.Fatalf("deltas = %q, reasoning = %q", deltas, reasoning)
+			}
+			if done.FinishReason != core.FinishReasonStop || done.Usage == nil || done.Usage.CompletionTokens != 4 {
+				t.Fatalf("done = %#v", done)
+			}
+		})
+	}
+}
+
+func TestChatReturnsToolCalls(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range providerCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := tc.newServer(t, Reply{ToolCalls: []core.ToolCall{{Name: "get_weather", Arguments: map[string]any{"city": "Paris"}}}})
+			tool := core.ClientTool{Name: "get_weather", Parameters: map[string]any{"type": "object"}}
+			result, err := tc.adapter(server.URL).Chat(context.Background(), chatParams(tool))
+			if err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+			if result.FinishReason != core.FinishReasonToolCalls || len(result.ToolCalls) != 1 {
+				t.Fatalf("result = %#v", result)
+			}
+			call := result.ToolCalls[0]
+			if call.ID != "call_1_1" || call.Name != "get_weather" || !reflect.DeepEqual(call.Arguments, map[string]any{"city": "Paris"}) {
+				t.Fatalf("tool call = %#v", call)
+			}
+		})
+	}
+}
+
+func TestErrorsUseProviderFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range providerCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := tc.newServer(t, Reply{Status: http.StatusBadRequest, Error: "model is required"})
+			_, err := tc.adapter(server.URL).Chat(context.Background(), chatParams())
+			if err == nil || !strings.Contains(err.Error(), "model is required") {
+				t.Fatalf("Chat() error = %v", err)
+			}
+
+			_, err = tc.adapter(server.URL).Chat(context.Background(), chatParams())
+			if err == nil || !strings.Contains(err.Error(), "no reply scripted for request 2") {
+				t.Fatalf("unscripted Chat() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestEmbeddings(t *testing.T) {
+	t.Parallel()
+
+	reply := Reply{Embeddings: [][]float64{{0.1, 0.2}, {0.3, 0.4}}, PromptTokens: 4}
+	adapters := map[string]core.EmbeddingAdapter{
+		"openai": openai.New("text-embedding-test", openai.WithAPIKey("test-key"), openai.WithBaseURL(NewOpenAIServer(t, reply).URL)),
+		"ollama": ollama.New("embed-test", ollama.WithBaseURL(NewOllamaServer(t, reply).URL)),
+	}
+
+	for name, adapter := range adapters {
+		result, err := adapter.EmbedMany(context.Background(), &core.EmbedManyParams{Inputs: []string{"a", "b"}})
+		if err != nil {
+			t.Fatalf("%s EmbedMany() error = %v", name, err)
+		}
+		if !reflect.DeepEqual(result.Embeddings, reply.Embeddings) {
+			t.Fatalf("%s embeddings = %v", name, result.Embeddings)
+		}
+	}
+}
+
+func TestUnknownEndpointDoesNotUseReply(t *testing.T) {
+	t.Parallel()
+
+	server := NewOpenAIServer(t, Reply{Text: "kept"})
+	resp, err := http.Post(server.URL+"/v1/responses", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || server.Remaining() != 1 || len(server.Requests()) != 1 {
+		t.Fatalf("status = %d, remaining = %d", resp.StatusCode, server.Remaining())
+	}
+}
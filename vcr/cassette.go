@@ -0,0 +1,147 @@
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// cassetteVersion is written to every fixture so the format can change.
+const cassetteVersion = 1
+
+// cassette is the content of a fixture file.
+type cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. Body holds the decompressed request body.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response. A streamed body, server-sent events or
+// newline-delimited JSON, is kept in Events, one event or line per entry;
+// any other body is kept in Body.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	Events     []string    `json:"events,omitempty"`
+}
+
+func newResponse(status int, header http.Header, body []byte) Response {
+	resp := Response{StatusCode: status, Header: header}
+
+	text := string(body)
+	switch mediaType := strings.ToLower(header.Get("Content-Type")); {
+	case strings.HasPrefix(mediaType, "text/event-stream"):
+		resp.Events = strings.SplitAfter(text, "\n\n")
+	case strings.HasPrefix(mediaType, "application/x-ndjson"):
+		resp.Events = strings.SplitAfter(text, "\n")
+	default:
+		resp.Body = text
+	}
+	// SplitAfter leaves an empty entry after the final separator.
+	if n := len(resp.Events); n > 0 && resp.Events[n-1] == "" {
+		resp.Events = resp.Events[:n-1]
+	}
+	return resp
+}
+
+// body returns the response body as it was received.
+func (r Response) body() string {
+	if len(r.Events) > 0 {
+		return strings.Join(r.Events, "")
+	}
+	return r.Body
+}
+
+func (r Response) toHTTP(req *http.Request) *http.Response {
+	body := r.body()
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func loadCassette(path string) (cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cassette{}, fmt.Errorf("vcr: read fixture: %w", err)
+	}
+
+	var loaded cassette
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return cassette{}, fmt.Errorf("vcr: decode fixture %s: %w", path, err)
+	}
+	if loaded.Version != cassetteVersion {
+		return cassette{}, fmt.Errorf("vcr: fixture %s has unsupported version %d", path, loaded.Version)
+	}
+	return loaded, nil
+}
+
+func (c cassette) save(path string) error {
+	c.Version = cassetteVersion
+	if c.Interactions == nil {
+		c.Interactions = []Interaction{}
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("vcr: encode fixture: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("vcr: write fixture: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("vcr: write fixture: %w", err)
+	}
+	return nil
+}
+
+// matchRequest is the default Matcher. It compares the method, the URL, and
+// the body, comparing JSON bodies by value so key order does not matter.
+func matchRequest(req *http.Request, body []byte, recorded Request) bool {
+	if req.Method != recorded.Method || req.URL.String() != recorded.URL {
+		return false
+	}
+	if bytes.Equal(body, []byte(recorded.Body)) {
+		return true
+	}
+
+	var got, want any
+	if json.Unmarshal(body, &got) != nil || json.Unmarshal([]byte(recorded.Body), &want) != nil {
+		return false
+	}
+	return reflect.DeepEqual(got, want)
+}
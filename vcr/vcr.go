@@ -0,0 +1,379 @@
+// Package vcr records the HTTP interactions of provider adapters to fixture
+// files and replays them, so tests that exercise a real provider's wire
+// format run deterministically and without API keys.
+//
+// A Recorder is an http.RoundTripper. Pass its client to an adapter with the
+// adapter's WithHTTPClient option:
+//
+//	recorder := vcr.Start(t, "testdata/chat.json")
+//	adapter := openai.New("gpt-4o-mini", openai.WithHTTPClient(recorder.Client()))
+//
+// Record the fixture once against the real API by running the test with
+// GOAI_VCR_MODE=record and a valid key; later runs replay it. Streamed
+// responses are recorded too, so ChatStream replays the same chunks.
+package vcr
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// EnvMode selects the mode of recorders created without WithMode: "record",
+// "replay", or "auto".
+const EnvMode = "GOAI_VCR_MODE"
+
+// Mode selects whether a Recorder sends requests or replays a fixture.
+type Mode int
+
+const (
+	// ModeReplay answers requests from the fixture and never sends them.
+	// Requests without a matching interaction fail.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends every request and overwrites the fixture with the
+	// interactions when the recorder stops.
+	ModeRecord
+
+	// ModeAuto replays the fixture when it exists and records it otherwise.
+	ModeAuto
+)
+
+// redactedValue replaces the values of redacted headers and query
+// parameters in fixtures.
+const redactedValue = "REDACTED"
+
+// defaultRedactedHeaders hold the credentials the adapters send.
+var defaultRedactedHeaders = []string{"Authorization", "X-Api-Key", "Api-Key", "Cookie", "Set-Cookie"}
+
+// defaultRedactedParams hold the credentials sent in URLs, such as the
+// "key" parameter of the Gemini API.
+var defaultRedactedParams = []string{"key", "api_key", "apikey", "access_token"}
+
+// Matcher reports whether a recorded interaction answers req, whose body has
+// been read into body.
+type Matcher func(req *http.Request, body []byte, recorded Request) bool
+
+// Recorder records or replays the requests sent through it.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	matcher   Matcher
+	redact    map[string]struct{}
+	params    map[string]struct{}
+
+	mu       sync.Mutex
+	cassette cassette
+	used     []bool
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithMode sets the mode instead of reading it from GOAI_VCR_MODE.
+func WithMode(mode Mode) Option {
+	return func(r *Recorder) {
+		r.mode = mode
+	}
+}
+
+// WithTransport sets the transport that sends requests while recording.
+// Defaults to http.DefaultTransport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithMatcher replaces how requests are matched to recorded interactions.
+// The default matches the method, the URL, and the body, comparing JSON
+// bodies by value. Matchers see both URLs without redacted query
+// parameters.
+func WithMatcher(matcher Matcher) Option {
+	return func(r *Recorder) {
+		r.matcher = matcher
+	}
+}
+
+// WithRedactedParams adds query parameters whose values are replaced in the
+// fixture and that are ignored when matching requests. The key, api_key,
+// apikey, and access_token parameters are always redacted.
+func WithRedactedParams(names ...string) Option {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.params[strings.ToLower(name)] = struct{}{}
+		}
+	}
+}
+
+// WithRedactedHeaders adds headers whose values are replaced in the fixture.
+// Authorization, X-Api-Key, Api-Key, and cookies are always redacted.
+func WithRedactedHeaders(names ...string) Option {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.redact[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	}
+}
+
+// New returns a recorder for the fixture at path. In replay mode, and in
+// auto mode when the file exists, the fixture is loaded now.
+func New(path string, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		transport: http.DefaultTransport,
+		matcher:   matchRequest,
+		redact:    make(map[string]struct{}, len(defaultRedactedHeaders)),
+		params:    make(map[string]struct{}, len(defaultRedactedParams)),
+	}
+	for _, name := range defaultRedactedHeaders {
+		r.redact[name] = struct{}{}
+	}
+	for _, name := range defaultRedactedParams {
+		r.params[name] = struct{}{}
+	}
+
+	mode, err := modeFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	r.mode = mode
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.mode == ModeAuto {
+		r.mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			r.mode = ModeReplay
+		}
+	}
+
+	if r.mode == ModeReplay {
+		loaded, err := loadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = loaded
+		r.used = make([]bool, len(loaded.Interactions))
+	}
+	return r, nil
+}
+
+// Start returns a recorder for the fixture at path and stops it when the
+// test ends. It fails the test when the recorder cannot be created or the
+// fixture cannot be saved.
+func Start(tb testing.TB, path string, opts ...Option) *Recorder {
+	tb.Helper()
+
+	r, err := New(path, opts...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if err := r.Stop(); err != nil {
+			tb.Error(err)
+		}
+	})
+	return r
+}
+
+// Mode returns the mode the recorder runs in, which is never ModeAuto.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an HTTP client that sends its requests through r.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Stop saves the recorded interactions when recording. It does nothing when
+// replaying.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.save(r.path)
+}
+
+// RoundTrip records or replays req.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+// replay answers req with the first unused interaction the matcher accepts.
+// Redacted query parameters are removed from both URLs first, so that a
+// request matches whatever key it was recorded with.
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	matched := req.Clone(req.Context())
+	matched.URL = r.stripParams(req.URL)
+	for i, interaction := range r.cassette.Interactions {
+		recorded := interaction.Request
+		if parsed, err := url.Parse(recorded.URL); err == nil {
+			recorded.URL = r.stripParams(parsed).String()
+		}
+		if r.used[i] || !r.matcher(matched, body, recorded) {
+			continue
+		}
+		r.used[i] = true
+		return interaction.Response.toHTTP(req), nil
+	}
+	return nil, fmt.Errorf("vcr: no recorded interaction for %s %s in %s", req.Method, req.URL, r.path)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Streams are read to the end before the caller sees them, so a recorded
+	// stream arrives at once rather than as the provider sent it.
+	respBody, err := readResponseBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("vcr: read response: %w", err)
+	}
+
+	interaction := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    r.redactParams(req.URL).String(),
+			Header: r.redactHeader(req.Header),
+			Body:   string(body),
+		},
+		Response: newResponse(resp.StatusCode, r.redactHeader(resp.Header), respBody),
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return interaction.Response.toHTTP(req), nil
+}
+
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	out := header.Clone()
+	for name := range out {
+		if _, ok := r.redact[http.CanonicalHeaderKey(name)]; ok {
+			out[name] = []string{redactedValue}
+		}
+	}
+	return out
+}
+
+// redactParams returns u with the values of redacted query parameters
+// replaced.
+func (r *Recorder) redactParams(u *url.URL) *url.URL {
+	query := u.Query()
+	redacted := false
+	for name := range query {
+		if _, ok := r.params[strings.ToLower(name)]; ok {
+			query[name] = []string{redactedValue}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u
+	}
+	out := *u
+	out.RawQuery = query.Encode()
+	return &out
+}
+
+// stripParams returns u without redacted query parameters and with the
+// others in sorted order.
+func (r *Recorder) stripParams(u *url.URL) *url.URL {
+	if u.RawQuery == "" {
+		return u
+	}
+	query := u.Query()
+	for name := range query {
+		if _, ok := r.params[strings.ToLower(name)]; ok {
+			delete(query, name)
+		}
+	}
+	out := *u
+	out.RawQuery = query.Encode()
+	return &out
+}
+
+func modeFromEnv() (Mode, error) {
+	switch value := strings.ToLower(strings.TrimSpace(os.Getenv(EnvMode))); value {
+	case "", "replay":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	case "auto":
+		return ModeAuto, nil
+	default:
+		return ModeReplay, fmt.Errorf("vcr: unknown %s %q", EnvMode, value)
+	}
+}
+
+// readRequestBody reads the body of req, decompressing a gzip body, and
+// replaces it so the request can still be sent.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("vcr: read request: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+
+	if strings.EqualFold(req.Header.Get("Content-Encoding"), "gzip") {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("vcr: decompress request: %w", err)
+		}
+		if data, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("vcr: decompress request: %w", err)
+		}
+	}
+	return data, nil
+}
+
+// readResponseBody reads the body of resp. A gzip body the transport did not
+// decompress is decompressed so the fixture stays readable.
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	var body io.Reader = resp.Body
+	if !resp.Uncompressed && strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		reader, err := gzip.NewReader(resp.Body)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if err == nil {
+			body = reader
+		}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+	}
+	return io.ReadAll(body)
+}
@@ -0,0 +1,220 @@
+package vcr
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/ollama"
+	"github.com/m43i/go-ai/openai"
+)
+
+func chatParams(text string) *core.ChatParams {
+	return &core.ChatParams{Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: text}}}
+}
+
+func TestRecordThenReplayChat(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req_1")
+		_, _ = io.WriteString(w, `{"choices":[{"message":{"content":"hi there"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`)
+	}))
+
+	path := filepath.Join(t.TempDir(), "fixtures", "chat.json")
+	recorder, err := New(path, WithMode(ModeRecord))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	adapter := openai.New("gpt-test", openai.WithAPIKey("sk-secret"), openai.WithBaseURL(server.URL), openai.WithHTTPClient(recorder.Client()))
+	recorded, err := adapter.Chat(context.Background(), chatParams("hello"))
+	if err != nil {
+		t.Fatalf("record Chat() error = %v", err)
+	}
+	if err := recorder.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	server.Close()
+
+	fixture, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	if strings.Contains(string(fixture), "sk-secret") || !strings.Contains(string(fixture), redactedValue) {
+		t.Fatalf("fixture does not redact the API key:\n%s", fixture)
+	}
+
+	recorder, err = New(path, WithMode(ModeReplay))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	adapter = openai.New("gpt-test", openai.WithAPIKey("other-key"), openai.WithBaseURL(server.URL), openai.WithHTTPClient(recorder.Client()))
+	replayed, err := adapter.Chat(context.Background(), chatParams("hello"))
+	if err != nil {
+		t.Fatalf("replay Chat() error = %v", err)
+	}
+	if replayed.Text != recorded.Text || replayed.Text != "hi there" || replayed.RequestID != "req_1" || calls.Load() != 1 {
+		t.Fatalf("replayed %q (request %q), recorded %q, server calls %d", replayed.Text, replayed.RequestID, recorded.Text, calls.Load())
+	}
+
+	if _, err := adapter.Chat(context.Background(), chatParams("hello")); err == nil || !strings.Contains(err.Error(), "vcr: no recorded interaction for POST") {
+		t.Fatalf("second replay error = %v, want no recorded interaction", err)
+	}
+}
+
+func TestReplayStreamsRecordedEvents(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = io.WriteString(w, "{\"message\":{\"thinking\":\"Greet\"},\"done\":false}\n")
+		_, _ = io.WriteString(w, "{\"message\":{\"content\":\"Hel\"},\"done\":false}\n")
+		_, _ = io.WriteString(w, "{\"message\":{\"content\":\"lo\"},\"done\":true,\"done_reason\":\"stop\"}\n")
+	}))
+	defer server.Close()
+
+	collect := func(client *http.Client) []string {
+		adapter := ollama.New("llama-test", ollama.WithBaseURL(server.URL), ollama.WithHTTPClient(client))
+		stream, err := adapter.ChatStream(context.Background(), chatParams("hello"))
+		if err != nil {
+			t.Fatalf("ChatStream() error = %v", err)
+		}
+		var chunks []string
+		for chunk := range stream {
+			if chunk.Type == core.StreamChunkError {
+				t.Fatalf("stream error: %s", chunk.Error)
+			}
+			chunks = append(chunks, chunk.Type+":"+chunk.Delta)
+		}
+		return chunks
+	}
+
+	path := filepath.Join(t.TempDir(), "stream.json")
+	recorder := Start(t, path, WithMode(ModeRecord))
+	recorded := collect(recorder.Client())
+	if err := recorder.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	loaded, err := loadCassette(path)
+	if err != nil {
+		t.Fatalf("loadCassette() error = %v", err)
+	}
+	if events := loaded.Interactions[0].Response.Events; len(events) != 3 || events[1] != "{\"message\":{\"content\":\"Hel\"},\"done\":false}\n" {
+		t.Fatalf("recorded events = %q", events)
+	}
+
+	replayed := collect(Start(t, path, WithMode(ModeReplay)).Client())
+	if !reflect.DeepEqual(replayed, recorded) || len(replayed) != 4 {
+		t.Fatalf("replayed chunks %q, recorded %q", replayed, recorded)
+	}
+}
+
+func TestAutoModeRecordsMissingFixture(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "auto.json")
+	recorder, err := New(path, WithMode(ModeAuto))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if recorder.Mode() != ModeRecord {
+		t.Fatalf("Mode() = %v, want ModeRecord for a missing fixture", recorder.Mode())
+	}
+	if err := recorder.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if recorder, err = New(path, WithMode(ModeAuto)); err != nil || recorder.Mode() != ModeReplay {
+		t.Fatalf("New() = %v, %v; want ModeReplay for an existing fixture", recorder, err)
+	}
+	if _, err := New(filepath.Join(t.TempDir(), "missing.json"), WithMode(ModeReplay)); err == nil {
+		t.Fatal("New() error = nil, want an error for a missing fixture in replay mode")
+	}
+}
+
+func TestRedactsQueryCredentials(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "query.json")
+	recorder, err := New(path, WithMode(ModeRecord), WithRedactedParams("sig"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	resp, err := recorder.Client().Get(server.URL + "/v1/models?key=secret-key&sig=signature&alt=json")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if err := recorder.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	fixture, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(fixture), "secret-key") || strings.Contains(string(fixture), "signature") || !strings.Contains(string(fixture), "key=REDACTED") {
+		t.Fatalf("fixture = %s", fixture)
+	}
+
+	replayer, err := New(path, WithMode(ModeReplay), WithRedactedParams("sig"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	resp, err = replayer.Client().Get(server.URL + "/v1/models?alt=json&key=other-key")
+	if err != nil {
+		t.Fatalf("replay Get() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"ok":true}` {
+		t.Fatalf("replayed body = %s", body)
+	}
+}
+
+func TestModeFromEnvironment(t *testing.T) {
+	t.Setenv(EnvMode, "record")
+	if recorder, err := New(filepath.Join(t.TempDir(), "env.json")); err != nil || recorder.Mode() != ModeRecord {
+		t.Fatalf("New() = %v, %v; want ModeRecord", recorder, err)
+	}
+
+	t.Setenv(EnvMode, "sometimes")
+	if _, err := New(filepath.Join(t.TempDir(), "env.json")); err == nil {
+		t.Fatal("New() error = nil, want an error for an unknown mode")
+	}
+}
+
+func TestMatchRequestComparesJSONByValue(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "https://api.example.com/v1/chat?x=1", nil)
+	recorded := Request{Method: http.MethodPost, URL: "https://api.example.com/v1/chat?x=1", Body: `{"b": [1, 2], "a": "x"}`}
+
+	if !matchRequest(req, []byte(`{"a":"x","b":[1,2]}`), recorded) {
+		t.Fatal("matchRequest() = false for equal JSON in another key order")
+	}
+	if matchRequest(req, []byte(`{"a":"y","b":[1,2]}`), recorded) {
+		t.Fatal("matchRequest() = true for a different body")
+	}
+	recorded.URL = "https://api.example.com/v1/chat"
+	if matchRequest(req, []byte(`{"a":"x","b":[1,2]}`), recorded) {
+		t.Fatal("matchRequest() = true for a different URL")
+	}
+}
@@ -0,0 +1,343 @@
+// Package vertex is an adapter for Gemini models on Google Cloud Vertex AI,
+// using the generateContent, streamGenerateContent, and predict APIs.
+//
+// Requests are authorized with OAuth2 access tokens. Without explicit
+// credentials the adapter uses Application Default Credentials: the service
+// account key or user credentials file named by
+// GOOGLE_APPLICATION_CREDENTIALS, the file written by "gcloud auth
+// application-default login", or the attached service account from the
+// metadata server on Google Cloud. Service account keys are exchanged for
+// tokens with an RS256-signed JWT, and tokens are cached until shortly
+// before they expire.
+package vertex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/accesstoken"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+const (
+	providerName              = "vertex"
+	defaultLocation           = "us-central1"
+	defaultMaxAgenticLoops    = 8
+	defaultHTTPTimeout        = 5 * time.Minute
+	defaultThinkingBudget     = 8192
+	envApplicationCredentials = "GOOGLE_APPLICATION_CREDENTIALS"
+	envCloudProject           = "GOOGLE_CLOUD_PROJECT"
+	envGcloudProject          = "GCLOUD_PROJECT"
+	envCloudLocation          = "GOOGLE_CLOUD_LOCATION"
+	envCloudRegion            = "GOOGLE_CLOUD_REGION"
+)
+
+type Adapter struct {
+	Model string
+
+	// Project and Location select the Vertex AI endpoint. Project defaults
+	// to GOOGLE_CLOUD_PROJECT, GCLOUD_PROJECT, or the project of the
+	// credentials; Location to GOOGLE_CLOUD_LOCATION, GOOGLE_CLOUD_REGION,
+	// or us-central1.
+	Project  string
+	Location string
+
+	// BaseURL overrides the regional endpoint, such as
+	// https://us-central1-aiplatform.googleapis.com/v1.
+	BaseURL string
+
+	// CredentialsJSON holds a service account key or user credentials
+	// file. It is used when TokenSource is nil.
+	CredentialsJSON []byte
+
+	// TokenSource supplies access tokens. When nil, tokens come from
+	// CredentialsJSON or Application Default Credentials.
+	TokenSource TokenSource
+
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	tokens      TokenSource
+	credentials *Credentials
+}
+
+var _ core.TextAdapter = (*Adapter)(nil)
+var _ core.EmbeddingAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
+
+type Option func(*Adapter)
+
+// New creates a Vertex AI adapter for a Gemini model, such as
+// "gemini-2.5-flash".
+//
+// Preferred usage is to use core and add this adapter there.
+//
+// If no credentials are provided via options, the adapter uses Application
+// Default Credentials.
+func New(model string, opts ...Option) *Adapter {
+	adapter := &Adapter{
+		Model:      strings.TrimSpace(model),
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(adapter)
+	}
+
+	return adapter
+}
+
+// WithProject sets the Google Cloud project used by the adapter.
+func WithProject(project string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(project) == "" {
+			return
+		}
+		adapter.Project = strings.TrimSpace(project)
+	}
+}
+
+// WithLocation sets the Vertex AI location, such as "europe-west4" or
+// "global".
+func WithLocation(location string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(location) == "" {
+			return
+		}
+		adapter.Location = strings.TrimSpace(location)
+	}
+}
+
+// WithBaseURL sets the API base URL used by the adapter.
+func WithBaseURL(baseURL string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(baseURL) == "" {
+			return
+		}
+		adapter.BaseURL = strings.TrimSpace(baseURL)
+	}
+}
+
+// WithCredentialsJSON sets the service account key or user credentials
+// used to obtain access tokens.
+func WithCredentialsJSON(data []byte) Option {
+	return func(adapter *Adapter) {
+		if len(data) == 0 {
+			return
+		}
+		adapter.CredentialsJSON = data
+	}
+}
+
+// WithTokenSource sets the source of access tokens, such as one backed by
+// workload identity federation.
+func WithTokenSource(source TokenSource) Option {
+	return func(adapter *Adapter) {
+		if source == nil {
+			return
+		}
+		adapter.TokenSource = source
+	}
+}
+
+// WithAccessToken sets a fixed access token, such as the output of
+// "gcloud auth print-access-token". It is not refreshed.
+func WithAccessToken(token string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(token) == "" {
+			return
+		}
+		adapter.TokenSource = accesstoken.Static(strings.TrimSpace(token))
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the adapter.
+func WithHTTPClient(client *http.Client) Option {
+	return func(adapter *Adapter) {
+		if client == nil {
+			return
+		}
+		adapter.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the timeout on the adapter HTTP client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(adapter *Adapter) {
+		if timeout <= 0 {
+			return
+		}
+		if adapter.HTTPClient == nil {
+			adapter.HTTPClient = &http.Client{}
+		}
+		adapter.HTTPClient.Timeout = timeout
+	}
+}
+
+// Capabilities reports the chat features of Gemini models on Vertex AI.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:              true,
+		Vision:             true,
+		AudioInput:         true,
+		Documents:          true,
+		StructuredOutput:   true,
+		StreamingWithTools: true,
+		Reasoning:          true,
+	}
+}
+
+func (a *Adapter) validate() error {
+	if a == nil {
+		return errors.New("vertex: adapter is nil")
+	}
+	if strings.TrimSpace(a.Model) == "" {
+		return errors.New("vertex: model is required")
+	}
+	if _, err := a.tokenSource(); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(a.Model, "projects/") && a.project() == "" {
+		return fmt.Errorf("vertex: project is required (set %s or use vertex.WithProject)", envCloudProject)
+	}
+	return nil
+}
+
+// tokenSource resolves the source of access tokens once.
+func (a *Adapter) tokenSource() (TokenSource, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.tokens != nil {
+		return a.tokens, nil
+	}
+
+	source := a.TokenSource
+	if source == nil {
+		credentials, err := a.loadCredentials()
+		if err != nil {
+			return nil, err
+		}
+		if credentials == nil {
+			source = &metadataSource{url: metadataTokenURL, client: a.client()}
+		} else {
+			source, err = credentials.tokenSource(a.client())
+			if err != nil {
+				return nil, err
+			}
+			a.credentials = credentials
+		}
+	}
+
+	if _, ok := source.(accesstoken.Static); !ok {
+		source = &accesstoken.Cache{Source: source, RefreshWindow: tokenRefreshWindow}
+	}
+	a.tokens = source
+	return source, nil
+}
+
+func (a *Adapter) loadCredentials() (*Credentials, error) {
+	if len(a.CredentialsJSON) > 0 {
+		return ParseCredentials(a.CredentialsJSON)
+	}
+	return findDefaultCredentials()
+}
+
+func (a *Adapter) project() string {
+	if project := strings.TrimSpace(a.Project); project != "" {
+		return project
+	}
+	for _, name := range []string{envCloudProject, envGcloudProject} {
+		if project := strings.TrimSpace(os.Getenv(name)); project != "" {
+			return project
+		}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.credentials.project()
+}
+
+func (a *Adapter) location() string {
+	if location := strings.TrimSpace(a.Location); location != "" {
+		return location
+	}
+	for _, name := range []string{envCloudLocation, envCloudRegion} {
+		if location := strings.TrimSpace(os.Getenv(name)); location != "" {
+			return location
+		}
+	}
+	return defaultLocation
+}
+
+func (a *Adapter) baseURL() string {
+	if strings.TrimSpace(a.BaseURL) != "" {
+		return strings.TrimRight(a.BaseURL, "/")
+	}
+	if location := a.location(); location != "global" {
+		return "https://" + location + "-aiplatform.googleapis.com/v1"
+	}
+	return "https://aiplatform.googleapis.com/v1"
+}
+
+// modelPath returns the path of a model method, such as
+// "/projects/p/locations/l/publishers/google/models/gemini-2.5-flash:generateContent".
+// Models may also be given as "publishers/<publisher>/models/<model>" or as
+// a full resource name.
+func (a *Adapter) modelPath(method string) string {
+	model := strings.Trim(a.Model, "/")
+	switch {
+	case strings.HasPrefix(model, "projects/"):
+	case strings.HasPrefix(model, "publishers/"):
+		model = "projects/" + url.PathEscape(a.project()) + "/locations/" + url.PathEscape(a.location()) + "/" + model
+	default:
+		model = "projects/" + url.PathEscape(a.project()) + "/locations/" + url.PathEscape(a.location()) + "/publishers/google/models/" + model
+	}
+	return "/" + model + ":" + method
+}
+
+// request builds an API request authorized with a current access token.
+func (a *Adapter) request(ctx context.Context, path, name string, body any) (httpclient.Request, error) {
+	source, err := a.tokenSource()
+	if err != nil {
+		return httpclient.Request{}, err
+	}
+	token, err := source.Token(ctx)
+	if err != nil {
+		return httpclient.Request{}, err
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token.AccessToken)
+	return httpclient.Request{Path: path, Name: name, Body: body, Header: header}, nil
+}
+
+func (a *Adapter) client() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// transport returns the client that sends API requests.
+func (a *Adapter) transport() *httpclient.Client {
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   providerName,
+		Header: func(header http.Header) {
+			header.Set("Accept", "application/json")
+		},
+		DecodeError: decodeAPIError,
+	}
+}
@@ -0,0 +1,289 @@
+package vertex
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/internal/accesstoken"
+)
+
+const (
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+	defaultTokenURL    = "https://oauth2.googleapis.com/token"
+	metadataTokenURL   = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	jwtBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+	// tokenRefreshWindow is how long before its expiry a token is refreshed.
+	tokenRefreshWindow = time.Minute
+)
+
+// Token is an OAuth2 access token.
+type Token = accesstoken.Token
+
+// TokenSource returns access tokens for the Vertex AI API.
+type TokenSource = accesstoken.Source
+
+// Credentials are parsed Google credentials, as found in a service account
+// key file or the application default credentials file.
+type Credentials struct {
+	// Type is "service_account" or "authorized_user".
+	Type string `json:"type"`
+
+	// ProjectID is set in service account keys. QuotaProjectID is set in
+	// user credentials created with gcloud.
+	ProjectID      string `json:"project_id"`
+	QuotaProjectID string `json:"quota_project_id"`
+
+	// Service account fields.
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+
+	// Authorized user fields.
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ParseCredentials parses a service account key or an authorized user
+// credentials file.
+func ParseCredentials(data []byte) (*Credentials, error) {
+	var credentials Credentials
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("vertex: parse credentials: %w", err)
+	}
+
+	switch credentials.Type {
+	case "service_account":
+		if credentials.ClientEmail == "" || credentials.PrivateKey == "" {
+			return nil, errors.New("vertex: service account credentials need client_email and private_key")
+		}
+	case "authorized_user":
+		if credentials.ClientID == "" || credentials.ClientSecret == "" || credentials.RefreshToken == "" {
+			return nil, errors.New("vertex: authorized user credentials need client_id, client_secret, and refresh_token")
+		}
+	default:
+		return nil, fmt.Errorf("vertex: unsupported credentials type %q", credentials.Type)
+	}
+
+	return &credentials, nil
+}
+
+// project returns the project named in the credentials.
+func (c *Credentials) project() string {
+	if c == nil {
+		return ""
+	}
+	if c.ProjectID != "" {
+		return c.ProjectID
+	}
+	return c.QuotaProjectID
+}
+
+// tokenSource returns a source that exchanges the credentials for access
+// tokens with client.
+func (c *Credentials) tokenSource(client *http.Client) (TokenSource, error) {
+	switch c.Type {
+	case "service_account":
+		key, err := parsePrivateKey(c.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		return &serviceAccountSource{credentials: c, key: key, client: client}, nil
+	case "authorized_user":
+		return &refreshTokenSource{credentials: c, client: client}, nil
+	}
+	return nil, fmt.Errorf("vertex: unsupported credentials type %q", c.Type)
+}
+
+// findDefaultCredentials loads the application default credentials: the
+// file named by GOOGLE_APPLICATION_CREDENTIALS, then the gcloud well-known
+// file. It returns nil when neither exists, so that the metadata server of
+// the environment is used.
+func findDefaultCredentials() (*Credentials, error) {
+	if path := strings.TrimSpace(os.Getenv(envApplicationCredentials)); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("vertex: read %s: %w", envApplicationCredentials, err)
+		}
+		return ParseCredentials(data)
+	}
+
+	path := wellKnownCredentialsFile()
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vertex: read application default credentials: %w", err)
+	}
+	return ParseCredentials(data)
+}
+
+// wellKnownCredentialsFile is where "gcloud auth application-default login"
+// writes credentials.
+func wellKnownCredentialsFile() string {
+	const file = "application_default_credentials.json"
+	if dir := strings.TrimSpace(os.Getenv("CLOUDSDK_CONFIG")); dir != "" {
+		return filepath.Join(dir, file)
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return filepath.Join(dir, "gcloud", file)
+		}
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", file)
+}
+
+// serviceAccountSource signs a JWT with the service account key and
+// exchanges it for an access token.
+type serviceAccountSource struct {
+	credentials *Credentials
+	key         *rsa.PrivateKey
+	client      *http.Client
+}
+
+func (s *serviceAccountSource) Token(ctx context.Context) (*Token, error) {
+	tokenURL := s.credentials.TokenURI
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+
+	assertion, err := signJWT(s.key, s.credentials.PrivateKeyID, map[string]any{
+		"iss":   s.credentials.ClientEmail,
+		"scope": cloudPlatformScope,
+		"aud":   tokenURL,
+	}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return accesstoken.Exchange(ctx, s.client, providerName, tokenURL, url.Values{
+		"grant_type": {jwtBearerGrantType},
+		"assertion":  {assertion},
+	})
+}
+
+// refreshTokenSource exchanges the refresh token of user credentials for
+// access tokens.
+type refreshTokenSource struct {
+	credentials *Credentials
+	client      *http.Client
+}
+
+func (s *refreshTokenSource) Token(ctx context.Context) (*Token, error) {
+	tokenURL := s.credentials.TokenURI
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+
+	return accesstoken.Exchange(ctx, s.client, providerName, tokenURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {s.credentials.ClientID},
+		"client_secret": {s.credentials.ClientSecret},
+		"refresh_token": {s.credentials.RefreshToken},
+	})
+}
+
+// metadataSource reads tokens of the attached service account from the
+// metadata server on Google Cloud, such as on Compute Engine, GKE, or Cloud
+// Run.
+type metadataSource struct {
+	url    string
+	client *http.Client
+}
+
+func (s *metadataSource) Token(ctx context.Context) (*Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("vertex: build metadata token request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vertex: no credentials found (set %s or run on Google Cloud): %w", envApplicationCredentials, err)
+	}
+	defer resp.Body.Close()
+	return accesstoken.Decode(providerName, resp)
+}
+
+// signJWT builds an RS256-signed JWT that is valid for an hour from now.
+func signJWT(key *rsa.PrivateKey, keyID string, claims map[string]any, now time.Time) (string, error) {
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	if keyID != "" {
+		header["kid"] = keyID
+	}
+
+	payload := make(map[string]any, len(claims)+2)
+	for name, value := range claims {
+		payload[name] = value
+	}
+	payload["iat"] = now.Unix()
+	payload["exp"] = now.Add(time.Hour).Unix()
+
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("vertex: encode JWT header: %w", err)
+	}
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("vertex: encode JWT claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(encodedHeader) + "." + base64.RawURLEncoding.EncodeToString(encodedPayload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("vertex: sign JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM-encoded RSA key in PKCS #8 or PKCS #1 form.
+func parsePrivateKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("vertex: private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("vertex: private key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("vertex: parse private key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,217 @@
+package vertex
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func newTestKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	encoded, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: encoded}))
+}
+
+// verifyJWT checks the RS256 signature of token and returns its header and
+// claims.
+func verifyJWT(t *testing.T, key *rsa.PublicKey, token string) (map[string]any, map[string]any) {
+	t.Helper()
+
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		t.Fatalf("JWT has %d segments", len(segments))
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil {
+		t.Fatalf("decode signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(segments[0] + "." + segments[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Fatalf("JWT signature: %v", err)
+	}
+
+	decode := func(segment string) map[string]any {
+		raw, err := base64.RawURLEncoding.DecodeString(segment)
+		if err != nil {
+			t.Fatalf("decode segment: %v", err)
+		}
+		var out map[string]any
+		if err := json.Unmarshal(raw, &out); err != nil {
+			t.Fatalf("unmarshal segment: %v", err)
+		}
+		return out
+	}
+	return decode(segments[0]), decode(segments[1])
+}
+
+func TestServiceAccountCredentialsSignJWTAndCacheToken(t *testing.T) {
+	t.Parallel()
+
+	key, keyPEM := newTestKey(t)
+	var exchanges atomic.Int32
+	var tokenURL string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exchanges.Add(1)
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		if grant := r.PostForm.Get("grant_type"); grant != jwtBearerGrantType {
+			t.Errorf("grant_type = %q", grant)
+		}
+		header, claims := verifyJWT(t, &key.PublicKey, r.PostForm.Get("assertion"))
+		if header["alg"] != "RS256" || header["kid"] != "key-1" {
+			t.Errorf("JWT header = %v", header)
+		}
+		if claims["iss"] != "bot@example.iam.gserviceaccount.com" || claims["aud"] != tokenURL || claims["scope"] != cloudPlatformScope {
+			t.Errorf("JWT claims = %v", claims)
+		}
+		if exp, iat := claims["exp"].(float64), claims["iat"].(float64); exp-iat != 3600 {
+			t.Errorf("JWT lifetime = %v", exp-iat)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"sa-token","expires_in":3600,"token_type":"Bearer"}`))
+	}))
+	defer tokenServer.Close()
+	tokenURL = tokenServer.URL + "/token"
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer sa-token" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if r.URL.Path != "/projects/key-project/locations/us-central1/publishers/google/models/gemini-2.5-flash:generateContent" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer apiServer.Close()
+
+	credentials, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "key-project",
+		"private_key_id": "key-1",
+		"private_key":    keyPEM,
+		"client_email":   "bot@example.iam.gserviceaccount.com",
+		"token_uri":      tokenURL,
+	})
+	adapter := New("gemini-2.5-flash", WithCredentialsJSON(credentials), WithLocation("us-central1"), WithBaseURL(apiServer.URL))
+
+	for range 2 {
+		if _, err := adapter.Chat(context.Background(), &core.ChatParams{
+			Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		}); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+	if got := exchanges.Load(); got != 1 {
+		t.Fatalf("token exchanges = %d, want 1", got)
+	}
+}
+
+func TestAuthorizedUserCredentialsRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		if r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("refresh_token") != "refresh-1" || r.PostForm.Get("client_id") != "client" {
+			t.Errorf("unexpected form %v", r.PostForm)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"user-token","expires_in":3599}`))
+	}))
+	defer server.Close()
+
+	credentials, err := ParseCredentials([]byte(`{"type":"authorized_user","client_id":"client","client_secret":"secret","refresh_token":"refresh-1","quota_project_id":"quota","token_uri":"` + server.URL + `"}`))
+	if err != nil {
+		t.Fatalf("ParseCredentials() error = %v", err)
+	}
+	if credentials.project() != "quota" {
+		t.Fatalf("project = %q", credentials.project())
+	}
+
+	source, err := credentials.tokenSource(server.Client())
+	if err != nil {
+		t.Fatalf("tokenSource() error = %v", err)
+	}
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "user-token" || time.Until(token.Expiry) < 59*time.Minute {
+		t.Fatalf("token = %+v", token)
+	}
+}
+
+func TestTokenExchangeReportsOAuthErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`))
+	}))
+	defer server.Close()
+
+	source := &refreshTokenSource{credentials: &Credentials{TokenURI: server.URL}, client: server.Client()}
+	_, err := source.Token(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant: Token has been expired or revoked.") {
+		t.Fatalf("Token() error = %v", err)
+	}
+}
+
+func TestParseCredentialsValidatesFields(t *testing.T) {
+	t.Parallel()
+
+	for _, data := range []string{
+		`{"type":"external_account"}`,
+		`{"type":"service_account","client_email":"bot@example.com"}`,
+		`{"type":"authorized_user","client_id":"client"}`,
+		`not json`,
+	} {
+		if _, err := ParseCredentials([]byte(data)); err == nil {
+			t.Errorf("ParseCredentials(%s) expected error", data)
+		}
+	}
+}
+
+func TestMetadataSourceSendsFlavorHeader(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			t.Errorf("Metadata-Flavor = %q", r.Header.Get("Metadata-Flavor"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"metadata-token","expires_in":300}`))
+	}))
+	defer server.Close()
+
+	token, err := (&metadataSource{url: server.URL, client: server.Client()}).Token(context.Background())
+	if err != nil || token.AccessToken != "metadata-token" {
+		t.Fatalf("Token() = %+v, %v", token, err)
+	}
+}
@@ -0,0 +1,482 @@
+package vertex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/partialjson"
+	"github.com/m43i/go-ai/internal/sse"
+)
+
+// Chat sends a non-streaming generateContent request to Vertex AI.
+//
+// It supports tool calls, structured output, thinking, and several
+// candidates through ChatParams.CandidateCount.
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	requestTemplate, serverTools, clientTools, maxLoopCount, err := a.buildRequest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := requestTemplate.Contents
+	conversation := cloneCoreMessages(params)
+	reasoningParts := make([]string, 0, 4)
+	callCount := 0
+
+	for range maxLoopCount {
+		request := requestTemplate
+		request.Contents = contents
+
+		response, err := a.generate(ctx, &request)
+		if err != nil {
+			return nil, err
+		}
+
+		if blocked := blockedResult(response, conversation); blocked != nil {
+			return blocked, nil
+		}
+		if len(response.Candidates) == 0 {
+			return nil, errors.New("vertex: response did not include a candidate")
+		}
+
+		first := response.Candidates[0]
+		parts := first.Content.Parts
+		reasoningParts = appendReasoningPart(reasoningParts, extractReasoning(parts))
+
+		calls := toCoreToolCalls(parts, &callCount)
+		if len(calls) == 0 {
+			text := extractText(parts)
+			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: text})
+			return &core.ChatResult{
+				Text:            text,
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				Citations:       toCoreCitations(first.CitationMetadata),
+				FinishReason:    toCoreFinishReason(first.FinishReason),
+				RawFinishReason: first.FinishReason,
+				Usage:           responseUsage(response),
+				RequestID:       response.ResponseID,
+				Candidates:      toCoreCandidates(response.Candidates),
+			}, nil
+		}
+
+		contents = append(contents, content{Role: "model", Parts: parts})
+
+		if text := extractText(parts); strings.TrimSpace(text) != "" {
+			conversation = append(conversation, core.TextMessagePart{Role: core.RoleAssistant, Content: text})
+		}
+		conversation = append(conversation, core.ToolCallMessagePart{
+			Role:            core.RoleToolCall,
+			ToolCalls:       calls,
+			ReasoningBlocks: extractReasoningBlocks(parts, calls),
+		})
+
+		results, pendingClientCalls, err := runServerTools(calls, serverTools, clientTools)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			conversation = append(conversation, result)
+		}
+
+		if len(pendingClientCalls) > 0 {
+			return &core.ChatResult{
+				Text:            "",
+				Reasoning:       joinReasoningParts(reasoningParts),
+				Messages:        append([]core.MessageUnion(nil), conversation...),
+				ToolCalls:       pendingClientCalls,
+				FinishReason:    core.FinishReasonToolCalls,
+				RawFinishReason: first.FinishReason,
+				Usage:           responseUsage(response),
+				RequestID:       response.ResponseID,
+			}, nil
+		}
+
+		contents = append(contents, toolResultsContent(results))
+	}
+
+	return nil, fmt.Errorf("vertex: reached max tool loop count (%d)", maxLoopCount)
+}
+
+// ChatStream sends streamGenerateContent requests to Vertex AI.
+//
+// Thoughts stream as reasoning chunks and structured output as
+// StreamChunkPartialJSON chunks. Server tools run between streamed turns, and
+// the stream ends with FinishReason "tool_calls" when client tools must be
+// run.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	requestTemplate, serverTools, clientTools, maxLoopCount, err := a.buildRequest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	out := make(chan core.StreamChunk, 64)
+
+	go func() {
+		defer close(out)
+
+		contents := requestTemplate.Contents
+		reasoningParts := make([]string, 0, 4)
+		callCount := 0
+
+		for range maxLoopCount {
+			request := requestTemplate
+			request.Contents = contents
+
+			turn, err := a.streamTurn(ctx, &request, params.Output != nil, &callCount, out)
+			if err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: turn.requestID}
+				return
+			}
+
+			reasoningParts = appendReasoningPart(reasoningParts, extractReasoning(turn.parts))
+
+			if len(turn.calls) == 0 {
+				out <- core.StreamChunk{
+					Type:            core.StreamChunkDone,
+					FinishReason:    toCoreFinishReason(turn.finishReason),
+					RawFinishReason: turn.finishReason,
+					Reasoning:       joinReasoningParts(reasoningParts),
+					Usage:           turn.usage,
+					RequestID:       turn.requestID,
+				}
+				return
+			}
+
+			contents = append(contents, content{Role: "model", Parts: turn.parts})
+
+			results, pendingClientCalls, err := runServerTools(turn.calls, serverTools, clientTools)
+			if err != nil {
+				out <- core.StreamChunk{Type: core.StreamChunkError, Error: err.Error(), Err: err, RequestID: turn.requestID}
+				return
+			}
+			for _, result := range results {
+				out <- core.StreamChunk{Type: core.StreamChunkToolResult, ToolCallID: result.ToolCallID, Content: result.Content}
+			}
+
+			if len(pendingClientCalls) > 0 {
+				out <- core.StreamChunk{
+					Type:            core.StreamChunkDone,
+					FinishReason:    core.FinishReasonToolCalls,
+					RawFinishReason: turn.finishReason,
+					Reasoning:       joinReasoningParts(reasoningParts),
+					Usage:           turn.usage,
+					RequestID:       turn.requestID,
+				}
+				return
+			}
+
+			contents = append(contents, toolResultsContent(results))
+		}
+
+		out <- core.StreamChunk{Type: core.StreamChunkError, Error: fmt.Sprintf("vertex: reached max tool loop count (%d)", maxLoopCount)}
+	}()
+
+	return core.TimeStream(ctx, out, start), nil
+}
+
+// streamedTurn is one streamed model response, accumulated for the next
+// request of the tool loop.
+type streamedTurn struct {
+	parts        []part
+	calls        []core.ToolCall
+	finishReason string
+	usage        *core.Usage
+	requestID    string
+}
+
+// streamTurn streams one streamGenerateContent request, forwarding thought,
+// content, structured output, and tool call chunks to out as they arrive.
+// The returned turn is never nil, so that failed turns still report the
+// response ID.
+func (a *Adapter) streamTurn(ctx context.Context, request *generateRequest, output bool, callCount *int, out chan<- core.StreamChunk) (*streamedTurn, error) {
+	turn := &streamedTurn{}
+
+	body, err := marshalWithModelOptions(request, request.ModelOptions)
+	if err != nil {
+		return turn, fmt.Errorf("vertex: marshal stream request: %w", err)
+	}
+	apiRequest, err := a.request(ctx, a.modelPath("streamGenerateContent")+"?alt=sse", "stream", body)
+	if err != nil {
+		return turn, err
+	}
+	apiRequest.Header.Set("Accept", "text/event-stream")
+
+	httpResp, err := a.transport().Do(ctx, apiRequest)
+	if err != nil {
+		return turn, err
+	}
+	defer httpResp.Body.Close()
+
+	reader := sse.NewReader(httpResp.Body)
+	defer reader.Release()
+
+	var text, thoughts strings.Builder
+	var streamed, reasoning strings.Builder
+	var signature string
+	var partial partialjson.Assembler
+	var usage *usageMetadata
+
+	// flush ends the text or thought part being streamed, so that parts keep
+	// their order in the model turn sent back with tool results.
+	flush := func() {
+		if thoughts.Len() > 0 {
+			turn.parts = append(turn.parts, part{Text: thoughts.String(), Thought: true})
+			thoughts.Reset()
+		}
+		if text.Len() > 0 || signature != "" {
+			turn.parts = append(turn.parts, part{Text: text.String(), ThoughtSignature: signature})
+			text.Reset()
+			signature = ""
+		}
+	}
+
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return turn, fmt.Errorf("vertex: stream read failed: %w", err)
+		}
+
+		payload := bytes.TrimSpace(event.Data)
+		if len(payload) == 0 {
+			continue
+		}
+
+		var chunk struct {
+			generateResponse
+			Error *apiErrorDetail `json:"error,omitempty"`
+		}
+		if err := json.Unmarshal(payload, &chunk); err != nil {
+			return turn, fmt.Errorf("vertex: decode stream chunk: %w", err)
+		}
+		if chunk.ResponseID != "" {
+			turn.requestID = chunk.ResponseID
+		}
+		if chunk.Error != nil {
+			return turn, apiError(chunk.Error.Code, chunk.Error.Status, chunk.Error.Message, turn.requestID)
+		}
+		if chunk.UsageMetadata != nil {
+			usage = chunk.UsageMetadata
+		}
+		if chunk.PromptFeedback != nil && chunk.PromptFeedback.BlockReason != "" {
+			turn.finishReason = chunk.PromptFeedback.BlockReason
+			break
+		}
+		if len(chunk.Candidates) == 0 {
+			continue
+		}
+
+		first := chunk.Candidates[0]
+		for _, item := range first.Content.Parts {
+			switch {
+			case item.Thought:
+				if text.Len() > 0 {
+					flush()
+				}
+				thoughts.WriteString(item.Text)
+				reasoning.WriteString(item.Text)
+				if item.Text != "" {
+					out <- core.StreamChunk{
+						Type:      core.StreamChunkReasoning,
+						Role:      core.RoleAssistant,
+						Delta:     item.Text,
+						Reasoning: reasoning.String(),
+					}
+				}
+
+			case item.FunctionCall != nil:
+				flush()
+				call := toCoreToolCall(item.FunctionCall, callCount)
+				turn.parts = append(turn.parts, item)
+				turn.calls = append(turn.calls, call)
+				out <- core.StreamChunk{Type: core.StreamChunkToolCall, ToolCall: &call}
+
+			case item.Text != "" || item.ThoughtSignature != "":
+				if thoughts.Len() > 0 {
+					flush()
+				}
+				text.WriteString(item.Text)
+				if item.ThoughtSignature != "" {
+					signature = item.ThoughtSignature
+				}
+				if item.Text == "" {
+					continue
+				}
+				streamed.WriteString(item.Text)
+				if output {
+					partial.WriteString(item.Text)
+					out <- core.StreamChunk{
+						Type:    core.StreamChunkPartialJSON,
+						Role:    core.RoleAssistant,
+						Delta:   item.Text,
+						Content: partial.Partial(),
+					}
+				} else {
+					out <- core.StreamChunk{
+						Type:    core.StreamChunkContent,
+						Role:    core.RoleAssistant,
+						Delta:   item.Text,
+						Content: streamed.String(),
+					}
+				}
+			}
+		}
+
+		if first.FinishReason != "" {
+			turn.finishReason = first.FinishReason
+		}
+	}
+	flush()
+
+	if usage != nil {
+		turn.usage = toCoreUsage(usage)
+		turn.usage.RequestID = turn.requestID
+	}
+	return turn, nil
+}
+
+// buildRequest converts params into a generateContent request template
+// holding the initial contents.
+func (a *Adapter) buildRequest(params *core.ChatParams) (generateRequest, map[string]core.ServerTool, map[string]struct{}, int, error) {
+	contents, system, err := toContents(params)
+	if err != nil {
+		return generateRequest{}, nil, nil, 0, err
+	}
+	if len(contents) == 0 {
+		return generateRequest{}, nil, nil, 0, errors.New("vertex: at least one user or assistant message is required")
+	}
+
+	tools, serverTools, clientTools, err := toTools(params)
+	if err != nil {
+		return generateRequest{}, nil, nil, 0, err
+	}
+
+	choice, err := toToolConfig(params, tools)
+	if err != nil {
+		return generateRequest{}, nil, nil, 0, err
+	}
+
+	if params.Output != nil && params.Output.Schema == nil {
+		return generateRequest{}, nil, nil, 0, errors.New("vertex: output schema is required")
+	}
+	config, err := toGenerationConfig(params)
+	if err != nil {
+		return generateRequest{}, nil, nil, 0, err
+	}
+
+	request := generateRequest{
+		Contents:          contents,
+		SystemInstruction: system,
+		Tools:             tools,
+		ToolConfig:        choice,
+		GenerationConfig:  config,
+		ModelOptions:      modelOptions(params),
+	}
+
+	return request, serverTools, clientTools, maxLoops(params, len(serverTools) > 0), nil
+}
+
+func (a *Adapter) generate(ctx context.Context, request *generateRequest) (*generateResponse, error) {
+	body, err := marshalWithModelOptions(request, request.ModelOptions)
+	if err != nil {
+		return nil, fmt.Errorf("vertex: marshal generate request: %w", err)
+	}
+
+	apiRequest, err := a.request(ctx, a.modelPath("generateContent"), "generate", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response generateResponse
+	httpResp, err := a.transport().Send(ctx, apiRequest, &response)
+	if err != nil {
+		return nil, err
+	}
+	response.Duration = httpResp.Duration
+
+	return &response, nil
+}
+
+// blockedResult returns the result of a prompt that was blocked before any
+// candidate was generated, or nil.
+func blockedResult(response *generateResponse, conversation []core.MessageUnion) *core.ChatResult {
+	if response.PromptFeedback == nil || response.PromptFeedback.BlockReason == "" {
+		return nil
+	}
+	return &core.ChatResult{
+		Messages:        append([]core.MessageUnion(nil), conversation...),
+		FinishReason:    core.FinishReasonContentFilter,
+		RawFinishReason: response.PromptFeedback.BlockReason,
+		Usage:           responseUsage(response),
+		RequestID:       response.ResponseID,
+	}
+}
+
+// runServerTools runs the server tools among calls and returns their results
+// and the calls left to the caller.
+func runServerTools(calls []core.ToolCall, serverTools map[string]core.ServerTool, clientTools map[string]struct{}) ([]core.ToolResultMessagePart, []core.ToolCall, error) {
+	results := make([]core.ToolResultMessagePart, 0, len(calls))
+	var pendingClientCalls []core.ToolCall
+
+	for _, call := range calls {
+		if serverTool, ok := serverTools[call.Name]; ok {
+			result, callErr := serverTool.Handler(call.Arguments)
+			if callErr != nil {
+				result = "tool_error: " + callErr.Error()
+			}
+			results = append(results, core.ToolResultMessagePart{
+				Role:       core.RoleToolResult,
+				ToolCallID: call.ID,
+				Name:       call.Name,
+				Content:    result,
+			})
+			continue
+		}
+
+		if _, ok := clientTools[call.Name]; ok {
+			pendingClientCalls = append(pendingClientCalls, call)
+			continue
+		}
+
+		return nil, nil, fmt.Errorf("vertex: tool %q was requested but not registered", call.Name)
+	}
+
+	return results, pendingClientCalls, nil
+}
+
+func toolResultsContent(results []core.ToolResultMessagePart) content {
+	parts := make([]part, 0, len(results))
+	for _, result := range results {
+		parts = append(parts, functionResponsePart(result.Name, result.Content))
+	}
+	return content{Role: core.RoleUser, Parts: parts}
+}
+
+func cloneCoreMessages(params *core.ChatParams) []core.MessageUnion {
+	if params == nil || len(params.Messages) == 0 {
+		return nil
+	}
+
+	out := make([]core.MessageUnion, 0, len(params.Messages)+8)
+	out = append(out, params.Messages...)
+	return out
+}
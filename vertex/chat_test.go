@@ -0,0 +1,460 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+const testModel = "gemini-2.5-flash"
+
+func newTestAdapter(baseURL string, opts ...Option) *Adapter {
+	opts = append([]Option{
+		WithProject("test-project"),
+		WithLocation("europe-west4"),
+		WithAccessToken("test-token"),
+		WithBaseURL(baseURL),
+	}, opts...)
+	return New(testModel, opts...)
+}
+
+const testGeneratePath = "/projects/test-project/locations/europe-west4/publishers/google/models/gemini-2.5-flash:generateContent"
+
+func TestChatMapsRequestAndResponse(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != testGeneratePath {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-token" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"candidates":[{"content":{"role":"model","parts":[{"text":"Weighing the question.","thought":true},{"text":"Paris."}]},"finishReason":"STOP",
+				"citationMetadata":{"citations":[{"startIndex":0,"endIndex":6,"uri":"https://example.com/paris","title":"Paris"}]}}],
+			"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":3,"thoughtsTokenCount":20,"totalTokenCount":35,"cachedContentTokenCount":8},
+			"responseId":"resp-1"}`))
+	}))
+	defer server.Close()
+
+	maxTokens := int64(256)
+	temperature := 0.3
+	result, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		SystemPrompts: []string{"Be brief."},
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleSystem, Content: "Answer in English."},
+			core.ContentMessagePart{Role: core.RoleUser, Parts: []core.ContentPart{
+				core.TextPart{Text: "Which city is this?"},
+				core.ImagePart{Source: core.DataSource{Data: "aGVsbG8=", MimeType: "image/png"}},
+				core.DocumentPart{Source: core.URLSource{URL: "gs://bucket/guide.pdf", MimeType: "application/pdf"}},
+				core.RawPart{Provider: "openai", Payload: json.RawMessage(`{"type":"ignored"}`)},
+			}},
+		},
+		MaxTokens:       &maxTokens,
+		Temperature:     &temperature,
+		StopSequences:   []string{"END"},
+		ReasoningEffort: "high",
+		ModelOptions:    map[string]any{"labels": map[string]any{"team": "search"}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	system := request["systemInstruction"].(map[string]any)["parts"].([]any)
+	if len(system) != 2 || system[0].(map[string]any)["text"] != "Be brief." || system[1].(map[string]any)["text"] != "Answer in English." {
+		t.Fatalf("systemInstruction parts = %v", system)
+	}
+	contents := request["contents"].([]any)
+	if len(contents) != 1 || contents[0].(map[string]any)["role"] != "user" {
+		t.Fatalf("contents = %v", contents)
+	}
+	parts := contents[0].(map[string]any)["parts"].([]any)
+	if len(parts) != 3 {
+		t.Fatalf("parts = %v", parts)
+	}
+	if inline := parts[1].(map[string]any)["inlineData"].(map[string]any); inline["mimeType"] != "image/png" || inline["data"] != "aGVsbG8=" {
+		t.Fatalf("inlineData = %v", inline)
+	}
+	if file := parts[2].(map[string]any)["fileData"].(map[string]any); file["fileUri"] != "gs://bucket/guide.pdf" {
+		t.Fatalf("fileData = %v", file)
+	}
+
+	config := request["generationConfig"].(map[string]any)
+	if config["maxOutputTokens"] != float64(256) || config["temperature"] != 0.3 || !reflect.DeepEqual(config["stopSequences"], []any{"END"}) {
+		t.Fatalf("generationConfig = %v", config)
+	}
+	if thinking := config["thinkingConfig"].(map[string]any); thinking["thinkingBudget"] != float64(24576) || thinking["includeThoughts"] != true {
+		t.Fatalf("thinkingConfig = %v", thinking)
+	}
+	if labels := request["labels"].(map[string]any); labels["team"] != "search" {
+		t.Fatalf("labels = %v", labels)
+	}
+
+	if result.Text != "Paris." || result.Reasoning != "Weighing the question." || result.FinishReason != core.FinishReasonStop || result.RequestID != "resp-1" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Usage.PromptTokens != 12 || result.Usage.CompletionTokens != 23 || result.Usage.ReasoningTokens != 20 || result.Usage.TotalTokens != 35 || result.Usage.Details["cached_content_tokens"] != 8 {
+		t.Fatalf("usage = %+v", result.Usage)
+	}
+	if len(result.Citations) != 1 || result.Citations[0].URL != "https://example.com/paris" || result.Citations[0].EndIndex != 6 {
+		t.Fatalf("citations = %+v", result.Citations)
+	}
+}
+
+func TestChatRunsServerToolsWithThoughtSignatures(t *testing.T) {
+	t.Parallel()
+
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		requests = append(requests, request)
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{"city":"Paris"}},"thoughtSignature":"sig-1"}]},"finishReason":"STOP"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"It is sunny."}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	result, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather in Paris?"}},
+		Tools: []core.ToolUnion{core.ServerTool{
+			Name:        "get_weather",
+			Description: "Get the weather",
+			Parameters:  map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}},
+			Handler: func(args any) (string, error) {
+				if args.(map[string]any)["city"] != "Paris" {
+					t.Errorf("unexpected args %v", args)
+				}
+				return "sunny", nil
+			},
+		}},
+		ToolChoice: &core.ToolChoice{Mode: core.ToolChoiceAuto},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Text != "It is sunny." || len(requests) != 2 {
+		t.Fatalf("unexpected result %+v after %d requests", result, len(requests))
+	}
+
+	first := requests[0]
+	declarations := first["tools"].([]any)[0].(map[string]any)["functionDeclarations"].([]any)
+	if declaration := declarations[0].(map[string]any); declaration["name"] != "get_weather" || declaration["parametersJsonSchema"] == nil {
+		t.Fatalf("declaration = %v", declaration)
+	}
+	if mode := first["toolConfig"].(map[string]any)["functionCallingConfig"].(map[string]any)["mode"]; mode != "AUTO" {
+		t.Fatalf("mode = %v", mode)
+	}
+
+	contents := requests[1]["contents"].([]any)
+	if len(contents) != 3 {
+		t.Fatalf("contents = %v", contents)
+	}
+	modelPart := contents[1].(map[string]any)["parts"].([]any)[0].(map[string]any)
+	if modelPart["thoughtSignature"] != "sig-1" || modelPart["functionCall"].(map[string]any)["name"] != "get_weather" {
+		t.Fatalf("model part = %v", modelPart)
+	}
+	response := contents[2].(map[string]any)["parts"].([]any)[0].(map[string]any)["functionResponse"].(map[string]any)
+	if response["name"] != "get_weather" || response["response"].(map[string]any)["output"] != "sunny" {
+		t.Fatalf("functionResponse = %v", response)
+	}
+
+	var toolCalls *core.ToolCallMessagePart
+	for _, message := range result.Messages {
+		if typed, ok := message.(core.ToolCallMessagePart); ok {
+			toolCalls = &typed
+		}
+	}
+	if toolCalls == nil || toolCalls.ToolCalls[0].ID != "call_1" {
+		t.Fatalf("messages = %+v", result.Messages)
+	}
+	if want := []core.ReasoningBlock{{Type: reasoningBlockThoughtSignature, Data: "call_1", Signature: "sig-1"}}; !reflect.DeepEqual(toolCalls.ReasoningBlocks, want) {
+		t.Fatalf("reasoning blocks = %+v", toolCalls.ReasoningBlocks)
+	}
+}
+
+func TestChatReplaysClientToolResults(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Done."}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{
+			core.TextMessagePart{Role: core.RoleUser, Content: "Look up both."},
+			core.ToolCallMessagePart{
+				Role: core.RoleToolCall,
+				ToolCalls: []core.ToolCall{
+					{ID: "call_a", Name: "lookup", Arguments: `{"key":"a"}`},
+					{ID: "call_b", Name: "lookup", Arguments: map[string]any{"key": "b"}},
+				},
+				ReasoningBlocks: []core.ReasoningBlock{{Type: reasoningBlockThoughtSignature, Data: "call_a", Signature: "sig-a"}},
+			},
+			core.ToolResultMessagePart{Role: core.RoleToolResult, ToolCallID: "call_a", Content: `{"value":1}`},
+			core.ToolResultMessagePart{Role: core.RoleToolResult, ToolCallID: "call_b", Content: "two"},
+		},
+		Tools: []core.ToolUnion{core.ClientTool{Name: "lookup"}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	contents := request["contents"].([]any)
+	if len(contents) != 3 {
+		t.Fatalf("contents = %v", contents)
+	}
+	calls := contents[1].(map[string]any)["parts"].([]any)
+	if calls[0].(map[string]any)["thoughtSignature"] != "sig-a" || calls[1].(map[string]any)["thoughtSignature"] != nil {
+		t.Fatalf("function call parts = %v", calls)
+	}
+	if args := calls[0].(map[string]any)["functionCall"].(map[string]any)["args"].(map[string]any); args["key"] != "a" {
+		t.Fatalf("args = %v", args)
+	}
+
+	results := contents[2].(map[string]any)["parts"].([]any)
+	if len(results) != 2 {
+		t.Fatalf("function responses = %v", results)
+	}
+	first := results[0].(map[string]any)["functionResponse"].(map[string]any)
+	second := results[1].(map[string]any)["functionResponse"].(map[string]any)
+	if first["name"] != "lookup" || first["response"].(map[string]any)["value"] != float64(1) {
+		t.Fatalf("first response = %v", first)
+	}
+	if second["name"] != "lookup" || second["response"].(map[string]any)["output"] != "two" {
+		t.Fatalf("second response = %v", second)
+	}
+}
+
+func TestChatReturnsClientToolCalls(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Checking.","thought":true},{"functionCall":{"id":"fc-1","name":"lookup","args":{"key":"a"}}}]},"finishReason":"STOP"}],"responseId":"resp-2"}`))
+	}))
+	defer server.Close()
+
+	result, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages:   []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Look up a."}},
+		Tools:      []core.ToolUnion{core.ClientTool{Name: "lookup"}},
+		ToolChoice: &core.ToolChoice{Name: "lookup"},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.FinishReason != core.FinishReasonToolCalls || len(result.ToolCalls) != 1 || result.ToolCalls[0].ID != "fc-1" || result.Reasoning != "Checking." {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	last := result.Messages[len(result.Messages)-1].(core.ToolCallMessagePart)
+	if len(last.ReasoningBlocks) != 1 || last.ReasoningBlocks[0].Type != reasoningBlockThought {
+		t.Fatalf("reasoning blocks = %+v", last.ReasoningBlocks)
+	}
+}
+
+func TestChatMapsStructuredOutputAndToolChoice(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"{\"city\":\"Paris\"}"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	result, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages:   []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Capital of France?"}},
+		Tools:      []core.ToolUnion{core.ClientTool{Name: "lookup"}, core.ClientTool{Name: "search"}},
+		ToolChoice: &core.ToolChoice{Name: "search"},
+		Output:     &core.Schema{Name: "city", Schema: map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}}},
+		Thinking:   "disabled",
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Text != `{"city":"Paris"}` {
+		t.Fatalf("text = %q", result.Text)
+	}
+
+	config := request["generationConfig"].(map[string]any)
+	if config["responseMimeType"] != "application/json" || config["responseJsonSchema"].(map[string]any)["type"] != "object" {
+		t.Fatalf("generationConfig = %v", config)
+	}
+	if thinking := config["thinkingConfig"].(map[string]any); thinking["thinkingBudget"] != float64(0) || thinking["includeThoughts"] != nil {
+		t.Fatalf("thinkingConfig = %v", thinking)
+	}
+	calling := request["toolConfig"].(map[string]any)["functionCallingConfig"].(map[string]any)
+	if calling["mode"] != "ANY" || !reflect.DeepEqual(calling["allowedFunctionNames"], []any{"search"}) {
+		t.Fatalf("functionCallingConfig = %v", calling)
+	}
+
+	_, err = newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages:   []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		Tools:      []core.ToolUnion{core.ClientTool{Name: "lookup"}},
+		ToolChoice: &core.ToolChoice{Name: "missing"},
+	})
+	if err == nil || !strings.Contains(err.Error(), `tool choice "missing"`) {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
+
+func TestChatReturnsCandidates(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[
+			{"index":0,"content":{"role":"model","parts":[{"text":"Tagline A"}]},"finishReason":"STOP"},
+			{"index":1,"content":{"role":"model","parts":[{"text":"Tagline B"}]},"finishReason":"MAX_TOKENS"}]}`))
+	}))
+	defer server.Close()
+
+	count := int64(2)
+	result, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages:       []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Write a tagline."}},
+		CandidateCount: &count,
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if request["generationConfig"].(map[string]any)["candidateCount"] != float64(2) {
+		t.Fatalf("generationConfig = %v", request["generationConfig"])
+	}
+	if result.Text != "Tagline A" || len(result.Candidates) != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if second := result.Candidates[1]; second.Text != "Tagline B" || second.FinishReason != core.FinishReasonLength {
+		t.Fatalf("second candidate = %+v", second)
+	}
+}
+
+func TestChatReportsBlockedPrompt(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"promptFeedback":{"blockReason":"PROHIBITED_CONTENT"},"usageMetadata":{"promptTokenCount":7,"totalTokenCount":7}}`))
+	}))
+	defer server.Close()
+
+	result, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "..."}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.FinishReason != core.FinishReasonContentFilter || result.RawFinishReason != "PROHIBITED_CONTENT" || result.Usage.PromptTokens != 7 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestChatDecodesAPIErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		check  func(error) bool
+	}{
+		{
+			name:   "rate limit",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`,
+			check: func(err error) bool {
+				var rateLimit *core.RateLimitError
+				return errors.As(err, &rateLimit) && strings.Contains(rateLimit.Message, "Quota exceeded")
+			},
+		},
+		{
+			name:   "model not found",
+			status: http.StatusNotFound,
+			body:   `[{"error":{"code":404,"message":"Publisher Model gemini-0 was not found","status":"NOT_FOUND"}}]`,
+			check: func(err error) bool {
+				var notFound *core.ModelNotFoundError
+				return errors.As(err, &notFound)
+			},
+		},
+		{
+			name:   "invalid argument",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"code":400,"message":"Invalid JSON payload","status":"INVALID_ARGUMENT"}}`,
+			check: func(err error) bool {
+				var apiErr *core.APIError
+				return errors.As(err, &apiErr) && apiErr.Type == "INVALID_ARGUMENT" && !apiErr.Retryable &&
+					apiErr.Message == "vertex: API error (INVALID_ARGUMENT): Invalid JSON payload"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := newTestAdapter(server.URL).Chat(context.Background(), &core.ChatParams{
+				Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+			})
+			if !tt.check(err) {
+				t.Fatalf("Chat() error = %#v", err)
+			}
+		})
+	}
+}
+
+func TestModelPathAndBaseURL(t *testing.T) {
+	t.Parallel()
+
+	adapter := New("publishers/anthropic/models/claude-sonnet-4", WithProject("p"), WithLocation("global"))
+	if got := adapter.baseURL(); got != "https://aiplatform.googleapis.com/v1" {
+		t.Fatalf("baseURL() = %q", got)
+	}
+	if got := adapter.modelPath("generateContent"); got != "/projects/p/locations/global/publishers/anthropic/models/claude-sonnet-4:generateContent" {
+		t.Fatalf("modelPath() = %q", got)
+	}
+
+	adapter = New("projects/p/locations/us-east5/endpoints/123", WithLocation("us-east5"))
+	if got := adapter.baseURL(); got != "https://us-east5-aiplatform.googleapis.com/v1" {
+		t.Fatalf("baseURL() = %q", got)
+	}
+	if got := adapter.modelPath("predict"); got != "/projects/p/locations/us-east5/endpoints/123:predict" {
+		t.Fatalf("modelPath() = %q", got)
+	}
+}
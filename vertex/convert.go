@@ -0,0 +1,657 @@
+package vertex
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// Reasoning block types kept on core.ToolCallMessagePart. A thought
+// signature block holds the ID of the tool call it belongs to in Data.
+const (
+	reasoningBlockThought          = "thought"
+	reasoningBlockThoughtSignature = "thought_signature"
+)
+
+// toContents converts params into contents and the system instruction.
+// System prompts and system messages form the system instruction. Tool
+// results that follow each other are sent together in one user turn, which
+// Gemini needs to match them with parallel function calls.
+func toContents(params *core.ChatParams) ([]content, *content, error) {
+	if params == nil {
+		return nil, nil, errors.New("vertex: chat params are required")
+	}
+
+	var system []part
+	for _, prompt := range params.SystemPrompts {
+		if prompt = strings.TrimSpace(prompt); prompt != "" {
+			system = append(system, part{Text: prompt})
+		}
+	}
+
+	contents := make([]content, 0, len(params.Messages))
+	callNames := make(map[string]string)
+	for i, union := range params.Messages {
+		converted, isSystem, err := toContent(union, callNames)
+		if err != nil {
+			return nil, nil, fmt.Errorf("vertex: invalid message at index %d: %w", i, err)
+		}
+		if isSystem {
+			system = append(system, converted.Parts...)
+			continue
+		}
+
+		if len(contents) > 0 && isFunctionResponse(converted) && isFunctionResponse(contents[len(contents)-1]) {
+			last := &contents[len(contents)-1]
+			last.Parts = append(last.Parts, converted.Parts...)
+			continue
+		}
+		contents = append(contents, converted)
+	}
+
+	if len(system) == 0 {
+		return contents, nil, nil
+	}
+	return contents, &content{Parts: system}, nil
+}
+
+func isFunctionResponse(c content) bool {
+	return len(c.Parts) > 0 && c.Parts[0].FunctionResponse != nil
+}
+
+// toContent converts one message. It reports true for system messages,
+// whose parts join the system instruction.
+func toContent(union core.MessageUnion, callNames map[string]string) (content, bool, error) {
+	switch msg := union.(type) {
+	case core.TextMessagePart:
+		return textContent(msg.Role, msg.Content)
+	case *core.TextMessagePart:
+		if msg == nil {
+			return content{}, false, errors.New("text message is nil")
+		}
+		return textContent(msg.Role, msg.Content)
+
+	case core.ContentMessagePart:
+		return contentParts(msg.Role, msg.Parts)
+	case *core.ContentMessagePart:
+		if msg == nil {
+			return content{}, false, errors.New("content message is nil")
+		}
+		return contentParts(msg.Role, msg.Parts)
+
+	case core.AssistantToolCallMessagePart:
+		converted, err := functionCallContent(msg.Role, msg.ToolCalls, msg.ReasoningBlocks, callNames)
+		return converted, false, err
+	case *core.AssistantToolCallMessagePart:
+		if msg == nil {
+			return content{}, false, errors.New("assistant tool call message is nil")
+		}
+		converted, err := functionCallContent(msg.Role, msg.ToolCalls, msg.ReasoningBlocks, callNames)
+		return converted, false, err
+
+	case core.ToolResultMessagePart:
+		converted, err := functionResponseContent(msg.Role, msg.ToolCallID, msg.Name, msg.Content, callNames)
+		return converted, false, err
+	case *core.ToolResultMessagePart:
+		if msg == nil {
+			return content{}, false, errors.New("tool result message is nil")
+		}
+		converted, err := functionResponseContent(msg.Role, msg.ToolCallID, msg.Name, msg.Content, callNames)
+		return converted, false, err
+	}
+
+	return content{}, false, fmt.Errorf("unsupported message type %T", union)
+}
+
+func textContent(role, text string) (content, bool, error) {
+	normalizedRole, isSystem, err := normalizeRole(role)
+	if err != nil {
+		return content{}, false, err
+	}
+	if text == "" {
+		return content{}, false, errors.New("text message content is required")
+	}
+
+	return content{Role: normalizedRole, Parts: []part{{Text: text}}}, isSystem, nil
+}
+
+func contentParts(role string, parts []core.ContentPart) (content, bool, error) {
+	normalizedRole, isSystem, err := normalizeRole(role)
+	if err != nil {
+		return content{}, false, err
+	}
+	if len(parts) == 0 {
+		return content{}, false, errors.New("content message must include at least one content part")
+	}
+
+	converted := make([]part, 0, len(parts))
+	for i, item := range parts {
+		value, ok, err := toPart(item)
+		if err != nil {
+			return content{}, false, fmt.Errorf("content part at index %d: %w", i, err)
+		}
+		if ok {
+			converted = append(converted, value)
+		}
+	}
+	if len(converted) == 0 {
+		return content{}, false, errors.New("content message has no content for vertex")
+	}
+
+	return content{Role: normalizedRole, Parts: converted}, isSystem, nil
+}
+
+// toPart converts one content part. It reports false for parts that are
+// skipped, such as raw parts for other providers.
+func toPart(item core.ContentPart) (part, bool, error) {
+	switch typed := item.(type) {
+	case core.TextPart:
+		return part{Text: typed.Text}, typed.Text != "", nil
+	case *core.TextPart:
+		if typed == nil {
+			return part{}, false, errors.New("text part is nil")
+		}
+		return toPart(*typed)
+
+	case core.ImagePart:
+		value, err := mediaPart(typed.Source, "image")
+		return value, err == nil, err
+	case *core.ImagePart:
+		if typed == nil {
+			return part{}, false, errors.New("image part is nil")
+		}
+		return toPart(*typed)
+
+	case core.AudioPart:
+		value, err := mediaPart(typed.Source, "audio")
+		return value, err == nil, err
+	case *core.AudioPart:
+		if typed == nil {
+			return part{}, false, errors.New("audio part is nil")
+		}
+		return toPart(*typed)
+
+	case core.DocumentPart:
+		value, err := mediaPart(typed.Source, "document")
+		return value, err == nil, err
+	case *core.DocumentPart:
+		if typed == nil {
+			return part{}, false, errors.New("document part is nil")
+		}
+		return toPart(*typed)
+
+	case core.FilePart:
+		return filePart(typed)
+	case *core.FilePart:
+		if typed == nil {
+			return part{}, false, errors.New("file part is nil")
+		}
+		return filePart(*typed)
+
+	case core.SearchResultPart:
+		lines := make([]string, 0, len(typed.Texts)+2)
+		if title := strings.TrimSpace(typed.Title); title != "" {
+			lines = append(lines, title)
+		}
+		if source := strings.TrimSpace(typed.Source); source != "" {
+			lines = append(lines, source)
+		}
+		lines = append(lines, typed.Texts...)
+		return part{Text: strings.Join(lines, "\n\n")}, true, nil
+	case *core.SearchResultPart:
+		if typed == nil {
+			return part{}, false, errors.New("search result part is nil")
+		}
+		return toPart(*typed)
+
+	case core.RawPart:
+		if !strings.EqualFold(strings.TrimSpace(typed.Provider), providerName) {
+			return part{}, false, nil
+		}
+		if !json.Valid(typed.Payload) || !bytes.HasPrefix(bytes.TrimSpace(typed.Payload), []byte("{")) {
+			return part{}, false, errors.New("vertex: raw part payload must be a JSON object")
+		}
+		return part{Raw: typed.Payload}, true, nil
+	case *core.RawPart:
+		if typed == nil {
+			return part{}, false, errors.New("raw part is nil")
+		}
+		return toPart(*typed)
+	}
+
+	return part{}, false, fmt.Errorf("unsupported content part type %T", item)
+}
+
+// mediaPart sends inline data as base64 and URLs, such as gs:// objects, as
+// file data.
+func mediaPart(source core.Source, kind string) (part, error) {
+	switch typed := source.(type) {
+	case core.DataSource:
+		data := strings.TrimSpace(typed.Data)
+		if data == "" {
+			return part{}, fmt.Errorf("%s data is required", kind)
+		}
+		if strings.HasPrefix(data, "data:") {
+			return part{}, fmt.Errorf("%s data must be raw base64", kind)
+		}
+		mimeType := strings.TrimSpace(typed.MimeType)
+		if mimeType == "" {
+			return part{}, fmt.Errorf("%s mime type is required", kind)
+		}
+		return part{InlineData: &blob{MimeType: mimeType, Data: data}}, nil
+	case *core.DataSource:
+		if typed == nil {
+			return part{}, fmt.Errorf("%s data source is nil", kind)
+		}
+		return mediaPart(*typed, kind)
+
+	case core.URLSource:
+		uri := strings.TrimSpace(typed.URL)
+		if uri == "" {
+			return part{}, fmt.Errorf("%s URL is required", kind)
+		}
+		return part{FileData: &fileData{MimeType: strings.TrimSpace(typed.MimeType), FileURI: uri}}, nil
+	case *core.URLSource:
+		if typed == nil {
+			return part{}, fmt.Errorf("%s URL source is nil", kind)
+		}
+		return mediaPart(*typed, kind)
+	}
+
+	return part{}, fmt.Errorf("unsupported %s source type %T", kind, source)
+}
+
+// filePart sends file bytes inline. A FileID is sent as a file URI, such as
+// a gs:// object.
+func filePart(file core.FilePart) (part, bool, error) {
+	mimeType := strings.TrimSpace(file.MimeType)
+	if mimeType == "" {
+		return part{}, false, errors.New("file mime type is required")
+	}
+	if id := strings.TrimSpace(file.FileID); id != "" {
+		return part{FileData: &fileData{MimeType: mimeType, FileURI: id}}, true, nil
+	}
+	if len(file.Data) == 0 {
+		return part{}, false, errors.New("file data or file ID is required")
+	}
+	return part{InlineData: &blob{MimeType: mimeType, Data: base64.StdEncoding.EncodeToString(file.Data)}}, true, nil
+}
+
+// functionCallContent converts tool calls into a model turn. Thought
+// signature blocks are sent back on the function call they belong to, which
+// Gemini needs to continue its reasoning.
+func functionCallContent(role string, calls []core.ToolCall, reasoning []core.ReasoningBlock, callNames map[string]string) (content, error) {
+	role = strings.TrimSpace(strings.ToLower(role))
+	if role == "" {
+		role = core.RoleToolCall
+	}
+	if role != core.RoleToolCall && role != core.RoleAssistant {
+		return content{}, fmt.Errorf("tool call message role must be %q or %q, got %q", core.RoleToolCall, core.RoleAssistant, role)
+	}
+	if len(calls) == 0 {
+		return content{}, errors.New("assistant tool call message must include at least one tool call")
+	}
+
+	signatures := make(map[string]string)
+	for _, block := range reasoning {
+		if block.Type == reasoningBlockThoughtSignature && block.Signature != "" {
+			signatures[block.Data] = block.Signature
+		}
+	}
+
+	parts := make([]part, 0, len(calls))
+	for i, call := range calls {
+		name := strings.TrimSpace(call.Name)
+		if name == "" {
+			return content{}, fmt.Errorf("tool call at index %d is missing a name", i)
+		}
+
+		args, err := toArgs(call.Arguments)
+		if err != nil {
+			return content{}, fmt.Errorf("tool call %q arguments: %w", name, err)
+		}
+
+		callNames[call.ID] = name
+		parts = append(parts, part{
+			FunctionCall:     &functionCall{Name: name, Args: args},
+			ThoughtSignature: signatures[call.ID],
+		})
+	}
+
+	return content{Role: "model", Parts: parts}, nil
+}
+
+// toArgs converts tool call arguments into the object Gemini expects.
+func toArgs(arguments any) (map[string]any, error) {
+	var raw []byte
+	switch typed := arguments.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case map[string]any:
+		return typed, nil
+	case string:
+		if strings.TrimSpace(typed) == "" {
+			return map[string]any{}, nil
+		}
+		raw = []byte(typed)
+	case json.RawMessage:
+		raw = typed
+	default:
+		encoded, err := json.Marshal(arguments)
+		if err != nil {
+			return nil, err
+		}
+		raw = encoded
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, errors.New("arguments must be a JSON object")
+	}
+	return args, nil
+}
+
+// functionResponseContent converts a tool result. Gemini matches results to
+// calls by name, which is taken from the earlier tool call when the result
+// has none. A result that is a JSON object is sent as is; other results are
+// sent as {"output": ...}.
+func functionResponseContent(role, toolCallID, name, result string, callNames map[string]string) (content, error) {
+	role = strings.TrimSpace(strings.ToLower(role))
+	if role == "" {
+		role = core.RoleToolResult
+	}
+	if role != core.RoleToolResult && role != "tool" {
+		return content{}, fmt.Errorf("tool result message role must be %q or %q, got %q", core.RoleToolResult, "tool", role)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = callNames[strings.TrimSpace(toolCallID)]
+	}
+	if name == "" {
+		return content{}, errors.New("tool result message needs the tool name or the ID of an earlier tool call")
+	}
+
+	return content{Role: core.RoleUser, Parts: []part{functionResponsePart(name, result)}}, nil
+}
+
+func functionResponsePart(name, result string) part {
+	var response map[string]any
+	if err := json.Unmarshal([]byte(result), &response); err != nil || response == nil {
+		response = map[string]any{"output": result}
+	}
+	return part{FunctionResponse: &functionResponse{Name: name, Response: response}}
+}
+
+// normalizeRole maps a role onto "user" or "model". It reports true for the
+// system role.
+func normalizeRole(role string) (string, bool, error) {
+	switch normalized := strings.ToLower(strings.TrimSpace(role)); normalized {
+	case "":
+		return "", false, errors.New("message role is required")
+	case core.RoleSystem:
+		return "", true, nil
+	case core.RoleUser:
+		return core.RoleUser, false, nil
+	case core.RoleAssistant, "model":
+		return "model", false, nil
+	default:
+		return "", false, fmt.Errorf("unsupported role %q", role)
+	}
+}
+
+func toTools(params *core.ChatParams) ([]tool, map[string]core.ServerTool, map[string]struct{}, error) {
+	if params == nil || len(params.Tools) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	declarations := make([]functionDeclaration, 0, len(params.Tools))
+	serverTools := make(map[string]core.ServerTool)
+	clientTools := make(map[string]struct{})
+	seenNames := make(map[string]struct{})
+
+	for i, union := range params.Tools {
+		switch toolValue := union.(type) {
+		case core.ServerTool:
+			declaration, serverTool, err := newServerTool(toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("vertex: invalid server tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, serverTool.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			declarations = append(declarations, declaration)
+			serverTools[serverTool.Name] = serverTool
+
+		case *core.ServerTool:
+			if toolValue == nil {
+				return nil, nil, nil, fmt.Errorf("vertex: server tool at index %d is nil", i)
+			}
+			declaration, serverTool, err := newServerTool(*toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("vertex: invalid server tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, serverTool.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			declarations = append(declarations, declaration)
+			serverTools[serverTool.Name] = serverTool
+
+		case core.ClientTool:
+			declaration, err := newClientTool(toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("vertex: invalid client tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, declaration.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			declarations = append(declarations, declaration)
+			clientTools[declaration.Name] = struct{}{}
+
+		case *core.ClientTool:
+			if toolValue == nil {
+				return nil, nil, nil, fmt.Errorf("vertex: client tool at index %d is nil", i)
+			}
+			declaration, err := newClientTool(*toolValue)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("vertex: invalid client tool at index %d: %w", i, err)
+			}
+			if err := assertNewToolName(seenNames, declaration.Name); err != nil {
+				return nil, nil, nil, err
+			}
+			declarations = append(declarations, declaration)
+			clientTools[declaration.Name] = struct{}{}
+
+		default:
+			return nil, nil, nil, fmt.Errorf("vertex: unsupported tool type %T", union)
+		}
+	}
+
+	return []tool{{FunctionDeclarations: declarations}}, serverTools, clientTools, nil
+}
+
+func newServerTool(toolValue core.ServerTool) (functionDeclaration, core.ServerTool, error) {
+	name := strings.TrimSpace(toolValue.Name)
+	if name == "" {
+		return functionDeclaration{}, core.ServerTool{}, errors.New("tool name is required")
+	}
+	if toolValue.Handler == nil {
+		return functionDeclaration{}, core.ServerTool{}, fmt.Errorf("tool %q handler is required", name)
+	}
+
+	toolValue.Name = name
+	return newDeclaration(name, toolValue.Description, toolValue.Parameters), toolValue, nil
+}
+
+func newClientTool(toolValue core.ClientTool) (functionDeclaration, error) {
+	name := strings.TrimSpace(toolValue.Name)
+	if name == "" {
+		return functionDeclaration{}, errors.New("tool name is required")
+	}
+
+	return newDeclaration(name, toolValue.Description, toolValue.Parameters), nil
+}
+
+func newDeclaration(name, description string, parameters map[string]any) functionDeclaration {
+	if parameters == nil {
+		parameters = map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		}
+	}
+
+	return functionDeclaration{Name: name, Description: description, ParametersJSONSchema: parameters}
+}
+
+func assertNewToolName(seen map[string]struct{}, name string) error {
+	if _, exists := seen[name]; exists {
+		return fmt.Errorf("vertex: duplicate tool name %q", name)
+	}
+	seen[name] = struct{}{}
+	return nil
+}
+
+// toToolConfig maps the tool choice onto a function calling mode. A named
+// choice allows only that function and requires a call.
+func toToolConfig(params *core.ChatParams, tools []tool) (*toolConfig, error) {
+	if params == nil || params.ToolChoice == nil || len(tools) == 0 {
+		return nil, nil
+	}
+
+	if name := strings.TrimSpace(params.ToolChoice.Name); name != "" {
+		for _, declaration := range tools[0].FunctionDeclarations {
+			if declaration.Name == name {
+				return &toolConfig{FunctionCallingConfig: functionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{name}}}, nil
+			}
+		}
+		return nil, fmt.Errorf("vertex: tool choice %q does not match a tool", name)
+	}
+
+	switch mode := strings.TrimSpace(params.ToolChoice.Mode); mode {
+	case "":
+		return nil, nil
+	case core.ToolChoiceAuto:
+		return &toolConfig{FunctionCallingConfig: functionCallingConfig{Mode: "AUTO"}}, nil
+	case core.ToolChoiceNone:
+		return &toolConfig{FunctionCallingConfig: functionCallingConfig{Mode: "NONE"}}, nil
+	case core.ToolChoiceRequired:
+		return &toolConfig{FunctionCallingConfig: functionCallingConfig{Mode: "ANY"}}, nil
+	default:
+		return nil, fmt.Errorf("vertex: unsupported tool choice mode %q", mode)
+	}
+}
+
+// toGenerationConfig maps sampling, output, and thinking settings. It
+// returns nil when none are set.
+func toGenerationConfig(params *core.ChatParams) (*generationConfig, error) {
+	thinking, err := toThinkingConfig(params)
+	if err != nil {
+		return nil, err
+	}
+
+	config := generationConfig{
+		Temperature:     params.Temperature,
+		TopP:            params.TopP,
+		TopK:            params.TopK,
+		MaxOutputTokens: maxTokens(params),
+		StopSequences:   params.StopSequences,
+		Seed:            params.Seed,
+		ThinkingConfig:  thinking,
+	}
+	if params.CandidateCount != nil && *params.CandidateCount > 1 {
+		config.CandidateCount = params.CandidateCount
+	}
+	if params.Output != nil {
+		config.ResponseMimeType = "application/json"
+		config.ResponseJSONSchema = params.Output.Schema
+	}
+
+	if config.Temperature == nil && config.TopP == nil && config.TopK == nil && config.CandidateCount == nil &&
+		config.MaxOutputTokens == nil && len(config.StopSequences) == 0 && config.Seed == nil &&
+		config.ResponseMimeType == "" && config.ThinkingConfig == nil {
+		return nil, nil
+	}
+	return &config, nil
+}
+
+// toThinkingConfig derives the thinking budget from ChatParams.Thinking,
+// falling back to ChatParams.ReasoningEffort. Thinking accepts
+// "true"/"enabled" for a dynamic budget, "false"/"disabled", an effort level
+// ("low", "medium", "high"), or an explicit token budget such as "8192".
+// Thoughts are included in the response whenever thinking is enabled.
+func toThinkingConfig(params *core.ChatParams) (*thinkingConfig, error) {
+	raw := strings.ToLower(strings.TrimSpace(params.Thinking))
+	switch raw {
+	case "":
+		if budget := thinkingBudgetForEffort(strings.ToLower(strings.TrimSpace(params.ReasoningEffort))); budget > 0 {
+			return &thinkingConfig{IncludeThoughts: true, ThinkingBudget: &budget}, nil
+		}
+		return nil, nil
+	case "false", "disabled", "off", "none":
+		budget := int64(0)
+		return &thinkingConfig{ThinkingBudget: &budget}, nil
+	case "true", "enabled", "on", "dynamic":
+		budget := int64(-1)
+		return &thinkingConfig{IncludeThoughts: true, ThinkingBudget: &budget}, nil
+	}
+
+	if budget := thinkingBudgetForEffort(raw); budget > 0 {
+		return &thinkingConfig{IncludeThoughts: true, ThinkingBudget: &budget}, nil
+	}
+
+	budget, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || budget <= 0 {
+		return nil, fmt.Errorf("vertex: unsupported thinking value %q", params.Thinking)
+	}
+	return &thinkingConfig{IncludeThoughts: true, ThinkingBudget: &budget}, nil
+}
+
+func thinkingBudgetForEffort(effort string) int64 {
+	switch effort {
+	case "minimal", "low":
+		return 1024
+	case "medium":
+		return defaultThinkingBudget
+	case "high":
+		return 24576
+	default:
+		return 0
+	}
+}
+
+func maxTokens(params *core.ChatParams) *int64 {
+	if params.MaxTokens != nil && *params.MaxTokens > 0 {
+		return params.MaxTokens
+	}
+	if params.MaxOutputTokens != nil && *params.MaxOutputTokens > 0 {
+		return params.MaxOutputTokens
+	}
+	if params.MaxLength > 0 {
+		value := params.MaxLength
+		return &value
+	}
+	return nil
+}
+
+func modelOptions(params *core.ChatParams) map[string]any {
+	if params == nil || len(params.ModelOptions) == 0 {
+		return nil
+	}
+	return params.ModelOptions
+}
+
+func maxLoops(params *core.ChatParams, hasServerTools bool) int {
+	if !hasServerTools {
+		return 1
+	}
+	if params != nil && params.MaxAgenticLoops > 0 {
+		return int(params.MaxAgenticLoops)
+	}
+	return defaultMaxAgenticLoops
+}
@@ -0,0 +1,138 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// Embed creates one embedding vector for params.Input.
+//
+// ModelOptions may set "task_type", such as "RETRIEVAL_QUERY", "title" for
+// "RETRIEVAL_DOCUMENT" inputs, and "auto_truncate".
+func (a *Adapter) Embed(ctx context.Context, params *core.EmbedParams) (*core.EmbedResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("vertex: embed params are required")
+	}
+
+	input := strings.TrimSpace(params.Input)
+	if input == "" {
+		return nil, errors.New("vertex: embed input is required")
+	}
+
+	vectors, usage, err := a.embed(ctx, []string{input}, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.EmbedResult{Embedding: vectors[0], Usage: usage}, nil
+}
+
+// EmbedMany creates embedding vectors for params.Inputs. Gemini embedding
+// models accept one input per request, so EmbedMany sends one request per
+// input for them.
+func (a *Adapter) EmbedMany(ctx context.Context, params *core.EmbedManyParams) (*core.EmbedManyResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, errors.New("vertex: embed many params are required")
+	}
+	if len(params.Inputs) == 0 {
+		return nil, errors.New("vertex: embed many inputs are required")
+	}
+
+	inputs := make([]string, 0, len(params.Inputs))
+	for i, input := range params.Inputs {
+		trimmed := strings.TrimSpace(input)
+		if trimmed == "" {
+			return nil, fmt.Errorf("vertex: embed many input at index %d is empty", i)
+		}
+		inputs = append(inputs, trimmed)
+	}
+
+	vectors, usage, err := a.embed(ctx, inputs, params.Dimensions, params.ModelOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.EmbedManyResult{Embeddings: vectors, Usage: usage}, nil
+}
+
+func (a *Adapter) embed(ctx context.Context, inputs []string, dimensions *int64, options map[string]any) ([][]float64, *core.Usage, error) {
+	if dimensions != nil && *dimensions <= 0 {
+		return nil, nil, errors.New("vertex: embed dimensions must be greater than zero")
+	}
+
+	var instance embedInstance
+	if value, ok := options["task_type"].(string); ok {
+		instance.TaskType = strings.TrimSpace(value)
+	}
+	if value, ok := options["title"].(string); ok {
+		instance.Title = strings.TrimSpace(value)
+	}
+	parameters := &embedParameters{OutputDimensionality: dimensions}
+	if value, ok := options["auto_truncate"].(bool); ok {
+		parameters.AutoTruncate = &value
+	}
+	if parameters.OutputDimensionality == nil && parameters.AutoTruncate == nil {
+		parameters = nil
+	}
+
+	batchSize := len(inputs)
+	if isGeminiEmbedModel(a.Model) {
+		batchSize = 1
+	}
+
+	vectors := make([][]float64, 0, len(inputs))
+	usage := &core.Usage{}
+	for start := 0; start < len(inputs); start += batchSize {
+		batch := inputs[start:min(start+batchSize, len(inputs))]
+		request := predictRequest{Instances: make([]embedInstance, 0, len(batch)), Parameters: parameters}
+		for _, input := range batch {
+			item := instance
+			item.Content = input
+			request.Instances = append(request.Instances, item)
+		}
+
+		apiRequest, err := a.request(ctx, a.modelPath("predict"), "embeddings", &request)
+		if err != nil {
+			return nil, nil, err
+		}
+		var response predictResponse
+		if _, err := a.transport().Send(ctx, apiRequest, &response); err != nil {
+			return nil, nil, err
+		}
+
+		for _, prediction := range response.Predictions {
+			vectors = append(vectors, prediction.Embeddings.Values)
+			usage.PromptTokens += int64(prediction.Embeddings.Statistics.TokenCount)
+		}
+	}
+	usage.TotalTokens = usage.PromptTokens
+
+	if len(vectors) != len(inputs) {
+		return nil, nil, fmt.Errorf("vertex: embeddings response count mismatch: expected %d, got %d", len(inputs), len(vectors))
+	}
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, nil, fmt.Errorf("vertex: embeddings response did not include a vector at index %d", i)
+		}
+		if dimensions != nil && int64(len(vector)) != *dimensions {
+			return nil, nil, &core.EmbeddingDimensionError{Expected: *dimensions, Actual: int64(len(vector)), Index: i}
+		}
+	}
+	return vectors, usage, nil
+}
+
+// isGeminiEmbedModel reports whether model is a Gemini embedding model, such
+// as "gemini-embedding-001", which embeds one input per request.
+func isGeminiEmbedModel(model string) bool {
+	return strings.Contains(strings.ToLower(model), "gemini-embedding")
+}
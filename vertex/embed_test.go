@@ -0,0 +1,100 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestEmbedManySendsInstancesInOneRequest(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/projects/test-project/locations/europe-west4/publishers/google/models/text-embedding-005:predict" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		instances := request["instances"].([]any)
+		if len(instances) != 2 || instances[1].(map[string]any)["content"] != "world" || instances[0].(map[string]any)["task_type"] != "RETRIEVAL_QUERY" {
+			t.Errorf("instances = %v", instances)
+		}
+		if parameters := request["parameters"].(map[string]any); parameters["outputDimensionality"] != float64(2) {
+			t.Errorf("parameters = %v", parameters)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"predictions":[
+			{"embeddings":{"values":[0.1,0.2],"statistics":{"token_count":1,"truncated":false}}},
+			{"embeddings":{"values":[0.3,0.4],"statistics":{"token_count":2,"truncated":false}}}]}`))
+	}))
+	defer server.Close()
+
+	adapter := New("text-embedding-005", WithProject("test-project"), WithLocation("europe-west4"), WithAccessToken("test-token"), WithBaseURL(server.URL))
+	dimensions := int64(2)
+	result, err := adapter.EmbedMany(context.Background(), &core.EmbedManyParams{
+		Inputs:       []string{"hello", "world"},
+		Dimensions:   &dimensions,
+		ModelOptions: map[string]any{"task_type": "RETRIEVAL_QUERY"},
+	})
+	if err != nil {
+		t.Fatalf("EmbedMany() error = %v", err)
+	}
+	if requests != 1 || len(result.Embeddings) != 2 || result.Embeddings[1][1] != 0.4 || result.Usage.PromptTokens != 3 {
+		t.Fatalf("unexpected result %+v after %d requests", result, requests)
+	}
+}
+
+func TestEmbedManySendsOneInputPerRequestForGeminiModels(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var request predictRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if len(request.Instances) != 1 || request.Parameters != nil {
+			t.Errorf("request = %+v", request)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"predictions":[{"embeddings":{"values":[0.5,0.6,0.7],"statistics":{"token_count":4}}}]}`))
+	}))
+	defer server.Close()
+
+	adapter := New("gemini-embedding-001", WithProject("test-project"), WithAccessToken("test-token"), WithBaseURL(server.URL))
+	result, err := adapter.EmbedMany(context.Background(), &core.EmbedManyParams{Inputs: []string{"a", "b", "c"}})
+	if err != nil {
+		t.Fatalf("EmbedMany() error = %v", err)
+	}
+	if requests != 3 || len(result.Embeddings) != 3 || result.Usage.TotalTokens != 12 {
+		t.Fatalf("unexpected result %+v after %d requests", result, requests)
+	}
+}
+
+func TestEmbedChecksDimensions(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"predictions":[{"embeddings":{"values":[0.1,0.2,0.3]}}]}`))
+	}))
+	defer server.Close()
+
+	adapter := New("text-embedding-005", WithProject("test-project"), WithAccessToken("test-token"), WithBaseURL(server.URL))
+	dimensions := int64(2)
+	_, err := adapter.Embed(context.Background(), &core.EmbedParams{Input: "hello", Dimensions: &dimensions})
+	var dimensionErr *core.EmbeddingDimensionError
+	if !errors.As(err, &dimensionErr) || dimensionErr.Actual != 3 {
+		t.Fatalf("Embed() error = %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package vertex
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func init() {
+	core.RegisterProvider(providerName, newFromConfig)
+}
+
+// newFromConfig builds an adapter for core.NewAdapter. It supports the
+// options timeout, project, location, credentials_file, and access_token.
+// The API key is used as an access token.
+func newFromConfig(config core.ProviderConfig) (core.TextAdapter, error) {
+	opts := []Option{WithBaseURL(config.BaseURL), WithAccessToken(config.APIKey)}
+	for name, value := range config.Options {
+		switch name {
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			opts = append(opts, WithTimeout(timeout))
+		case "project":
+			opts = append(opts, WithProject(value))
+		case "location":
+			opts = append(opts, WithLocation(value))
+		case "credentials_file":
+			data, err := os.ReadFile(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			if _, err := ParseCredentials(data); err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			opts = append(opts, WithCredentialsJSON(data))
+		case "access_token":
+			opts = append(opts, WithAccessToken(value))
+		default:
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+
+	return New(config.Model, opts...), nil
+}
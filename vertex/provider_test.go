@@ -0,0 +1,55 @@
+package vertex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/accesstoken"
+)
+
+func TestFromDSN(t *testing.T) {
+	t.Parallel()
+
+	adapter, err := core.FromDSN("vertex://gemini-2.5-pro?project=my-project&location=europe-west4&access_token=ya29.token&timeout=30s")
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	vertex := adapter.(*Adapter)
+	if vertex.Model != "gemini-2.5-pro" || vertex.Project != "my-project" || vertex.Location != "europe-west4" || vertex.HTTPClient.Timeout != 30*time.Second {
+		t.Fatalf("adapter = %+v", vertex)
+	}
+	if vertex.TokenSource != accesstoken.Static("ya29.token") {
+		t.Fatalf("token source = %#v", vertex.TokenSource)
+	}
+	if _, err := core.FromDSN("vertex://gemini-2.5-pro?region=us-central1"); err == nil {
+		t.Fatal("FromDSN() expected error for an unknown option")
+	}
+}
+
+func TestFromDSNReadsCredentialsFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	data := []byte(`{"type":"authorized_user","client_id":"client","client_secret":"secret","refresh_token":"refresh"}`)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	adapter, err := core.FromDSN("vertex://gemini-2.5-flash?credentials_file=" + path)
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	if string(adapter.(*Adapter).CredentialsJSON) != string(data) {
+		t.Fatalf("credentials = %s", adapter.(*Adapter).CredentialsJSON)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"type":"external_account"}`), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := core.FromDSN("vertex://gemini-2.5-flash?credentials_file=" + path); err == nil {
+		t.Fatal("FromDSN() expected error for unsupported credentials")
+	}
+}
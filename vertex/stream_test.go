@@ -0,0 +1,210 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+const testStreamPath = "/projects/test-project/locations/europe-west4/publishers/google/models/gemini-2.5-flash:streamGenerateContent"
+
+func writeEvents(w http.ResponseWriter, events ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, event := range events {
+		_, _ = w.Write([]byte("data: " + event + "\r\n\r\n"))
+	}
+}
+
+func collect(t *testing.T, stream <-chan core.StreamChunk) []core.StreamChunk {
+	t.Helper()
+
+	var chunks []core.StreamChunk
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) == 0 {
+		t.Fatal("stream returned no chunks")
+	}
+	return chunks
+}
+
+func TestChatStreamEmitsThoughtsContentAndUsage(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != testStreamPath || r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("unexpected URL %q", r.URL.String())
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-token" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		writeEvents(w,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Thinking it over.","thought":true}]}}],"responseId":"resp-s"}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]}}],"responseId":"resp-s"}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"lo!"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":4,"candidatesTokenCount":2,"thoughtsTokenCount":5,"totalTokenCount":11},"responseId":"resp-s"}`,
+		)
+	}))
+	defer server.Close()
+
+	stream, err := newTestAdapter(server.URL).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		Thinking: "enabled",
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	chunks := collect(t, stream)
+
+	var content strings.Builder
+	var reasoning string
+	for _, chunk := range chunks {
+		switch chunk.Type {
+		case core.StreamChunkContent:
+			content.WriteString(chunk.Delta)
+		case core.StreamChunkReasoning:
+			reasoning = chunk.Reasoning
+		case core.StreamChunkError:
+			t.Fatalf("stream error: %s", chunk.Error)
+		}
+	}
+	if content.String() != "Hello!" || reasoning != "Thinking it over." {
+		t.Fatalf("content = %q, reasoning = %q", content.String(), reasoning)
+	}
+
+	done := chunks[len(chunks)-1]
+	if done.Type != core.StreamChunkDone || done.FinishReason != core.FinishReasonStop || done.RequestID != "resp-s" {
+		t.Fatalf("done chunk = %+v", done)
+	}
+	if done.Usage == nil || done.Usage.CompletionTokens != 7 || done.Usage.ReasoningTokens != 5 || done.Usage.TotalTokens != 11 {
+		t.Fatalf("usage = %+v", done.Usage)
+	}
+}
+
+func TestChatStreamRunsServerTools(t *testing.T) {
+	t.Parallel()
+
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		requests = append(requests, request)
+		if len(requests) == 1 {
+			writeEvents(w,
+				`{"candidates":[{"content":{"role":"model","parts":[{"text":"Let me check.","thought":true}]}}]}`,
+				`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{"city":"Paris"}},"thoughtSignature":"sig-s"}]},"finishReason":"STOP"}]}`,
+			)
+			return
+		}
+		writeEvents(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"Sunny."}]},"finishReason":"STOP"}]}`)
+	}))
+	defer server.Close()
+
+	stream, err := newTestAdapter(server.URL).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather?"}},
+		Tools: []core.ToolUnion{core.ServerTool{
+			Name:    "get_weather",
+			Handler: func(any) (string, error) { return `{"sky":"clear"}`, nil },
+		}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	chunks := collect(t, stream)
+
+	var call *core.ToolCall
+	var result string
+	for _, chunk := range chunks {
+		switch chunk.Type {
+		case core.StreamChunkToolCall:
+			call = chunk.ToolCall
+		case core.StreamChunkToolResult:
+			result = chunk.Content
+		case core.StreamChunkError:
+			t.Fatalf("stream error: %s", chunk.Error)
+		}
+	}
+	if call == nil || call.ID != "call_1" || call.Name != "get_weather" || result != `{"sky":"clear"}` {
+		t.Fatalf("call = %+v, result = %q", call, result)
+	}
+	if done := chunks[len(chunks)-1]; done.Type != core.StreamChunkDone || done.Reasoning != "Let me check." {
+		t.Fatalf("done chunk = %+v", done)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d", len(requests))
+	}
+	contents := requests[1]["contents"].([]any)
+	modelParts := contents[1].(map[string]any)["parts"].([]any)
+	if len(modelParts) != 2 || modelParts[0].(map[string]any)["thought"] != true || modelParts[1].(map[string]any)["thoughtSignature"] != "sig-s" {
+		t.Fatalf("model parts = %v", modelParts)
+	}
+	response := contents[2].(map[string]any)["parts"].([]any)[0].(map[string]any)["functionResponse"].(map[string]any)
+	if response["name"] != "get_weather" || response["response"].(map[string]any)["sky"] != "clear" {
+		t.Fatalf("functionResponse = %v", response)
+	}
+}
+
+func TestChatStreamEmitsPartialJSON(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"{\"city\":\"Pa"}]}}]}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"ris\"}"}]},"finishReason":"STOP"}]}`,
+		)
+	}))
+	defer server.Close()
+
+	stream, err := newTestAdapter(server.URL).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Capital of France?"}},
+		Output:   &core.Schema{Schema: map[string]any{"type": "object"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var partials []string
+	for _, chunk := range collect(t, stream) {
+		if chunk.Type == core.StreamChunkContent {
+			t.Fatalf("unexpected content chunk %+v", chunk)
+		}
+		if chunk.Type == core.StreamChunkPartialJSON {
+			partials = append(partials, chunk.Content)
+		}
+	}
+	if len(partials) != 2 || partials[0] != `{"city":"Pa"}` || partials[1] != `{"city":"Paris"}` {
+		t.Fatalf("partials = %q", partials)
+	}
+}
+
+func TestChatStreamReportsErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]}}],"responseId":"resp-e"}`,
+			`{"error":{"code":503,"message":"The model is overloaded.","status":"UNAVAILABLE"}}`,
+		)
+	}))
+	defer server.Close()
+
+	stream, err := newTestAdapter(server.URL).ChatStream(context.Background(), &core.ChatParams{
+		Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	chunks := collect(t, stream)
+	last := chunks[len(chunks)-1]
+	if last.Type != core.StreamChunkError || last.Error != "vertex: API error (UNAVAILABLE): The model is overloaded." || last.RequestID != "resp-e" {
+		t.Fatalf("last chunk = %+v", last)
+	}
+}
@@ -0,0 +1,339 @@
+package vertex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/httpclient"
+)
+
+func (p part) MarshalJSON() ([]byte, error) {
+	if len(p.Raw) > 0 {
+		return p.Raw, nil
+	}
+	type plain part
+	return json.Marshal(plain(p))
+}
+
+// marshalWithModelOptions encodes request and sets each model option as a
+// top-level field, such as "safetySettings" or "labels".
+func marshalWithModelOptions(request any, options map[string]any) ([]byte, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	if len(options) == 0 {
+		return body, nil
+	}
+
+	var envelope map[string]any
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	for key, value := range options {
+		key = strings.TrimSpace(key)
+		if key == "" || value == nil {
+			continue
+		}
+		envelope[key] = value
+	}
+
+	return json.Marshal(envelope)
+}
+
+// apiErrorDetail is the error object of Google APIs.
+type apiErrorDetail struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// decodeAPIError reads the {"error":{"code","message","status"}} envelope of
+// Google APIs, which streaming endpoints may wrap in an array.
+func decodeAPIError(resp *http.Response) error {
+	id := httpclient.RequestID(resp.Header)
+
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
+	if readErr != nil {
+		return &core.APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("vertex: API status %d and failed to read error body: %v", resp.StatusCode, readErr),
+			RequestID:  id,
+			Retryable:  resp.StatusCode >= http.StatusInternalServerError,
+		}
+	}
+
+	type envelope struct {
+		Error *apiErrorDetail `json:"error"`
+	}
+	var detail *apiErrorDetail
+	trimmed := bytes.TrimSpace(body)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var envelopes []envelope
+		if err := json.Unmarshal(trimmed, &envelopes); err == nil && len(envelopes) > 0 {
+			detail = envelopes[0].Error
+		}
+	} else {
+		var single envelope
+		if err := json.Unmarshal(trimmed, &single); err == nil {
+			detail = single.Error
+		}
+	}
+
+	if detail == nil || strings.TrimSpace(detail.Message) == "" {
+		text := string(trimmed)
+		if text == "" {
+			text = http.StatusText(resp.StatusCode)
+		}
+		return statusError(resp.StatusCode, "", fmt.Sprintf("vertex: API status %d: %s", resp.StatusCode, text), id, retryAfter(resp.Header))
+	}
+
+	return statusError(resp.StatusCode, detail.Status, apiErrorMessage(detail.Status, detail.Message), id, retryAfter(resp.Header))
+}
+
+// apiError builds the typed error for an error object sent in a stream.
+func apiError(code int, status, message, id string) error {
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+	return statusError(code, status, apiErrorMessage(status, message), id, 0)
+}
+
+func apiErrorMessage(status, message string) string {
+	message = strings.TrimSpace(message)
+	if status != "" {
+		return fmt.Sprintf("vertex: API error (%s): %s", status, message)
+	}
+	return "vertex: API error: " + message
+}
+
+// statusError maps a status code and Google status, such as
+// "RESOURCE_EXHAUSTED", onto core errors.
+func statusError(code int, status, message, id string, wait time.Duration) error {
+	switch {
+	case code == http.StatusTooManyRequests || status == "RESOURCE_EXHAUSTED":
+		return &core.RateLimitError{Message: message, RetryAfter: wait, RequestID: id}
+	case code == http.StatusNotFound && strings.Contains(strings.ToLower(message), "model"):
+		return &core.ModelNotFoundError{Message: message, RequestID: id}
+	}
+
+	return &core.APIError{
+		StatusCode: code,
+		Type:       status,
+		Message:    message,
+		RequestID:  id,
+		Retryable:  code >= http.StatusInternalServerError || status == "UNAVAILABLE",
+		RetryAfter: wait,
+	}
+}
+
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(header.Get("retry-after")), 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func extractText(parts []part) string {
+	var builder strings.Builder
+	for _, item := range parts {
+		if !item.Thought {
+			builder.WriteString(item.Text)
+		}
+	}
+	return builder.String()
+}
+
+func extractReasoning(parts []part) string {
+	texts := make([]string, 0, 1)
+	for _, item := range parts {
+		if item.Thought {
+			if text := strings.TrimSpace(item.Text); text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// extractReasoningBlocks returns the thought summaries of a response and the
+// thought signatures of its function calls, keyed by the IDs in calls. The
+// signatures must be sent back with the function calls to continue the
+// model's reasoning.
+func extractReasoningBlocks(parts []part, calls []core.ToolCall) []core.ReasoningBlock {
+	var blocks []core.ReasoningBlock
+	index := 0
+	for _, item := range parts {
+		switch {
+		case item.FunctionCall != nil:
+			if item.ThoughtSignature != "" && index < len(calls) {
+				blocks = append(blocks, core.ReasoningBlock{
+					Type:      reasoningBlockThoughtSignature,
+					Data:      calls[index].ID,
+					Signature: item.ThoughtSignature,
+				})
+			}
+			index++
+		case item.Thought && strings.TrimSpace(item.Text) != "":
+			blocks = append(blocks, core.ReasoningBlock{Type: reasoningBlockThought, Text: item.Text})
+		}
+	}
+	return blocks
+}
+
+// toCoreToolCalls returns the function calls among parts. Gemini models do
+// not always send call IDs, so missing IDs are numbered with callCount
+// across the turns of a tool loop.
+func toCoreToolCalls(parts []part, callCount *int) []core.ToolCall {
+	var calls []core.ToolCall
+	for _, item := range parts {
+		if item.FunctionCall != nil {
+			calls = append(calls, toCoreToolCall(item.FunctionCall, callCount))
+		}
+	}
+	return calls
+}
+
+func toCoreToolCall(call *functionCall, callCount *int) core.ToolCall {
+	*callCount++
+	id := strings.TrimSpace(call.ID)
+	if id == "" {
+		id = fmt.Sprintf("call_%d", *callCount)
+	}
+
+	args := call.Args
+	if args == nil {
+		args = map[string]any{}
+	}
+	return core.ToolCall{ID: id, Name: call.Name, Arguments: args}
+}
+
+func toCoreCitations(metadata *citationMetadata) []core.Citation {
+	if metadata == nil {
+		return nil
+	}
+
+	out := make([]core.Citation, 0, len(metadata.Citations))
+	for _, item := range metadata.Citations {
+		out = append(out, core.Citation{
+			Type:       "citation",
+			URL:        item.URI,
+			Title:      item.Title,
+			StartIndex: item.StartIndex,
+			EndIndex:   item.EndIndex,
+		})
+	}
+	return out
+}
+
+// toCoreCandidates returns every candidate of a response that has several.
+func toCoreCandidates(candidates []candidate) []core.Candidate {
+	if len(candidates) < 2 {
+		return nil
+	}
+
+	out := make([]core.Candidate, 0, len(candidates))
+	for _, item := range candidates {
+		callCount := 0
+		calls := toCoreToolCalls(item.Content.Parts, &callCount)
+		finishReason := toCoreFinishReason(item.FinishReason)
+		if len(calls) > 0 {
+			finishReason = core.FinishReasonToolCalls
+		}
+		out = append(out, core.Candidate{
+			Text:            extractText(item.Content.Parts),
+			Reasoning:       extractReasoning(item.Content.Parts),
+			ToolCalls:       calls,
+			FinishReason:    finishReason,
+			RawFinishReason: item.FinishReason,
+		})
+	}
+	return out
+}
+
+// toCoreUsage reports thought tokens as completion and reasoning tokens, as
+// Gemini bills them as output.
+func toCoreUsage(in *usageMetadata) *core.Usage {
+	if in == nil {
+		return nil
+	}
+
+	var details map[string]int64
+	addDetail := func(key string, value int64) {
+		if value <= 0 {
+			return
+		}
+		if details == nil {
+			details = make(map[string]int64)
+		}
+		details[key] = value
+	}
+	addDetail("cached_content_tokens", in.CachedContentTokenCount)
+	addDetail("tool_use_prompt_tokens", in.ToolUsePromptTokenCount)
+
+	completion := in.CandidatesTokenCount + in.ThoughtsTokenCount
+	total := in.TotalTokenCount
+	if total == 0 {
+		total = in.PromptTokenCount + in.ToolUsePromptTokenCount + completion
+	}
+	return &core.Usage{
+		PromptTokens:     in.PromptTokenCount,
+		CompletionTokens: completion,
+		TotalTokens:      total,
+		ReasoningTokens:  in.ThoughtsTokenCount,
+		Details:          details,
+	}
+}
+
+func responseUsage(response *generateResponse) *core.Usage {
+	out := toCoreUsage(response.UsageMetadata)
+	if out == nil {
+		out = &core.Usage{}
+	}
+	out.Duration = response.Duration
+	out.RequestID = response.ResponseID
+	return out
+}
+
+func appendReasoningPart(parts []string, reasoning string) []string {
+	reasoning = strings.TrimSpace(reasoning)
+	if reasoning == "" {
+		return parts
+	}
+	if len(parts) > 0 && parts[len(parts)-1] == reasoning {
+		return parts
+	}
+	return append(parts, reasoning)
+}
+
+func joinReasoningParts(parts []string) string {
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// toCoreFinishReason maps a Gemini finishReason or prompt blockReason onto
+// core.FinishReason.
+func toCoreFinishReason(reason string) core.FinishReason {
+	switch strings.TrimSpace(reason) {
+	case "", "STOP", "FINISH_REASON_UNSPECIFIED":
+		return core.FinishReasonStop
+	case "MAX_TOKENS":
+		return core.FinishReasonLength
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return core.FinishReasonContentFilter
+	case "MALFORMED_FUNCTION_CALL", "UNEXPECTED_TOOL_CALL":
+		return core.FinishReasonError
+	default:
+		return core.FinishReason(reason)
+	}
+}
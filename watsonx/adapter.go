@@ -0,0 +1,437 @@
+// Package watsonx is an adapter for the chat API of IBM watsonx.ai, which
+// serves foundation models such as Granite, Llama, and Mistral.
+//
+// Requests are authorized with IAM access tokens. The adapter exchanges an
+// IBM Cloud API key for a token, reuses it until shortly before it expires,
+// and fetches a new one when the API rejects it. Each request is scoped to a
+// watsonx.ai project or deployment space, which bills and governs it.
+package watsonx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/accesstoken"
+	"github.com/m43i/go-ai/internal/httpclient"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+const (
+	providerName       = "watsonx"
+	defaultBaseURL     = "https://us-south.ml.cloud.ibm.com"
+	defaultVersion     = "2024-05-31"
+	defaultHTTPTimeout = 5 * time.Minute
+	envWatsonxAPIKey   = "WATSONX_APIKEY"
+	envWatsonxURL      = "WATSONX_URL"
+	envWatsonxProject  = "WATSONX_PROJECT_ID"
+	envWatsonxSpace    = "WATSONX_SPACE_ID"
+)
+
+type Adapter struct {
+	// APIKey is an IBM Cloud API key, which is exchanged for IAM access
+	// tokens. It is used when TokenSource is nil.
+	APIKey string
+	Model  string
+
+	// BaseURL is the regional endpoint, such as
+	// https://eu-de.ml.cloud.ibm.com.
+	BaseURL string
+
+	// ProjectID or SpaceID scopes each request to a project or a
+	// deployment space. One of them is required.
+	ProjectID string
+	SpaceID   string
+
+	// Version is the API version date sent with each request.
+	Version string
+
+	// IAMURL is the token endpoint the API key is exchanged at.
+	IAMURL string
+
+	// TokenSource supplies access tokens instead of the API key.
+	TokenSource TokenSource
+
+	HTTPClient *http.Client
+
+	mu     sync.Mutex
+	tokens TokenSource
+}
+
+var _ core.TextAdapter = (*Adapter)(nil)
+var _ core.CapabilityAdapter = (*Adapter)(nil)
+
+type Option func(*Adapter)
+
+// New creates a watsonx.ai adapter for a model, such as
+// "ibm/granite-3-3-8b-instruct".
+//
+// Preferred usage is to use core and add this adapter there.
+//
+// If not provided via options, New reads the API key from WATSONX_APIKEY,
+// the endpoint from WATSONX_URL, and the scope from WATSONX_PROJECT_ID or
+// WATSONX_SPACE_ID.
+func New(model string, opts ...Option) *Adapter {
+	baseURL := strings.TrimSpace(os.Getenv(envWatsonxURL))
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	adapter := &Adapter{
+		APIKey:     strings.TrimSpace(os.Getenv(envWatsonxAPIKey)),
+		Model:      strings.TrimSpace(model),
+		BaseURL:    baseURL,
+		ProjectID:  strings.TrimSpace(os.Getenv(envWatsonxProject)),
+		SpaceID:    strings.TrimSpace(os.Getenv(envWatsonxSpace)),
+		Version:    defaultVersion,
+		IAMURL:     defaultIAMURL,
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(adapter)
+	}
+
+	return adapter
+}
+
+// WithAPIKey sets the IBM Cloud API key used by the adapter.
+func WithAPIKey(apiKey string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(apiKey) == "" {
+			return
+		}
+		adapter.APIKey = strings.TrimSpace(apiKey)
+	}
+}
+
+// WithBaseURL sets the regional endpoint used by the adapter.
+func WithBaseURL(baseURL string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(baseURL) == "" {
+			return
+		}
+		adapter.BaseURL = strings.TrimSpace(baseURL)
+	}
+}
+
+// WithProjectID scopes requests to a project. It replaces a space.
+func WithProjectID(projectID string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(projectID) == "" {
+			return
+		}
+		adapter.ProjectID = strings.TrimSpace(projectID)
+		adapter.SpaceID = ""
+	}
+}
+
+// WithSpaceID scopes requests to a deployment space. It replaces a
+// project.
+func WithSpaceID(spaceID string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(spaceID) == "" {
+			return
+		}
+		adapter.SpaceID = strings.TrimSpace(spaceID)
+		adapter.ProjectID = ""
+	}
+}
+
+// WithVersion sets the API version date, such as "2025-02-11".
+func WithVersion(version string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(version) == "" {
+			return
+		}
+		adapter.Version = strings.TrimSpace(version)
+	}
+}
+
+// WithIAMURL sets the IAM token endpoint, for IBM Cloud environments with
+// their own IAM.
+func WithIAMURL(iamURL string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(iamURL) == "" {
+			return
+		}
+		adapter.IAMURL = strings.TrimSpace(iamURL)
+	}
+}
+
+// WithTokenSource sets the source of access tokens, such as one for a
+// Cloud Pak for Data cluster.
+func WithTokenSource(source TokenSource) Option {
+	return func(adapter *Adapter) {
+		if source == nil {
+			return
+		}
+		adapter.TokenSource = source
+	}
+}
+
+// WithAccessToken sets a fixed access token. It is not refreshed.
+func WithAccessToken(token string) Option {
+	return func(adapter *Adapter) {
+		if strings.TrimSpace(token) == "" {
+			return
+		}
+		adapter.TokenSource = accesstoken.Static(strings.TrimSpace(token))
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the adapter.
+func WithHTTPClient(client *http.Client) Option {
+	return func(adapter *Adapter) {
+		if client == nil {
+			return
+		}
+		adapter.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the timeout on the adapter HTTP client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(adapter *Adapter) {
+		if timeout <= 0 {
+			return
+		}
+		if adapter.HTTPClient == nil {
+			adapter.HTTPClient = &http.Client{}
+		}
+		adapter.HTTPClient.Timeout = timeout
+	}
+}
+
+// Capabilities reports the chat features of watsonx.ai. Vision depends on
+// the model.
+func (a *Adapter) Capabilities() core.Capabilities {
+	return core.Capabilities{
+		Tools:              true,
+		Vision:             true,
+		StructuredOutput:   true,
+		StreamingWithTools: true,
+	}
+}
+
+func (a *Adapter) validate() error {
+	if a == nil {
+		return errors.New("watsonx: adapter is nil")
+	}
+	if strings.TrimSpace(a.Model) == "" {
+		return errors.New("watsonx: model is required")
+	}
+	if strings.TrimSpace(a.ProjectID) == "" && strings.TrimSpace(a.SpaceID) == "" {
+		return errors.New("watsonx: project or space is required (set WATSONX_PROJECT_ID or use watsonx.WithProjectID)")
+	}
+	if strings.TrimSpace(a.ProjectID) != "" && strings.TrimSpace(a.SpaceID) != "" {
+		return errors.New("watsonx: set a project or a space, not both")
+	}
+	if _, err := a.tokenSource(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// tokenSource resolves the source of access tokens once.
+func (a *Adapter) tokenSource() (TokenSource, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.tokens != nil {
+		return a.tokens, nil
+	}
+
+	source := a.TokenSource
+	if source == nil {
+		if strings.TrimSpace(a.APIKey) == "" {
+			a.APIKey = strings.TrimSpace(os.Getenv(envWatsonxAPIKey))
+		}
+		if strings.TrimSpace(a.APIKey) == "" {
+			return nil, errors.New("watsonx: API key is required (set WATSONX_APIKEY or use watsonx.WithAPIKey)")
+		}
+		iamURL := strings.TrimSpace(a.IAMURL)
+		if iamURL == "" {
+			iamURL = defaultIAMURL
+		}
+		source = &apiKeySource{apiKey: a.APIKey, url: iamURL, client: a.client()}
+	}
+
+	if _, ok := source.(accesstoken.Static); !ok {
+		source = &accesstoken.Cache{Source: source, RefreshWindow: tokenRefreshWindow}
+	}
+	a.tokens = source
+	return source, nil
+}
+
+// dropToken forgets the cached token after the API rejected it.
+func (a *Adapter) dropToken() {
+	a.mu.Lock()
+	source := a.tokens
+	a.mu.Unlock()
+
+	if cache, ok := source.(*accesstoken.Cache); ok {
+		cache.Invalidate()
+	}
+}
+
+// refreshable reports whether a rejected token can be replaced.
+func (a *Adapter) refreshable() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.tokens.(*accesstoken.Cache)
+	return ok
+}
+
+// compat returns the chat client for the adapter, authorized with a
+// current access token.
+func (a *Adapter) compat(ctx context.Context) (*openaicompat.Client, error) {
+	source, err := a.tokenSource()
+	if err != nil {
+		return nil, err
+	}
+	token, err := source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	version := "?version=" + a.version()
+	return &openaicompat.Client{
+		Provider:       providerName,
+		Model:          a.Model,
+		Transport:      a.transport(ctx, source, token.AccessToken),
+		ChatPath:       "/ml/v1/text/chat" + version,
+		StreamPath:     "/ml/v1/text/chat_stream" + version,
+		ModelField:     "model_id",
+		PrepareRequest: a.prepareRequest,
+	}, nil
+}
+
+func (a *Adapter) version() string {
+	if version := strings.TrimSpace(a.Version); version != "" {
+		return version
+	}
+	return defaultVersion
+}
+
+func (a *Adapter) client() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// transport returns the client that sends API requests with accessToken.
+// When the API rejects the token, the client fetches a new one from source
+// and sends the rejected request again once, so that a refresh never
+// repeats the turns of a chat that already completed.
+func (a *Adapter) transport(ctx context.Context, source TokenSource, accessToken string) *httpclient.Client {
+	var mu sync.Mutex
+	return &httpclient.Client{
+		HTTPClient: a.client(),
+		BaseURL:    a.baseURL(),
+		Provider:   providerName,
+		Header: func(header http.Header) {
+			mu.Lock()
+			defer mu.Unlock()
+			header.Set("Authorization", "Bearer "+accessToken)
+			header.Set("Accept", "application/json")
+		},
+		DecodeError: a.decodeError,
+		Retry: func(attempt int, err error) (time.Duration, bool) {
+			if attempt > 0 || !unauthorized(err) || !a.refreshable() {
+				return 0, false
+			}
+			token, tokenErr := source.Token(ctx)
+			if tokenErr != nil {
+				return 0, false
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			accessToken = token.AccessToken
+			return 0, true
+		},
+	}
+}
+
+func (a *Adapter) baseURL() string {
+	baseURL := strings.TrimRight(strings.TrimSpace(a.BaseURL), "/")
+	if baseURL == "" {
+		return defaultBaseURL
+	}
+	return baseURL
+}
+
+// decodeError reads the {"errors":[...],"trace":...} envelope of
+// watsonx.ai. Status 401 drops the cached access token, 429 becomes a
+// core.RateLimitError, and an unknown model a core.ModelNotFoundError.
+func (a *Adapter) decodeError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusUnauthorized {
+		a.dropToken()
+	}
+
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
+	if readErr != nil {
+		return &core.APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("watsonx: API status %d and failed to read error body: %v", resp.StatusCode, readErr),
+			Retryable:  resp.StatusCode >= http.StatusInternalServerError,
+		}
+	}
+
+	var envelope struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+		Trace string `json:"trace"`
+	}
+	_ = json.Unmarshal(body, &envelope)
+
+	requestID := envelope.Trace
+	if requestID == "" {
+		requestID = httpclient.RequestID(resp.Header)
+	}
+
+	code := ""
+	message := fmt.Sprintf("watsonx: API status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	if len(envelope.Errors) > 0 {
+		code = envelope.Errors[0].Code
+		messages := make([]string, 0, len(envelope.Errors))
+		for _, detail := range envelope.Errors {
+			messages = append(messages, strings.TrimSpace(detail.Message))
+		}
+		message = fmt.Sprintf("watsonx: API error (%s): %s", code, strings.Join(messages, "; "))
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &core.RateLimitError{
+			Message:    message,
+			RetryAfter: openaicompat.RetryAfter(resp.Header),
+			RequestID:  requestID,
+		}
+	case code == "model_not_supported":
+		return &core.ModelNotFoundError{Message: message, RequestID: requestID}
+	}
+
+	return &core.APIError{
+		StatusCode: resp.StatusCode,
+		Type:       code,
+		Message:    message,
+		RequestID:  requestID,
+		Retryable:  resp.StatusCode >= http.StatusInternalServerError,
+		RetryAfter: openaicompat.RetryAfter(resp.Header),
+	}
+}
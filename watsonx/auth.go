@@ -0,0 +1,40 @@
+package watsonx
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/m43i/go-ai/internal/accesstoken"
+)
+
+const (
+	defaultIAMURL   = "https://iam.cloud.ibm.com/identity/token"
+	apiKeyGrantType = "urn:ibm:params:oauth:grant-type:apikey"
+
+	// tokenRefreshWindow is how long before its expiry a token is refreshed.
+	// IAM tokens are valid for an hour.
+	tokenRefreshWindow = 5 * time.Minute
+)
+
+// Token is an IAM access token.
+type Token = accesstoken.Token
+
+// TokenSource returns access tokens for the watsonx.ai API, such as the
+// tokens of a Cloud Pak for Data cluster.
+type TokenSource = accesstoken.Source
+
+// apiKeySource exchanges an IBM Cloud API key for IAM access tokens.
+type apiKeySource struct {
+	apiKey string
+	url    string
+	client *http.Client
+}
+
+func (s *apiKeySource) Token(ctx context.Context) (*Token, error) {
+	return accesstoken.Exchange(ctx, s.client, providerName, s.url, url.Values{
+		"grant_type": {apiKeyGrantType},
+		"apikey":     {s.apiKey},
+	})
+}
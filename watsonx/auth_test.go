@@ -0,0 +1,173 @@
+package watsonx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+// newIAMServer returns an IAM token endpoint that issues iam-1, iam-2, and
+// so on, valid for lifetime.
+func newIAMServer(t *testing.T, lifetime time.Duration, exchanges *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		if grant, key := r.PostForm.Get("grant_type"), r.PostForm.Get("apikey"); grant != apiKeyGrantType || key != "ibm-key" {
+			t.Errorf("grant_type = %q, apikey = %q", grant, key)
+		}
+		n := exchanges.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"iam-%d","refresh_token":"not_supported","token_type":"Bearer","expires_in":%d,"expiration":%d}`,
+			n, int(lifetime.Seconds()), time.Now().Add(lifetime).Unix())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func chatParams() *core.ChatParams {
+	return &core.ChatParams{Messages: []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}}}
+}
+
+const testChatResponse = `{"id":"chat-1","model_id":"ibm/granite-3-3-8b-instruct","choices":[{"index":0,"message":{"role":"assistant","content":"Hello!"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`
+
+func TestChatExchangesAPIKeyAndCachesToken(t *testing.T) {
+	t.Parallel()
+
+	var exchanges atomic.Int32
+	iam := newIAMServer(t, time.Hour, &exchanges)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer iam-1" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, testChatResponse)
+	}))
+	defer api.Close()
+
+	adapter := New("ibm/granite-3-3-8b-instruct", WithAPIKey("ibm-key"), WithProjectID("project-1"), WithBaseURL(api.URL), WithIAMURL(iam.URL))
+	for range 2 {
+		if _, err := adapter.Chat(context.Background(), chatParams()); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+	if exchanges.Load() != 1 {
+		t.Fatalf("token exchanges = %d", exchanges.Load())
+	}
+}
+
+func TestChatRefreshesExpiringToken(t *testing.T) {
+	t.Parallel()
+
+	var exchanges atomic.Int32
+	iam := newIAMServer(t, 2*time.Minute, &exchanges)
+	var tokens []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, testChatResponse)
+	}))
+	defer api.Close()
+
+	adapter := New("ibm/granite-3-3-8b-instruct", WithAPIKey("ibm-key"), WithProjectID("project-1"), WithBaseURL(api.URL), WithIAMURL(iam.URL))
+	for range 2 {
+		if _, err := adapter.Chat(context.Background(), chatParams()); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+	if len(tokens) != 2 || tokens[0] != "Bearer iam-1" || tokens[1] != "Bearer iam-2" {
+		t.Fatalf("tokens = %q", tokens)
+	}
+}
+
+func TestChatRetriesWithNewTokenAfterUnauthorized(t *testing.T) {
+	t.Parallel()
+
+	var exchanges atomic.Int32
+	iam := newIAMServer(t, time.Hour, &exchanges)
+	var requests atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") == "Bearer iam-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errors":[{"code":"authentication_token_expired","message":"Failed to authenticate the request due to an expired token"}],"trace":"trace-401","status_code":401}`)
+			return
+		}
+		fmt.Fprint(w, testChatResponse)
+	}))
+	defer api.Close()
+
+	adapter := New("ibm/granite-3-3-8b-instruct", WithAPIKey("ibm-key"), WithProjectID("project-1"), WithBaseURL(api.URL), WithIAMURL(iam.URL))
+	result, err := adapter.Chat(context.Background(), chatParams())
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Text != "Hello!" || exchanges.Load() != 2 || requests.Load() != 2 {
+		t.Fatalf("text = %q after %d exchanges and %d requests", result.Text, exchanges.Load(), requests.Load())
+	}
+}
+
+func TestChatRetriesOnlyRejectedTurn(t *testing.T) {
+	t.Parallel()
+
+	var exchanges atomic.Int32
+	iam := newIAMServer(t, time.Hour, &exchanges)
+	var requests atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case n == 1:
+			fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{}"}}]},"finish_reason":"tool_calls"}]}`)
+		case r.Header.Get("Authorization") == "Bearer iam-1":
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errors":[{"code":"authentication_token_expired","message":"Failed to authenticate the request due to an expired token"}],"trace":"trace-401","status_code":401}`)
+		default:
+			fmt.Fprint(w, testChatResponse)
+		}
+	}))
+	defer api.Close()
+
+	var calls atomic.Int32
+	params := chatParams()
+	params.Tools = []core.ToolUnion{core.ServerTool{Name: "lookup", Handler: func(any) (string, error) {
+		calls.Add(1)
+		return "found", nil
+	}}}
+
+	adapter := New("ibm/granite-3-3-8b-instruct", WithAPIKey("ibm-key"), WithProjectID("project-1"), WithBaseURL(api.URL), WithIAMURL(iam.URL))
+	result, err := adapter.Chat(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result.Text != "Hello!" || calls.Load() != 1 || requests.Load() != 3 || exchanges.Load() != 2 {
+		t.Fatalf("text = %q after %d tool calls, %d requests, and %d exchanges", result.Text, calls.Load(), requests.Load(), exchanges.Load())
+	}
+}
+
+func TestChatDoesNotRetryFixedAccessToken(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"errors":[{"code":"authentication_token_not_valid","message":"Invalid token"}],"trace":"trace-1","status_code":401}`)
+	}))
+	defer api.Close()
+
+	_, err := New("ibm/granite-3-3-8b-instruct", WithAccessToken("fixed"), WithSpaceID("space-1"), WithBaseURL(api.URL)).Chat(context.Background(), chatParams())
+	if !unauthorized(err) || core.RequestIDOf(err) != "trace-1" || requests.Load() != 1 {
+		t.Fatalf("Chat() error = %v after %d requests", err, requests.Load())
+	}
+}
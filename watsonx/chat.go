@@ -0,0 +1,66 @@
+package watsonx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/openaicompat"
+)
+
+// Chat sends a non-streaming chat request to /ml/v1/text/chat.
+//
+// It supports tool calls and structured output. When the API rejects the
+// access token, the rejected request is sent again once with a new token.
+func (a *Adapter) Chat(ctx context.Context, params *core.ChatParams) (*core.ChatResult, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	compat, err := a.compat(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return compat.Chat(ctx, params)
+}
+
+// ChatStream sends a streaming chat request to /ml/v1/text/chat_stream.
+//
+// Server tools run between streamed turns. Structured output streams as
+// StreamChunkPartialJSON chunks. Usage is reported on the done chunk. A
+// rejected access token is replaced before the stream starts, as in Chat.
+func (a *Adapter) ChatStream(ctx context.Context, params *core.ChatParams) (<-chan core.StreamChunk, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	compat, err := a.compat(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return compat.ChatStream(ctx, params)
+}
+
+// prepareRequest scopes the request to the project or space and sends a
+// tool choice mode as tool_choice_option, as watsonx.ai expects.
+func (a *Adapter) prepareRequest(_ *core.ChatParams, request *openaicompat.Request) error {
+	if request.Extra == nil {
+		request.Extra = make(map[string]any, 2)
+	}
+	if projectID := strings.TrimSpace(a.ProjectID); projectID != "" {
+		request.Extra["project_id"] = projectID
+	} else {
+		request.Extra["space_id"] = strings.TrimSpace(a.SpaceID)
+	}
+
+	if mode, ok := request.ToolChoice.(string); ok {
+		request.Extra["tool_choice_option"] = mode
+		request.ToolChoice = nil
+	}
+	return nil
+}
+
+func unauthorized(err error) bool {
+	var apiErr *core.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
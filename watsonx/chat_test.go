@@ -0,0 +1,173 @@
+package watsonx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func TestChatSendsModelIDAndScope(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ml/v1/text/chat" || r.URL.Query().Get("version") != "2025-02-11" {
+			t.Errorf("unexpected URL %s", r.URL)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"chat-2","model_id":"ibm/granite-3-3-8b-instruct","choices":[{"index":0,"message":{"role":"assistant","tool_calls":[{"id":"chatcmpl-tool-1","type":"function","function":{"name":"weather","arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":30,"completion_tokens":12,"total_tokens":42}}`)
+	}))
+	defer server.Close()
+
+	adapter := New("ibm/granite-3-3-8b-instruct", WithAccessToken("token"), WithProjectID("project-1"), WithBaseURL(server.URL), WithVersion("2025-02-11"))
+	result, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages:   []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Weather in Paris?"}},
+		Tools:      []core.ToolUnion{core.ClientTool{Name: "weather", Parameters: map[string]any{"type": "object"}}},
+		ToolChoice: &core.ToolChoice{Mode: core.ToolChoiceRequired},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if body["model_id"] != "ibm/granite-3-3-8b-instruct" || body["project_id"] != "project-1" || body["tool_choice_option"] != "required" {
+		t.Fatalf("unexpected request body %v", body)
+	}
+	for _, field := range []string{"model", "space_id", "tool_choice"} {
+		if _, ok := body[field]; ok {
+			t.Fatalf("unexpected %s in %v", field, body)
+		}
+	}
+	if len(result.ToolCalls) != 1 || result.ToolCalls[0].Name != "weather" || result.Usage.TotalTokens != 42 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestChatSendsNamedToolChoiceAndSpace(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, testChatResponse)
+	}))
+	defer server.Close()
+
+	adapter := New("meta-llama/llama-3-3-70b-instruct", WithAccessToken("token"), WithSpaceID("space-1"), WithBaseURL(server.URL))
+	_, err := adapter.Chat(context.Background(), &core.ChatParams{
+		Messages:   []core.MessageUnion{core.TextMessagePart{Role: core.RoleUser, Content: "Hi"}},
+		Tools:      []core.ToolUnion{core.ClientTool{Name: "weather"}},
+		ToolChoice: &core.ToolChoice{Name: "weather"},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	choice, _ := body["tool_choice"].(map[string]any)
+	if body["space_id"] != "space-1" || choice["type"] != "function" || choice["function"].(map[string]any)["name"] != "weather" {
+		t.Fatalf("unexpected request body %v", body)
+	}
+	if _, ok := body["project_id"]; ok {
+		t.Fatalf("unexpected project_id in %v", body)
+	}
+}
+
+func TestChatDecodesErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("version") {
+		case "2024-05-31":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[{"code":"model_not_supported","message":"Model 'ibm/unknown' is not supported"}],"trace":"trace-404","status_code":404}`)
+		default:
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"errors":[{"code":"token_quota_reached","message":"Token quota reached"}],"trace":"trace-429","status_code":429}`)
+		}
+	}))
+	defer server.Close()
+
+	_, err := New("ibm/unknown", WithAccessToken("token"), WithProjectID("project-1"), WithBaseURL(server.URL)).Chat(context.Background(), chatParams())
+	var notFound *core.ModelNotFoundError
+	if !errors.As(err, &notFound) || notFound.RequestID != "trace-404" || !strings.Contains(err.Error(), "watsonx: API error (model_not_supported): Model 'ibm/unknown' is not supported") {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	_, err = New("ibm/granite-3-3-8b-instruct", WithAccessToken("token"), WithProjectID("project-1"), WithBaseURL(server.URL), WithVersion("2025-02-11")).Chat(context.Background(), chatParams())
+	var rateLimit *core.RateLimitError
+	if !errors.As(err, &rateLimit) || rateLimit.RetryAfter.Seconds() != 3 || rateLimit.RequestID != "trace-429" {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
+
+func TestChatStreamUsesStreamEndpoint(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ml/v1/text/chat_stream" || r.URL.Query().Get("version") != defaultVersion {
+			t.Errorf("unexpected URL %s", r.URL)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["stream"] != true || body["project_id"] != "project-1" {
+			t.Errorf("unexpected request body %v", body)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `id: 1
+event: message
+data: {"id":"chat-3","model_id":"ibm/granite-3-3-8b-instruct","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}
+
+id: 2
+event: message
+data: {"id":"chat-3","model_id":"ibm/granite-3-3-8b-instruct","choices":[{"index":0,"delta":{"content":"lo!"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}
+
+`)
+	}))
+	defer server.Close()
+
+	stream, err := New("ibm/granite-3-3-8b-instruct", WithAccessToken("token"), WithProjectID("project-1"), WithBaseURL(server.URL)).ChatStream(context.Background(), chatParams())
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var content strings.Builder
+	var done core.StreamChunk
+	for chunk := range stream {
+		switch chunk.Type {
+		case core.StreamChunkContent:
+			content.WriteString(chunk.Delta)
+		case core.StreamChunkDone:
+			done = chunk
+		case core.StreamChunkError:
+			t.Fatalf("stream error: %s", chunk.Error)
+		}
+	}
+	if content.String() != "Hello!" || done.Usage == nil || done.Usage.TotalTokens != 5 {
+		t.Fatalf("content = %q, done = %+v", content.String(), done)
+	}
+}
+
+func TestChatRequiresScope(t *testing.T) {
+	t.Parallel()
+
+	adapter := New("ibm/granite-3-3-8b-instruct", WithAccessToken("token"))
+	adapter.ProjectID, adapter.SpaceID = "", ""
+	if _, err := adapter.Chat(context.Background(), chatParams()); err == nil || !strings.Contains(err.Error(), "watsonx: project or space is required") {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	adapter.ProjectID, adapter.SpaceID = "project-1", "space-1"
+	if _, err := adapter.Chat(context.Background(), chatParams()); err == nil || !strings.Contains(err.Error(), "not both") {
+		t.Fatalf("Chat() error = %v", err)
+	}
+}
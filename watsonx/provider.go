@@ -0,0 +1,42 @@
+package watsonx
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+)
+
+func init() {
+	core.RegisterProvider(providerName, newFromConfig)
+}
+
+// newFromConfig builds an adapter for core.NewAdapter. It supports the
+// options timeout, project_id, space_id, version, iam_url, and
+// access_token. The API key is an IBM Cloud API key.
+func newFromConfig(config core.ProviderConfig) (core.TextAdapter, error) {
+	opts := []Option{WithAPIKey(config.APIKey), WithBaseURL(config.BaseURL)}
+	for name, value := range config.Options {
+		switch name {
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", name, err)
+			}
+			opts = append(opts, WithTimeout(timeout))
+		case "project_id":
+			opts = append(opts, WithProjectID(value))
+		case "space_id":
+			opts = append(opts, WithSpaceID(value))
+		case "version":
+			opts = append(opts, WithVersion(value))
+		case "iam_url":
+			opts = append(opts, WithIAMURL(value))
+		case "access_token":
+			opts = append(opts, WithAccessToken(value))
+		default:
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	return New(config.Model, opts...), nil
+}
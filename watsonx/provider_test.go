@@ -0,0 +1,37 @@
+package watsonx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m43i/go-ai/core"
+	"github.com/m43i/go-ai/internal/accesstoken"
+)
+
+func TestFromDSN(t *testing.T) {
+	t.Parallel()
+
+	adapter, err := core.FromDSN("watsonx://ibm-key@ibm/granite-3-3-8b-instruct?base_url=https://eu-de.ml.cloud.ibm.com&project_id=project-1&version=2025-02-11&timeout=30s")
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	watsonx := adapter.(*Adapter)
+	if watsonx.APIKey != "ibm-key" || watsonx.Model != "ibm/granite-3-3-8b-instruct" || watsonx.BaseURL != "https://eu-de.ml.cloud.ibm.com" {
+		t.Fatalf("adapter = %+v", watsonx)
+	}
+	if watsonx.ProjectID != "project-1" || watsonx.SpaceID != "" || watsonx.Version != "2025-02-11" || watsonx.HTTPClient.Timeout != 30*time.Second {
+		t.Fatalf("adapter = %+v", watsonx)
+	}
+
+	adapter, err = core.FromDSN("watsonx://ibm/granite-3-3-8b-instruct?space_id=space-1&access_token=cpd-token")
+	if err != nil {
+		t.Fatalf("FromDSN() error = %v", err)
+	}
+	if watsonx := adapter.(*Adapter); watsonx.SpaceID != "space-1" || watsonx.TokenSource != accesstoken.Static("cpd-token") {
+		t.Fatalf("adapter = %+v", watsonx)
+	}
+
+	if _, err := core.FromDSN("watsonx://ibm/granite-3-3-8b-instruct?region=eu-de"); err == nil {
+		t.Fatal("FromDSN() expected error for an unknown option")
+	}
+}